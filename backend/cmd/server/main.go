@@ -2,22 +2,30 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"time"
+	_ "time/tzdata" // 内嵌时区数据库，港口 IANA 时区校验不依赖运行环境的 zoneinfo
 
 	casbinv2 "github.com/casbin/casbin/v2"
 	"github.com/cruisebooking/backend/internal/config"
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/handler"
+	"github.com/cruisebooking/backend/internal/middleware"
 	"github.com/cruisebooking/backend/internal/pkg/database"
 	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
 	"github.com/cruisebooking/backend/internal/pkg/search"
+	"github.com/cruisebooking/backend/internal/pkg/tracing"
 	"github.com/cruisebooking/backend/internal/repository"
 	"github.com/cruisebooking/backend/internal/router"
 	"github.com/cruisebooking/backend/internal/service"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 
 	_ "github.com/cruisebooking/backend/docs" // 导入 Swagger 自动生成的文档
 )
@@ -64,6 +72,20 @@ func RunApp(configDir string) error {
 	// 2. 初始化日志记录器
 	appLogger := logger.New(cfg.Log.Level, cfg.Log.Filename)
 	defer func() { _ = appLogger.Sync() }()
+	// 未携带请求上下文的日志（定时任务等）通过 logger.FromContext 回退到全局记录器
+	zap.ReplaceGlobals(appLogger)
+
+	// 链路追踪：透传 traceparent，并按配置把 span 导出到标准输出或文件
+	shutdownTracing, err := tracing.Setup(tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		Filename:    cfg.Tracing.Filename,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = shutdownTracing(context.Background()) }()
 
 	// 3. 连接数据库
 	db, err := database.Connect(database.Config{
@@ -80,6 +102,9 @@ func RunApp(configDir string) error {
 	if err != nil {
 		return fmt.Errorf("数据库连接失败: %w", err)
 	}
+	if err := metrics.InstrumentDB(db, cfg.Database.DBName); err != nil {
+		return fmt.Errorf("注册数据库指标失败: %w", err)
+	}
 
 	// 4. 初始化数据仓储层
 	staffRepo := repository.NewStaffRepository(db)
@@ -100,15 +125,34 @@ func RunApp(configDir string) error {
 	notifyTplRepo := repository.NewNotificationTemplateRepository(db)
 	contentTemplateRepo := repository.NewContentTemplateRepository(db)
 	customDestRepo := repository.NewCustomDestinationRepository(db)
+	portRepo := repository.NewPortRepository(db)
+	operationLogRepo := repository.NewOperationLogRepository(db)
 
 	// 5. 初始化业务服务层
-	authSvc := service.NewAuthService(staffRepo, cfg.JWT.Secret, cfg.JWT.ExpireHours)
+	// 员工账号安全：登录锁定、密码复杂度/历史/有效期、一次性重置令牌与按角色强制的 TOTP 二次验证
+	staffSecurityPolicy := service.StaffSecurityPolicy{
+		MinPasswordLength: cfg.StaffSecurity.MinPasswordLength,
+		MinCharClasses:    cfg.StaffSecurity.MinCharClasses,
+		PasswordHistory:   cfg.StaffSecurity.PasswordHistory,
+		PasswordMaxAge:    time.Duration(cfg.StaffSecurity.PasswordMaxAgeDays) * 24 * time.Hour,
+		MaxFailedLogins:   cfg.StaffSecurity.MaxFailedLogins,
+		LockDuration:      time.Duration(cfg.StaffSecurity.LockMinutes) * time.Minute,
+		ResetTokenTTL:     time.Duration(cfg.StaffSecurity.ResetTokenTTLHours) * time.Hour,
+		TOTPRequiredRoles: cfg.StaffSecurity.TOTPRequiredRoles,
+		TOTPIssuer:        cfg.StaffSecurity.TOTPIssuer,
+	}
+	authSvc := service.NewAuthServiceWithSecurity(staffRepo, repository.NewStaffSecurityRepository(db), staffSecurityPolicy,
+		service.NewStaffOperationLogger(operationLogRepo), cfg.JWT.Secret, cfg.JWT.ExpireHours)
 	companySvc := service.NewCompanyService(companyRepo, cruiseRepo)
 	cruiseSvc := service.NewCruiseService(cruiseRepo, cabinTypeRepo, companyRepo)
 	cabinTypeSvc := service.NewCabinTypeService(cabinTypeRepo, cabinTypeBindingRepo)
 	cabinTypeCategorySvc := service.NewCabinTypeCategoryService(cabinTypeCategoryRepo)
 	cabinTypeMediaSvc := service.NewCabinTypeMediaService(cabinTypeMediaRepo)
 	voyageCabinTypePriceSvc := service.NewVoyageCabinTypePriceService(voyageCabinTypePriceRepo)
+	priceVersionScheduler := service.NewPriceVersionScheduler(voyageCabinTypePriceSvc, time.Minute)
+	priceVersionScheduler.Start()
+	defer priceVersionScheduler.Stop()
+	dynamicPricingSvc := service.NewDynamicPricingService(repository.NewDynamicPricingRepository(db), voyageRepo, voyageCabinTypePriceSvc)
 	facilityCategorySvc := service.NewFacilityCategoryService(facilityCategoryRepo)
 	facilitySvc := service.NewFacilityService(facilityRepo)
 	imageSvc := service.NewImageService(imageRepo)
@@ -119,7 +163,6 @@ func RunApp(configDir string) error {
 	searchRetryQueue.Start()
 	holdRepo := repository.NewCabinHoldRepository(db)
 	holdSvc := service.NewCabinHoldService(holdRepo, 15*time.Minute)
-	operationLogRepo := repository.NewOperationLogRepository(db)
 
 	// 7. 初始化 Casbin RBAC 权限执行器
 	mPath := filepath.Join(configDir, "rbac/model.conf")
@@ -135,6 +178,7 @@ func RunApp(configDir string) error {
 	cruiseHandler := handler.NewCruiseHandler(cruiseSvc)
 	cabinTypeHandler := handler.NewCabinTypeHandler(cabinTypeSvc)
 	cabinPricingHandler := handler.NewCabinPricingHandler(voyageCabinTypePriceSvc, voyageRepo, cruiseRepo)
+	dynamicPricingHandler := handler.NewDynamicPricingHandler(dynamicPricingSvc)
 	cabinTypeCategoryHandler := handler.NewCabinTypeCategoryHandler(cabinTypeCategorySvc)
 	cabinTypeMediaHandler := handler.NewCabinTypeMediaHandler(cabinTypeMediaSvc, cfg.Upload.StorageDir, cfg.Upload.PublicPath, cfg.Upload.MaxFileSize)
 	facilityCategoryHandler := handler.NewFacilityCategoryHandler(facilityCategorySvc)
@@ -151,33 +195,128 @@ func RunApp(configDir string) error {
 	bookingSvc := service.NewBookingService(bookingRepo, pricingSvc, holdSvc)
 	bookingHandler := handler.NewBookingHandler(bookingSvc, bookingRepo)
 	bookingHandler.SetExportService(service.NewOrderExportService(bookingOrderExportRepo{repo: bookingRepo}))
-	userAuthSvc := service.NewUserAuthService(service.NewInMemoryCodeStore())
+	// 分销端令牌使用派生密钥签发，避免与后台、C 端令牌互相冒用
+	agencyJWTSecret := cfg.JWT.Secret + ":agency"
+	agencySvc := service.NewAgencyService(repository.NewAgencyRepository(db), cabinRepo, agencyJWTSecret, cfg.JWT.ExpireHours)
+	bookingSvc.SetAgencyChannel(agencySvc)
+	agencyAllotmentScheduler := service.NewAgencyAllotmentScheduler(agencySvc, time.Minute)
+	agencyAllotmentScheduler.Start()
+	defer agencyAllotmentScheduler.Stop()
+	agencyHandler := handler.NewAgencyHandler(agencySvc)
+	agencyPortalHandler := handler.NewAgencyPortalHandler(agencySvc, bookingSvc)
+	// 售罄候补：库存释放时为排队用户独占占座 30 分钟并写入通知发件箱
+	waitlistSvc := service.NewWaitlistService(repository.NewWaitlistRepository(db), cabinRepo, 30*time.Minute)
+	bookingSvc.SetWaitlist(waitlistSvc)
+	cabinAdminSvc.SetReleaseListener(waitlistSvc)
+	waitlistOfferScheduler := service.NewWaitlistOfferScheduler(waitlistSvc, time.Minute)
+	waitlistOfferScheduler.Start()
+	defer waitlistOfferScheduler.Stop()
+	waitlistHandler := handler.NewWaitlistHandler(waitlistSvc)
+	// 小程序登录：服务端以 code2session 换取 OpenID，登录标识与账号绑定持久化在 users 表
+	wechatAuthClient := service.NewWechatAuthClient(service.WechatClientConfig{
+		AppID:     cfg.Wechat.AppID,
+		AppSecret: cfg.Wechat.AppSecret,
+		Endpoint:  cfg.Wechat.Endpoint,
+		Timeout:   time.Duration(cfg.Wechat.TimeoutSeconds) * time.Second,
+	})
+	// 认证风控状态与限流令牌桶可选用 Redis，两者共用同一个连接
+	var redisClient *redis.Client
+	if cfg.AuthState.Store == "redis" || (!cfg.RateLimit.Disabled && cfg.RateLimit.Store == "redis") {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		defer func() { _ = redisClient.Close() }()
+	}
+	// 短信重发间隔、失败锁定与绑定窗口存放在共享存储中，多副本部署时各实例状态一致
+	var authStates domain.AuthStateStore = repository.NewAuthStateRepository(db)
+	if cfg.AuthState.Store == "redis" {
+		authStates = repository.NewRedisAuthStateStore(redisClient, cfg.AuthState.KeyPrefix)
+	}
+	authStateCleanupScheduler := service.NewAuthStateCleanupScheduler(authStates, time.Duration(cfg.AuthState.CleanupIntervalMinutes)*time.Minute)
+	authStateCleanupScheduler.Start()
+	defer authStateCleanupScheduler.Stop()
+	// 验证码同样存放在共享存储中，在一个副本发出的验证码可以在任一副本校验
+	smsCodeTTL := 5 * time.Minute
+	userAuthSvc := service.NewUserAuthServiceWithPolicy(service.NewAuthStateCodeStore(authStates, smsCodeTTL), service.UserAuthPolicy{
+		CodeTTL:  smsCodeTTL,
+		Accounts: userRepo,
+		Wechat:   wechatAuthClient,
+		States:   authStates,
+	})
 	userHandler := handler.NewUserHandlerWithRepo(userAuthSvc, userRepo, cfg.JWT.Secret) // M-03
+	userHandler.SetWechatAuth(userAuthSvc)
+	// 用户自助服务：资料编辑、换绑手机、注销（冷静期后匿名化）与个人数据导出
+	userAccountSvc := service.NewUserAccountService(userRepo, userAuthSvc, service.DefaultAccountDeletionCoolingOff)
+	userHandler.SetAccountService(userAccountSvc)
+	accountDeletionScheduler := service.NewAccountDeletionScheduler(userAccountSvc, time.Hour)
+	accountDeletionScheduler.Start()
+	defer accountDeletionScheduler.Stop()
 	staffRoleSync := service.NewCasbinStaffRoleSync(enforcer)
 	staffAuditLogger := service.NewStaffOperationLogger(operationLogRepo)
 	staffSvc := service.NewStaffServiceWithDeps(staffRepo, staffRoleSync, staffAuditLogger)
+	staffSvc.SetSecurityPolicy(staffSecurityPolicy)
+	staffCompanyRepo := repository.NewStaffCompanyRepository(db)
+	staffSvc.SetCompanyScope(staffCompanyRepo, companyRepo)
+	authSvc.SetStaffManageGuard(staffSvc)
 	shopInfoSvc := service.NewShopInfoService(shopInfoRepo)
 	notifyTplSvc := service.NewNotificationTemplateService(notifyTplRepo)
 	contentTemplateSvc := service.NewContentTemplateService(contentTemplateRepo)
 	customDestSvc := service.NewCustomDestinationService(customDestRepo)
+	// 异步批量导入任务：任务在进程内执行，启动时把上次退出前未完成的任务标记为失败
+	importJobSvc := service.NewImportJobService(repository.NewImportJobRepository(db), 2)
+	importJobSvc.Register(service.ImportKindCustomDestinations, customDestSvc.RunImport)
+	if n, err := importJobSvc.RecoverInterrupted(context.Background()); err != nil {
+		zap.L().Error("import job: recover interrupted jobs failed", zap.Error(err))
+	} else if n > 0 {
+		zap.L().Info("import job: marked interrupted jobs as failed", zap.Int64("count", n))
+	}
+	defer importJobSvc.Wait()
+	// 航线地图与外部地理编码结果持久化缓存，过期记录每小时清理一次
+	routeMapCacheRepo := repository.NewRouteMapCacheRepository(db)
+	geocodeCacheRepo := repository.NewGeocodeCacheRepository(db)
+	geoCacheCleanupScheduler := service.NewGeoCacheCleanupScheduler(time.Hour, routeMapCacheRepo, geocodeCacheRepo)
+	geoCacheCleanupScheduler.Start()
+	defer geoCacheCleanupScheduler.Stop()
 	portCitySvc := service.NewPortCityService(service.PortCityServiceConfig{
 		Endpoint: cfg.CitySearch.Endpoint,
 		Timeout:  time.Duration(cfg.CitySearch.TimeoutSeconds) * time.Second,
+		CacheTTL: time.Duration(cfg.CitySearch.CacheTTLHours) * time.Hour,
 	})
-	portCitySvc.SetCustomDestinationRepo(customDestRepo)
+	portCitySvc.SetPortRepo(portRepo).SetCustomDestinationRepo(customDestRepo).SetGeocodeCache(geocodeCacheRepo)
+	portSvc := service.NewPortService(portRepo)
 	seaRouteClient := service.NewSeaRouteClient(service.SeaRouteClientConfig{
-		Endpoint:     cfg.MaritimeRoute.Endpoint,
-		Timeout:      time.Duration(cfg.MaritimeRoute.TimeoutSeconds) * time.Second,
-		ResolutionKM: cfg.MaritimeRoute.ResolutionKM,
-	})
-	voyageSvc := service.NewVoyageService(voyageRepo, seaRouteClient).SetCityResolver(portCitySvc)
+		Endpoint:        cfg.MaritimeRoute.Endpoint,
+		Timeout:         time.Duration(cfg.MaritimeRoute.TimeoutSeconds) * time.Second,
+		ResolutionKM:    cfg.MaritimeRoute.ResolutionKM,
+		DisableOffline:  cfg.MaritimeRoute.DisableOffline,
+		OfflineCacheTTL: time.Duration(cfg.MaritimeRoute.OfflineCacheTTLHours) * time.Hour,
+	}).SetPortLookup(portRepo).SetRouteCache(routeMapCacheRepo)
+	voyageSvc := service.NewVoyageService(voyageRepo, seaRouteClient).SetCityResolver(portCitySvc).SetRouteCache(seaRouteClient)
 	voyageHandler := handler.NewVoyageHandler(voyageSvc)
 	portCityHandler := handler.NewPortCityHandler(portCitySvc)
 	staffHandler := handler.NewStaffHandler(staffSvc)
+	staffHandler.SetSecurityService(authSvc)
+	staffHandler.SetCompanyService(staffSvc)
 	shopInfoHandler := handler.NewShopInfoHandler(shopInfoSvc)
 	notifyTplHandler := handler.NewNotificationTemplateHandler(notifyTplSvc)
 	contentTemplateHandler := handler.NewContentTemplateHandler(contentTemplateSvc)
 	customDestHandler := handler.NewCustomDestinationHandler(customDestSvc)
+	customDestHandler.SetImportJobs(importJobSvc)
+	importJobHandler := handler.NewImportJobHandler(importJobSvc)
+	portHandler := handler.NewPortHandler(portSvc)
+	voyageSeriesSvc := service.NewVoyageSeriesService(repository.NewVoyageSeriesRepository(db), voyageRepo, cabinRepo, voyageCabinTypePriceRepo, cabinTypeBindingRepo)
+	voyageSeriesHandler := handler.NewVoyageSeriesHandler(voyageSeriesSvc)
+	voyageCloneSvc := service.NewVoyageCloneService(repository.NewVoyageDraftRepository(db), voyageRepo, cabinRepo, voyageCabinTypePriceRepo)
+	voyageCloneHandler := handler.NewVoyageCloneHandler(voyageCloneSvc)
+	voyageDisruptionSvc := service.NewVoyageDisruptionService(repository.NewVoyageDisruptionRepository(db), voyageRepo, cabinRepo, pricingSvc)
+	voyageDisruptionHandler := handler.NewVoyageDisruptionHandler(voyageDisruptionSvc)
+	cabinImportSvc := service.NewCabinImportService(repository.NewCabinImportRepository(db), voyageRepo, cabinRepo, cabinTypeBindingRepo)
+	cabinImportSvc.SetReleaseListener(waitlistSvc)
+	cabinImportHandler := handler.NewCabinImportHandler(cabinImportSvc, meiliIndexer, searchRetryQueue)
+	deckPlanSvc := service.NewDeckPlanService(repository.NewDeckPlanRepository(db), voyageRepo, cabinRepo, cruiseRepo)
+	deckPlanHandler := handler.NewDeckPlanHandler(deckPlanSvc)
 
 	// Sprint 04: 支付 / 退款 / 通知 / 统计分析 依赖注入
 	paymentRepo := repository.NewPaymentRepository(db)
@@ -198,6 +337,80 @@ func RunApp(configDir string) error {
 	paymentHandler := handler.NewPaymentHandler(payCallbackSvc)
 	refundHandler := handler.NewRefundHandler(refundSvc)
 	analyticsHandler := handler.NewAnalyticsHandler(analyticsSvc)
+	analyticsHandler.SetReportService(analyticsSvc)
+
+	// 后台写操作审计：按资源注册快照读取函数，用于记录变更前后差异
+	operationAuditSvc := service.NewOperationAuditService(operationLogRepo).
+		RegisterSnapshot("companies", service.AuditSnapshotOf(companySvc.GetByID)).
+		RegisterSnapshot("cruises", service.AuditSnapshotOf(cruiseSvc.GetByID)).
+		RegisterSnapshot("cabin-types", service.AuditSnapshotOf(cabinTypeSvc.GetByID)).
+		RegisterSnapshot("voyages", service.AuditSnapshotOf(voyageSvc.GetByID)).
+		RegisterSnapshot("ports", service.AuditSnapshotOf(portSvc.Get)).
+		RegisterSnapshot("bookings", service.AuditSnapshotOf(bookingRepo.GetByID)).
+		RegisterSnapshot("staffs", service.AuditSnapshotOf(staffRepo.GetByID)).
+		RegisterSnapshot("cabins", func(ctx context.Context, id int64) (any, error) {
+			sku, err := cabinRepo.GetSKUByID(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			inventory, _ := cabinRepo.GetInventoryBySKU(ctx, id)
+			prices, _ := cabinRepo.ListPricesBySKU(ctx, id)
+			return map[string]any{"sku": sku, "inventory": inventory, "prices": prices}, nil
+		})
+
+	// 接口限流：登录、验证码按 IP 严格限制，其余接口按分组限制，规则可在 config.yaml 的 rate_limit 中覆盖
+	var rateLimiter *middleware.RateLimiter
+	if !cfg.RateLimit.Disabled {
+		var rateLimitStore domain.RateLimitStore = repository.NewMemoryRateLimitStore()
+		if cfg.RateLimit.Store == "redis" {
+			rateLimitStore = repository.NewRedisRateLimitStore(redisClient, cfg.RateLimit.KeyPrefix)
+		}
+		rules := make([]middleware.RateLimitRule, 0, len(cfg.RateLimit.Rules))
+		for _, rule := range cfg.RateLimit.Rules {
+			rules = append(rules, middleware.RateLimitRule{
+				Name:   rule.Name,
+				Method: rule.Method,
+				Path:   rule.Path,
+				By:     rule.By,
+				Limit: domain.RateLimit{
+					Requests: rule.Requests,
+					Period:   time.Duration(rule.PeriodSeconds) * time.Second,
+					Burst:    rule.Burst,
+				},
+			})
+		}
+		rateLimiter = middleware.NewRateLimiter(rateLimitStore, rules)
+	}
+
+	// 下单、退款接口的 Idempotency-Key 记录保存在数据库中，多副本部署时共享
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	idempotencyCleanupScheduler := service.NewIdempotencyCleanupScheduler(idempotencyRepo, time.Duration(cfg.Idempotency.CleanupIntervalMinutes)*time.Minute)
+	idempotencyCleanupScheduler.Start()
+	defer idempotencyCleanupScheduler.Stop()
+
+	// 业务状态指标在每次抓取时查询
+	var metricsHandler http.Handler
+	if !cfg.Metrics.Disabled && cfg.Metrics.Token == "" && !cfg.Metrics.Public {
+		zap.L().Warn("metrics: /metrics not registered because metrics.token is empty; set a token or metrics.public=true")
+	} else if !cfg.Metrics.Disabled {
+		err = errors.Join(
+			metrics.RegisterGauge("search_retry_queue_length", "Search index tasks waiting in the retry queue.", func(context.Context) (float64, error) {
+				return float64(searchRetryQueue.Len()), nil
+			}),
+			metrics.RegisterGauge("cabin_holds_active", "Cabin holds that have not expired.", func(ctx context.Context) (float64, error) {
+				n, err := holdRepo.CountActive(ctx, time.Now())
+				return float64(n), err
+			}),
+			metrics.RegisterGauge("notifications_pending", "Outbox notifications waiting to be delivered.", func(ctx context.Context) (float64, error) {
+				n, err := notifRepo.CountPending(ctx)
+				return float64(n), err
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("注册业务指标失败: %w", err)
+		}
+		metricsHandler = metrics.Handler(cfg.Metrics.Token)
+	}
 
 	// 8. 配置路由并启动 HTTP 服务器
 	r := router.Setup(router.Dependencies{
@@ -206,6 +419,10 @@ func RunApp(configDir string) error {
 		Cruise:            cruiseHandler,
 		CabinType:         cabinTypeHandler,
 		CabinPricing:      cabinPricingHandler,
+		DynamicPricing:    dynamicPricingHandler,
+		Agency:            agencyHandler,
+		Waitlist:          waitlistHandler,
+		AgencyPortal:      agencyPortalHandler,
 		CabinTypeCategory: cabinTypeCategoryHandler,
 		CabinTypeMedia:    cabinTypeMediaHandler,
 		FacilityCategory:  facilityCategoryHandler,
@@ -225,8 +442,30 @@ func RunApp(configDir string) error {
 		NotificationTpl:   notifyTplHandler,
 		ContentTemplate:   contentTemplateHandler,
 		CustomDestination: customDestHandler,
+		Port:              portHandler,
+		VoyageSeries:      voyageSeriesHandler,
+		VoyageClone:       voyageCloneHandler,
+		VoyageDisruption:  voyageDisruptionHandler,
+		CabinImport:       cabinImportHandler,
+		ImportJob:         importJobHandler,
+		DeckPlan:          deckPlanHandler,
+		OperationLog:      handler.NewOperationLogHandler(operationAuditSvc),
 		JWTSecret:         cfg.JWT.Secret,
+		AgencyJWTSecret:   agencyJWTSecret,
+		AgencyAPIKeys:     agencySvc,
+		AgencyStatus:      agencySvc,
 		Enforcer:          enforcer,
+		AuditRecorder:     operationAuditSvc,
+		CompanyScope:      staffCompanyRepo,
+		RateLimiter:       rateLimiter,
+		Idempotency: middleware.IdempotencyConfig{
+			Store:       idempotencyRepo,
+			TTL:         time.Duration(cfg.Idempotency.TTLHours) * time.Hour,
+			LockTimeout: time.Duration(cfg.Idempotency.LockSeconds) * time.Second,
+		},
+		Logger:         appLogger,
+		Metrics:        metricsHandler,
+		TrustedProxies: cfg.Server.TrustedProxies,
 	})
 
 	log.Printf("服务启动于 %s（模式: %s）", cfg.Server.Port, cfg.Server.Mode)
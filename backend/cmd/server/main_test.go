@@ -2,12 +2,14 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMain_Exit(t *testing.T) {
+	t.Setenv("CRUISE_LOG_FILENAME", filepath.Join(t.TempDir(), "app.log"))
 	// Override osExit to avoid exiting the test panic
 	exited := false
 	osExit = func(code int) {
@@ -27,6 +29,7 @@ func TestMain_Exit(t *testing.T) {
 }
 
 func TestRunApp_Success(t *testing.T) {
+	t.Setenv("CRUISE_LOG_FILENAME", filepath.Join(t.TempDir(), "app.log"))
 	os.Setenv("CRUISE_DATABASE_HOST", "sqlite") // trigger sqlite connection!
 	defer os.Unsetenv("CRUISE_DATABASE_HOST")
 	os.Setenv("CRUISE_SERVER_PORT", "invalid-port") // fail at r.Run but pass all setups
@@ -37,6 +40,7 @@ func TestRunApp_Success(t *testing.T) {
 }
 
 func TestRunApp_CasbinError(t *testing.T) {
+	t.Setenv("CRUISE_LOG_FILENAME", filepath.Join(t.TempDir(), "app.log"))
 	os.Setenv("CRUISE_DATABASE_HOST", "sqlite")
 	defer os.Unsetenv("CRUISE_DATABASE_HOST")
 
@@ -23,12 +23,103 @@ type Config struct {
 	Log           LogConfig           // 日志配置
 	Upload        UploadConfig        // 本地上传配置
 	MaritimeRoute MaritimeRouteConfig // 海上路由服务配置
+	Wechat        WechatConfig        // 微信小程序登录配置
+	AuthState     AuthStateConfig     `mapstructure:"auth_state"`     // 认证风控状态存储配置
+	StaffSecurity StaffSecurityConfig `mapstructure:"staff_security"` // 员工账号安全策略
+	RateLimit     RateLimitConfig     `mapstructure:"rate_limit"`     // 接口限流配置
+	Idempotency   IdempotencyConfig   `mapstructure:"idempotency"`    // 下单、退款接口的 Idempotency-Key 幂等配置
+	Tracing       TracingConfig       // 链路追踪配置
+	Metrics       MetricsConfig       // Prometheus 指标配置
+}
+
+// MetricsConfig 定义 /metrics 指标端点。
+type MetricsConfig struct {
+	Disabled bool   // 为 true 时不注册 /metrics
+	Token    string // 抓取需携带 Authorization: Bearer <Token>；为空且未开启 Public 时不注册 /metrics
+	Public   bool   // 为 true 时允许在未配置 Token 的情况下公开 /metrics，仅用于已在网络层隔离的部署
+}
+
+// TracingConfig 定义 OpenTelemetry 链路追踪的导出方式，traceparent 上下文透传始终开启。
+type TracingConfig struct {
+	ServiceName string  // 上报的服务名，默认 cruise-backend
+	Exporter    string  // 导出方式："none"（默认）、"stdout" 或 "file"
+	Filename    string  // Exporter 为 file 时的文件路径，默认 logs/trace.log
+	SampleRatio float64 // 根 span 采样比例（0~1），默认 1；上游已采样的请求始终跟随上游
+}
+
+// IdempotencyConfig 定义 Idempotency-Key 幂等记录的保留与清理策略。
+type IdempotencyConfig struct {
+	TTLHours               int // 首次响应的保留时长（小时），期间的重试直接重放，默认 24
+	LockSeconds            int // 处理中记录的占用时长（秒），超时后允许重试，默认 60
+	CleanupIntervalMinutes int // 过期记录清理间隔（分钟），默认 10
+}
+
+// RateLimitConfig 定义接口令牌桶限流。每个请求只应用路径前缀最长（其次指定了方法）的一条规则，
+// 同一规则下的路由共享一个桶；未配置 Rules 时使用 DefaultRateLimitRules。
+type RateLimitConfig struct {
+	Disabled  bool                  // 为 true 时关闭限流
+	Store     string                // 令牌桶存储："memory"（单实例）或 "redis"（多副本共享）
+	KeyPrefix string                // Redis 键前缀
+	Rules     []RateLimitRuleConfig // 限流规则
+}
+
+// RateLimitRuleConfig 是一条限流规则：每 PeriodSeconds 秒补充 Requests 个令牌，桶容量为 Burst。
+type RateLimitRuleConfig struct {
+	Name          string // 规则名称，同时作为令牌桶键的一部分，需唯一
+	Method        string // HTTP 方法，为空时匹配所有方法
+	Path          string // 路径前缀，按路径段匹配（如 /api/v1/admin）
+	By            string // 计数维度："ip" 或 "user"（已认证的员工/用户/分销商，仅对需登录的路由生效）
+	Requests      int    // 每周期补充的令牌数
+	PeriodSeconds int    // 补充周期（秒）
+	Burst         int    // 桶容量，为 0 时等于 Requests
+}
+
+// DefaultRateLimitRules 返回内置限流规则：登录与验证码接口按 IP 严格限制，其余接口按分组限制。
+func DefaultRateLimitRules() []RateLimitRuleConfig {
+	return []RateLimitRuleConfig{
+		{Name: "api", Path: "/api/v1", By: "ip", Requests: 20, PeriodSeconds: 1, Burst: 60},
+		{Name: "admin", Path: "/api/v1/admin", By: "user", Requests: 20, PeriodSeconds: 1, Burst: 60},
+		{Name: "admin-login", Method: "POST", Path: "/api/v1/admin/auth", By: "ip", Requests: 10, PeriodSeconds: 60, Burst: 10},
+		{Name: "sms-code", Method: "POST", Path: "/api/v1/users/sms-code", By: "ip", Requests: 5, PeriodSeconds: 60, Burst: 5},
+		{Name: "user-login", Method: "POST", Path: "/api/v1/users/login", By: "ip", Requests: 10, PeriodSeconds: 60, Burst: 10},
+		{Name: "wx-login", Method: "POST", Path: "/api/v1/users/wx-login", By: "ip", Requests: 10, PeriodSeconds: 60, Burst: 10},
+		{Name: "agency-login", Method: "POST", Path: "/api/v1/agency/auth/login", By: "ip", Requests: 10, PeriodSeconds: 60, Burst: 10},
+	}
+}
+
+// StaffSecurityConfig 定义员工密码复杂度、历史与有效期、登录锁定及 TOTP 二次验证策略，数值为 0 时使用内置默认值。
+type StaffSecurityConfig struct {
+	MinPasswordLength  int      // 密码最小长度，默认 10
+	MinCharClasses     int      // 至少包含的字符类别数（大写/小写/数字/符号），默认 3
+	PasswordHistory    int      // 禁止重复使用的最近历史密码个数，默认 5
+	PasswordMaxAgeDays int      // 密码有效期（天），默认 90
+	MaxFailedLogins    int      // 连续失败锁定阈值，默认 5
+	LockMinutes        int      // 锁定时长（分钟），默认 15
+	ResetTokenTTLHours int      // 重置令牌有效期（小时），默认 24
+	TOTPRequiredRoles  []string // 强制启用 TOTP 的角色，默认 super_admin、finance
+	TOTPIssuer         string   // 验证器 App 中显示的签发方名称
+}
+
+// AuthStateConfig 定义短信重发间隔、失败锁定与绑定窗口等认证风控状态的存储方式。
+type AuthStateConfig struct {
+	Store                  string // 存储后端："sql"（auth_states 表）或 "redis"
+	KeyPrefix              string // Redis 键前缀
+	CleanupIntervalMinutes int    // 过期状态清理间隔（分钟），仅 sql 后端需要
+}
+
+// WechatConfig 定义微信小程序登录（code2session）配置。
+type WechatConfig struct {
+	AppID          string // 小程序 AppID
+	AppSecret      string // 小程序 AppSecret
+	Endpoint       string // 微信开放接口地址
+	TimeoutSeconds int    // 请求超时秒数
 }
 
 // CitySearchConfig 定义外部城市搜索服务配置。
 type CitySearchConfig struct {
 	Endpoint       string // 城市搜索 API 地址
 	TimeoutSeconds int    // 请求超时秒数
+	CacheTTLHours  int    // 搜索结果缓存时长（小时）
 }
 
 // MaritimeRouteConfig 定义外部海上路由服务配置。
@@ -36,6 +127,9 @@ type MaritimeRouteConfig struct {
 	Endpoint       string // SeaRoute WebService 地址
 	TimeoutSeconds int    // 请求超时秒数
 	ResolutionKM   int    // 路由网络分辨率（km）
+	DisableOffline bool   // 为 true 时关闭内置离线航线兜底
+	// OfflineCacheTTLHours 为外部服务不可用时离线兜底航线的缓存时长（小时），到期后重试外部服务
+	OfflineCacheTTLHours int
 }
 
 // UploadConfig 定义本地文件上传配置。
@@ -49,6 +143,8 @@ type UploadConfig struct {
 type ServerConfig struct {
 	Port string // 监听端口（如 ":8080"）
 	Mode string // 运行模式（"debug" / "release"）
+	// TrustedProxies 为可信反向代理的 IP 或 CIDR，只信任来自这些地址的 X-Forwarded-For；为空时使用 TCP 连接地址
+	TrustedProxies []string
 }
 
 // DatabaseConfig 定义 PostgreSQL 数据库连接参数。
@@ -137,6 +233,11 @@ func Load(root string) Config {
 	applyUploadDefaults(&cfg)
 	applyCitySearchDefaults(&cfg)
 	applyMaritimeRouteDefaults(&cfg)
+	applyWechatDefaults(&cfg)
+	applyAuthStateDefaults(&cfg)
+	applyRateLimitDefaults(&cfg)
+	applyIdempotencyDefaults(&cfg)
+	applyTracingDefaults(&cfg)
 
 	return cfg
 }
@@ -203,6 +304,9 @@ func applyCitySearchDefaults(cfg *Config) {
 	if cfg.CitySearch.TimeoutSeconds <= 0 {
 		cfg.CitySearch.TimeoutSeconds = 8
 	}
+	if cfg.CitySearch.CacheTTLHours <= 0 {
+		cfg.CitySearch.CacheTTLHours = 168
+	}
 }
 
 func applyMaritimeRouteDefaults(cfg *Config) {
@@ -215,4 +319,84 @@ func applyMaritimeRouteDefaults(cfg *Config) {
 	if cfg.MaritimeRoute.ResolutionKM <= 0 {
 		cfg.MaritimeRoute.ResolutionKM = 20
 	}
+	if cfg.MaritimeRoute.OfflineCacheTTLHours <= 0 {
+		cfg.MaritimeRoute.OfflineCacheTTLHours = 6
+	}
+}
+
+func applyWechatDefaults(cfg *Config) {
+	if cfg == nil {
+		return
+	}
+	if strings.TrimSpace(cfg.Wechat.Endpoint) == "" {
+		cfg.Wechat.Endpoint = "https://api.weixin.qq.com"
+	}
+	if cfg.Wechat.TimeoutSeconds <= 0 {
+		cfg.Wechat.TimeoutSeconds = 5
+	}
+}
+
+// applyAuthStateDefaults 为认证风控状态存储设置默认值：默认使用数据库，多副本部署无需额外组件。
+func applyAuthStateDefaults(cfg *Config) {
+	cfg.AuthState.Store = strings.ToLower(strings.TrimSpace(cfg.AuthState.Store))
+	if cfg.AuthState.Store == "" {
+		cfg.AuthState.Store = "sql"
+	}
+	if cfg.AuthState.KeyPrefix == "" {
+		cfg.AuthState.KeyPrefix = "cruise:auth:"
+	}
+	if cfg.AuthState.CleanupIntervalMinutes <= 0 {
+		cfg.AuthState.CleanupIntervalMinutes = 10
+	}
+}
+
+// applyRateLimitDefaults 为限流设置默认值：默认使用内存存储与内置规则，规则缺省维度按 IP、缺省周期为 1 秒。
+func applyRateLimitDefaults(cfg *Config) {
+	cfg.RateLimit.Store = strings.ToLower(strings.TrimSpace(cfg.RateLimit.Store))
+	if cfg.RateLimit.Store == "" {
+		cfg.RateLimit.Store = "memory"
+	}
+	if cfg.RateLimit.KeyPrefix == "" {
+		cfg.RateLimit.KeyPrefix = "cruise:ratelimit:"
+	}
+	if len(cfg.RateLimit.Rules) == 0 {
+		cfg.RateLimit.Rules = DefaultRateLimitRules()
+	}
+	for i := range cfg.RateLimit.Rules {
+		rule := &cfg.RateLimit.Rules[i]
+		rule.By = strings.ToLower(strings.TrimSpace(rule.By))
+		if rule.By == "" {
+			rule.By = "ip"
+		}
+		if rule.PeriodSeconds <= 0 {
+			rule.PeriodSeconds = 1
+		}
+	}
+}
+
+func applyIdempotencyDefaults(cfg *Config) {
+	if cfg.Idempotency.TTLHours <= 0 {
+		cfg.Idempotency.TTLHours = 24
+	}
+	if cfg.Idempotency.LockSeconds <= 0 {
+		cfg.Idempotency.LockSeconds = 60
+	}
+	if cfg.Idempotency.CleanupIntervalMinutes <= 0 {
+		cfg.Idempotency.CleanupIntervalMinutes = 10
+	}
+}
+
+func applyTracingDefaults(cfg *Config) {
+	if strings.TrimSpace(cfg.Tracing.ServiceName) == "" {
+		cfg.Tracing.ServiceName = "cruise-backend"
+	}
+	if strings.TrimSpace(cfg.Tracing.Exporter) == "" {
+		cfg.Tracing.Exporter = "none"
+	}
+	if strings.TrimSpace(cfg.Tracing.Filename) == "" {
+		cfg.Tracing.Filename = "logs/trace.log"
+	}
+	if cfg.Tracing.SampleRatio <= 0 || cfg.Tracing.SampleRatio > 1 {
+		cfg.Tracing.SampleRatio = 1
+	}
 }
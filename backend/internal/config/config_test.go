@@ -174,6 +174,122 @@ upload:
 	}
 }
 
+func TestLoadWechatConfigDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	requireFile(t, tmpDir, "config.yaml", []byte(`
+wechat:
+  appid: "wx123"
+`))
+
+	cfg := Load(tmpDir)
+	if cfg.Wechat.AppID != "wx123" {
+		t.Fatalf("expected wechat appid wx123, got %q", cfg.Wechat.AppID)
+	}
+	if cfg.Wechat.Endpoint != "https://api.weixin.qq.com" || cfg.Wechat.TimeoutSeconds != 5 {
+		t.Fatalf("expected wechat defaults, got %+v", cfg.Wechat)
+	}
+}
+
+func TestLoadAuthStateConfigDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	requireFile(t, tmpDir, "config.yaml", []byte(`
+auth_state:
+  store: " Redis "
+`))
+
+	cfg := Load(tmpDir)
+	if cfg.AuthState.Store != "redis" {
+		t.Fatalf("expected normalized store redis, got %q", cfg.AuthState.Store)
+	}
+	if cfg.AuthState.KeyPrefix != "cruise:auth:" || cfg.AuthState.CleanupIntervalMinutes != 10 {
+		t.Fatalf("expected auth state defaults, got %+v", cfg.AuthState)
+	}
+}
+
+func TestLoadStaffSecurityConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	requireFile(t, tmpDir, "config.yaml", []byte(`
+staff_security:
+  maxfailedlogins: 3
+  lockminutes: 30
+  totprequiredroles: ["super_admin"]
+`))
+
+	cfg := Load(tmpDir)
+	if cfg.StaffSecurity.MaxFailedLogins != 3 || cfg.StaffSecurity.LockMinutes != 30 {
+		t.Fatalf("expected lockout settings to load, got %+v", cfg.StaffSecurity)
+	}
+	if len(cfg.StaffSecurity.TOTPRequiredRoles) != 1 || cfg.StaffSecurity.TOTPRequiredRoles[0] != "super_admin" {
+		t.Fatalf("expected totp roles to load, got %v", cfg.StaffSecurity.TOTPRequiredRoles)
+	}
+}
+
+func TestLoadRateLimitConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	requireFile(t, tmpDir, "config.yaml", []byte(`
+server:
+  port: ":8080"
+`))
+	cfg := Load(tmpDir)
+	if cfg.RateLimit.Disabled || cfg.RateLimit.Store != "memory" || cfg.RateLimit.KeyPrefix != "cruise:ratelimit:" {
+		t.Fatalf("expected rate limit defaults, got %+v", cfg.RateLimit)
+	}
+	if len(cfg.RateLimit.Rules) != len(DefaultRateLimitRules()) {
+		t.Fatalf("expected built-in rules, got %+v", cfg.RateLimit.Rules)
+	}
+
+	requireFile(t, tmpDir, "config.yaml", []byte(`
+rate_limit:
+  store: "REDIS"
+  rules:
+    - name: "sms-code"
+      method: "POST"
+      path: "/api/v1/users/sms-code"
+      requests: 1
+      periodseconds: 60
+    - name: "admin"
+      path: "/api/v1/admin"
+      by: " User "
+      requests: 5
+      burst: 10
+`))
+	cfg = Load(tmpDir)
+	if cfg.RateLimit.Store != "redis" || len(cfg.RateLimit.Rules) != 2 {
+		t.Fatalf("expected configured rules to replace defaults, got %+v", cfg.RateLimit)
+	}
+	sms, admin := cfg.RateLimit.Rules[0], cfg.RateLimit.Rules[1]
+	if sms.By != "ip" || sms.Requests != 1 || sms.PeriodSeconds != 60 || sms.Method != "POST" {
+		t.Fatalf("unexpected sms rule %+v", sms)
+	}
+	if admin.By != "user" || admin.PeriodSeconds != 1 || admin.Burst != 10 {
+		t.Fatalf("unexpected admin rule %+v", admin)
+	}
+}
+
+func TestLoadIdempotencyConfigDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	requireFile(t, tmpDir, "config.yaml", []byte(`
+idempotency:
+  ttlhours: 48
+`))
+	cfg := Load(tmpDir)
+	if cfg.Idempotency.TTLHours != 48 || cfg.Idempotency.LockSeconds != 60 || cfg.Idempotency.CleanupIntervalMinutes != 10 {
+		t.Fatalf("expected idempotency settings with defaults, got %+v", cfg.Idempotency)
+	}
+}
+
+func TestLoadTracingConfigDefaults(t *testing.T) {
+	tmpDir := t.TempDir()
+	requireFile(t, tmpDir, "config.yaml", []byte(`
+tracing:
+  exporter: "file"
+`))
+	cfg := Load(tmpDir)
+	if cfg.Tracing.Exporter != "file" || cfg.Tracing.Filename != "logs/trace.log" || cfg.Tracing.ServiceName != "cruise-backend" || cfg.Tracing.SampleRatio != 1 {
+		t.Fatalf("expected tracing settings with defaults, got %+v", cfg.Tracing)
+	}
+}
+
 func requireFile(t *testing.T, dir, name string, content []byte) {
 	err := os.WriteFile(filepath.Join(dir, name), content, 0644)
 	if err != nil {
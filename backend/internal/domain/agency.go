@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	AgencyStatementStatusIssued = "issued" // 已出账待结算
+	AgencyStatementStatusPaid   = "paid"   // 已结清
+)
+
+var (
+	// ErrAllotmentExhausted 表示分销商配额已用完、已释放或已过释放日期。
+	ErrAllotmentExhausted = errors.New("agency allotment exhausted")
+	// ErrAgencyCreditExceeded 表示本次下单将超出分销商信用额度。
+	ErrAgencyCreditExceeded = errors.New("agency credit limit exceeded")
+	// ErrAgencyStatementExists 表示该账期的月结账单已生成。
+	ErrAgencyStatementExists = errors.New("agency statement already exists for period")
+	// ErrAgencyStatementNotIssued 表示账单不处于待结算状态。
+	ErrAgencyStatementNotIssued = errors.New("agency statement is not issued")
+)
+
+// Agency 表示 B2B 分销商（旅行社）账户。
+// 分销商以账户名 + 密码登录分销端，或使用 API Key 进行系统对接。
+type Agency struct {
+	ID                int64      `gorm:"primaryKey" json:"id"`                  // 主键 ID
+	Code              string     `gorm:"size:50;uniqueIndex" json:"code"`       // 分销商编码（唯一）
+	Name              string     `gorm:"size:100;not null" json:"name"`         // 分销商名称
+	ContactName       string     `gorm:"size:50" json:"contact_name"`           // 联系人
+	ContactPhone      string     `gorm:"size:20" json:"contact_phone"`          // 联系电话
+	Email             string     `gorm:"size:100" json:"email"`                 // 电子邮箱
+	UserID            int64      `gorm:"index" json:"user_id"`                  // 渠道下单用户 ID，分销订单均记在该用户名下
+	LoginName         string     `gorm:"size:50;uniqueIndex" json:"login_name"` // 分销端登录账户名（唯一）
+	PasswordHash      string     `gorm:"size:255" json:"-"`                     // 登录密码的 bcrypt 哈希
+	CommissionPercent float64    `gorm:"default:0" json:"commission_percent"`   // 默认佣金比例：净价 = 售价 × (1 - 比例)
+	CreditLimitCents  int64      `gorm:"default:0" json:"credit_limit_cents"`   // 信用额度（分）
+	Status            int16      `gorm:"default:1" json:"status"`               // 状态：1=启用，0=停用
+	LastLoginAt       *time.Time `json:"last_login_at,omitempty"`               // 最后登录时间
+	CreatedAt         time.Time  `json:"created_at"`                            // 创建时间
+	UpdatedAt         time.Time  `json:"updated_at"`                            // 更新时间
+	DeletedAt         *time.Time `gorm:"index" json:"deleted_at,omitempty"`     // 软删除时间
+}
+
+// AgencyAPIKey 表示分销商系统对接用的 API Key，仅保存哈希值。
+type AgencyAPIKey struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`              // 主键 ID
+	AgencyID   int64      `gorm:"index;not null" json:"agency_id"`   // 所属分销商 ID
+	Name       string     `gorm:"size:100" json:"name"`              // 用途说明
+	Prefix     string     `gorm:"size:20;uniqueIndex" json:"prefix"` // Key 前缀，用于定位记录与界面展示
+	KeyHash    string     `gorm:"size:64;not null" json:"-"`         // 完整 Key 的 SHA-256 十六进制摘要
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`            // 最近使用时间
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`              // 吊销时间
+	CreatedAt  time.Time  `json:"created_at"`                        // 创建时间
+}
+
+// AgencyAllotment 表示为分销商在某航次预留的舱房配额。
+// 创建时即从舱房库存中扣减 Quantity，到达 ReleaseAt 后未使用部分自动退回库存。
+type AgencyAllotment struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`                             // 主键 ID
+	AgencyID   int64      `gorm:"index;not null" json:"agency_id"`                  // 分销商 ID
+	VoyageID   int64      `gorm:"index;not null" json:"voyage_id"`                  // 航次 ID
+	CabinSKUID int64      `gorm:"column:cabin_sku_id;not null" json:"cabin_sku_id"` // 舱房 SKU ID
+	Quantity   int        `gorm:"not null" json:"quantity"`                         // 预留数量
+	Used       int        `gorm:"default:0" json:"used"`                            // 已下单数量
+	ReleaseAt  time.Time  `gorm:"index" json:"release_at"`                          // 释放日期，到期未用部分退回公共库存
+	ReleasedAt *time.Time `json:"released_at,omitempty"`                            // 实际释放时间
+	CreatedBy  int64      `json:"created_by"`                                       // 创建人员工 ID
+	CreatedAt  time.Time  `json:"created_at"`                                       // 创建时间
+	UpdatedAt  time.Time  `json:"updated_at"`                                       // 更新时间
+}
+
+// Remaining 返回配额剩余可用数量。
+func (a AgencyAllotment) Remaining() int {
+	if a.ReleasedAt != nil || a.Used >= a.Quantity {
+		return 0
+	}
+	return a.Quantity - a.Used
+}
+
+// AgencyNetRate 为分销商指定航次舱型的固定净价，优先于默认佣金比例。
+type AgencyNetRate struct {
+	ID            int64     `gorm:"primaryKey" json:"id"`                                                             // 主键 ID
+	AgencyID      int64     `gorm:"uniqueIndex:uk_agency_net_rates_agency_voyage_type;not null" json:"agency_id"`     // 分销商 ID
+	VoyageID      int64     `gorm:"uniqueIndex:uk_agency_net_rates_agency_voyage_type;not null" json:"voyage_id"`     // 航次 ID
+	CabinTypeID   int64     `gorm:"uniqueIndex:uk_agency_net_rates_agency_voyage_type;not null" json:"cabin_type_id"` // 舱型 ID
+	NetPriceCents int64     `gorm:"not null" json:"net_price_cents"`                                                  // 净价（分）
+	CreatedAt     time.Time `json:"created_at"`                                                                       // 创建时间
+	UpdatedAt     time.Time `json:"updated_at"`                                                                       // 更新时间
+}
+
+// AgencyBooking 记录分销商订单的渠道信息与结算净价，BookingID 对应 bookings 表。
+type AgencyBooking struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`                   // 主键 ID
+	AgencyID    int64     `gorm:"index;not null" json:"agency_id"`        // 分销商 ID
+	BookingID   int64     `gorm:"uniqueIndex;not null" json:"booking_id"` // 订单 ID
+	AllotmentID int64     `gorm:"not null" json:"allotment_id"`           // 占用的配额 ID
+	NetCents    int64     `gorm:"not null" json:"net_cents"`              // 结算净价（分）
+	AgencyRef   string    `gorm:"size:64" json:"agency_ref,omitempty"`    // 分销商自有订单号
+	StatementID *int64    `gorm:"index" json:"statement_id,omitempty"`    // 所属月结账单 ID
+	CreatedAt   time.Time `json:"created_at"`                             // 创建时间
+}
+
+// AgencyBookingView 是分销商订单与订单主表的联合视图。
+type AgencyBookingView struct {
+	AgencyBooking
+	VoyageID   int64  `json:"voyage_id"`    // 航次 ID
+	CabinSKUID int64  `json:"cabin_sku_id"` // 舱房 SKU ID
+	Status     string `json:"status"`       // 订单状态
+	TotalCents int64  `json:"total_cents"`  // 订单金额（分）
+}
+
+// AgencyStatement 表示分销商的月结账单。
+type AgencyStatement struct {
+	ID           int64      `gorm:"primaryKey" json:"id"`                                                         // 主键 ID
+	AgencyID     int64      `gorm:"uniqueIndex:uk_agency_statements_agency_period;not null" json:"agency_id"`     // 分销商 ID
+	Period       string     `gorm:"size:7;uniqueIndex:uk_agency_statements_agency_period;not null" json:"period"` // 账期（YYYY-MM，上海时间）
+	BookingCount int        `json:"booking_count"`                                                                // 计入订单数
+	TotalCents   int64      `json:"total_cents"`                                                                  // 应结净价合计（分）
+	Status       string     `gorm:"size:20;default:issued" json:"status"`                                         // 账单状态
+	IssuedBy     int64      `json:"issued_by"`                                                                    // 出账人员工 ID
+	PaidAt       *time.Time `json:"paid_at,omitempty"`                                                            // 结清时间
+	CreatedAt    time.Time  `json:"created_at"`                                                                   // 创建时间
+	UpdatedAt    time.Time  `json:"updated_at"`                                                                   // 更新时间
+}
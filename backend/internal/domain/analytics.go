@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// AnalyticsFactFilter 描述分析事实查询的时间范围与维度过滤条件。
+// 时间区间为左闭右开 [From, To)，由调用方换算为 UTC 时间点。
+type AnalyticsFactFilter struct {
+	From       time.Time // 起始时间（含）
+	To         time.Time // 截止时间（不含）
+	CompanyID  int64     // 邮轮公司过滤，0 表示不过滤
+	CruiseID   int64     // 邮轮过滤，0 表示不过滤
+	VoyageID   int64     // 航次过滤，0 表示不过滤
+	CategoryID int64     // 舱型大类过滤，0 表示不过滤
+	Channel    string    // 销售渠道过滤，空表示不过滤
+}
+
+// AnalyticsBookingFact 是分析查询使用的订单事实行，携带各维度的 ID 与名称。
+type AnalyticsBookingFact struct {
+	BookingID    int64     // 订单 ID
+	CreatedAt    time.Time // 下单时间
+	Status       string    // 订单状态
+	TotalCents   int64     // 订单金额（分）
+	Channel      string    // 销售渠道
+	CompanyID    int64     // 邮轮公司 ID
+	CompanyName  string    // 邮轮公司名称
+	CruiseID     int64     // 邮轮 ID
+	CruiseName   string    // 邮轮名称
+	VoyageID     int64     // 航次 ID
+	VoyageCode   string    // 航次编码
+	CategoryID   int64     // 舱型大类 ID
+	CategoryName string    // 舱型大类名称
+}
+
+// AnalyticsCapacityFact 是按航次 + 舱型大类汇总的库存容量事实行，用于计算上座率。
+type AnalyticsCapacityFact struct {
+	CompanyID    int64  // 邮轮公司 ID
+	CompanyName  string // 邮轮公司名称
+	CruiseID     int64  // 邮轮 ID
+	CruiseName   string // 邮轮名称
+	VoyageID     int64  // 航次 ID
+	VoyageCode   string // 航次编码
+	CategoryID   int64  // 舱型大类 ID
+	CategoryName string // 舱型大类名称
+	Capacity     int64  // 舱房总量
+}
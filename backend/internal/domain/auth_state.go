@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// AuthState 是一条带过期时间的认证风控状态，如验证码重发间隔、有效期、失败次数、锁定与绑定授权窗口。
+type AuthState struct {
+	Key       string    `gorm:"column:state_key;primaryKey;size:128" json:"key"`          // 状态键，如 sms:lock:13800000000
+	Count     int64     `gorm:"not null;default:0" json:"count"`                          // 计数值，仅失败次数等计数器使用
+	Value     string    `gorm:"column:state_value;size:128;not null;default:''" json:"-"` // 取值，如短信验证码的哈希
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`                         // 过期时间，过期后视为不存在
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AuthStateStore 定义认证风控状态的共享存储，多副本部署时各实例看到同一份状态。
+// 所有状态都带 TTL：ttl 从 now 起算，过期的键视为不存在；Redis 实现直接使用服务端 TTL。
+type AuthStateStore interface {
+	// SetNX 仅当键不存在（或已过期）时写入，返回是否写入成功。
+	SetNX(ctx context.Context, key string, ttl time.Duration, now time.Time) (bool, error)
+	// Set 写入或覆盖键并重置过期时间。
+	Set(ctx context.Context, key string, ttl time.Duration, now time.Time) error
+	// SetValue 写入或覆盖带取值的键并重置过期时间。
+	SetValue(ctx context.Context, key, value string, ttl time.Duration, now time.Time) error
+	// GetValue 返回键在 now 时刻的取值，键不存在或已过期时第二个返回值为 false。
+	GetValue(ctx context.Context, key string, now time.Time) (string, bool, error)
+	// Exists 判断键在 now 时刻是否仍有效。
+	Exists(ctx context.Context, key string, now time.Time) (bool, error)
+	// Incr 计数加一并返回新值；键不存在或已过期时从 1 开始并设置 ttl，已存在时保留原过期时间。
+	Incr(ctx context.Context, key string, ttl time.Duration, now time.Time) (int64, error)
+	// Delete 删除指定键。
+	Delete(ctx context.Context, keys ...string) error
+	// PurgeExpired 清理已过期的键，返回删除条数。
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
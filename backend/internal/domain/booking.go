@@ -15,6 +15,11 @@ const (
 	OrderStatusRefunded       = "refunded"
 )
 
+const (
+	BookingChannelDirect = "direct" // C 端直销（小程序/H5）
+	BookingChannelAgency = "agency" // B2B 分销商（旅行社）
+)
+
 var validTransitions = map[string][]string{
 	OrderStatusCreated:        {OrderStatusPendingPayment, OrderStatusCancelled},
 	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusCancelled},
@@ -47,6 +52,7 @@ type Booking struct {
 	Status     string    `gorm:"size:30;default:created" json:"status"`   // 订单状态
 	TotalCents int64     `json:"total_cents"`                             // 订单总金额（单位：分）
 	PaidCents  int64     `json:"paid_cents"`                              // 已支付金额（单位：分）
+	Channel    string    `gorm:"size:30;default:direct" json:"channel"`   // 销售渠道
 	BookingNo  string    `gorm:"->;-:migration;column:booking_no" json:"booking_no,omitempty"`
 	Phone      string    `gorm:"->;-:migration;column:phone" json:"phone,omitempty"`
 	VoyageCode string    `gorm:"->;-:migration;column:voyage_code" json:"voyage_code,omitempty"`
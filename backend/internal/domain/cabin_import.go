@@ -0,0 +1,14 @@
+package domain
+
+import "errors"
+
+// ErrCabinInventoryBelowCommitted 表示导入的库存总量小于已锁定与已售数量之和。
+var ErrCabinInventoryBelowCommitted = errors.New("inventory total is below locked and sold")
+
+// CabinImportItem 是批量导入中的一行：SKU.ID 为 0 表示新增，否则按舱房编号覆盖已有舱房。
+type CabinImportItem struct {
+	Row            int      // 文件中的行号（含表头），用于回报错误
+	SKU            CabinSKU // 待写入的舱房 SKU
+	InventoryTotal *int     // 库存总量，nil 表示保持原值
+	AlertThreshold *int     // 库存预警阈值，nil 表示保持原值
+}
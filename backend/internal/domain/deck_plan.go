@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDeckPlanExists 表示该邮轮的同一甲板层已有平面图。
+var ErrDeckPlanExists = errors.New("deck plan already exists for this deck")
+
+// DeckPlan 表示邮轮某层甲板的平面图，前端按图片尺寸与舱位坐标渲染可点选的舱位图。
+type DeckPlan struct {
+	ID        int64           `gorm:"primaryKey" json:"id"`                                                // 主键 ID
+	CruiseID  int64           `gorm:"uniqueIndex:idx_deck_plans_cruise_deck;not null" json:"cruise_id"`    // 所属邮轮 ID
+	Deck      string          `gorm:"size:20;uniqueIndex:idx_deck_plans_cruise_deck;not null" json:"deck"` // 甲板层，与 CabinSKU.Deck 对应
+	Name      string          `gorm:"size:100" json:"name"`                                                // 展示名称，如“8 层 海景甲板”
+	ImageURL  string          `gorm:"size:500" json:"image_url"`                                           // 平面图图片 URL
+	Width     int             `json:"width"`                                                               // 图片宽度（像素）
+	Height    int             `json:"height"`                                                              // 图片高度（像素）
+	SortOrder int             `json:"sort_order"`                                                          // 排序，越小越靠前
+	CreatedAt time.Time       `json:"created_at"`                                                          // 创建时间
+	UpdatedAt time.Time       `json:"updated_at"`                                                          // 更新时间
+	Cabins    []DeckPlanCabin `gorm:"foreignKey:DeckPlanID" json:"cabins,omitempty"`                       // 舱位坐标
+}
+
+// DeckPlanCabin 记录舱房在甲板平面图上的矩形区域（像素坐标，原点为图片左上角）。
+// CabinNumber 为船上的舱房号，对应航次舱房编号去掉“航次编码-”前缀后的部分，因此同一平面图适用于该邮轮的所有航次。
+type DeckPlanCabin struct {
+	ID          int64  `gorm:"primaryKey" json:"id"`                                                         // 主键 ID
+	DeckPlanID  int64  `gorm:"uniqueIndex:idx_deck_plan_cabins_number;not null" json:"deck_plan_id"`         // 所属甲板平面图 ID
+	CabinNumber string `gorm:"size:50;uniqueIndex:idx_deck_plan_cabins_number;not null" json:"cabin_number"` // 船上舱房号
+	X           int    `json:"x"`                                                                            // 左上角横坐标
+	Y           int    `json:"y"`                                                                            // 左上角纵坐标
+	Width       int    `json:"width"`                                                                        // 宽度
+	Height      int    `json:"height"`                                                                       // 高度
+}
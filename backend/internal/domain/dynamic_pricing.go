@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPricingProposalReviewed 表示提案已被审批或驳回，条件更新未命中。
+var ErrPricingProposalReviewed = errors.New("pricing proposal already reviewed")
+
+const (
+	PricingProposalStatusPending  = "pending"  // 待审批
+	PricingProposalStatusApproved = "approved" // 已审批并生成价格版本
+	PricingProposalStatusRejected = "rejected" // 已驳回
+
+	PricingProposalItemStatusPending = "pending" // 待生效
+	PricingProposalItemStatusApplied = "applied" // 已生成价格版本
+	PricingProposalItemStatusSkipped = "skipped" // 审批时当前价已变更，跳过
+)
+
+// DynamicPricingRule 描述一条收益管理动态调价规则。
+// 所有条件均为可选：为 nil 表示不限制；VoyageID/CabinTypeID 为 0 表示适用于全部航次/舱型。
+// 比例字段均以百分比表示（例如 10 表示 10%）。
+type DynamicPricingRule struct {
+	ID                   int64      `gorm:"primaryKey" json:"id"`                  // 主键 ID
+	Name                 string     `gorm:"size:100;not null" json:"name"`         // 规则名称
+	VoyageID             int64      `gorm:"index;default:0" json:"voyage_id"`      // 适用航次 ID，0=全部
+	CabinTypeID          int64      `gorm:"index;default:0" json:"cabin_type_id"`  // 适用舱型 ID，0=全部
+	MinLoadFactor        *float64   `json:"min_load_factor,omitempty"`             // 上座率下限（0~1，含）
+	MaxLoadFactor        *float64   `json:"max_load_factor,omitempty"`             // 上座率上限（0~1，不含）
+	MinDaysToDeparture   *int       `json:"min_days_to_departure,omitempty"`       // 距出发天数下限（含）
+	MaxDaysToDeparture   *int       `json:"max_days_to_departure,omitempty"`       // 距出发天数上限（含）
+	VelocityWindowDays   int        `gorm:"default:7" json:"velocity_window_days"` // 销售速度统计窗口（天）
+	MinVelocity          *float64   `json:"min_velocity,omitempty"`                // 日均售出舱房数下限（含）
+	MaxVelocity          *float64   `json:"max_velocity,omitempty"`                // 日均售出舱房数上限（不含）
+	AdjustPercent        float64    `json:"adjust_percent"`                        // 相对当前售价的调整比例（可为负）
+	FloorMarkupPercent   float64    `json:"floor_markup_percent"`                  // 售价下限 = 结算价 × (1 + 该比例)
+	CeilingMarkupPercent *float64   `json:"ceiling_markup_percent,omitempty"`      // 售价上限 = 结算价 × (1 + 该比例)，nil 表示不封顶
+	Priority             int        `gorm:"default:0" json:"priority"`             // 优先级，值越大越优先
+	Status               int16      `gorm:"default:1" json:"status"`               // 状态：1=启用，0=停用
+	CreatedAt            time.Time  `json:"created_at"`                            // 创建时间
+	UpdatedAt            time.Time  `json:"updated_at"`                            // 更新时间
+	DeletedAt            *time.Time `gorm:"index" json:"deleted_at,omitempty"`     // 软删除时间
+}
+
+// DynamicPricingProposal 表示一次动态调价的待审批提案。
+type DynamicPricingProposal struct {
+	ID          int64                        `gorm:"primaryKey" json:"id"`                         // 主键 ID
+	Status      string                       `gorm:"size:20;index;default:pending" json:"status"`  // 提案状态
+	EffectiveAt *time.Time                   `json:"effective_at,omitempty"`                       // 新价格生效时间，nil 表示审批即生效
+	CreatedBy   int64                        `json:"created_by"`                                   // 发起人员工 ID
+	ReviewedBy  int64                        `json:"reviewed_by,omitempty"`                        // 审批人员工 ID
+	ReviewedAt  *time.Time                   `json:"reviewed_at,omitempty"`                        // 审批时间
+	ReviewNote  string                       `gorm:"size:500" json:"review_note,omitempty"`        // 审批备注
+	CreatedAt   time.Time                    `json:"created_at"`                                   // 创建时间
+	UpdatedAt   time.Time                    `json:"updated_at"`                                   // 更新时间
+	Items       []DynamicPricingProposalItem `gorm:"foreignKey:ProposalID" json:"items,omitempty"` // 调价明细
+}
+
+// DynamicPricingProposalItem 表示提案中单个航次舱型的调价明细与计算依据。
+type DynamicPricingProposalItem struct {
+	ID                     int64   `gorm:"primaryKey" json:"id"`                  // 主键 ID
+	ProposalID             int64   `gorm:"index;not null" json:"proposal_id"`     // 所属提案 ID
+	VoyageID               int64   `gorm:"not null" json:"voyage_id"`             // 航次 ID
+	CabinTypeID            int64   `gorm:"not null" json:"cabin_type_id"`         // 舱型 ID
+	RuleID                 int64   `json:"rule_id"`                               // 命中规则 ID
+	BaseVersionID          int64   `json:"base_version_id"`                       // 计算时的当前价版本 ID
+	InventoryTotal         int     `json:"inventory_total"`                       // 库存总量（沿用当前版本）
+	SettlementPriceCents   int64   `json:"settlement_price_cents"`                // 结算价（分）
+	CurrentSalePriceCents  int64   `json:"current_sale_price_cents"`              // 当前售价（分）
+	ProposedSalePriceCents int64   `json:"proposed_sale_price_cents"`             // 建议售价（分）
+	LoadFactor             float64 `json:"load_factor"`                           // 计算时上座率
+	DaysToDeparture        int     `json:"days_to_departure"`                     // 计算时距出发天数
+	Velocity               float64 `json:"velocity"`                              // 计算时日均售出舱房数
+	Reason                 string  `gorm:"size:300" json:"reason"`                // 调价说明
+	Status                 string  `gorm:"size:20;default:pending" json:"status"` // 明细状态
+	VersionID              int64   `json:"version_id,omitempty"`                  // 审批后生成的价格版本 ID
+}
+
+// PricingInventoryStat 是按航次 + 舱型汇总的库存统计。
+type PricingInventoryStat struct {
+	VoyageID    int64 // 航次 ID
+	CabinTypeID int64 // 舱型 ID
+	Total       int64 // 库存总量
+	Sold        int64 // 已售数量
+}
+
+// PricingSalesStat 是按航次 + 舱型汇总的近期销量统计。
+type PricingSalesStat struct {
+	VoyageID    int64 // 航次 ID
+	CabinTypeID int64 // 舱型 ID
+	Sold        int64 // 统计窗口内售出舱房数
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// RouteMapCache 持久化已规划的航线地图，缓存键由有序停靠港坐标与分辨率哈希生成。
+type RouteMapCache struct {
+	ID           int64      `gorm:"primaryKey" json:"id"`
+	CacheKey     string     `gorm:"size:64;not null;uniqueIndex:idx_route_map_cache_key" json:"cache_key"` // 停靠港坐标与分辨率的 SHA-256
+	Provider     string     `gorm:"size:30;not null" json:"provider"`                                      // 航线来源（searoute/searoute_offline）
+	ResolutionKM int        `gorm:"not null" json:"resolution_km"`                                         // 路由分辨率（km）
+	StopCount    int        `gorm:"not null" json:"stop_count"`                                            // 停靠港数量
+	Payload      string     `gorm:"type:text;not null" json:"-"`                                           // 航线地图 JSON
+	ExpiresAt    *time.Time `gorm:"index" json:"expires_at,omitempty"`                                     // 过期时间，为空表示长期有效
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 指定航线地图缓存表名。
+func (RouteMapCache) TableName() string { return "route_map_cache" }
+
+// GeocodeCache 缓存外部地理编码服务按查询词返回的原始结果。
+type GeocodeCache struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	Provider  string    `gorm:"size:30;not null;uniqueIndex:idx_geocode_cache_query" json:"provider"` // 地理编码服务商
+	Query     string    `gorm:"size:200;not null;uniqueIndex:idx_geocode_cache_query" json:"query"`   // 规范化后的查询词
+	Payload   string    `gorm:"type:text;not null" json:"-"`                                          // 原始结果 JSON
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`                                     // 过期时间
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定地理编码缓存表名。
+func (GeocodeCache) TableName() string { return "geocode_cache" }
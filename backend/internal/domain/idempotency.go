@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyRecord 保存一次带 Idempotency-Key 请求的指纹与响应，用于在有效期内重放重试请求。
+// StatusCode 为 0 表示原请求仍在处理中。
+type IdempotencyRecord struct {
+	Key          string    `gorm:"column:idem_key;primaryKey;size:64" json:"key"`    // 调用方身份、路由与幂等键的哈希
+	Fingerprint  string    `gorm:"size:64;not null" json:"fingerprint"`              // 请求方法、路由与请求体的哈希
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"`            // 原响应状态码
+	ContentType  string    `gorm:"size:100;not null;default:''" json:"content_type"` // 原响应内容类型
+	ResponseBody string    `gorm:"type:text;not null;default:''" json:"response_body"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"` // 过期后键可被重新使用
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName 返回幂等记录表名。
+func (IdempotencyRecord) TableName() string { return "idempotency_keys" }
+
+// Completed 判断原请求是否已处理完成。
+func (r IdempotencyRecord) Completed() bool { return r.StatusCode > 0 }
+
+// IdempotencyStore 定义幂等记录的共享存储，多副本部署时各实例看到同一份记录。
+type IdempotencyStore interface {
+	// Reserve 在键不存在或已过期时写入处理中的记录并返回 true；否则返回已有记录与 false。
+	Reserve(ctx context.Context, record *IdempotencyRecord, now time.Time) (*IdempotencyRecord, bool, error)
+	// Complete 保存原请求的响应并把过期时间设为 expiresAt。
+	Complete(ctx context.Context, key string, statusCode int, contentType, body string, expiresAt time.Time) error
+	// Release 删除处理中的记录，使失败的请求可以用同一个键重试。
+	Release(ctx context.Context, key string) error
+	// PurgeExpired 清理已过期的记录，返回删除条数。
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
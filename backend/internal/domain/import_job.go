@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+const (
+	ImportJobPending   = "pending"   // 排队中
+	ImportJobRunning   = "running"   // 处理中
+	ImportJobSucceeded = "succeeded" // 已完成（可能含失败行）
+	ImportJobFailed    = "failed"    // 文件级错误或处理异常
+)
+
+// ImportJob 记录一次异步批量导入任务的状态与结果，失败行的错误报告随任务保存以便下载。
+type ImportJob struct {
+	ID          int64      `gorm:"primaryKey" json:"id"`                 // 主键 ID
+	Kind        string     `gorm:"size:50;index;not null" json:"kind"`   // 导入类型，如 custom_destinations
+	Status      string     `gorm:"size:20;index;not null" json:"status"` // pending / running / succeeded / failed
+	Mode        string     `gorm:"size:20;not null" json:"mode"`         // all_or_nothing / best_effort
+	DryRun      bool       `json:"dry_run"`                              // 是否为试运行
+	FileName    string     `gorm:"size:255" json:"file_name"`            // 上传的文件名
+	Total       int        `json:"total"`                                // 数据行数
+	Succeeded   int        `json:"succeeded"`                            // 成功行数
+	Failed      int        `json:"failed"`                               // 失败行数
+	Committed   bool       `json:"committed"`                            // 是否已写入数据
+	Message     string     `gorm:"type:text" json:"message,omitempty"`   // 文件级错误或异常信息
+	ErrorReport string     `gorm:"type:text" json:"-"`                   // 失败行错误报告（CSV）
+	CreatedBy   *int64     `json:"created_by,omitempty"`                 // 提交人员工 ID
+	StartedAt   *time.Time `json:"started_at,omitempty"`                 // 开始处理时间
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`                // 处理结束时间
+	CreatedAt   time.Time  `json:"created_at"`                           // 创建时间
+	UpdatedAt   time.Time  `json:"updated_at"`                           // 更新时间
+}
@@ -1,6 +1,12 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
 
 // OperationLog 记录后台员工的系统操作日志，用于审计和追溯。
 type OperationLog struct {
@@ -13,4 +19,73 @@ type OperationLog struct {
 	IPAddress  string    `gorm:"size:50"`            // 客户端 IP 地址
 	UserAgent  string    `gorm:"size:500"`           // 客户端 User-Agent
 	CreatedAt  time.Time `gorm:"index"`              // 操作时间
+
+	Method     string `gorm:"size:10"`  // HTTP 方法，非接口操作为空
+	Route      string `gorm:"size:200"` // 命中的路由模板
+	StatusCode int    // 响应状态码
+	Changes    string `gorm:"type:text"` // 变更前后差异（JSON，字段 -> {before, after}）
+	PrevHash   string `gorm:"size:64"`   // 哈希链中上一条日志的哈希
+	Hash       string `gorm:"size:64"`   // 本条日志的哈希，覆盖 PrevHash 与全部业务字段
+}
+
+// ChainHash 计算日志在哈希链中的摘要：对 PrevHash 与各业务字段的 JSON 数组取 SHA-256。
+// 任意字段被改动、日志被删除或重排都会导致后续日志校验失败。
+func (l *OperationLog) ChainHash() string {
+	payload, _ := json.Marshal([]any{
+		l.PrevHash, l.StaffID, l.Operation, l.Resource, l.ResourceID, l.Details, l.IPAddress, l.UserAgent,
+		l.Method, l.Route, l.StatusCode, l.Changes, l.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// OperationLogChainHead 保存哈希链的链头（单行），追加日志时对其加行锁以串行化写入。
+type OperationLogChainHead struct {
+	ID        int    `gorm:"primaryKey"`
+	LastLogID int64  // 链上最后一条日志 ID
+	LastHash  string `gorm:"size:64"` // 链上最后一条日志的哈希
+	UpdatedAt time.Time
+}
+
+// OperationLogFilter 定义操作日志查询条件，零值表示不限。
+type OperationLogFilter struct {
+	StaffID    int64     // 操作员工 ID
+	Resource   string    // 资源类型
+	ResourceID int64     // 资源 ID
+	Operation  string    // 操作类型
+	Method     string    // HTTP 方法
+	From       time.Time // 起始时间（含）
+	To         time.Time // 截止时间（不含）
+}
+
+// OperationLogChainReport 是哈希链校验结果。
+type OperationLogChainReport struct {
+	Valid      bool   `json:"valid"`                  // 是否完整未被篡改
+	Checked    int64  `json:"checked"`                // 已校验的链上日志条数
+	BrokenAtID int64  `json:"broken_at_id,omitempty"` // 首条校验失败的日志 ID
+	Reason     string `json:"reason,omitempty"`       // 失败原因
+}
+
+// AuditEntry 是审计中间件采集的一次后台写操作，由审计服务补全变更差异后写入 OperationLog。
+type AuditEntry struct {
+	StaffID     int64  // 操作员工 ID
+	Method      string // HTTP 方法
+	Route       string // 路由模板，如 /api/v1/admin/cruises/:id
+	Operation   string // 操作类型，如 create、update、inventory_adjust
+	Resource    string // 资源类型，取路由中 /admin/ 之后的第一段
+	ResourceID  int64  // 资源 ID，创建类操作取响应中的 id
+	StatusCode  int    // 响应状态码
+	ContentType string // 请求体类型
+	RequestBody []byte // 请求体（已截断），写入前会脱敏
+	Before      any    // 执行前的资源快照，nil 表示无快照
+	IPAddress   string // 客户端 IP
+	UserAgent   string // 客户端 User-Agent
+}
+
+// OperationLogRepository 定义操作日志的持久化接口，写入统一经过哈希链。
+type OperationLogRepository interface {
+	Create(ctx context.Context, log *OperationLog) error                                                    // 追加日志并更新链头
+	List(ctx context.Context, filter OperationLogFilter, page, pageSize int) ([]OperationLog, int64, error) // 按条件分页查询，按 ID 倒序
+	ListForExport(ctx context.Context, filter OperationLogFilter, limit int) ([]OperationLog, error)        // 导出用查询，最多 limit 条
+	VerifyChain(ctx context.Context) (*OperationLogChainReport, error)                                      // 按 ID 顺序重算哈希并与链头比对
 }
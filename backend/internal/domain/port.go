@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	PortSourceAdmin     = "admin"      // 后台手工维护
+	PortSourceCSVImport = "csv_import" // CSV 批量导入
+)
+
+// ErrPortCodeExists 表示 UN/LOCODE 已被其他港口占用。
+var ErrPortCodeExists = errors.New("port code already exists")
+
+// Port 表示港口主数据，是港口城市搜索、行程坐标解析与航线规划的权威数据源。
+type Port struct {
+	ID              int64     `gorm:"primaryKey" json:"id"`                                       // 主键 ID
+	Code            string    `gorm:"size:5;uniqueIndex;not null" json:"code"`                    // UN/LOCODE，如 CNSHA
+	NameZH          string    `gorm:"column:name_zh;size:100;index;not null" json:"name_zh"`      // 中文名称
+	NameEN          string    `gorm:"column:name_en;size:100;not null;default:''" json:"name_en"` // 英文名称
+	Country         string    `gorm:"size:100;not null;default:''" json:"country"`                // 国家/地区中文名
+	CountryCode     string    `gorm:"size:2;index;not null;default:''" json:"country_code"`       // ISO 3166-1 国家代码
+	Timezone        string    `gorm:"size:64;not null;default:'UTC'" json:"timezone"`             // IANA 时区，如 Asia/Shanghai
+	Latitude        float64   `gorm:"not null" json:"latitude"`                                   // 纬度
+	Longitude       float64   `gorm:"not null" json:"longitude"`                                  // 经度
+	Keywords        string    `gorm:"type:text;not null;default:''" json:"keywords"`              // 搜索关键词（逗号分隔）
+	TerminalName    string    `gorm:"size:200;not null;default:''" json:"terminal_name"`          // 邮轮码头名称
+	TerminalAddress string    `gorm:"size:500;not null;default:''" json:"terminal_address"`       // 码头地址
+	TerminalNotes   string    `gorm:"type:text;not null;default:''" json:"terminal_notes"`        // 登船/交通说明
+	Source          string    `gorm:"size:64;not null;default:'admin'" json:"source"`             // 数据来源：admin/csv_import/迁移种子标记
+	Status          int16     `gorm:"not null" json:"status"`                                     // 1=启用, 0=停用（无数据库默认值，避免停用被零值覆盖）
+	SortOrder       int       `gorm:"default:0" json:"sort_order"`                                // 排序权重
+	CreatedAt       time.Time `json:"created_at"`                                                 // 创建时间
+	UpdatedAt       time.Time `json:"updated_at"`                                                 // 更新时间
+}
+
+// Label 返回港口城市下拉使用的 "名称（国家）" 标签。
+func (p Port) Label() string {
+	if p.Country == "" {
+		return p.NameZH
+	}
+	return p.NameZH + "（" + p.Country + "）"
+}
+
+// PortFilter 定义后台查询港口的过滤条件，零值字段表示不过滤。
+type PortFilter struct {
+	Keyword     string // 匹配代码、中英文名称、国家与关键词
+	CountryCode string // 国家代码
+	Status      *int16 // 状态（nil 表示不按状态筛选）
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// RateLimit 描述令牌桶限流参数：每 Period 补充 Requests 个令牌，桶容量为 Burst（允许的瞬时突发）。
+type RateLimit struct {
+	Requests int           // 每个周期补充的令牌数
+	Period   time.Duration // 补充周期
+	Burst    int           // 桶容量，<= 0 时等于 Requests
+}
+
+// RateLimitDecision 是一次取令牌的结果，用于生成 X-RateLimit-* 与 Retry-After 响应头。
+type RateLimitDecision struct {
+	Allowed    bool          // 是否放行
+	Limit      int           // 桶容量
+	Remaining  int           // 剩余令牌数（向下取整）
+	RetryAfter time.Duration // 被拒绝时距下一个令牌可用的时间，放行时为 0
+	ResetAfter time.Duration // 距桶重新装满的时间
+}
+
+// RateLimitStore 定义令牌桶状态存储，Take 从 key 对应的桶中原子地取出一个令牌。
+// 内存实现仅在单实例内生效，Redis 实现在多副本间共享同一个桶。
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitDecision, error)
+}
+
+// Capacity 返回桶容量。
+func (l RateLimit) Capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// TokensPerMillisecond 返回每毫秒补充的令牌数，参数非法时返回 0（桶不再补充）。
+func (l RateLimit) TokensPerMillisecond() float64 {
+	if l.Requests <= 0 || l.Period <= 0 {
+		return 0
+	}
+	return float64(l.Requests) / float64(l.Period.Milliseconds())
+}
+
+// Refill 计算桶经过 elapsed 后的令牌数，不超过桶容量。
+func (l RateLimit) Refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return tokens
+	}
+	return math.Min(l.Capacity(), tokens+float64(elapsed.Milliseconds())*l.TokensPerMillisecond())
+}
+
+// Decide 根据取令牌后的剩余令牌数生成限流结果。
+func (l RateLimit) Decide(allowed bool, tokens float64) RateLimitDecision {
+	decision := RateLimitDecision{
+		Allowed:   allowed,
+		Limit:     int(l.Capacity()),
+		Remaining: int(math.Floor(tokens)),
+	}
+	rate := l.TokensPerMillisecond()
+	if rate <= 0 {
+		return decision
+	}
+	if !allowed {
+		decision.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+	decision.ResetAfter = time.Duration(math.Ceil((l.Capacity()-tokens)/rate)) * time.Millisecond
+	return decision
+}
@@ -78,14 +78,81 @@ type ContentTemplateRepository interface {
 
 // VoyageCabinTypePriceRepository 定义航次舱型价格版本与当前态的数据持久化接口。
 type VoyageCabinTypePriceRepository interface {
-	CreateVersion(ctx context.Context, version *VoyageCabinTypePriceVersion) error                                                   // 创建价格版本
-	UpsertCurrent(ctx context.Context, current *VoyageCabinTypeCurrent) error                                                        // 写入当前生效价格
-	GetCurrent(ctx context.Context, voyageID, cabinTypeID int64) (*VoyageCabinTypeCurrent, error)                                    // 查询单个当前价
-	GetCurrentAt(ctx context.Context, voyageID, cabinTypeID int64, at time.Time) (*VoyageCabinTypeCurrent, error)                    // 按生效时点查询单个当前价
-	ListCurrentByVoyages(ctx context.Context, voyageIDs []int64) ([]VoyageCabinTypeCurrent, error)                                   // 查询多个航次的当前价
-	ListCurrentByVoyagesAt(ctx context.Context, voyageIDs []int64, at time.Time) ([]VoyageCabinTypeCurrent, error)                   // 按生效时点查询多个航次当前价
-	GetLatestVersionAt(ctx context.Context, voyageID, cabinTypeID int64, at time.Time) (*VoyageCabinTypePriceVersion, error)         // 按生效时点查询最新历史版本
-	ListVersions(ctx context.Context, voyageID, cabinTypeID int64, page, pageSize int) ([]VoyageCabinTypePriceVersion, int64, error) // 查询历史版本
+	CreateVersion(ctx context.Context, version *VoyageCabinTypePriceVersion) error                                                                        // 创建价格版本
+	UpsertCurrent(ctx context.Context, current *VoyageCabinTypeCurrent) error                                                                             // 写入当前生效价格
+	GetCurrent(ctx context.Context, voyageID, cabinTypeID int64) (*VoyageCabinTypeCurrent, error)                                                         // 查询单个当前价
+	GetCurrentAt(ctx context.Context, voyageID, cabinTypeID int64, at time.Time) (*VoyageCabinTypeCurrent, error)                                         // 按生效时点查询单个当前价
+	ListCurrentByVoyages(ctx context.Context, voyageIDs []int64) ([]VoyageCabinTypeCurrent, error)                                                        // 查询多个航次的当前价
+	ListCurrentByVoyagesAt(ctx context.Context, voyageIDs []int64, at time.Time) ([]VoyageCabinTypeCurrent, error)                                        // 按生效时点查询多个航次当前价
+	GetLatestVersionAt(ctx context.Context, voyageID, cabinTypeID int64, at time.Time) (*VoyageCabinTypePriceVersion, error)                              // 按生效时点查询最新历史版本
+	ListVersions(ctx context.Context, voyageID, cabinTypeID int64, page, pageSize int) ([]VoyageCabinTypePriceVersion, int64, error)                      // 查询历史版本
+	GetVersion(ctx context.Context, id int64) (*VoyageCabinTypePriceVersion, error)                                                                       // 根据 ID 查询价格版本
+	ListPendingVersions(ctx context.Context, voyageID, cabinTypeID int64, at time.Time, page, pageSize int) ([]VoyageCabinTypePriceVersion, int64, error) // 查询 at 之后待生效的版本
+	CancelVersion(ctx context.Context, id, staffID int64, at time.Time) error                                                                             // 撤销 at 之后待生效的版本
+	ListStaleCurrentKeys(ctx context.Context, at time.Time) ([]VoyageCabinTypeKey, error)                                                                 // 查询当前态落后于已到期版本的航次舱型
+}
+
+// DynamicPricingRepository 定义动态调价规则、提案及调价依据统计的数据访问接口。
+type DynamicPricingRepository interface {
+	ListRules(ctx context.Context, activeOnly bool) ([]DynamicPricingRule, error)                                  // 查询规则列表
+	GetRule(ctx context.Context, id int64) (*DynamicPricingRule, error)                                            // 根据 ID 查询规则
+	CreateRule(ctx context.Context, rule *DynamicPricingRule) error                                                // 创建规则
+	UpdateRule(ctx context.Context, rule *DynamicPricingRule) error                                                // 更新规则
+	DeleteRule(ctx context.Context, id int64) error                                                                // 删除规则
+	CreateProposal(ctx context.Context, proposal *DynamicPricingProposal) error                                    // 创建提案（含明细）
+	GetProposal(ctx context.Context, id int64) (*DynamicPricingProposal, error)                                    // 查询提案（含明细）
+	ListProposals(ctx context.Context, status string, page, pageSize int) ([]DynamicPricingProposal, int64, error) // 分页查询提案
+	MarkProposalReviewed(ctx context.Context, proposal *DynamicPricingProposal) error                              // 以 pending 为条件写入审批结果
+	UpdateProposalItems(ctx context.Context, items []DynamicPricingProposalItem) error                             // 回写明细状态与版本 ID
+	ListInventoryStats(ctx context.Context, voyageIDs []int64) ([]PricingInventoryStat, error)                     // 按航次舱型汇总库存
+	ListSalesStats(ctx context.Context, voyageIDs []int64, since time.Time) ([]PricingSalesStat, error)            // 按航次舱型统计近期销量
+}
+
+// AgencyRepository 定义分销商账户、API Key、配额、净价、分销订单与月结账单的数据访问接口。
+type AgencyRepository interface {
+	ListAgencies(ctx context.Context, keyword string, page, pageSize int) ([]Agency, int64, error) // 分页查询分销商
+	GetAgency(ctx context.Context, id int64) (*Agency, error)                                      // 根据 ID 查询分销商
+	GetAgencyByLoginName(ctx context.Context, loginName string) (*Agency, error)                   // 根据登录账户名查询分销商
+	CreateAgency(ctx context.Context, agency *Agency) error                                        // 创建分销商及其渠道下单用户
+	UpdateAgency(ctx context.Context, agency *Agency) error                                        // 更新分销商资料（不含密码）
+	UpdateAgencyPassword(ctx context.Context, id int64, passwordHash string) error                 // 更新登录密码
+	TouchAgencyLogin(ctx context.Context, id int64, at time.Time) error                            // 记录最后登录时间
+
+	CreateAPIKey(ctx context.Context, key *AgencyAPIKey) error                   // 创建 API Key
+	ListAPIKeys(ctx context.Context, agencyID int64) ([]AgencyAPIKey, error)     // 查询分销商的 API Key
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*AgencyAPIKey, error) // 按前缀查询 API Key
+	RevokeAPIKey(ctx context.Context, agencyID, keyID int64, at time.Time) error // 吊销 API Key
+	TouchAPIKey(ctx context.Context, keyID int64, at time.Time) error            // 记录 API Key 最近使用时间
+
+	CreateAllotment(ctx context.Context, allotment *AgencyAllotment) error                   // 创建配额并扣减公共库存
+	GetAllotment(ctx context.Context, id int64) (*AgencyAllotment, error)                    // 根据 ID 查询配额
+	ListAllotments(ctx context.Context, agencyID, voyageID int64) ([]AgencyAllotment, error) // 查询配额，voyageID 为 0 表示全部
+	ReleaseAllotment(ctx context.Context, id int64, at time.Time) (int, error)               // 释放配额未用部分并退回库存，返回退回数量
+	ListDueAllotmentIDs(ctx context.Context, at time.Time) ([]int64, error)                  // 查询已到释放日期且未释放的配额
+
+	UpsertNetRate(ctx context.Context, rate *AgencyNetRate) error              // 按分销商 + 航次 + 舱型写入净价
+	ListNetRates(ctx context.Context, agencyID int64) ([]AgencyNetRate, error) // 查询分销商净价
+	DeleteNetRate(ctx context.Context, agencyID, id int64) error               // 删除净价
+
+	OutstandingCredit(ctx context.Context, agencyID int64) (int64, error)                                           // 统计已占用信用额度
+	ListAgencyBookings(ctx context.Context, agencyID int64, page, pageSize int) ([]AgencyBookingView, int64, error) // 分页查询分销订单
+
+	CreateStatement(ctx context.Context, statement *AgencyStatement, from, to time.Time) error                // 汇总 [from, to) 内未出账订单生成月结账单
+	GetStatement(ctx context.Context, id int64) (*AgencyStatement, error)                                     // 根据 ID 查询账单
+	ListStatements(ctx context.Context, agencyID int64, page, pageSize int) ([]AgencyStatement, int64, error) // 分页查询账单
+	MarkStatementPaid(ctx context.Context, id int64, at time.Time) error                                      // 标记账单已结清
+}
+
+// WaitlistRepository 定义售罄候补登记、邀约到期与取消的数据访问接口。
+type WaitlistRepository interface {
+	CreateEntry(ctx context.Context, entry *WaitlistEntry) error                                                // 登记候补，同一用户同一航次舱型仅允许一条有效记录
+	GetEntry(ctx context.Context, id int64) (*WaitlistEntry, error)                                             // 根据 ID 查询候补记录
+	ListUserEntries(ctx context.Context, userID int64) ([]WaitlistEntry, error)                                 // 查询用户的候补记录
+	ListEntries(ctx context.Context, filter WaitlistFilter, page, pageSize int) ([]WaitlistEntry, int64, error) // 后台分页查询候补记录
+	CancelEntry(ctx context.Context, userID, id int64) (*WaitlistEntry, error)                                  // 取消候补并退回尚未使用的独占占座
+	ListExpiredOfferIDs(ctx context.Context, at time.Time) ([]int64, error)                                     // 查询独占期已过的邀约
+	ExpireOffer(ctx context.Context, id int64, at time.Time) (*WaitlistEntry, error)                            // 结束到期邀约并退回占座，未处理时返回 nil
+	ListWaitingTargets(ctx context.Context) ([]WaitlistTarget, error)                                           // 查询存在排队用户的航次舱型
 }
 
 // FacilityCategoryRepository 定义设施分类的数据持久化接口。
@@ -134,9 +201,39 @@ type CustomDestinationRepository interface {
 	SearchByKeyword(ctx context.Context, keyword string) ([]CustomDestination, error)       // 按关键词搜索（启用状态）
 	GetByLabel(ctx context.Context, name, country string) (*CustomDestination, error)       // 按名称+国家查询
 	UpsertByNameCountry(ctx context.Context, dest *CustomDestination) error                 // 按名称+国家更新或新增
+	UpsertBatch(ctx context.Context, dests []CustomDestination) error                       // 单事务按名称+国家批量更新或新增，任一失败整体回滚
 	Delete(ctx context.Context, id int64) error                                             // 删除自定义目的地（软删除）
 }
 
+// PortRepository 定义港口主数据的数据持久化接口。
+type PortRepository interface {
+	Create(ctx context.Context, port *Port) error                                           // 创建港口，代码冲突时返回 ErrPortCodeExists
+	Update(ctx context.Context, port *Port) error                                           // 更新港口，代码冲突时返回 ErrPortCodeExists
+	GetByID(ctx context.Context, id int64) (*Port, error)                                   // 根据 ID 查询
+	GetByCode(ctx context.Context, code string) (*Port, error)                              // 根据 UN/LOCODE 查询
+	List(ctx context.Context, filter PortFilter, page, pageSize int) ([]Port, int64, error) // 分页查询港口
+	ListAll(ctx context.Context) ([]Port, error)                                            // 查询全部港口（导出用）
+	SearchByKeyword(ctx context.Context, keyword string, limit int) ([]Port, error)         // 按关键词搜索启用港口
+	FindByName(ctx context.Context, name, country string) (*Port, error)                    // 按中/英文名称或代码查找启用港口，country 为空时不限国家
+	UpsertByCode(ctx context.Context, port *Port) error                                     // 按代码更新或新增
+	Delete(ctx context.Context, id int64) error                                             // 删除港口
+}
+
+// RouteMapCacheRepository 定义航线地图缓存的数据持久化接口。
+type RouteMapCacheRepository interface {
+	Get(ctx context.Context, key string, now time.Time) (*RouteMapCache, error) // 查询未过期的缓存，未命中返回 gorm.ErrRecordNotFound
+	Upsert(ctx context.Context, entry *RouteMapCache) error                     // 按缓存键写入或覆盖
+	Delete(ctx context.Context, keys ...string) error                           // 删除指定缓存键
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)             // 清理已过期的缓存
+}
+
+// GeocodeCacheRepository 定义地理编码缓存的数据持久化接口。
+type GeocodeCacheRepository interface {
+	Get(ctx context.Context, provider, query string, now time.Time) (*GeocodeCache, error) // 查询未过期的缓存，未命中返回 gorm.ErrRecordNotFound
+	Upsert(ctx context.Context, entry *GeocodeCache) error                                 // 按服务商与查询词写入或覆盖
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)                        // 清理已过期的缓存
+}
+
 // Sprint 2 仓储端口 —— 按照 DDD 规范定义在领域层。
 
 // RouteRepository 定义航线的数据持久化接口。
@@ -158,6 +255,35 @@ type VoyageRepository interface {
 	Delete(ctx context.Context, id int64) error                                                                  // 删除航次
 }
 
+// VoyageDraftRepository 定义按航次草稿单事务写入航次、行程、舱位、库存与价格的持久化接口。
+type VoyageDraftRepository interface {
+	CreateDraft(ctx context.Context, draft *VoyageDraft) error                     // 单事务创建单个航次草稿，编码冲突时返回 ErrVoyageCodeConflict
+	FindExistingVoyageCodes(ctx context.Context, codes []string) ([]string, error) // 返回已被占用的航次编码
+	FindExistingSKUCodes(ctx context.Context, codes []string) ([]string, error)    // 返回已被占用的舱位编号
+}
+
+// VoyageSeriesRepository 定义航次系列的数据持久化接口。
+type VoyageSeriesRepository interface {
+	VoyageDraftRepository
+	CreateWithVoyages(ctx context.Context, series *VoyageSeries, drafts []VoyageDraft) error // 单事务创建系列及其全部航次草稿，编码冲突时返回 ErrVoyageCodeConflict
+	GetByID(ctx context.Context, id int64) (*VoyageSeries, error)                            // 根据 ID 查询航次系列
+	List(ctx context.Context, page, pageSize int) ([]VoyageSeries, int64, error)             // 分页查询航次系列
+	ListVoyages(ctx context.Context, seriesID int64) ([]Voyage, error)                       // 查询系列下的航次（按出发日期排序）
+}
+
+// VoyageDisruptionRepository 定义航次停航/变更事件及受影响订单处理的持久化接口。
+type VoyageDisruptionRepository interface {
+	Open(ctx context.Context, disruption *VoyageDisruption, notify DisruptionNotifier) error                                  // 单事务创建事件、标记航次并快照受影响订单，已有处理中事件时返回 ErrVoyageDisruptionOpen
+	GetByID(ctx context.Context, id int64) (*VoyageDisruption, error)                                                         // 根据 ID 查询事件（含航次）
+	List(ctx context.Context, voyageID int64, status string, page, pageSize int) ([]VoyageDisruption, int64, error)           // 分页查询事件
+	ListBookings(ctx context.Context, filter DisruptionBookingFilter, page, pageSize int) ([]DisruptionBooking, int64, error) // 分页查询受影响订单
+	GetBooking(ctx context.Context, id int64) (*DisruptionBooking, error)                                                     // 根据 ID 查询受影响订单
+	FindBooking(ctx context.Context, disruptionID, bookingID int64) (*DisruptionBooking, error)                               // 按事件与订单 ID 查询受影响订单
+	Offer(ctx context.Context, ids []int64, offer DisruptionOffer, notify DisruptionNotifier) (int, error)                    // 为未处理的受影响订单发出替代方案，返回实际发出数
+	Resolve(ctx context.Context, id int64, res DisruptionResolution, notify DisruptionNotifier) (*DisruptionBooking, error)   // 单事务执行改签/全额退款/抵用金并记录处理结果
+	Close(ctx context.Context, id int64, at time.Time) (*VoyageDisruption, error)                                             // 结案，仍有未处理订单时返回 ErrDisruptionUnresolved
+}
+
 // CabinSKUFilter 描述舱位商品的后台筛选条件。
 type CabinSKUFilter struct {
 	VoyageID    int64  // 航次 ID
@@ -187,6 +313,37 @@ type CabinSKURepository interface {
 	UpsertPrice(ctx context.Context, p *CabinPrice) error                       // 新增或更新价格记录
 }
 
+// DeckPlanRepository 定义甲板平面图、舱位坐标以及舱位图所需库存、价格与舱型数据的访问接口。
+type DeckPlanRepository interface {
+	Create(ctx context.Context, plan *DeckPlan) error                                    // 创建平面图，甲板层已有平面图时返回 ErrDeckPlanExists
+	Update(ctx context.Context, plan *DeckPlan) error                                    // 更新平面图基础信息，甲板层冲突时返回 ErrDeckPlanExists
+	GetByID(ctx context.Context, id int64) (*DeckPlan, error)                            // 根据 ID 查询平面图（含舱位坐标）
+	ListByCruise(ctx context.Context, cruiseID int64) ([]DeckPlan, error)                // 查询邮轮的全部平面图（含舱位坐标）
+	Delete(ctx context.Context, id int64) error                                          // 删除平面图及其舱位坐标
+	ReplaceCabins(ctx context.Context, planID int64, cabins []DeckPlanCabin) error       // 整体替换平面图的舱位坐标
+	ListInventoriesBySKUs(ctx context.Context, skuIDs []int64) ([]CabinInventory, error) // 批量查询舱房库存
+	ListPricesBySKUs(ctx context.Context, skuIDs []int64) ([]CabinPrice, error)          // 批量查询舱房日历价格
+	ListCabinTypesByIDs(ctx context.Context, ids []int64) ([]CabinType, error)           // 批量查询舱房类型
+}
+
+// ImportJobRepository 定义异步批量导入任务的持久化接口。
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *ImportJob) error                                              // 创建导入任务
+	Update(ctx context.Context, job *ImportJob) error                                              // 保存任务状态与结果
+	GetByID(ctx context.Context, id int64) (*ImportJob, error)                                     // 根据 ID 查询
+	List(ctx context.Context, kind, status string, page, pageSize int) ([]ImportJob, int64, error) // 分页查询任务（不含错误报告）
+	FailUnfinished(ctx context.Context, message string, finishedAt time.Time) (int64, error)       // 把排队中/处理中的任务标记为失败，用于服务重启后的恢复
+}
+
+// CabinImportRepository 定义舱房 SKU 与库存批量导入导出的数据访问。
+type CabinImportRepository interface {
+	FindSKUsByCodes(ctx context.Context, codes []string) ([]CabinSKU, error)             // 按舱房编号批量查询 SKU
+	ListInventoriesBySKUs(ctx context.Context, skuIDs []int64) ([]CabinInventory, error) // 批量查询舱房库存
+	// ApplyImport 在单个事务内新增或覆盖舱房 SKU 与库存，库存总量变化写入库存日志；
+	// 库存总量小于已锁定与已售之和时返回 ErrCabinInventoryBelowCommitted 并整体回滚。
+	ApplyImport(ctx context.Context, items []CabinImportItem, reason string) error
+}
+
 // --- Sprint 4: 支付 / 退款 / 通知 / 分析仓储 ---
 
 // PaymentRepository 定义支付持久化操作。
@@ -246,6 +403,8 @@ type AnalyticsRepository interface {
 	CabinHotnessRanking(ctx context.Context, limit int) ([]CabinRankingItem, error)
 	InventoryOverview(ctx context.Context) (*InventoryOverviewData, error)
 	PageViewStats(ctx context.Context) ([]PageViewData, error)
+	ListBookingFacts(ctx context.Context, filter AnalyticsFactFilter) ([]AnalyticsBookingFact, error)
+	ListCapacityFacts(ctx context.Context, voyageIDs []int64) ([]AnalyticsCapacityFact, error)
 }
 
 // BookingStatusRepository 提供订单状态更新功能，
@@ -25,7 +25,7 @@ func IsValidStaffRole(role string) bool {
 type Staff struct {
 	ID           int64      `gorm:"primaryKey"`               // 主键 ID
 	Username     string     `gorm:"size:50;uniqueIndex"`      // 登录用户名（唯一）
-	PasswordHash string     `gorm:"size:255"`                 // 密码的 bcrypt 哈希值
+	PasswordHash string     `gorm:"size:255" json:"-"`        // 密码的 bcrypt 哈希值
 	RealName     string     `gorm:"size:50"`                  // 员工真实姓名
 	Phone        string     `gorm:"size:20"`                  // 联系电话
 	Email        string     `gorm:"size:100"`                 // 电子邮箱
@@ -36,4 +36,13 @@ type Staff struct {
 	CreatedAt    time.Time  // 创建时间
 	UpdatedAt    time.Time  // 更新时间
 	DeletedAt    *time.Time `gorm:"index"` // 软删除时间
+
+	PasswordChangedAt  *time.Time // 最近一次设置密码的时间，用于强制定期更换
+	MustChangePassword bool       `gorm:"default:false"` // 下次登录必须修改密码（如管理员设置的初始密码）
+	FailedLoginCount   int        `gorm:"default:0"`     // 连续登录失败次数，登录成功后清零
+	LockedUntil        *time.Time // 账号锁定截止时间
+	TOTPSecret         string     `gorm:"column:totp_secret;size:64" json:"-"` // TOTP 密钥（Base32），启用前为待确认密钥
+	TOTPEnabledAt      *time.Time `gorm:"column:totp_enabled_at"`              // TOTP 二次验证启用时间，nil 表示未启用
+	TOTPLastStep       int64      `gorm:"column:totp_last_step" json:"-"`      // 最近一次通过校验的 TOTP 时间步，防止验证码重放
+	AllCompanies       bool       `gorm:"default:false"`                       // 平台管理员标记：可访问全部邮轮公司的数据，否则只能访问 staff_companies 中授权的公司
 }
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// StaffCompany 记录员工可访问的邮轮公司。员工只能查看和修改被授权公司下的邮轮、航次、舱房与订单等数据，
+// 没有任何授权记录时看不到任何公司的数据；只有标记了 Staff.AllCompanies 的平台管理员不受限制。
+type StaffCompany struct {
+	StaffID   int64 `gorm:"primaryKey;autoIncrement:false"` // 员工 ID
+	CompanyID int64 `gorm:"primaryKey;autoIncrement:false"` // 邮轮公司 ID
+	CreatedAt time.Time
+}
+
+// StaffCompanyScope 是员工的公司数据范围：AllCompanies 为 true 时可访问全部公司（平台管理员），
+// 否则只能访问 CompanyIDs 中的公司，CompanyIDs 为空表示不能访问任何公司的数据。
+type StaffCompanyScope struct {
+	AllCompanies bool    `json:"all_companies"`
+	CompanyIDs   []int64 `json:"company_ids"`
+}
+
+// StaffCompanyRepository 定义员工公司数据范围的持久化接口。
+type StaffCompanyRepository interface {
+	GetScope(ctx context.Context, staffID int64) (StaffCompanyScope, error)         // 员工的公司数据范围
+	ReplaceScope(ctx context.Context, staffID int64, scope StaffCompanyScope) error // 整体替换员工的公司数据范围
+}
+
+type companyScopeContextKey struct{}
+
+// WithCompanyScope 把公司数据范围写入上下文，仓储层据此过滤查询；companyIDs 为空时任何公司的数据都不可见。
+// 不受限制的平台管理员不调用此函数。
+func WithCompanyScope(ctx context.Context, companyIDs []int64) context.Context {
+	return context.WithValue(ctx, companyScopeContextKey{}, append([]int64{}, companyIDs...))
+}
+
+// CompanyScopeFromContext 读取上下文中的公司数据范围，ok 为 false 表示不限制；ok 为 true 且 companyIDs 为空表示不能访问任何公司。
+func CompanyScopeFromContext(ctx context.Context) (companyIDs []int64, ok bool) {
+	companyIDs, ok = ctx.Value(companyScopeContextKey{}).([]int64)
+	return companyIDs, ok
+}
+
+// CompanyInScope 判断公司是否在上下文的数据范围内，不限制时总是返回 true。
+func CompanyInScope(ctx context.Context, companyID int64) bool {
+	companyIDs, ok := CompanyScopeFromContext(ctx)
+	if !ok {
+		return true
+	}
+	for _, id := range companyIDs {
+		if id == companyID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCompanyScopeContext(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := CompanyScopeFromContext(ctx); ok {
+		t.Fatal("contexts without a scope are unrestricted")
+	}
+	if !CompanyInScope(ctx, 42) {
+		t.Fatal("unscoped contexts can access every company")
+	}
+
+	empty := WithCompanyScope(ctx, nil)
+	if ids, ok := CompanyScopeFromContext(empty); !ok || len(ids) != 0 {
+		t.Fatalf("an empty scope must restrict to nothing, got %v %v", ids, ok)
+	}
+	if CompanyInScope(empty, 42) {
+		t.Fatal("an empty scope must not contain any company")
+	}
+
+	ids := []int64{1, 2}
+	scoped := WithCompanyScope(ctx, ids)
+	ids[0] = 99
+	if got, ok := CompanyScopeFromContext(scoped); !ok || len(got) != 2 || got[0] != 1 {
+		t.Fatalf("scope should be copied into the context, got %v %v", got, ok)
+	}
+	if !CompanyInScope(scoped, 2) || CompanyInScope(scoped, 3) {
+		t.Fatal("scope membership check is wrong")
+	}
+}
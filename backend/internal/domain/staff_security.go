@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// StaffPasswordHistory 记录员工历史密码哈希，用于禁止重复使用近期密码。
+type StaffPasswordHistory struct {
+	ID           int64     `gorm:"primaryKey"`
+	StaffID      int64     `gorm:"index;not null"`
+	PasswordHash string    `gorm:"size:255;not null"`
+	CreatedAt    time.Time // 密码被替换下来的时间
+}
+
+// StaffPasswordResetToken 是管理员发起重置密码时签发的一次性令牌，库中只保存令牌的 SHA-256 摘要。
+type StaffPasswordResetToken struct {
+	ID        int64      `gorm:"primaryKey"`
+	StaffID   int64      `gorm:"index;not null"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null"`
+	CreatedBy int64      // 发起重置的管理员 ID
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // 使用时间，非空表示已失效
+	CreatedAt time.Time
+}
+
+// StaffSecurityRepository 定义员工密码、登录锁定与二次验证相关的持久化接口。
+type StaffSecurityRepository interface {
+	RecordLoginFailure(ctx context.Context, staffID int64, maxAttempts int, lockedUntil time.Time) (*time.Time, error) // 失败计数加一，达到上限时锁定并返回锁定截止时间
+	RecordLoginSuccess(ctx context.Context, staffID int64, now time.Time) error                                        // 清零失败计数并记录登录时间
+	UpdatePassword(ctx context.Context, staffID int64, hash string, changedAt time.Time, keepHistory int) error        // 更新密码并把旧哈希写入历史（只保留最近 keepHistory 条）
+	ListPasswordHistory(ctx context.Context, staffID int64, limit int) ([]string, error)                               // 最近的历史密码哈希
+	SetTOTP(ctx context.Context, staffID int64, secret string, enabledAt *time.Time) error                             // 写入 TOTP 密钥与启用时间，secret 为空表示清除；仅启用时保留已用时间步
+	ConsumeTOTPStep(ctx context.Context, staffID int64, step int64) (bool, error)                                      // 仅当 step 大于已用时间步时记录并返回 true
+	CreateResetToken(ctx context.Context, token *StaffPasswordResetToken) error                                        // 签发重置令牌，同时作废该员工此前未使用的令牌
+	FindResetToken(ctx context.Context, tokenHash string, now time.Time) (*StaffPasswordResetToken, error)             // 查询未使用且未过期的令牌，不改变其状态
+	ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*StaffPasswordResetToken, error)          // 原子地使用未过期令牌，无效时返回 gorm.ErrRecordNotFound
+}
@@ -1,18 +1,49 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrIdentityTaken 表示手机号或第三方账号已绑定到其他用户。
+var ErrIdentityTaken = errors.New("identity already bound to another user")
+
+// ErrIdentityConflict 表示用户已绑定同一渠道的另一个账号，不能直接覆盖。
+var ErrIdentityConflict = errors.New("user already bound to another account of this provider")
+
+// 可绑定的账号渠道，对应 users 表中的唯一标识列。
+const (
+	IdentityPhone  = "phone"  // 手机号
+	IdentityWechat = "wechat" // 微信 OpenID
+	IdentityAlipay = "alipay" // 支付宝 UID
+)
 
 // User 表示 C 端登录用户基础资料。
-// 支持手机号、微信 OpenID 和支付宝 UID 三种唯一标识，均可用于登录。
+// 支持手机号、微信 OpenID 和支付宝 UID 三种唯一标识，均可用于登录；未绑定的标识为空字符串，唯一索引只约束非空值。
 type User struct {
-	ID        int64     `gorm:"primaryKey"`                            // 主键 ID
-	Phone     string    `gorm:"size:20;uniqueIndex"`                   // 手机号（唯一）
-	WxOpenID  string    `gorm:"size:80;uniqueIndex"`                   // 微信 OpenID（唯一）
-	AlipayUID string    `gorm:"size:80;uniqueIndex" json:"alipay_uid"` // 支付宝用户ID（唯一）
-	Email     string    `gorm:"size:100" json:"email"`                 // 邮箱
-	Nickname  string    `gorm:"size:50"`                               // 用户昵称
-	AvatarURL string    `gorm:"size:500"`                              // 头像图片地址
-	Status    int16     `gorm:"default:1"`                             // 状态：1=启用，0=停用
-	CreatedAt time.Time // 创建时间
-	UpdatedAt time.Time // 更新时间
+	ID           int64     `gorm:"primaryKey"`                                                                        // 主键 ID
+	Phone        string    `gorm:"size:20;uniqueIndex:idx_users_phone,where:phone <> ''"`                             // 手机号（唯一）
+	WxOpenID     string    `gorm:"size:80;uniqueIndex:idx_users_wx_open_id,where:wx_open_id <> ''"`                   // 微信 OpenID（唯一）
+	WxUnionID    string    `gorm:"size:80;index" json:"wx_union_id"`                                                  // 微信 UnionID（绑定开放平台后返回）
+	WxSessionKey string    `gorm:"size:64" json:"-"`                                                                  // 微信会话密钥，仅服务端用于解密手机号授权数据
+	AlipayUID    string    `gorm:"size:80;uniqueIndex:idx_users_alipay_uid,where:alipay_uid <> ''" json:"alipay_uid"` // 支付宝用户ID（唯一）
+	Email        string    `gorm:"size:100" json:"email"`                                                             // 邮箱
+	Nickname     string    `gorm:"size:50"`                                                                           // 用户昵称
+	AvatarURL    string    `gorm:"size:500"`                                                                          // 头像图片地址
+	Status       int16     `gorm:"default:1"`                                                                         // 状态：1=启用，0=停用
+	CreatedAt    time.Time // 创建时间
+	UpdatedAt    time.Time // 更新时间
+
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at"`              // 申请注销时间，冷静期内可撤销
+	DeletionScheduledAt *time.Time `gorm:"index" json:"deletion_scheduled_at"` // 冷静期结束、执行匿名化的时间，撤销或完成注销后清空
+	AnonymizedAt        *time.Time `json:"anonymized_at"`                      // 完成注销（个人信息匿名化）的时间，非空表示账号已注销
+}
+
+// UserData 汇总一个用户名下的个人数据，用于个人信息导出。
+type UserData struct {
+	User              User               // 用户资料
+	Bookings          []Booking          // 用户的全部订单
+	BookingPassengers []BookingPassenger // 订单与出行乘客的关联
+	Passengers        []Passenger        // 用户维护的出行乘客
+	Notifications     []Notification     // 发给用户的通知
 }
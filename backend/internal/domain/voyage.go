@@ -17,14 +17,16 @@ type Voyage struct {
 	RouteID    int64     `gorm:"-" json:"route_id,omitempty"` // 已下线字段，仅用于兼容旧测试/旧请求
 	CruiseID   int64     `gorm:"index" json:"cruise_id"`      // 执行邮轮 ID
 	Cruise     *Cruise   `gorm:"foreignKey:CruiseID" json:"cruise,omitempty"`
-	Code       string    `gorm:"size:50;uniqueIndex" json:"code"` // 航次编码（全局唯一）
-	ImageURL   string    `gorm:"size:500" json:"image_url"`       // 航次封面图 URL
-	BriefInfo  string    `gorm:"size:300" json:"brief_info"`      // 航次简短信息（手动输入）
-	DepartDate time.Time `json:"depart_date"`                     // 出发日期
-	ReturnDate time.Time `json:"return_date"`                     // 返航日期
-	Status     int16     `gorm:"default:1" json:"status"`         // 状态：1=开放预订，0=关闭
-	CreatedAt  time.Time `json:"created_at"`                      // 创建时间
-	UpdatedAt  time.Time `json:"updated_at"`                      // 更新时间
+	Code       string    `gorm:"size:50;uniqueIndex" json:"code"`     // 航次编码（全局唯一）
+	ImageURL   string    `gorm:"size:500" json:"image_url"`           // 航次封面图 URL
+	BriefInfo  string    `gorm:"size:300" json:"brief_info"`          // 航次简短信息（手动输入）
+	DepartDate time.Time `json:"depart_date"`                         // 出发日期
+	ReturnDate time.Time `json:"return_date"`                         // 返航日期
+	Status     int16     `gorm:"default:1" json:"status"`             // 状态：1=开放预订，0=关闭
+	SeriesID   int64     `gorm:"index" json:"series_id,omitempty"`    // 所属航次系列 ID，0 表示单独创建
+	Disruption string    `gorm:"size:20" json:"disruption,omitempty"` // 停航/变更标记：cancelled / modified，空表示正常
+	CreatedAt  time.Time `json:"created_at"`                          // 创建时间
+	UpdatedAt  time.Time `json:"updated_at"`                          // 更新时间
 
 	Itineraries   []VoyageItinerary `gorm:"foreignKey:VoyageID" json:"itineraries,omitempty"` // 航次行程明细
 	ItineraryDays int               `gorm:"-" json:"itinerary_days,omitempty"`                // 行程天数（列表辅助字段）
@@ -32,6 +34,7 @@ type Voyage struct {
 	MinPriceCents int64             `gorm:"-" json:"min_price_cents,omitempty"`
 	SoldCount     int64             `gorm:"-" json:"sold_count,omitempty"`
 	RouteMap      *VoyageRouteMap   `gorm:"-" json:"route_map,omitempty"`
+	Schedule      *VoyageSchedule   `gorm:"-" json:"schedule,omitempty"` // 行程时间表校验结果（创建/更新时返回）
 
 	FeeNoteTemplateID        int64                 `json:"fee_note_template_id,omitempty"`
 	FeeNoteMode              VoyageContentMode     `gorm:"size:20" json:"fee_note_mode,omitempty"`
@@ -45,6 +48,23 @@ type Voyage struct {
 	BookingNotice            *BookingNoticeContent `gorm:"-" json:"booking_notice,omitempty"`
 }
 
+// ItineraryIssue 描述行程时间表校验发现的问题；DayNo 为 0 表示针对整个行程。
+type ItineraryIssue struct {
+	Code      string `json:"code"`                 // 问题代码
+	DayNo     int    `json:"day_no,omitempty"`     // 行程第几天
+	StopIndex int    `json:"stop_index,omitempty"` // 当天第几站
+	Message   string `json:"message"`              // 问题说明
+}
+
+// VoyageSchedule 汇总航次行程时间表：日期跨度、行程天数、海上巡游日与校验警告。
+type VoyageSchedule struct {
+	DateSpanDays  int              `json:"date_span_days"` // 按出发/返航日期计算的天数，未设置日期时为 0
+	ItineraryDays int              `json:"itinerary_days"` // 行程天数
+	SeaDays       int              `json:"sea_days"`       // 全天海上巡游的天数
+	PortDays      int              `json:"port_days"`      // 有停靠港的天数
+	Warnings      []ItineraryIssue `json:"warnings"`       // 不阻止保存的警告
+}
+
 // VoyageItinerary 表示航次中某天某站的计划信息。
 type VoyageItinerary struct {
 	ID                int64     `gorm:"primaryKey" json:"id"`
@@ -57,6 +77,7 @@ type VoyageItinerary struct {
 	Longitude         *float64  `gorm:"column:longitude" json:"longitude,omitempty"`
 	ETATime           *string   `gorm:"column:eta_time" json:"eta_time,omitempty"`
 	ETDTime           *string   `gorm:"column:etd_time" json:"etd_time,omitempty"`
+	Timezone          string    `gorm:"size:64" json:"timezone,omitempty"` // 解析 ETA/ETD 所用的港口 IANA 时区
 	HasBreakfast      bool      `json:"has_breakfast"`
 	HasLunch          bool      `json:"has_lunch"`
 	HasDinner         bool      `json:"has_dinner"`
@@ -1,18 +1,33 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrPriceVersionNotPending 表示价格版本已生效或已撤销，不能再撤销。
+var ErrPriceVersionNotPending = errors.New("price version is not pending")
 
 // VoyageCabinTypePriceVersion 记录航次舱型价格与库存的历史版本。
 type VoyageCabinTypePriceVersion struct {
-	ID                   int64     `gorm:"primaryKey" json:"id"`
-	VoyageID             int64     `gorm:"index;not null" json:"voyage_id"`
-	CabinTypeID          int64     `gorm:"index;not null" json:"cabin_type_id"`
-	InventoryTotal       int       `json:"inventory_total"`
-	SettlementPriceCents int64     `json:"settlement_price_cents"`
-	SalePriceCents       int64     `json:"sale_price_cents"`
-	EffectiveAt          time.Time `json:"effective_at"`
-	CreatedBy            *int64    `json:"created_by,omitempty"`
-	CreatedAt            time.Time `json:"created_at"`
+	ID                   int64      `gorm:"primaryKey" json:"id"`
+	VoyageID             int64      `gorm:"index;not null" json:"voyage_id"`
+	CabinTypeID          int64      `gorm:"index;not null" json:"cabin_type_id"`
+	InventoryTotal       int        `json:"inventory_total"`
+	SettlementPriceCents int64      `json:"settlement_price_cents"`
+	SalePriceCents       int64      `json:"sale_price_cents"`
+	EffectiveAt          time.Time  `json:"effective_at"`
+	CreatedBy            *int64     `json:"created_by,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	CancelledAt          *time.Time `json:"cancelled_at,omitempty"`      // 撤销时间，仅未生效版本可撤销
+	CancelledBy          *int64     `json:"cancelled_by,omitempty"`      // 撤销人员工 ID
+	SourceVersionID      *int64     `json:"source_version_id,omitempty"` // 回滚来源版本 ID
+}
+
+// VoyageCabinTypeKey 标识一个航次舱型组合。
+type VoyageCabinTypeKey struct {
+	VoyageID    int64 // 航次 ID
+	CabinTypeID int64 // 舱型 ID
 }
 
 // VoyageCabinTypeCurrent 表示航次舱型当前对外生效价格快照。
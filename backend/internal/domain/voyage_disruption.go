@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	VoyageDisruptionCancelled = "cancelled" // 航次取消
+	VoyageDisruptionModified  = "modified"  // 行程变更
+)
+
+const (
+	VoyageDisruptionStatusOpen   = "open"   // 处理中
+	VoyageDisruptionStatusClosed = "closed" // 已结案
+)
+
+const (
+	DisruptionBookingPending  = "pending"  // 待处理
+	DisruptionBookingOffered  = "offered"  // 已发出替代方案，等待乘客选择
+	DisruptionBookingResolved = "resolved" // 已处理
+)
+
+const (
+	DisruptionResolutionRebook = "rebook" // 改签到其他航次，差价多退少补
+	DisruptionResolutionRefund = "refund" // 全额退款，不适用退款阶梯规则
+	DisruptionResolutionCredit = "credit" // 转为出行抵用金
+)
+
+// TravelCreditStatusActive 表示抵用金可用。
+const TravelCreditStatusActive = "active"
+
+// DisruptionAffectedOrderStatuses 为停航/变更时需要处理的订单状态。
+var DisruptionAffectedOrderStatuses = []string{
+	OrderStatusCreated, OrderStatusPendingPayment, OrderStatusPaid, OrderStatusConfirmed, OrderStatusPendingTravel,
+}
+
+var (
+	// ErrVoyageDisruptionOpen 表示航次已有处理中的停航/变更事件。
+	ErrVoyageDisruptionOpen = errors.New("voyage already has an open disruption")
+	// ErrVoyageDisruptionClosed 表示停航/变更事件已结案，不能再处理订单。
+	ErrVoyageDisruptionClosed = errors.New("voyage disruption is closed")
+	// ErrDisruptionBookingResolved 表示受影响订单已处理或已不再有效。
+	ErrDisruptionBookingResolved = errors.New("disruption booking already resolved")
+	// ErrNoRebookCabin 表示改签目标航次没有可用的舱房。
+	ErrNoRebookCabin = errors.New("no cabin available on target voyage")
+	// ErrRebookPriceDifference 表示已支付订单改签到更贵的舱房且未免收差价；系统不支持补收差价，需免收或改为退款/抵用金。
+	ErrRebookPriceDifference = errors.New("rebooking a paid booking to a pricier cabin requires waiving the price difference")
+	// ErrDisruptionRefundShort 表示订单的已支付记录不足以原路退回应退金额，需人工核实后处理。
+	ErrDisruptionRefundShort = errors.New("paid amount exceeds refundable payments")
+	// ErrDisruptionUnresolved 表示仍有受影响订单未处理，不能结案。
+	ErrDisruptionUnresolved = errors.New("disruption has unresolved bookings")
+)
+
+// VoyageDisruption 表示航次取消或行程变更事件，创建时快照全部受影响订单。
+type VoyageDisruption struct {
+	ID            int64      `gorm:"primaryKey" json:"id"`                        // 主键 ID
+	VoyageID      int64      `gorm:"index;not null" json:"voyage_id"`             // 受影响航次 ID
+	Type          string     `gorm:"size:20;not null" json:"type"`                // cancelled / modified
+	Reason        string     `gorm:"type:text" json:"reason"`                     // 原因说明（如台风）
+	Status        string     `gorm:"size:20;index;not null" json:"status"`        // open / closed
+	AffectedCount int        `json:"affected_count"`                              // 受影响订单数
+	ResolvedCount int        `json:"resolved_count"`                              // 已处理订单数
+	CreatedBy     *int64     `json:"created_by,omitempty"`                        // 创建人员工 ID
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`                         // 结案时间
+	CreatedAt     time.Time  `json:"created_at"`                                  // 创建时间
+	UpdatedAt     time.Time  `json:"updated_at"`                                  // 更新时间
+	Voyage        *Voyage    `gorm:"foreignKey:VoyageID" json:"voyage,omitempty"` // 受影响航次
+}
+
+// DisruptionBooking 记录停航/变更事件中单个订单的替代方案与处理结果。
+type DisruptionBooking struct {
+	ID                   int64      `gorm:"primaryKey" json:"id"`                                                      // 主键 ID
+	DisruptionID         int64      `gorm:"uniqueIndex:idx_disruption_bookings_booking;not null" json:"disruption_id"` // 停航/变更事件 ID
+	BookingID            int64      `gorm:"uniqueIndex:idx_disruption_bookings_booking;not null" json:"booking_id"`    // 受影响订单 ID
+	UserID               int64      `gorm:"index;not null" json:"user_id"`                                             // 下单用户 ID
+	CabinSKUID           int64      `gorm:"column:cabin_sku_id" json:"cabin_sku_id"`                                   // 原舱房 SKU ID
+	CabinTypeID          int64      `json:"cabin_type_id"`                                                             // 原舱型 ID，改签时优先匹配同舱型
+	Guests               int        `json:"guests"`                                                                    // 乘客人数
+	BookingStatus        string     `gorm:"size:30" json:"booking_status"`                                             // 事件发生时的订单状态
+	TotalCents           int64      `json:"total_cents"`                                                               // 原订单金额（分）
+	PaidCents            int64      `json:"paid_cents"`                                                                // 原订单已付金额（分）
+	Status               string     `gorm:"size:20;index;not null" json:"status"`                                      // pending / offered / resolved
+	OfferedOptions       string     `gorm:"size:50" json:"offered_options,omitempty"`                                  // 提供给乘客的方案，逗号分隔
+	AlternativeVoyageIDs string     `gorm:"size:500" json:"alternative_voyage_ids,omitempty"`                          // 可改签航次 ID，逗号分隔
+	WaivePriceDifference bool       `json:"waive_price_difference"`                                                    // 改签到更贵舱位时免收差价
+	CreditBonusPercent   int        `json:"credit_bonus_percent"`                                                      // 转抵用金的额外补偿比例
+	OfferedAt            *time.Time `json:"offered_at,omitempty"`                                                      // 发出替代方案时间
+	Resolution           string     `gorm:"size:20" json:"resolution,omitempty"`                                       // rebook / refund / credit
+	NewBookingID         int64      `json:"new_booking_id,omitempty"`                                                  // 改签后的新订单 ID
+	NewVoyageID          int64      `json:"new_voyage_id,omitempty"`                                                   // 改签后的航次 ID
+	PriceDiffCents       int64      `json:"price_diff_cents"`                                                          // 改签差价（正数为应补，负数为应退）
+	RefundCents          int64      `json:"refund_cents"`                                                              // 已发起的退款金额
+	CreditID             int64      `json:"credit_id,omitempty"`                                                       // 发放的抵用金 ID
+	ResolvedBy           int64      `json:"resolved_by,omitempty"`                                                     // 处理员工 ID，0 表示乘客自助选择
+	ResolvedAt           *time.Time `json:"resolved_at,omitempty"`                                                     // 处理时间
+	Remark               string     `gorm:"type:text" json:"remark,omitempty"`                                         // 处理备注
+	CreatedAt            time.Time  `json:"created_at"`                                                                // 创建时间
+	UpdatedAt            time.Time  `json:"updated_at"`                                                                // 更新时间
+}
+
+// TravelCredit 表示因停航/变更发放给用户的出行抵用金。
+type TravelCredit struct {
+	ID              int64     `gorm:"primaryKey" json:"id"`           // 主键 ID
+	UserID          int64     `gorm:"index;not null" json:"user_id"`  // 持有用户 ID
+	AmountCents     int64     `json:"amount_cents"`                   // 发放金额（分）
+	BalanceCents    int64     `json:"balance_cents"`                  // 剩余金额（分）
+	SourceBookingID int64     `json:"source_booking_id"`              // 来源订单 ID
+	DisruptionID    int64     `gorm:"index" json:"disruption_id"`     // 来源停航/变更事件 ID
+	Status          string    `gorm:"size:20;not null" json:"status"` // 状态
+	ExpiresAt       time.Time `json:"expires_at"`                     // 失效时间
+	CreatedAt       time.Time `json:"created_at"`                     // 创建时间
+	UpdatedAt       time.Time `json:"updated_at"`                     // 更新时间
+}
+
+// DisruptionBookingFilter 定义受影响订单的查询条件，零值字段表示不过滤。
+type DisruptionBookingFilter struct {
+	DisruptionID int64  // 停航/变更事件 ID
+	UserID       int64  // 用户 ID
+	Status       string // 处理状态
+	Resolution   string // 处理方式
+}
+
+// DisruptionRebookCandidate 表示改签目标舱房及改签后的订单金额。
+type DisruptionRebookCandidate struct {
+	CabinSKUID int64 // 目标舱房 SKU ID
+	TotalCents int64 // 改签后订单金额（分）
+}
+
+// DisruptionResolution 描述对单个受影响订单的处理方式。
+type DisruptionResolution struct {
+	Resolution           string                      // rebook / refund / credit
+	TargetVoyageID       int64                       // 改签目标航次
+	Candidates           []DisruptionRebookCandidate // 按优先级排列的改签舱房，依次尝试占用库存
+	WaivePriceDifference bool                        // 免收改签差价
+	CreditBonusPercent   int                         // 抵用金额外补偿比例
+	CreditExpiresAt      time.Time                   // 抵用金失效时间
+	OperatorID           int64                       // 处理员工 ID，0 表示乘客自助
+	Remark               string                      // 处理备注
+	At                   time.Time                   // 处理时间
+}
+
+// DisruptionOffer 描述批量发给受影响订单的替代方案。
+type DisruptionOffer struct {
+	Options              []string  // 可选方案
+	AlternativeVoyageIDs []int64   // 可改签航次
+	WaivePriceDifference bool      // 免收改签差价
+	CreditBonusPercent   int       // 抵用金额外补偿比例
+	At                   time.Time // 发出时间
+}
+
+// DisruptionNotifier 为受影响订单生成乘客通知，在处理事务内写入发件箱；返回 nil 表示不通知。
+type DisruptionNotifier func(item *DisruptionBooking) (*Notification, error)
@@ -0,0 +1,23 @@
+package domain
+
+import "errors"
+
+// ErrVoyageCodeConflict 表示待创建的航次编码或舱位编号已被占用。
+var ErrVoyageCodeConflict = errors.New("voyage or cabin code already exists")
+
+// VoyageDraftCabin 是待创建的舱位 SKU 及其初始库存与日历价格。
+type VoyageDraftCabin struct {
+	SKU            CabinSKU     // 舱位 SKU
+	InventoryTotal int          // 初始库存总量
+	AlertThreshold int          // 库存预警阈值
+	Prices         []CabinPrice // 日历价格
+}
+
+// VoyageDraft 是一个待创建航次的完整数据：航次与行程、舱位 SKU 与库存、价格版本。
+// 由航次系列批量生成与航次克隆共用，单事务写入。
+type VoyageDraft struct {
+	Voyage               Voyage                        // 航次（含行程）
+	Cabins               []VoyageDraftCabin            // 舱位 SKU
+	PriceVersions        []VoyageCabinTypePriceVersion // 立即生效的价格版本，同时写入当前价
+	PendingPriceVersions []VoyageCabinTypePriceVersion // 定时生效的价格版本，到期后由价格调度器切换
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// 航次系列重复规则。
+const (
+	VoyageSeriesFrequencyDaily   = "daily"   // 每 N 天
+	VoyageSeriesFrequencyWeekly  = "weekly"  // 每 N 周的指定星期
+	VoyageSeriesFrequencyMonthly = "monthly" // 每 N 月的同一日期
+)
+
+// VoyageSeries 表示按模板航次与重复规则批量生成的一组航次。
+// 模板航次提供邮轮、行程、费用说明/预订须知模板与舱位 SKU，系列记录生成参数以便追溯。
+type VoyageSeries struct {
+	ID               int64     `gorm:"primaryKey" json:"id"`                        // 主键 ID
+	Name             string    `gorm:"size:100;not null" json:"name"`               // 系列名称
+	TemplateVoyageID int64     `gorm:"index;not null" json:"template_voyage_id"`    // 模板航次 ID
+	CruiseID         int64     `gorm:"index;not null" json:"cruise_id"`             // 执行邮轮 ID（取自模板航次）
+	CodePrefix       string    `gorm:"size:30;not null" json:"code_prefix"`         // 航次编码前缀，编码为前缀 + 出发日期 YYYYMMDD
+	Frequency        string    `gorm:"size:20;not null" json:"frequency"`           // 重复频率：daily/weekly/monthly
+	RepeatInterval   int       `gorm:"not null" json:"repeat_interval"`             // 重复间隔（天/周/月）
+	Weekdays         string    `gorm:"size:20" json:"weekdays"`                     // 每周出发的星期（0=周日，逗号分隔），仅 weekly 使用
+	StartDate        time.Time `json:"start_date"`                                  // 首个可出发日期
+	EndDate          time.Time `json:"end_date"`                                    // 最后可出发日期（含）
+	CabinPricesJSON  string    `gorm:"column:cabin_prices_json;type:text" json:"-"` // 初始舱型价格 JSON
+	VoyageCount      int       `json:"voyage_count"`                                // 生成的航次数
+	CreatedBy        *int64    `json:"created_by,omitempty"`                        // 创建人员工 ID
+	CreatedAt        time.Time `json:"created_at"`                                  // 创建时间
+	UpdatedAt        time.Time `json:"updated_at"`                                  // 更新时间
+
+	CabinPrices []VoyageSeriesCabinPrice `gorm:"-" json:"cabin_prices,omitempty"` // 初始舱型价格
+	Voyages     []Voyage                 `gorm:"-" json:"voyages,omitempty"`      // 系列下的航次（详情辅助字段）
+}
+
+// TableName 指定航次系列表名。
+func (VoyageSeries) TableName() string {
+	return "voyage_series"
+}
+
+// VoyageSeriesCabinPrice 描述系列航次某舱型的初始库存与价格。
+type VoyageSeriesCabinPrice struct {
+	CabinTypeID          int64 `json:"cabin_type_id"`          // 舱型 ID
+	InventoryTotal       int   `json:"inventory_total"`        // 可售库存，0 表示按克隆的舱位库存汇总
+	SettlementPriceCents int64 `json:"settlement_price_cents"` // 结算价（分）
+	SalePriceCents       int64 `json:"sale_price_cents"`       // 销售价（分）
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	WaitlistStatusWaiting   = "waiting"   // 排队等待释放库存
+	WaitlistStatusOffered   = "offered"   // 已为其独占占座，等待在有效期内下单
+	WaitlistStatusBooked    = "booked"    // 已在独占期内完成下单
+	WaitlistStatusExpired   = "expired"   // 独占期内未下单，占座已退回
+	WaitlistStatusCancelled = "cancelled" // 用户主动取消
+)
+
+var (
+	// ErrWaitlistEntryActive 表示用户在该航次舱型上已有排队中或待下单的候补记录。
+	ErrWaitlistEntryActive = errors.New("waitlist entry already active")
+	// ErrWaitlistEntryClosed 表示候补记录已下单、过期或取消，不能再变更。
+	ErrWaitlistEntryClosed = errors.New("waitlist entry is closed")
+)
+
+// WaitlistEntry 表示用户对售罄航次舱型的候补登记。
+// 库存释放时按登记先后为排队用户独占占座（OfferHoldID），并在 OfferExpiresAt 前保留给该用户下单。
+type WaitlistEntry struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`                                                 // 主键 ID
+	UserID         int64      `gorm:"index;not null" json:"user_id"`                                        // 候补用户 ID
+	VoyageID       int64      `gorm:"index:idx_waitlist_entries_voyage_type;not null" json:"voyage_id"`     // 航次 ID
+	CabinTypeID    int64      `gorm:"index:idx_waitlist_entries_voyage_type;not null" json:"cabin_type_id"` // 舱房类型 ID
+	Guests         int        `gorm:"not null" json:"guests"`                                               // 期望入住人数
+	Status         string     `gorm:"size:20;index;not null" json:"status"`                                 // 状态：waiting/offered/booked/expired/cancelled
+	OfferedSKUID   int64      `gorm:"column:offered_sku_id" json:"offered_sku_id,omitempty"`                // 独占占座的舱房 SKU ID
+	OfferHoldID    int64      `json:"offer_hold_id,omitempty"`                                              // 独占占座记录 ID
+	OfferedAt      *time.Time `json:"offered_at,omitempty"`                                                 // 发出候补邀约时间
+	OfferExpiresAt *time.Time `gorm:"index" json:"offer_expires_at,omitempty"`                              // 独占占座到期时间
+	BookingID      int64      `json:"booking_id,omitempty"`                                                 // 独占期内创建的订单 ID
+	CreatedAt      time.Time  `json:"created_at"`                                                           // 登记时间，决定排队顺序
+	UpdatedAt      time.Time  `json:"updated_at"`                                                           // 更新时间
+}
+
+// Active 判断候补记录是否仍在排队或等待下单。
+func (e WaitlistEntry) Active() bool {
+	return e.Status == WaitlistStatusWaiting || e.Status == WaitlistStatusOffered
+}
+
+// WaitlistFilter 定义后台查询候补记录的过滤条件，零值字段表示不过滤。
+type WaitlistFilter struct {
+	VoyageID    int64  // 航次 ID
+	CabinTypeID int64  // 舱房类型 ID
+	UserID      int64  // 用户 ID
+	Status      string // 状态
+}
+
+// WaitlistTarget 表示存在排队用户的航次舱型。
+type WaitlistTarget struct {
+	VoyageID    int64 // 航次 ID
+	CabinTypeID int64 // 舱房类型 ID
+}
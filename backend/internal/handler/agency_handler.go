@@ -0,0 +1,456 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AgencyAdminService 定义后台管理分销商所需的能力。
+type AgencyAdminService interface {
+	ListAgencies(ctx context.Context, keyword string, page, pageSize int) ([]domain.Agency, int64, error)
+	GetAgency(ctx context.Context, id int64) (*domain.Agency, error)
+	CreateAgency(ctx context.Context, agency *domain.Agency, password string) error
+	UpdateAgency(ctx context.Context, agency *domain.Agency) error
+	ResetPassword(ctx context.Context, id int64, password string) error
+	CreateAPIKey(ctx context.Context, agencyID int64, name string) (*domain.AgencyAPIKey, string, error)
+	ListAPIKeys(ctx context.Context, agencyID int64) ([]domain.AgencyAPIKey, error)
+	RevokeAPIKey(ctx context.Context, agencyID, keyID int64) error
+	CreateAllotment(ctx context.Context, allotment *domain.AgencyAllotment) error
+	ListAllotments(ctx context.Context, agencyID, voyageID int64) ([]domain.AgencyAllotment, error)
+	ReleaseAllotment(ctx context.Context, agencyID, allotmentID int64) (int, error)
+	UpsertNetRate(ctx context.Context, rate *domain.AgencyNetRate) error
+	ListNetRates(ctx context.Context, agencyID int64) ([]domain.AgencyNetRate, error)
+	DeleteNetRate(ctx context.Context, agencyID, id int64) error
+	Credit(ctx context.Context, agencyID int64) (*service.AgencyCredit, error)
+	ListBookings(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyBookingView, int64, error)
+	IssueStatement(ctx context.Context, agencyID int64, period string, staffID int64) (*domain.AgencyStatement, error)
+	ListStatements(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyStatement, int64, error)
+	MarkStatementPaid(ctx context.Context, agencyID, statementID int64) (*domain.AgencyStatement, error)
+}
+
+// AgencyHandler 提供 B2B 分销商账户、配额、净价、信用额度与月结账单的管理端点。
+type AgencyHandler struct {
+	svc AgencyAdminService
+}
+
+// NewAgencyHandler 创建分销商管理处理器。
+func NewAgencyHandler(svc AgencyAdminService) *AgencyHandler {
+	return &AgencyHandler{svc: svc}
+}
+
+type agencyPayload struct {
+	Code              string  `json:"code" binding:"required,max=50"`
+	Name              string  `json:"name" binding:"required,max=100"`
+	ContactName       string  `json:"contact_name" binding:"max=50"`
+	ContactPhone      string  `json:"contact_phone" binding:"max=20"`
+	Email             string  `json:"email" binding:"max=100"`
+	LoginName         string  `json:"login_name" binding:"required,max=50"`
+	Password          string  `json:"password"`
+	CommissionPercent float64 `json:"commission_percent"`
+	CreditLimitCents  int64   `json:"credit_limit_cents"`
+	Status            *int16  `json:"status"`
+}
+
+type agencyPasswordPayload struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type agencyAPIKeyPayload struct {
+	Name string `json:"name" binding:"max=100"`
+}
+
+type agencyAllotmentPayload struct {
+	VoyageID   int64  `json:"voyage_id"`
+	CabinSKUID int64  `json:"cabin_sku_id" binding:"required"`
+	Quantity   int    `json:"quantity" binding:"required"`
+	ReleaseAt  string `json:"release_at" binding:"required"`
+}
+
+type agencyNetRatePayload struct {
+	VoyageID      int64 `json:"voyage_id" binding:"required"`
+	CabinTypeID   int64 `json:"cabin_type_id" binding:"required"`
+	NetPriceCents int64 `json:"net_price_cents"`
+}
+
+type agencyStatementPayload struct {
+	Period string `json:"period" binding:"required"`
+}
+
+func (p agencyPayload) toAgency(id int64) *domain.Agency {
+	status := int16(1)
+	if p.Status != nil {
+		status = *p.Status
+	}
+	return &domain.Agency{
+		ID:                id,
+		Code:              p.Code,
+		Name:              p.Name,
+		ContactName:       p.ContactName,
+		ContactPhone:      p.ContactPhone,
+		Email:             p.Email,
+		LoginName:         p.LoginName,
+		CommissionPercent: p.CommissionPercent,
+		CreditLimitCents:  p.CreditLimitCents,
+		Status:            status,
+	}
+}
+
+// List 处理 GET /admin/agencies。
+func (h *AgencyHandler) List(c *gin.Context) {
+	items, total, err := h.svc.ListAgencies(c.Request.Context(), c.Query("keyword"), queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// Get 处理 GET /admin/agencies/:id。
+func (h *AgencyHandler) Get(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	agency, err := h.svc.GetAgency(c.Request.Context(), id)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, agency)
+}
+
+// Create 处理 POST /admin/agencies，同时设置分销端登录密码。
+func (h *AgencyHandler) Create(c *gin.Context) {
+	var req agencyPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	agency := req.toAgency(0)
+	if err := h.svc.CreateAgency(c.Request.Context(), agency, req.Password); err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, agency)
+}
+
+// Update 处理 PUT /admin/agencies/:id，密码需通过单独接口重置。
+func (h *AgencyHandler) Update(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	var req agencyPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	agency := req.toAgency(id)
+	if err := h.svc.UpdateAgency(c.Request.Context(), agency); err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, agency)
+}
+
+// ResetPassword 处理 PUT /admin/agencies/:id/password。
+func (h *AgencyHandler) ResetPassword(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	var req agencyPasswordPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if err := h.svc.ResetPassword(c.Request.Context(), id, req.Password); err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ListAPIKeys 处理 GET /admin/agencies/:id/api-keys。
+func (h *AgencyHandler) ListAPIKeys(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	keys, err := h.svc.ListAPIKeys(c.Request.Context(), id)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": keys, "total": len(keys)})
+}
+
+// CreateAPIKey 处理 POST /admin/agencies/:id/api-keys，明文 Key 仅在本次响应中返回。
+func (h *AgencyHandler) CreateAPIKey(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	var req agencyAPIKeyPayload
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+			return
+		}
+	}
+	key, plain, err := h.svc.CreateAPIKey(c.Request.Context(), id, req.Name)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"key": key, "api_key": plain})
+}
+
+// RevokeAPIKey 处理 DELETE /admin/agencies/:id/api-keys/:keyId。
+func (h *AgencyHandler) RevokeAPIKey(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	keyID, ok := parseAgencyPathID(c, "keyId")
+	if !ok {
+		return
+	}
+	if err := h.svc.RevokeAPIKey(c.Request.Context(), id, keyID); err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ListAllotments 处理 GET /admin/agencies/:id/allotments，可按 voyage_id 过滤。
+func (h *AgencyHandler) ListAllotments(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	items, err := h.svc.ListAllotments(c.Request.Context(), id, queryInt64(c, "voyage_id", 0))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": len(items)})
+}
+
+// CreateAllotment 处理 POST /admin/agencies/:id/allotments，预留配额并扣减公共库存。
+func (h *AgencyHandler) CreateAllotment(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	var req agencyAllotmentPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	releaseAt, err := parseEffectiveAtInShanghai(req.ReleaseAt)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid release_at")
+		return
+	}
+	allotment := &domain.AgencyAllotment{
+		AgencyID:   id,
+		VoyageID:   req.VoyageID,
+		CabinSKUID: req.CabinSKUID,
+		Quantity:   req.Quantity,
+		ReleaseAt:  releaseAt.In(time.UTC),
+		CreatedBy:  parseOperatorID(c),
+	}
+	if err := h.svc.CreateAllotment(c.Request.Context(), allotment); err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, allotment)
+}
+
+// ReleaseAllotment 处理 POST /admin/agencies/:id/allotments/:allotmentId/release，提前释放未用配额。
+func (h *AgencyHandler) ReleaseAllotment(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	allotmentID, ok := parseAgencyPathID(c, "allotmentId")
+	if !ok {
+		return
+	}
+	released, err := h.svc.ReleaseAllotment(c.Request.Context(), id, allotmentID)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"released": released})
+}
+
+// ListNetRates 处理 GET /admin/agencies/:id/net-rates。
+func (h *AgencyHandler) ListNetRates(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	items, err := h.svc.ListNetRates(c.Request.Context(), id)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": len(items)})
+}
+
+// UpsertNetRate 处理 POST /admin/agencies/:id/net-rates，按航次 + 舱型覆盖写入净价。
+func (h *AgencyHandler) UpsertNetRate(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	var req agencyNetRatePayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	rate := &domain.AgencyNetRate{AgencyID: id, VoyageID: req.VoyageID, CabinTypeID: req.CabinTypeID, NetPriceCents: req.NetPriceCents}
+	if err := h.svc.UpsertNetRate(c.Request.Context(), rate); err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, rate)
+}
+
+// DeleteNetRate 处理 DELETE /admin/agencies/:id/net-rates/:rateId。
+func (h *AgencyHandler) DeleteNetRate(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	rateID, ok := parseAgencyPathID(c, "rateId")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteNetRate(c.Request.Context(), id, rateID); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Credit 处理 GET /admin/agencies/:id/credit。
+func (h *AgencyHandler) Credit(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	credit, err := h.svc.Credit(c.Request.Context(), id)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, credit)
+}
+
+// ListBookings 处理 GET /admin/agencies/:id/bookings。
+func (h *AgencyHandler) ListBookings(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	items, total, err := h.svc.ListBookings(c.Request.Context(), id, queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// ListStatements 处理 GET /admin/agencies/:id/statements。
+func (h *AgencyHandler) ListStatements(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	items, total, err := h.svc.ListStatements(c.Request.Context(), id, queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// IssueStatement 处理 POST /admin/agencies/:id/statements，生成指定账期（YYYY-MM）的月结账单。
+func (h *AgencyHandler) IssueStatement(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	var req agencyStatementPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	statement, err := h.svc.IssueStatement(c.Request.Context(), id, req.Period, parseOperatorID(c))
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, statement)
+}
+
+// MarkStatementPaid 处理 POST /admin/agencies/:id/statements/:statementId/paid。
+func (h *AgencyHandler) MarkStatementPaid(c *gin.Context) {
+	id, ok := parseAgencyPathID(c, "id")
+	if !ok {
+		return
+	}
+	statementID, ok := parseAgencyPathID(c, "statementId")
+	if !ok {
+		return
+	}
+	statement, err := h.svc.MarkStatementPaid(c.Request.Context(), id, statementID)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, statement)
+}
+
+func parseAgencyPathID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+func respondAgencyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAgencyRequest):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrAgencyNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrAgencyInvalidCredentials):
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "invalid credentials")
+	case errors.Is(err, service.ErrAgencyDisabled):
+		response.Error(c, http.StatusForbidden, errcode.ErrForbidden, err.Error())
+	case errors.Is(err, service.ErrAgencyLoginNameTaken),
+		errors.Is(err, service.ErrAgencyAllotmentExhausted),
+		errors.Is(err, service.ErrAgencyCreditExceeded),
+		errors.Is(err, service.ErrAgencyInventoryInsufficient),
+		errors.Is(err, service.ErrAgencyStatementExists),
+		errors.Is(err, service.ErrAgencyStatementNotIssued):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAgencyAdminSvc struct {
+	err           error
+	lastAgency    *domain.Agency
+	lastPassword  string
+	lastAllotment *domain.AgencyAllotment
+	lastRate      *domain.AgencyNetRate
+	lastPeriod    string
+	lastStaffID   int64
+	lastIDs       [2]int64
+}
+
+func (f *fakeAgencyAdminSvc) ListAgencies(_ context.Context, _ string, _, _ int) ([]domain.Agency, int64, error) {
+	return []domain.Agency{{ID: 1, Name: "海天旅行社"}}, 1, f.err
+}
+
+func (f *fakeAgencyAdminSvc) GetAgency(_ context.Context, id int64) (*domain.Agency, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Agency{ID: id}, nil
+}
+
+func (f *fakeAgencyAdminSvc) CreateAgency(_ context.Context, agency *domain.Agency, password string) error {
+	f.lastAgency = agency
+	f.lastPassword = password
+	agency.ID = 5
+	return f.err
+}
+
+func (f *fakeAgencyAdminSvc) UpdateAgency(_ context.Context, agency *domain.Agency) error {
+	f.lastAgency = agency
+	return f.err
+}
+
+func (f *fakeAgencyAdminSvc) ResetPassword(_ context.Context, _ int64, password string) error {
+	f.lastPassword = password
+	return f.err
+}
+
+func (f *fakeAgencyAdminSvc) CreateAPIKey(_ context.Context, agencyID int64, name string) (*domain.AgencyAPIKey, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	return &domain.AgencyAPIKey{ID: 2, AgencyID: agencyID, Name: name, Prefix: "ak_abc"}, "ak_abc.secret", nil
+}
+
+func (f *fakeAgencyAdminSvc) ListAPIKeys(_ context.Context, _ int64) ([]domain.AgencyAPIKey, error) {
+	return nil, f.err
+}
+
+func (f *fakeAgencyAdminSvc) RevokeAPIKey(_ context.Context, agencyID, keyID int64) error {
+	f.lastIDs = [2]int64{agencyID, keyID}
+	return f.err
+}
+
+func (f *fakeAgencyAdminSvc) CreateAllotment(_ context.Context, allotment *domain.AgencyAllotment) error {
+	f.lastAllotment = allotment
+	return f.err
+}
+
+func (f *fakeAgencyAdminSvc) ListAllotments(_ context.Context, _, _ int64) ([]domain.AgencyAllotment, error) {
+	return nil, f.err
+}
+
+func (f *fakeAgencyAdminSvc) ReleaseAllotment(_ context.Context, agencyID, allotmentID int64) (int, error) {
+	f.lastIDs = [2]int64{agencyID, allotmentID}
+	return 2, f.err
+}
+
+func (f *fakeAgencyAdminSvc) UpsertNetRate(_ context.Context, rate *domain.AgencyNetRate) error {
+	f.lastRate = rate
+	return f.err
+}
+
+func (f *fakeAgencyAdminSvc) ListNetRates(_ context.Context, _ int64) ([]domain.AgencyNetRate, error) {
+	return nil, f.err
+}
+
+func (f *fakeAgencyAdminSvc) DeleteNetRate(_ context.Context, _, _ int64) error { return f.err }
+
+func (f *fakeAgencyAdminSvc) Credit(_ context.Context, _ int64) (*service.AgencyCredit, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.AgencyCredit{LimitCents: 100, UsedCents: 40, AvailableCents: 60}, nil
+}
+
+func (f *fakeAgencyAdminSvc) ListBookings(_ context.Context, _ int64, _, _ int) ([]domain.AgencyBookingView, int64, error) {
+	return nil, 0, f.err
+}
+
+func (f *fakeAgencyAdminSvc) IssueStatement(_ context.Context, agencyID int64, period string, staffID int64) (*domain.AgencyStatement, error) {
+	f.lastPeriod = period
+	f.lastStaffID = staffID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.AgencyStatement{ID: 3, AgencyID: agencyID, Period: period}, nil
+}
+
+func (f *fakeAgencyAdminSvc) ListStatements(_ context.Context, _ int64, _, _ int) ([]domain.AgencyStatement, int64, error) {
+	return nil, 0, f.err
+}
+
+func (f *fakeAgencyAdminSvc) MarkStatementPaid(_ context.Context, agencyID, statementID int64) (*domain.AgencyStatement, error) {
+	f.lastIDs = [2]int64{agencyID, statementID}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.AgencyStatement{ID: statementID, Status: domain.AgencyStatementStatusPaid}, nil
+}
+
+func newAgencyTestRouter(svc *fakeAgencyAdminSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewAgencyHandler(svc)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyStaffID, int64(7))
+		c.Next()
+	})
+	r.GET("/agencies", h.List)
+	r.POST("/agencies", h.Create)
+	r.GET("/agencies/:id", h.Get)
+	r.PUT("/agencies/:id", h.Update)
+	r.PUT("/agencies/:id/password", h.ResetPassword)
+	r.POST("/agencies/:id/api-keys", h.CreateAPIKey)
+	r.DELETE("/agencies/:id/api-keys/:keyId", h.RevokeAPIKey)
+	r.POST("/agencies/:id/allotments", h.CreateAllotment)
+	r.POST("/agencies/:id/allotments/:allotmentId/release", h.ReleaseAllotment)
+	r.POST("/agencies/:id/net-rates", h.UpsertNetRate)
+	r.GET("/agencies/:id/credit", h.Credit)
+	r.POST("/agencies/:id/statements", h.IssueStatement)
+	r.POST("/agencies/:id/statements/:statementId/paid", h.MarkStatementPaid)
+	return r
+}
+
+func doAgencyRequest(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAgencyHandler_CreateAndUpdate(t *testing.T) {
+	svc := &fakeAgencyAdminSvc{}
+	r := newAgencyTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/agencies", `{"code":"TA01","name":"海天旅行社","login_name":"haitian","password":"s3cret-pass","commission_percent":8,"credit_limit_cents":500000}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.lastAgency)
+	assert.Equal(t, "s3cret-pass", svc.lastPassword)
+	assert.Equal(t, int16(1), svc.lastAgency.Status, "未传 status 默认启用")
+	assert.NotContains(t, w.Body.String(), "password_hash")
+
+	w = doAgencyRequest(r, http.MethodPost, "/agencies", `{"name":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrAgencyLoginNameTaken
+	w = doAgencyRequest(r, http.MethodPut, "/agencies/5", `{"code":"TA01","name":"x","login_name":"taken"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	svc.err = service.ErrAgencyNotFound
+	w = doAgencyRequest(r, http.MethodGet, "/agencies/5", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	svc.err = fmt.Errorf("%w: password too short", service.ErrInvalidAgencyRequest)
+	w = doAgencyRequest(r, http.MethodPut, "/agencies/5/password", `{"password":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAgencyHandler_APIKeys(t *testing.T) {
+	svc := &fakeAgencyAdminSvc{}
+	r := newAgencyTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/agencies/5/api-keys", `{"name":"OTA"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"api_key":"ak_abc.secret"`)
+
+	w = doAgencyRequest(r, http.MethodDelete, "/agencies/5/api-keys/2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, [2]int64{5, 2}, svc.lastIDs)
+
+	w = doAgencyRequest(r, http.MethodDelete, "/agencies/5/api-keys/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAgencyHandler_AllotmentsAndRates(t *testing.T) {
+	svc := &fakeAgencyAdminSvc{}
+	r := newAgencyTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/agencies/5/allotments", `{"voyage_id":10,"cabin_sku_id":1,"quantity":4,"release_at":"2026-12-01 00:00:00"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.lastAllotment)
+	assert.Equal(t, int64(5), svc.lastAllotment.AgencyID)
+	assert.Equal(t, int64(7), svc.lastAllotment.CreatedBy)
+	assert.Equal(t, "2026-11-30T16:00:00Z", svc.lastAllotment.ReleaseAt.Format("2006-01-02T15:04:05Z07:00"), "释放日期按上海时间解析")
+
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/allotments", `{"cabin_sku_id":1,"quantity":4,"release_at":"soon"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/allotments/9/release", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"released":2`)
+
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/net-rates", `{"voyage_id":10,"cabin_type_id":100,"net_price_cents":8000}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(8000), svc.lastRate.NetPriceCents)
+
+	svc.err = service.ErrAgencyInventoryInsufficient
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/allotments", `{"cabin_sku_id":1,"quantity":40,"release_at":"2026-12-01"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAgencyHandler_CreditAndStatements(t *testing.T) {
+	svc := &fakeAgencyAdminSvc{}
+	r := newAgencyTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/agencies/5/credit", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"available_cents":60`)
+
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/statements", `{"period":"2026-09"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2026-09", svc.lastPeriod)
+	assert.Equal(t, int64(7), svc.lastStaffID)
+
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/statements/3/paid", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, [2]int64{5, 3}, svc.lastIDs)
+
+	svc.err = service.ErrAgencyStatementExists
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/statements", `{"period":"2026-09"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	svc.err = service.ErrAgencyStatementNotIssued
+	w = doAgencyRequest(r, http.MethodPost, "/agencies/5/statements/3/paid", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AgencyPortalService 定义分销端自助查询所需的能力。
+type AgencyPortalService interface {
+	Login(ctx context.Context, loginName, password string) (string, time.Time, *domain.Agency, error)
+	GetAgency(ctx context.Context, id int64) (*domain.Agency, error)
+	Credit(ctx context.Context, agencyID int64) (*service.AgencyCredit, error)
+	ListAllotments(ctx context.Context, agencyID, voyageID int64) ([]domain.AgencyAllotment, error)
+	ListBookings(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyBookingView, int64, error)
+	ListStatements(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyStatement, int64, error)
+}
+
+// AgencyBookingCreator 定义分销渠道下单能力，由 BookingService 实现。
+type AgencyBookingCreator interface {
+	CreateForAgency(ctx context.Context, req service.AgencyBookingRequest) (*domain.Booking, error)
+}
+
+// AgencyPortalHandler 提供分销端（旅行社）登录、配额查询、下单与账单查询端点。
+type AgencyPortalHandler struct {
+	svc      AgencyPortalService
+	bookings AgencyBookingCreator
+}
+
+// NewAgencyPortalHandler 创建分销端处理器。
+func NewAgencyPortalHandler(svc AgencyPortalService, bookings AgencyBookingCreator) *AgencyPortalHandler {
+	return &AgencyPortalHandler{svc: svc, bookings: bookings}
+}
+
+type agencyLoginPayload struct {
+	LoginName string `json:"login_name" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+}
+
+type agencyBookingPayload struct {
+	VoyageID   int64  `json:"voyage_id" binding:"required"`
+	CabinSKUID int64  `json:"cabin_sku_id" binding:"required"`
+	Guests     int    `json:"guests" binding:"required,min=1"`
+	AgencyRef  string `json:"agency_ref" binding:"max=64"`
+}
+
+// Login 处理 POST /api/v1/agency/auth/login。
+func (h *AgencyPortalHandler) Login(c *gin.Context) {
+	var req agencyLoginPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	token, expireAt, agency, err := h.svc.Login(c.Request.Context(), req.LoginName, req.Password)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"token": token, "expire_at": expireAt, "agency": agency})
+}
+
+// Profile 处理 GET /api/v1/agency/profile，返回分销商资料与信用额度。
+func (h *AgencyPortalHandler) Profile(c *gin.Context) {
+	agencyID := currentAgencyID(c)
+	agency, err := h.svc.GetAgency(c.Request.Context(), agencyID)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	credit, err := h.svc.Credit(c.Request.Context(), agencyID)
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"agency": agency, "credit": credit})
+}
+
+// Allotments 处理 GET /api/v1/agency/allotments，可按 voyage_id 过滤。
+func (h *AgencyPortalHandler) Allotments(c *gin.Context) {
+	items, err := h.svc.ListAllotments(c.Request.Context(), currentAgencyID(c), queryInt64(c, "voyage_id", 0))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": len(items)})
+}
+
+// CreateBooking 处理 POST /api/v1/agency/bookings，占用配额并按净价下单。
+func (h *AgencyPortalHandler) CreateBooking(c *gin.Context) {
+	var req agencyBookingPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	booking, err := h.bookings.CreateForAgency(c.Request.Context(), service.AgencyBookingRequest{
+		AgencyID:   currentAgencyID(c),
+		VoyageID:   req.VoyageID,
+		CabinSKUID: req.CabinSKUID,
+		Guests:     req.Guests,
+		AgencyRef:  req.AgencyRef,
+	})
+	if err != nil {
+		respondAgencyError(c, err)
+		return
+	}
+	response.Success(c, booking)
+}
+
+// Bookings 处理 GET /api/v1/agency/bookings。
+func (h *AgencyPortalHandler) Bookings(c *gin.Context) {
+	items, total, err := h.svc.ListBookings(c.Request.Context(), currentAgencyID(c), queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// Statements 处理 GET /api/v1/agency/statements。
+func (h *AgencyPortalHandler) Statements(c *gin.Context) {
+	items, total, err := h.svc.ListStatements(c.Request.Context(), currentAgencyID(c), queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// currentAgencyID 读取分销端鉴权中间件写入的分销商 ID。
+func currentAgencyID(c *gin.Context) int64 {
+	return c.GetInt64(middleware.ContextKeyAgencyID)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAgencyPortalSvc struct {
+	loginErr error
+}
+
+func (f *fakeAgencyPortalSvc) Login(_ context.Context, loginName, _ string) (string, time.Time, *domain.Agency, error) {
+	if f.loginErr != nil {
+		return "", time.Time{}, nil, f.loginErr
+	}
+	return "agency-token", time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), &domain.Agency{ID: 3, LoginName: loginName}, nil
+}
+
+func (f *fakeAgencyPortalSvc) GetAgency(_ context.Context, id int64) (*domain.Agency, error) {
+	return &domain.Agency{ID: id, Name: "海天旅行社"}, nil
+}
+
+func (f *fakeAgencyPortalSvc) Credit(_ context.Context, _ int64) (*service.AgencyCredit, error) {
+	return &service.AgencyCredit{LimitCents: 100, UsedCents: 10, AvailableCents: 90}, nil
+}
+
+func (f *fakeAgencyPortalSvc) ListAllotments(_ context.Context, agencyID, _ int64) ([]domain.AgencyAllotment, error) {
+	return []domain.AgencyAllotment{{ID: 1, AgencyID: agencyID}}, nil
+}
+
+func (f *fakeAgencyPortalSvc) ListBookings(_ context.Context, _ int64, _, _ int) ([]domain.AgencyBookingView, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeAgencyPortalSvc) ListStatements(_ context.Context, _ int64, _, _ int) ([]domain.AgencyStatement, int64, error) {
+	return nil, 0, nil
+}
+
+type fakeAgencyBookingCreator struct {
+	err     error
+	lastReq service.AgencyBookingRequest
+}
+
+func (f *fakeAgencyBookingCreator) CreateForAgency(_ context.Context, req service.AgencyBookingRequest) (*domain.Booking, error) {
+	f.lastReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Booking{ID: 11, Channel: domain.BookingChannelAgency, TotalCents: 9000}, nil
+}
+
+func newAgencyPortalTestRouter(svc *fakeAgencyPortalSvc, bookings *fakeAgencyBookingCreator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewAgencyPortalHandler(svc, bookings)
+	r := gin.New()
+	r.POST("/auth/login", h.Login)
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyAgencyID, int64(3))
+		c.Next()
+	})
+	r.GET("/profile", h.Profile)
+	r.GET("/allotments", h.Allotments)
+	r.POST("/bookings", h.CreateBooking)
+	return r
+}
+
+func TestAgencyPortalHandler_Login(t *testing.T) {
+	svc := &fakeAgencyPortalSvc{}
+	r := newAgencyPortalTestRouter(svc, &fakeAgencyBookingCreator{})
+
+	w := doAgencyRequest(r, http.MethodPost, "/auth/login", `{"login_name":"haitian","password":"s3cret-pass"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"token":"agency-token"`)
+
+	svc.loginErr = service.ErrAgencyInvalidCredentials
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"login_name":"haitian","password":"bad"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	svc.loginErr = service.ErrAgencyDisabled
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"login_name":"haitian","password":"s3cret-pass"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAgencyPortalHandler_ProfileAndAllotments(t *testing.T) {
+	r := newAgencyPortalTestRouter(&fakeAgencyPortalSvc{}, &fakeAgencyBookingCreator{})
+
+	w := doAgencyRequest(r, http.MethodGet, "/profile", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"available_cents":90`)
+
+	w = doAgencyRequest(r, http.MethodGet, "/allotments", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"agency_id":3`)
+}
+
+func TestAgencyPortalHandler_CreateBooking(t *testing.T) {
+	bookings := &fakeAgencyBookingCreator{}
+	r := newAgencyPortalTestRouter(&fakeAgencyPortalSvc{}, bookings)
+
+	w := doAgencyRequest(r, http.MethodPost, "/bookings", `{"voyage_id":10,"cabin_sku_id":1,"guests":2,"agency_ref":"OTA-1"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(3), bookings.lastReq.AgencyID, "分销商 ID 取自鉴权上下文")
+	assert.Equal(t, "OTA-1", bookings.lastReq.AgencyRef)
+
+	w = doAgencyRequest(r, http.MethodPost, "/bookings", `{"voyage_id":10,"cabin_sku_id":1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	bookings.err = service.ErrAgencyAllotmentExhausted
+	w = doAgencyRequest(r, http.MethodPost, "/bookings", `{"voyage_id":10,"cabin_sku_id":1,"guests":2}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	bookings.err = service.ErrAgencyCreditExceeded
+	w = doAgencyRequest(r, http.MethodPost, "/bookings", `{"voyage_id":10,"cabin_sku_id":1,"guests":2}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
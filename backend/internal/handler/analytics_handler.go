@@ -2,10 +2,17 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/pkg/errcode"
 	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/pkg/xlsx"
+	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,14 +23,28 @@ type AnalyticsSummaryService interface {
 	TodayOrderCount(ctx context.Context) (int64, error)
 }
 
+// AnalyticsReportService 提供可配置分析查询与报表导出。
+type AnalyticsReportService interface {
+	Query(ctx context.Context, q service.AnalyticsQuery) (*service.AnalyticsQueryResult, error)
+	Export(ctx context.Context, q service.AnalyticsQuery, format string) ([]byte, error)
+}
+
 // AnalyticsHandler 提供仪表盘分析相关的 HTTP 端点。
-type AnalyticsHandler struct{ svc AnalyticsSummaryService }
+type AnalyticsHandler struct {
+	svc       AnalyticsSummaryService
+	reportSvc AnalyticsReportService
+}
 
 // NewAnalyticsHandler 使用给定的服务创建 AnalyticsHandler 实例。
 func NewAnalyticsHandler(svc AnalyticsSummaryService) *AnalyticsHandler {
 	return &AnalyticsHandler{svc: svc}
 }
 
+// SetReportService 注入可配置分析查询服务。
+func (h *AnalyticsHandler) SetReportService(reportSvc AnalyticsReportService) {
+	h.reportSvc = reportSvc
+}
+
 // Summary 处理 GET /admin/analytics/summary 请求。
 // 返回今日销售总额、过去7天的趋势以及今日订单数。
 func (h *AnalyticsHandler) Summary(c *gin.Context) {
@@ -53,3 +74,88 @@ func (h *AnalyticsHandler) Summary(c *gin.Context) {
 		"today_orders": todayOrders,
 	})
 }
+
+// Query 处理 GET /admin/analytics/query 请求。
+// 按日期区间、时间粒度与维度返回 GMV、订单数、退款率、上座率与客单价。
+func (h *AnalyticsHandler) Query(c *gin.Context) {
+	if h.reportSvc == nil {
+		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, "analytics report service unavailable")
+		return
+	}
+	q, ok := parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+	result, err := h.reportSvc.Query(c.Request.Context(), q)
+	if err != nil {
+		respondAnalyticsError(c, err)
+		return
+	}
+	response.Success(c, result)
+}
+
+// Export 处理 GET /admin/analytics/export 请求，format=csv|xlsx。
+func (h *AnalyticsHandler) Export(c *gin.Context) {
+	if h.reportSvc == nil {
+		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, "analytics report service unavailable")
+		return
+	}
+	q, ok := parseAnalyticsQuery(c)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", service.AnalyticsExportCSV)))
+	data, err := h.reportSvc.Export(c.Request.Context(), q, format)
+	if err != nil {
+		respondAnalyticsError(c, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == service.AnalyticsExportXLSX {
+		contentType = xlsx.ContentType
+	}
+	filename := fmt.Sprintf("analytics_%s.%s", time.Now().Format("20060102_150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// parseAnalyticsQuery 从查询参数构造分析查询；解析失败时直接写入 400 响应。
+func parseAnalyticsQuery(c *gin.Context) (service.AnalyticsQuery, bool) {
+	q := service.AnalyticsQuery{
+		StartDate:   c.Query("start_date"),
+		EndDate:     c.Query("end_date"),
+		Granularity: c.Query("granularity"),
+		Channel:     c.Query("channel"),
+	}
+	if raw := strings.TrimSpace(c.Query("dimensions")); raw != "" {
+		q.Dimensions = strings.Split(raw, ",")
+	}
+	ids := map[string]*int64{
+		"company_id":  &q.CompanyID,
+		"cruise_id":   &q.CruiseID,
+		"voyage_id":   &q.VoyageID,
+		"category_id": &q.CategoryID,
+	}
+	for key, target := range ids {
+		raw := strings.TrimSpace(c.Query(key))
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid "+key)
+			return q, false
+		}
+		*target = v
+	}
+	return q, true
+}
+
+func respondAnalyticsError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidAnalyticsQuery) {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	response.InternalError(c, err)
+}
@@ -3,10 +3,13 @@ package handler
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/cruisebooking/backend/internal/pkg/xlsx"
+	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -113,3 +116,96 @@ func TestAnalyticsHandler_Summary_OrderCountError(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+// fakeAnalyticsReportSvc 记录最后一次查询参数并返回预置结果。
+type fakeAnalyticsReportSvc struct {
+	last   service.AnalyticsQuery
+	format string
+	err    error
+}
+
+func (f *fakeAnalyticsReportSvc) Query(_ context.Context, q service.AnalyticsQuery) (*service.AnalyticsQueryResult, error) {
+	f.last = q
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.AnalyticsQueryResult{Granularity: "day", Rows: []service.AnalyticsQueryRow{{Bucket: "2026-03-01", GMVCents: 100}}}, nil
+}
+
+func (f *fakeAnalyticsReportSvc) Export(_ context.Context, q service.AnalyticsQuery, format string) ([]byte, error) {
+	f.last = q
+	f.format = format
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte("bucket\n"), nil
+}
+
+func newAnalyticsReportRouter(report *fakeAnalyticsReportSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewAnalyticsHandler(&fakeAnalyticsSvc{})
+	if report != nil {
+		h.SetReportService(report)
+	}
+	r := gin.New()
+	r.GET("/analytics/query", h.Query)
+	r.GET("/analytics/export", h.Export)
+	return r
+}
+
+// TestAnalyticsHandler_Query_ParsesParams 验证查询参数解析与维度拆分。
+func TestAnalyticsHandler_Query_ParsesParams(t *testing.T) {
+	report := &fakeAnalyticsReportSvc{}
+	r := newAnalyticsReportRouter(report)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/analytics/query?start_date=2026-03-01&end_date=2026-03-31&granularity=week&dimensions=company,channel&company_id=3&channel=agency", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"gmv_cents":100`)
+	assert.Equal(t, "2026-03-01", report.last.StartDate)
+	assert.Equal(t, "week", report.last.Granularity)
+	assert.Equal(t, []string{"company", "channel"}, report.last.Dimensions)
+	assert.Equal(t, int64(3), report.last.CompanyID)
+	assert.Equal(t, "agency", report.last.Channel)
+}
+
+// TestAnalyticsHandler_Query_Errors 覆盖参数非法、服务校验失败与内部错误分支。
+func TestAnalyticsHandler_Query_Errors(t *testing.T) {
+	w := httptest.NewRecorder()
+	newAnalyticsReportRouter(&fakeAnalyticsReportSvc{}).ServeHTTP(w, httptest.NewRequest("GET", "/analytics/query?voyage_id=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	invalid := &fakeAnalyticsReportSvc{err: fmt.Errorf("%w: bad range", service.ErrInvalidAnalyticsQuery)}
+	newAnalyticsReportRouter(invalid).ServeHTTP(w, httptest.NewRequest("GET", "/analytics/query", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	newAnalyticsReportRouter(&fakeAnalyticsReportSvc{err: errors.New("db down")}).ServeHTTP(w, httptest.NewRequest("GET", "/analytics/query", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = httptest.NewRecorder()
+	newAnalyticsReportRouter(nil).ServeHTTP(w, httptest.NewRequest("GET", "/analytics/query", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// TestAnalyticsHandler_Export_Formats 验证导出文件的内容类型与文件名。
+func TestAnalyticsHandler_Export_Formats(t *testing.T) {
+	report := &fakeAnalyticsReportSvc{}
+	r := newAnalyticsReportRouter(report)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/analytics/export?start_date=2026-03-01&end_date=2026-03-02", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "csv", report.format)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/analytics/export?start_date=2026-03-01&end_date=2026-03-02&format=XLSX", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "xlsx", report.format)
+	assert.Equal(t, xlsx.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".xlsx")
+}
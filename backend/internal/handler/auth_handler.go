@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -22,8 +23,10 @@ func NewAuthHandler(authSvc *service.AuthService) *AuthHandler {
 
 // LoginRequest 是 POST /api/v1/admin/auth/login 的请求体结构。
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"` // 登录用户名
-	Password string `json:"password" binding:"required"` // 登录密码
+	Username    string `json:"username" binding:"required"` // 登录用户名
+	Password    string `json:"password" binding:"required"` // 登录密码
+	TOTPCode    string `json:"totp_code"`                   // 二次验证码，已启用或正在绑定 TOTP 时必填
+	NewPassword string `json:"new_password"`                // 新密码，密码过期或需首次修改时必填
 }
 
 // LoginResponse 包含签发的 JWT 令牌和过期时间。
@@ -41,9 +44,12 @@ type LoginResponse struct {
 // @Success 200 {object} response.Response{data=LoginResponse}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Router /api/v1/admin/auth/login [post]
 // Login 处理管理员登录请求。
 // 验证请求参数 → 调用认证服务校验凭据 → 返回 JWT 令牌。
+// 需要二次验证、绑定验证器或修改密码时分别返回 ErrTOTPRequired、ErrTOTPEnrollmentRequired、ErrPasswordChangeRequired，
+// 前端据此补充 totp_code 或 new_password 后重新提交。
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	// 绑定并验证请求参数
@@ -53,9 +59,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// 调用认证服务进行登录验证
-	token, expireAt, err := h.authSvc.Login(c.Request.Context(), req.Username, req.Password)
+	token, expireAt, err := h.authSvc.LoginStaff(c.Request.Context(), service.StaffLoginInput{
+		Username:    req.Username,
+		Password:    req.Password,
+		TOTPCode:    req.TOTPCode,
+		NewPassword: req.NewPassword,
+	})
 	if err != nil {
-		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "invalid credentials")
+		respondStaffAuthError(c, err)
 		return
 	}
 
@@ -88,3 +99,198 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 	response.Success(c, staff)
 }
+
+// ChangePasswordRequest 是 PUT /api/v1/admin/auth/password 的请求体。
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// ChangePassword godoc
+// @Summary Change own password
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} response.Response
+// @Router /api/v1/admin/auth/password [put]
+// ChangePassword 修改当前员工的密码，新密码需满足复杂度与历史规则。
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	staffID := parseStaffID(c)
+	if staffID == 0 {
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "not authenticated")
+		return
+	}
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if err := h.authSvc.ChangePassword(c.Request.Context(), staffID, req.OldPassword, req.NewPassword); err != nil {
+		respondStaffCredentialError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"id": staffID})
+}
+
+// ResetPasswordRequest 是 POST /api/v1/admin/auth/password/reset 的请求体。
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`        // 管理员签发的一次性令牌
+	NewPassword string `json:"new_password" binding:"required"` // 新密码
+}
+
+// ResetPassword godoc
+// @Summary Reset password with a one-time token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.Response
+// @Router /api/v1/admin/auth/password/reset [post]
+// ResetPassword 使用管理员签发的一次性令牌设置新密码（无需登录），成功后解除登录锁定。
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if err := h.authSvc.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		respondStaffAuthError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// EnrollTOTPRequest 是 POST /api/v1/admin/auth/totp/enroll 的请求体。
+type EnrollTOTPRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// EnrollTOTP godoc
+// @Summary Start TOTP enrollment
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body EnrollTOTPRequest true "Login credentials"
+// @Success 200 {object} response.Response{data=service.TOTPEnrollment}
+// @Router /api/v1/admin/auth/totp/enroll [post]
+// EnrollTOTP 凭账号密码生成待确认的 TOTP 密钥（无需登录，供强制二次验证的角色首次绑定），
+// 之后在登录请求中携带首个验证码或调用 ActivateTOTP 完成启用。
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	var req EnrollTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	enrollment, err := h.authSvc.BeginTOTPEnrollment(c.Request.Context(), req.Username, req.Password)
+	if err != nil {
+		respondStaffAuthError(c, err)
+		return
+	}
+	response.Success(c, enrollment)
+}
+
+// TOTPCodeRequest 携带 6 位 TOTP 验证码；关闭二次验证时还需提供当前密码。
+type TOTPCodeRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Password string `json:"password"`
+}
+
+// ActivateTOTP godoc
+// @Summary Activate TOTP for the current staff
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body TOTPCodeRequest true "First TOTP code"
+// @Success 200 {object} response.Response
+// @Router /api/v1/admin/auth/totp/activate [post]
+// ActivateTOTP 提交首个验证码，为当前员工启用二次验证。
+func (h *AuthHandler) ActivateTOTP(c *gin.Context) {
+	staffID := parseStaffID(c)
+	if staffID == 0 {
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "not authenticated")
+		return
+	}
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if err := h.authSvc.ActivateTOTP(c.Request.Context(), staffID, req.Code); err != nil {
+		respondStaffCredentialError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"id": staffID, "totp_enabled": true})
+}
+
+// DisableTOTP godoc
+// @Summary Disable TOTP for the current staff
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body TOTPCodeRequest true "Password and current TOTP code"
+// @Success 200 {object} response.Response
+// @Router /api/v1/admin/auth/totp [delete]
+// DisableTOTP 关闭当前员工的二次验证；所属角色强制二次验证时返回 409。
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	staffID := parseStaffID(c)
+	if staffID == 0 {
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "not authenticated")
+		return
+	}
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if err := h.authSvc.DisableTOTP(c.Request.Context(), staffID, req.Password, req.Code); err != nil {
+		respondStaffCredentialError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"id": staffID, "totp_enabled": false})
+}
+
+// respondStaffCredentialError 用于已登录员工的改密与二次验证操作：当前密码错误返回 400，
+// 避免前端把 401 当作登录态失效而退出。
+func respondStaffCredentialError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrStaffInvalidCredentials) {
+		response.Error(c, http.StatusBadRequest, errcode.ErrPasswordMismatch, "current password is incorrect")
+		return
+	}
+	respondStaffAuthError(c, err)
+}
+
+// respondStaffAuthError 将员工认证与账号安全错误映射为 HTTP 状态码和业务错误码。
+// 停用账号与密码错误返回相同提示，避免泄露账号状态。
+func respondStaffAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrStaffInvalidCredentials), errors.Is(err, service.ErrStaffAccountDisabled):
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "invalid credentials")
+	case errors.Is(err, service.ErrStaffTOTPInvalid):
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, err.Error())
+	case errors.Is(err, service.ErrStaffTOTPRequired):
+		response.Error(c, http.StatusUnauthorized, errcode.ErrTOTPRequired, err.Error())
+	case errors.Is(err, service.ErrStaffAccountLocked):
+		response.Error(c, http.StatusForbidden, errcode.ErrAccountLocked, err.Error())
+	case errors.Is(err, service.ErrStaffTOTPEnrollmentRequired):
+		response.Error(c, http.StatusForbidden, errcode.ErrTOTPEnrollmentRequired, err.Error())
+	case errors.Is(err, service.ErrStaffPasswordChangeRequired):
+		response.Error(c, http.StatusForbidden, errcode.ErrPasswordChangeRequired, err.Error())
+	case errors.Is(err, service.ErrStaffPasswordPolicy), errors.Is(err, service.ErrStaffPasswordReused):
+		response.Error(c, http.StatusBadRequest, errcode.ErrPasswordPolicy, err.Error())
+	case errors.Is(err, service.ErrStaffResetTokenInvalid):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrStaffTOTPAlreadyEnabled), errors.Is(err, service.ErrStaffTOTPRequiredByRole):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	case errors.Is(err, service.ErrStaffNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrStaffManageDenied):
+		response.Error(c, http.StatusForbidden, errcode.ErrForbidden, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/repository"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newStaffSecurityTestRouter 使用内存 SQLite 与真实 AuthService 搭建员工认证路由，
+// 受保护路由通过 X-Staff-ID 请求头模拟 JWT 中间件注入的员工 ID。
+func newStaffSecurityTestRouter(t *testing.T) (*gin.Engine, *service.AuthService, *gorm.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.Staff{}, &domain.StaffPasswordHistory{}, &domain.StaffPasswordResetToken{}))
+	svc := service.NewAuthServiceWithSecurity(repository.NewStaffRepository(db), repository.NewStaffSecurityRepository(db),
+		service.StaffSecurityPolicy{MaxFailedLogins: 2}, nil, "secret", 1)
+	h := NewAuthHandler(svc)
+
+	r := gin.New()
+	r.POST("/auth/login", h.Login)
+	r.POST("/auth/password/reset", h.ResetPassword)
+	r.POST("/auth/totp/enroll", h.EnrollTOTP)
+	authed := r.Group("", func(c *gin.Context) {
+		if id := c.GetHeader("X-Staff-ID"); id != "" {
+			c.Set(middleware.ContextKeyStaffID, id)
+		}
+	})
+	authed.PUT("/auth/password", h.ChangePassword)
+	authed.POST("/auth/totp/activate", h.ActivateTOTP)
+	authed.DELETE("/auth/totp", h.DisableTOTP)
+	return r, svc, db
+}
+
+func decodeResponse(t *testing.T, body []byte) response.Response {
+	t.Helper()
+	var resp response.Response
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp
+}
+
+func TestAuthHandler_LoginErrorCodes(t *testing.T) {
+	r, _, db := newStaffSecurityTestRouter(t)
+	hash, _ := service.HashPassword("Initial-Pass1")
+	require.NoError(t, db.Create(&domain.Staff{ID: 1, Username: "cfo", PasswordHash: hash, Role: "finance", Status: 1}).Error)
+	require.NoError(t, db.Create(&domain.Staff{ID: 2, Username: "clerk", PasswordHash: hash, Role: "operator", Status: 1, MustChangePassword: true}).Error)
+
+	w := doAgencyRequest(r, http.MethodPost, "/auth/login", `{"username":"cfo","password":"Initial-Pass1"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, errcode.ErrTOTPEnrollmentRequired, decodeResponse(t, w.Body.Bytes()).Code)
+
+	w = doAgencyRequest(r, http.MethodPost, "/auth/totp/enroll", `{"username":"cfo","password":"Initial-Pass1"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var enrolled struct {
+		Data service.TOTPEnrollment `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrolled))
+	code, err := service.TOTPCode(enrolled.Data.Secret, time.Now())
+	require.NoError(t, err)
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"username":"cfo","password":"Initial-Pass1","totp_code":"`+code+`"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"token"`)
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"username":"cfo","password":"Initial-Pass1"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, errcode.ErrTOTPRequired, decodeResponse(t, w.Body.Bytes()).Code)
+
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"username":"clerk","password":"Initial-Pass1"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, errcode.ErrPasswordChangeRequired, decodeResponse(t, w.Body.Bytes()).Code)
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"username":"clerk","password":"Initial-Pass1","new_password":"weak"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, errcode.ErrPasswordPolicy, decodeResponse(t, w.Body.Bytes()).Code)
+
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"username":"clerk","password":"wrong"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doAgencyRequest(r, http.MethodPost, "/auth/login", `{"username":"clerk","password":"wrong"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, errcode.ErrAccountLocked, decodeResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestAuthHandler_PasswordAndTOTPSelfService(t *testing.T) {
+	r, svc, db := newStaffSecurityTestRouter(t)
+	hash, _ := service.HashPassword("Initial-Pass1")
+	require.NoError(t, db.Create(&domain.Staff{ID: 7, Username: "agent", PasswordHash: hash, Role: "support", Status: 1}).Error)
+
+	w := doAgencyRequest(r, http.MethodPut, "/auth/password", `{"old_password":"Initial-Pass1","new_password":"Second-Pass2"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "staff id is required")
+	req := func(method, path, body string) int {
+		return doStaffRequest(r, "7", method, path, body).Code
+	}
+	assert.Equal(t, http.StatusBadRequest, req(http.MethodPut, "/auth/password", `{"old_password":"wrong","new_password":"Second-Pass2"}`))
+	assert.Equal(t, http.StatusOK, req(http.MethodPut, "/auth/password", `{"old_password":"Initial-Pass1","new_password":"Second-Pass2"}`))
+
+	enrollment, err := svc.BeginTOTPEnrollment(context.Background(), "agent", "Second-Pass2")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, req(http.MethodPost, "/auth/totp/activate", `{"code":"000000"}`))
+	code, _ := service.TOTPCode(enrollment.Secret, time.Now())
+	assert.Equal(t, http.StatusOK, req(http.MethodPost, "/auth/totp/activate", `{"code":"`+code+`"}`))
+	assert.Equal(t, http.StatusConflict, req(http.MethodPost, "/auth/totp/activate", `{"code":"`+code+`"}`))
+
+	reset, err := svc.IssuePasswordReset(context.Background(), 7, 1)
+	require.NoError(t, err)
+	w = doAgencyRequest(r, http.MethodPost, "/auth/password/reset", `{"token":"`+reset.Token+`","new_password":"Third-Pass3"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doAgencyRequest(r, http.MethodPost, "/auth/password/reset", `{"token":"`+reset.Token+`","new_password":"Fourth-Pass4"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "reset tokens are single-use")
+}
+
+// doStaffRequest 以指定员工身份发送 JSON 请求。
+func doStaffRequest(r *gin.Engine, staffID, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Staff-ID", staffID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -95,6 +96,11 @@ func (h *BookingHandler) Create(c *gin.Context) {
 
 	booking, err := h.svc.Create(c.Request.Context(), userID, req.VoyageID, req.CabinSKUID, req.Guests)
 	if err != nil {
+		if errors.Is(err, service.ErrBookingSoldOut) {
+			// 售罄时返回独立业务码，客户端据此引导用户登记候补。
+			response.Error(c, http.StatusConflict, errcode.ErrCabinSoldOut, err.Error())
+			return
+		}
 		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
 		return
 	}
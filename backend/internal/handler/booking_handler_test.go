@@ -10,6 +10,8 @@ import (
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -83,6 +85,28 @@ func TestCreateBookingNotAuthenticated(t *testing.T) {
 	}
 }
 
+// TestCreateBookingSoldOut 测试售罄时返回候补引导业务码
+func TestCreateBookingSoldOut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyUserID, "1")
+		c.Next()
+	})
+	h := NewBookingHandler(&bookingTestSvc{err: service.ErrBookingSoldOut})
+	r.POST("/api/bookings", h.Create)
+
+	w := httptest.NewRecorder()
+	body := []byte(`{"voyage_id":2,"cabin_sku_id":3,"guests":2}`)
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/api/bookings", bytes.NewReader(body)))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", w.Code)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(fmt.Sprintf(`"code":%d`, errcode.ErrCabinSoldOut))) {
+		t.Fatalf("expected sold-out business code, got %s", w.Body.String())
+	}
+}
+
 // TestCreateBookingServiceError 测试预订服务错误
 func TestCreateBookingServiceError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
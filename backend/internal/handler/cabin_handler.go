@@ -2,15 +2,16 @@ package handler
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
 	"github.com/cruisebooking/backend/internal/pkg/response"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // CabinService 定义舱房处理器所需的服务接口。
@@ -171,7 +172,7 @@ func (h *CabinHandler) BatchUpdateStatus(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "batch size exceeds limit")
 		return
 	}
-	log.Printf("audit bulk update cabins count=%d status=%d", len(req.IDs), req.Status)
+	logger.FromContext(c.Request.Context()).Info("audit bulk update cabins", zap.Int("count", len(req.IDs)), zap.Int("status", int(req.Status)))
 	if err := h.svc.BatchUpdateStatus(c.Request.Context(), req.IDs, req.Status); err != nil {
 		response.InternalError(c, err)
 		return
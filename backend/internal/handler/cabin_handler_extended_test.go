@@ -2,13 +2,15 @@ package handler
 
 import (
 	"bytes"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestCabinHandler_FilteredList(t *testing.T) {
@@ -62,10 +64,8 @@ func TestCabinHandler_BatchStatusWritesAuditLog(t *testing.T) {
 	h := NewCabinHandler(&mockCabinSvc{})
 	r.PUT("/api/v1/admin/cabins/batch-status", h.BatchUpdateStatus)
 
-	var buf bytes.Buffer
-	origin := log.Writer()
-	log.SetOutput(&buf)
-	defer log.SetOutput(origin)
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
 
 	body := bytes.NewBufferString(`{"ids":[1,2],"status":0}`)
 	w := httptest.NewRecorder()
@@ -75,8 +75,8 @@ func TestCabinHandler_BatchStatusWritesAuditLog(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d", w.Code)
 	}
-	if !strings.Contains(buf.String(), "audit bulk update cabins") {
-		t.Fatalf("expected cabin audit log, got: %s", buf.String())
+	if logs.FilterMessage("audit bulk update cabins").Len() != 1 {
+		t.Fatalf("expected cabin audit log, got: %v", logs.All())
 	}
 }
 
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/pkg/xlsx"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CabinImportService 定义舱房批量导入导出处理器依赖的业务能力。
+type CabinImportService interface {
+	Import(ctx context.Context, voyageID int64, format string, reader io.Reader, dryRun bool) (*service.CabinImportSummary, error)
+	Export(ctx context.Context, voyageID int64, format string) ([]byte, error)
+}
+
+// CabinImportHandler 提供按航次批量导入导出舱房 SKU 与库存的端点。
+type CabinImportHandler struct {
+	svc        CabinImportService
+	indexer    CabinIndexer
+	retryQueue CabinIndexRetryQueue
+}
+
+// NewCabinImportHandler 创建舱房批量导入导出处理器；indexer 非空时导入成功后同步搜索索引。
+func NewCabinImportHandler(svc CabinImportService, indexer CabinIndexer, retryQueue CabinIndexRetryQueue) *CabinImportHandler {
+	return &CabinImportHandler{svc: svc, indexer: indexer, retryQueue: retryQueue}
+}
+
+// Import 处理 POST /api/v1/admin/voyages/:id/cabins/import（multipart 字段 file），
+// 按舱房编号新增或覆盖舱房；dry_run=true 时只校验并返回逐行错误。格式取 format 参数，缺省按文件扩展名判断。
+func (h *CabinImportHandler) Import(c *gin.Context) {
+	voyageID, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "dry_run must be true or false")
+		return
+	}
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "file is required")
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	}
+	opened, err := file.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "failed to read file")
+		return
+	}
+	defer opened.Close()
+	summary, err := h.svc.Import(c.Request.Context(), voyageID, format, opened, dryRun)
+	if err != nil {
+		respondCabinImportError(c, err)
+		return
+	}
+	if h.indexer != nil {
+		for _, sku := range summary.SKUs {
+			if err := h.indexer.IndexCabin(sku); err != nil && h.retryQueue != nil {
+				h.retryQueue.Enqueue(sku)
+			}
+		}
+	}
+	response.Success(c, summary)
+}
+
+// Export 处理 GET /api/v1/admin/voyages/:id/cabins/export，format=csv|xlsx，导出的文件可直接修改后重新导入。
+func (h *CabinImportHandler) Export(c *gin.Context) {
+	voyageID, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", service.CabinFileCSV)))
+	data, err := h.svc.Export(c.Request.Context(), voyageID, format)
+	if err != nil {
+		respondCabinImportError(c, err)
+		return
+	}
+	contentType := "text/csv; charset=utf-8"
+	if format == service.CabinFileXLSX {
+		contentType = xlsx.ContentType
+	}
+	filename := fmt.Sprintf("voyage_%d_cabins_%s.%s", voyageID, time.Now().Format("20060102_150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func respondCabinImportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCabinImport):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrCabinImportVoyageNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
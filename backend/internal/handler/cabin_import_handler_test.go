@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/xlsx"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCabinImportSvc struct {
+	err      error
+	voyageID int64
+	format   string
+	dryRun   bool
+	imported string
+}
+
+func (f *fakeCabinImportSvc) Import(_ context.Context, voyageID int64, format string, reader io.Reader, dryRun bool) (*service.CabinImportSummary, error) {
+	data, _ := io.ReadAll(reader)
+	f.voyageID, f.format, f.dryRun, f.imported = voyageID, format, dryRun, string(data)
+	if f.err != nil {
+		return nil, f.err
+	}
+	summary := &service.CabinImportSummary{DryRun: dryRun, Total: 1, Created: 1, Errors: []service.CabinImportRowError{}}
+	if !dryRun {
+		summary.Applied = true
+		summary.SKUs = []domain.CabinSKU{{ID: 11, VoyageID: voyageID, Code: "A-8001"}}
+	}
+	return summary, nil
+}
+
+func (f *fakeCabinImportSvc) Export(_ context.Context, voyageID int64, format string) ([]byte, error) {
+	f.voyageID, f.format = voyageID, format
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []byte("code,cabin_type_id\nA-8001,31\n"), nil
+}
+
+type recordingCabinIndexer struct{ docs []any }
+
+func (r *recordingCabinIndexer) IndexCabin(doc any) error {
+	r.docs = append(r.docs, doc)
+	return nil
+}
+
+func newCabinImportTestRouter(svc *fakeCabinImportSvc, indexer CabinIndexer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewCabinImportHandler(svc, indexer, nil)
+	r := gin.New()
+	r.POST("/admin/voyages/:id/cabins/import", h.Import)
+	r.GET("/admin/voyages/:id/cabins/export", h.Export)
+	return r
+}
+
+func uploadCabinFile(t *testing.T, r *gin.Engine, path, filename, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, _ = part.Write([]byte(content))
+	require.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCabinImportHandler_Import(t *testing.T) {
+	svc := &fakeCabinImportSvc{}
+	indexer := &recordingCabinIndexer{}
+	r := newCabinImportTestRouter(svc, indexer)
+
+	w := uploadCabinFile(t, r, "/admin/voyages/5/cabins/import?dry_run=true", "deck8.XLSX", "binary")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(5), svc.voyageID)
+	assert.Equal(t, "xlsx", svc.format, "格式缺省按扩展名判断")
+	assert.True(t, svc.dryRun)
+	assert.Contains(t, w.Body.String(), `"dry_run":true`)
+	assert.Empty(t, indexer.docs)
+
+	w = uploadCabinFile(t, r, "/admin/voyages/5/cabins/import?format=csv", "cabins.txt", "code,cabin_type_id\nA-8001,31\n")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "csv", svc.format)
+	assert.False(t, svc.dryRun)
+	assert.Contains(t, svc.imported, "A-8001")
+	assert.Contains(t, w.Body.String(), `"applied":true`)
+	assert.Len(t, indexer.docs, 1, "导入成功后同步搜索索引")
+
+	w = uploadCabinFile(t, r, "/admin/voyages/5/cabins/import?dry_run=maybe", "cabins.csv", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/cabins/import", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrInvalidCabinImport
+	w = uploadCabinFile(t, r, "/admin/voyages/5/cabins/import", "cabins.csv", "code\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.err = service.ErrCabinImportVoyageNotFound
+	w = uploadCabinFile(t, r, "/admin/voyages/404/cabins/import", "cabins.csv", "code\n")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	svc.err = errors.New("db down")
+	w = uploadCabinFile(t, r, "/admin/voyages/5/cabins/import", "cabins.csv", "code\n")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestCabinImportHandler_Export(t *testing.T) {
+	svc := &fakeCabinImportSvc{}
+	r := newCabinImportTestRouter(svc, nil)
+
+	w := doAgencyRequest(r, http.MethodGet, "/admin/voyages/5/cabins/export", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "csv", svc.format)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "voyage_5_cabins_")
+	assert.Contains(t, w.Body.String(), "A-8001")
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/voyages/5/cabins/export?format=XLSX", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, xlsx.ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".xlsx")
+
+	svc.err = service.ErrInvalidCabinImport
+	w = doAgencyRequest(r, http.MethodGet, "/admin/voyages/5/cabins/export?format=pdf", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
@@ -159,6 +160,59 @@ func (h *CabinPricingHandler) History(c *gin.Context) {
 	response.Success(c, gin.H{"list": items, "total": total})
 }
 
+// PendingVersions 查询尚未生效的未来价格版本，可按航次/舱型过滤。
+func (h *CabinPricingHandler) PendingVersions(c *gin.Context) {
+	page := queryInt(c, "page", 1)
+	pageSize := queryInt(c, "page_size", 20)
+	items, total, err := h.priceSvc.ListPendingVersions(c.Request.Context(), queryInt64(c, "voyage_id", 0), queryInt64(c, "cabin_type_id", 0), page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, err.Error())
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// CancelVersion 撤销一个尚未生效的未来价格版本。
+func (h *CabinPricingHandler) CancelVersion(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	version, err := h.priceSvc.CancelPendingVersion(c.Request.Context(), id, parseOperatorID(c))
+	if err != nil {
+		respondPriceVersionError(c, err)
+		return
+	}
+	response.Success(c, version)
+}
+
+// Rollback 以指定历史版本为模板创建新版本并立即生效。
+func (h *CabinPricingHandler) Rollback(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	version, err := h.priceSvc.RollbackToVersion(c.Request.Context(), id, parseOperatorID(c))
+	if err != nil {
+		respondPriceVersionError(c, err)
+		return
+	}
+	response.Success(c, version)
+}
+
+func respondPriceVersionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPriceVersionNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrPriceVersionNotPending), errors.Is(err, service.ErrPriceVersionNotRollbackable):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, err.Error())
+	}
+}
+
 func parseDateOnly(raw string) (time.Time, bool) {
 	if raw == "" {
 		return time.Time{}, false
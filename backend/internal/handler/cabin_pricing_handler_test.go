@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// fakePriceVersionRepo 仅实现撤销/回滚/待生效列表所需的最小行为。
+type fakePriceVersionRepo struct {
+	versions map[int64]*domain.VoyageCabinTypePriceVersion
+	created  []domain.VoyageCabinTypePriceVersion
+}
+
+func (f *fakePriceVersionRepo) CreateVersion(_ context.Context, v *domain.VoyageCabinTypePriceVersion) error {
+	v.ID = int64(100 + len(f.created))
+	f.created = append(f.created, *v)
+	return nil
+}
+func (f *fakePriceVersionRepo) UpsertCurrent(context.Context, *domain.VoyageCabinTypeCurrent) error {
+	return nil
+}
+func (f *fakePriceVersionRepo) GetCurrent(context.Context, int64, int64) (*domain.VoyageCabinTypeCurrent, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (f *fakePriceVersionRepo) GetCurrentAt(context.Context, int64, int64, time.Time) (*domain.VoyageCabinTypeCurrent, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (f *fakePriceVersionRepo) ListCurrentByVoyages(context.Context, []int64) ([]domain.VoyageCabinTypeCurrent, error) {
+	return nil, nil
+}
+func (f *fakePriceVersionRepo) ListCurrentByVoyagesAt(context.Context, []int64, time.Time) ([]domain.VoyageCabinTypeCurrent, error) {
+	return nil, nil
+}
+func (f *fakePriceVersionRepo) GetLatestVersionAt(context.Context, int64, int64, time.Time) (*domain.VoyageCabinTypePriceVersion, error) {
+	return nil, nil
+}
+func (f *fakePriceVersionRepo) ListVersions(context.Context, int64, int64, int, int) ([]domain.VoyageCabinTypePriceVersion, int64, error) {
+	return nil, 0, nil
+}
+func (f *fakePriceVersionRepo) GetVersion(_ context.Context, id int64) (*domain.VoyageCabinTypePriceVersion, error) {
+	if v, ok := f.versions[id]; ok {
+		return v, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (f *fakePriceVersionRepo) ListPendingVersions(_ context.Context, voyageID, _ int64, _ time.Time, _, _ int) ([]domain.VoyageCabinTypePriceVersion, int64, error) {
+	return []domain.VoyageCabinTypePriceVersion{{ID: 9, VoyageID: voyageID}}, 1, nil
+}
+func (f *fakePriceVersionRepo) CancelVersion(_ context.Context, id, _ int64, at time.Time) error {
+	v, ok := f.versions[id]
+	if !ok || v.CancelledAt != nil || !v.EffectiveAt.After(at) {
+		return domain.ErrPriceVersionNotPending
+	}
+	v.CancelledAt = &at
+	return nil
+}
+func (f *fakePriceVersionRepo) ListStaleCurrentKeys(context.Context, time.Time) ([]domain.VoyageCabinTypeKey, error) {
+	return nil, nil
+}
+
+func newCabinPricingVersionRouter() (*gin.Engine, *fakePriceVersionRepo) {
+	gin.SetMode(gin.TestMode)
+	repo := &fakePriceVersionRepo{versions: map[int64]*domain.VoyageCabinTypePriceVersion{
+		1: {ID: 1, VoyageID: 3, CabinTypeID: 4, SalePriceCents: 5000, EffectiveAt: time.Now().Add(-24 * time.Hour)},
+		2: {ID: 2, VoyageID: 3, CabinTypeID: 4, SalePriceCents: 6000, EffectiveAt: time.Now().Add(24 * time.Hour)},
+	}}
+	h := NewCabinPricingHandler(service.NewVoyageCabinTypePriceService(repo), nil, nil)
+	r := gin.New()
+	r.GET("/pending-versions", h.PendingVersions)
+	r.POST("/versions/:id/cancel", h.CancelVersion)
+	r.POST("/versions/:id/rollback", h.Rollback)
+	return r, repo
+}
+
+func TestCabinPricingHandler_PendingVersions(t *testing.T) {
+	r, _ := newCabinPricingVersionRouter()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pending-versions?voyage_id=3", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"voyage_id":3`)
+}
+
+func TestCabinPricingHandler_CancelVersion(t *testing.T) {
+	r, _ := newCabinPricingVersionRouter()
+	cases := []struct {
+		path string
+		code int
+	}{
+		{"/versions/2/cancel", http.StatusOK},
+		{"/versions/2/cancel", http.StatusConflict},
+		{"/versions/1/cancel", http.StatusConflict},
+		{"/versions/404/cancel", http.StatusNotFound},
+		{"/versions/abc/cancel", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		assert.Equal(t, tc.code, w.Code, tc.path)
+	}
+}
+
+func TestCabinPricingHandler_Rollback(t *testing.T) {
+	r, repo := newCabinPricingVersionRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/versions/1/rollback", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.Len(t, repo.created, 1) {
+		assert.Equal(t, int64(5000), repo.created[0].SalePriceCents)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/versions/2/rollback", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
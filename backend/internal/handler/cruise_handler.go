@@ -2,15 +2,16 @@ package handler
 
 import (
 	"errors"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
 	"github.com/cruisebooking/backend/internal/pkg/response"
 	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // CruiseHandler 处理邮轮的 CRUD 端点。
@@ -273,7 +274,7 @@ func (h *CruiseHandler) BatchUpdateStatus(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "batch size exceeds limit")
 		return
 	}
-	log.Printf("audit bulk update cruises count=%d status=%d", len(req.IDs), req.Status)
+	logger.FromContext(c.Request.Context()).Info("audit bulk update cruises", zap.Int("count", len(req.IDs)), zap.Int("status", int(req.Status)))
 	if err := h.svc.BatchUpdateStatus(c.Request.Context(), req.IDs, req.Status); err != nil {
 		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, err.Error())
 		return
@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -14,6 +13,9 @@ import (
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 type imageRepoForHandler struct {
@@ -113,10 +115,8 @@ func TestCruiseHandler_BatchUpdateStatusWritesAuditLog(t *testing.T) {
 	h := NewCruiseHandler(service.NewCruiseService(&mockCruiseRepo{}, &mockCabinTypeRepo{}, &mockCompanyRepo{}))
 	r.PUT("/api/v1/admin/cruises/batch-status", h.BatchUpdateStatus)
 
-	var buf bytes.Buffer
-	origin := log.Writer()
-	log.SetOutput(&buf)
-	defer log.SetOutput(origin)
+	core, logs := observer.New(zapcore.InfoLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
 
 	body := bytes.NewBufferString(`{"ids":[1],"status":0}`)
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/cruises/batch-status", body)
@@ -127,8 +127,8 @@ func TestCruiseHandler_BatchUpdateStatusWritesAuditLog(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected 200, got %d, body=%s", w.Code, w.Body.String())
 	}
-	if !strings.Contains(buf.String(), "audit bulk update cruises") {
-		t.Fatalf("expected cruise audit log, got: %s", buf.String())
+	if logs.FilterMessage("audit bulk update cruises").Len() != 1 {
+		t.Fatalf("expected cruise audit log, got: %v", logs.All())
 	}
 }
 
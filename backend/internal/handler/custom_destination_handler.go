@@ -2,11 +2,13 @@ package handler
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/csvimport"
 	"github.com/cruisebooking/backend/internal/pkg/errcode"
 	"github.com/cruisebooking/backend/internal/pkg/response"
 	"github.com/cruisebooking/backend/internal/service"
@@ -15,7 +17,8 @@ import (
 
 // CustomDestinationHandler 处理自定义目的地的 CRUD 端点。
 type CustomDestinationHandler struct {
-	svc *service.CustomDestinationService
+	svc  *service.CustomDestinationService
+	jobs ImportJobSubmitter
 }
 
 // NewCustomDestinationHandler 创建自定义目的地处理器。
@@ -23,6 +26,11 @@ func NewCustomDestinationHandler(svc *service.CustomDestinationService) *CustomD
 	return &CustomDestinationHandler{svc: svc}
 }
 
+// SetImportJobs 启用异步导入（async=true）。
+func (h *CustomDestinationHandler) SetImportJobs(jobs ImportJobSubmitter) {
+	h.jobs = jobs
+}
+
 // CustomDestinationRequest 创建/更新自定义目的地的请求体。
 type CustomDestinationRequest struct {
 	Name        string   `json:"name" binding:"required"`    // 目的地名称（必填）
@@ -151,8 +159,30 @@ func (h *CustomDestinationHandler) ExportCSV(c *gin.Context) {
 	c.Data(http.StatusOK, "text/csv", data)
 }
 
-// ImportCSV 导入港口城市词典 CSV。
+// ImportCSV 导入港口城市词典 CSV（multipart 字段 file），按表头映射列并逐行校验。
+// 查询参数：dry_run=true 只校验不写入；mode=all_or_nothing|best_effort；
+// async=true 时创建后台导入任务并立即返回任务，结果通过 /import-jobs 查询；
+// report=csv 时若存在失败行则直接下载错误报告。
 func (h *CustomDestinationHandler) ImportCSV(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "dry_run must be true or false")
+		return
+	}
+	async, err := strconv.ParseBool(c.DefaultQuery("async", "false"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "async must be true or false")
+		return
+	}
+	if async && h.jobs == nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "async import is not enabled")
+		return
+	}
+	opts, err := csvimport.Options{DryRun: dryRun, Mode: c.Query("mode")}.Normalize()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
 	file, err := c.FormFile("file")
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "csv file is required")
@@ -164,9 +194,32 @@ func (h *CustomDestinationHandler) ImportCSV(c *gin.Context) {
 		return
 	}
 	defer opened.Close()
-	summary, err := h.svc.ImportCSV(c.Request.Context(), opened)
+	if async {
+		data, err := io.ReadAll(opened)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "failed to read csv file")
+			return
+		}
+		job, err := h.jobs.Submit(c.Request.Context(), service.ImportKindCustomDestinations, file.Filename, data, opts, parseOperatorID(c))
+		if err != nil {
+			respondImportJobError(c, err)
+			return
+		}
+		response.Success(c, job)
+		return
+	}
+	summary, err := h.svc.ImportCSV(c.Request.Context(), opened, opts)
 	if err != nil {
-		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		respondImportJobError(c, err)
+		return
+	}
+	if c.Query("report") == "csv" && summary.Failed > 0 {
+		report, err := summary.ErrorReport()
+		if err != nil {
+			response.InternalError(c, err)
+			return
+		}
+		writeImportErrorReport(c, fmt.Sprintf("port_city_dictionary_errors_%s.csv", time.Now().Format("20060102_150405")), report)
 		return
 	}
 	response.Success(c, summary)
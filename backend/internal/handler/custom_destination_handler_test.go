@@ -42,6 +42,10 @@ func (s *customDestinationHandlerRepoStub) UpsertByNameCountry(_ context.Context
 	s.upserted = append(s.upserted, *dest)
 	return nil
 }
+func (s *customDestinationHandlerRepoStub) UpsertBatch(_ context.Context, dests []domain.CustomDestination) error {
+	s.upserted = append(s.upserted, dests...)
+	return nil
+}
 
 func TestCustomDestinationHandlerExportCSV(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -99,4 +103,49 @@ func TestCustomDestinationHandlerImportCSV(t *testing.T) {
 	}
 }
 
+func TestCustomDestinationHandlerImportCSVOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &customDestinationHandlerRepoStub{}
+	h := NewCustomDestinationHandler(service.NewCustomDestinationService(repo))
+	r := gin.New()
+	r.POST("/custom-destinations/import", h.ImportCSV)
+	file := "name,country,latitude,longitude\n温哥华,加拿大,49.2827,-123.1207\n=cmd,加拿大,95,-123.1207\n"
+
+	w := uploadCabinFile(t, r, "/custom-destinations/import?dry_run=true", "ports.csv", file)
+	if w.Code != http.StatusOK || len(repo.upserted) != 0 || !strings.Contains(w.Body.String(), `"failed":1`) || !strings.Contains(w.Body.String(), `"column":"latitude"`) {
+		t.Fatalf("dry run should report row errors without writes: %d %s", w.Code, w.Body.String())
+	}
+
+	w = uploadCabinFile(t, r, "/custom-destinations/import?mode=best_effort&report=csv", "ports.csv", file)
+	if w.Code != http.StatusOK || len(repo.upserted) != 1 || !strings.Contains(w.Header().Get("Content-Disposition"), "errors") {
+		t.Fatalf("best_effort should import valid rows and return the error report: %d %v", w.Code, repo.upserted)
+	}
+	if !strings.Contains(w.Body.String(), "3,latitude: must be between -90 and 90,'=cmd") {
+		t.Fatalf("unexpected error report: %s", w.Body.String())
+	}
+
+	w = uploadCabinFile(t, r, "/custom-destinations/import?async=true", "ports.csv", file)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("async without job service should be rejected, got %d", w.Code)
+	}
+	jobs := &fakeImportJobSvc{}
+	h.SetImportJobs(jobs)
+	w = uploadCabinFile(t, r, "/custom-destinations/import?async=true&mode=best_effort", "ports.csv", file)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"pending"`) {
+		t.Fatalf("async import should return the pending job: %d %s", w.Code, w.Body.String())
+	}
+	if len(jobs.submitted) != 3 || jobs.submitted[0] != service.ImportKindCustomDestinations || jobs.submitted[1] != "ports.csv" || jobs.submitted[2] != file || jobs.opts.Mode != "best_effort" {
+		t.Fatalf("unexpected submitted job: %v %+v", jobs.submitted, jobs.opts)
+	}
+
+	for _, path := range []string{"?mode=partial", "?dry_run=maybe", "?async=maybe"} {
+		if w := uploadCabinFile(t, r, "/custom-destinations/import"+path, "ports.csv", file); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+	if w := uploadCabinFile(t, r, "/custom-destinations/import", "ports.csv", "name,country\n温哥华,加拿大\n"); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing required columns should be rejected, got %d", w.Code)
+	}
+}
+
 func float64PtrForHandler(v float64) *float64 { return &v }
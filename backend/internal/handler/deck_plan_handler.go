@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DeckPlanService 定义甲板平面图与舱位图处理器依赖的业务能力。
+type DeckPlanService interface {
+	ListByCruise(ctx context.Context, cruiseID int64) ([]domain.DeckPlan, error)
+	Get(ctx context.Context, id int64) (*domain.DeckPlan, error)
+	Create(ctx context.Context, plan *domain.DeckPlan) error
+	Update(ctx context.Context, plan *domain.DeckPlan) error
+	Delete(ctx context.Context, id int64) error
+	ReplaceCabins(ctx context.Context, planID int64, cabins []domain.DeckPlanCabin) (*domain.DeckPlan, error)
+	VoyageDeckMap(ctx context.Context, voyageID int64, guests int, deck string) (*service.VoyageDeckMap, error)
+}
+
+// DeckPlanHandler 提供后台甲板平面图维护端点，以及 C 端按甲板选择舱房的舱位图端点。
+type DeckPlanHandler struct {
+	svc DeckPlanService
+}
+
+// NewDeckPlanHandler 创建甲板平面图处理器。
+func NewDeckPlanHandler(svc DeckPlanService) *DeckPlanHandler {
+	return &DeckPlanHandler{svc: svc}
+}
+
+// DeckPlanRequest 创建/更新甲板平面图的请求体。
+type DeckPlanRequest struct {
+	CruiseID  int64  `json:"cruise_id"`               // 所属邮轮 ID（创建时必填，更新时忽略）
+	Deck      string `json:"deck" binding:"required"` // 甲板层（必填），与舱房的 deck 一致
+	Name      string `json:"name"`                    // 展示名称
+	ImageURL  string `json:"image_url"`               // 平面图图片 URL
+	Width     int    `json:"width"`                   // 图片宽度（像素）
+	Height    int    `json:"height"`                  // 图片高度（像素）
+	SortOrder int    `json:"sort_order"`              // 排序，越小越靠前
+}
+
+// DeckPlanCabinsRequest 整体替换舱位坐标的请求体。
+type DeckPlanCabinsRequest struct {
+	Cabins []DeckPlanCabinRequest `json:"cabins"` // 舱位坐标，空数组表示清空
+}
+
+// DeckPlanCabinRequest 单个舱位在平面图上的矩形区域。
+type DeckPlanCabinRequest struct {
+	CabinNumber string `json:"cabin_number"` // 船上舱房号，如 8001
+	X           int    `json:"x"`            // 左上角横坐标
+	Y           int    `json:"y"`            // 左上角纵坐标
+	Width       int    `json:"width"`        // 宽度
+	Height      int    `json:"height"`       // 高度
+}
+
+func (r DeckPlanRequest) toPlan() *domain.DeckPlan {
+	return &domain.DeckPlan{
+		CruiseID:  r.CruiseID,
+		Deck:      r.Deck,
+		Name:      r.Name,
+		ImageURL:  r.ImageURL,
+		Width:     r.Width,
+		Height:    r.Height,
+		SortOrder: r.SortOrder,
+	}
+}
+
+// List 处理 GET /api/v1/admin/deck-plans?cruise_id=，返回邮轮的全部平面图及舱位坐标。
+func (h *DeckPlanHandler) List(c *gin.Context) {
+	plans, err := h.svc.ListByCruise(c.Request.Context(), queryInt64(c, "cruise_id", 0))
+	if err != nil {
+		respondDeckPlanError(c, err)
+		return
+	}
+	response.Success(c, plans)
+}
+
+// Get 处理 GET /api/v1/admin/deck-plans/:id。
+func (h *DeckPlanHandler) Get(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	plan, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		respondDeckPlanError(c, err)
+		return
+	}
+	response.Success(c, plan)
+}
+
+// Create 处理 POST /api/v1/admin/deck-plans。
+func (h *DeckPlanHandler) Create(c *gin.Context) {
+	var req DeckPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	plan := req.toPlan()
+	if err := h.svc.Create(c.Request.Context(), plan); err != nil {
+		respondDeckPlanError(c, err)
+		return
+	}
+	response.Success(c, plan)
+}
+
+// Update 处理 PUT /api/v1/admin/deck-plans/:id，更新图片与尺寸等基础信息。
+func (h *DeckPlanHandler) Update(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req DeckPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	plan := req.toPlan()
+	plan.ID = id
+	if err := h.svc.Update(c.Request.Context(), plan); err != nil {
+		respondDeckPlanError(c, err)
+		return
+	}
+	response.Success(c, plan)
+}
+
+// Delete 处理 DELETE /api/v1/admin/deck-plans/:id。
+func (h *DeckPlanHandler) Delete(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
+		respondDeckPlanError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ReplaceCabins 处理 PUT /api/v1/admin/deck-plans/:id/cabins，整体替换平面图上的舱位坐标。
+func (h *DeckPlanHandler) ReplaceCabins(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req DeckPlanCabinsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	cabins := make([]domain.DeckPlanCabin, len(req.Cabins))
+	for i, cabin := range req.Cabins {
+		cabins[i] = domain.DeckPlanCabin{CabinNumber: cabin.CabinNumber, X: cabin.X, Y: cabin.Y, Width: cabin.Width, Height: cabin.Height}
+	}
+	plan, err := h.svc.ReplaceCabins(c.Request.Context(), id, cabins)
+	if err != nil {
+		respondDeckPlanError(c, err)
+		return
+	}
+	response.Success(c, plan)
+}
+
+// DeckMap 处理 GET /api/v1/voyages/:id/deck-map?guests=&deck=，返回航次按甲板分层的舱位图，
+// 含每个舱房的可选状态、舱型、属性、出发日价格与平面图坐标。
+func (h *DeckPlanHandler) DeckMap(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	deckMap, err := h.svc.VoyageDeckMap(c.Request.Context(), id, queryInt(c, "guests", 0), c.Query("deck"))
+	if err != nil {
+		respondDeckPlanError(c, err)
+		return
+	}
+	response.Success(c, deckMap)
+}
+
+func respondDeckPlanError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDeckPlan):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrDeckPlanNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrDeckPlanConflict):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDeckPlanSvc struct {
+	err      error
+	received *domain.DeckPlan
+	cabins   []domain.DeckPlanCabin
+	guests   int
+	deck     string
+}
+
+func (f *fakeDeckPlanSvc) ListByCruise(_ context.Context, cruiseID int64) ([]domain.DeckPlan, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []domain.DeckPlan{{ID: 1, CruiseID: cruiseID, Deck: "8"}}, nil
+}
+
+func (f *fakeDeckPlanSvc) Get(_ context.Context, id int64) (*domain.DeckPlan, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DeckPlan{ID: id, Deck: "8"}, nil
+}
+
+func (f *fakeDeckPlanSvc) Create(_ context.Context, plan *domain.DeckPlan) error {
+	f.received = plan
+	plan.ID = 3
+	return f.err
+}
+
+func (f *fakeDeckPlanSvc) Update(_ context.Context, plan *domain.DeckPlan) error {
+	f.received = plan
+	return f.err
+}
+
+func (f *fakeDeckPlanSvc) Delete(context.Context, int64) error {
+	return f.err
+}
+
+func (f *fakeDeckPlanSvc) ReplaceCabins(_ context.Context, planID int64, cabins []domain.DeckPlanCabin) (*domain.DeckPlan, error) {
+	f.cabins = cabins
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DeckPlan{ID: planID, Cabins: cabins}, nil
+}
+
+func (f *fakeDeckPlanSvc) VoyageDeckMap(_ context.Context, voyageID int64, guests int, deck string) (*service.VoyageDeckMap, error) {
+	f.guests, f.deck = guests, deck
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.VoyageDeckMap{VoyageID: voyageID, Guests: guests, Decks: []service.DeckMapDeck{{Deck: "8", Cabins: []service.DeckMapCabin{{ID: 12, CabinNumber: "8001", Status: service.DeckCabinAvailable}}}}}, nil
+}
+
+func newDeckPlanTestRouter(svc *fakeDeckPlanSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewDeckPlanHandler(svc)
+	r := gin.New()
+	r.GET("/admin/deck-plans", h.List)
+	r.GET("/admin/deck-plans/:id", h.Get)
+	r.POST("/admin/deck-plans", h.Create)
+	r.PUT("/admin/deck-plans/:id", h.Update)
+	r.DELETE("/admin/deck-plans/:id", h.Delete)
+	r.PUT("/admin/deck-plans/:id/cabins", h.ReplaceCabins)
+	r.GET("/voyages/:id/deck-map", h.DeckMap)
+	return r
+}
+
+func TestDeckPlanHandler_AdminEndpoints(t *testing.T) {
+	svc := &fakeDeckPlanSvc{}
+	r := newDeckPlanTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/admin/deck-plans", `{"cruise_id":1,"deck":"8","image_url":"https://cdn/8.png","width":800,"height":300}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.received)
+	assert.Equal(t, 800, svc.received.Width)
+	assert.Contains(t, w.Body.String(), `"id":3`)
+
+	w = doAgencyRequest(r, http.MethodPut, "/admin/deck-plans/3", `{"deck":"9","sort_order":2}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 3, svc.received.ID)
+	assert.Equal(t, 2, svc.received.SortOrder)
+
+	w = doAgencyRequest(r, http.MethodPut, "/admin/deck-plans/3/cabins", `{"cabins":[{"cabin_number":"8001","x":10,"y":20,"width":30,"height":40}]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, svc.cabins, 1)
+	assert.Equal(t, domain.DeckPlanCabin{CabinNumber: "8001", X: 10, Y: 20, Width: 30, Height: 40}, svc.cabins[0])
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/deck-plans?cruise_id=1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"cruise_id":1`)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/deck-plans", `{"cruise_id":1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doAgencyRequest(r, http.MethodDelete, "/admin/deck-plans/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrDeckPlanConflict
+	w = doAgencyRequest(r, http.MethodPost, "/admin/deck-plans", `{"cruise_id":1,"deck":"8"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	svc.err = service.ErrInvalidDeckPlan
+	w = doAgencyRequest(r, http.MethodPut, "/admin/deck-plans/3/cabins", `{"cabins":[]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	svc.err = service.ErrDeckPlanNotFound
+	w = doAgencyRequest(r, http.MethodDelete, "/admin/deck-plans/3", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeckPlanHandler_DeckMap(t *testing.T) {
+	svc := &fakeDeckPlanSvc{}
+	r := newDeckPlanTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/voyages/5/deck-map?guests=3&deck=8", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 3, svc.guests)
+	assert.Equal(t, "8", svc.deck)
+	assert.Contains(t, w.Body.String(), `"cabin_number":"8001"`)
+	assert.Contains(t, w.Body.String(), `"status":"available"`)
+
+	w = doAgencyRequest(r, http.MethodGet, "/voyages/5/deck-map?guests=abc", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Zero(t, svc.guests, "无效人数交由服务层使用默认值")
+
+	svc.err = service.ErrDeckPlanNotFound
+	w = doAgencyRequest(r, http.MethodGet, "/voyages/404/deck-map", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
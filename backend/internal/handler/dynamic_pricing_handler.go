@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DynamicPricingService 定义动态调价规则、试算与提案审批能力。
+type DynamicPricingService interface {
+	ListRules(ctx context.Context) ([]domain.DynamicPricingRule, error)
+	CreateRule(ctx context.Context, rule *domain.DynamicPricingRule) error
+	UpdateRule(ctx context.Context, rule *domain.DynamicPricingRule) error
+	DeleteRule(ctx context.Context, id int64) error
+	Simulate(ctx context.Context, req service.DynamicPricingRequest) ([]domain.DynamicPricingProposalItem, error)
+	Propose(ctx context.Context, req service.DynamicPricingRequest, staffID int64) (*domain.DynamicPricingProposal, error)
+	ListProposals(ctx context.Context, status string, page, pageSize int) ([]domain.DynamicPricingProposal, int64, error)
+	GetProposal(ctx context.Context, id int64) (*domain.DynamicPricingProposal, error)
+	Approve(ctx context.Context, id, staffID int64, note string) (*domain.DynamicPricingProposal, error)
+	Reject(ctx context.Context, id, staffID int64, note string) (*domain.DynamicPricingProposal, error)
+}
+
+// DynamicPricingHandler 提供收益管理动态调价的管理端点。
+type DynamicPricingHandler struct {
+	svc DynamicPricingService
+}
+
+// NewDynamicPricingHandler 创建动态调价处理器。
+func NewDynamicPricingHandler(svc DynamicPricingService) *DynamicPricingHandler {
+	return &DynamicPricingHandler{svc: svc}
+}
+
+type dynamicPricingRulePayload struct {
+	Name                 string   `json:"name" binding:"required,max=100"`
+	VoyageID             int64    `json:"voyage_id"`
+	CabinTypeID          int64    `json:"cabin_type_id"`
+	MinLoadFactor        *float64 `json:"min_load_factor"`
+	MaxLoadFactor        *float64 `json:"max_load_factor"`
+	MinDaysToDeparture   *int     `json:"min_days_to_departure"`
+	MaxDaysToDeparture   *int     `json:"max_days_to_departure"`
+	VelocityWindowDays   int      `json:"velocity_window_days"`
+	MinVelocity          *float64 `json:"min_velocity"`
+	MaxVelocity          *float64 `json:"max_velocity"`
+	AdjustPercent        float64  `json:"adjust_percent"`
+	FloorMarkupPercent   float64  `json:"floor_markup_percent"`
+	CeilingMarkupPercent *float64 `json:"ceiling_markup_percent"`
+	Priority             int      `json:"priority"`
+	Status               *int16   `json:"status"`
+}
+
+type dynamicPricingRunPayload struct {
+	VoyageIDs   []int64 `json:"voyage_ids" binding:"required"`
+	CabinTypeID int64   `json:"cabin_type_id"`
+	EffectiveAt string  `json:"effective_at"`
+}
+
+type dynamicPricingReviewPayload struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+func (p dynamicPricingRulePayload) toRule(id int64) *domain.DynamicPricingRule {
+	status := int16(1)
+	if p.Status != nil {
+		status = *p.Status
+	}
+	return &domain.DynamicPricingRule{
+		ID:                   id,
+		Name:                 p.Name,
+		VoyageID:             p.VoyageID,
+		CabinTypeID:          p.CabinTypeID,
+		MinLoadFactor:        p.MinLoadFactor,
+		MaxLoadFactor:        p.MaxLoadFactor,
+		MinDaysToDeparture:   p.MinDaysToDeparture,
+		MaxDaysToDeparture:   p.MaxDaysToDeparture,
+		VelocityWindowDays:   p.VelocityWindowDays,
+		MinVelocity:          p.MinVelocity,
+		MaxVelocity:          p.MaxVelocity,
+		AdjustPercent:        p.AdjustPercent,
+		FloorMarkupPercent:   p.FloorMarkupPercent,
+		CeilingMarkupPercent: p.CeilingMarkupPercent,
+		Priority:             p.Priority,
+		Status:               status,
+	}
+}
+
+// ListRules 处理 GET /admin/dynamic-pricing/rules。
+func (h *DynamicPricingHandler) ListRules(c *gin.Context) {
+	rules, err := h.svc.ListRules(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": rules, "total": len(rules)})
+}
+
+// CreateRule 处理 POST /admin/dynamic-pricing/rules。
+func (h *DynamicPricingHandler) CreateRule(c *gin.Context) {
+	var req dynamicPricingRulePayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	rule := req.toRule(0)
+	if err := h.svc.CreateRule(c.Request.Context(), rule); err != nil {
+		respondDynamicPricingError(c, err)
+		return
+	}
+	response.Success(c, rule)
+}
+
+// UpdateRule 处理 PUT /admin/dynamic-pricing/rules/:id。
+func (h *DynamicPricingHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	var req dynamicPricingRulePayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	rule := req.toRule(id)
+	if err := h.svc.UpdateRule(c.Request.Context(), rule); err != nil {
+		respondDynamicPricingError(c, err)
+		return
+	}
+	response.Success(c, rule)
+}
+
+// DeleteRule 处理 DELETE /admin/dynamic-pricing/rules/:id。
+func (h *DynamicPricingHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	if err := h.svc.DeleteRule(c.Request.Context(), id); err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// Simulate 处理 POST /admin/dynamic-pricing/simulate，仅试算不落库。
+func (h *DynamicPricingHandler) Simulate(c *gin.Context) {
+	req, ok := bindDynamicPricingRun(c)
+	if !ok {
+		return
+	}
+	items, err := h.svc.Simulate(c.Request.Context(), req)
+	if err != nil {
+		respondDynamicPricingError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": len(items)})
+}
+
+// CreateProposal 处理 POST /admin/dynamic-pricing/proposals，生成待审批提案。
+func (h *DynamicPricingHandler) CreateProposal(c *gin.Context) {
+	req, ok := bindDynamicPricingRun(c)
+	if !ok {
+		return
+	}
+	proposal, err := h.svc.Propose(c.Request.Context(), req, parseOperatorID(c))
+	if err != nil {
+		respondDynamicPricingError(c, err)
+		return
+	}
+	response.Success(c, proposal)
+}
+
+// ListProposals 处理 GET /admin/dynamic-pricing/proposals。
+func (h *DynamicPricingHandler) ListProposals(c *gin.Context) {
+	page := queryInt(c, "page", 1)
+	pageSize := queryInt(c, "page_size", 20)
+	items, total, err := h.svc.ListProposals(c.Request.Context(), c.Query("status"), page, pageSize)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// GetProposal 处理 GET /admin/dynamic-pricing/proposals/:id。
+func (h *DynamicPricingHandler) GetProposal(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	proposal, err := h.svc.GetProposal(c.Request.Context(), id)
+	if err != nil {
+		respondDynamicPricingError(c, err)
+		return
+	}
+	response.Success(c, proposal)
+}
+
+// ApproveProposal 处理 POST /admin/dynamic-pricing/proposals/:id/approve，审批通过后生成价格版本。
+func (h *DynamicPricingHandler) ApproveProposal(c *gin.Context) {
+	h.reviewProposal(c, h.svc.Approve)
+}
+
+// RejectProposal 处理 POST /admin/dynamic-pricing/proposals/:id/reject。
+func (h *DynamicPricingHandler) RejectProposal(c *gin.Context) {
+	h.reviewProposal(c, h.svc.Reject)
+}
+
+func (h *DynamicPricingHandler) reviewProposal(c *gin.Context, review func(ctx context.Context, id, staffID int64, note string) (*domain.DynamicPricingProposal, error)) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	var req dynamicPricingReviewPayload
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+			return
+		}
+	}
+	proposal, err := review(c.Request.Context(), id, parseOperatorID(c), req.Note)
+	if err != nil {
+		respondDynamicPricingError(c, err)
+		return
+	}
+	response.Success(c, proposal)
+}
+
+func bindDynamicPricingRun(c *gin.Context) (service.DynamicPricingRequest, bool) {
+	var req dynamicPricingRunPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return service.DynamicPricingRequest{}, false
+	}
+	out := service.DynamicPricingRequest{VoyageIDs: req.VoyageIDs, CabinTypeID: req.CabinTypeID}
+	if req.EffectiveAt != "" {
+		parsed, err := parseEffectiveAtInShanghai(req.EffectiveAt)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid effective_at")
+			return service.DynamicPricingRequest{}, false
+		}
+		effectiveAt := parsed.In(time.UTC)
+		out.EffectiveAt = &effectiveAt
+	}
+	return out, true
+}
+
+func respondDynamicPricingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPricingRule), errors.Is(err, service.ErrNoPricingChanges):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrPricingProposalNotPending):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	case errors.Is(err, service.ErrDynamicPricingNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
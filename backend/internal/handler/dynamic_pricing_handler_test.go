@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDynamicPricingSvc struct {
+	err          error
+	lastRule     *domain.DynamicPricingRule
+	lastRequest  service.DynamicPricingRequest
+	lastStaffID  int64
+	lastNote     string
+	approvedByID int64
+}
+
+func (f *fakeDynamicPricingSvc) ListRules(_ context.Context) ([]domain.DynamicPricingRule, error) {
+	return []domain.DynamicPricingRule{{ID: 1, Name: "旺销提价"}}, f.err
+}
+
+func (f *fakeDynamicPricingSvc) CreateRule(_ context.Context, rule *domain.DynamicPricingRule) error {
+	f.lastRule = rule
+	rule.ID = 9
+	return f.err
+}
+
+func (f *fakeDynamicPricingSvc) UpdateRule(_ context.Context, rule *domain.DynamicPricingRule) error {
+	f.lastRule = rule
+	return f.err
+}
+
+func (f *fakeDynamicPricingSvc) DeleteRule(_ context.Context, _ int64) error { return f.err }
+
+func (f *fakeDynamicPricingSvc) Simulate(_ context.Context, req service.DynamicPricingRequest) ([]domain.DynamicPricingProposalItem, error) {
+	f.lastRequest = req
+	return []domain.DynamicPricingProposalItem{{VoyageID: 1, CabinTypeID: 10, ProposedSalePriceCents: 1100}}, f.err
+}
+
+func (f *fakeDynamicPricingSvc) Propose(_ context.Context, req service.DynamicPricingRequest, staffID int64) (*domain.DynamicPricingProposal, error) {
+	f.lastRequest = req
+	f.lastStaffID = staffID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DynamicPricingProposal{ID: 3, Status: domain.PricingProposalStatusPending, CreatedBy: staffID}, nil
+}
+
+func (f *fakeDynamicPricingSvc) ListProposals(_ context.Context, _ string, _, _ int) ([]domain.DynamicPricingProposal, int64, error) {
+	return nil, 0, f.err
+}
+
+func (f *fakeDynamicPricingSvc) GetProposal(_ context.Context, id int64) (*domain.DynamicPricingProposal, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DynamicPricingProposal{ID: id}, nil
+}
+
+func (f *fakeDynamicPricingSvc) Approve(_ context.Context, id, staffID int64, note string) (*domain.DynamicPricingProposal, error) {
+	f.approvedByID = staffID
+	f.lastNote = note
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DynamicPricingProposal{ID: id, Status: domain.PricingProposalStatusApproved}, nil
+}
+
+func (f *fakeDynamicPricingSvc) Reject(_ context.Context, id, staffID int64, note string) (*domain.DynamicPricingProposal, error) {
+	f.lastNote = note
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DynamicPricingProposal{ID: id, Status: domain.PricingProposalStatusRejected}, nil
+}
+
+func newDynamicPricingTestRouter(svc *fakeDynamicPricingSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewDynamicPricingHandler(svc)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyStaffID, int64(7))
+		c.Next()
+	})
+	r.GET("/rules", h.ListRules)
+	r.POST("/rules", h.CreateRule)
+	r.PUT("/rules/:id", h.UpdateRule)
+	r.DELETE("/rules/:id", h.DeleteRule)
+	r.POST("/simulate", h.Simulate)
+	r.GET("/proposals", h.ListProposals)
+	r.POST("/proposals", h.CreateProposal)
+	r.GET("/proposals/:id", h.GetProposal)
+	r.POST("/proposals/:id/approve", h.ApproveProposal)
+	r.POST("/proposals/:id/reject", h.RejectProposal)
+	return r
+}
+
+func doDynamicPricingRequest(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestDynamicPricingHandler_CreateRule(t *testing.T) {
+	svc := &fakeDynamicPricingSvc{}
+	r := newDynamicPricingTestRouter(svc)
+
+	w := doDynamicPricingRequest(r, http.MethodPost, "/rules", `{"name":"旺销提价","min_load_factor":0.8,"adjust_percent":10,"floor_markup_percent":5}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.lastRule)
+	require.NotNil(t, svc.lastRule.MinLoadFactor)
+	assert.Equal(t, 0.8, *svc.lastRule.MinLoadFactor)
+	assert.Equal(t, int16(1), svc.lastRule.Status, "未传 status 默认启用")
+
+	w = doDynamicPricingRequest(r, http.MethodPost, "/rules", `{"adjust_percent":10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = fmt.Errorf("%w: bad", service.ErrInvalidPricingRule)
+	w = doDynamicPricingRequest(r, http.MethodPost, "/rules", `{"name":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDynamicPricingHandler_UpdateRuleErrors(t *testing.T) {
+	svc := &fakeDynamicPricingSvc{}
+	r := newDynamicPricingTestRouter(svc)
+
+	w := doDynamicPricingRequest(r, http.MethodPut, "/rules/abc", `{"name":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrDynamicPricingNotFound
+	w = doDynamicPricingRequest(r, http.MethodPut, "/rules/4", `{"name":"x"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDynamicPricingHandler_SimulateAndPropose(t *testing.T) {
+	svc := &fakeDynamicPricingSvc{}
+	r := newDynamicPricingTestRouter(svc)
+
+	w := doDynamicPricingRequest(r, http.MethodPost, "/simulate", `{"voyage_ids":[1,2],"cabin_type_id":10}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{1, 2}, svc.lastRequest.VoyageIDs)
+	assert.Contains(t, w.Body.String(), `"proposed_sale_price_cents":1100`)
+
+	w = doDynamicPricingRequest(r, http.MethodPost, "/proposals", `{"voyage_ids":[1],"effective_at":"2026-05-03 00:00:00"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(7), svc.lastStaffID)
+	require.NotNil(t, svc.lastRequest.EffectiveAt)
+	assert.Equal(t, "2026-05-02T16:00:00Z", svc.lastRequest.EffectiveAt.Format("2006-01-02T15:04:05Z07:00"))
+
+	w = doDynamicPricingRequest(r, http.MethodPost, "/proposals", `{"voyage_ids":[1],"effective_at":"tomorrow"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrNoPricingChanges
+	w = doDynamicPricingRequest(r, http.MethodPost, "/proposals", `{"voyage_ids":[1]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDynamicPricingHandler_ReviewProposal(t *testing.T) {
+	svc := &fakeDynamicPricingSvc{}
+	r := newDynamicPricingTestRouter(svc)
+
+	w := doDynamicPricingRequest(r, http.MethodPost, "/proposals/3/approve", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(7), svc.approvedByID)
+
+	w = doDynamicPricingRequest(r, http.MethodPost, "/proposals/3/reject", `{"note":"价格过高"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "价格过高", svc.lastNote)
+
+	svc.err = service.ErrPricingProposalNotPending
+	w = doDynamicPricingRequest(r, http.MethodPost, "/proposals/3/approve", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	svc.err = service.ErrDynamicPricingNotFound
+	w = doDynamicPricingRequest(r, http.MethodGet, "/proposals/3", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
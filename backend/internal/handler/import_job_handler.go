@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/csvimport"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportJobService 定义导入任务查询处理器依赖的业务能力。
+type ImportJobService interface {
+	Get(ctx context.Context, id int64) (*domain.ImportJob, error)
+	List(ctx context.Context, kind, status string, page, pageSize int) ([]domain.ImportJob, int64, error)
+	ErrorReport(ctx context.Context, id int64) ([]byte, error)
+}
+
+// ImportJobSubmitter 定义提交异步导入任务的能力，供各类导入端点复用。
+type ImportJobSubmitter interface {
+	Submit(ctx context.Context, kind, fileName string, data []byte, opts csvimport.Options, createdBy int64) (*domain.ImportJob, error)
+}
+
+// ImportJobHandler 提供异步导入任务的状态查询与错误报告下载端点。
+type ImportJobHandler struct {
+	svc ImportJobService
+}
+
+// NewImportJobHandler 创建导入任务处理器。
+func NewImportJobHandler(svc ImportJobService) *ImportJobHandler {
+	return &ImportJobHandler{svc: svc}
+}
+
+// List 处理 GET /api/v1/admin/import-jobs，支持按 kind、status 过滤。
+func (h *ImportJobHandler) List(c *gin.Context) {
+	items, total, err := h.svc.List(c.Request.Context(), c.Query("kind"), c.Query("status"), queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// Get 处理 GET /api/v1/admin/import-jobs/:id，返回任务状态与逐行统计。
+func (h *ImportJobHandler) Get(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	job, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		respondImportJobError(c, err)
+		return
+	}
+	response.Success(c, job)
+}
+
+// Errors 处理 GET /api/v1/admin/import-jobs/:id/errors，下载失败行错误报告 CSV。
+func (h *ImportJobHandler) Errors(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	report, err := h.svc.ErrorReport(c.Request.Context(), id)
+	if err != nil {
+		respondImportJobError(c, err)
+		return
+	}
+	if len(report) == 0 {
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, "import job has no error report")
+		return
+	}
+	writeImportErrorReport(c, fmt.Sprintf("import_job_%d_errors.csv", id), report)
+}
+
+// writeImportErrorReport 以附件形式输出导入错误报告。
+func writeImportErrorReport(c *gin.Context, filename string, report []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", report)
+}
+
+func respondImportJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrImportJobNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidImportJob), errors.Is(err, csvimport.ErrInvalidFile), errors.Is(err, csvimport.ErrInvalidMode):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/csvimport"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeImportJobSvc struct {
+	jobs      map[int64]domain.ImportJob
+	listKind  string
+	submitted []string
+	opts      csvimport.Options
+	err       error
+}
+
+func (f *fakeImportJobSvc) Get(_ context.Context, id int64) (*domain.ImportJob, error) {
+	job, ok := f.jobs[id]
+	if !ok {
+		return nil, service.ErrImportJobNotFound
+	}
+	return &job, nil
+}
+
+func (f *fakeImportJobSvc) List(_ context.Context, kind, _ string, _, _ int) ([]domain.ImportJob, int64, error) {
+	f.listKind = kind
+	if f.err != nil {
+		return nil, 0, f.err
+	}
+	return []domain.ImportJob{f.jobs[1]}, 1, nil
+}
+
+func (f *fakeImportJobSvc) ErrorReport(ctx context.Context, id int64) ([]byte, error) {
+	job, err := f.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(job.ErrorReport), nil
+}
+
+func (f *fakeImportJobSvc) Submit(_ context.Context, kind, fileName string, data []byte, opts csvimport.Options, _ int64) (*domain.ImportJob, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.submitted = append(f.submitted, kind, fileName, string(data))
+	f.opts = opts
+	return &domain.ImportJob{ID: 7, Kind: kind, Status: domain.ImportJobPending, Mode: opts.Mode, DryRun: opts.DryRun}, nil
+}
+
+func newImportJobTestRouter(svc *fakeImportJobSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewImportJobHandler(svc)
+	r := gin.New()
+	r.GET("/admin/import-jobs", h.List)
+	r.GET("/admin/import-jobs/:id", h.Get)
+	r.GET("/admin/import-jobs/:id/errors", h.Errors)
+	return r
+}
+
+func TestImportJobHandler(t *testing.T) {
+	svc := &fakeImportJobSvc{jobs: map[int64]domain.ImportJob{
+		1: {ID: 1, Kind: service.ImportKindCustomDestinations, Status: domain.ImportJobSucceeded, Failed: 1, ErrorReport: "_row,_error,name\n3,name: is required,\n"},
+		2: {ID: 2, Kind: service.ImportKindCustomDestinations, Status: domain.ImportJobRunning},
+	}}
+	r := newImportJobTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/admin/import-jobs?kind=custom_destinations", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, service.ImportKindCustomDestinations, svc.listKind)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+	assert.NotContains(t, w.Body.String(), "name: is required", "错误报告不随任务 JSON 返回")
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/import-jobs/2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"running"`)
+	w = doAgencyRequest(r, http.MethodGet, "/admin/import-jobs/404", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAgencyRequest(r, http.MethodGet, "/admin/import-jobs/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/import-jobs/1/errors", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "import_job_1_errors.csv")
+	assert.Contains(t, w.Body.String(), "3,name: is required")
+	w = doAgencyRequest(r, http.MethodGet, "/admin/import-jobs/2/errors", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "没有失败行时无错误报告")
+
+	svc.err = errors.New("db down")
+	w = doAgencyRequest(r, http.MethodGet, "/admin/import-jobs", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/repository"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newOperationLogTestRouter 搭建带审计中间件的后台路由：一个可被修改的邮轮资源，以及操作日志查询端点。
+func newOperationLogTestRouter(t *testing.T) (*gin.Engine, *gorm.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.OperationLog{}, &domain.OperationLogChainHead{}))
+
+	cruise := map[string]any{"id": float64(3), "name": "海洋量子号"}
+	svc := service.NewOperationAuditService(repository.NewOperationLogRepository(db)).
+		RegisterSnapshot("cruises", func(context.Context, int64) (any, error) { return cruise, nil })
+	h := NewOperationLogHandler(svc)
+
+	r := gin.New()
+	admin := r.Group("/api/v1/admin", func(c *gin.Context) {
+		c.Set(middleware.ContextKeyStaffID, c.GetHeader("X-Staff-ID"))
+	}, middleware.Audit(svc))
+	admin.PUT("/cruises/:id", func(c *gin.Context) {
+		var req map[string]any
+		_ = c.ShouldBindJSON(&req)
+		cruise = map[string]any{"id": float64(3), "name": req["name"]}
+		c.Status(http.StatusOK)
+	})
+	admin.GET("/operation-logs", h.List)
+	admin.GET("/operation-logs/export", h.ExportCSV)
+	admin.GET("/operation-logs/verify", h.Verify)
+	return r, db
+}
+
+func TestOperationLogHandler_AuditTrail(t *testing.T) {
+	r, db := newOperationLogTestRouter(t)
+
+	w := doStaffRequest(r, "5", http.MethodPut, "/api/v1/admin/cruises/3", `{"name":"海洋光谱号"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = doStaffRequest(r, "5", http.MethodGet, "/api/v1/admin/operation-logs?resource=cruises&resource_id=3&staff_id=5&from=2020-01-01", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Data struct {
+			List  []domain.OperationLog `json:"list"`
+			Total int64                 `json:"total"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.EqualValues(t, 1, listed.Data.Total)
+	entry := listed.Data.List[0]
+	assert.Equal(t, "update", entry.Operation)
+	assert.Equal(t, "/api/v1/admin/cruises/:id", entry.Route)
+	assert.JSONEq(t, `{"name":{"before":"海洋量子号","after":"海洋光谱号"}}`, entry.Changes)
+	assert.NotEmpty(t, entry.Hash)
+
+	w = doStaffRequest(r, "5", http.MethodGet, "/api/v1/admin/operation-logs?staff_id=abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doStaffRequest(r, "5", http.MethodGet, "/api/v1/admin/operation-logs/export?resource=cruises", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, w.Body.String(), "/api/v1/admin/cruises/:id")
+
+	w = doStaffRequest(r, "5", http.MethodGet, "/api/v1/admin/operation-logs/verify", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"valid":true`)
+
+	require.NoError(t, db.Model(&domain.OperationLog{}).Where("id = ?", entry.ID).Update("staff_id", 1).Error)
+	w = doStaffRequest(r, "5", http.MethodGet, "/api/v1/admin/operation-logs/verify", "")
+	assert.Contains(t, w.Body.String(), `"valid":false`)
+}
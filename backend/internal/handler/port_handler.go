@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PortService 定义港口主数据处理器依赖的业务能力。
+type PortService interface {
+	Create(ctx context.Context, port *domain.Port) error
+	Update(ctx context.Context, id int64, input *domain.Port) (*domain.Port, error)
+	Get(ctx context.Context, id int64) (*domain.Port, error)
+	List(ctx context.Context, filter domain.PortFilter, page, pageSize int) ([]domain.Port, int64, error)
+	Delete(ctx context.Context, id int64) error
+	ExportCSV(ctx context.Context) ([]byte, error)
+	ImportCSV(ctx context.Context, reader io.Reader) (*service.PortImportSummary, error)
+}
+
+// PortHandler 提供后台港口主数据的增删改查与 CSV 导入导出端点。
+type PortHandler struct {
+	svc PortService
+}
+
+// NewPortHandler 创建港口主数据处理器。
+func NewPortHandler(svc PortService) *PortHandler {
+	return &PortHandler{svc: svc}
+}
+
+// PortRequest 创建/更新港口的请求体。
+type PortRequest struct {
+	Code            string   `json:"code" binding:"required"`      // UN/LOCODE（必填）
+	NameZH          string   `json:"name_zh" binding:"required"`   // 中文名称（必填）
+	NameEN          string   `json:"name_en"`                      // 英文名称
+	Country         string   `json:"country"`                      // 国家/地区中文名
+	CountryCode     string   `json:"country_code"`                 // 国家代码，缺省取 UN/LOCODE 前两位
+	Timezone        string   `json:"timezone" binding:"required"`  // IANA 时区（必填）
+	Latitude        *float64 `json:"latitude" binding:"required"`  // 纬度（必填）
+	Longitude       *float64 `json:"longitude" binding:"required"` // 经度（必填）
+	Keywords        string   `json:"keywords"`                     // 搜索关键词（逗号分隔）
+	TerminalName    string   `json:"terminal_name"`                // 邮轮码头名称
+	TerminalAddress string   `json:"terminal_address"`             // 码头地址
+	TerminalNotes   string   `json:"terminal_notes"`               // 登船/交通说明
+	Status          *int16   `json:"status"`                       // 启用状态，缺省为启用
+	SortOrder       int      `json:"sort_order"`                   // 排序权重
+}
+
+func (r PortRequest) toPort() *domain.Port {
+	status := int16(1)
+	if r.Status != nil {
+		status = *r.Status
+	}
+	return &domain.Port{
+		Code:            r.Code,
+		NameZH:          r.NameZH,
+		NameEN:          r.NameEN,
+		Country:         r.Country,
+		CountryCode:     r.CountryCode,
+		Timezone:        r.Timezone,
+		Latitude:        *r.Latitude,
+		Longitude:       *r.Longitude,
+		Keywords:        r.Keywords,
+		TerminalName:    r.TerminalName,
+		TerminalAddress: r.TerminalAddress,
+		TerminalNotes:   r.TerminalNotes,
+		Status:          status,
+		SortOrder:       r.SortOrder,
+	}
+}
+
+// List 处理 GET /api/v1/admin/ports，可按 keyword、country_code、status 过滤。
+func (h *PortHandler) List(c *gin.Context) {
+	filter := domain.PortFilter{
+		Keyword:     c.Query("keyword"),
+		CountryCode: c.Query("country_code"),
+	}
+	if raw := c.Query("status"); raw != "" {
+		status := int16(queryInt(c, "status", 0))
+		filter.Status = &status
+	}
+	items, total, err := h.svc.List(c.Request.Context(), filter, queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// Get 处理 GET /api/v1/admin/ports/:id。
+func (h *PortHandler) Get(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	port, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		respondPortError(c, err)
+		return
+	}
+	response.Success(c, port)
+}
+
+// Create 处理 POST /api/v1/admin/ports。
+func (h *PortHandler) Create(c *gin.Context) {
+	var req PortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	port := req.toPort()
+	if err := h.svc.Create(c.Request.Context(), port); err != nil {
+		respondPortError(c, err)
+		return
+	}
+	response.Success(c, port)
+}
+
+// Update 处理 PUT /api/v1/admin/ports/:id。
+func (h *PortHandler) Update(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req PortRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	port, err := h.svc.Update(c.Request.Context(), id, req.toPort())
+	if err != nil {
+		respondPortError(c, err)
+		return
+	}
+	response.Success(c, port)
+}
+
+// Delete 处理 DELETE /api/v1/admin/ports/:id。
+func (h *PortHandler) Delete(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
+		respondPortError(c, err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// ExportCSV 处理 GET /api/v1/admin/ports/export，导出的文件可直接修改后重新导入。
+func (h *PortHandler) ExportCSV(c *gin.Context) {
+	data, err := h.svc.ExportCSV(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	filename := fmt.Sprintf("ports_%s.csv", time.Now().Format("20060102_150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// ImportCSV 处理 POST /api/v1/admin/ports/import（multipart 字段 file），按 UN/LOCODE 新增或覆盖港口。
+func (h *PortHandler) ImportCSV(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "csv file is required")
+		return
+	}
+	opened, err := file.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "failed to read csv file")
+		return
+	}
+	defer opened.Close()
+	summary, err := h.svc.ImportCSV(c.Request.Context(), opened)
+	if err != nil {
+		respondPortError(c, err)
+		return
+	}
+	response.Success(c, summary)
+}
+
+func respondPortError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPort):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrPortNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrPortCodeExists):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
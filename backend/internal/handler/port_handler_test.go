@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePortSvc struct {
+	err        error
+	created    *domain.Port
+	updatedID  int64
+	lastFilter domain.PortFilter
+	imported   string
+}
+
+func (f *fakePortSvc) Create(_ context.Context, port *domain.Port) error {
+	f.created = port
+	return f.err
+}
+
+func (f *fakePortSvc) Update(_ context.Context, id int64, input *domain.Port) (*domain.Port, error) {
+	f.updatedID = id
+	if f.err != nil {
+		return nil, f.err
+	}
+	input.ID = id
+	return input, nil
+}
+
+func (f *fakePortSvc) Get(_ context.Context, id int64) (*domain.Port, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Port{ID: id, Code: "CNSHA", NameZH: "上海"}, nil
+}
+
+func (f *fakePortSvc) List(_ context.Context, filter domain.PortFilter, _, _ int) ([]domain.Port, int64, error) {
+	f.lastFilter = filter
+	return []domain.Port{{ID: 1, Code: "CNSHA"}}, 1, nil
+}
+
+func (f *fakePortSvc) Delete(context.Context, int64) error { return f.err }
+
+func (f *fakePortSvc) ExportCSV(context.Context) ([]byte, error) {
+	return []byte("code,name_zh\nCNSHA,上海\n"), nil
+}
+
+func (f *fakePortSvc) ImportCSV(_ context.Context, reader io.Reader) (*service.PortImportSummary, error) {
+	data, _ := io.ReadAll(reader)
+	f.imported = string(data)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.PortImportSummary{Total: 1, Imported: 1, Errors: []service.PortImportRowError{}}, nil
+}
+
+func newPortTestRouter(svc *fakePortSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewPortHandler(svc)
+	r := gin.New()
+	r.GET("/admin/ports", h.List)
+	r.GET("/admin/ports/export", h.ExportCSV)
+	r.POST("/admin/ports/import", h.ImportCSV)
+	r.GET("/admin/ports/:id", h.Get)
+	r.POST("/admin/ports", h.Create)
+	r.PUT("/admin/ports/:id", h.Update)
+	r.DELETE("/admin/ports/:id", h.Delete)
+	return r
+}
+
+func TestPortHandler_CreateAndUpdate(t *testing.T) {
+	svc := &fakePortSvc{}
+	r := newPortTestRouter(svc)
+	body := `{"code":"JPFUK","name_zh":"福冈","timezone":"Asia/Tokyo","latitude":33.59,"longitude":130.40,"terminal_name":"博多港"}`
+
+	w := doAgencyRequest(r, http.MethodPost, "/admin/ports", body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.created)
+	assert.Equal(t, int16(1), svc.created.Status, "未传 status 时默认启用")
+	assert.Equal(t, "博多港", svc.created.TerminalName)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/ports", `{"code":"JPFUK","name_zh":"福冈","timezone":"Asia/Tokyo"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "坐标必填")
+
+	svc.err = service.ErrPortCodeExists
+	w = doAgencyRequest(r, http.MethodPost, "/admin/ports", body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	svc.err = service.ErrInvalidPort
+	w = doAgencyRequest(r, http.MethodPut, "/admin/ports/3", body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = nil
+	w = doAgencyRequest(r, http.MethodPut, "/admin/ports/3", `{"code":"JPFUK","name_zh":"福冈","timezone":"Asia/Tokyo","latitude":33.59,"longitude":130.40,"status":0}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(3), svc.updatedID)
+	assert.Contains(t, w.Body.String(), `"status":0`)
+}
+
+func TestPortHandler_GetListDelete(t *testing.T) {
+	svc := &fakePortSvc{}
+	r := newPortTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/admin/ports?keyword=sha&country_code=CN&status=0", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.lastFilter.Status)
+	assert.Equal(t, int16(0), *svc.lastFilter.Status)
+	assert.Equal(t, "sha", svc.lastFilter.Keyword)
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/ports/1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	svc.err = service.ErrPortNotFound
+	w = doAgencyRequest(r, http.MethodGet, "/admin/ports/9", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAgencyRequest(r, http.MethodDelete, "/admin/ports/9", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPortHandler_ImportAndExportCSV(t *testing.T) {
+	svc := &fakePortSvc{}
+	r := newPortTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/admin/ports/export", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Contains(t, w.Body.String(), "CNSHA")
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "ports.csv")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte("code,name_zh,timezone,latitude,longitude\nKRPUS,釜山,Asia/Seoul,35.18,129.08\n"))
+	require.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, "/admin/ports/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, svc.imported, "KRPUS")
+	assert.Contains(t, w.Body.String(), `"imported":1`)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/ports/import", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
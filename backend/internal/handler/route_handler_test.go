@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -286,3 +287,54 @@ func TestVoyageCreateHandler_AcceptsTemplateSelection(t *testing.T) {
 		t.Fatalf("expected booking_notice_mode=snapshot, got %+v", payload.Data)
 	}
 }
+
+// scheduleCheckingVoyageService 模拟服务层的行程时间表校验：返回警告或拒绝保存。
+type scheduleCheckingVoyageService struct {
+	fakeVoyageService
+	reject bool
+}
+
+func (f *scheduleCheckingVoyageService) Update(_ context.Context, v *domain.Voyage) error {
+	if f.reject {
+		return &service.ItineraryScheduleError{Issues: []domain.ItineraryIssue{
+			{Code: service.ItineraryIssueStopOverlap, DayNo: 2, StopIndex: 1, Message: "福冈 starts before 上海 ends"},
+		}}
+	}
+	v.Schedule = &domain.VoyageSchedule{DateSpanDays: 5, ItineraryDays: 1, PortDays: 1, Warnings: []domain.ItineraryIssue{
+		{Code: service.ItineraryIssueDaysShortOfDates, Message: "itinerary has 1 days but depart/return dates span 5 days"},
+	}}
+	return nil
+}
+
+func TestVoyageUpdateHandler_ReturnsScheduleWarningsAndIssues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &scheduleCheckingVoyageService{}
+	r := gin.New()
+	r.PUT("/voyages/:id", NewVoyageHandler(svc).Update)
+	body := `{"cruise_id": 11, "code": "RC101", "brief_info": "上海-福冈", "depart_date": "2026-04-02T00:00:00Z", "return_date": "2026-04-06T00:00:00Z",
+		"itineraries": [{"day_no": 1, "stop_index": 1, "city": "上海", "etd_time": "17:00"}]}`
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/voyages/5", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send()
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"code":"days_short_of_dates"`) {
+		t.Fatalf("expected schedule warnings in response, got %d body=%s", w.Code, w.Body.String())
+	}
+
+	svc.reject = true
+	w = send()
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid schedule, got %d body=%s", w.Code, w.Body.String())
+	}
+	var payload struct {
+		Issues []domain.ItineraryIssue `json:"issues"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil || len(payload.Issues) != 1 || payload.Issues[0].Code != "stop_overlap" {
+		t.Fatalf("expected structured issues, got %s", w.Body.String())
+	}
+}
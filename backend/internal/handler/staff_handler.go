@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -9,11 +10,13 @@ import (
 	"github.com/cruisebooking/backend/internal/middleware"
 	"github.com/cruisebooking/backend/internal/pkg/errcode"
 	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
 type StaffService interface {
 	Create(ctx context.Context, name, email, role string) (*domain.Staff, error)
+	CreateAccount(ctx context.Context, in service.StaffAccountInput) (*domain.Staff, error)
 	AssignRole(ctx context.Context, id int64, role string, operatorID int64) error
 	List(ctx context.Context) ([]domain.Staff, error)
 	GetByID(ctx context.Context, id int64) (*domain.Staff, error)
@@ -21,26 +24,68 @@ type StaffService interface {
 	Delete(ctx context.Context, id int64) error
 }
 
+// StaffSecurityService 定义管理员对员工账号执行的安全操作。
+type StaffSecurityService interface {
+	IssuePasswordReset(ctx context.Context, staffID, operatorID int64) (*service.StaffPasswordReset, error)
+	ResetTOTP(ctx context.Context, staffID, operatorID int64) error
+}
+
+// StaffCompanyService 定义员工公司数据范围的查询与授权。
+type StaffCompanyService interface {
+	GetCompanyScope(ctx context.Context, staffID int64) (domain.StaffCompanyScope, error)
+	AssignCompanies(ctx context.Context, staffID int64, scope domain.StaffCompanyScope, operatorID int64) error
+}
+
 type StaffHandler struct {
-	svc StaffService
+	svc       StaffService
+	security  StaffSecurityService
+	companies StaffCompanyService
 }
 
 func NewStaffHandler(svc StaffService) *StaffHandler {
 	return &StaffHandler{svc: svc}
 }
 
+// SetSecurityService 注入密码重置与二次验证重置能力，未注入时相关接口返回 500。
+func (h *StaffHandler) SetSecurityService(security StaffSecurityService) {
+	h.security = security
+}
+
+// SetCompanyService 注入员工公司数据范围能力，未注入时相关接口返回 500。
+func (h *StaffHandler) SetCompanyService(companies StaffCompanyService) {
+	h.companies = companies
+}
+
 func (h *StaffHandler) Create(c *gin.Context) {
 	var req struct {
-		Name  string `json:"name" binding:"required"`
-		Email string `json:"email" binding:"required,email"`
-		Role  string `json:"role" binding:"required"`
+		Name     string `json:"name" binding:"required"`
+		Email    string `json:"email" binding:"required,email"`
+		Role     string `json:"role" binding:"required"`
+		Username string `json:"username"` // 登录用户名，提供时创建可登录账号
+		Password string `json:"password"` // 初始密码，员工首次登录时必须修改；留空则通过重置令牌设置
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
 		return
 	}
 
-	staff, err := h.svc.Create(c.Request.Context(), req.Name, req.Email, req.Role)
+	var staff *domain.Staff
+	var err error
+	if req.Username != "" || req.Password != "" {
+		staff, err = h.svc.CreateAccount(c.Request.Context(), service.StaffAccountInput{
+			Name:     req.Name,
+			Email:    req.Email,
+			Role:     req.Role,
+			Username: req.Username,
+			Password: req.Password,
+		})
+	} else {
+		staff, err = h.svc.Create(c.Request.Context(), req.Name, req.Email, req.Role)
+	}
+	if errors.Is(err, service.ErrStaffPasswordPolicy) {
+		response.Error(c, http.StatusBadRequest, errcode.ErrPasswordPolicy, err.Error())
+		return
+	}
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
 		return
@@ -113,7 +158,7 @@ func (h *StaffHandler) Update(c *gin.Context) {
 	}
 
 	if err := h.svc.Update(c.Request.Context(), staff); err != nil {
-		response.InternalError(c, err)
+		respondStaffCompanyError(c, err)
 		return
 	}
 	response.Success(c, staff)
@@ -126,7 +171,7 @@ func (h *StaffHandler) Delete(c *gin.Context) {
 		return
 	}
 	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
-		response.InternalError(c, err)
+		respondStaffCompanyError(c, err)
 		return
 	}
 	c.Status(http.StatusNoContent)
@@ -148,12 +193,119 @@ func (h *StaffHandler) AssignRole(c *gin.Context) {
 	}
 
 	if err := h.svc.AssignRole(c.Request.Context(), id, req.Role, parseStaffID(c)); err != nil {
+		if errors.Is(err, service.ErrStaffManageDenied) {
+			response.Error(c, http.StatusForbidden, errcode.ErrForbidden, err.Error())
+			return
+		}
 		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
 		return
 	}
 	response.Success(c, gin.H{"id": id, "role": req.Role})
 }
 
+// ResetPassword 为员工签发一次性密码重置令牌（也用于新账号首次设置密码），令牌明文仅在本次响应中返回。
+func (h *StaffHandler) ResetPassword(c *gin.Context) {
+	if h.security == nil {
+		response.InternalError(c, errors.New("staff security service not configured"))
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	reset, err := h.security.IssuePasswordReset(c.Request.Context(), id, parseStaffID(c))
+	if err != nil {
+		respondStaffAuthError(c, err)
+		return
+	}
+	response.Success(c, reset)
+}
+
+// ResetTOTP 清除员工的二次验证绑定，供员工丢失验证器时重新绑定。
+func (h *StaffHandler) ResetTOTP(c *gin.Context) {
+	if h.security == nil {
+		response.InternalError(c, errors.New("staff security service not configured"))
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	if err := h.security.ResetTOTP(c.Request.Context(), id, parseStaffID(c)); err != nil {
+		respondStaffAuthError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"id": id, "totp_enabled": false})
+}
+
+// GetCompanies 返回员工的公司数据范围：all_companies 为 true 表示平台管理员，否则只能访问 company_ids，空列表表示无权访问任何公司。
+func (h *StaffHandler) GetCompanies(c *gin.Context) {
+	if h.companies == nil {
+		response.InternalError(c, errors.New("staff company service not configured"))
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	scope, err := h.companies.GetCompanyScope(c.Request.Context(), id)
+	if err != nil {
+		respondStaffCompanyError(c, err)
+		return
+	}
+	respondStaffCompanyScope(c, id, scope)
+}
+
+// SetCompanies 整体替换员工的公司数据范围：all_companies 为 true 时可访问全部公司（仅不受限的管理员可授予），
+// 否则只能访问 company_ids 中的公司，空列表表示收回全部授权。
+func (h *StaffHandler) SetCompanies(c *gin.Context) {
+	if h.companies == nil {
+		response.InternalError(c, errors.New("staff company service not configured"))
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, "invalid id")
+		return
+	}
+	var req domain.StaffCompanyScope
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if err := h.companies.AssignCompanies(c.Request.Context(), id, req, parseStaffID(c)); err != nil {
+		respondStaffCompanyError(c, err)
+		return
+	}
+	if req.AllCompanies {
+		req.CompanyIDs = nil
+	}
+	respondStaffCompanyScope(c, id, req)
+}
+
+func respondStaffCompanyScope(c *gin.Context, id int64, scope domain.StaffCompanyScope) {
+	if scope.CompanyIDs == nil {
+		scope.CompanyIDs = []int64{}
+	}
+	response.Success(c, gin.H{"id": id, "all_companies": scope.AllCompanies, "company_ids": scope.CompanyIDs})
+}
+
+func respondStaffCompanyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrStaffNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrStaffCompanyNotFound):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrStaffCompanyScopeDenied), errors.Is(err, service.ErrStaffManageDenied):
+		response.Error(c, http.StatusForbidden, errcode.ErrForbidden, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
+
 func parseStaffID(c *gin.Context) int64 {
 	v, ok := c.Get(middleware.ContextKeyStaffID)
 	if !ok {
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStaffSvc struct {
+	created *service.StaffAccountInput
+	legacy  bool
+	err     error
+}
+
+func (f *fakeStaffSvc) Create(_ context.Context, name, email, role string) (*domain.Staff, error) {
+	f.legacy = true
+	return &domain.Staff{ID: 1, RealName: name, Email: email, Role: role}, f.err
+}
+
+func (f *fakeStaffSvc) CreateAccount(_ context.Context, in service.StaffAccountInput) (*domain.Staff, error) {
+	f.created = &in
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.Staff{ID: 2, Username: in.Username, RealName: in.Name, Role: in.Role, PasswordHash: "hash", TOTPSecret: "SECRET"}, nil
+}
+
+func (f *fakeStaffSvc) AssignRole(context.Context, int64, string, int64) error { return nil }
+func (f *fakeStaffSvc) List(context.Context) ([]domain.Staff, error)           { return nil, nil }
+func (f *fakeStaffSvc) GetByID(context.Context, int64) (*domain.Staff, error)  { return nil, nil }
+func (f *fakeStaffSvc) Update(context.Context, *domain.Staff) error            { return nil }
+func (f *fakeStaffSvc) Delete(context.Context, int64) error                    { return nil }
+
+type fakeStaffSecuritySvc struct {
+	operatorID int64
+	err        error
+}
+
+func (f *fakeStaffSecuritySvc) IssuePasswordReset(_ context.Context, staffID, operatorID int64) (*service.StaffPasswordReset, error) {
+	f.operatorID = operatorID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.StaffPasswordReset{Token: "one-time-token", ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (f *fakeStaffSecuritySvc) ResetTOTP(_ context.Context, staffID, operatorID int64) error {
+	f.operatorID = operatorID
+	return f.err
+}
+
+func newStaffTestRouter(svc *fakeStaffSvc, security *fakeStaffSecuritySvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewStaffHandler(svc)
+	if security != nil {
+		h.SetSecurityService(security)
+	}
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(middleware.ContextKeyStaffID, "9") })
+	r.POST("/staffs", h.Create)
+	r.POST("/staffs/:id/reset-password", h.ResetPassword)
+	r.DELETE("/staffs/:id/totp", h.ResetTOTP)
+	return r
+}
+
+func TestStaffHandler_CreateAccount(t *testing.T) {
+	svc := &fakeStaffSvc{}
+	r := newStaffTestRouter(svc, nil)
+
+	w := doAgencyRequest(r, http.MethodPost, "/staffs", `{"name":"张三","email":"z@example.com","role":"operator"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, svc.legacy, "requests without credentials keep the original behaviour")
+
+	w = doAgencyRequest(r, http.MethodPost, "/staffs", `{"name":"王五","email":"w@example.com","role":"finance","username":"wangwu","password":"Str0ng-Passw0rd"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "wangwu", svc.created.Username)
+	assert.NotContains(t, w.Body.String(), "hash", "password hashes must never be serialised")
+	assert.NotContains(t, w.Body.String(), "SECRET", "TOTP secrets must never be serialised")
+
+	svc.err = service.ErrStaffPasswordPolicy
+	w = doAgencyRequest(r, http.MethodPost, "/staffs", `{"name":"王五","email":"w@example.com","role":"finance","username":"wangwu","password":"weak"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, errcode.ErrPasswordPolicy, decodeResponse(t, w.Body.Bytes()).Code)
+}
+
+func TestStaffHandler_SecurityResets(t *testing.T) {
+	security := &fakeStaffSecuritySvc{}
+	r := newStaffTestRouter(&fakeStaffSvc{}, security)
+
+	w := doAgencyRequest(r, http.MethodPost, "/staffs/3/reset-password", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "one-time-token")
+	assert.EqualValues(t, 9, security.operatorID)
+
+	w = doAgencyRequest(r, http.MethodDelete, "/staffs/3/totp", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	security.err = service.ErrStaffNotFound
+	w = doAgencyRequest(r, http.MethodPost, "/staffs/404/reset-password", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doAgencyRequest(r, http.MethodDelete, "/staffs/x/totp", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAgencyRequest(newStaffTestRouter(&fakeStaffSvc{}, nil), http.MethodPost, "/staffs/3/reset-password", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+type fakeStaffCompanySvc struct {
+	assigned   domain.StaffCompanyScope
+	operatorID int64
+	err        error
+}
+
+func (f *fakeStaffCompanySvc) GetCompanyScope(context.Context, int64) (domain.StaffCompanyScope, error) {
+	return f.assigned, f.err
+}
+
+func (f *fakeStaffCompanySvc) AssignCompanies(_ context.Context, _ int64, scope domain.StaffCompanyScope, operatorID int64) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.assigned, f.operatorID = scope, operatorID
+	return nil
+}
+
+func TestStaffHandler_Companies(t *testing.T) {
+	companies := &fakeStaffCompanySvc{}
+	h := NewStaffHandler(&fakeStaffSvc{})
+	h.SetCompanyService(companies)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(middleware.ContextKeyStaffID, "9") })
+	r.GET("/staffs/:id/companies", h.GetCompanies)
+	r.PUT("/staffs/:id/companies", h.SetCompanies)
+
+	w := doAgencyRequest(r, http.MethodGet, "/staffs/3/companies", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"all_companies":false,"company_ids":[]`, "staff without assignments report an empty list")
+
+	w = doAgencyRequest(r, http.MethodPut, "/staffs/3/companies", `{"company_ids":[1,2]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, domain.StaffCompanyScope{CompanyIDs: []int64{1, 2}}, companies.assigned)
+	assert.EqualValues(t, 9, companies.operatorID)
+
+	w = doAgencyRequest(r, http.MethodGet, "/staffs/3/companies", "")
+	assert.Contains(t, w.Body.String(), `"all_companies":false,"company_ids":[1,2]`)
+
+	w = doAgencyRequest(r, http.MethodPut, "/staffs/3/companies", `{"all_companies":true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, companies.assigned.AllCompanies)
+	assert.Contains(t, w.Body.String(), `"all_companies":true,"company_ids":[]`)
+
+	for err, status := range map[error]int{
+		service.ErrStaffNotFound:           http.StatusNotFound,
+		service.ErrStaffCompanyNotFound:    http.StatusBadRequest,
+		service.ErrStaffCompanyScopeDenied: http.StatusForbidden,
+	} {
+		companies.err = err
+		w = doAgencyRequest(r, http.MethodPut, "/staffs/3/companies", `{"company_ids":[]}`)
+		assert.Equal(t, status, w.Code, err.Error())
+	}
+
+	w = doAgencyRequest(r, http.MethodPut, "/staffs/x/companies", `{"company_ids":[1]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// UserAccountService 定义 C 端用户自助管理能力：资料、换绑手机号、注销与个人信息导出。
+type UserAccountService interface {
+	Profile(ctx context.Context, userID int64) (*service.UserProfile, error)
+	UpdateProfile(ctx context.Context, userID int64, update service.UserProfileUpdate) (*service.UserProfile, error)
+	ChangePhone(ctx context.Context, userID int64, phone, code, oldCode string) (*service.UserProfile, error)
+	RequestDeletion(ctx context.Context, userID int64, code string) (*service.UserProfile, error)
+	CancelDeletion(ctx context.Context, userID int64) (*service.UserProfile, error)
+	Export(ctx context.Context, userID int64) (*service.UserDataExport, error)
+}
+
+// SetAccountService 启用用户自助管理端点，GET /users/profile 同时改为返回完整资料。
+func (h *UserHandler) SetAccountService(account UserAccountService) {
+	h.account = account
+}
+
+// UpdateProfileRequest 表示修改个人资料请求体，三项整体覆盖。
+type UpdateProfileRequest struct {
+	Nickname  string `json:"nickname"`   // 昵称，最多 50 个字符
+	AvatarURL string `json:"avatar_url"` // 头像地址，须为 http(s) URL
+	Email     string `json:"email"`      // 邮箱
+}
+
+// ChangePhoneRequest 表示换绑手机号请求体。
+type ChangePhoneRequest struct {
+	Phone   string `json:"phone" binding:"required"` // 新手机号
+	Code    string `json:"code" binding:"required"`  // 发送到新手机号的验证码
+	OldCode string `json:"old_code"`                 // 发送到原手机号的验证码，已绑定手机号时必填
+}
+
+// DeleteAccountRequest 表示注销申请请求体。
+type DeleteAccountRequest struct {
+	Code string `json:"code"` // 发送到绑定手机号的验证码，已绑定手机号时必填
+}
+
+func (h *UserHandler) accountProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	profile, err := h.account.Profile(c.Request.Context(), userID)
+	if err != nil {
+		respondUserAccountError(c, err)
+		return
+	}
+	response.Success(c, profile)
+}
+
+// UpdateProfile 处理 PUT /users/profile：修改昵称、头像与邮箱。
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	userID, ok := h.accountUserID(c)
+	if !ok {
+		return
+	}
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	profile, err := h.account.UpdateProfile(c.Request.Context(), userID, service.UserProfileUpdate{
+		Nickname:  req.Nickname,
+		AvatarURL: req.AvatarURL,
+		Email:     req.Email,
+	})
+	if err != nil {
+		respondUserAccountError(c, err)
+		return
+	}
+	response.Success(c, profile)
+}
+
+// ChangePhone 处理 PUT /users/phone：新旧手机号均通过短信验证后换绑。
+func (h *UserHandler) ChangePhone(c *gin.Context) {
+	userID, ok := h.accountUserID(c)
+	if !ok {
+		return
+	}
+	var req ChangePhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	profile, err := h.account.ChangePhone(c.Request.Context(), userID, req.Phone, req.Code, req.OldCode)
+	if err != nil {
+		respondUserAccountError(c, err)
+		return
+	}
+	response.Success(c, profile)
+}
+
+// RequestDeletion 处理 POST /users/deletion：申请注销，进入冷静期。
+func (h *UserHandler) RequestDeletion(c *gin.Context) {
+	userID, ok := h.accountUserID(c)
+	if !ok {
+		return
+	}
+	var req DeleteAccountRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+			return
+		}
+	}
+	profile, err := h.account.RequestDeletion(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		respondUserAccountError(c, err)
+		return
+	}
+	response.Success(c, profile)
+}
+
+// CancelDeletion 处理 DELETE /users/deletion：冷静期内撤销注销申请。
+func (h *UserHandler) CancelDeletion(c *gin.Context) {
+	userID, ok := h.accountUserID(c)
+	if !ok {
+		return
+	}
+	profile, err := h.account.CancelDeletion(c.Request.Context(), userID)
+	if err != nil {
+		respondUserAccountError(c, err)
+		return
+	}
+	response.Success(c, profile)
+}
+
+// ExportData 处理 GET /users/export：以 JSON 附件下载本人的资料、订单、出行乘客与通知。
+func (h *UserHandler) ExportData(c *gin.Context) {
+	userID, ok := h.accountUserID(c)
+	if !ok {
+		return
+	}
+	export, err := h.account.Export(c.Request.Context(), userID)
+	if err != nil {
+		respondUserAccountError(c, err)
+		return
+	}
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	filename := fmt.Sprintf("user_%d_data_%s.json", userID, export.ExportedAt.Format("20060102_150405"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// accountUserID 校验自助管理服务可用并解析当前用户 ID；失败时直接写入响应。
+func (h *UserHandler) accountUserID(c *gin.Context) (int64, bool) {
+	if h.account == nil {
+		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, "user account service unavailable")
+		return 0, false
+	}
+	return currentUserID(c)
+}
+
+func respondUserAccountError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrProfileInvalid),
+		errors.Is(err, service.ErrBindPayloadInvalid),
+		errors.Is(err, service.ErrSMSVerificationFailed):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrUserDisabled):
+		response.Error(c, http.StatusForbidden, errcode.ErrForbidden, err.Error())
+	case errors.Is(err, service.ErrUserNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrThirdPartyAlreadyBound),
+		errors.Is(err, service.ErrAccountHasActiveOrders),
+		errors.Is(err, service.ErrDeletionNotRequested):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUserAccountSvc struct {
+	err      error
+	userID   int64
+	update   service.UserProfileUpdate
+	phone    string
+	codes    [2]string
+	canceled bool
+}
+
+func (f *fakeUserAccountSvc) profile(userID int64) (*service.UserProfile, error) {
+	f.userID = userID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.UserProfile{ID: userID, Phone: "13800000001", Nickname: "海风"}, nil
+}
+
+func (f *fakeUserAccountSvc) Profile(_ context.Context, userID int64) (*service.UserProfile, error) {
+	return f.profile(userID)
+}
+
+func (f *fakeUserAccountSvc) UpdateProfile(_ context.Context, userID int64, update service.UserProfileUpdate) (*service.UserProfile, error) {
+	f.update = update
+	return f.profile(userID)
+}
+
+func (f *fakeUserAccountSvc) ChangePhone(_ context.Context, userID int64, phone, code, oldCode string) (*service.UserProfile, error) {
+	f.phone, f.codes = phone, [2]string{code, oldCode}
+	return f.profile(userID)
+}
+
+func (f *fakeUserAccountSvc) RequestDeletion(_ context.Context, userID int64, code string) (*service.UserProfile, error) {
+	f.codes = [2]string{code}
+	return f.profile(userID)
+}
+
+func (f *fakeUserAccountSvc) CancelDeletion(_ context.Context, userID int64) (*service.UserProfile, error) {
+	f.canceled = true
+	return f.profile(userID)
+}
+
+func (f *fakeUserAccountSvc) Export(_ context.Context, userID int64) (*service.UserDataExport, error) {
+	profile, err := f.profile(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &service.UserDataExport{ExportedAt: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), Profile: *profile, Orders: []service.ExportedOrder{}}, nil
+}
+
+func newUserAccountTestRouter(svc *fakeUserAccountSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewUserHandler(userHandlerTestAuthSvc{ok: true}, "secret")
+	h.SetAccountService(svc)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(middleware.ContextKeyUserID, "42") })
+	r.GET("/users/profile", h.Profile)
+	r.PUT("/users/profile", h.UpdateProfile)
+	r.PUT("/users/phone", h.ChangePhone)
+	r.POST("/users/deletion", h.RequestDeletion)
+	r.DELETE("/users/deletion", h.CancelDeletion)
+	r.GET("/users/export", h.ExportData)
+	return r
+}
+
+func TestUserAccountHandler_ProfileAndPhone(t *testing.T) {
+	svc := &fakeUserAccountSvc{}
+	r := newUserAccountTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/users/profile", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, 42, svc.userID)
+	assert.Contains(t, w.Body.String(), `"nickname":"海风"`)
+
+	w = doAgencyRequest(r, http.MethodPut, "/users/profile", `{"nickname":"Alice","avatar_url":"https://cdn/a.png","email":"a@example.com"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, service.UserProfileUpdate{Nickname: "Alice", AvatarURL: "https://cdn/a.png", Email: "a@example.com"}, svc.update)
+
+	w = doAgencyRequest(r, http.MethodPut, "/users/phone", `{"phone":"13900000009","code":"999999","old_code":"111111"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "13900000009", svc.phone)
+	assert.Equal(t, [2]string{"999999", "111111"}, svc.codes)
+	w = doAgencyRequest(r, http.MethodPut, "/users/phone", `{"phone":"13900000009"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	for err, status := range map[error]int{
+		service.ErrProfileInvalid:         http.StatusBadRequest,
+		service.ErrSMSVerificationFailed:  http.StatusBadRequest,
+		service.ErrThirdPartyAlreadyBound: http.StatusConflict,
+		service.ErrUserDisabled:           http.StatusForbidden,
+		service.ErrUserNotFound:           http.StatusNotFound,
+	} {
+		svc.err = err
+		w = doAgencyRequest(r, http.MethodPut, "/users/phone", `{"phone":"13900000009","code":"999999"}`)
+		assert.Equal(t, status, w.Code, err.Error())
+	}
+}
+
+func TestUserAccountHandler_DeletionAndExport(t *testing.T) {
+	svc := &fakeUserAccountSvc{}
+	r := newUserAccountTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/users/deletion", `{"code":"111111"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "111111", svc.codes[0])
+	w = doAgencyRequest(r, http.MethodPost, "/users/deletion", "")
+	assert.Equal(t, http.StatusOK, w.Code, "未绑定手机号的用户可不带请求体申请注销")
+	w = doAgencyRequest(r, http.MethodDelete, "/users/deletion", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, svc.canceled)
+
+	w = doAgencyRequest(r, http.MethodGet, "/users/export", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Equal(t, `attachment; filename="user_42_data_20261019_120000.json"`, w.Header().Get("Content-Disposition"))
+	var export service.UserDataExport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	assert.EqualValues(t, 42, export.Profile.ID)
+
+	svc.err = service.ErrAccountHasActiveOrders
+	w = doAgencyRequest(r, http.MethodPost, "/users/deletion", `{"code":"111111"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	svc.err = service.ErrDeletionNotRequested
+	w = doAgencyRequest(r, http.MethodDelete, "/users/deletion", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestUserAccountHandler_Unavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewUserHandler(userHandlerTestAuthSvc{ok: true}, "secret")
+	r := gin.New()
+	r.GET("/users/export", h.ExportData)
+
+	w := doAgencyRequest(r, http.MethodGet, "/users/export", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
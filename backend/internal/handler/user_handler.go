@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"crypto/rand"
 	"errors"
 	"fmt"
@@ -29,10 +30,18 @@ type UserRepository interface {
 	FindOrCreateByPhone(phone string) (*domain.User, error)
 }
 
+// WechatAuthService 定义小程序登录与手机号授权绑定能力。
+type WechatAuthService interface {
+	WechatLogin(ctx context.Context, code string) (*domain.User, error)
+	BindWechatPhone(ctx context.Context, userID int64, code, encryptedData, iv string) (*domain.User, error)
+}
+
 // UserHandler 处理 C 端登录、发码与个人信息接口。
 type UserHandler struct {
 	authSvc   UserAuthService
 	userRepo  UserRepository // 可为 nil（向下兼容）
+	wechat    WechatAuthService
+	account   UserAccountService
 	jwtSecret string
 }
 
@@ -47,6 +56,11 @@ func NewUserHandlerWithRepo(authSvc UserAuthService, userRepo UserRepository, jw
 	return &UserHandler{authSvc: authSvc, userRepo: userRepo, jwtSecret: jwtSecret}
 }
 
+// SetWechatAuth 启用小程序登录（POST /users/wx-login）与手机号授权绑定。
+func (h *UserHandler) SetWechatAuth(wechat WechatAuthService) {
+	h.wechat = wechat
+}
+
 // UserLoginRequest 表示用户短信验证码登录请求体。
 type UserLoginRequest struct {
 	Phone string `json:"phone" binding:"required"`
@@ -88,6 +102,11 @@ func (h *UserHandler) Login(c *gin.Context) {
 		sub = strconv.FormatInt(user.ID, 10)
 	}
 
+	response.Success(c, h.issueToken(sub))
+}
+
+// issueToken 为 C 端用户签发 24 小时有效的 JWT。
+func (h *UserHandler) issueToken(sub string) UserLoginResponse {
 	expireAt := time.Now().Add(24 * time.Hour)
 	tokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub":   sub,
@@ -95,8 +114,86 @@ func (h *UserHandler) Login(c *gin.Context) {
 		"exp":   expireAt.Unix(),
 	})
 	token, _ := tokenObj.SignedString([]byte(h.jwtSecret))
+	return UserLoginResponse{Token: token, ExpireAt: expireAt}
+}
+
+// WechatLoginRequest 表示小程序登录请求体。
+type WechatLoginRequest struct {
+	Code string `json:"code" binding:"required"` // wx.login 返回的登录凭证
+}
+
+// WechatLoginResponse 表示小程序登录结果；phone_bound 为 false 时前端应引导手机号授权。
+type WechatLoginResponse struct {
+	UserLoginResponse
+	PhoneBound bool `json:"phone_bound"`
+}
 
-	response.Success(c, UserLoginResponse{Token: token, ExpireAt: expireAt})
+// WechatLogin 处理 POST /users/wx-login：服务端用 code 调用 code2session 换取 OpenID 后签发 JWT。
+func (h *UserHandler) WechatLogin(c *gin.Context) {
+	var req WechatLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if h.wechat == nil || h.jwtSecret == "" {
+		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, "wechat login unavailable")
+		return
+	}
+	user, err := h.wechat.WechatLogin(c.Request.Context(), req.Code)
+	if err != nil {
+		respondWechatAuthError(c, err)
+		return
+	}
+	response.Success(c, WechatLoginResponse{UserLoginResponse: h.issueToken(strconv.FormatInt(user.ID, 10)), PhoneBound: user.Phone != ""})
+}
+
+// WechatPhoneRequest 表示手机号授权绑定请求体，字段来自 getPhoneNumber 回调。
+type WechatPhoneRequest struct {
+	Code          string `json:"code"`                              // 可选：新的 wx.login 凭证，用于刷新会话密钥
+	EncryptedData string `json:"encrypted_data" binding:"required"` // 加密的手机号数据
+	IV            string `json:"iv" binding:"required"`             // 加密向量
+}
+
+// BindWechatPhone 处理 POST /users/wx-phone：解密手机号授权数据并绑定到当前用户。
+func (h *UserHandler) BindWechatPhone(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req WechatPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	if h.wechat == nil {
+		response.Error(c, http.StatusInternalServerError, errcode.ErrInternal, "wechat login unavailable")
+		return
+	}
+	user, err := h.wechat.BindWechatPhone(c.Request.Context(), userID, req.Code, req.EncryptedData, req.IV)
+	if err != nil {
+		respondWechatAuthError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"id": user.ID, "phone": user.Phone})
+}
+
+func respondWechatAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrWechatCodeInvalid):
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, err.Error())
+	case errors.Is(err, service.ErrWechatPhoneInvalid), errors.Is(err, service.ErrBindPayloadInvalid):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrUserDisabled):
+		response.Error(c, http.StatusForbidden, errcode.ErrForbidden, err.Error())
+	case errors.Is(err, service.ErrUserNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrThirdPartyAlreadyBound), errors.Is(err, service.ErrAccountAlreadyBound):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	case errors.Is(err, service.ErrWechatUnavailable):
+		response.Error(c, http.StatusBadGateway, errcode.ErrInternal, "wechat service unavailable")
+	default:
+		response.InternalError(c, err)
+	}
 }
 
 // SendCodeRequest 表示短信验证码发送请求体。
@@ -132,8 +229,12 @@ func (h *UserHandler) SendCode(c *gin.Context) {
 	response.Success(c, gin.H{"status": "sent"})
 }
 
-// Profile 返回当前登录用户的基础身份信息。
+// Profile 返回当前登录用户的个人资料；未启用自助管理服务时仅返回用户标识。
 func (h *UserHandler) Profile(c *gin.Context) {
+	if h.account != nil {
+		h.accountProfile(c)
+		return
+	}
 	userID, exists := c.Get(middleware.ContextKeyUserID) // M-01: C端使用 ContextKeyUserID
 	if !exists {
 		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "not authenticated")
@@ -2,10 +2,12 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/middleware"
 	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
@@ -104,3 +106,63 @@ func TestUserHandlerSendCodeTooFrequent(t *testing.T) {
 		t.Fatalf("expected 429, got %d, body=%s", w.Code, w.Body.String())
 	}
 }
+
+type userHandlerWechatSvc struct {
+	err    error
+	userID int64
+	code   string
+}
+
+func (s *userHandlerWechatSvc) WechatLogin(_ context.Context, code string) (*domain.User, error) {
+	s.code = code
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.User{ID: 42, WxOpenID: "openid-1"}, nil
+}
+
+func (s *userHandlerWechatSvc) BindWechatPhone(_ context.Context, userID int64, code, _, _ string) (*domain.User, error) {
+	s.userID, s.code = userID, code
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.User{ID: userID, Phone: "13800000000"}, nil
+}
+
+// TestUserHandlerWechatLoginAndPhone 测试小程序登录与手机号授权绑定
+func TestUserHandlerWechatLoginAndPhone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := &userHandlerWechatSvc{}
+	h := NewUserHandler(userHandlerTestAuthSvc{ok: true}, "secret")
+	h.SetWechatAuth(svc)
+	r := gin.New()
+	r.POST("/api/users/wx-login", h.WechatLogin)
+	r.POST("/api/users/wx-phone", func(c *gin.Context) { c.Set(middleware.ContextKeyUserID, "42") }, h.BindWechatPhone)
+
+	w := doAgencyRequest(r, http.MethodPost, "/api/users/wx-login", `{"code":"code-1"}`)
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"phone_bound":false`)) || svc.code != "code-1" {
+		t.Fatalf("unexpected wechat login: %d %s", w.Code, w.Body.String())
+	}
+	w = doAgencyRequest(r, http.MethodPost, "/api/users/wx-login", `{}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without code, got %d", w.Code)
+	}
+	w = doAgencyRequest(r, http.MethodPost, "/api/users/wx-phone", `{"code":"code-2","encrypted_data":"x","iv":"y"}`)
+	if w.Code != http.StatusOK || svc.userID != 42 || svc.code != "code-2" || !bytes.Contains(w.Body.Bytes(), []byte("13800000000")) {
+		t.Fatalf("unexpected phone binding: %d %s", w.Code, w.Body.String())
+	}
+
+	cases := map[error]int{
+		service.ErrWechatCodeInvalid:      http.StatusUnauthorized,
+		service.ErrWechatPhoneInvalid:     http.StatusBadRequest,
+		service.ErrUserDisabled:           http.StatusForbidden,
+		service.ErrThirdPartyAlreadyBound: http.StatusConflict,
+		service.ErrWechatUnavailable:      http.StatusBadGateway,
+	}
+	for err, status := range cases {
+		svc.err = err
+		if w := doAgencyRequest(r, http.MethodPost, "/api/users/wx-phone", `{"encrypted_data":"x","iv":"y"}`); w.Code != status {
+			t.Fatalf("%v: expected %d, got %d", err, status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// VoyageCloneService 定义航次克隆处理器依赖的业务能力。
+type VoyageCloneService interface {
+	Clone(ctx context.Context, sourceID int64, opts service.VoyageCloneOptions, staffID int64) (*service.VoyageCloneReport, error)
+}
+
+// VoyageCloneHandler 提供后台复制航次（含舱位、库存与价格）的端点。
+type VoyageCloneHandler struct {
+	svc VoyageCloneService
+}
+
+// NewVoyageCloneHandler 创建航次克隆处理器。
+func NewVoyageCloneHandler(svc VoyageCloneService) *VoyageCloneHandler {
+	return &VoyageCloneHandler{svc: svc}
+}
+
+// VoyageCloneRequest 克隆航次的请求体；复制选项缺省均为 true。
+type VoyageCloneRequest struct {
+	Code              string `json:"code" binding:"required"`        // 新航次编码（必填）
+	DepartDate        string `json:"depart_date" binding:"required"` // 新出发日期 YYYY-MM-DD（必填）
+	Status            *int16 `json:"status"`                         // 新航次状态，缺省沿用源航次
+	CopyItineraries   *bool  `json:"copy_itineraries"`               // 复制行程
+	CopyCabins        *bool  `json:"copy_cabins"`                    // 复制舱位 SKU
+	CopyInventory     *bool  `json:"copy_inventory"`                 // 复制库存总量与预警阈值
+	CopyPriceCalendar *bool  `json:"copy_price_calendar"`            // 复制并平移日历价格
+	CopyPriceVersions *bool  `json:"copy_price_versions"`            // 复制舱型价格及待生效版本
+}
+
+func (r VoyageCloneRequest) toOptions() (service.VoyageCloneOptions, error) {
+	depart, err := time.Parse("2006-01-02", strings.TrimSpace(r.DepartDate))
+	if err != nil {
+		return service.VoyageCloneOptions{}, errors.New("depart_date must be YYYY-MM-DD")
+	}
+	// 不复制舱位时，库存与日历价格默认随之关闭
+	cabins := boolOrTrue(r.CopyCabins)
+	return service.VoyageCloneOptions{
+		Code:          r.Code,
+		DepartDate:    depart,
+		Status:        r.Status,
+		Itineraries:   boolOrTrue(r.CopyItineraries),
+		Cabins:        cabins,
+		Inventory:     boolOr(r.CopyInventory, cabins),
+		PriceCalendar: boolOr(r.CopyPriceCalendar, cabins),
+		PriceVersions: boolOrTrue(r.CopyPriceVersions),
+	}, nil
+}
+
+// Clone 处理 POST /api/v1/admin/voyages/:id/clone，按新出发日期复制航次并返回创建报告。
+func (h *VoyageCloneHandler) Clone(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req VoyageCloneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	opts, err := req.toOptions()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	report, err := h.svc.Clone(c.Request.Context(), id, opts, parseOperatorID(c))
+	if err != nil {
+		respondVoyageCloneError(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// boolOr 返回可选布尔参数的值，未传时使用 def。
+func boolOr(v *bool, def bool) bool {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+func boolOrTrue(v *bool) bool {
+	return boolOr(v, true)
+}
+
+func respondVoyageCloneError(c *gin.Context, err error) {
+	var scheduleErr *service.ItineraryScheduleError
+	switch {
+	case errors.As(err, &scheduleErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "issues": scheduleErr.Issues})
+	case errors.Is(err, service.ErrInvalidVoyageClone):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrVoyageCloneSourceNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrVoyageCloneConflict):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVoyageCloneSvc struct {
+	err      error
+	sourceID int64
+	opts     service.VoyageCloneOptions
+	staffID  int64
+}
+
+func (f *fakeVoyageCloneSvc) Clone(_ context.Context, sourceID int64, opts service.VoyageCloneOptions, staffID int64) (*service.VoyageCloneReport, error) {
+	f.sourceID, f.opts, f.staffID = sourceID, opts, staffID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.VoyageCloneReport{SourceVoyageID: sourceID, Voyage: &domain.Voyage{ID: 77, Code: opts.Code}, CabinSKUs: 2, Skipped: []string{}}, nil
+}
+
+func newVoyageCloneTestRouter(svc *fakeVoyageCloneSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewVoyageCloneHandler(svc)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(middleware.ContextKeyStaffID, "9") })
+	r.POST("/admin/voyages/:id/clone", h.Clone)
+	return r
+}
+
+func TestVoyageCloneHandler_Clone(t *testing.T) {
+	svc := &fakeVoyageCloneSvc{}
+	r := newVoyageCloneTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/clone", `{"code":"SPEC-20260419","depart_date":"2026-04-19"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"cabin_skus":2`)
+	assert.Equal(t, int64(5), svc.sourceID)
+	assert.Equal(t, int64(9), svc.staffID)
+	assert.Equal(t, "2026-04-19", svc.opts.DepartDate.Format("2006-01-02"))
+	assert.True(t, svc.opts.Itineraries && svc.opts.Cabins && svc.opts.Inventory && svc.opts.PriceCalendar && svc.opts.PriceVersions)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/clone", `{"code":"SPEC-20260419","depart_date":"2026-04-19","copy_cabins":false,"copy_price_versions":false,"status":0}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, svc.opts.Cabins || svc.opts.Inventory || svc.opts.PriceCalendar || svc.opts.PriceVersions)
+	assert.True(t, svc.opts.Itineraries)
+	assert.Equal(t, int16(0), *svc.opts.Status)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/clone", `{"code":"SPEC-20260419","depart_date":"19/04/2026"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrVoyageCloneSourceNotFound
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/404/clone", `{"code":"X","depart_date":"2026-04-19"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	svc.err = service.ErrVoyageCloneConflict
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/clone", `{"code":"X","depart_date":"2026-04-19"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	svc.err = service.ErrInvalidVoyageClone
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/clone", `{"code":"X","depart_date":"2026-04-19"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
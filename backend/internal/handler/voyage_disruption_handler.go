@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// VoyageDisruptionService 定义停航/变更处理器依赖的业务能力。
+type VoyageDisruptionService interface {
+	Open(ctx context.Context, voyageID int64, disruptionType, reason string, notify bool, staffID int64) (*domain.VoyageDisruption, error)
+	Get(ctx context.Context, id int64) (*domain.VoyageDisruption, error)
+	List(ctx context.Context, voyageID int64, status string, page, pageSize int) ([]domain.VoyageDisruption, int64, error)
+	ListBookings(ctx context.Context, filter domain.DisruptionBookingFilter, page, pageSize int) ([]domain.DisruptionBooking, int64, error)
+	ListMine(ctx context.Context, userID int64) ([]domain.DisruptionBooking, error)
+	Offer(ctx context.Context, disruptionID int64, req service.DisruptionOfferRequest) (int, error)
+	Resolve(ctx context.Context, disruptionID int64, req service.DisruptionResolveRequest, staffID int64) ([]service.DisruptionResolveResult, error)
+	Accept(ctx context.Context, userID, itemID int64, resolution string, voyageID int64) (*domain.DisruptionBooking, error)
+	Close(ctx context.Context, id int64) (*domain.VoyageDisruption, error)
+}
+
+// VoyageDisruptionHandler 提供航次取消/行程变更处理的后台端点与乘客自助选择端点。
+type VoyageDisruptionHandler struct {
+	svc VoyageDisruptionService
+}
+
+// NewVoyageDisruptionHandler 创建停航/变更处理器。
+func NewVoyageDisruptionHandler(svc VoyageDisruptionService) *VoyageDisruptionHandler {
+	return &VoyageDisruptionHandler{svc: svc}
+}
+
+// OpenDisruptionRequest 标记航次取消/变更的请求体。
+type OpenDisruptionRequest struct {
+	Type   string `json:"type" binding:"required"` // cancelled / modified（必填）
+	Reason string `json:"reason"`                  // 原因说明
+	Notify *bool  `json:"notify"`                  // 是否立即通知受影响乘客，缺省为 true
+}
+
+// DisruptionOfferBody 批量发出替代方案的请求体。
+type DisruptionOfferBody struct {
+	BookingIDs           []int64  `json:"booking_ids"`                // 订单 ID，空表示全部未处理订单
+	Options              []string `json:"options" binding:"required"` // rebook / refund / credit（必填）
+	AlternativeVoyageIDs []int64  `json:"alternative_voyage_ids"`     // 可改签航次
+	WaivePriceDifference bool     `json:"waive_price_difference"`     // 免收改签差价
+	CreditBonusPercent   int      `json:"credit_bonus_percent"`       // 抵用金额外补偿比例
+}
+
+// DisruptionResolveBody 后台批量处理受影响订单的请求体。
+type DisruptionResolveBody struct {
+	BookingIDs           []int64 `json:"booking_ids"`                   // 订单 ID，空表示全部未处理订单
+	Resolution           string  `json:"resolution" binding:"required"` // rebook / refund / credit（必填）
+	TargetVoyageID       int64   `json:"target_voyage_id"`              // 改签目标航次
+	TargetCabinSKUID     int64   `json:"target_cabin_sku_id"`           // 指定改签舱房
+	WaivePriceDifference bool    `json:"waive_price_difference"`        // 免收改签差价
+	CreditBonusPercent   int     `json:"credit_bonus_percent"`          // 抵用金额外补偿比例
+	Remark               string  `json:"remark"`                        // 处理备注
+}
+
+// DisruptionAcceptBody 乘客选择替代方案的请求体。
+type DisruptionAcceptBody struct {
+	Resolution string `json:"resolution" binding:"required"` // rebook / refund / credit（必填）
+	VoyageID   int64  `json:"voyage_id"`                     // 改签航次，选择 rebook 时必填
+}
+
+// Open 处理 POST /api/v1/admin/voyages/:id/disruptions，标记航次取消/变更并快照受影响订单。
+func (h *VoyageDisruptionHandler) Open(c *gin.Context) {
+	voyageID, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req OpenDisruptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	disruption, err := h.svc.Open(c.Request.Context(), voyageID, req.Type, req.Reason, boolOrTrue(req.Notify), parseOperatorID(c))
+	if err != nil {
+		respondDisruptionError(c, err)
+		return
+	}
+	response.Success(c, disruption)
+}
+
+// List 处理 GET /api/v1/admin/disruptions，支持 voyage_id 与 status 过滤。
+func (h *VoyageDisruptionHandler) List(c *gin.Context) {
+	items, total, err := h.svc.List(c.Request.Context(), queryInt64(c, "voyage_id", 0), c.Query("status"), queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// Get 处理 GET /api/v1/admin/disruptions/:id。
+func (h *VoyageDisruptionHandler) Get(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	disruption, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		respondDisruptionError(c, err)
+		return
+	}
+	response.Success(c, disruption)
+}
+
+// Bookings 处理 GET /api/v1/admin/disruptions/:id/bookings，返回受影响订单及处理结果。
+func (h *VoyageDisruptionHandler) Bookings(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	filter := domain.DisruptionBookingFilter{DisruptionID: id, Status: c.Query("status"), Resolution: c.Query("resolution")}
+	items, total, err := h.svc.ListBookings(c.Request.Context(), filter, queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// Offer 处理 POST /api/v1/admin/disruptions/:id/offers，批量发出替代方案并通知乘客。
+func (h *VoyageDisruptionHandler) Offer(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req DisruptionOfferBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	offered, err := h.svc.Offer(c.Request.Context(), id, service.DisruptionOfferRequest{
+		BookingIDs:           req.BookingIDs,
+		Options:              req.Options,
+		AlternativeVoyageIDs: req.AlternativeVoyageIDs,
+		WaivePriceDifference: req.WaivePriceDifference,
+		CreditBonusPercent:   req.CreditBonusPercent,
+	})
+	if err != nil {
+		respondDisruptionError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"offered": offered})
+}
+
+// Resolve 处理 POST /api/v1/admin/disruptions/:id/resolve，批量改签/全额退款/转抵用金并逐单返回结果。
+func (h *VoyageDisruptionHandler) Resolve(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req DisruptionResolveBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	results, err := h.svc.Resolve(c.Request.Context(), id, service.DisruptionResolveRequest{
+		BookingIDs:           req.BookingIDs,
+		Resolution:           req.Resolution,
+		TargetVoyageID:       req.TargetVoyageID,
+		TargetCabinSKUID:     req.TargetCabinSKUID,
+		WaivePriceDifference: req.WaivePriceDifference,
+		CreditBonusPercent:   req.CreditBonusPercent,
+		Remark:               req.Remark,
+	}, parseOperatorID(c))
+	if err != nil {
+		respondDisruptionError(c, err)
+		return
+	}
+	resolved := 0
+	for _, result := range results {
+		if result.Error == "" {
+			resolved++
+		}
+	}
+	response.Success(c, gin.H{"resolved": resolved, "failed": len(results) - resolved, "results": results})
+}
+
+// Close 处理 POST /api/v1/admin/disruptions/:id/close，全部订单处理完毕后结案。
+func (h *VoyageDisruptionHandler) Close(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	disruption, err := h.svc.Close(c.Request.Context(), id)
+	if err != nil {
+		respondDisruptionError(c, err)
+		return
+	}
+	response.Success(c, disruption)
+}
+
+// Mine 处理 GET /api/v1/disruptions，返回当前用户收到的替代方案与处理结果。
+func (h *VoyageDisruptionHandler) Mine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	items, err := h.svc.ListMine(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": len(items)})
+}
+
+// Accept 处理 POST /api/v1/disruptions/:id/accept，乘客在替代方案中自助选择改签、退款或抵用金。
+func (h *VoyageDisruptionHandler) Accept(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	var req DisruptionAcceptBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	item, err := h.svc.Accept(c.Request.Context(), userID, id, req.Resolution, req.VoyageID)
+	if err != nil {
+		respondDisruptionError(c, err)
+		return
+	}
+	response.Success(c, item)
+}
+
+func respondDisruptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDisruption):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrDisruptionNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrDisruptionConflict):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVoyageDisruptionSvc struct {
+	err        error
+	notify     bool
+	staffID    int64
+	filter     domain.DisruptionBookingFilter
+	offer      service.DisruptionOfferRequest
+	resolve    service.DisruptionResolveRequest
+	acceptUser int64
+	acceptArgs []any
+}
+
+func (f *fakeVoyageDisruptionSvc) Open(_ context.Context, voyageID int64, disruptionType, reason string, notify bool, staffID int64) (*domain.VoyageDisruption, error) {
+	f.notify, f.staffID = notify, staffID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.VoyageDisruption{ID: 3, VoyageID: voyageID, Type: disruptionType, Reason: reason, Status: domain.VoyageDisruptionStatusOpen, AffectedCount: 2}, nil
+}
+
+func (f *fakeVoyageDisruptionSvc) Get(_ context.Context, id int64) (*domain.VoyageDisruption, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.VoyageDisruption{ID: id}, nil
+}
+
+func (f *fakeVoyageDisruptionSvc) List(context.Context, int64, string, int, int) ([]domain.VoyageDisruption, int64, error) {
+	return []domain.VoyageDisruption{{ID: 3}}, 1, f.err
+}
+
+func (f *fakeVoyageDisruptionSvc) ListBookings(_ context.Context, filter domain.DisruptionBookingFilter, _, _ int) ([]domain.DisruptionBooking, int64, error) {
+	f.filter = filter
+	return []domain.DisruptionBooking{{ID: 31, BookingID: 501}}, 1, f.err
+}
+
+func (f *fakeVoyageDisruptionSvc) ListMine(_ context.Context, userID int64) ([]domain.DisruptionBooking, error) {
+	return []domain.DisruptionBooking{{ID: 31, UserID: userID}}, f.err
+}
+
+func (f *fakeVoyageDisruptionSvc) Offer(_ context.Context, _ int64, req service.DisruptionOfferRequest) (int, error) {
+	f.offer = req
+	return 2, f.err
+}
+
+func (f *fakeVoyageDisruptionSvc) Resolve(_ context.Context, _ int64, req service.DisruptionResolveRequest, staffID int64) ([]service.DisruptionResolveResult, error) {
+	f.resolve, f.staffID = req, staffID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []service.DisruptionResolveResult{
+		{BookingID: 501, Item: &domain.DisruptionBooking{ID: 31, Status: domain.DisruptionBookingResolved}},
+		{BookingID: 502, Error: "no cabin available on target voyage"},
+	}, nil
+}
+
+func (f *fakeVoyageDisruptionSvc) Accept(_ context.Context, userID, itemID int64, resolution string, voyageID int64) (*domain.DisruptionBooking, error) {
+	f.acceptUser, f.acceptArgs = userID, []any{itemID, resolution, voyageID}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.DisruptionBooking{ID: itemID, Resolution: resolution}, nil
+}
+
+func (f *fakeVoyageDisruptionSvc) Close(_ context.Context, id int64) (*domain.VoyageDisruption, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.VoyageDisruption{ID: id, Status: domain.VoyageDisruptionStatusClosed}, nil
+}
+
+func newVoyageDisruptionTestRouter(svc *fakeVoyageDisruptionSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewVoyageDisruptionHandler(svc)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyStaffID, "9")
+		c.Set(middleware.ContextKeyUserID, "12")
+	})
+	r.POST("/admin/voyages/:id/disruptions", h.Open)
+	r.GET("/admin/disruptions", h.List)
+	r.GET("/admin/disruptions/:id", h.Get)
+	r.GET("/admin/disruptions/:id/bookings", h.Bookings)
+	r.POST("/admin/disruptions/:id/offers", h.Offer)
+	r.POST("/admin/disruptions/:id/resolve", h.Resolve)
+	r.POST("/admin/disruptions/:id/close", h.Close)
+	r.GET("/disruptions", h.Mine)
+	r.POST("/disruptions/:id/accept", h.Accept)
+	return r
+}
+
+func TestVoyageDisruptionHandler_AdminFlow(t *testing.T) {
+	svc := &fakeVoyageDisruptionSvc{}
+	r := newVoyageDisruptionTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/disruptions", `{"type":"cancelled","reason":"台风"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"affected_count":2`)
+	assert.True(t, svc.notify)
+	assert.Equal(t, int64(9), svc.staffID)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/disruptions", `{"type":"modified","notify":false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, svc.notify)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyages/5/disruptions", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/disruptions/3/bookings?status=offered&resolution=rebook", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, domain.DisruptionBookingFilter{DisruptionID: 3, Status: "offered", Resolution: "rebook"}, svc.filter)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/disruptions/3/offers", `{"options":["rebook","refund"],"alternative_voyage_ids":[6],"credit_bonus_percent":10}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"offered":2`)
+	assert.Equal(t, []int64{6}, svc.offer.AlternativeVoyageIDs)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/disruptions/3/resolve", `{"booking_ids":[501,502],"resolution":"rebook","target_voyage_id":6}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"resolved":1`)
+	assert.Contains(t, w.Body.String(), `"failed":1`)
+	assert.Equal(t, int64(6), svc.resolve.TargetVoyageID)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/disruptions/3/close", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"closed"`)
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/disruptions?voyage_id=5", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestVoyageDisruptionHandler_PassengerAccept(t *testing.T) {
+	svc := &fakeVoyageDisruptionSvc{}
+	r := newVoyageDisruptionTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/disruptions", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"user_id":12`)
+
+	w = doAgencyRequest(r, http.MethodPost, "/disruptions/32/accept", `{"resolution":"rebook","voyage_id":6}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(12), svc.acceptUser)
+	assert.Equal(t, []any{int64(32), "rebook", int64(6)}, svc.acceptArgs)
+
+	w = doAgencyRequest(r, http.MethodPost, "/disruptions/32/accept", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVoyageDisruptionHandler_ErrorMapping(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{service.ErrInvalidDisruption, http.StatusBadRequest},
+		{service.ErrDisruptionNotFound, http.StatusNotFound},
+		{service.ErrDisruptionConflict, http.StatusConflict},
+		{errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		r := newVoyageDisruptionTestRouter(&fakeVoyageDisruptionSvc{err: tc.err})
+		w := doAgencyRequest(r, http.MethodPost, "/admin/disruptions/3/resolve", `{"resolution":"refund"}`)
+		assert.Equal(t, tc.code, w.Code, tc.err.Error())
+		w = doAgencyRequest(r, http.MethodPost, "/disruptions/32/accept", `{"resolution":"refund"}`)
+		assert.Equal(t, tc.code, w.Code, tc.err.Error())
+	}
+}
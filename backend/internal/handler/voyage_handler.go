@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
@@ -118,7 +120,7 @@ func (h *VoyageHandler) Create(c *gin.Context) {
 		return
 	}
 	if err := h.svc.Create(c.Request.Context(), v); err != nil {
-		response.InternalError(c, err)
+		respondVoyageSaveError(c, err)
 		return
 	}
 	response.Success(c, v)
@@ -143,7 +145,7 @@ func (h *VoyageHandler) Update(c *gin.Context) {
 	}
 	v.ID = id
 	if err := h.svc.Update(c.Request.Context(), v); err != nil {
-		response.InternalError(c, err)
+		respondVoyageSaveError(c, err)
 		return
 	}
 	response.Success(c, v)
@@ -163,6 +165,16 @@ func (h *VoyageHandler) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// respondVoyageSaveError 将行程时间表校验失败映射为 400 并返回全部问题，其余错误按 500 处理。
+func respondVoyageSaveError(c *gin.Context, err error) {
+	var scheduleErr *service.ItineraryScheduleError
+	if errors.As(err, &scheduleErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "issues": scheduleErr.Issues})
+		return
+	}
+	response.InternalError(c, err)
+}
+
 func buildVoyageFromPayload(req voyageUpsertPayload) (*domain.Voyage, error) {
 	if req.DepartDate.After(req.ReturnDate) {
 		return nil, fmt.Errorf("depart_date must be before or equal to return_date")
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// VoyageSeriesService 定义航次系列处理器依赖的业务能力。
+type VoyageSeriesService interface {
+	Preview(ctx context.Context, series *domain.VoyageSeries) (*service.VoyageSeriesPreview, error)
+	Create(ctx context.Context, series *domain.VoyageSeries, staffID int64) (*domain.VoyageSeries, error)
+	Get(ctx context.Context, id int64) (*domain.VoyageSeries, error)
+	List(ctx context.Context, page, pageSize int) ([]domain.VoyageSeries, int64, error)
+}
+
+// VoyageSeriesHandler 提供后台按模板航次批量生成航次的预览与创建端点。
+type VoyageSeriesHandler struct {
+	svc VoyageSeriesService
+}
+
+// NewVoyageSeriesHandler 创建航次系列处理器。
+func NewVoyageSeriesHandler(svc VoyageSeriesService) *VoyageSeriesHandler {
+	return &VoyageSeriesHandler{svc: svc}
+}
+
+// VoyageSeriesRequest 预览/创建航次系列的请求体。
+type VoyageSeriesRequest struct {
+	Name             string                          `json:"name" binding:"required"`               // 系列名称（必填）
+	TemplateVoyageID int64                           `json:"template_voyage_id" binding:"required"` // 模板航次 ID（必填）
+	CodePrefix       string                          `json:"code_prefix" binding:"required"`        // 航次编码前缀（必填）
+	Frequency        string                          `json:"frequency" binding:"required"`          // daily/weekly/monthly（必填）
+	RepeatInterval   int                             `json:"repeat_interval"`                       // 重复间隔，缺省为 1
+	Weekdays         []int                           `json:"weekdays"`                              // 每周出发的星期（0=周日），仅 weekly 使用
+	StartDate        string                          `json:"start_date" binding:"required"`         // 开始日期 YYYY-MM-DD（必填）
+	EndDate          string                          `json:"end_date" binding:"required"`           // 结束日期 YYYY-MM-DD（必填，含）
+	CabinPrices      []domain.VoyageSeriesCabinPrice `json:"cabin_prices"`                          // 初始舱型价格，缺省沿用模板航次当前价
+}
+
+func (r VoyageSeriesRequest) toSeries() (*domain.VoyageSeries, error) {
+	start, err := time.Parse("2006-01-02", strings.TrimSpace(r.StartDate))
+	if err != nil {
+		return nil, errors.New("start_date must be YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", strings.TrimSpace(r.EndDate))
+	if err != nil {
+		return nil, errors.New("end_date must be YYYY-MM-DD")
+	}
+	weekdays := make([]string, len(r.Weekdays))
+	for i, day := range r.Weekdays {
+		weekdays[i] = strconv.Itoa(day)
+	}
+	return &domain.VoyageSeries{
+		Name:             r.Name,
+		TemplateVoyageID: r.TemplateVoyageID,
+		CodePrefix:       r.CodePrefix,
+		Frequency:        r.Frequency,
+		RepeatInterval:   r.RepeatInterval,
+		Weekdays:         strings.Join(weekdays, ","),
+		StartDate:        start,
+		EndDate:          end,
+		CabinPrices:      r.CabinPrices,
+	}, nil
+}
+
+// Preview 处理 POST /api/v1/admin/voyage-series/preview，返回将生成的航次编码、日期与编码冲突。
+func (h *VoyageSeriesHandler) Preview(c *gin.Context) {
+	series, ok := bindVoyageSeries(c)
+	if !ok {
+		return
+	}
+	preview, err := h.svc.Preview(c.Request.Context(), series)
+	if err != nil {
+		respondVoyageSeriesError(c, err)
+		return
+	}
+	response.Success(c, preview)
+}
+
+// Create 处理 POST /api/v1/admin/voyage-series，在一个事务内批量创建航次、舱位与初始价格。
+func (h *VoyageSeriesHandler) Create(c *gin.Context) {
+	series, ok := bindVoyageSeries(c)
+	if !ok {
+		return
+	}
+	created, err := h.svc.Create(c.Request.Context(), series, parseOperatorID(c))
+	if err != nil {
+		respondVoyageSeriesError(c, err)
+		return
+	}
+	response.Success(c, created)
+}
+
+// List 处理 GET /api/v1/admin/voyage-series。
+func (h *VoyageSeriesHandler) List(c *gin.Context) {
+	items, total, err := h.svc.List(c.Request.Context(), queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// Get 处理 GET /api/v1/admin/voyage-series/:id，返回系列及其航次。
+func (h *VoyageSeriesHandler) Get(c *gin.Context) {
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	series, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		respondVoyageSeriesError(c, err)
+		return
+	}
+	response.Success(c, series)
+}
+
+func bindVoyageSeries(c *gin.Context) (*domain.VoyageSeries, bool) {
+	var req VoyageSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return nil, false
+	}
+	series, err := req.toSeries()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return nil, false
+	}
+	return series, true
+}
+
+func respondVoyageSeriesError(c *gin.Context, err error) {
+	var scheduleErr *service.ItineraryScheduleError
+	switch {
+	case errors.As(err, &scheduleErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "issues": scheduleErr.Issues})
+	case errors.Is(err, service.ErrInvalidVoyageSeries):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrVoyageSeriesNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrVoyageSeriesConflict):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
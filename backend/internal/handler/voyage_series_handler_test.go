@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVoyageSeriesSvc struct {
+	err       error
+	received  *domain.VoyageSeries
+	createdBy int64
+}
+
+func (f *fakeVoyageSeriesSvc) Preview(_ context.Context, series *domain.VoyageSeries) (*service.VoyageSeriesPreview, error) {
+	f.received = series
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &service.VoyageSeriesPreview{TemplateVoyageID: series.TemplateVoyageID, Occurrences: []service.VoyageSeriesOccurrence{{Code: "SPEC-20260503"}}}, nil
+}
+
+func (f *fakeVoyageSeriesSvc) Create(_ context.Context, series *domain.VoyageSeries, staffID int64) (*domain.VoyageSeries, error) {
+	f.received, f.createdBy = series, staffID
+	if f.err != nil {
+		return nil, f.err
+	}
+	series.ID = 11
+	return series, nil
+}
+
+func (f *fakeVoyageSeriesSvc) Get(_ context.Context, id int64) (*domain.VoyageSeries, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.VoyageSeries{ID: id, Voyages: []domain.Voyage{{ID: 1, Code: "SPEC-20260503"}}}, nil
+}
+
+func (f *fakeVoyageSeriesSvc) List(context.Context, int, int) ([]domain.VoyageSeries, int64, error) {
+	return []domain.VoyageSeries{{ID: 11}}, 1, nil
+}
+
+func newVoyageSeriesTestRouter(svc *fakeVoyageSeriesSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewVoyageSeriesHandler(svc)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set(middleware.ContextKeyStaffID, "9") })
+	r.POST("/admin/voyage-series/preview", h.Preview)
+	r.POST("/admin/voyage-series", h.Create)
+	r.GET("/admin/voyage-series", h.List)
+	r.GET("/admin/voyage-series/:id", h.Get)
+	return r
+}
+
+const voyageSeriesBody = `{"name":"五月周日航次","template_voyage_id":5,"code_prefix":"SPEC-","frequency":"weekly","weekdays":[0,3],"start_date":"2026-05-01","end_date":"2026-05-31","cabin_prices":[{"cabin_type_id":31,"sale_price_cents":399900}]}`
+
+func TestVoyageSeriesHandler_PreviewAndCreate(t *testing.T) {
+	svc := &fakeVoyageSeriesSvc{}
+	r := newVoyageSeriesTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/admin/voyage-series/preview", voyageSeriesBody)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, svc.received)
+	assert.Equal(t, "0,3", svc.received.Weekdays)
+	assert.Equal(t, "2026-05-31", svc.received.EndDate.Format("2006-01-02"))
+	assert.Contains(t, w.Body.String(), "SPEC-20260503")
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyage-series", voyageSeriesBody)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(9), svc.createdBy)
+	assert.EqualValues(t, 399900, svc.received.CabinPrices[0].SalePriceCents)
+
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyage-series/preview", `{"name":"x","template_voyage_id":5,"code_prefix":"A","frequency":"daily","start_date":"2026/05/01","end_date":"2026-05-02"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrVoyageSeriesConflict
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyage-series", voyageSeriesBody)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	svc.err = &service.ItineraryScheduleError{Issues: []domain.ItineraryIssue{{Code: service.ItineraryIssueStopOverlap, Message: "overlap"}}}
+	w = doAgencyRequest(r, http.MethodPost, "/admin/voyage-series/preview", voyageSeriesBody)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"issues"`)
+}
+
+func TestVoyageSeriesHandler_GetAndList(t *testing.T) {
+	svc := &fakeVoyageSeriesSvc{}
+	r := newVoyageSeriesTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/admin/voyage-series", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total":1`)
+
+	w = doAgencyRequest(r, http.MethodGet, "/admin/voyage-series/11", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "SPEC-20260503")
+
+	svc.err = service.ErrVoyageSeriesNotFound
+	w = doAgencyRequest(r, http.MethodGet, "/admin/voyage-series/12", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
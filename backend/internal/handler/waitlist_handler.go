@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// WaitlistService 定义候补处理器依赖的业务能力。
+type WaitlistService interface {
+	Join(ctx context.Context, userID, voyageID, cabinTypeID int64, guests int) (*domain.WaitlistEntry, error)
+	ListMine(ctx context.Context, userID int64) ([]domain.WaitlistEntry, error)
+	Cancel(ctx context.Context, userID, id int64) error
+	List(ctx context.Context, filter domain.WaitlistFilter, page, pageSize int) ([]domain.WaitlistEntry, int64, error)
+}
+
+// WaitlistHandler 提供 C 端候补登记、查询、取消与后台候补查询端点。
+type WaitlistHandler struct {
+	svc WaitlistService
+}
+
+// NewWaitlistHandler 创建候补处理器。
+func NewWaitlistHandler(svc WaitlistService) *WaitlistHandler {
+	return &WaitlistHandler{svc: svc}
+}
+
+type joinWaitlistPayload struct {
+	VoyageID    int64 `json:"voyage_id" binding:"required,gt=0"`
+	CabinTypeID int64 `json:"cabin_type_id" binding:"required,gt=0"`
+	Guests      int   `json:"guests" binding:"required,gt=0"`
+}
+
+// Join 处理 POST /api/v1/waitlist，登记售罄航次舱型候补。
+func (h *WaitlistHandler) Join(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req joinWaitlistPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+		return
+	}
+	entry, err := h.svc.Join(c.Request.Context(), userID, req.VoyageID, req.CabinTypeID, req.Guests)
+	if err != nil {
+		respondWaitlistError(c, err)
+		return
+	}
+	response.Success(c, entry)
+}
+
+// Mine 处理 GET /api/v1/waitlist，返回当前用户的候补记录。
+func (h *WaitlistHandler) Mine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	items, err := h.svc.ListMine(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": len(items)})
+}
+
+// Cancel 处理 DELETE /api/v1/waitlist/:id，取消当前用户的候补。
+func (h *WaitlistHandler) Cancel(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parsePositiveID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.Cancel(c.Request.Context(), userID, id); err != nil {
+		respondWaitlistError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"id": id, "status": domain.WaitlistStatusCancelled})
+}
+
+// AdminList 处理 GET /api/v1/admin/waitlist，可按 voyage_id、cabin_type_id、user_id、status 过滤。
+func (h *WaitlistHandler) AdminList(c *gin.Context) {
+	filter := domain.WaitlistFilter{
+		VoyageID:    queryInt64(c, "voyage_id", 0),
+		CabinTypeID: queryInt64(c, "cabin_type_id", 0),
+		UserID:      queryInt64(c, "user_id", 0),
+		Status:      c.Query("status"),
+	}
+	items, total, err := h.svc.List(c.Request.Context(), filter, queryInt(c, "page", 1), queryInt(c, "page_size", 20))
+	if err != nil {
+		response.InternalError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"list": items, "total": total})
+}
+
+// currentUserID 读取 C 端鉴权中间件写入的用户 ID，缺失或非法时直接返回 401。
+func currentUserID(c *gin.Context) (int64, bool) {
+	value, exists := c.Get(middleware.ContextKeyUserID)
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "not authenticated")
+		return 0, false
+	}
+	id, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusUnauthorized, errcode.ErrUnauthorized, "invalid user identity")
+		return 0, false
+	}
+	return id, true
+}
+
+func respondWaitlistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWaitlistRequest):
+		response.Error(c, http.StatusBadRequest, errcode.ErrValidation, err.Error())
+	case errors.Is(err, service.ErrWaitlistNotFound):
+		response.Error(c, http.StatusNotFound, errcode.ErrNotFound, err.Error())
+	case errors.Is(err, service.ErrWaitlistEntryActive),
+		errors.Is(err, service.ErrWaitlistEntryClosed):
+		response.Error(c, http.StatusConflict, errcode.ErrConflict, err.Error())
+	default:
+		response.InternalError(c, err)
+	}
+}
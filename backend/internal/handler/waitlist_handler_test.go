@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWaitlistSvc struct {
+	err        error
+	lastUserID int64
+	lastFilter domain.WaitlistFilter
+}
+
+func (f *fakeWaitlistSvc) Join(_ context.Context, userID, voyageID, cabinTypeID int64, guests int) (*domain.WaitlistEntry, error) {
+	f.lastUserID = userID
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &domain.WaitlistEntry{ID: 5, UserID: userID, VoyageID: voyageID, CabinTypeID: cabinTypeID, Guests: guests, Status: domain.WaitlistStatusWaiting}, nil
+}
+
+func (f *fakeWaitlistSvc) ListMine(_ context.Context, userID int64) ([]domain.WaitlistEntry, error) {
+	return []domain.WaitlistEntry{{ID: 5, UserID: userID}}, nil
+}
+
+func (f *fakeWaitlistSvc) Cancel(_ context.Context, userID, _ int64) error {
+	f.lastUserID = userID
+	return f.err
+}
+
+func (f *fakeWaitlistSvc) List(_ context.Context, filter domain.WaitlistFilter, _, _ int) ([]domain.WaitlistEntry, int64, error) {
+	f.lastFilter = filter
+	return nil, 0, nil
+}
+
+func newWaitlistTestRouter(svc *fakeWaitlistSvc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewWaitlistHandler(svc)
+	r := gin.New()
+	r.GET("/admin/waitlist", h.AdminList)
+	r.POST("/anonymous/waitlist", h.Join)
+	user := r.Group("")
+	user.Use(func(c *gin.Context) {
+		c.Set(middleware.ContextKeyUserID, "7")
+		c.Next()
+	})
+	user.POST("/waitlist", h.Join)
+	user.GET("/waitlist", h.Mine)
+	user.DELETE("/waitlist/:id", h.Cancel)
+	return r
+}
+
+func TestWaitlistHandler_Join(t *testing.T) {
+	svc := &fakeWaitlistSvc{}
+	r := newWaitlistTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodPost, "/waitlist", `{"voyage_id":10,"cabin_type_id":100,"guests":2}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(7), svc.lastUserID, "用户 ID 取自鉴权上下文")
+	assert.Contains(t, w.Body.String(), `"status":"waiting"`)
+
+	w = doAgencyRequest(r, http.MethodPost, "/waitlist", `{"voyage_id":10}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doAgencyRequest(r, http.MethodPost, "/anonymous/waitlist", `{"voyage_id":10,"cabin_type_id":100,"guests":2}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	svc.err = service.ErrWaitlistEntryActive
+	w = doAgencyRequest(r, http.MethodPost, "/waitlist", `{"voyage_id":10,"cabin_type_id":100,"guests":2}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	svc.err = service.ErrInvalidWaitlistRequest
+	w = doAgencyRequest(r, http.MethodPost, "/waitlist", `{"voyage_id":10,"cabin_type_id":100,"guests":20}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestWaitlistHandler_MineAndCancel(t *testing.T) {
+	svc := &fakeWaitlistSvc{}
+	r := newWaitlistTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/waitlist", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"user_id":7`)
+
+	w = doAgencyRequest(r, http.MethodDelete, "/waitlist/5", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doAgencyRequest(r, http.MethodDelete, "/waitlist/abc", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	svc.err = service.ErrWaitlistNotFound
+	w = doAgencyRequest(r, http.MethodDelete, "/waitlist/6", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	svc.err = service.ErrWaitlistEntryClosed
+	w = doAgencyRequest(r, http.MethodDelete, "/waitlist/5", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestWaitlistHandler_AdminListFilters(t *testing.T) {
+	svc := &fakeWaitlistSvc{}
+	r := newWaitlistTestRouter(svc)
+
+	w := doAgencyRequest(r, http.MethodGet, "/admin/waitlist?voyage_id=10&cabin_type_id=100&status=offered", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, domain.WaitlistFilter{VoyageID: 10, CabinTypeID: 100, Status: domain.WaitlistStatusOffered}, svc.lastFilter)
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ContextKeyAgencyID 是 gin 上下文中存储已认证分销商 ID（int64）的键名。
+const ContextKeyAgencyID = "agencyID"
+
+// HeaderAgencyAPIKey 是分销商系统对接时携带 API Key 的请求头。
+const HeaderAgencyAPIKey = "X-API-Key"
+
+// AgencyRole 是分销端令牌中必须携带的角色。
+const AgencyRole = "agency"
+
+// AgencyKeyResolver 校验分销商 API Key 并返回分销商 ID。
+type AgencyKeyResolver interface {
+	ResolveAPIKey(ctx context.Context, key string) (int64, error)
+}
+
+// AgencyStatusChecker 确认分销商账户仍处于启用状态。
+type AgencyStatusChecker interface {
+	CheckAgencyActive(ctx context.Context, agencyID int64) error
+}
+
+// AgencyAuthConfig 包含分销端鉴权中间件的配置参数。
+type AgencyAuthConfig struct {
+	Secret   string              // 分销端 JWT 签名密钥，应与后台、C 端密钥不同
+	APIKeys  AgencyKeyResolver   // API Key 校验器，为 nil 时仅接受 JWT
+	Agencies AgencyStatusChecker // 每次请求复核令牌对应的分销商状态，为 nil 时不复核
+}
+
+// AgencyAuth 返回分销端鉴权中间件：优先校验 X-API-Key 请求头，否则校验角色为 agency 的 Bearer 令牌，
+// 通过后将分销商 ID 写入 ContextKeyAgencyID。令牌在有效期内不会失效，因此每次请求都复核分销商是否已被停用。
+func AgencyAuth(cfg AgencyAuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(HeaderAgencyAPIKey); key != "" {
+			if cfg.APIKeys == nil {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			agencyID, err := cfg.APIKeys.ResolveAPIKey(c.Request.Context(), key)
+			if err != nil || agencyID <= 0 {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			c.Set(ContextKeyAgencyID, agencyID)
+			c.Next()
+			return
+		}
+
+		auth := c.GetHeader("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		token, err := jwt.Parse(strings.TrimPrefix(auth, "Bearer "), func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return []byte(cfg.Secret), nil
+		})
+		if err != nil || !token.Valid {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		claims, _ := token.Claims.(jwt.MapClaims)
+		if !hasRole(claims, AgencyRole) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		sub, err := claims.GetSubject()
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		agencyID, err := strconv.ParseInt(sub, 10, 64)
+		if err != nil || agencyID <= 0 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if cfg.Agencies != nil {
+			if err := cfg.Agencies.CheckAgencyActive(c.Request.Context(), agencyID); err != nil {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		c.Set(ContextKeyAgencyID, agencyID)
+		c.Next()
+	}
+}
+
+// hasRole 判断 claims 中的 roles 是否包含指定角色。
+func hasRole(claims jwt.MapClaims, role string) bool {
+	roles, _ := claims["roles"].([]interface{})
+	for _, r := range roles {
+		if s, ok := r.(string); ok && s == role {
+			return true
+		}
+	}
+	return false
+}
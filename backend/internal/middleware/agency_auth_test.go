@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type fakeAgencyKeyResolver struct{}
+
+func (fakeAgencyKeyResolver) ResolveAPIKey(_ context.Context, key string) (int64, error) {
+	if key == "ak_good.secret" {
+		return 42, nil
+	}
+	return 0, errors.New("invalid key")
+}
+
+// fakeAgencyStatus 模拟分销商状态：分销商 8 已停用。
+type fakeAgencyStatus struct{}
+
+func (fakeAgencyStatus) CheckAgencyActive(_ context.Context, agencyID int64) error {
+	if agencyID == 8 {
+		return errors.New("agency disabled")
+	}
+	return nil
+}
+
+func signAgencyToken(t *testing.T, secret, sub string, roles []interface{}) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   sub,
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	s, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAgencyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := "agency-secret"
+
+	r := gin.New()
+	r.Use(AgencyAuth(AgencyAuthConfig{Secret: secret, APIKeys: fakeAgencyKeyResolver{}, Agencies: fakeAgencyStatus{}}))
+	var gotID interface{}
+	r.GET("/", func(c *gin.Context) {
+		gotID, _ = c.Get(ContextKeyAgencyID)
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+		id     int64
+	}{
+		{"No Credentials", "", "", http.StatusUnauthorized, 0},
+		{"Valid API Key", HeaderAgencyAPIKey, "ak_good.secret", http.StatusOK, 42},
+		{"Invalid API Key", HeaderAgencyAPIKey, "ak_bad.secret", http.StatusUnauthorized, 0},
+		{"Valid Token", "Authorization", "Bearer " + signAgencyToken(t, secret, "7", []interface{}{AgencyRole}), http.StatusOK, 7},
+		{"Disabled Agency Token", "Authorization", "Bearer " + signAgencyToken(t, secret, "8", []interface{}{AgencyRole}), http.StatusUnauthorized, 0},
+		{"Staff Role", "Authorization", "Bearer " + signAgencyToken(t, secret, "7", []interface{}{"admin"}), http.StatusUnauthorized, 0},
+		{"Other Secret", "Authorization", "Bearer " + signAgencyToken(t, "staff-secret", "7", []interface{}{AgencyRole}), http.StatusUnauthorized, 0},
+		{"Non Numeric Sub", "Authorization", "Bearer " + signAgencyToken(t, secret, "abc", []interface{}{AgencyRole}), http.StatusUnauthorized, 0},
+	}
+
+	for _, tt := range tests {
+		gotID = nil
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if tt.status == http.StatusOK && gotID != tt.id {
+			t.Errorf("%s: expected agency id %d, got %v", tt.name, tt.id, gotID)
+		}
+	}
+}
+
+func TestAgencyAuth_APIKeyWithoutResolver(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(AgencyAuth(AgencyAuthConfig{Secret: "s"}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderAgencyAPIKey, "ak_good.secret")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
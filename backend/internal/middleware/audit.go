@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// auditBodyLimit 是审计记录保留的请求体与用于解析新建 ID 的响应体的最大字节数。
+const auditBodyLimit = 64 << 10
+
+// AuditRecorder 读取资源快照并写入审计记录，由 service.OperationAuditService 实现。
+type AuditRecorder interface {
+	Snapshot(ctx context.Context, resource string, id int64) any
+	Record(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// Audit 返回后台写操作审计中间件：对 POST/PUT/PATCH/DELETE 请求，
+// 在处理前读取资源快照并保留请求体，处理后连同状态码、操作员、IP 与 User-Agent 交给 recorder 落库。
+// 资源类型取路由模板中 /admin/ 之后的第一段，资源 ID 取其后的第一个路径参数；
+// 创建类请求没有路径参数时，从响应 data.id 中解析新资源 ID。
+// 审计写入失败只记录日志，不影响业务响应。
+func Audit(recorder AuditRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		resource, operation, param := parseAuditRoute(c.Request.Method, route)
+		resourceID, _ := strconv.ParseInt(c.Param(param), 10, 64)
+		body := peekAuditBody(c.Request)
+		before := recorder.Snapshot(c.Request.Context(), resource, resourceID)
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		if resourceID == 0 {
+			c.Writer = writer
+		}
+		c.Next()
+
+		if resourceID == 0 && c.Writer.Status() < http.StatusBadRequest {
+			resourceID = parseCreatedID(writer.body.Bytes())
+		}
+		staffID, _ := strconv.ParseInt(c.GetString(ContextKeyStaffID), 10, 64)
+		entry := domain.AuditEntry{
+			StaffID:     staffID,
+			Method:      c.Request.Method,
+			Route:       route,
+			Operation:   operation,
+			Resource:    resource,
+			ResourceID:  resourceID,
+			StatusCode:  c.Writer.Status(),
+			ContentType: c.ContentType(),
+			RequestBody: body,
+			Before:      before,
+			IPAddress:   c.ClientIP(),
+			UserAgent:   c.Request.UserAgent(),
+		}
+		if err := recorder.Record(c.Request.Context(), entry); err != nil {
+			logger.FromContext(c.Request.Context()).Error("audit: record failed", zap.String("method", entry.Method), zap.String("route", entry.Route), zap.Error(err))
+		}
+	}
+}
+
+// parseAuditRoute 从路由模板解析资源类型、操作类型与资源 ID 参数名。
+// 操作类型由方法（create/update/delete）与资源后的固定路径段组成，例如
+// POST /cabins/:id/inventory/adjust -> inventory_adjust，PUT /cruises/batch-status -> update_batch_status。
+func parseAuditRoute(method, route string) (resource, operation, param string) {
+	rel := route
+	if idx := strings.Index(route, "/admin/"); idx >= 0 {
+		rel = route[idx+len("/admin/"):]
+	}
+	segments := strings.Split(strings.Trim(rel, "/"), "/")
+	resource = segments[0]
+
+	var actions []string
+	for _, segment := range segments[1:] {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			if param == "" {
+				param = segment[1:]
+			}
+		case segment != "":
+			actions = append(actions, strings.ReplaceAll(segment, "-", "_"))
+		}
+	}
+
+	verb := "update"
+	switch method {
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodDelete:
+		verb = "delete"
+	}
+	switch {
+	case len(actions) == 0:
+		operation = verb
+	case method == http.MethodPost:
+		// POST 子路径本身就是动作（如 clone、approve），无需再加 create 前缀
+		operation = strings.Join(actions, "_")
+	default:
+		operation = verb + "_" + strings.Join(actions, "_")
+	}
+	return resource, operation, param
+}
+
+// peekAuditBody 读取至多 auditBodyLimit 字节的请求体，并把完整请求体还原给后续处理器。
+func peekAuditBody(req *http.Request) []byte {
+	if req.Body == nil {
+		return nil
+	}
+	head, _ := io.ReadAll(io.LimitReader(req.Body, auditBodyLimit))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), req.Body), req.Body}
+	return head
+}
+
+// parseCreatedID 从统一响应 {"data":{"id":...}} 中解析新建资源 ID，兼容未加 json 标签的 ID 字段。
+func parseCreatedID(body []byte) int64 {
+	var resp struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return 0
+	}
+	for _, key := range []string{"id", "ID"} {
+		var id int64
+		if raw, ok := resp.Data[key]; ok && json.Unmarshal(raw, &id) == nil {
+			return id
+		}
+	}
+	return 0
+}
+
+// auditResponseWriter 在写出响应的同时保留前 auditBodyLimit 字节。
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	if remaining := auditBodyLimit - w.body.Len(); remaining > 0 {
+		w.body.Write(data[:min(len(data), remaining)])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeAuditRecorder struct {
+	snapshots []int64
+	entries   []domain.AuditEntry
+}
+
+func (f *fakeAuditRecorder) Snapshot(_ context.Context, _ string, id int64) any {
+	f.snapshots = append(f.snapshots, id)
+	if id == 0 {
+		return nil
+	}
+	return map[string]any{"id": id}
+}
+
+func (f *fakeAuditRecorder) Record(_ context.Context, entry domain.AuditEntry) error {
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func TestAuditRecordsAdminMutations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := &fakeAuditRecorder{}
+	r := gin.New()
+	admin := r.Group("/api/v1/admin", func(c *gin.Context) { c.Set(ContextKeyStaffID, "7") }, Audit(recorder))
+	var handlerBody string
+	admin.POST("/cruises", func(c *gin.Context) {
+		data, _ := io.ReadAll(c.Request.Body)
+		handlerBody = string(data)
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": gin.H{"id": 55}})
+	})
+	admin.POST("/cabins/:id/inventory/adjust", func(c *gin.Context) { c.Status(http.StatusOK) })
+	admin.PUT("/cruises/batch-status", func(c *gin.Context) { c.Status(http.StatusOK) })
+	admin.GET("/cruises", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "audit-test")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	do(http.MethodPost, "/api/v1/admin/cruises", `{"name":"A"}`)
+	do(http.MethodPost, "/api/v1/admin/cabins/12/inventory/adjust", `{"delta":-1}`)
+	do(http.MethodPut, "/api/v1/admin/cruises/batch-status", `{"ids":[1,2]}`)
+	do(http.MethodGet, "/api/v1/admin/cruises", "")
+
+	if handlerBody != `{"name":"A"}` {
+		t.Fatalf("handler must still see the full body, got %q", handlerBody)
+	}
+	if len(recorder.entries) != 3 {
+		t.Fatalf("expected 3 audited mutations (GET skipped), got %d", len(recorder.entries))
+	}
+	created := recorder.entries[0]
+	if created.StaffID != 7 || created.Resource != "cruises" || created.Operation != "create" || created.ResourceID != 55 ||
+		string(created.RequestBody) != `{"name":"A"}` || created.UserAgent != "audit-test" || created.Route != "/api/v1/admin/cruises" {
+		t.Fatalf("unexpected create entry: %+v", created)
+	}
+	adjusted := recorder.entries[1]
+	if adjusted.Resource != "cabins" || adjusted.ResourceID != 12 || adjusted.Operation != "inventory_adjust" || adjusted.Before == nil {
+		t.Fatalf("unexpected sub-action entry: %+v", adjusted)
+	}
+	if op := recorder.entries[2].Operation; op != "update_batch_status" {
+		t.Fatalf("unexpected batch operation %q", op)
+	}
+}
+
+func TestParseAuditRoute(t *testing.T) {
+	cases := []struct {
+		method, route, resource, operation, param string
+	}{
+		{http.MethodDelete, "/api/v1/admin/agencies/:id/api-keys/:keyId", "agencies", "delete_api_keys", "id"},
+		{http.MethodPut, "/api/v1/admin/voyages/:id", "voyages", "update", "id"},
+		{http.MethodPost, "/api/v1/admin/dynamic-pricing/proposals/:id/approve", "dynamic-pricing", "proposals_approve", "id"},
+	}
+	for _, tc := range cases {
+		resource, operation, param := parseAuditRoute(tc.method, tc.route)
+		if resource != tc.resource || operation != tc.operation || param != tc.param {
+			t.Fatalf("%s %s: got %s %s %s", tc.method, tc.route, resource, operation, param)
+		}
+	}
+}
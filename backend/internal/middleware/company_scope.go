@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyCompanyIDs 是 gin 上下文中存储员工可访问公司 ID（[]int64）的键名，未设置表示平台管理员不受限制。
+const ContextKeyCompanyIDs = "companyIDs"
+
+// CompanyScopeResolver 查询员工的公司数据范围。
+type CompanyScopeResolver interface {
+	GetScope(ctx context.Context, staffID int64) (domain.StaffCompanyScope, error)
+}
+
+// CompanyScope 返回员工公司数据范围中间件，需在 JWT 中间件之后使用：
+// 除标记为可访问全部公司的平台管理员外，把员工被授权的公司 ID 写入 ContextKeyCompanyIDs，
+// 并通过 domain.WithCompanyScope 注入请求上下文，由仓储层在查询与写入时强制过滤；没有任何授权的员工看不到任何公司的数据。
+// 查询失败时拒绝请求，避免在范围未知时返回全部数据。
+func CompanyScope(resolver CompanyScopeResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		staffID, err := strconv.ParseInt(c.GetString(ContextKeyStaffID), 10, 64)
+		if err != nil || staffID <= 0 {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		scope, err := resolver.GetScope(c.Request.Context(), staffID)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if !scope.AllCompanies {
+			companyIDs := append([]int64{}, scope.CompanyIDs...)
+			c.Set(ContextKeyCompanyIDs, companyIDs)
+			c.Request = c.Request.WithContext(domain.WithCompanyScope(c.Request.Context(), companyIDs))
+		}
+		c.Next()
+	}
+}
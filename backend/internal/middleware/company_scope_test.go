@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeCompanyScopeResolver map[int64]domain.StaffCompanyScope
+
+func (f fakeCompanyScopeResolver) GetScope(_ context.Context, staffID int64) (domain.StaffCompanyScope, error) {
+	if staffID == 500 {
+		return domain.StaffCompanyScope{}, errors.New("db down")
+	}
+	return f[staffID], nil
+}
+
+func TestCompanyScopeInjectsScopeIntoRequestContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Staff-ID"); id != "" {
+			c.Set(ContextKeyStaffID, id)
+		}
+	}, CompanyScope(fakeCompanyScopeResolver{7: {CompanyIDs: []int64{1, 3}}, 9: {AllCompanies: true}}))
+	r.GET("/scope", func(c *gin.Context) {
+		ids, scoped := domain.CompanyScopeFromContext(c.Request.Context())
+		if _, set := c.Get(ContextKeyCompanyIDs); set != scoped {
+			c.Status(http.StatusTeapot)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"scoped": scoped, "ids": ids})
+	})
+
+	do := func(staffID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/scope", nil)
+		if staffID != "" {
+			req.Header.Set("X-Staff-ID", staffID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("7"); w.Code != http.StatusOK || w.Body.String() != `{"ids":[1,3],"scoped":true}` {
+		t.Fatalf("scoped staff: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("8"); w.Code != http.StatusOK || w.Body.String() != `{"ids":[],"scoped":true}` {
+		t.Fatalf("staff without assignments must be scoped to nothing: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("9"); w.Code != http.StatusOK || w.Body.String() != `{"ids":null,"scoped":false}` {
+		t.Fatalf("platform admins are unrestricted: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("500"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("resolver failures must fail closed, got %d", w.Code)
+	}
+	if w := do(""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("missing staff id must be rejected, got %d", w.Code)
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 幂等请求头。
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = time.Minute
+)
+
+// IdempotencyConfig 包含幂等中间件的配置参数。
+type IdempotencyConfig struct {
+	Store       domain.IdempotencyStore // 幂等记录存储，为 nil 时中间件直接放行
+	TTL         time.Duration           // 响应保留时长，默认 24 小时
+	LockTimeout time.Duration           // 处理中记录的占用时长，超时后（如实例崩溃）允许重试，默认 1 分钟
+}
+
+// Idempotency 返回 Idempotency-Key 幂等中间件，需挂在认证中间件之后：
+// 同一调用方在同一路由上重复使用某个键时，若请求体一致则直接重放首次响应（带 Idempotent-Replayed: true），
+// 请求体不一致返回 422（errcode.ErrIdempotencyKeyReused），首次请求仍在处理中返回 409。
+// 5xx 响应不保存，客户端可以用同一个键重试；未携带请求头的请求不受影响。
+// 存储不可用时拒绝请求，避免在无法去重时重复下单。
+func Idempotency(cfg IdempotencyConfig) gin.HandlerFunc {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyTTL
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = defaultIdempotencyLockTTL
+	}
+	return func(c *gin.Context) {
+		clientKey := c.GetHeader(HeaderIdempotencyKey)
+		if cfg.Store == nil || clientKey == "" {
+			c.Next()
+			return
+		}
+		if len(clientKey) > idempotencyKeyMaxLength || strings.TrimSpace(clientKey) == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{Code: errcode.ErrValidation, Message: "invalid Idempotency-Key header"})
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.Response{Code: errcode.ErrBadRequest, Message: "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		identity := rateLimitIdentity(c)
+		if identity == "" {
+			identity = "ip:" + c.ClientIP()
+		}
+		route := c.Request.Method + " " + c.FullPath()
+		now := time.Now()
+		record := &domain.IdempotencyRecord{
+			Key:         hashIdempotencyParts(identity, route, clientKey),
+			Fingerprint: hashIdempotencyParts(route, string(body)),
+			ExpiresAt:   now.Add(cfg.LockTimeout),
+		}
+		existing, reserved, err := cfg.Store.Reserve(c.Request.Context(), record, now)
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Error("idempotency: reserve failed", zap.String("route", route), zap.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Response{Code: errcode.ErrInternal, Message: "internal server error"})
+			return
+		}
+		if !reserved {
+			switch {
+			case existing.Fingerprint != record.Fingerprint:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, response.Response{Code: errcode.ErrIdempotencyKeyReused, Message: "Idempotency-Key has already been used with a different request"})
+			case !existing.Completed():
+				c.AbortWithStatusJSON(http.StatusConflict, response.Response{Code: errcode.ErrConflict, Message: "a request with this Idempotency-Key is still being processed"})
+			default:
+				c.Header(HeaderIdempotentReplayed, "true")
+				c.Data(existing.StatusCode, existing.ContentType, []byte(existing.ResponseBody))
+				c.Abort()
+			}
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// 客户端断开时请求上下文已取消，仍需保存结果，否则重试会一直收到 409
+		ctx := context.WithoutCancel(c.Request.Context())
+		if status := writer.Status(); status >= http.StatusInternalServerError {
+			err = cfg.Store.Release(ctx, record.Key)
+		} else {
+			err = cfg.Store.Complete(ctx, record.Key, status, writer.Header().Get("Content-Type"), writer.body.String(), time.Now().Add(cfg.TTL))
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("idempotency: save result failed", zap.String("route", route), zap.Error(err))
+		}
+	}
+}
+
+// hashIdempotencyParts 对各部分做长度无歧义的拼接后取 SHA-256。
+func hashIdempotencyParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(part))))
+		_, _ = io.WriteString(h, part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyResponseWriter 在写出响应的同时保留完整响应体以便重放。
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func (f *fakeIdempotencyStore) Reserve(_ context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.Key]; ok && existing.ExpiresAt.After(now) {
+		return &existing, false, nil
+	}
+	f.records[record.Key] = *record
+	return nil, true, nil
+}
+
+func (f *fakeIdempotencyStore) Complete(_ context.Context, key string, statusCode int, contentType, body string, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	record := f.records[key]
+	record.StatusCode, record.ContentType, record.ResponseBody, record.ExpiresAt = statusCode, contentType, body, expiresAt
+	f.records[key] = record
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Release(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, key)
+	return nil
+}
+
+func (f *fakeIdempotencyStore) PurgeExpired(context.Context, time.Time) (int64, error) { return 0, nil }
+
+func TestIdempotency_ReplaysRetriesAndRejectsConflicts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &fakeIdempotencyStore{records: map[string]domain.IdempotencyRecord{}}
+	created := 0
+	failNext := false
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-User-ID"); id != "" {
+			c.Set(ContextKeyUserID, id)
+		}
+	}, Idempotency(IdempotencyConfig{Store: store}))
+	r.POST("/bookings", func(c *gin.Context) {
+		if failNext {
+			failNext = false
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 50000})
+			return
+		}
+		created++
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": gin.H{"id": created}})
+	})
+
+	do := func(userID, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", userID)
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := do("1", "tap-1", `{"sku_id":9}`)
+	if first.Code != http.StatusOK || first.Body.String() != `{"code":0,"data":{"id":1}}` {
+		t.Fatalf("first request: %d %s", first.Code, first.Body.String())
+	}
+	retry := do("1", "tap-1", `{"sku_id":9}`)
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() || retry.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatalf("retry must replay the original response: %d %s %v", retry.Code, retry.Body.String(), retry.Header())
+	}
+	if retry.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("replay must keep the content type, got %q", retry.Header().Get("Content-Type"))
+	}
+	if created != 1 {
+		t.Fatalf("handler must run once, ran %d times", created)
+	}
+
+	if w := do("1", "tap-1", `{"sku_id":10}`); w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"code":42211`) {
+		t.Fatalf("conflicting payload must be rejected: %d %s", w.Code, w.Body.String())
+	}
+	if w := do("2", "tap-1", `{"sku_id":9}`); w.Code != http.StatusOK || created != 2 {
+		t.Fatalf("keys are scoped per caller: %d, created %d", w.Code, created)
+	}
+	for i := 0; i < 2; i++ {
+		if w := do("1", "", `{"sku_id":9}`); w.Code != http.StatusOK {
+			t.Fatalf("requests without a key are unaffected, got %d", w.Code)
+		}
+	}
+	if created != 4 {
+		t.Fatalf("expected 4 creations, got %d", created)
+	}
+
+	failNext = true
+	if w := do("1", "tap-2", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected failure, got %d", w.Code)
+	}
+	if w := do("1", "tap-2", `{}`); w.Code != http.StatusOK || w.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Fatalf("5xx responses are not stored so the key can be retried: %d", w.Code)
+	}
+
+	if w := do("1", strings.Repeat("k", 256), `{}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("oversized keys must be rejected, got %d", w.Code)
+	}
+}
+
+func TestIdempotency_InFlightRequestReturnsConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &fakeIdempotencyStore{records: map[string]domain.IdempotencyRecord{}}
+	entered, release := make(chan struct{}), make(chan struct{})
+	r := gin.New()
+	r.Use(Idempotency(IdempotencyConfig{Store: store}))
+	r.POST("/refunds", func(c *gin.Context) {
+		close(entered)
+		<-release
+		c.JSON(http.StatusOK, gin.H{"status": "refunded"})
+	})
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/refunds", strings.NewReader(`{"payment_id":1}`))
+		req.Header.Set(HeaderIdempotencyKey, "refund-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- do() }()
+	<-entered
+	if w := do(); w.Code != http.StatusConflict {
+		t.Fatalf("concurrent retry must get 409, got %d", w.Code)
+	}
+	close(release)
+	if w := <-done; w.Code != http.StatusOK {
+		t.Fatalf("original request: %d", w.Code)
+	}
+	if w := do(); w.Code != http.StatusOK || w.Header().Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatalf("completed request must be replayed: %d", w.Code)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 返回 HTTP RED 指标中间件：按方法、路由模板与状态码统计请求数，按方法与路由模板统计耗时。
+// 未匹配任何路由的请求（如扫描探测）统一记为 "unmatched"，避免标签基数随路径增长。
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
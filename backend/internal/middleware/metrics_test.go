@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_CountsRequestsByRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Metrics())
+	r.GET("/voyages/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	ok := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/voyages/:id", "200")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "unmatched", "404")
+	beforeOK, beforeUnmatched := testutil.ToFloat64(ok), testutil.ToFloat64(unmatched)
+	for _, path := range []string{"/voyages/1", "/voyages/2", "/wp-admin"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(ok) - beforeOK; got != 2 {
+		t.Fatalf("expected 2 requests on route template, got %v", got)
+	}
+	if got := testutil.ToFloat64(unmatched) - beforeUnmatched; got != 1 {
+		t.Fatalf("expected 1 unmatched request, got %v", got)
+	}
+}
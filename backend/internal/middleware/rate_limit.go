@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/errcode"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/cruisebooking/backend/internal/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 限流维度。
+const (
+	RateLimitByIP   = "ip"   // 按客户端 IP 计数
+	RateLimitByUser = "user" // 按已认证身份（员工、C 端用户或分销商）计数
+)
+
+// contextKeyRateLimited 标记本次请求已完成限流判定，避免同一请求在认证前后被重复计数。
+const contextKeyRateLimited = "rateLimited"
+
+// RateLimitRule 是一条限流规则：Path 为路径前缀（按路径段匹配），Method 为空时匹配所有方法。
+// 同一请求只应用最具体的一条规则（路径最长，其次是指定了方法的规则），同一规则下的路由共享一个令牌桶。
+type RateLimitRule struct {
+	Name   string
+	Method string
+	Path   string
+	By     string
+	Limit  domain.RateLimit
+}
+
+// RateLimiter 按规则对请求做令牌桶限流。
+type RateLimiter struct {
+	store domain.RateLimitStore
+	rules []RateLimitRule
+	now   func() time.Time
+}
+
+// NewRateLimiter 创建限流器，未指定 By 的规则按 IP 计数。
+func NewRateLimiter(store domain.RateLimitStore, rules []RateLimitRule) *RateLimiter {
+	normalized := make([]RateLimitRule, 0, len(rules))
+	for _, rule := range rules {
+		rule.Method = strings.ToUpper(strings.TrimSpace(rule.Method))
+		rule.Path = "/" + strings.Trim(strings.TrimSpace(rule.Path), "/")
+		if rule.By != RateLimitByUser {
+			rule.By = RateLimitByIP
+		}
+		normalized = append(normalized, rule)
+	}
+	return &RateLimiter{store: store, rules: normalized, now: time.Now}
+}
+
+// RateLimit 返回限流中间件，limiter 为 nil 时直接放行。
+// 中间件需挂在全局（处理按 IP 的规则）以及各认证中间件之后（处理按用户的规则）：
+// 认证前遇到按用户的规则时不计数，由认证后的那一次调用完成判定，因此按用户的规则只对需要登录的路由生效。
+// 按 IP 的规则使用 c.ClientIP()，只采信引擎 SetTrustedProxies 配置的代理转发的 X-Forwarded-For。
+// 被限流时返回 429、errcode.ErrTooManyRequests 与 Retry-After；存储故障时记录日志并放行，限流不影响可用性。
+func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil || c.GetBool(contextKeyRateLimited) {
+			c.Next()
+			return
+		}
+		rule, ok := limiter.match(c.Request.Method, c.Request.URL.Path)
+		if !ok {
+			c.Set(contextKeyRateLimited, true)
+			c.Next()
+			return
+		}
+		identity := c.ClientIP()
+		if rule.By == RateLimitByUser {
+			if identity = rateLimitIdentity(c); identity == "" {
+				c.Next()
+				return
+			}
+		}
+		c.Set(contextKeyRateLimited, true)
+
+		decision, err := limiter.store.Take(c.Request.Context(), rule.Name+":"+rule.By+":"+identity, rule.Limit, limiter.now())
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Error("rate limit: rule failed", zap.String("rule", rule.Name), zap.Error(err))
+			c.Next()
+			return
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.ResetAfter)))
+		if !decision.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(1, ceilSeconds(decision.RetryAfter))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Response{Code: errcode.ErrTooManyRequests, Message: "too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// match 返回与请求最匹配的规则。
+func (l *RateLimiter) match(method, path string) (RateLimitRule, bool) {
+	var best RateLimitRule
+	found := false
+	for _, rule := range l.rules {
+		if rule.Method != "" && rule.Method != method {
+			continue
+		}
+		if rule.Path != "/" && path != rule.Path && !strings.HasPrefix(path, rule.Path+"/") {
+			continue
+		}
+		if !found || len(rule.Path) > len(best.Path) || (len(rule.Path) == len(best.Path) && best.Method == "" && rule.Method != "") {
+			best, found = rule, true
+		}
+	}
+	return best, found
+}
+
+// rateLimitIdentity 返回已认证的调用方身份，未认证时返回空字符串。
+func rateLimitIdentity(c *gin.Context) string {
+	if id := c.GetString(ContextKeyStaffID); id != "" {
+		return "staff:" + id
+	}
+	if id := c.GetString(ContextKeyUserID); id != "" {
+		return "user:" + id
+	}
+	if id, ok := c.Get(ContextKeyAgencyID); ok {
+		if agencyID, ok := id.(int64); ok {
+			return "agency:" + strconv.FormatInt(agencyID, 10)
+		}
+	}
+	return ""
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeRateLimitStore 为每个键放行前 Burst 次请求，并记录每个键的计数。
+type fakeRateLimitStore struct {
+	counts map[string]int
+	err    error
+}
+
+func (f *fakeRateLimitStore) Take(_ context.Context, key string, limit domain.RateLimit, _ time.Time) (domain.RateLimitDecision, error) {
+	if f.err != nil {
+		return domain.RateLimitDecision{}, f.err
+	}
+	f.counts[key]++
+	remaining := limit.Burst - f.counts[key]
+	if remaining < 0 {
+		return domain.RateLimitDecision{Limit: limit.Burst, RetryAfter: 1500 * time.Millisecond, ResetAfter: 3 * time.Second}, nil
+	}
+	return domain.RateLimitDecision{Allowed: true, Limit: limit.Burst, Remaining: remaining, ResetAfter: time.Second}, nil
+}
+
+func newRateLimitTestRouter(store *fakeRateLimitStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	limiter := NewRateLimiter(store, []RateLimitRule{
+		{Name: "api", Path: "/api/v1", Limit: domain.RateLimit{Requests: 100, Period: time.Second, Burst: 100}},
+		{Name: "sms", Method: "post", Path: "/api/v1/users/sms-code/", Limit: domain.RateLimit{Requests: 1, Period: time.Minute, Burst: 1}},
+		{Name: "admin", Path: "/api/v1/admin", By: RateLimitByUser, Limit: domain.RateLimit{Requests: 2, Period: time.Second, Burst: 2}},
+	})
+	rateLimit := RateLimit(limiter)
+	fakeAuth := func(c *gin.Context) {
+		if id := c.GetHeader("X-Staff-ID"); id != "" {
+			c.Set(ContextKeyStaffID, id)
+		}
+	}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	r := gin.New()
+	r.Use(rateLimit)
+	r.POST("/api/v1/users/sms-code", ok)
+	r.GET("/api/v1/users/sms-code-history", ok)
+	admin := r.Group("/api/v1/admin", fakeAuth, rateLimit)
+	admin.GET("/cruises", ok)
+	return r
+}
+
+func doRateLimitRequest(r *gin.Engine, method, path, ip, staffID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	if staffID != "" {
+		req.Header.Set("X-Staff-ID", staffID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_PerIPRouteOverride(t *testing.T) {
+	store := &fakeRateLimitStore{counts: map[string]int{}}
+	r := newRateLimitTestRouter(store)
+
+	w := doRateLimitRequest(r, http.MethodPost, "/api/v1/users/sms-code", "10.0.0.1", "")
+	if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "1" || w.Header().Get("X-RateLimit-Remaining") != "0" || w.Header().Get("X-RateLimit-Reset") != "1" {
+		t.Fatalf("first sms request: %d %v", w.Code, w.Header())
+	}
+	w = doRateLimitRequest(r, http.MethodPost, "/api/v1/users/sms-code", "10.0.0.1", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" {
+		t.Fatalf("second sms request must be throttled: %d %v", w.Code, w.Header())
+	}
+	if body := w.Body.String(); body != `{"code":40029,"message":"too many requests","data":null}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+	if w = doRateLimitRequest(r, http.MethodPost, "/api/v1/users/sms-code", "10.0.0.2", ""); w.Code != http.StatusOK {
+		t.Fatalf("other IPs have their own bucket, got %d", w.Code)
+	}
+
+	// 路径前缀按段匹配：sms-code-history 不属于 sms-code 规则，回落到 /api/v1 分组规则
+	if w = doRateLimitRequest(r, http.MethodGet, "/api/v1/users/sms-code-history", "10.0.0.1", ""); w.Code != http.StatusOK {
+		t.Fatalf("group rule must apply, got %d", w.Code)
+	}
+	if store.counts["api:ip:10.0.0.1"] != 1 || store.counts["sms:ip:10.0.0.1"] != 2 {
+		t.Fatalf("unexpected buckets %v", store.counts)
+	}
+}
+
+func TestRateLimit_PerUserCountsOnceAfterAuthentication(t *testing.T) {
+	store := &fakeRateLimitStore{counts: map[string]int{}}
+	r := newRateLimitTestRouter(store)
+
+	for i := 0; i < 2; i++ {
+		if w := doRateLimitRequest(r, http.MethodGet, "/api/v1/admin/cruises", "10.0.0.1", "7"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: %d", i, w.Code)
+		}
+	}
+	if w := doRateLimitRequest(r, http.MethodGet, "/api/v1/admin/cruises", "10.0.0.2", "7"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("per-user bucket is shared across IPs, got %d", w.Code)
+	}
+	if w := doRateLimitRequest(r, http.MethodGet, "/api/v1/admin/cruises", "10.0.0.1", "8"); w.Code != http.StatusOK {
+		t.Fatalf("other staff have their own bucket, got %d", w.Code)
+	}
+	if len(store.counts) != 2 || store.counts["admin:user:staff:7"] != 3 {
+		t.Fatalf("each request must be counted exactly once: %v", store.counts)
+	}
+}
+
+func TestRateLimit_FailsOpenAndNilLimiter(t *testing.T) {
+	store := &fakeRateLimitStore{counts: map[string]int{}, err: errors.New("redis down")}
+	r := newRateLimitTestRouter(store)
+	if w := doRateLimitRequest(r, http.MethodPost, "/api/v1/users/sms-code", "10.0.0.1", ""); w.Code != http.StatusOK {
+		t.Fatalf("store failures must not block traffic, got %d", w.Code)
+	}
+
+	gin.SetMode(gin.TestMode)
+	plain := gin.New()
+	plain.Use(RateLimit(nil))
+	plain.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	if w := doRateLimitRequest(plain, http.MethodGet, "/", "10.0.0.1", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("nil limiter must pass through, got %d", w.Code)
+	}
+}
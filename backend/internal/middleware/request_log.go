@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HeaderRequestID 是请求 ID 的请求/响应头。
+const HeaderRequestID = "X-Request-ID"
+
+// ContextKeyRequestID 是 gin 上下文中存储请求 ID 的键名。
+const ContextKeyRequestID = "requestID"
+
+// requestIDMaxLength 是沿用调用方请求 ID 的最大长度，超长或含非法字符时重新生成。
+const requestIDMaxLength = 128
+
+// RequestLogger 返回请求日志与链路追踪中间件，应作为第一个中间件挂载：
+// 沿用合法的 X-Request-ID（否则生成新 ID）并写回响应头；按 W3C traceparent 提取上游 trace 上下文并开启服务端 span；
+// 将携带 request_id、trace_id、span_id 的日志记录器写入请求 context（logger.FromContext 读取）；
+// 请求结束后记录方法、路由、状态码、耗时及已认证的员工/用户/分销商 ID。base 为 nil 时使用 zap.L()。
+func RequestLogger(base *zap.Logger) gin.HandlerFunc {
+	tracer := otel.Tracer("github.com/cruisebooking/backend/internal/middleware")
+	return func(c *gin.Context) {
+		start := time.Now()
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Set(ContextKeyRequestID, requestID)
+		c.Header(HeaderRequestID, requestID)
+
+		route := c.FullPath()
+		spanName := c.Request.Method + " " + route
+		if route == "" {
+			spanName = c.Request.Method
+		}
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("client.address", c.ClientIP()),
+				attribute.String("request.id", requestID),
+			))
+		defer span.End()
+
+		l := base
+		if l == nil {
+			l = zap.L()
+		}
+		fields := []zap.Field{zap.String("request_id", requestID)}
+		if sc := span.SpanContext(); sc.IsValid() {
+			fields = append(fields, zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String()))
+		}
+		l = l.With(fields...)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx, l))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+		logFields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Int("size", c.Writer.Size()),
+		}
+		if id := c.GetString(ContextKeyStaffID); id != "" {
+			logFields = append(logFields, zap.String("staff_id", id))
+		}
+		if id := c.GetString(ContextKeyUserID); id != "" {
+			logFields = append(logFields, zap.String("user_id", id))
+		}
+		if id, ok := c.Get(ContextKeyAgencyID); ok {
+			if agencyID, ok := id.(int64); ok {
+				logFields = append(logFields, zap.Int64("agency_id", agencyID))
+			}
+		}
+		if len(c.Errors) > 0 {
+			logFields = append(logFields, zap.String("errors", c.Errors.String()))
+		}
+		level := zapcore.InfoLevel
+		switch {
+		case status >= 500:
+			level = zapcore.ErrorLevel
+		case status >= 400:
+			level = zapcore.WarnLevel
+		}
+		l.Log(level, "http request", logFields...)
+	}
+}
+
+// validRequestID 判断调用方传入的请求 ID 是否可以沿用：非空、不超长且只含可见 ASCII 字符。
+func validRequestID(id string) bool {
+	if id == "" || len(id) > requestIDMaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
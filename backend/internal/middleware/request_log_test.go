@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/cruisebooking/backend/internal/pkg/tracing"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestLogger_PropagatesRequestIDAndTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if _, err := tracing.Setup(tracing.Config{ServiceName: "cruise-test", SampleRatio: 1}); err != nil {
+		t.Fatalf("tracing setup failed: %v", err)
+	}
+	core, logs := observer.New(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(RequestLogger(zap.New(core)))
+	r.GET("/bookings/:id", func(c *gin.Context) {
+		c.Set(ContextKeyUserID, "42")
+		logger.FromContext(c.Request.Context()).Info("loading booking")
+		c.Status(http.StatusNotFound)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/bookings/7", nil)
+	req.Header.Set(HeaderRequestID, "req-123")
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Header().Get(HeaderRequestID) != "req-123" {
+		t.Fatalf("expected request id to be echoed, got %q", w.Header().Get(HeaderRequestID))
+	}
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected handler log and access log, got %d entries", len(entries))
+	}
+	for _, entry := range entries {
+		fields := entry.ContextMap()
+		if fields["request_id"] != "req-123" || fields["trace_id"] != traceID {
+			t.Fatalf("expected request and trace ids on %q, got %v", entry.Message, fields)
+		}
+	}
+	access := entries[1]
+	fields := access.ContextMap()
+	if access.Level != zapcore.WarnLevel || fields["route"] != "/bookings/:id" || fields["status"] != int64(http.StatusNotFound) || fields["user_id"] != "42" {
+		t.Fatalf("unexpected access log: level=%s fields=%v", access.Level, fields)
+	}
+}
+
+func TestRequestLogger_GeneratesRequestIDForInvalidHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestLogger(zap.NewNop()))
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(HeaderRequestID, "bad id\n")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(HeaderRequestID); len(got) != 32 {
+		t.Fatalf("expected generated 32-char request id, got %q", got)
+	}
+}
@@ -0,0 +1,355 @@
+// Package csvimport 提供通用的 CSV 批量导入框架：按表头映射列、逐行校验并收集错误、
+// 支持试运行（dry-run）以及“全部成功才写入”与“尽力而为”两种提交模式，并可生成可下载的错误报告。
+package csvimport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ModeAllOrNothing 任一行校验失败则整批不写入，写入时由 Spec.Save 在单个事务内完成。
+	ModeAllOrNothing = "all_or_nothing"
+	// ModeBestEffort 跳过失败行，逐行写入其余数据。
+	ModeBestEffort = "best_effort"
+)
+
+// DefaultMaxRows 为单个文件默认允许的最大数据行数。
+const DefaultMaxRows = 10000
+
+var (
+	// ErrInvalidFile 表示文件无法按 CSV 解析、缺少必需列或超出行数上限。
+	ErrInvalidFile = errors.New("invalid import file")
+	// ErrInvalidMode 表示导入模式不受支持。
+	ErrInvalidMode = errors.New("invalid import mode")
+)
+
+// Options 控制单次导入的行为。
+type Options struct {
+	DryRun  bool   // 只校验不写入
+	Mode    string // all_or_nothing / best_effort，缺省为 all_or_nothing
+	MaxRows int    // 数据行上限，非正数时取 DefaultMaxRows
+}
+
+// Normalize 补齐缺省值并校验导入模式。
+func (o Options) Normalize() (Options, error) {
+	o.Mode = strings.ToLower(strings.TrimSpace(o.Mode))
+	if o.Mode == "" {
+		o.Mode = ModeAllOrNothing
+	}
+	if o.Mode != ModeAllOrNothing && o.Mode != ModeBestEffort {
+		return o, fmt.Errorf("%w: %q", ErrInvalidMode, o.Mode)
+	}
+	if o.MaxRows <= 0 {
+		o.MaxRows = DefaultMaxRows
+	}
+	return o, nil
+}
+
+// Row 表示按表头映射后的单行数据，Number 为该行在文件中的行号（表头为第 1 行）。
+type Row struct {
+	Number int
+	values map[string]string
+}
+
+// NewRow 按列名构造数据行，供调用方在测试或非 CSV 来源中复用解析逻辑。
+func NewRow(number int, values map[string]string) Row {
+	return Row{Number: number, values: values}
+}
+
+// Get 返回去除首尾空白后的单元格值；列不存在时返回空串。
+func (r Row) Get(column string) string {
+	return strings.TrimSpace(r.values[column])
+}
+
+// Has 判断文件是否包含该列。
+func (r Row) Has(column string) bool {
+	_, ok := r.values[column]
+	return ok
+}
+
+// RowError 描述单行的校验或写入错误。
+type RowError struct {
+	Row     int    `json:"row"`              // 文件行号
+	Column  string `json:"column,omitempty"` // 出错列，整行错误时为空
+	Message string `json:"message"`          // 错误说明
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d %s: %s", e.Row, e.Column, e.Message)
+}
+
+// ColumnError 构造指定列的校验错误，行号由 Run 填充。多个列错误可用 errors.Join 一并返回。
+func ColumnError(column, format string, args ...any) error {
+	return &RowError{Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+// Result 汇总一次导入的结果。
+type Result struct {
+	Mode      string     `json:"mode"`      // 实际使用的导入模式
+	DryRun    bool       `json:"dry_run"`   // 是否为试运行
+	Committed bool       `json:"committed"` // 是否已写入数据
+	Total     int        `json:"total"`     // 数据行数（不含空行）
+	Succeeded int        `json:"succeeded"` // 校验（及写入）成功的行数
+	Failed    int        `json:"failed"`    // 失败行数
+	Errors    []RowError `json:"errors"`    // 逐行错误
+
+	header []string
+	failed map[int][]string
+}
+
+// Spec 描述一类数据的导入规则。
+type Spec[T any] struct {
+	Required []string          // 必需列（规范列名）
+	Aliases  map[string]string // 表头别名 → 规范列名，匹配时忽略大小写
+	// Parse 把一行转换为待写入对象；返回的 *RowError（可经 errors.Join 组合）会按列记录。
+	Parse func(ctx context.Context, row Row) (T, error)
+	// Key 返回对象的业务唯一键，用于发现文件内的重复行；为空时不做去重。
+	Key func(item T) string
+	// Save 写入一批对象。all_or_nothing 模式下一次传入全部对象，须在单个事务内完成；
+	// best_effort 模式下逐行调用。
+	Save func(ctx context.Context, items []T) error
+}
+
+type pending[T any] struct {
+	row    int
+	record []string
+	item   T
+}
+
+// Run 读取 CSV 并按 spec 导入。文件级错误（无法解析、缺少必需列等）返回 ErrInvalidFile；
+// 行级错误收集在 Result.Errors 中。all_or_nothing 模式下 Save 失败时整批回滚并返回该错误。
+func Run[T any](ctx context.Context, reader io.Reader, spec Spec[T], opts Options) (*Result, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Mode: opts.Mode, DryRun: opts.DryRun, Errors: []RowError{}, failed: map[int][]string{}}
+
+	parser := csv.NewReader(reader)
+	parser.FieldsPerRecord = -1
+	header, err := parser.Read()
+	if errors.Is(err, io.EOF) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	result.header = header
+	columns, err := mapHeader(header, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []pending[T]{}
+	seen := map[string]int{}
+	for {
+		record, err := parser.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		if blankRecord(record) {
+			continue
+		}
+		line, _ := parser.FieldPos(0)
+		result.Total++
+		if result.Total > opts.MaxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidFile, opts.MaxRows)
+		}
+		row := Row{Number: line, values: make(map[string]string, len(columns))}
+		for idx, column := range columns {
+			if column == "" {
+				continue
+			}
+			if idx < len(record) {
+				row.values[column] = record[idx]
+			} else {
+				row.values[column] = ""
+			}
+		}
+		item, err := spec.Parse(ctx, row)
+		if err != nil {
+			result.addError(line, record, err)
+			continue
+		}
+		if spec.Key != nil {
+			key := spec.Key(item)
+			if first, ok := seen[key]; ok {
+				result.addError(line, record, fmt.Errorf("duplicate row, first seen on row %d", first))
+				continue
+			}
+			seen[key] = line
+		}
+		items = append(items, pending[T]{row: line, record: record, item: item})
+	}
+
+	if opts.Mode == ModeAllOrNothing {
+		result.Succeeded = len(items)
+		if opts.DryRun || result.Failed > 0 || len(items) == 0 {
+			return result, nil
+		}
+		batch := make([]T, len(items))
+		for i, p := range items {
+			batch[i] = p.item
+		}
+		if err := spec.Save(ctx, batch); err != nil {
+			return nil, err
+		}
+		result.Committed = true
+		return result, nil
+	}
+
+	for _, p := range items {
+		if opts.DryRun {
+			result.Succeeded++
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := spec.Save(ctx, []T{p.item}); err != nil {
+			result.addError(p.row, p.record, err)
+			continue
+		}
+		result.Succeeded++
+		result.Committed = true
+	}
+	return result, nil
+}
+
+// mapHeader 把表头映射为规范列名；无法识别的列映射为空串并被忽略。
+func mapHeader[T any](header []string, spec Spec[T]) ([]string, error) {
+	aliases := make(map[string]string, len(spec.Aliases))
+	for alias, column := range spec.Aliases {
+		aliases[normalizeHeader(alias)] = column
+	}
+	columns := make([]string, len(header))
+	present := map[string]bool{}
+	for idx, raw := range header {
+		name := normalizeHeader(raw)
+		if canonical, ok := aliases[name]; ok {
+			name = canonical
+		}
+		if name == "" {
+			continue
+		}
+		if present[name] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidFile, name)
+		}
+		present[name] = true
+		columns[idx] = name
+	}
+	missing := []string{}
+	for _, column := range spec.Required {
+		if !present[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: missing required columns %s", ErrInvalidFile, strings.Join(missing, ", "))
+	}
+	return columns, nil
+}
+
+func normalizeHeader(raw string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(raw, "\ufeff")))
+}
+
+func blankRecord(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// addError 记录一行的错误并保留原始数据；errors.Join 组合的多个错误逐条展开。
+func (r *Result) addError(line int, record []string, err error) {
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else {
+		errs = []error{err}
+	}
+	for _, e := range errs {
+		var rowErr *RowError
+		if errors.As(e, &rowErr) {
+			r.Errors = append(r.Errors, RowError{Row: line, Column: rowErr.Column, Message: rowErr.Message})
+			continue
+		}
+		r.Errors = append(r.Errors, RowError{Row: line, Message: e.Error()})
+	}
+	if _, ok := r.failed[line]; !ok {
+		r.Failed++
+		r.failed[line] = record
+	}
+}
+
+// ErrorReport 生成错误报告 CSV：前两列为行号与错误说明，其后为原始表头与失败行的原始数据，
+// 修正后去掉前两列即可重新导入。没有失败行时返回 nil。
+func (r *Result) ErrorReport() ([]byte, error) {
+	if r == nil || len(r.Errors) == 0 {
+		return nil, nil
+	}
+	messages := map[int][]string{}
+	order := []int{}
+	for _, e := range r.Errors {
+		if _, ok := messages[e.Row]; !ok {
+			order = append(order, e.Row)
+		}
+		msg := e.Message
+		if e.Column != "" {
+			msg = e.Column + ": " + msg
+		}
+		messages[e.Row] = append(messages[e.Row], msg)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(append([]string{"_row", "_error"}, r.header...)); err != nil {
+		return nil, err
+	}
+	for _, line := range order {
+		out := []string{strconv.Itoa(line), strings.Join(messages[line], "; ")}
+		for _, cell := range r.failed[line] {
+			out = append(out, sanitizeCell(cell))
+		}
+		if err := writer.Write(out); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sanitizeCell 为可能被表格软件当作公式执行的单元格加前缀单引号；数字（如负经度）保持原样以便修正后重新导入。
+func sanitizeCell(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return value
+	}
+	if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		return value
+	}
+	if strings.ContainsRune("=+-@\t\r\n", rune(value[0])) || strings.ContainsRune("=+-@", rune(trimmed[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,180 @@
+package csvimport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testItem struct {
+	Code string
+	Qty  int
+}
+
+type recordingSaver struct {
+	batches [][]testItem
+	failOn  string
+	err     error
+}
+
+func (s *recordingSaver) save(_ context.Context, items []testItem) error {
+	for _, item := range items {
+		if item.Code == s.failOn {
+			return errors.New("code already taken")
+		}
+	}
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, items)
+	return nil
+}
+
+func testSpec(saver *recordingSaver) Spec[testItem] {
+	return Spec[testItem]{
+		Required: []string{"code", "qty"},
+		Aliases:  map[string]string{"编号": "code", "数量": "qty"},
+		Parse: func(_ context.Context, row Row) (testItem, error) {
+			var errs []error
+			if row.Get("code") == "" {
+				errs = append(errs, ColumnError("code", "is required"))
+			}
+			qty, err := strconv.Atoi(row.Get("qty"))
+			if err != nil || qty < 0 {
+				errs = append(errs, ColumnError("qty", "must be a non-negative integer"))
+			}
+			return testItem{Code: row.Get("code"), Qty: qty}, errors.Join(errs...)
+		},
+		Key:  func(item testItem) string { return item.Code },
+		Save: saver.save,
+	}
+}
+
+const mixedFile = "\ufeff编号,Qty,note\n" +
+	"A-1,2,ok\n" +
+	"\n" +
+	",x,both wrong\n" +
+	"A-2,=1+1,formula\n" +
+	"A-1,3,dup\n" +
+	"A-3,-0,ok\n"
+
+func TestRunAllOrNothingWritesNothingWhenAnyRowFails(t *testing.T) {
+	saver := &recordingSaver{}
+	result, err := Run(context.Background(), strings.NewReader(mixedFile), testSpec(saver), Options{})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if result.Mode != ModeAllOrNothing || result.Committed || len(saver.batches) != 0 {
+		t.Fatalf("expected nothing written, got %+v batches=%v", result, saver.batches)
+	}
+	if result.Total != 5 || result.Succeeded != 2 || result.Failed != 3 {
+		t.Fatalf("unexpected counts: %+v", result)
+	}
+	want := []RowError{
+		{Row: 4, Column: "code", Message: "is required"},
+		{Row: 4, Column: "qty", Message: "must be a non-negative integer"},
+		{Row: 5, Column: "qty", Message: "must be a non-negative integer"},
+		{Row: 6, Message: "duplicate row, first seen on row 2"},
+	}
+	if len(result.Errors) != len(want) {
+		t.Fatalf("errors = %+v", result.Errors)
+	}
+	for i := range want {
+		if result.Errors[i] != want[i] {
+			t.Fatalf("error %d = %+v, want %+v", i, result.Errors[i], want[i])
+		}
+	}
+
+	report, err := result.ErrorReport()
+	if err != nil {
+		t.Fatalf("ErrorReport returned error: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(report, []byte("\ufeff")))).ReadAll()
+	if err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(records) != 4 || strings.Join(records[0], ",") != "_row,_error,编号,Qty,note" {
+		t.Fatalf("unexpected report: %q", records)
+	}
+	if records[1][0] != "4" || records[1][1] != "code: is required; qty: must be a non-negative integer" {
+		t.Fatalf("unexpected report row: %q", records[1])
+	}
+	if records[2][3] != "'=1+1" {
+		t.Fatalf("formula cells should be escaped: %q", records[2])
+	}
+}
+
+func TestRunAllOrNothingSavesOneBatch(t *testing.T) {
+	saver := &recordingSaver{}
+	file := "code,qty\nA-1,1\nA-2,-3\n"
+	spec := testSpec(saver)
+	spec.Parse = func(_ context.Context, row Row) (testItem, error) {
+		qty, _ := strconv.Atoi(row.Get("qty"))
+		return testItem{Code: row.Get("code"), Qty: qty}, nil
+	}
+
+	result, err := Run(context.Background(), strings.NewReader(file), spec, Options{DryRun: true})
+	if err != nil || result.Committed || result.Succeeded != 2 || len(saver.batches) != 0 {
+		t.Fatalf("dry run must not write: %+v (%v)", result, err)
+	}
+	if report, _ := result.ErrorReport(); report != nil {
+		t.Fatalf("expected no error report, got %q", report)
+	}
+
+	result, err = Run(context.Background(), strings.NewReader(file), spec, Options{Mode: "ALL_OR_NOTHING"})
+	if err != nil || !result.Committed || len(saver.batches) != 1 || len(saver.batches[0]) != 2 {
+		t.Fatalf("expected one batch with both rows: %+v batches=%v (%v)", result, saver.batches, err)
+	}
+
+	saver.err = errors.New("tx aborted")
+	if _, err := Run(context.Background(), strings.NewReader(file), spec, Options{}); err == nil || err.Error() != "tx aborted" {
+		t.Fatalf("expected save error, got %v", err)
+	}
+}
+
+func TestRunBestEffortSavesValidRows(t *testing.T) {
+	saver := &recordingSaver{failOn: "A-3"}
+	result, err := Run(context.Background(), strings.NewReader(mixedFile), testSpec(saver), Options{Mode: ModeBestEffort})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !result.Committed || result.Succeeded != 1 || result.Failed != 4 || len(saver.batches) != 1 || saver.batches[0][0].Code != "A-1" {
+		t.Fatalf("unexpected result: %+v batches=%v", result, saver.batches)
+	}
+	last := result.Errors[len(result.Errors)-1]
+	if last.Row != 7 || last.Message != "code already taken" {
+		t.Fatalf("save errors should be recorded per row: %+v", last)
+	}
+	report, _ := result.ErrorReport()
+	if !strings.Contains(string(report), "7,code already taken,A-3,-0,ok") {
+		t.Fatalf("report should keep original values of rows failed on save: %s", report)
+	}
+}
+
+func TestRunRejectsInvalidFiles(t *testing.T) {
+	saver := &recordingSaver{}
+	cases := []string{
+		"code,note\nA-1,x\n",
+		"code,qty,编号\nA-1,1,A-1\n",
+		"code,qty\n\"A-1,1\n",
+	}
+	for i, file := range cases {
+		if _, err := Run(context.Background(), strings.NewReader(file), testSpec(saver), Options{}); !errors.Is(err, ErrInvalidFile) {
+			t.Fatalf("case %d: expected ErrInvalidFile, got %v", i, err)
+		}
+	}
+	if _, err := Run(context.Background(), strings.NewReader("code,qty\nA-1,1\nA-2,1\n"), testSpec(saver), Options{MaxRows: 1}); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("expected row limit error, got %v", err)
+	}
+	if _, err := Run(context.Background(), strings.NewReader("code,qty\n"), testSpec(saver), Options{Mode: "partial"}); !errors.Is(err, ErrInvalidMode) {
+		t.Fatalf("expected ErrInvalidMode, got %v", err)
+	}
+	result, err := Run(context.Background(), strings.NewReader(""), testSpec(saver), Options{})
+	if err != nil || result.Total != 0 || result.Errors == nil {
+		t.Fatalf("empty file should produce an empty result: %+v (%v)", result, err)
+	}
+}
@@ -7,11 +7,12 @@ const (
 	OK = 0
 
 	// 通用客户端错误（4xx 范围）
-	ErrBadRequest   = 40000 // 请求参数错误
-	ErrUnauthorized = 40001 // 未认证（未登录或令牌无效）
-	ErrForbidden    = 40003 // 无权限（RBAC 拒绝）
-	ErrNotFound     = 40004 // 资源不存在
-	ErrConflict     = 40009 // 资源冲突
+	ErrBadRequest      = 40000 // 请求参数错误
+	ErrUnauthorized    = 40001 // 未认证（未登录或令牌无效）
+	ErrForbidden       = 40003 // 无权限（RBAC 拒绝）
+	ErrNotFound        = 40004 // 资源不存在
+	ErrConflict        = 40009 // 资源冲突
+	ErrTooManyRequests = 40029 // 请求过于频繁，已被限流
 
 	// 参数验证错误
 	ErrValidation = 42200 // 请求参数验证失败
@@ -21,6 +22,17 @@ const (
 	ErrCompanyHasCruises = 42202 // 公司下仍有邮轮，无法删除
 	ErrPasswordMismatch  = 42203 // 密码不匹配
 	ErrCruiseHasVoyages  = 42204 // 邮轮下仍有航次，无法删除
+	ErrCabinSoldOut      = 42205 // 舱房已售罄，可登记候补
+
+	// 员工账号安全
+	ErrAccountLocked          = 42206 // 连续登录失败，账号被临时锁定
+	ErrPasswordChangeRequired = 42207 // 密码已过期或需首次修改，需在登录时提交新密码
+	ErrPasswordPolicy         = 42208 // 新密码不符合复杂度或历史规则
+	ErrTOTPRequired           = 42209 // 需要提供二次验证码
+	ErrTOTPEnrollmentRequired = 42210 // 所属角色要求二次验证，需先绑定验证器
+
+	// 幂等请求
+	ErrIdempotencyKeyReused = 42211 // 同一 Idempotency-Key 被用于内容不同的请求
 
 	// 服务器内部错误（5xx 范围）
 	ErrInternal = 50000 // 服务器内部错误
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// WithContext 将请求级日志记录器（携带 request_id、trace_id 等字段）写入 context。
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext 返回 context 中的请求级日志记录器；不存在时（如定时任务）返回全局记录器 zap.L()。
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok && l != nil {
+			return l
+		}
+	}
+	return zap.L()
+}
@@ -1,6 +1,11 @@
 package logger
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
 
 func TestNew(t *testing.T) {
 	// Valid logLevel tests the happy path
@@ -15,3 +20,13 @@ func TestNew(t *testing.T) {
 		t.Fatal("Logger should not be nil even with invalid level")
 	}
 }
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != zap.L() {
+		t.Fatal("expected global logger when context has none")
+	}
+	l := zap.NewNop().With(zap.String("request_id", "r1"))
+	if FromContext(WithContext(context.Background(), l)) != l {
+		t.Fatal("expected request logger from context")
+	}
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// namespace 是所有业务指标的名称前缀。
+const namespace = "cruise"
+
+// gaugeQueryTimeout 是抓取时查询业务状态（如有效占座数）的超时时间。
+const gaugeQueryTimeout = 2 * time.Second
+
+// Registry 是本服务指标的注册表，已包含 Go 运行时与进程指标。
+var Registry = prometheus.NewRegistry()
+
+// HTTP RED 指标，route 取路由模板（未匹配路由记为 "unmatched"），避免路径参数导致标签基数膨胀。
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "http", Name: "requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+		Help:    "HTTP request latency by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	HTTPInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "http", Name: "requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+)
+
+// 数据库与业务指标。
+var (
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "db", Name: "query_duration_seconds",
+		Help:    "GORM statement latency by operation, table and result.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "result"})
+	SearchRetryDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "search_retry", Name: "dropped_total",
+		Help: "Search index tasks dropped because the retry queue was full.",
+	})
+	SearchRetryExhausted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "search_retry", Name: "exhausted_total",
+		Help: "Search index tasks abandoned after reaching the retry limit.",
+	})
+	OrderTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "order", Name: "status_transitions_total",
+		Help: "Committed order status transitions by source and target status.",
+	}, []string{"from", "to"})
+	PaymentCallbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "payment", Name: "callbacks_total",
+		Help: "Payment provider callbacks by provider and outcome.",
+	}, []string{"provider", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPRequestDuration, HTTPInFlight,
+		DBQueryDuration, SearchRetryDropped, SearchRetryExhausted, OrderTransitions, PaymentCallbacks,
+	)
+}
+
+// Handler 返回 /metrics 处理器；token 非空时要求请求携带 Authorization: Bearer <token>。
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+	if token == "" {
+		return h
+	}
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// RegisterGauge 注册一个在抓取时调用 fn 取值的业务状态指标（如有效占座数、待投递通知数）。
+// fn 出错时记录日志并跳过本次取值，不影响其余指标；同名指标重复注册时替换原取值函数。
+func RegisterGauge(name, help string, fn func(ctx context.Context) (float64, error)) error {
+	return replace("gauge:"+name, &gaugeCollector{
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, nil, nil),
+		name: name,
+		fn:   fn,
+	})
+}
+
+var (
+	replaceableMu sync.Mutex
+	replaceable   = map[string]prometheus.Collector{}
+)
+
+// replace 以 key 注册依赖运行时对象的采集器，先注销同 key 的旧采集器，使同一进程内重复装配（如测试中多次启动）不会冲突。
+func replace(key string, c prometheus.Collector) error {
+	replaceableMu.Lock()
+	defer replaceableMu.Unlock()
+	if old, ok := replaceable[key]; ok {
+		Registry.Unregister(old)
+		delete(replaceable, key)
+	}
+	if err := Registry.Register(c); err != nil {
+		return err
+	}
+	replaceable[key] = c
+	return nil
+}
+
+type gaugeCollector struct {
+	desc *prometheus.Desc
+	name string
+	fn   func(ctx context.Context) (float64, error)
+}
+
+func (g *gaugeCollector) Describe(ch chan<- *prometheus.Desc) { ch <- g.desc }
+
+func (g *gaugeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), gaugeQueryTimeout)
+	defer cancel()
+	value, err := g.fn(ctx)
+	if err != nil {
+		zap.L().Warn("metrics: collect gauge failed", zap.String("metric", g.name), zap.Error(err))
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, value)
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type metricsTestRow struct {
+	ID   int64
+	Name string
+}
+
+func TestInstrumentDB_ObservesQueriesAndPoolStats(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.AutoMigrate(&metricsTestRow{}); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if err := InstrumentDB(db, "metrics_test"); err != nil {
+		t.Fatalf("instrument failed: %v", err)
+	}
+
+	if err := db.Create(&metricsTestRow{Name: "a"}).Error; err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	var row metricsTestRow
+	if err := db.First(&row).Error; err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+
+	if n := testutil.CollectAndCount(DBQueryDuration, "cruise_db_query_duration_seconds"); n < 2 {
+		t.Fatalf("expected create and query observations, got %d series", n)
+	}
+	body := scrape(t, Handler(""), "")
+	if !strings.Contains(body, `cruise_db_query_duration_seconds_count{operation="query",result="ok",table="metrics_test_rows"}`) {
+		t.Fatalf("expected query timing for metrics_test_rows, got:\n%s", body)
+	}
+	if !strings.Contains(body, `go_sql_open_connections{db_name="metrics_test"}`) {
+		t.Fatal("expected db pool stats to be exported")
+	}
+}
+
+func TestRegisterGauge_SkipsFailedCollection(t *testing.T) {
+	if err := RegisterGauge("test_active_holds", "Active holds in test.", func(context.Context) (float64, error) { return 7, nil }); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	if err := RegisterGauge("test_broken_gauge", "Broken gauge in test.", func(context.Context) (float64, error) { return 0, errors.New("db down") }); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	body := scrape(t, Handler(""), "")
+	if !strings.Contains(body, "cruise_test_active_holds 7") {
+		t.Fatalf("expected gauge value, got:\n%s", body)
+	}
+	if strings.Contains(body, "cruise_test_broken_gauge ") {
+		t.Fatal("expected failed gauge to be skipped")
+	}
+}
+
+func TestHandler_RequiresBearerToken(t *testing.T) {
+	h := Handler("s3cret")
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", w.Code)
+	}
+	if body := scrape(t, h, "Bearer s3cret"); !strings.Contains(body, "go_goroutines") {
+		t.Fatal("expected runtime metrics with valid token")
+	}
+}
+
+func scrape(t *testing.T, h http.Handler, authorization string) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 from metrics handler, got %d", w.Code)
+	}
+	return w.Body.String()
+}
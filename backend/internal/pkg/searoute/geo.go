@@ -0,0 +1,94 @@
+package searoute
+
+import "math"
+
+// earthRadiusKM 是计算大圆距离使用的地球平均半径（公里）。
+const earthRadiusKM = 6371.0088
+
+// Point 表示一个经纬度坐标（十进制度）。
+type Point struct {
+	Lat float64 // 纬度
+	Lon float64 // 经度
+}
+
+// DistanceKM 使用 haversine 公式计算两点之间的大圆距离（公里）。
+func DistanceKM(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKM * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Interpolate 返回 a 到 b 大圆弧上比例 f（0~1）处的点。
+func Interpolate(a, b Point, f float64) Point {
+	d := DistanceKM(a, b) / earthRadiusKM
+	if d == 0 {
+		return a
+	}
+	lat1, lon1 := radians(a.Lat), radians(a.Lon)
+	lat2, lon2 := radians(b.Lat), radians(b.Lon)
+	sinD := math.Sin(d)
+	wa := math.Sin((1-f)*d) / sinD
+	wb := math.Sin(f*d) / sinD
+	x := wa*math.Cos(lat1)*math.Cos(lon1) + wb*math.Cos(lat2)*math.Cos(lon2)
+	y := wa*math.Cos(lat1)*math.Sin(lon1) + wb*math.Cos(lat2)*math.Sin(lon2)
+	z := wa*math.Sin(lat1) + wb*math.Sin(lat2)
+	return Point{
+		Lat: degrees(math.Atan2(z, math.Sqrt(x*x+y*y))),
+		Lon: degrees(math.Atan2(y, x)),
+	}
+}
+
+// Densify 沿大圆弧插值，使相邻两点间距不超过 stepKM，便于地图绘制平滑航线；stepKM 非正数时原样返回。
+func Densify(points []Point, stepKM float64) []Point {
+	if stepKM <= 0 || len(points) < 2 {
+		return points
+	}
+	result := []Point{points[0]}
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		n := int(math.Ceil(DistanceKM(a, b) / stepKM))
+		for k := 1; k < n; k++ {
+			result = append(result, Interpolate(a, b, float64(k)/float64(n)))
+		}
+		result = append(result, b)
+	}
+	return result
+}
+
+// polygon 是以 [经度, 纬度] 顶点描述的粗粒度陆地多边形，附带外包框用于快速排除。
+type polygon struct {
+	vertices                       [][2]float64
+	minLon, maxLon, minLat, maxLat float64
+}
+
+func newPolygon(vertices [][2]float64) polygon {
+	p := polygon{vertices: vertices, minLon: 180, maxLon: -180, minLat: 90, maxLat: -90}
+	for _, v := range vertices {
+		p.minLon, p.maxLon = math.Min(p.minLon, v[0]), math.Max(p.maxLon, v[0])
+		p.minLat, p.maxLat = math.Min(p.minLat, v[1]), math.Max(p.maxLat, v[1])
+	}
+	return p
+}
+
+// contains 使用射线法判断点是否落在多边形内。
+func (p polygon) contains(pt Point) bool {
+	if pt.Lon < p.minLon || pt.Lon > p.maxLon || pt.Lat < p.minLat || pt.Lat > p.maxLat {
+		return false
+	}
+	inside := false
+	n := len(p.vertices)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		xi, yi := p.vertices[i][0], p.vertices[i][1]
+		xj, yj := p.vertices[j][0], p.vertices[j][1]
+		if (yi > pt.Lat) != (yj > pt.Lat) && pt.Lon < (xj-xi)*(pt.Lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
@@ -0,0 +1,257 @@
+package searoute
+
+// networkNode 是内置海上航路网络的航路点，均位于开阔水域。
+type networkNode struct {
+	id  string
+	lat float64
+	lon float64
+}
+
+// bundledNodes 覆盖渤海、黄海、东海、台湾海峡、南海、日本列岛沿岸、日本海与马六甲海峡的粗粒度航路点。
+var bundledNodes = []networkNode{
+	// 渤海、黄海
+	{"bohai_w", 38.75, 118.6},
+	{"bohai_c", 38.9, 120.0},
+	{"bohai_strait", 38.2, 120.9},
+	{"yellow_n", 38.3, 122.8},
+	{"shandong_e", 37.2, 123.2},
+	{"qingdao_off", 35.7, 120.9},
+	{"yellow_c", 35.5, 123.5},
+	{"yellow_s", 33.5, 124.0},
+	// 东海、台湾海峡
+	{"yangtze_off", 31.2, 122.8},
+	{"east_china_c", 30.0, 124.5},
+	{"zhejiang_off", 28.0, 122.6},
+	{"taiwan_strait_n", 25.8, 120.4},
+	{"taiwan_strait_c", 24.2, 119.6},
+	{"taiwan_strait_s", 22.9, 118.6},
+	{"taiwan_ne", 25.3, 122.5},
+	{"taiwan_e", 23.8, 122.8},
+	{"luzon_strait", 20.8, 120.9},
+	{"okinawa_w", 26.3, 127.0},
+	// 南海、北部湾、泰国湾、马六甲海峡
+	{"hk_off", 21.9, 114.4},
+	{"pearl_w", 21.4, 112.5},
+	{"scs_n", 20.0, 116.0},
+	{"hainan_e", 19.3, 111.8},
+	{"hainan_s", 17.8, 109.5},
+	{"tonkin_s", 18.5, 107.5},
+	{"tonkin_n", 20.6, 107.5},
+	{"paracel_w", 17.5, 111.0},
+	{"danang_off", 16.2, 108.8},
+	{"nhatrang_off", 12.0, 109.9},
+	{"vungtau_off", 9.8, 107.6},
+	{"camau_s", 7.8, 105.0},
+	{"gulf_thai", 10.0, 102.0},
+	{"thai_n", 12.6, 100.6},
+	{"scs_c", 14.5, 115.0},
+	{"scs_s", 8.5, 110.5},
+	{"luzon_w", 16.8, 119.4},
+	{"manila_off", 14.2, 120.2},
+	{"sg_e", 1.5, 104.6},
+	{"sg_strait", 1.2, 103.75},
+	{"malacca_sg", 1.15, 103.2},
+	{"malacca_s", 2.0, 101.6},
+	{"malacca_c", 3.4, 100.2},
+	{"malacca_n", 5.3, 98.9},
+	// 韩国沿岸、济州岛、对马海峡
+	{"nk_w", 38.5, 123.9},
+	{"incheon_off", 37.1, 126.0},
+	{"korea_w", 36.0, 125.7},
+	{"korea_sw", 33.9, 125.5},
+	{"jeju_n", 33.75, 126.5},
+	{"jeju_w", 33.35, 125.95},
+	{"jeju_s", 32.95, 126.55},
+	{"jeju_e", 33.45, 127.2},
+	{"korea_strait_w", 34.85, 129.05},
+	{"tsushima_n", 35.0, 129.7},
+	{"korea_strait_e", 34.1, 129.9},
+	// 九州、濑户内海、日本太平洋沿岸
+	{"genkai", 33.85, 130.2},
+	{"hibiki", 34.15, 130.6},
+	{"kanmon", 33.96, 130.95},
+	{"suo", 33.85, 131.35},
+	{"hirado_off", 33.4, 129.3},
+	{"nagasaki_off", 32.7, 129.6},
+	{"koshiki_w", 31.9, 129.4},
+	{"kyushu_sw", 30.6, 130.1},
+	{"kagoshima_mouth", 30.95, 130.55},
+	{"kagoshima_bay", 31.35, 130.7},
+	{"osumi", 30.75, 130.8},
+	{"pacific_kyushu", 31.3, 131.8},
+	{"bungo_s", 32.6, 132.2},
+	{"hoyo", 33.3, 131.85},
+	{"iyo", 33.75, 132.1},
+	{"seto_w", 34.12, 132.75},
+	{"seto_c", 34.32, 133.5},
+	{"seto_e", 34.5, 134.6},
+	{"akashi", 34.62, 135.02},
+	{"shikoku_s", 32.5, 133.5},
+	{"kii_s", 32.9, 135.2},
+	{"kii_channel", 33.9, 134.85},
+	{"osaka_bay", 34.45, 135.25},
+	{"enshu", 33.9, 137.0},
+	{"izu_s", 34.45, 138.75},
+	{"izu_oshima", 34.75, 139.15},
+	{"tokyo_mouth", 35.0, 139.62},
+	{"boso_s", 34.7, 139.9},
+	{"boso_e", 35.0, 140.9},
+	{"joban", 37.0, 141.6},
+	{"sanriku", 38.3, 142.1},
+	{"iwate", 40.0, 142.3},
+	{"tsugaru_e", 41.55, 141.6},
+	{"tsugaru_c2", 41.62, 140.95},
+	{"tsugaru_c", 41.45, 140.6},
+	{"tsugaru_w", 41.3, 139.7},
+	// 日本海
+	{"sj_4", 40.5, 139.2},
+	{"sj_3", 38.8, 137.5},
+	{"sj_2", 38.0, 134.0},
+	{"sj_1", 36.5, 131.5},
+	{"korea_e_n", 37.5, 130.0},
+	{"vlad_off", 42.4, 132.0},
+}
+
+// bundledEdges 是航路点之间的双向航段，距离按大圆距离计算。
+var bundledEdges = [][2]string{
+	{"bohai_w", "bohai_c"}, {"bohai_c", "bohai_strait"}, {"bohai_strait", "yellow_n"},
+	{"yellow_n", "shandong_e"}, {"yellow_n", "nk_w"}, {"shandong_e", "qingdao_off"},
+	{"shandong_e", "yellow_c"}, {"qingdao_off", "yellow_c"}, {"qingdao_off", "yangtze_off"},
+	{"yellow_c", "yellow_s"}, {"yellow_c", "korea_w"}, {"yellow_s", "yangtze_off"},
+	{"yellow_s", "east_china_c"}, {"yellow_s", "korea_sw"}, {"yellow_s", "jeju_w"},
+	{"yangtze_off", "east_china_c"}, {"yangtze_off", "zhejiang_off"},
+	{"east_china_c", "zhejiang_off"}, {"east_china_c", "okinawa_w"}, {"east_china_c", "jeju_s"},
+	{"east_china_c", "kyushu_sw"}, {"east_china_c", "taiwan_ne"},
+	{"zhejiang_off", "taiwan_strait_n"}, {"zhejiang_off", "taiwan_ne"},
+	{"taiwan_strait_n", "taiwan_strait_c"}, {"taiwan_strait_n", "taiwan_ne"},
+	{"taiwan_strait_c", "taiwan_strait_s"}, {"taiwan_strait_s", "hk_off"},
+	{"taiwan_strait_s", "scs_n"}, {"taiwan_strait_s", "luzon_strait"},
+	{"taiwan_ne", "taiwan_e"}, {"taiwan_ne", "okinawa_w"}, {"taiwan_e", "luzon_strait"},
+	{"luzon_strait", "scs_n"}, {"luzon_strait", "luzon_w"},
+	{"hk_off", "pearl_w"}, {"hk_off", "scs_n"}, {"pearl_w", "hainan_e"}, {"scs_n", "hainan_e"},
+	{"scs_n", "scs_c"}, {"hainan_e", "paracel_w"}, {"hainan_e", "hainan_s"},
+	{"hainan_s", "tonkin_s"}, {"hainan_s", "paracel_w"}, {"hainan_s", "danang_off"},
+	{"tonkin_s", "tonkin_n"}, {"tonkin_s", "danang_off"}, {"paracel_w", "danang_off"},
+	{"paracel_w", "scs_c"}, {"danang_off", "nhatrang_off"}, {"nhatrang_off", "vungtau_off"},
+	{"nhatrang_off", "scs_s"}, {"scs_c", "scs_s"}, {"scs_c", "manila_off"},
+	{"luzon_w", "manila_off"}, {"luzon_w", "scs_c"}, {"vungtau_off", "camau_s"},
+	{"vungtau_off", "scs_s"}, {"camau_s", "gulf_thai"}, {"camau_s", "sg_e"}, {"scs_s", "sg_e"},
+	{"gulf_thai", "thai_n"}, {"sg_e", "sg_strait"}, {"sg_strait", "malacca_sg"},
+	{"malacca_sg", "malacca_s"}, {"malacca_s", "malacca_c"}, {"malacca_c", "malacca_n"},
+	{"nk_w", "incheon_off"}, {"incheon_off", "korea_w"}, {"korea_w", "korea_sw"},
+	{"korea_sw", "jeju_w"}, {"korea_sw", "jeju_n"}, {"jeju_w", "jeju_n"}, {"jeju_w", "jeju_s"},
+	{"jeju_n", "jeju_e"}, {"jeju_s", "jeju_e"}, {"jeju_e", "korea_strait_w"},
+	{"jeju_e", "hirado_off"}, {"korea_strait_w", "tsushima_n"},
+	{"tsushima_n", "korea_strait_e"}, {"tsushima_n", "korea_e_n"}, {"tsushima_n", "sj_1"},
+	{"korea_strait_e", "genkai"}, {"korea_strait_e", "hibiki"}, {"korea_strait_e", "hirado_off"},
+	{"genkai", "hibiki"}, {"hibiki", "sj_1"}, {"hibiki", "kanmon"}, {"kanmon", "suo"}, {"suo", "iyo"},
+	{"hirado_off", "nagasaki_off"}, {"nagasaki_off", "koshiki_w"}, {"nagasaki_off", "jeju_s"},
+	{"koshiki_w", "jeju_s"}, {"koshiki_w", "kyushu_sw"}, {"okinawa_w", "kyushu_sw"},
+	{"kyushu_sw", "kagoshima_mouth"}, {"kyushu_sw", "osumi"},
+	{"kagoshima_mouth", "kagoshima_bay"}, {"kagoshima_mouth", "osumi"},
+	{"osumi", "pacific_kyushu"}, {"pacific_kyushu", "bungo_s"}, {"bungo_s", "hoyo"},
+	{"hoyo", "iyo"}, {"iyo", "seto_w"}, {"seto_w", "seto_c"}, {"seto_c", "seto_e"},
+	{"seto_e", "akashi"}, {"akashi", "osaka_bay"}, {"bungo_s", "shikoku_s"},
+	{"shikoku_s", "kii_s"}, {"kii_s", "kii_channel"}, {"kii_channel", "osaka_bay"},
+	{"kii_s", "enshu"}, {"enshu", "izu_s"}, {"izu_s", "izu_oshima"},
+	{"izu_oshima", "tokyo_mouth"}, {"tokyo_mouth", "boso_s"}, {"boso_s", "boso_e"},
+	{"boso_e", "joban"}, {"joban", "sanriku"}, {"sanriku", "iwate"}, {"iwate", "tsugaru_e"},
+	{"tsugaru_e", "tsugaru_c2"}, {"tsugaru_c2", "tsugaru_c"}, {"tsugaru_c", "tsugaru_w"},
+	{"tsugaru_w", "sj_4"}, {"sj_4", "sj_3"}, {"sj_3", "sj_2"}, {"sj_2", "sj_1"},
+	{"sj_2", "korea_e_n"}, {"sj_2", "vlad_off"}, {"korea_e_n", "vlad_off"},
+}
+
+// bundledLand 是航线避让使用的粗粒度陆地轮廓（[经度, 纬度]），仅覆盖网络范围内的主要陆块与岛屿，
+// 海湾按需简化或封闭；港口坐标可能落在轮廓内，由路由时的出港段处理。
+var bundledLand = [][][2]float64{
+	// 中国大陆东部沿海（含山东、辽东半岛），向内陆闭合
+	{
+		{108.0, 21.5}, {109.1, 21.45}, {109.7, 21.0}, {110.2, 20.25}, {110.55, 20.4}, {110.45, 21.2},
+		{111.8, 21.5}, {113.1, 22.0}, {113.5, 22.1}, {113.8, 22.4}, {114.1, 22.25}, {114.3, 22.2},
+		{115.4, 22.7}, {116.7, 23.3}, {117.2, 23.6}, {118.1, 24.4}, {118.7, 24.8}, {119.6, 25.5},
+		{119.7, 26.2}, {120.1, 26.7}, {120.8, 27.9}, {121.5, 28.5}, {122.1, 30.0}, {121.9, 30.85},
+		{121.95, 31.3}, {121.8, 31.7}, {121.3, 32.4}, {120.9, 33.0}, {120.3, 34.3}, {119.6, 35.1},
+		{120.0, 35.8}, {120.4, 36.05}, {120.9, 36.3}, {121.6, 36.8}, {122.6, 37.0}, {122.7, 37.4},
+		{121.9, 37.55}, {121.4, 37.6}, {120.7, 37.8}, {120.2, 37.6}, {119.3, 37.2}, {119.2, 37.8},
+		{118.5, 38.2}, {117.8, 38.6}, {117.7, 39.0}, {118.0, 39.2}, {119.0, 39.2}, {119.5, 39.8},
+		{120.5, 40.3}, {121.0, 40.8}, {122.0, 40.7}, {121.6, 39.6}, {121.15, 38.75}, {121.9, 39.0},
+		{122.8, 39.5}, {123.5, 39.8}, {124.3, 39.85}, {124.5, 42.0}, {105.0, 42.0}, {105.0, 21.5},
+	},
+	// 朝鲜半岛
+	{
+		{124.3, 39.85}, {124.7, 39.6}, {125.3, 38.9}, {124.7, 38.1}, {125.6, 37.7}, {126.1, 37.7},
+		{126.6, 37.45}, {126.15, 36.75}, {126.5, 36.0}, {126.3, 35.1}, {126.3, 34.4}, {126.5, 34.3},
+		{127.5, 34.6}, {128.5, 34.85}, {129.1, 35.1}, {129.45, 35.5}, {129.4, 36.0}, {129.45, 37.0},
+		{129.0, 37.7}, {128.4, 38.6}, {127.5, 39.3}, {128.6, 40.0}, {129.7, 40.8}, {129.8, 41.4},
+		{130.7, 42.3}, {130.0, 43.0}, {125.0, 43.0},
+	},
+	// 俄罗斯滨海边疆区
+	{{130.7, 42.3}, {131.3, 42.6}, {131.9, 43.0}, {133.0, 42.7}, {135.0, 43.5}, {138.0, 46.0}, {138.0, 48.0}, {130.0, 48.0}},
+	// 济州岛
+	{{126.15, 33.3}, {126.3, 33.2}, {126.9, 33.3}, {126.95, 33.5}, {126.5, 33.55}, {126.2, 33.45}},
+	// 对马岛
+	{{129.2, 34.1}, {129.5, 34.3}, {129.5, 34.7}, {129.3, 34.7}, {129.2, 34.3}},
+	// 九州（鹿儿岛湾开口）
+	{
+		{130.4, 33.6}, {129.9, 33.5}, {129.6, 33.35}, {129.7, 33.15}, {129.75, 32.75},
+		{129.75, 32.58}, {130.35, 32.6}, {130.0, 32.2}, {130.15, 31.7}, {130.2, 31.3}, {130.5, 31.18},
+		{130.62, 31.25}, {130.6, 31.55}, {130.7, 31.7}, {130.8, 31.5}, {130.78, 31.2}, {130.67, 31.0},
+		{131.1, 31.4}, {131.45, 31.8}, {131.6, 32.5}, {131.9, 32.9}, {131.7, 33.3}, {131.7, 33.55},
+		{131.6, 33.65}, {131.0, 33.9}, {130.9, 33.9},
+	},
+	// 本州（东京湾封闭）
+	{
+		{130.9, 34.02}, {131.4, 34.45}, {132.1, 34.9}, {132.7, 35.45}, {134.2, 35.55}, {135.2, 35.75},
+		{136.0, 35.65}, {136.7, 37.3}, {137.3, 37.5}, {137.0, 36.8}, {139.0, 37.95}, {139.6, 38.7},
+		{139.75, 39.95}, {140.0, 40.6}, {140.3, 41.2}, {140.95, 41.5}, {141.45, 41.4}, {141.45, 40.6},
+		{142.0, 39.5}, {141.6, 38.4}, {141.0, 38.2}, {141.0, 37.0}, {140.6, 36.3}, {140.85, 35.7},
+		{140.4, 35.15}, {139.85, 34.9}, {139.65, 35.15}, {139.35, 35.3}, {139.1, 35.1}, {138.85, 34.6},
+		{138.75, 34.95}, {138.2, 34.6}, {137.05, 34.58}, {136.85, 34.3}, {136.2, 33.8}, {135.75, 33.43},
+		{135.1, 33.9}, {135.15, 34.2}, {135.4, 34.6}, {135.2, 34.68}, {134.95, 34.65}, {134.6, 34.75},
+		{134.0, 34.55}, {133.4, 34.4}, {132.45, 34.3}, {132.0, 34.0}, {131.5, 33.95}, {131.0, 33.98},
+	},
+	// 淡路岛
+	{{134.98, 34.57}, {135.05, 34.52}, {134.95, 34.3}, {134.85, 34.2}, {134.7, 34.2}, {134.65, 34.3}, {134.85, 34.5}},
+	// 四国
+	{
+		{134.6, 34.2}, {134.17, 33.25}, {133.55, 33.5}, {133.0, 32.72}, {132.7, 32.9}, {132.5, 33.2},
+		{132.0, 33.34}, {132.7, 33.85}, {133.0, 34.1}, {134.05, 34.35},
+	},
+	// 北海道
+	{
+		{140.0, 41.45}, {141.2, 41.8}, {141.0, 42.3}, {142.0, 42.5}, {143.3, 41.95}, {143.9, 42.9},
+		{145.6, 43.3}, {145.3, 44.3}, {144.0, 44.1}, {142.0, 45.5}, {141.6, 45.0}, {141.6, 43.9},
+		{141.3, 43.2}, {140.4, 43.3}, {140.0, 42.6}, {139.8, 42.0},
+	},
+	// 台湾岛
+	{
+		{121.0, 25.1}, {121.55, 25.3}, {122.0, 25.0}, {121.8, 24.3}, {121.5, 23.5}, {121.2, 22.8},
+		{120.85, 21.9}, {120.6, 22.4}, {120.3, 22.6}, {120.1, 23.0}, {120.2, 23.8}, {120.5, 24.4},
+		{120.9, 24.8},
+	},
+	// 海南岛
+	{{108.6, 19.1}, {109.2, 20.0}, {110.2, 20.1}, {111.0, 19.7}, {110.5, 18.8}, {109.5, 18.2}, {108.7, 18.5}},
+	// 吕宋岛（马尼拉湾封闭）
+	{
+		{120.6, 18.5}, {121.3, 18.6}, {122.2, 18.5}, {122.0, 17.0}, {121.6, 16.0}, {122.0, 14.5},
+		{124.2, 12.6}, {123.3, 13.0}, {122.5, 13.5}, {121.8, 13.8}, {120.9, 13.8}, {120.6, 14.4},
+		{120.3, 14.8}, {120.0, 15.5}, {119.8, 16.2}, {120.3, 16.6}, {120.4, 17.5},
+	},
+	// 中南半岛与马来半岛
+	{
+		{108.0, 21.5}, {107.3, 21.0}, {106.6, 20.2}, {105.9, 19.3}, {106.0, 18.3}, {107.1, 17.0},
+		{108.2, 16.1}, {108.8, 15.2}, {109.2, 13.8}, {109.3, 12.7}, {109.0, 11.5}, {108.0, 10.9},
+		{107.1, 10.4}, {106.7, 9.6}, {105.3, 8.6}, {104.8, 9.0}, {104.5, 10.4}, {103.5, 10.6},
+		{102.9, 11.6}, {101.0, 12.7}, {100.5, 13.5}, {99.9, 12.6}, {99.4, 11.0}, {99.3, 9.5},
+		{100.2, 8.4}, {100.6, 7.0}, {102.3, 6.2}, {103.45, 3.9}, {103.5, 2.5}, {104.25, 1.4},
+		{103.5, 1.27}, {103.4, 1.3}, {102.9, 1.9}, {101.8, 2.6}, {101.3, 3.0}, {100.7, 4.2},
+		{100.35, 5.3}, {100.1, 6.4}, {99.7, 7.0}, {98.4, 7.9}, {98.3, 9.0}, {98.6, 10.0},
+		{98.4, 12.0}, {97.6, 16.5}, {97.0, 22.0}, {105.0, 22.0},
+	},
+	// 苏门答腊岛
+	{
+		{95.3, 5.6}, {97.5, 5.2}, {98.7, 3.8}, {100.4, 2.2}, {101.5, 1.7}, {103.0, 0.5}, {104.0, -1.0},
+		{104.9, -2.3}, {106.0, -3.2}, {105.8, -5.8}, {104.5, -5.9}, {102.3, -4.0}, {100.4, -1.0},
+		{98.7, 1.7}, {97.2, 3.5},
+	},
+}
@@ -0,0 +1,298 @@
+// Package searoute 提供离线海上航线规划能力。
+// 基于内置的粗粒度海上航路网络，以大圆距离为权重用 A* 求两港之间的最短航线，
+// 并借助粗粒度陆地轮廓避免航段穿越陆地，供外部航线服务不可用时兜底使用。
+package searoute
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	sampleStepKM      = 2.0   // 陆地检测沿航段的采样间距
+	maxConnectKM      = 600.0 // 港口接入航路网络时允许的最远航路点距离
+	maxConnectNodes   = 8     // 港口接入时评估的最近航路点数量
+	maxConnectLinks   = 3     // 港口最多接入的航路点数量
+	maxApproachKM     = 80.0  // 港口坐标到开阔水域之间允许的最长陆地段（港口常落在粗粒度陆地轮廓内）
+	approachSlackKM   = 15.0  // 接入航段的陆地段相对最短出港段允许多出的距离，避免横穿岛屿绕行
+	samePortThreshold = 0.5   // 起终点距离小于该值（公里）视为同一港口
+)
+
+// ErrNoRoute 表示港口无法接入航路网络或网络内不存在连通航线。
+var ErrNoRoute = errors.New("searoute: no sea route found")
+
+// Route 表示一条规划好的海上航线。
+type Route struct {
+	Points     []Point // 航线途经点，首尾为起终点港口
+	DistanceKM float64 // 航线总距离（公里）
+}
+
+// Router 是基于内置航路网络的离线航线规划器，可并发使用。
+type Router struct {
+	nodes []Point
+	adj   [][]link
+	land  []polygon
+}
+
+// link 表示航路图中的一条有向边。
+type link struct {
+	to     int
+	distKM float64
+}
+
+var (
+	defaultRouter     *Router
+	defaultRouterOnce sync.Once
+)
+
+// Default 返回基于内置航路网络的共享规划器。
+func Default() *Router {
+	defaultRouterOnce.Do(func() {
+		router, err := newRouter(bundledNodes, bundledEdges, bundledLand)
+		if err != nil {
+			panic(err)
+		}
+		defaultRouter = router
+	})
+	return defaultRouter
+}
+
+// newRouter 根据航路点、航段与陆地轮廓构建规划器。
+func newRouter(nodes []networkNode, edges [][2]string, land [][][2]float64) (*Router, error) {
+	r := &Router{
+		nodes: make([]Point, len(nodes)),
+		adj:   make([][]link, len(nodes)),
+		land:  make([]polygon, 0, len(land)),
+	}
+	index := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		if _, exists := index[node.id]; exists {
+			return nil, fmt.Errorf("searoute: duplicate node %s", node.id)
+		}
+		index[node.id] = i
+		r.nodes[i] = Point{Lat: node.lat, Lon: node.lon}
+	}
+	for _, edge := range edges {
+		a, okA := index[edge[0]]
+		b, okB := index[edge[1]]
+		if !okA || !okB {
+			return nil, fmt.Errorf("searoute: edge %s-%s references unknown node", edge[0], edge[1])
+		}
+		dist := DistanceKM(r.nodes[a], r.nodes[b])
+		r.adj[a] = append(r.adj[a], link{to: b, distKM: dist})
+		r.adj[b] = append(r.adj[b], link{to: a, distKM: dist})
+	}
+	for _, vertices := range land {
+		r.land = append(r.land, newPolygon(vertices))
+	}
+	return r, nil
+}
+
+// Route 规划 from 到 to 的最短海上航线。
+// 起终点先分别接入附近可直达的航路点（允许从落在陆地轮廓内的港口坐标驶出），
+// 再在航路图上以大圆距离为启发函数执行 A* 搜索；两港之间可直接航行时也会考虑直达。
+func (r *Router) Route(from, to Point) (*Route, error) {
+	if DistanceKM(from, to) < samePortThreshold {
+		return &Route{Points: []Point{from, to}}, nil
+	}
+	startLinks, startApproach := r.connect(from)
+	goalLinks, goalApproach := r.connect(to)
+
+	start, goal := len(r.nodes), len(r.nodes)+1
+	var direct *link
+	if head, tail, crosses := r.landProfile(from, to); !crosses && head <= startApproach && tail <= goalApproach {
+		direct = &link{to: goal, distKM: DistanceKM(from, to)}
+	}
+	if direct == nil && (len(startLinks) == 0 || len(goalLinks) == 0) {
+		return nil, ErrNoRoute
+	}
+
+	goalFrom := make(map[int]float64, len(goalLinks))
+	for _, l := range goalLinks {
+		goalFrom[l.to] = l.distKM
+	}
+	point := func(i int) Point {
+		switch i {
+		case start:
+			return from
+		case goal:
+			return to
+		default:
+			return r.nodes[i]
+		}
+	}
+	neighbors := func(i int) []link {
+		if i == start {
+			links := append([]link(nil), startLinks...)
+			if direct != nil {
+				links = append(links, *direct)
+			}
+			return links
+		}
+		links := r.adj[i]
+		if dist, ok := goalFrom[i]; ok {
+			links = append(append([]link(nil), links...), link{to: goal, distKM: dist})
+		}
+		return links
+	}
+
+	path, dist, ok := aStar(start, goal, len(r.nodes)+2, neighbors, func(i int) float64 {
+		return DistanceKM(point(i), to)
+	})
+	if !ok {
+		return nil, ErrNoRoute
+	}
+	points := make([]Point, len(path))
+	for i, id := range path {
+		points[i] = point(id)
+	}
+	return &Route{Points: points, DistanceKM: dist}, nil
+}
+
+// connect 为港口挑选可接入的航路点，返回接入航段及允许的出港陆地段长度。
+// 只接受离开陆地后不再穿越陆地的航段，并优先出港陆地段最短的方向，避免从岛屿另一侧接入。
+func (r *Router) connect(port Point) ([]link, float64) {
+	type candidate struct {
+		node   int
+		distKM float64
+		headKM float64
+	}
+	nearest := make([]candidate, 0, len(r.nodes))
+	for i, node := range r.nodes {
+		if dist := DistanceKM(port, node); dist <= maxConnectKM {
+			nearest = append(nearest, candidate{node: i, distKM: dist})
+		}
+	}
+	sort.Slice(nearest, func(i, j int) bool { return nearest[i].distKM < nearest[j].distKM })
+	if len(nearest) > maxConnectNodes {
+		nearest = nearest[:maxConnectNodes]
+	}
+
+	clean := make([]candidate, 0, len(nearest))
+	minHead := math.Inf(1)
+	for _, c := range nearest {
+		head, tail, crosses := r.landProfile(port, r.nodes[c.node])
+		if crosses || tail > 0 || head > maxApproachKM {
+			continue
+		}
+		c.headKM = head
+		clean = append(clean, c)
+		minHead = math.Min(minHead, head)
+	}
+	if len(clean) == 0 {
+		return nil, maxApproachKM
+	}
+	allowed := math.Min(minHead+approachSlackKM, maxApproachKM)
+	links := make([]link, 0, maxConnectLinks)
+	for _, c := range clean {
+		if c.headKM <= allowed && len(links) < maxConnectLinks {
+			links = append(links, link{to: c.node, distKM: c.distKM})
+		}
+	}
+	return links, allowed
+}
+
+// landProfile 沿 a→b 大圆弧采样，返回从 a 出发连续落在陆地上的距离、到达 b 前连续落在陆地上的距离，
+// 以及两段之外的中间部分是否穿越陆地；整段都在陆地上时视为穿越。
+func (r *Router) landProfile(a, b Point) (headKM, tailKM float64, crosses bool) {
+	total := DistanceKM(a, b)
+	n := int(math.Ceil(total / sampleStepKM))
+	if n < 1 {
+		n = 1
+	}
+	step := total / float64(n)
+	firstSea, lastSea := -1, -1
+	onLand := make([]bool, n+1)
+	for i := 0; i <= n; i++ {
+		onLand[i] = r.onLand(Interpolate(a, b, float64(i)/float64(n)))
+		if !onLand[i] {
+			if firstSea < 0 {
+				firstSea = i
+			}
+			lastSea = i
+		}
+	}
+	if firstSea < 0 {
+		return total, total, true
+	}
+	for i := firstSea; i <= lastSea; i++ {
+		if onLand[i] {
+			crosses = true
+			break
+		}
+	}
+	return float64(firstSea) * step, float64(n-lastSea) * step, crosses
+}
+
+// onLand 判断坐标是否落在任一陆地轮廓内。
+func (r *Router) onLand(p Point) bool {
+	for _, poly := range r.land {
+		if poly.contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// aStar 在 size 个顶点的图上搜索 start 到 goal 的最短路径，heuristic 须不高估剩余距离。
+func aStar(start, goal, size int, neighbors func(int) []link, heuristic func(int) float64) ([]int, float64, bool) {
+	dist := make([]float64, size)
+	prev := make([]int, size)
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		prev[i] = -1
+	}
+	dist[start] = 0
+	open := &frontier{{node: start, priority: heuristic(start)}}
+	for open.Len() > 0 {
+		current := heap.Pop(open).(frontierItem)
+		if current.node == goal {
+			break
+		}
+		if current.priority-heuristic(current.node) > dist[current.node]+1e-9 {
+			continue
+		}
+		for _, l := range neighbors(current.node) {
+			if candidate := dist[current.node] + l.distKM; candidate < dist[l.to] {
+				dist[l.to] = candidate
+				prev[l.to] = current.node
+				heap.Push(open, frontierItem{node: l.to, priority: candidate + heuristic(l.to)})
+			}
+		}
+	}
+	if math.IsInf(dist[goal], 1) {
+		return nil, 0, false
+	}
+	path := []int{goal}
+	for node := prev[goal]; node >= 0; node = prev[node] {
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, dist[goal], true
+}
+
+// frontierItem 是 A* 开放列表中的顶点及其估计总代价。
+type frontierItem struct {
+	node     int
+	priority float64
+}
+
+// frontier 是按估计总代价排序的最小堆。
+type frontier []frontierItem
+
+func (f frontier) Len() int           { return len(f) }
+func (f frontier) Less(i, j int) bool { return f[i].priority < f[j].priority }
+func (f frontier) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f *frontier) Push(x any)        { *f = append(*f, x.(frontierItem)) }
+func (f *frontier) Pop() any {
+	old := *f
+	item := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,144 @@
+package searoute
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+var (
+	shanghai = Point{Lat: 31.2304, Lon: 121.4737}
+	fukuoka  = Point{Lat: 33.5902, Lon: 130.4017}
+	jeju     = Point{Lat: 33.4996, Lon: 126.5312}
+	seogwipo = Point{Lat: 33.2541, Lon: 126.5601}
+	yokohama = Point{Lat: 35.4437, Lon: 139.6380}
+	hongKong = Point{Lat: 22.3193, Lon: 114.1694}
+)
+
+func TestDistanceKM(t *testing.T) {
+	if got := DistanceKM(Point{Lat: 0, Lon: 0}, Point{Lat: 0, Lon: 1}); math.Abs(got-111.195) > 0.01 {
+		t.Fatalf("one degree on the equator = %.3f km, want ~111.195", got)
+	}
+	if got := DistanceKM(shanghai, fukuoka); math.Abs(got-874) > 10 {
+		t.Fatalf("Shanghai-Fukuoka great-circle = %.1f km, want ~874", got)
+	}
+}
+
+func TestDensifyKeepsEndpointsAndStep(t *testing.T) {
+	points := Densify([]Point{shanghai, fukuoka}, 20)
+	if points[0] != shanghai || points[len(points)-1] != fukuoka {
+		t.Fatalf("densify must keep endpoints, got %v ... %v", points[0], points[len(points)-1])
+	}
+	for i := 1; i < len(points); i++ {
+		if d := DistanceKM(points[i-1], points[i]); d > 20.01 {
+			t.Fatalf("step %d is %.2f km, want <= 20", i, d)
+		}
+	}
+}
+
+// TestBundledNetworkStaysAtSea 保证内置航路点与航段均不触碰陆地轮廓，且网络连通。
+func TestBundledNetworkStaysAtSea(t *testing.T) {
+	r := Default()
+	for i, node := range r.nodes {
+		if r.onLand(node) {
+			t.Errorf("node %s lies on land", bundledNodes[i].id)
+		}
+	}
+	for _, edge := range bundledEdges {
+		a, b := nodeByID(t, edge[0]), nodeByID(t, edge[1])
+		if head, tail, crosses := r.landProfile(a, b); head > 0 || tail > 0 || crosses {
+			t.Errorf("edge %s-%s crosses land", edge[0], edge[1])
+		}
+	}
+
+	seen := map[int]bool{0: true}
+	queue := []int{0}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, l := range r.adj[current] {
+			if !seen[l.to] {
+				seen[l.to] = true
+				queue = append(queue, l.to)
+			}
+		}
+	}
+	if len(seen) != len(r.nodes) {
+		t.Fatalf("network is not connected: reached %d of %d nodes", len(seen), len(r.nodes))
+	}
+}
+
+func TestRouteShanghaiToFukuoka(t *testing.T) {
+	route, err := Default().Route(shanghai, fukuoka)
+	if err != nil {
+		t.Fatalf("Route returned error: %v", err)
+	}
+	if route.Points[0] != shanghai || route.Points[len(route.Points)-1] != fukuoka {
+		t.Fatalf("route must start and end at the ports, got %v", route.Points)
+	}
+	greatCircle := DistanceKM(shanghai, fukuoka)
+	if route.DistanceKM < greatCircle || route.DistanceKM > greatCircle*1.3 {
+		t.Fatalf("route distance %.1f km is implausible against great-circle %.1f km", route.DistanceKM, greatCircle)
+	}
+	assertAvoidsLand(t, route)
+}
+
+func TestRouteGoesAroundIsland(t *testing.T) {
+	route, err := Default().Route(jeju, seogwipo)
+	if err != nil {
+		t.Fatalf("Route returned error: %v", err)
+	}
+	if len(route.Points) < 3 {
+		t.Fatalf("expected a detour around Jeju island, got direct line %v", route.Points)
+	}
+	if route.DistanceKM <= DistanceKM(jeju, seogwipo) {
+		t.Fatalf("detour %.1f km should be longer than the straight line", route.DistanceKM)
+	}
+	assertAvoidsLand(t, route)
+}
+
+func TestRouteAcrossRegions(t *testing.T) {
+	route, err := Default().Route(hongKong, yokohama)
+	if err != nil {
+		t.Fatalf("Route returned error: %v", err)
+	}
+	if route.DistanceKM < DistanceKM(hongKong, yokohama) {
+		t.Fatalf("route distance %.1f km shorter than great-circle", route.DistanceKM)
+	}
+	assertAvoidsLand(t, route)
+}
+
+func TestRouteSamePortAndUnreachable(t *testing.T) {
+	route, err := Default().Route(shanghai, shanghai)
+	if err != nil || route.DistanceKM != 0 {
+		t.Fatalf("same port route = %+v, %v", route, err)
+	}
+	lisbon := Point{Lat: 38.7223, Lon: -9.1393}
+	if _, err := Default().Route(shanghai, lisbon); !errors.Is(err, ErrNoRoute) {
+		t.Fatalf("expected ErrNoRoute outside the bundled network, got %v", err)
+	}
+}
+
+// assertAvoidsLand 断言航线只在起终点的出港段落在陆地轮廓内。
+func assertAvoidsLand(t *testing.T, route *Route) {
+	t.Helper()
+	r := Default()
+	last := len(route.Points) - 1
+	for i := 1; i < len(route.Points); i++ {
+		head, tail, crosses := r.landProfile(route.Points[i-1], route.Points[i])
+		if crosses || (i > 1 && head > 0) || (i < last && tail > 0) {
+			t.Fatalf("leg %d %v -> %v crosses land", i, route.Points[i-1], route.Points[i])
+		}
+	}
+}
+
+func nodeByID(t *testing.T, id string) Point {
+	t.Helper()
+	for _, node := range bundledNodes {
+		if node.id == id {
+			return Point{Lat: node.lat, Lon: node.lon}
+		}
+	}
+	t.Fatalf("unknown node %s", id)
+	return Point{}
+}
@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 追踪数据导出方式。
+const (
+	ExporterNone   = "none"   // 不导出，仅生成 trace_id 并透传上下文
+	ExporterStdout = "stdout" // 以 JSON 写到标准输出
+	ExporterFile   = "file"   // 以 JSON 写到轮转文件
+)
+
+// Config 包含链路追踪的配置参数。
+type Config struct {
+	ServiceName string  // 上报的服务名
+	Exporter    string  // 导出方式：ExporterNone / ExporterStdout / ExporterFile
+	Filename    string  // Exporter 为 file 时的文件路径
+	SampleRatio float64 // 根 span 采样比例（0~1），上游已采样的请求始终跟随上游
+}
+
+// Setup 安装全局 TracerProvider 与 W3C Trace Context / Baggage 传播器，返回关闭函数（刷新未导出的 span）。
+// 无论是否导出，都会为请求生成 trace_id 并沿 traceparent 请求头透传，便于与上下游日志关联。
+func Setup(cfg Config) (func(context.Context) error, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("tracing: build resource: %w", err)
+	}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+
+	var writer io.Writer
+	switch strings.ToLower(strings.TrimSpace(cfg.Exporter)) {
+	case "", ExporterNone:
+	case ExporterStdout:
+		writer = os.Stdout
+	case ExporterFile:
+		writer = &lumberjack.Logger{Filename: cfg.Filename, MaxSize: 100, MaxBackups: 10, MaxAge: 30, Compress: true}
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+	}
+	if writer != nil {
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+		if err != nil {
+			return nil, fmt.Errorf("tracing: create exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Transport 返回把当前 context 中的 trace 上下文写入请求头（traceparent）的 RoundTripper，用于调用下游服务；base 为 nil 时使用 http.DefaultTransport。
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return propagatingTransport{base: base}
+}
+
+type propagatingTransport struct {
+	base http.RoundTripper
+}
+
+func (t propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return t.base.RoundTrip(req)
+}
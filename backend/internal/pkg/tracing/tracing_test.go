@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_FileExporterWritesSpans(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "trace.log")
+	shutdown, err := Setup(Config{ServiceName: "cruise-test", Exporter: ExporterFile, Filename: filename, SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	_, span := otel.Tracer("test").Start(context.Background(), "unit-span")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read trace file failed: %v", err)
+	}
+	if !strings.Contains(string(data), "unit-span") || !strings.Contains(string(data), "cruise-test") {
+		t.Fatalf("expected exported span with service name, got %s", data)
+	}
+}
+
+func TestSetup_RejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup(Config{Exporter: "jaeger"}); err == nil {
+		t.Fatal("expected unknown exporter to be rejected")
+	}
+}
+
+func TestTransport_InjectsTraceparent(t *testing.T) {
+	if _, err := Setup(Config{ServiceName: "cruise-test", SampleRatio: 1}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "outgoing")
+	defer span.End()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if !strings.Contains(got, span.SpanContext().TraceID().String()) {
+		t.Fatalf("expected traceparent with trace id %s, got %q", span.SpanContext().TraceID(), got)
+	}
+	if req.Header.Get("traceparent") != "" {
+		t.Fatal("expected caller request headers to be left untouched")
+	}
+}
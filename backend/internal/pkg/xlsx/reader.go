@@ -0,0 +1,213 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	maxRows     = 1048576  // Excel 工作表最大行数
+	maxColumns  = 16384    // Excel 工作表最大列数（XFD）
+	maxPartSize = 64 << 20 // 单个 XML 部件解压后的大小上限，防止压缩炸弹
+)
+
+// ErrInvalidFile 表示文件不是可读取的 .xlsx 表格。
+var ErrInvalidFile = errors.New("invalid xlsx file")
+
+type workbookDoc struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationshipsDoc struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// richText 兼容纯文本 <t> 与富文本 <r><t> 两种字符串结构。
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (r richText) text() string {
+	if len(r.Runs) == 0 {
+		return r.T
+	}
+	var sb strings.Builder
+	for _, run := range r.Runs {
+		sb.WriteString(run.T)
+	}
+	return sb.String()
+}
+
+type sharedStringsDoc struct {
+	Items []richText `xml:"si"`
+}
+
+type worksheetDoc struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline richText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Read 读取 .xlsx 文件第一个工作表的单元格文本，按行返回；缺失的行与单元格补为空字符串。
+// 支持共享字符串、内联字符串、布尔与数值单元格，公式单元格取缓存值。
+func Read(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared sharedStringsDoc
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: worksheet %s not found", ErrInvalidFile, sheetPath)
+	}
+	var sheet worksheetDoc
+	if err := decodePart(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		index := len(rows)
+		if row.Index > 0 {
+			index = row.Index - 1
+		}
+		if index < len(rows) || index >= maxRows {
+			return nil, fmt.Errorf("%w: invalid row number %d", ErrInvalidFile, row.Index)
+		}
+		for len(rows) < index {
+			rows = append(rows, []string{})
+		}
+		values := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			col := len(values)
+			if cell.Ref != "" {
+				if col, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col < len(values) {
+				return nil, fmt.Errorf("%w: duplicate cell %s", ErrInvalidFile, cell.Ref)
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscan(cell.Value, &idx); err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: invalid shared string in cell %s", ErrInvalidFile, cell.Ref)
+				}
+				value = shared.Items[idx].text()
+			case "inlineStr":
+				value = cell.Inline.text()
+			case "b":
+				value = "false"
+				if cell.Value == "1" {
+					value = "true"
+				}
+			}
+			values = append(values, value)
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// firstSheetPath 通过 workbook.xml 与其关系文件定位第一个工作表，缺失时回退到 sheet1.xml。
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wf, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("%w: workbook not found", ErrInvalidFile)
+	}
+	var workbook workbookDoc
+	if err := decodePart(wf, &workbook); err != nil {
+		return "", err
+	}
+	rf, ok := files["xl/_rels/workbook.xml.rels"]
+	if len(workbook.Sheets) == 0 || !ok {
+		return fallback, nil
+	}
+	var rels relationshipsDoc
+	if err := decodePart(rf, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Items {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+func decodePart(f *zip.File, v any) error {
+	if f.UncompressedSize64 > maxPartSize {
+		return fmt.Errorf("%w: %s is too large", ErrInvalidFile, f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFile, f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex 将单元格引用（如 B12、AA3）转换为从 0 开始的列序号，是 ColumnName 的逆运算。
+func columnIndex(ref string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+		if index > maxColumns {
+			return 0, fmt.Errorf("%w: invalid cell reference %s", ErrInvalidFile, ref)
+		}
+	}
+	if letters == 0 {
+		return 0, fmt.Errorf("%w: invalid cell reference %s", ErrInvalidFile, ref)
+	}
+	return index - 1, nil
+}
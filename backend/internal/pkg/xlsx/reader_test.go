@@ -0,0 +1,90 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReadRoundTripsEncode(t *testing.T) {
+	data, err := Encode("cabins", [][]any{
+		{"code", "deck", "total"},
+		{"A-8001", "8", 2},
+		{"<&>", nil, 1.5},
+	})
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	rows, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	want := [][]string{{"code", "deck", "total"}, {"A-8001", "8", "2"}, {"<&>", "", "1.5"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows = %#v, want %#v", rows, want)
+	}
+}
+
+// buildWorkbook 构造 Excel 风格的文件：共享字符串、富文本、布尔值、跳过的行列与非默认工作表路径。
+func buildWorkbook(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadSharedStringsAndSparseCells(t *testing.T) {
+	data := buildWorkbook(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Deck" sheetId="3" r:id="rId7"/><sheet name="Other" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId7" Target="worksheets/deck.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>code</t></si><si><r><t>has_</t></r><r><t>window</t></r></si><si><t>B-9001</t></si></sst>`,
+		"xl/worksheets/deck.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3"><v>12</v></c><c r="C3" t="b"><v>1</v></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+	})
+	rows, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	want := [][]string{{"code", "", "has_window"}, {}, {"B-9001", "12", "true"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows = %#v, want %#v", rows, want)
+	}
+}
+
+func TestReadRejectsInvalidFiles(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("code,deck")), 9); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("expected ErrInvalidFile for csv content, got %v", err)
+	}
+	data := buildWorkbook(t, map[string]string{
+		"xl/workbook.xml":          `<workbook><sheets/></workbook>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>4</v></c></row></sheetData></worksheet>`,
+	})
+	if _, err := Read(bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("expected ErrInvalidFile for dangling shared string, got %v", err)
+	}
+	if _, err := columnIndex("12"); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("expected ErrInvalidFile for reference without column, got %v", err)
+	}
+	if got, _ := columnIndex("AA3"); got != 26 {
+		t.Fatalf("columnIndex(AA3) = %d, want 26", got)
+	}
+}
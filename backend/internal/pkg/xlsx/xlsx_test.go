@@ -0,0 +1,62 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for index, want := range cases {
+		if got := ColumnName(index); got != want {
+			t.Fatalf("ColumnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestEncodeProducesReadableWorkbook(t *testing.T) {
+	data, err := Encode("报表/明细", [][]any{
+		{"bucket", "gmv_cents", "load_factor"},
+		{"2026-10-01", int64(12000), 0.25},
+		{"<&>", 3, nil},
+	})
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open zip failed: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s failed: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(content)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("expected part %s in workbook", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="报表明细"`) {
+		t.Fatalf("expected sanitized sheet name, got %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="B2"><v>12000</v></c>`) {
+		t.Fatalf("expected numeric cell B2, got %s", sheet)
+	}
+	if !strings.Contains(sheet, `<c r="C2"><v>0.25</v></c>`) {
+		t.Fatalf("expected float cell C2, got %s", sheet)
+	}
+	if !strings.Contains(sheet, "&lt;&amp;&gt;") {
+		t.Fatalf("expected escaped string cell, got %s", sheet)
+	}
+}
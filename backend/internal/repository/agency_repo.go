@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// agencyInactiveBookingStatuses 为不再占用信用额度、也不计入月结账单的订单状态。
+var agencyInactiveBookingStatuses = []string{domain.OrderStatusCancelled, domain.OrderStatusRefunded}
+
+// AgencyRepository 提供分销商渠道的数据访问实现。
+// 分销商账号、API Key 与月结账单跨公司汇总，属于平台级数据：受公司范围限制的员工可以查看分销商名录，
+// 但只能读写自己公司航次上的配额、净价与分销订单。
+type AgencyRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.AgencyRepository = (*AgencyRepository)(nil)
+
+func NewAgencyRepository(db *gorm.DB) *AgencyRepository {
+	return &AgencyRepository{db: db}
+}
+
+func (r *AgencyRepository) ListAgencies(ctx context.Context, keyword string, page, pageSize int) ([]domain.Agency, int64, error) {
+	var items []domain.Agency
+	var total int64
+	q := r.db.WithContext(ctx).Model(&domain.Agency{}).Where("deleted_at IS NULL")
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		q = q.Where("name LIKE ? OR code LIKE ? OR login_name LIKE ?", like, like, like)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := q.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *AgencyRepository) GetAgency(ctx context.Context, id int64) (*domain.Agency, error) {
+	var item domain.Agency
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *AgencyRepository) GetAgencyByLoginName(ctx context.Context, loginName string) (*domain.Agency, error) {
+	var item domain.Agency
+	if err := r.db.WithContext(ctx).Where("login_name = ? AND deleted_at IS NULL", loginName).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// CreateAgency 在同一事务中创建分销商及其渠道下单用户。
+// bookings.user_id 外键指向 users，分销订单统一记在该用户名下；手机号等唯一列保持 NULL。
+func (r *AgencyRepository) CreateAgency(ctx context.Context, agency *domain.Agency) error {
+	if err := ensureAllCompanies(ctx); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var userID int64
+		if err := tx.Raw("INSERT INTO users (nickname, status, created_at, updated_at) VALUES (?, 1, ?, ?) RETURNING id",
+			agency.Name, now, now).Scan(&userID).Error; err != nil {
+			return err
+		}
+		agency.UserID = userID
+		return tx.Create(agency).Error
+	})
+}
+
+func (r *AgencyRepository) UpdateAgency(ctx context.Context, agency *domain.Agency) error {
+	if err := ensureAllCompanies(ctx); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&domain.Agency{}).
+		Where("id = ? AND deleted_at IS NULL", agency.ID).
+		Select("code", "name", "contact_name", "contact_phone", "email", "login_name", "commission_percent", "credit_limit_cents", "status", "updated_at").
+		Updates(agency)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AgencyRepository) UpdateAgencyPassword(ctx context.Context, id int64, passwordHash string) error {
+	if err := ensureAllCompanies(ctx); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&domain.Agency{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{"password_hash": passwordHash, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AgencyRepository) TouchAgencyLogin(ctx context.Context, id int64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.Agency{}).Where("id = ?", id).Update("last_login_at", at).Error
+}
+
+func (r *AgencyRepository) CreateAPIKey(ctx context.Context, key *domain.AgencyAPIKey) error {
+	if err := ensureAllCompanies(ctx); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *AgencyRepository) ListAPIKeys(ctx context.Context, agencyID int64) ([]domain.AgencyAPIKey, error) {
+	var items []domain.AgencyAPIKey
+	if err := r.db.WithContext(ctx).Scopes(scopeAllCompaniesOnly(ctx)).Where("agency_id = ?", agencyID).Order("id desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *AgencyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.AgencyAPIKey, error) {
+	var item domain.AgencyAPIKey
+	if err := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *AgencyRepository) RevokeAPIKey(ctx context.Context, agencyID, keyID int64, at time.Time) error {
+	if err := ensureAllCompanies(ctx); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&domain.AgencyAPIKey{}).
+		Where("id = ? AND agency_id = ? AND revoked_at IS NULL", keyID, agencyID).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AgencyRepository) TouchAPIKey(ctx context.Context, keyID int64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.AgencyAPIKey{}).Where("id = ?", keyID).Update("last_used_at", at).Error
+}
+
+// CreateAllotment 在同一事务中扣减公共库存并写入配额，可售余量不足时返回 domain.ErrInsufficientInventory。
+func (r *AgencyRepository) CreateAllotment(ctx context.Context, allotment *domain.AgencyAllotment) error {
+	if err := ensureSKUInScope(ctx, r.db, allotment.CabinSKUID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inv domain.CabinInventory
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("cabin_sku_id = ?", allotment.CabinSKUID).First(&inv).Error; err != nil {
+			return err
+		}
+		if inv.Total-inv.Locked-inv.Sold < allotment.Quantity {
+			return fmt.Errorf("cabin_sku_id=%d: %w", allotment.CabinSKUID, domain.ErrInsufficientInventory)
+		}
+		if err := adjustInventoryTx(tx, allotment.CabinSKUID, -allotment.Quantity, "agency_allotment"); err != nil {
+			return err
+		}
+		return tx.Create(allotment).Error
+	})
+}
+
+func (r *AgencyRepository) GetAllotment(ctx context.Context, id int64) (*domain.AgencyAllotment, error) {
+	var item domain.AgencyAllotment
+	if err := r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *AgencyRepository) ListAllotments(ctx context.Context, agencyID, voyageID int64) ([]domain.AgencyAllotment, error) {
+	var items []domain.AgencyAllotment
+	q := r.db.WithContext(ctx).Where("agency_id = ?", agencyID).Scopes(scopeByVoyage(ctx, "voyage_id"))
+	if voyageID > 0 {
+		q = q.Where("voyage_id = ?", voyageID)
+	}
+	if err := q.Order("release_at asc, id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ReleaseAllotment 锁定配额行后把未使用数量退回公共库存；已释放的配额返回 0。
+func (r *AgencyRepository) ReleaseAllotment(ctx context.Context, id int64, at time.Time) (int, error) {
+	released := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var allotment domain.AgencyAllotment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(scopeByVoyage(ctx, "voyage_id")).First(&allotment, id).Error; err != nil {
+			return err
+		}
+		if allotment.ReleasedAt != nil {
+			return nil
+		}
+		released = allotment.Quantity - allotment.Used
+		if released > 0 {
+			if err := adjustInventoryTx(tx, allotment.CabinSKUID, released, "agency_allotment_release"); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&domain.AgencyAllotment{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"released_at": at, "updated_at": at}).Error
+	})
+	return released, err
+}
+
+// returnAgencyAllotmentTx 在分销订单取消时归还其占用的配额：配额尚未释放时已用数量减一，舱房留在分销商配额内；
+// 配额已释放时未用部分早已退回公共库存，此时把这间舱房也退回公共库存。没有分销订单记录时不做处理。
+func returnAgencyAllotmentTx(tx *gorm.DB, bookingID int64, at time.Time) error {
+	var booking domain.AgencyBooking
+	err := tx.Where("booking_id = ?", bookingID).First(&booking).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var allotment domain.AgencyAllotment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&allotment, booking.AllotmentID).Error; err != nil {
+		return err
+	}
+	if allotment.ReleasedAt != nil {
+		return adjustInventoryTx(tx, allotment.CabinSKUID, 1, "agency_booking_cancel")
+	}
+	return tx.Model(&domain.AgencyAllotment{}).Where("id = ? AND used > 0", allotment.ID).
+		Updates(map[string]interface{}{"used": gorm.Expr("used - 1"), "updated_at": at}).Error
+}
+
+func (r *AgencyRepository) ListDueAllotmentIDs(ctx context.Context, at time.Time) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := r.db.WithContext(ctx).Model(&domain.AgencyAllotment{}).
+		Where("released_at IS NULL AND release_at <= ?", at).
+		Order("id asc").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// UpsertNetRate 以分销商 + 航次 + 舱型为唯一键写入净价。
+func (r *AgencyRepository) UpsertNetRate(ctx context.Context, rate *domain.AgencyNetRate) error {
+	if err := ensureVoyageInScope(ctx, r.db, rate.VoyageID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "agency_id"}, {Name: "voyage_id"}, {Name: "cabin_type_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"net_price_cents", "updated_at"}),
+	}).Create(rate).Error
+}
+
+func (r *AgencyRepository) ListNetRates(ctx context.Context, agencyID int64) ([]domain.AgencyNetRate, error) {
+	var items []domain.AgencyNetRate
+	if err := r.db.WithContext(ctx).Where("agency_id = ?", agencyID).Scopes(scopeByVoyage(ctx, "voyage_id")).Order("voyage_id asc, cabin_type_id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *AgencyRepository) DeleteNetRate(ctx context.Context, agencyID, id int64) error {
+	return r.db.WithContext(ctx).Where("id = ? AND agency_id = ?", id, agencyID).Scopes(scopeByVoyage(ctx, "voyage_id")).Delete(&domain.AgencyNetRate{}).Error
+}
+
+// LockAgencyTx 以行锁读取分销商，保证同一分销商的信用额度校验串行执行。
+func (r *AgencyRepository) LockAgencyTx(tx *gorm.DB, id int64) (*domain.Agency, error) {
+	var item domain.Agency
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND deleted_at IS NULL", id).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ConsumeAllotmentTx 选取最早释放且仍有余量的配额并占用一间；没有可用配额时返回 domain.ErrAllotmentExhausted。
+func (r *AgencyRepository) ConsumeAllotmentTx(tx *gorm.DB, agencyID, voyageID, skuID int64, at time.Time) (*domain.AgencyAllotment, error) {
+	var allotment domain.AgencyAllotment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("agency_id = ? AND voyage_id = ? AND cabin_sku_id = ?", agencyID, voyageID, skuID).
+		Where("released_at IS NULL AND release_at > ? AND used < quantity", at).
+		Order("release_at asc, id asc").
+		First(&allotment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrAllotmentExhausted
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := tx.Model(&domain.AgencyAllotment{}).
+		Where("id = ? AND used < quantity AND released_at IS NULL", allotment.ID).
+		Updates(map[string]interface{}{"used": gorm.Expr("used + 1"), "updated_at": at})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrAllotmentExhausted
+	}
+	allotment.Used++
+	return &allotment, nil
+}
+
+func (r *AgencyRepository) FindNetRateTx(tx *gorm.DB, agencyID, voyageID, skuID int64) (*domain.AgencyNetRate, error) {
+	var rates []domain.AgencyNetRate
+	err := tx.Table("agency_net_rates AS nr").
+		Select("nr.*").
+		Joins("JOIN cabin_skus s ON s.cabin_type_id = nr.cabin_type_id").
+		Where("nr.agency_id = ? AND nr.voyage_id = ? AND s.id = ?", agencyID, voyageID, skuID).
+		Limit(1).
+		Find(&rates).Error
+	if err != nil || len(rates) == 0 {
+		return nil, err
+	}
+	return &rates[0], nil
+}
+
+func (r *AgencyRepository) OutstandingCreditTx(tx *gorm.DB, agencyID int64) (int64, error) {
+	var total int64
+	err := tx.Table("agency_bookings AS ab").
+		Select("COALESCE(SUM(ab.net_cents), 0)").
+		Joins("JOIN bookings b ON b.id = ab.booking_id").
+		Joins("LEFT JOIN agency_statements st ON st.id = ab.statement_id").
+		Where("ab.agency_id = ? AND b.status NOT IN ?", agencyID, agencyInactiveBookingStatuses).
+		Where("st.id IS NULL OR st.status <> ?", domain.AgencyStatementStatusPaid).
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *AgencyRepository) CreateAgencyBookingTx(tx *gorm.DB, booking *domain.AgencyBooking) error {
+	return tx.Create(booking).Error
+}
+
+func (r *AgencyRepository) OutstandingCredit(ctx context.Context, agencyID int64) (int64, error) {
+	return r.OutstandingCreditTx(r.db.WithContext(ctx), agencyID)
+}
+
+func (r *AgencyRepository) ListAgencyBookings(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyBookingView, int64, error) {
+	var items []domain.AgencyBookingView
+	var total int64
+	q := r.db.WithContext(ctx).Table("agency_bookings AS ab").
+		Joins("JOIN bookings b ON b.id = ab.booking_id").
+		Where("ab.agency_id = ?", agencyID).
+		Scopes(scopeByVoyage(ctx, "b.voyage_id"))
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := q.Select("ab.*, b.voyage_id AS voyage_id, b.cabin_sku_id AS cabin_sku_id, b.status AS status, b.total_cents AS total_cents").
+		Order("ab.id desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// CreateStatement 在同一事务中汇总 [from, to) 内尚未出账的有效分销订单，写入账单并回填订单的 statement_id。
+// 同一分销商同一账期已有账单时返回 domain.ErrAgencyStatementExists。
+func (r *AgencyRepository) CreateStatement(ctx context.Context, statement *domain.AgencyStatement, from, to time.Time) error {
+	if err := ensureAllCompanies(ctx); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exists int64
+		if err := tx.Model(&domain.AgencyStatement{}).
+			Where("agency_id = ? AND period = ?", statement.AgencyID, statement.Period).
+			Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return domain.ErrAgencyStatementExists
+		}
+
+		var rows []domain.AgencyBooking
+		if err := tx.Table("agency_bookings AS ab").
+			Select("ab.*").
+			Joins("JOIN bookings b ON b.id = ab.booking_id").
+			Where("ab.agency_id = ? AND ab.statement_id IS NULL AND ab.created_at >= ? AND ab.created_at < ?", statement.AgencyID, from, to).
+			Where("b.status NOT IN ?", agencyInactiveBookingStatuses).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "ab"}}).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		ids := make([]int64, 0, len(rows))
+		statement.TotalCents = 0
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+			statement.TotalCents += row.NetCents
+		}
+		statement.BookingCount = len(rows)
+		if statement.Status == "" {
+			statement.Status = domain.AgencyStatementStatusIssued
+		}
+		if err := tx.Create(statement).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&domain.AgencyBooking{}).Where("id IN ?", ids).Update("statement_id", statement.ID).Error
+	})
+}
+
+func (r *AgencyRepository) GetStatement(ctx context.Context, id int64) (*domain.AgencyStatement, error) {
+	var item domain.AgencyStatement
+	if err := r.db.WithContext(ctx).Scopes(scopeAllCompaniesOnly(ctx)).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *AgencyRepository) ListStatements(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyStatement, int64, error) {
+	var items []domain.AgencyStatement
+	var total int64
+	q := r.db.WithContext(ctx).Model(&domain.AgencyStatement{}).Where("agency_id = ?", agencyID).Scopes(scopeAllCompaniesOnly(ctx))
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := q.Order("period desc, id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// MarkStatementPaid 以 status = issued 为条件结清账单，否则返回 domain.ErrAgencyStatementNotIssued。
+func (r *AgencyRepository) MarkStatementPaid(ctx context.Context, id int64, at time.Time) error {
+	if err := ensureAllCompanies(ctx); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&domain.AgencyStatement{}).
+		Where("id = ? AND status = ?", id, domain.AgencyStatementStatusIssued).
+		Updates(map[string]interface{}{"status": domain.AgencyStatementStatusPaid, "paid_at": at, "updated_at": at})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAgencyStatementNotIssued
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newAgencyTestRepo 创建 SQLite 内存库并返回分销商仓储实例。
+func newAgencyTestRepo(t *testing.T) *AgencyRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&domain.User{},
+		&domain.Agency{},
+		&domain.AgencyAPIKey{},
+		&domain.AgencyAllotment{},
+		&domain.AgencyNetRate{},
+		&domain.AgencyBooking{},
+		&domain.AgencyStatement{},
+		&domain.CabinSKU{},
+		&domain.CabinInventory{},
+		&domain.InventoryLog{},
+		&domain.Booking{},
+		&domain.OrderStatusLog{},
+	))
+	return NewAgencyRepository(db)
+}
+
+func createTestAgency(t *testing.T, repo *AgencyRepository, login string) *domain.Agency {
+	t.Helper()
+	agency := &domain.Agency{Code: login, Name: "旅行社" + login, LoginName: login, Status: 1, CreditLimitCents: 100000}
+	require.NoError(t, repo.CreateAgency(context.Background(), agency))
+	return agency
+}
+
+func TestAgencyRepository_CreateAgencyCreatesChannelUser(t *testing.T) {
+	repo := newAgencyTestRepo(t)
+	ctx := context.Background()
+
+	first := createTestAgency(t, repo, "ta01")
+	second := createTestAgency(t, repo, "ta02")
+	assert.NotZero(t, first.UserID)
+	assert.NotEqual(t, first.UserID, second.UserID, "每个分销商拥有独立的渠道用户")
+
+	got, err := repo.GetAgencyByLoginName(ctx, "ta02")
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, got.ID)
+
+	list, total, err := repo.ListAgencies(ctx, "ta01", 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, first.ID, list[0].ID)
+
+	assert.True(t, errors.Is(repo.UpdateAgency(ctx, &domain.Agency{ID: 999, Name: "x"}), gorm.ErrRecordNotFound))
+}
+
+func TestAgencyRepository_AllotmentLifecycle(t *testing.T) {
+	repo := newAgencyTestRepo(t)
+	ctx := context.Background()
+	db := repo.db
+	agency := createTestAgency(t, repo, "ta01")
+
+	require.NoError(t, db.Create(&domain.CabinSKU{ID: 1, VoyageID: 10, CabinTypeID: 100, Code: "A1"}).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: 1, Total: 5, Locked: 1, Sold: 1}).Error)
+
+	tooMany := &domain.AgencyAllotment{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Quantity: 4, ReleaseAt: time.Now().UTC().Add(time.Hour)}
+	assert.True(t, errors.Is(repo.CreateAllotment(ctx, tooMany), domain.ErrInsufficientInventory), "只能预留可售余量")
+
+	allotment := &domain.AgencyAllotment{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Quantity: 2, ReleaseAt: time.Now().UTC().Add(time.Hour)}
+	require.NoError(t, repo.CreateAllotment(ctx, allotment))
+	var inv domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 3, inv.Total)
+
+	now := time.Now().UTC()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		consumed, err := repo.ConsumeAllotmentTx(tx, agency.ID, 10, 1, now)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, allotment.ID, consumed.ID)
+		assert.Equal(t, 1, consumed.Used)
+		return nil
+	})
+	require.NoError(t, err)
+
+	due, err := repo.ListDueAllotmentIDs(ctx, now)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = repo.ListDueAllotmentIDs(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{allotment.ID}, due)
+
+	released, err := repo.ReleaseAllotment(ctx, allotment.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released, "仅退回未使用部分")
+	released, err = repo.ReleaseAllotment(ctx, allotment.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, released, "重复释放不再退回库存")
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 4, inv.Total)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := repo.ConsumeAllotmentTx(tx, agency.ID, 10, 1, now)
+		return err
+	})
+	assert.True(t, errors.Is(err, domain.ErrAllotmentExhausted), "已释放配额不可再用")
+}
+
+func TestAgencyRepository_CancelledBookingReturnsAllotment(t *testing.T) {
+	repo := newAgencyTestRepo(t)
+	ctx := context.Background()
+	db := repo.db
+	agency := createTestAgency(t, repo, "ta01")
+	require.NoError(t, db.Create(&domain.CabinSKU{ID: 1, VoyageID: 10, CabinTypeID: 100, Code: "A1"}).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: 1, Total: 5}).Error)
+	allotment := &domain.AgencyAllotment{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Quantity: 2, ReleaseAt: time.Now().UTC().Add(time.Hour)}
+	require.NoError(t, repo.CreateAllotment(ctx, allotment))
+
+	now := time.Now().UTC()
+	bookings := make([]domain.Booking, 2)
+	for i := range bookings {
+		require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+			consumed, err := repo.ConsumeAllotmentTx(tx, agency.ID, 10, 1, now)
+			if err != nil {
+				return err
+			}
+			bookings[i] = domain.Booking{UserID: agency.UserID, VoyageID: 10, CabinSKUID: 1, Status: domain.OrderStatusPendingPayment, Channel: domain.BookingChannelAgency}
+			if err := tx.Create(&bookings[i]).Error; err != nil {
+				return err
+			}
+			return repo.CreateAgencyBookingTx(tx, &domain.AgencyBooking{AgencyID: agency.ID, BookingID: bookings[i].ID, AllotmentID: consumed.ID})
+		}))
+	}
+
+	// 配额未释放：取消的分销订单归还配额，舱房不进入公共库存。
+	orders := NewBookingRepository(db)
+	require.NoError(t, orders.TransitionStatus(ctx, bookings[0].ID, domain.OrderStatusCancelled, 0, "timeout auto close"))
+	var got domain.AgencyAllotment
+	require.NoError(t, db.First(&got, allotment.ID).Error)
+	assert.Equal(t, 1, got.Used)
+	var inv domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 3, inv.Total)
+
+	// 配额已释放：取消的分销订单直接退回公共库存。
+	released, err := repo.ReleaseAllotment(ctx, allotment.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	require.NoError(t, orders.TransitionStatus(ctx, bookings[1].ID, domain.OrderStatusCancelled, 0, "timeout auto close"))
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 5, inv.Total)
+}
+
+func TestAgencyRepository_NetRateUpsert(t *testing.T) {
+	repo := newAgencyTestRepo(t)
+	ctx := context.Background()
+	agency := createTestAgency(t, repo, "ta01")
+	require.NoError(t, repo.db.Create(&domain.CabinSKU{ID: 1, VoyageID: 10, CabinTypeID: 100, Code: "A1"}).Error)
+
+	require.NoError(t, repo.UpsertNetRate(ctx, &domain.AgencyNetRate{AgencyID: agency.ID, VoyageID: 10, CabinTypeID: 100, NetPriceCents: 8000}))
+	require.NoError(t, repo.UpsertNetRate(ctx, &domain.AgencyNetRate{AgencyID: agency.ID, VoyageID: 10, CabinTypeID: 100, NetPriceCents: 7500}))
+	rates, err := repo.ListNetRates(ctx, agency.ID)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, int64(7500), rates[0].NetPriceCents)
+
+	rate, err := repo.FindNetRateTx(repo.db, agency.ID, 10, 1)
+	require.NoError(t, err)
+	require.NotNil(t, rate)
+	assert.Equal(t, int64(7500), rate.NetPriceCents)
+
+	require.NoError(t, repo.DeleteNetRate(ctx, agency.ID, rates[0].ID))
+	rate, err = repo.FindNetRateTx(repo.db, agency.ID, 10, 1)
+	require.NoError(t, err)
+	assert.Nil(t, rate)
+}
+
+func TestAgencyRepository_CreditAndStatements(t *testing.T) {
+	repo := newAgencyTestRepo(t)
+	ctx := context.Background()
+	db := repo.db
+	agency := createTestAgency(t, repo, "ta01")
+
+	sept := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	oct := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	bookings := []domain.Booking{
+		{ID: 1, UserID: agency.UserID, VoyageID: 10, CabinSKUID: 1, Status: domain.OrderStatusCreated, TotalCents: 1000},
+		{ID: 2, UserID: agency.UserID, VoyageID: 10, CabinSKUID: 1, Status: domain.OrderStatusCancelled, TotalCents: 2000},
+		{ID: 3, UserID: agency.UserID, VoyageID: 10, CabinSKUID: 1, Status: domain.OrderStatusCreated, TotalCents: 4000},
+	}
+	require.NoError(t, db.Create(&bookings).Error)
+	require.NoError(t, db.Create(&[]domain.AgencyBooking{
+		{AgencyID: agency.ID, BookingID: 1, AllotmentID: 1, NetCents: 1000, CreatedAt: sept},
+		{AgencyID: agency.ID, BookingID: 2, AllotmentID: 1, NetCents: 2000, CreatedAt: sept},
+		{AgencyID: agency.ID, BookingID: 3, AllotmentID: 1, NetCents: 4000, CreatedAt: oct},
+	}).Error)
+
+	used, err := repo.OutstandingCredit(ctx, agency.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000), used, "已取消订单不占用额度")
+
+	views, total, err := repo.ListAgencyBookings(ctx, agency.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, int64(3), views[0].BookingID)
+	assert.Equal(t, int64(10), views[0].VoyageID)
+	assert.Equal(t, domain.OrderStatusCreated, views[0].Status)
+
+	statement := &domain.AgencyStatement{AgencyID: agency.ID, Period: "2026-09"}
+	require.NoError(t, repo.CreateStatement(ctx, statement, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, statement.BookingCount)
+	assert.Equal(t, int64(1000), statement.TotalCents)
+	assert.Equal(t, domain.AgencyStatementStatusIssued, statement.Status)
+
+	dup := &domain.AgencyStatement{AgencyID: agency.ID, Period: "2026-09"}
+	assert.True(t, errors.Is(repo.CreateStatement(ctx, dup, time.Time{}, time.Now()), domain.ErrAgencyStatementExists))
+
+	require.NoError(t, repo.MarkStatementPaid(ctx, statement.ID, time.Now().UTC()))
+	assert.True(t, errors.Is(repo.MarkStatementPaid(ctx, statement.ID, time.Now().UTC()), domain.ErrAgencyStatementNotIssued))
+
+	used, err = repo.OutstandingCredit(ctx, agency.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4000), used, "结清账单后释放额度")
+
+	list, total, err := repo.ListStatements(ctx, agency.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, domain.AgencyStatementStatusPaid, list[0].Status)
+}
+
+func TestAgencyRepository_APIKeys(t *testing.T) {
+	repo := newAgencyTestRepo(t)
+	ctx := context.Background()
+	agency := createTestAgency(t, repo, "ta01")
+
+	key := &domain.AgencyAPIKey{AgencyID: agency.ID, Name: "OTA", Prefix: "ak_abc", KeyHash: "hash"}
+	require.NoError(t, repo.CreateAPIKey(ctx, key))
+	got, err := repo.GetAPIKeyByPrefix(ctx, "ak_abc")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+
+	assert.True(t, errors.Is(repo.RevokeAPIKey(ctx, agency.ID+1, key.ID, time.Now()), gorm.ErrRecordNotFound), "不能吊销其他分销商的 Key")
+	require.NoError(t, repo.RevokeAPIKey(ctx, agency.ID, key.ID, time.Now()))
+	keys, err := repo.ListAPIKeys(ctx, agency.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].RevokedAt)
+}
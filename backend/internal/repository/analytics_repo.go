@@ -23,13 +23,16 @@ func (r *AnalyticsRepository) startOfTodayExpr() string {
 
 func (r *AnalyticsRepository) TodaySales(ctx context.Context) (int64, error) {
 	var total int64
+	scope, args := rawScopeCondition(ctx, "order_id", bookingIDsInScope)
 	err := r.db.WithContext(ctx).Raw(
-		"SELECT COALESCE(SUM(amount_cents), 0) FROM payments WHERE status = 'paid' AND created_at >= " + r.startOfTodayExpr(),
+		"SELECT COALESCE(SUM(amount_cents), 0) FROM payments WHERE status = 'paid' AND created_at >= "+r.startOfTodayExpr()+scope,
+		args...,
 	).Scan(&total).Error
 	return total, err
 }
 
 func (r *AnalyticsRepository) WeeklyTrend(ctx context.Context) ([]int64, error) {
+	scope, args := rawScopeCondition(ctx, "p.order_id", bookingIDsInScope)
 	query := `
 		SELECT COALESCE(SUM(p.amount_cents), 0)
 		FROM generate_series(
@@ -38,7 +41,7 @@ func (r *AnalyticsRepository) WeeklyTrend(ctx context.Context) ([]int64, error)
 			'1 day'::interval
 		) AS d(day)
 		LEFT JOIN payments p
-			ON DATE(p.created_at) = d.day AND p.status = 'paid'
+			ON DATE(p.created_at) = d.day AND p.status = 'paid'` + scope + `
 		GROUP BY d.day
 		ORDER BY d.day
 	`
@@ -54,13 +57,13 @@ func (r *AnalyticsRepository) WeeklyTrend(ctx context.Context) ([]int64, error)
 			SELECT COALESCE(SUM(p.amount_cents), 0)
 			FROM days d
 			LEFT JOIN payments p
-				ON date(p.created_at) = d.day AND p.status = 'paid'
+				ON date(p.created_at) = d.day AND p.status = 'paid'` + scope + `
 			GROUP BY d.day
 			ORDER BY d.day
 		`
 	}
 
-	rows, err := r.db.WithContext(ctx).Raw(query).Rows()
+	rows, err := r.db.WithContext(ctx).Raw(query, args...).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -79,8 +82,10 @@ func (r *AnalyticsRepository) WeeklyTrend(ctx context.Context) ([]int64, error)
 
 func (r *AnalyticsRepository) TodayOrderCount(ctx context.Context) (int64, error) {
 	var count int64
+	scope, args := rawScopeCondition(ctx, "voyage_id", voyageIDsInScope)
 	err := r.db.WithContext(ctx).Raw(
-		"SELECT COUNT(*) FROM bookings WHERE created_at >= " + r.startOfTodayExpr(),
+		"SELECT COUNT(*) FROM bookings WHERE created_at >= "+r.startOfTodayExpr()+scope,
+		args...,
 	).Scan(&count).Error
 	return count, err
 }
@@ -92,6 +97,9 @@ func (r *AnalyticsRepository) Trend(ctx context.Context, days int) ([]domain.Tre
 	if days > 90 {
 		days = 90
 	}
+	paymentScope, paymentArgs := rawScopeCondition(ctx, "p.order_id", bookingIDsInScope)
+	bookingScope, bookingArgs := rawScopeCondition(ctx, "b.voyage_id", voyageIDsInScope)
+	args := append(paymentArgs, bookingArgs...)
 
 	query := fmt.Sprintf(`
 		WITH RECURSIVE days(day) AS (
@@ -104,11 +112,11 @@ func (r *AnalyticsRepository) Trend(ctx context.Context, days int) ([]domain.Tre
 			COALESCE(SUM(CASE WHEN p.status = 'paid' THEN p.amount_cents ELSE 0 END), 0) AS sales,
 			COALESCE(COUNT(b.id), 0) AS orders
 		FROM days d
-		LEFT JOIN payments p ON date(p.created_at) = d.day
-		LEFT JOIN bookings b ON date(b.created_at) = d.day
+		LEFT JOIN payments p ON date(p.created_at) = d.day%s
+		LEFT JOIN bookings b ON date(b.created_at) = d.day%s
 		GROUP BY d.day
 		ORDER BY d.day
-	`, days-1)
+	`, days-1, paymentScope, bookingScope)
 
 	if r.db.Dialector.Name() != "sqlite" {
 		query = fmt.Sprintf(`
@@ -120,14 +128,14 @@ func (r *AnalyticsRepository) Trend(ctx context.Context, days int) ([]domain.Tre
 				COALESCE(SUM(CASE WHEN p.status = 'paid' THEN p.amount_cents ELSE 0 END), 0) AS sales,
 				COALESCE(COUNT(b.id), 0) AS orders
 			FROM days d
-			LEFT JOIN payments p ON DATE(p.created_at) = d.day
-			LEFT JOIN bookings b ON DATE(b.created_at) = d.day
+			LEFT JOIN payments p ON DATE(p.created_at) = d.day%s
+			LEFT JOIN bookings b ON DATE(b.created_at) = d.day%s
 			GROUP BY d.day
 			ORDER BY d.day
-		`, days-1)
+		`, days-1, paymentScope, bookingScope)
 	}
 
-	rows, err := r.db.WithContext(ctx).Raw(query).Rows()
+	rows, err := r.db.WithContext(ctx).Raw(query, args...).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -148,6 +156,7 @@ func (r *AnalyticsRepository) CabinHotnessRanking(ctx context.Context, limit int
 	if limit <= 0 {
 		limit = 10
 	}
+	scope, args := rawScopeCondition(ctx, "b.voyage_id", voyageIDsInScope)
 	query := `
 		SELECT
 			b.cabin_sku_id,
@@ -156,11 +165,12 @@ func (r *AnalyticsRepository) CabinHotnessRanking(ctx context.Context, limit int
 			COUNT(*) AS view_count
 		FROM bookings b
 		LEFT JOIN cabin_skus cs ON cs.id = b.cabin_sku_id
+		WHERE 1 = 1` + scope + `
 		GROUP BY b.cabin_sku_id, cs.code
 		ORDER BY sold_count DESC, view_count DESC, b.cabin_sku_id ASC
 		LIMIT ?
 	`
-	rows, err := r.db.WithContext(ctx).Raw(query, limit).Rows()
+	rows, err := r.db.WithContext(ctx).Raw(query, append(args, limit)...).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -178,29 +188,32 @@ func (r *AnalyticsRepository) CabinHotnessRanking(ctx context.Context, limit int
 }
 
 func (r *AnalyticsRepository) InventoryOverview(ctx context.Context) (*domain.InventoryOverviewData, error) {
+	scope, args := rawScopeCondition(ctx, "cabin_sku_id", skuIDsInScope)
 	query := `
 		SELECT
 			COUNT(*) AS total_cabins,
 			SUM(CASE WHEN (total - locked - sold) <= alert_threshold AND alert_threshold > 0 THEN 1 ELSE 0 END) AS low_stock_count,
 			SUM(CASE WHEN (total - locked - sold) <= 0 THEN 1 ELSE 0 END) AS out_of_stock_count
 		FROM cabin_inventories
-	`
+		WHERE 1 = 1` + scope
 	data := &domain.InventoryOverviewData{}
-	if err := r.db.WithContext(ctx).Raw(query).Scan(data).Error; err != nil {
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(data).Error; err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
 func (r *AnalyticsRepository) PageViewStats(ctx context.Context) ([]domain.PageViewData, error) {
+	bookingScope, bookingArgs := rawScopeCondition(ctx, "voyage_id", voyageIDsInScope)
+	paymentScope, paymentArgs := rawScopeCondition(ctx, "order_id", bookingIDsInScope)
 	query := `
-		SELECT '/bookings' AS page, COUNT(*) AS views FROM bookings
+		SELECT '/bookings' AS page, COUNT(*) AS views FROM bookings WHERE 1 = 1` + bookingScope + `
 		UNION ALL
-		SELECT '/payments' AS page, COUNT(*) AS views FROM payments
+		SELECT '/payments' AS page, COUNT(*) AS views FROM payments WHERE 1 = 1` + paymentScope + `
 		UNION ALL
-		SELECT '/cabins' AS page, COUNT(DISTINCT cabin_sku_id) AS views FROM bookings
-	`
-	rows, err := r.db.WithContext(ctx).Raw(query).Rows()
+		SELECT '/cabins' AS page, COUNT(DISTINCT cabin_sku_id) AS views FROM bookings WHERE 1 = 1` + bookingScope
+	args := append(append(append([]interface{}{}, bookingArgs...), paymentArgs...), bookingArgs...)
+	rows, err := r.db.WithContext(ctx).Raw(query, args...).Rows()
 	if err != nil {
 		return nil, err
 	}
@@ -216,3 +229,90 @@ func (r *AnalyticsRepository) PageViewStats(ctx context.Context) ([]domain.PageV
 	}
 	return result, rows.Err()
 }
+
+// ListBookingFacts 返回时间区间内的订单事实行，并关联公司/邮轮/航次/舱型大类维度。
+func (r *AnalyticsRepository) ListBookingFacts(ctx context.Context, filter domain.AnalyticsFactFilter) ([]domain.AnalyticsBookingFact, error) {
+	query := r.db.WithContext(ctx).
+		Table("bookings AS b").
+		Select(`
+			b.id AS booking_id,
+			b.created_at AS created_at,
+			b.status AS status,
+			b.total_cents AS total_cents,
+			COALESCE(b.channel, '') AS channel,
+			COALESCE(cc.id, 0) AS company_id,
+			COALESCE(cc.name, '') AS company_name,
+			COALESCE(c.id, 0) AS cruise_id,
+			COALESCE(c.name, '') AS cruise_name,
+			b.voyage_id AS voyage_id,
+			COALESCE(v.code, '') AS voyage_code,
+			COALESCE(cat.id, 0) AS category_id,
+			COALESCE(cat.name, '') AS category_name
+		`).
+		Joins("LEFT JOIN voyages v ON v.id = b.voyage_id").
+		Joins("LEFT JOIN cruises c ON c.id = v.cruise_id").
+		Joins("LEFT JOIN cruise_companies cc ON cc.id = c.company_id").
+		Joins("LEFT JOIN cabin_skus s ON s.id = b.cabin_sku_id").
+		Joins("LEFT JOIN cabin_types ct ON ct.id = s.cabin_type_id").
+		Joins("LEFT JOIN cabin_type_categories cat ON cat.id = ct.category_id").
+		Where("b.created_at >= ? AND b.created_at < ?", filter.From, filter.To).
+		Scopes(scopeByCompany(ctx, "c.company_id"))
+
+	if filter.CompanyID > 0 {
+		query = query.Where("c.company_id = ?", filter.CompanyID)
+	}
+	if filter.CruiseID > 0 {
+		query = query.Where("v.cruise_id = ?", filter.CruiseID)
+	}
+	if filter.VoyageID > 0 {
+		query = query.Where("b.voyage_id = ?", filter.VoyageID)
+	}
+	if filter.CategoryID > 0 {
+		query = query.Where("ct.category_id = ?", filter.CategoryID)
+	}
+	if filter.Channel != "" {
+		query = query.Where("b.channel = ?", filter.Channel)
+	}
+
+	facts := make([]domain.AnalyticsBookingFact, 0)
+	if err := query.Order("b.created_at ASC, b.id ASC").Scan(&facts).Error; err != nil {
+		return nil, err
+	}
+	return facts, nil
+}
+
+// ListCapacityFacts 返回指定航次按舱型大类汇总的库存总量，供上座率计算使用。
+func (r *AnalyticsRepository) ListCapacityFacts(ctx context.Context, voyageIDs []int64) ([]domain.AnalyticsCapacityFact, error) {
+	facts := make([]domain.AnalyticsCapacityFact, 0)
+	if len(voyageIDs) == 0 {
+		return facts, nil
+	}
+	err := r.db.WithContext(ctx).
+		Table("cabin_skus AS s").
+		Select(`
+			COALESCE(cc.id, 0) AS company_id,
+			COALESCE(cc.name, '') AS company_name,
+			COALESCE(c.id, 0) AS cruise_id,
+			COALESCE(c.name, '') AS cruise_name,
+			s.voyage_id AS voyage_id,
+			COALESCE(v.code, '') AS voyage_code,
+			COALESCE(cat.id, 0) AS category_id,
+			COALESCE(cat.name, '') AS category_name,
+			COALESCE(SUM(inv.total), 0) AS capacity
+		`).
+		Joins("JOIN cabin_inventories inv ON inv.cabin_sku_id = s.id").
+		Joins("LEFT JOIN voyages v ON v.id = s.voyage_id").
+		Joins("LEFT JOIN cruises c ON c.id = v.cruise_id").
+		Joins("LEFT JOIN cruise_companies cc ON cc.id = c.company_id").
+		Joins("LEFT JOIN cabin_types ct ON ct.id = s.cabin_type_id").
+		Joins("LEFT JOIN cabin_type_categories cat ON cat.id = ct.category_id").
+		Where("s.voyage_id IN ?", voyageIDs).
+		Scopes(scopeByCompany(ctx, "c.company_id")).
+		Group("cc.id, cc.name, c.id, c.name, s.voyage_id, v.code, cat.id, cat.name").
+		Order("s.voyage_id ASC, cat.id ASC").
+		Scan(&facts).Error
+	if err != nil {
+		return nil, err
+	}
+	return facts, nil
+}
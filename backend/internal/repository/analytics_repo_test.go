@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/stretchr/testify/assert"
@@ -142,3 +143,57 @@ func TestAnalyticsRepository_InventoryOverviewAndPageViewStats(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, stats, 3)
 }
+
+func TestAnalyticsRepository_ListBookingFactsAndCapacity(t *testing.T) {
+	repo := newAnalyticsTestRepo(t)
+	db := repo.db
+	ctx := context.Background()
+
+	require.NoError(t, db.AutoMigrate(&domain.CruiseCompany{}, &domain.Cruise{}, &domain.Voyage{}, &domain.CabinSKU{}, &domain.CabinType{}, &domain.CabinTypeCategory{}, &domain.CabinInventory{}))
+	require.NoError(t, db.Create(&domain.CruiseCompany{ID: 1, Name: "皇家加勒比"}).Error)
+	require.NoError(t, db.Create(&domain.Cruise{ID: 2, CompanyID: 1, Name: "海洋光谱号"}).Error)
+	require.NoError(t, db.Create(&domain.Voyage{ID: 3, CruiseID: 2, Code: "SP20260301"}).Error)
+	require.NoError(t, db.Create(&domain.CabinTypeCategory{ID: 4, Name: "阳台", Code: "balcony"}).Error)
+	require.NoError(t, db.Create(&domain.CabinType{ID: 5, CruiseID: 2, CategoryID: 4, Name: "阳台房"}).Error)
+	require.NoError(t, db.Create(&domain.CabinSKU{ID: 6, VoyageID: 3, CabinTypeID: 5, Code: "B6001"}).Error)
+	require.NoError(t, db.Create(&domain.CabinSKU{ID: 7, VoyageID: 3, CabinTypeID: 5, Code: "B6002"}).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: 6, Total: 2}).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: 7, Total: 3}).Error)
+
+	inRange := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&domain.Booking{UserID: 1, VoyageID: 3, CabinSKUID: 6, Status: "paid", TotalCents: 5000, Channel: "agency", CreatedAt: inRange, UpdatedAt: inRange}).Error)
+	require.NoError(t, db.Create(&domain.Booking{UserID: 2, VoyageID: 3, CabinSKUID: 7, Status: "created", TotalCents: 6000, CreatedAt: inRange.Add(time.Hour), UpdatedAt: inRange}).Error)
+	require.NoError(t, db.Create(&domain.Booking{UserID: 3, VoyageID: 3, CabinSKUID: 7, Status: "paid", TotalCents: 7000, CreatedAt: inRange.AddDate(0, 0, 5), UpdatedAt: inRange}).Error)
+
+	facts, err := repo.ListBookingFacts(ctx, domain.AnalyticsFactFilter{
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Len(t, facts, 2)
+	assert.Equal(t, "皇家加勒比", facts[0].CompanyName)
+	assert.Equal(t, "海洋光谱号", facts[0].CruiseName)
+	assert.Equal(t, "SP20260301", facts[0].VoyageCode)
+	assert.Equal(t, int64(4), facts[0].CategoryID)
+	assert.Equal(t, "agency", facts[0].Channel)
+	assert.Equal(t, domain.BookingChannelDirect, facts[1].Channel)
+	assert.True(t, facts[0].CreatedAt.Equal(inRange))
+
+	filtered, err := repo.ListBookingFacts(ctx, domain.AnalyticsFactFilter{
+		From:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		CompanyID: 1, CategoryID: 4, Channel: "agency",
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+
+	capacity, err := repo.ListCapacityFacts(ctx, []int64{3})
+	require.NoError(t, err)
+	require.Len(t, capacity, 1)
+	assert.Equal(t, int64(5), capacity[0].Capacity)
+	assert.Equal(t, "阳台", capacity[0].CategoryName)
+
+	empty, err := repo.ListCapacityFacts(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
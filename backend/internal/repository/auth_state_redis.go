@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisIncrScript 累加计数并仅在首次创建时设置过期时间，保证计数与 TTL 原子生效。
+var redisIncrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// RedisAuthStateStore 基于 Redis 实现认证风控状态存储，过期由 Redis TTL 负责，忽略调用方传入的 now。
+type RedisAuthStateStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ domain.AuthStateStore = (*RedisAuthStateStore)(nil)
+
+// NewRedisAuthStateStore 创建 Redis 风控状态存储，prefix 为所有键的公共前缀。
+func NewRedisAuthStateStore(client redis.UniversalClient, prefix string) *RedisAuthStateStore {
+	return &RedisAuthStateStore{client: client, prefix: prefix}
+}
+
+// SetNX 仅当键不存在时写入（SET NX PX）。
+func (s *RedisAuthStateStore) SetNX(ctx context.Context, key string, ttl time.Duration, _ time.Time) (bool, error) {
+	return s.client.SetNX(ctx, s.prefix+key, 1, redisTTL(ttl)).Result()
+}
+
+// Set 写入或覆盖键并重置过期时间。
+func (s *RedisAuthStateStore) Set(ctx context.Context, key string, ttl time.Duration, _ time.Time) error {
+	return s.client.Set(ctx, s.prefix+key, 1, redisTTL(ttl)).Err()
+}
+
+// SetValue 写入或覆盖带取值的键并重置过期时间。
+func (s *RedisAuthStateStore) SetValue(ctx context.Context, key, value string, ttl time.Duration, _ time.Time) error {
+	return s.client.Set(ctx, s.prefix+key, value, redisTTL(ttl)).Err()
+}
+
+// GetValue 返回键的取值，键不存在时第二个返回值为 false。
+func (s *RedisAuthStateStore) GetValue(ctx context.Context, key string, _ time.Time) (string, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Exists 判断键是否存在。
+func (s *RedisAuthStateStore) Exists(ctx context.Context, key string, _ time.Time) (bool, error) {
+	n, err := s.client.Exists(ctx, s.prefix+key).Result()
+	return n > 0, err
+}
+
+// Incr 原子累加计数，首次创建时设置过期时间。
+func (s *RedisAuthStateStore) Incr(ctx context.Context, key string, ttl time.Duration, _ time.Time) (int64, error) {
+	return redisIncrScript.Run(ctx, s.client, []string{s.prefix + key}, redisTTL(ttl).Milliseconds()).Int64()
+}
+
+// Delete 删除指定键，未传键时不做任何操作。
+func (s *RedisAuthStateStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = s.prefix + key
+	}
+	return s.client.Del(ctx, prefixed...).Err()
+}
+
+// PurgeExpired 无需执行任何操作：Redis 会自行淘汰过期键。
+func (s *RedisAuthStateStore) PurgeExpired(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+// redisTTL 把过期时间规整为至少 1 毫秒，避免 0 被 Redis 客户端解释为永不过期。
+func redisTTL(ttl time.Duration) time.Duration {
+	if ttl < time.Millisecond {
+		return time.Millisecond
+	}
+	return ttl
+}
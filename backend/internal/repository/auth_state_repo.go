@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuthStateRepository 基于 auth_states 表实现认证风控状态存储，过期判断以调用方传入的 now 为准。
+type AuthStateRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.AuthStateStore = (*AuthStateRepository)(nil)
+
+// NewAuthStateRepository 创建认证风控状态仓储实例。
+func NewAuthStateRepository(db *gorm.DB) *AuthStateRepository {
+	return &AuthStateRepository{db: db}
+}
+
+// SetNX 仅当键不存在或已过期时写入；已过期的记录在同一条 upsert 中被覆盖，多实例并发时只有一个写入成功。
+func (r *AuthStateRepository) SetNX(ctx context.Context, key string, ttl time.Duration, now time.Time) (bool, error) {
+	state := domain.AuthState{Key: key, ExpiresAt: now.Add(ttl), UpdatedAt: now}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "state_key"}},
+			DoUpdates: clause.Assignments(map[string]any{"count": 0, "state_value": "", "expires_at": state.ExpiresAt, "updated_at": now}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "auth_states.expires_at <= ?", Vars: []any{now}}}},
+		}).
+		Create(&state)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Set 写入或覆盖键并重置计数与过期时间。
+func (r *AuthStateRepository) Set(ctx context.Context, key string, ttl time.Duration, now time.Time) error {
+	state := domain.AuthState{Key: key, ExpiresAt: now.Add(ttl), UpdatedAt: now}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "state_key"}},
+			DoUpdates: clause.Assignments(map[string]any{"count": 0, "state_value": "", "expires_at": state.ExpiresAt, "updated_at": now}),
+		}).
+		Create(&state).Error
+}
+
+// SetValue 写入或覆盖带取值的键并重置计数与过期时间。
+func (r *AuthStateRepository) SetValue(ctx context.Context, key, value string, ttl time.Duration, now time.Time) error {
+	state := domain.AuthState{Key: key, Value: value, ExpiresAt: now.Add(ttl), UpdatedAt: now}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "state_key"}},
+			DoUpdates: clause.Assignments(map[string]any{"count": 0, "state_value": value, "expires_at": state.ExpiresAt, "updated_at": now}),
+		}).
+		Create(&state).Error
+}
+
+// GetValue 返回键在 now 时刻的取值。
+func (r *AuthStateRepository) GetValue(ctx context.Context, key string, now time.Time) (string, bool, error) {
+	var states []domain.AuthState
+	err := r.db.WithContext(ctx).Where("state_key = ? AND expires_at > ?", key, now).Limit(1).Find(&states).Error
+	if err != nil || len(states) == 0 {
+		return "", false, err
+	}
+	return states[0].Value, true, nil
+}
+
+// Exists 判断键在 now 时刻是否仍有效。
+func (r *AuthStateRepository) Exists(ctx context.Context, key string, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.AuthState{}).
+		Where("state_key = ? AND expires_at > ?", key, now).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Incr 在事务中原子地累加计数并返回新值；已过期的记录从 1 重新计数并使用新的过期时间。
+func (r *AuthStateRepository) Incr(ctx context.Context, key string, ttl time.Duration, now time.Time) (int64, error) {
+	var state domain.AuthState
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expiresAt := now.Add(ttl)
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "state_key"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "count"}, Value: gorm.Expr("CASE WHEN auth_states.expires_at <= ? THEN 1 ELSE auth_states.count + 1 END", now)},
+				{Column: clause.Column{Name: "expires_at"}, Value: gorm.Expr("CASE WHEN auth_states.expires_at <= ? THEN ? ELSE auth_states.expires_at END", now, expiresAt)},
+				{Column: clause.Column{Name: "updated_at"}, Value: now},
+			},
+		}).Create(&domain.AuthState{Key: key, Count: 1, ExpiresAt: expiresAt, UpdatedAt: now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("state_key = ?", key).First(&state).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return state.Count, nil
+}
+
+// Delete 删除指定键，未传键时不做任何操作。
+func (r *AuthStateRepository) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("state_key IN ?", keys).Delete(&domain.AuthState{}).Error
+}
+
+// PurgeExpired 清理已过期的风控状态，返回删除条数。
+func (r *AuthStateRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.AuthState{})
+	return result.RowsAffected, result.Error
+}
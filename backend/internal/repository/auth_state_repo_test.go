@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newAuthStateTestRepo(t *testing.T) *AuthStateRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.AuthState{}))
+	return NewAuthStateRepository(db)
+}
+
+func TestAuthStateRepository_TTLSemantics(t *testing.T) {
+	repo := newAuthStateTestRepo(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	ok, err := repo.SetNX(ctx, "sms:sent:138", time.Minute, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.SetNX(ctx, "sms:sent:138", time.Minute, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.False(t, ok, "未过期的键不能被 SetNX 覆盖")
+	ok, err = repo.SetNX(ctx, "sms:sent:138", time.Minute, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok, "过期的键可以被 SetNX 重新写入")
+
+	require.NoError(t, repo.Set(ctx, "sms:lock:138", time.Minute, now))
+	exists, err := repo.Exists(ctx, "sms:lock:138", now.Add(59*time.Second))
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = repo.Exists(ctx, "sms:lock:138", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	for want := int64(1); want <= 3; want++ {
+		n, err := repo.Incr(ctx, "sms:fail:138", time.Minute, now.Add(time.Duration(want)*time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	n, err := repo.Incr(ctx, "sms:fail:138", time.Minute, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n, "过期计数器从 1 重新开始")
+
+	require.NoError(t, repo.Delete(ctx, "sms:fail:138", "missing"))
+	require.NoError(t, repo.Delete(ctx))
+	exists, err = repo.Exists(ctx, "sms:fail:138", now)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	purged, err := repo.PurgeExpired(ctx, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, purged)
+}
+
+func TestRedisAuthStateStore_TTLSemantics(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	store := NewRedisAuthStateStore(client, "test:auth:")
+	ctx := context.Background()
+	now := time.Now()
+
+	ok, err := store.SetNX(ctx, "sms:sent:138", time.Minute, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.SetNX(ctx, "sms:sent:138", time.Minute, now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.True(t, server.Exists("test:auth:sms:sent:138"), "键带统一前缀")
+
+	for want := int64(1); want <= 3; want++ {
+		n, err := store.Incr(ctx, "sms:fail:138", 10*time.Second, now)
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	assert.Equal(t, 10*time.Second, server.TTL("test:auth:sms:fail:138"), "累加不会延长首次设置的过期时间")
+
+	require.NoError(t, store.Set(ctx, "bind:auth:1", 0, now))
+	server.FastForward(time.Minute)
+	for _, key := range []string{"sms:sent:138", "sms:fail:138", "bind:auth:1"} {
+		exists, err := store.Exists(ctx, key, now)
+		require.NoError(t, err)
+		assert.False(t, exists, key)
+	}
+
+	require.NoError(t, store.Set(ctx, "sms:lock:138", time.Minute, now))
+	require.NoError(t, store.Delete(ctx, "sms:lock:138"))
+	exists, err := store.Exists(ctx, "sms:lock:138", now)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	purged, err := store.PurgeExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+}
+
+func TestAuthStateRepository_Values(t *testing.T) {
+	repo := newAuthStateTestRepo(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.SetValue(ctx, "sms:value:138", "digest-1", time.Minute, now))
+	value, ok, err := repo.GetValue(ctx, "sms:value:138", now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "digest-1", value)
+
+	require.NoError(t, repo.SetValue(ctx, "sms:value:138", "digest-2", time.Minute, now.Add(30*time.Second)))
+	value, ok, err = repo.GetValue(ctx, "sms:value:138", now.Add(80*time.Second))
+	require.NoError(t, err)
+	assert.True(t, ok, "重新写入会刷新过期时间")
+	assert.Equal(t, "digest-2", value)
+
+	_, ok, err = repo.GetValue(ctx, "sms:value:138", now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.False(t, ok, "过期的值不可读")
+
+	require.NoError(t, repo.Set(ctx, "sms:value:138", time.Minute, now))
+	value, ok, err = repo.GetValue(ctx, "sms:value:138", now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, value, "Set 覆盖时清空旧值")
+}
+
+func TestRedisAuthStateStore_Values(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	store := NewRedisAuthStateStore(client, "test:auth:")
+	ctx := context.Background()
+	now := time.Now()
+
+	_, ok, err := store.GetValue(ctx, "sms:value:138", now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.SetValue(ctx, "sms:value:138", "digest-1", time.Minute, now))
+	value, ok, err := store.GetValue(ctx, "sms:value:138", now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "digest-1", value)
+	assert.Equal(t, time.Minute, server.TTL("test:auth:sms:value:138"))
+
+	server.FastForward(time.Minute)
+	_, ok, err = store.GetValue(ctx, "sms:value:138", now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
 	"gorm.io/gorm"
 )
 
@@ -22,8 +24,11 @@ func NewBookingRepository(db *gorm.DB) *BookingRepository {
 	return &BookingRepository{db: db}
 }
 
-// Create 写入一条预订记录。
+// Create 写入一条预订记录，受限员工只能为授权公司的航次下单。
 func (r *BookingRepository) Create(ctx context.Context, b *domain.Booking) error {
+	if err := ensureVoyageInScope(ctx, r.db, b.VoyageID); err != nil {
+		return err
+	}
 	err := r.db.WithContext(ctx).Create(b).Error
 	return err
 }
@@ -45,11 +50,12 @@ func (r *BookingRepository) UpdateStatus(ctx context.Context, id int64, status s
 	return r.TransitionStatus(ctx, id, status, 0, "")
 }
 
-// TransitionStatus 通过统一入口变更订单状态，并在同一事务写入状态日志。
+// TransitionStatus 通过统一入口变更订单状态，并在同一事务写入状态日志；分销订单取消时同时归还分销商配额。
 func (r *BookingRepository) TransitionStatus(ctx context.Context, id int64, status string, operatorID int64, remark string) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	var from string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var current domain.Booking
-		if err := tx.First(&current, id).Error; err != nil {
+		if err := tx.Scopes(scopeByVoyage(ctx, "voyage_id")).First(&current, id).Error; err != nil {
 			return err
 		}
 		if !current.CanTransitionTo(status) {
@@ -58,6 +64,11 @@ func (r *BookingRepository) TransitionStatus(ctx context.Context, id int64, stat
 		if err := tx.Model(&domain.Booking{}).Where("id = ?", id).Update("status", status).Error; err != nil {
 			return err
 		}
+		if status == domain.OrderStatusCancelled && current.Channel == domain.BookingChannelAgency {
+			if err := returnAgencyAllotmentTx(tx, id, time.Now()); err != nil {
+				return err
+			}
+		}
 		if remark == "" {
 			remark = "status transition"
 		}
@@ -71,8 +82,14 @@ func (r *BookingRepository) TransitionStatus(ctx context.Context, id int64, stat
 		if err := tx.Create(log).Error; err != nil {
 			return err
 		}
+		from = current.Status
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	metrics.OrderTransitions.WithLabelValues(from, status).Inc()
+	return nil
 }
 
 // List 分页查询订单列表。
@@ -84,11 +101,12 @@ func (r *BookingRepository) List(ctx context.Context, page, pageSize int) ([]dom
 		pageSize = 20
 	}
 	var total int64
-	if err := r.db.WithContext(ctx).Model(&domain.Booking{}).Count(&total).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&domain.Booking{}).Scopes(scopeByVoyage(ctx, "voyage_id")).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	var items []domain.Booking
 	err := r.db.WithContext(ctx).
+		Scopes(scopeByVoyage(ctx, "voyage_id")).
 		Order("id DESC").
 		Offset((page - 1) * pageSize).
 		Limit(pageSize).
@@ -99,7 +117,7 @@ func (r *BookingRepository) List(ctx context.Context, page, pageSize int) ([]dom
 // GetByID 查询单条订单。
 func (r *BookingRepository) GetByID(ctx context.Context, id int64) (*domain.Booking, error) {
 	var b domain.Booking
-	if err := r.db.WithContext(ctx).First(&b, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).First(&b, id).Error; err != nil {
 		return nil, err
 	}
 	return &b, nil
@@ -107,7 +125,7 @@ func (r *BookingRepository) GetByID(ctx context.Context, id int64) (*domain.Book
 
 // Delete 删除订单。
 func (r *BookingRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.Booking{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).Delete(&domain.Booking{}, id))
 }
 
 type BookingFilter struct {
@@ -163,7 +181,8 @@ func bookingFilterBaseQuery(db *gorm.DB, ctx context.Context) *gorm.DB {
 		Model(&domain.Booking{}).
 		Joins("LEFT JOIN users ON users.id = bookings.user_id").
 		Joins("LEFT JOIN voyages ON voyages.id = bookings.voyage_id").
-		Joins("LEFT JOIN cruises ON cruises.id = voyages.cruise_id")
+		Joins("LEFT JOIN cruises ON cruises.id = voyages.cruise_id").
+		Scopes(scopeByCompany(ctx, "cruises.company_id"))
 }
 
 func (r *BookingRepository) ListWithFilter(ctx context.Context, filter BookingFilter, page, pageSize int) ([]domain.Booking, int64, error) {
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -19,6 +20,13 @@ func NewCabinHoldRepository(db *gorm.DB) *CabinHoldRepository {
 	return &CabinHoldRepository{db: db}
 }
 
+// CountActive 统计当前时刻仍有效的占座数。
+func (r *CabinHoldRepository) CountActive(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.CabinHold{}).Where("expires_at > ?", now).Count(&count).Error
+	return count, err
+}
+
 // ExistsActiveHoldTx 判断指定用户在当前时刻是否存在有效占座。
 func (r *CabinHoldRepository) ExistsActiveHoldTx(tx *gorm.DB, skuID, userID int64, now time.Time) (bool, error) {
 	db := tx
@@ -44,8 +52,11 @@ func (r *CabinHoldRepository) CreateHoldTx(tx *gorm.DB, hold *domain.CabinHold)
 
 // AdjustInventoryTx 在事务中按增量调整库存并写入库存日志。
 func (r *CabinHoldRepository) AdjustInventoryTx(tx *gorm.DB, skuID int64, delta int, reason string) error {
-	db := tx
+	return adjustInventoryTx(tx, skuID, delta, reason)
+}
 
+// adjustInventoryTx 锁定库存行后按增量调整总量并写入库存日志，供占座与分销配额共用。
+func adjustInventoryTx(db *gorm.DB, skuID int64, delta int, reason string) error {
 	var inv domain.CabinInventory
 	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("cabin_sku_id = ?", skuID).First(&inv).Error; err != nil {
 		return err
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CabinImportRepository 实现 domain.CabinImportRepository，负责舱房 SKU 与库存的批量写入。
+type CabinImportRepository struct{ db *gorm.DB }
+
+var _ domain.CabinImportRepository = (*CabinImportRepository)(nil)
+
+// NewCabinImportRepository 创建舱房批量导入仓储。
+func NewCabinImportRepository(db *gorm.DB) *CabinImportRepository {
+	return &CabinImportRepository{db: db}
+}
+
+// FindSKUsByCodes 按舱房编号批量查询 SKU。
+func (r *CabinImportRepository) FindSKUsByCodes(ctx context.Context, codes []string) ([]domain.CabinSKU, error) {
+	out := make([]domain.CabinSKU, 0)
+	if len(codes) == 0 {
+		return out, nil
+	}
+	return out, r.db.WithContext(ctx).Where("code IN ?", codes).Scopes(scopeByVoyage(ctx, "voyage_id")).Find(&out).Error
+}
+
+// ListInventoriesBySKUs 批量查询舱房库存。
+func (r *CabinImportRepository) ListInventoriesBySKUs(ctx context.Context, skuIDs []int64) ([]domain.CabinInventory, error) {
+	out := make([]domain.CabinInventory, 0)
+	if len(skuIDs) == 0 {
+		return out, nil
+	}
+	return out, r.db.WithContext(ctx).Where("cabin_sku_id IN ?", skuIDs).Scopes(scopeBySKU(ctx, "cabin_sku_id")).Find(&out).Error
+}
+
+// ApplyImport 在单个事务内新增或覆盖舱房 SKU 与库存。
+// 新增的下架舱房在插入后回写状态，避免被列默认值覆盖；已有舱房的库存行加锁后校验并更新。
+// 任一舱房或其目标航次不在数据范围内时整体返回 gorm.ErrRecordNotFound。
+func (r *CabinImportRepository) ApplyImport(ctx context.Context, items []domain.CabinImportItem, reason string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			item := &items[i]
+			if err := ensureVoyageInScope(ctx, tx, item.SKU.VoyageID); err != nil {
+				return err
+			}
+			if item.SKU.ID != 0 {
+				if err := ensureSKUInScope(ctx, tx, item.SKU.ID); err != nil {
+					return err
+				}
+			}
+			if item.SKU.ID == 0 {
+				if err := createImportedSKU(tx, item); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Save(&item.SKU).Error; err != nil {
+				return err
+			}
+			if err := updateImportedInventory(tx, item, reason); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createImportedSKU(tx *gorm.DB, item *domain.CabinImportItem) error {
+	offShelf := item.SKU.Status == 0
+	if err := tx.Create(&item.SKU).Error; err != nil {
+		return err
+	}
+	if offShelf {
+		if err := tx.Model(&item.SKU).Update("status", 0).Error; err != nil {
+			return err
+		}
+	}
+	inventory := domain.CabinInventory{CabinSKUID: item.SKU.ID}
+	if item.InventoryTotal != nil {
+		inventory.Total = *item.InventoryTotal
+	}
+	if item.AlertThreshold != nil {
+		inventory.AlertThreshold = *item.AlertThreshold
+	}
+	return tx.Create(&inventory).Error
+}
+
+// updateImportedInventory 覆盖已有舱房的库存总量与预警阈值；库存行不存在时补建。
+func updateImportedInventory(tx *gorm.DB, item *domain.CabinImportItem, reason string) error {
+	if item.InventoryTotal == nil && item.AlertThreshold == nil {
+		return nil
+	}
+	var inventory domain.CabinInventory
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("cabin_sku_id = ?", item.SKU.ID).First(&inventory).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		inventory = domain.CabinInventory{CabinSKUID: item.SKU.ID}
+		if err := tx.Create(&inventory).Error; err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	updates := map[string]any{}
+	if item.AlertThreshold != nil {
+		updates["alert_threshold"] = *item.AlertThreshold
+	}
+	delta := 0
+	if item.InventoryTotal != nil {
+		if *item.InventoryTotal < inventory.Locked+inventory.Sold {
+			return domain.ErrCabinInventoryBelowCommitted
+		}
+		delta = *item.InventoryTotal - inventory.Total
+		updates["total"] = *item.InventoryTotal
+	}
+	if err := tx.Model(&domain.CabinInventory{}).Where("id = ?", inventory.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	if delta == 0 {
+		return nil
+	}
+	return tx.Create(&domain.InventoryLog{CabinSKUID: item.SKU.ID, Change: delta, Reason: reason}).Error
+}
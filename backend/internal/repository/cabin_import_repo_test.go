@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newCabinImportTestRepo(t *testing.T) (*CabinImportRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.CabinSKU{}, &domain.CabinInventory{}, &domain.InventoryLog{}))
+	return NewCabinImportRepository(db), db
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestCabinImportRepository_ApplyImport(t *testing.T) {
+	repo, db := newCabinImportTestRepo(t)
+	ctx := context.Background()
+	existing := domain.CabinSKU{VoyageID: 5, CabinTypeID: 31, Code: "A-8001", Deck: "8", Status: 1}
+	require.NoError(t, db.Create(&existing).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: existing.ID, Total: 2, Locked: 1}).Error)
+
+	found, err := repo.FindSKUsByCodes(ctx, []string{"A-8001", "A-8002"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	updated := found[0]
+	updated.Deck = "9"
+	items := []domain.CabinImportItem{
+		{Row: 2, SKU: updated, InventoryTotal: intPtr(4), AlertThreshold: intPtr(1)},
+		{Row: 3, SKU: domain.CabinSKU{VoyageID: 5, CabinTypeID: 31, Code: "A-8002", Status: 0}, InventoryTotal: intPtr(1)},
+	}
+	require.NoError(t, repo.ApplyImport(ctx, items, "cabin import"))
+	require.NotZero(t, items[1].SKU.ID)
+
+	var created domain.CabinSKU
+	require.NoError(t, db.First(&created, items[1].SKU.ID).Error)
+	assert.Equal(t, int16(0), created.Status, "下架舱房不应被列默认值覆盖")
+	inventories, err := repo.ListInventoriesBySKUs(ctx, []int64{existing.ID, created.ID})
+	require.NoError(t, err)
+	require.Len(t, inventories, 2)
+	for _, inventory := range inventories {
+		if inventory.CabinSKUID == existing.ID {
+			assert.Equal(t, 4, inventory.Total)
+			assert.Equal(t, 1, inventory.AlertThreshold)
+			assert.Equal(t, 1, inventory.Locked)
+		} else {
+			assert.Equal(t, 1, inventory.Total)
+		}
+	}
+	var logs []domain.InventoryLog
+	require.NoError(t, db.Find(&logs).Error)
+	require.Len(t, logs, 1)
+	assert.Equal(t, 2, logs[0].Change)
+	var reloaded domain.CabinSKU
+	require.NoError(t, db.First(&reloaded, existing.ID).Error)
+	assert.Equal(t, "9", reloaded.Deck)
+}
+
+func TestCabinImportRepository_ApplyImportRollsBackBelowCommitted(t *testing.T) {
+	repo, db := newCabinImportTestRepo(t)
+	ctx := context.Background()
+	existing := domain.CabinSKU{VoyageID: 5, CabinTypeID: 31, Code: "A-8001", Status: 1}
+	require.NoError(t, db.Create(&existing).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: existing.ID, Total: 3, Locked: 1, Sold: 1}).Error)
+
+	err := repo.ApplyImport(ctx, []domain.CabinImportItem{
+		{SKU: domain.CabinSKU{VoyageID: 5, CabinTypeID: 31, Code: "A-8002", Status: 1}, InventoryTotal: intPtr(1)},
+		{SKU: existing, InventoryTotal: intPtr(1)},
+	}, "cabin import")
+	require.ErrorIs(t, err, domain.ErrCabinInventoryBelowCommitted)
+
+	var count int64
+	db.Model(&domain.CabinSKU{}).Count(&count)
+	assert.EqualValues(t, 1, count, "整个导入应回滚")
+	empty, err := repo.FindSKUsByCodes(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
@@ -19,18 +19,27 @@ func NewCabinRepository(db *gorm.DB) *CabinRepository { return &CabinRepository{
 
 // CreateSKU 创建舱房 SKU 记录。
 func (r *CabinRepository) CreateSKU(ctx context.Context, v *domain.CabinSKU) error {
+	if err := ensureVoyageInScope(ctx, r.db, v.VoyageID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(v).Error
 }
 
 // UpdateSKU 更新舱房 SKU 记录。
 func (r *CabinRepository) UpdateSKU(ctx context.Context, v *domain.CabinSKU) error {
+	if err := ensureSKUInScope(ctx, r.db, v.ID); err != nil {
+		return err
+	}
+	if err := ensureVoyageInScope(ctx, r.db, v.VoyageID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Save(v).Error
 }
 
 // GetSKUByID 根据 ID 查询舱房 SKU。
 func (r *CabinRepository) GetSKUByID(ctx context.Context, id int64) (*domain.CabinSKU, error) {
 	var out domain.CabinSKU
-	if err := r.db.WithContext(ctx).First(&out, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).First(&out, id).Error; err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -39,7 +48,7 @@ func (r *CabinRepository) GetSKUByID(ctx context.Context, id int64) (*domain.Cab
 // ListSKUByVoyage 查询指定航次下的所有舱房 SKU。
 func (r *CabinRepository) ListSKUByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error) {
 	var out []domain.CabinSKU
-	return out, r.db.WithContext(ctx).Where("voyage_id = ?", voyageID).Order("id desc").Find(&out).Error
+	return out, r.db.WithContext(ctx).Where("voyage_id = ?", voyageID).Scopes(scopeByVoyage(ctx, "voyage_id")).Order("id desc").Find(&out).Error
 }
 
 // ListSKUFiltered 按条件分页查询舱房 SKU。
@@ -56,7 +65,7 @@ func (r *CabinRepository) ListSKUFiltered(ctx context.Context, f domain.CabinSKU
 		pageSize = 10
 	}
 
-	q := r.db.WithContext(ctx).Model(&domain.CabinSKU{})
+	q := r.db.WithContext(ctx).Model(&domain.CabinSKU{}).Scopes(scopeByVoyage(ctx, "voyage_id"))
 	if f.VoyageID > 0 {
 		q = q.Where("voyage_id = ?", f.VoyageID)
 	}
@@ -85,7 +94,7 @@ func (r *CabinRepository) BatchUpdateStatus(ctx context.Context, ids []int64, st
 		return nil
 	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		res := tx.Model(&domain.CabinSKU{}).Where("id IN ?", ids).Update("status", status)
+		res := tx.Model(&domain.CabinSKU{}).Where("id IN ?", ids).Scopes(scopeByVoyage(ctx, "voyage_id")).Update("status", status)
 		if res.Error != nil {
 			return res.Error
 		}
@@ -98,13 +107,16 @@ func (r *CabinRepository) BatchUpdateStatus(ctx context.Context, ids []int64, st
 
 // DeleteSKU 删除指定的舱房 SKU。
 func (r *CabinRepository) DeleteSKU(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.CabinSKU{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).Delete(&domain.CabinSKU{}, id))
 }
 
 // AdjustInventoryAtomic 使用单条原子化 SQL 更新库存总量，
 // 防止并发请求导致竞态条件（CRITICAL-01 修复项）。
 // 当 total+delta 为负时 WHERE 子句不匹配，会返回 ErrInsufficientInventory。
 func (r *CabinRepository) AdjustInventoryAtomic(ctx context.Context, skuID int64, delta int) error {
+	if err := ensureSKUInScope(ctx, r.db, skuID); err != nil {
+		return err
+	}
 	result := r.db.WithContext(ctx).Exec(
 		`UPDATE cabin_inventories SET total = total + ?, updated_at = CURRENT_TIMESTAMP
 		 WHERE cabin_sku_id = ? AND total + ? >= 0`,
@@ -122,17 +134,20 @@ func (r *CabinRepository) AdjustInventoryAtomic(ctx context.Context, skuID int64
 // GetInventoryBySKU 根据 SKU ID 查询库存信息。
 func (r *CabinRepository) GetInventoryBySKU(ctx context.Context, skuID int64) (domain.CabinInventory, error) {
 	var out domain.CabinInventory
-	return out, r.db.WithContext(ctx).Where("cabin_sku_id = ?", skuID).First(&out).Error
+	return out, r.db.WithContext(ctx).Where("cabin_sku_id = ?", skuID).Scopes(scopeBySKU(ctx, "cabin_sku_id")).First(&out).Error
 }
 
 // ListAllInventories 查询全部库存记录。
 func (r *CabinRepository) ListAllInventories(ctx context.Context) ([]domain.CabinInventory, error) {
 	var out []domain.CabinInventory
-	return out, r.db.WithContext(ctx).Order("cabin_sku_id asc").Find(&out).Error
+	return out, r.db.WithContext(ctx).Scopes(scopeBySKU(ctx, "cabin_sku_id")).Order("cabin_sku_id asc").Find(&out).Error
 }
 
 // SetAlertThreshold 设置指定 SKU 的库存预警阈值。
 func (r *CabinRepository) SetAlertThreshold(ctx context.Context, skuID int64, threshold int) error {
+	if err := ensureSKUInScope(ctx, r.db, skuID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Model(&domain.CabinInventory{}).Where("cabin_sku_id = ?", skuID).Update("alert_threshold", threshold).Error
 }
 
@@ -144,7 +159,7 @@ func (r *CabinRepository) AppendInventoryLog(ctx context.Context, log *domain.In
 // ListPricesBySKU 查询指定 SKU 的价格列表，按日期和入住人数排序。
 func (r *CabinRepository) ListPricesBySKU(ctx context.Context, skuID int64) ([]domain.CabinPrice, error) {
 	var out []domain.CabinPrice
-	return out, r.db.WithContext(ctx).Where("cabin_sku_id = ?", skuID).Order("date asc, occupancy asc").Find(&out).Error
+	return out, r.db.WithContext(ctx).Where("cabin_sku_id = ?", skuID).Scopes(scopeBySKU(ctx, "cabin_sku_id")).Order("date asc, occupancy asc").Find(&out).Error
 }
 
 // ListBySKU 兼容 PricingService 的方法。
@@ -156,6 +171,9 @@ func (r *CabinRepository) ListBySKU(ctx context.Context, skuID int64) ([]domain.
 //
 //go:noinline
 func (r *CabinRepository) UpsertPrice(ctx context.Context, p *domain.CabinPrice) error {
+	if err := ensureSKUInScope(ctx, r.db, p.CabinSKUID); err != nil {
+		return err
+	}
 	err := r.db.WithContext(ctx).Save(p).Error
 	return err
 }
@@ -168,6 +186,9 @@ func (r *CabinRepository) Create(ctx context.Context, p *domain.CabinPrice) erro
 // BatchSetPrice 按日期区间批量设置价格。
 func (r *CabinRepository) BatchSetPrice(ctx context.Context, skuID int64, start, end time.Time, occupancy int, priceCents, childPriceCents, singleSupplementCents int64, priceType string) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureSKUInScope(ctx, tx, skuID); err != nil {
+			return err
+		}
 		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
 			p := &domain.CabinPrice{
 				CabinSKUID:            skuID,
@@ -16,7 +16,16 @@ func NewCabinTypeBindingRepository(db *gorm.DB) *CabinTypeBindingRepository {
 	return &CabinTypeBindingRepository{db: db}
 }
 
+// ReplaceCruiseBindings 整体替换舱型绑定的邮轮；舱型与每个目标邮轮都须在数据范围内。
 func (r *CabinTypeBindingRepository) ReplaceCruiseBindings(ctx context.Context, cabinTypeID int64, cruiseIDs []int64) error {
+	if err := ensureCabinTypeInScope(ctx, r.db, cabinTypeID); err != nil {
+		return err
+	}
+	for _, cruiseID := range cruiseIDs {
+		if err := ensureCruiseInScope(ctx, r.db, cruiseID); err != nil {
+			return err
+		}
+	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("cabin_type_id = ?", cabinTypeID).Delete(&domain.CabinTypeCruiseBinding{}).Error; err != nil {
 			return err
@@ -37,6 +46,7 @@ func (r *CabinTypeBindingRepository) ListCruiseIDsByCabinType(ctx context.Contex
 	if err := r.db.WithContext(ctx).
 		Model(&domain.CabinTypeCruiseBinding{}).
 		Where("cabin_type_id = ?", cabinTypeID).
+		Scopes(scopeByCruise(ctx, "cruise_id")).
 		Order("cruise_id asc").
 		Pluck("cruise_id", &ids).Error; err != nil {
 		return nil, err
@@ -49,6 +59,7 @@ func (r *CabinTypeBindingRepository) ListCabinTypeIDsByCruise(ctx context.Contex
 	if err := r.db.WithContext(ctx).
 		Model(&domain.CabinTypeCruiseBinding{}).
 		Where("cruise_id = ?", cruiseID).
+		Scopes(scopeByCruise(ctx, "cruise_id")).
 		Order("cabin_type_id asc").
 		Pluck("cabin_type_id", &ids).Error; err != nil {
 		return nil, err
@@ -17,16 +17,26 @@ func NewCabinTypeMediaRepository(db *gorm.DB) *CabinTypeMediaRepository {
 }
 
 func (r *CabinTypeMediaRepository) Create(ctx context.Context, media *domain.CabinTypeMedia) error {
+	if err := ensureCabinTypeInScope(ctx, r.db, media.CabinTypeID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(media).Error
 }
 
+// Update 保存媒体修改；原舱型与目标舱型都须在数据范围内。
 func (r *CabinTypeMediaRepository) Update(ctx context.Context, media *domain.CabinTypeMedia) error {
+	if err := ensureInScope(ctx, r.db.Model(&domain.CabinTypeMedia{}).Where("id = ?", media.ID).Scopes(scopeByCabinType(ctx, "cabin_type_id"))); err != nil {
+		return err
+	}
+	if err := ensureCabinTypeInScope(ctx, r.db, media.CabinTypeID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Save(media).Error
 }
 
 func (r *CabinTypeMediaRepository) GetByID(ctx context.Context, id int64) (*domain.CabinTypeMedia, error) {
 	var item domain.CabinTypeMedia
-	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByCabinType(ctx, "cabin_type_id")).First(&item, id).Error; err != nil {
 		return nil, err
 	}
 	return &item, nil
@@ -37,6 +47,7 @@ func (r *CabinTypeMediaRepository) ListByCabinType(ctx context.Context, cabinTyp
 	if err := r.db.WithContext(ctx).
 		Model(&domain.CabinTypeMedia{}).
 		Where("cabin_type_id = ?", cabinTypeID).
+		Scopes(scopeByCabinType(ctx, "cabin_type_id")).
 		Order("is_primary desc, sort_order desc, id desc").
 		Find(&items).Error; err != nil {
 		return nil, err
@@ -45,10 +56,13 @@ func (r *CabinTypeMediaRepository) ListByCabinType(ctx context.Context, cabinTyp
 }
 
 func (r *CabinTypeMediaRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.CabinTypeMedia{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByCabinType(ctx, "cabin_type_id")).Delete(&domain.CabinTypeMedia{}, id))
 }
 
 func (r *CabinTypeMediaRepository) SetPrimary(ctx context.Context, cabinTypeID int64, mediaType string, mediaID int64) error {
+	if err := ensureCabinTypeInScope(ctx, r.db, cabinTypeID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&domain.CabinTypeMedia{}).
 			Where("cabin_type_id = ? AND media_type = ?", cabinTypeID, mediaType).
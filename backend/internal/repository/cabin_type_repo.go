@@ -19,18 +19,27 @@ func NewCabinTypeRepository(db *gorm.DB) *CabinTypeRepository {
 
 // Create 插入一条新的舱房类型记录。
 func (r *CabinTypeRepository) Create(ctx context.Context, cabinType *domain.CabinType) error {
+	if err := ensureCruiseInScope(ctx, r.db, cabinType.CruiseID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(cabinType).Error
 }
 
-// Update 保存舱房类型的所有字段修改。
+// Update 保存舱房类型的所有字段修改；原邮轮与目标邮轮都须在数据范围内。
 func (r *CabinTypeRepository) Update(ctx context.Context, cabinType *domain.CabinType) error {
+	if err := ensureInScope(ctx, r.db.Model(&domain.CabinType{}).Where("id = ?", cabinType.ID).Scopes(scopeByCruise(ctx, "cruise_id"))); err != nil {
+		return err
+	}
+	if err := ensureCruiseInScope(ctx, r.db, cabinType.CruiseID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Save(cabinType).Error
 }
 
 // GetByID 根据主键查询舱房类型记录。
 func (r *CabinTypeRepository) GetByID(ctx context.Context, id int64) (*domain.CabinType, error) {
 	var cabinType domain.CabinType
-	if err := r.db.WithContext(ctx).First(&cabinType, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByCruise(ctx, "cruise_id")).First(&cabinType, id).Error; err != nil {
 		return nil, err
 	}
 	return &cabinType, nil
@@ -40,7 +49,7 @@ func (r *CabinTypeRepository) GetByID(ctx context.Context, id int64) (*domain.Ca
 func (r *CabinTypeRepository) ListByCruise(ctx context.Context, cruiseID int64, page, pageSize int) ([]domain.CabinType, int64, error) {
 	var items []domain.CabinType
 	var total int64
-	q := r.db.WithContext(ctx).Model(&domain.CabinType{}).Where("cruise_id = ?", cruiseID)
+	q := r.db.WithContext(ctx).Model(&domain.CabinType{}).Where("cruise_id = ?", cruiseID).Scopes(scopeByCruise(ctx, "cruise_id"))
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -52,7 +61,7 @@ func (r *CabinTypeRepository) ListByCruise(ctx context.Context, cruiseID int64,
 
 // Delete 软删除指定的舱房类型记录。
 func (r *CabinTypeRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.CabinType{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByCruise(ctx, "cruise_id")).Delete(&domain.CabinType{}, id))
 }
 
 // HasCabinTypesByCruise 判断指定邮轮是否仍有关联舱型。
@@ -24,24 +24,27 @@ func (r *CompanyRepository) Create(ctx context.Context, company *domain.CruiseCo
 
 // Update 保存公司的所有字段修改。
 func (r *CompanyRepository) Update(ctx context.Context, company *domain.CruiseCompany) error {
+	if err := ensureCompanyInScope(ctx, company.ID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Save(company).Error
 }
 
-// GetByID 根据主键查询公司记录。
+// GetByID 根据主键查询公司记录，受公司数据范围限制。
 func (r *CompanyRepository) GetByID(ctx context.Context, id int64) (*domain.CruiseCompany, error) {
 	var company domain.CruiseCompany
-	if err := r.db.WithContext(ctx).First(&company, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByCompany(ctx, "id")).First(&company, id).Error; err != nil {
 		return nil, err
 	}
 	return &company, nil
 }
 
-// List 分页查询公司列表，支持按名称关键词模糊搜索。
+// List 分页查询公司列表，支持按名称关键词模糊搜索，受公司数据范围限制。
 // 返回值：公司列表、总记录数、错误信息。
 func (r *CompanyRepository) List(ctx context.Context, keyword string, page, pageSize int) ([]domain.CruiseCompany, int64, error) {
 	var items []domain.CruiseCompany
 	var total int64
-	q := r.db.WithContext(ctx).Model(&domain.CruiseCompany{})
+	q := r.db.WithContext(ctx).Model(&domain.CruiseCompany{}).Scopes(scopeByCompany(ctx, "id"))
 	if keyword != "" {
 		kw := "%" + keyword + "%"
 		q = q.Where("name LIKE ? OR english_name LIKE ?", kw, kw)
@@ -71,5 +74,5 @@ func (r *CompanyRepository) ListPublic(ctx context.Context, page, pageSize int)
 
 // Delete 软删除指定的公司记录。
 func (r *CompanyRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.CruiseCompany{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByCompany(ctx, "id")).Delete(&domain.CruiseCompany{}, id))
 }
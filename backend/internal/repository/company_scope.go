@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// 公司数据范围过滤：上下文携带 domain.WithCompanyScope 时，查询只返回被授权公司下的数据，授权为空时不返回任何数据；
+// 范围外的记录对调用方表现为不存在（gorm.ErrRecordNotFound），不暴露其存在性。
+
+// 以下子查询返回授权公司名下的记录 ID，各含一个公司 ID 列表占位符。
+const (
+	cruiseIDsInScope    = "SELECT id FROM cruises WHERE company_id IN ?"
+	voyageIDsInScope    = "SELECT voyages.id FROM voyages JOIN cruises ON cruises.id = voyages.cruise_id WHERE cruises.company_id IN ?"
+	skuIDsInScope       = "SELECT cabin_skus.id FROM cabin_skus JOIN voyages ON voyages.id = cabin_skus.voyage_id JOIN cruises ON cruises.id = voyages.cruise_id WHERE cruises.company_id IN ?"
+	bookingIDsInScope   = "SELECT bookings.id FROM bookings JOIN voyages ON voyages.id = bookings.voyage_id JOIN cruises ON cruises.id = voyages.cruise_id WHERE cruises.company_id IN ?"
+	paymentIDsInScope   = "SELECT payments.id FROM payments WHERE payments.order_id IN (" + bookingIDsInScope + ")"
+	cabinTypeIDsInScope = "SELECT cabin_types.id FROM cabin_types JOIN cruises ON cruises.id = cabin_types.cruise_id WHERE cruises.company_id IN ?"
+	facilityIDsInScope  = "SELECT facilities.id FROM facilities JOIN cruises ON cruises.id = facilities.cruise_id WHERE cruises.company_id IN ?"
+)
+
+// scopeByCompany 按公司 ID 列过滤，column 如 "company_id"、"cruises.company_id"。
+func scopeByCompany(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, column+" IN ?")
+	}
+}
+
+// scopeByCruise 按邮轮 ID 列过滤，仅保留属于授权公司的邮轮。
+func scopeByCruise(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, column+" IN ("+cruiseIDsInScope+")")
+	}
+}
+
+// scopeByVoyage 按航次 ID 列过滤，仅保留授权公司邮轮执行的航次。
+func scopeByVoyage(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, column+" IN ("+voyageIDsInScope+")")
+	}
+}
+
+// scopeBySKU 按舱房 SKU ID 列过滤，仅保留授权公司航次下的舱房。
+func scopeBySKU(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, column+" IN ("+skuIDsInScope+")")
+	}
+}
+
+// scopeByBooking 按订单 ID 列过滤，仅保留授权公司航次下的订单。
+func scopeByBooking(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, column+" IN ("+bookingIDsInScope+")")
+	}
+}
+
+// scopeByPayment 按支付记录 ID 列过滤，仅保留授权公司订单的支付记录。
+func scopeByPayment(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, column+" IN ("+paymentIDsInScope+")")
+	}
+}
+
+// scopeByCabinType 按舱型 ID 列过滤，仅保留授权公司邮轮下的舱型。
+func scopeByCabinType(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, column+" IN ("+cabinTypeIDsInScope+")")
+	}
+}
+
+// rawScopeCondition 为原生 SQL 生成以 " AND " 开头的数据范围条件，subquery 取上面的 *InScope 子查询；
+// 未受限时返回空串。返回的参数须按条件在 SQL 中出现的顺序传入。
+func rawScopeCondition(ctx context.Context, column, subquery string) (string, []interface{}) {
+	companyIDs, ok := domain.CompanyScopeFromContext(ctx)
+	if !ok {
+		return "", nil
+	}
+	if len(companyIDs) == 0 {
+		return " AND 1 = 0", nil
+	}
+	return " AND " + column + " IN (" + subquery + ")", []interface{}{companyIDs}
+}
+
+// restrictToCompanies 在受限上下文中按 clause（含一个公司 ID 列表占位符）过滤，授权公司为空时不匹配任何行。
+func restrictToCompanies(ctx context.Context, db *gorm.DB, clause string) *gorm.DB {
+	companyIDs, ok := domain.CompanyScopeFromContext(ctx)
+	if !ok {
+		return db
+	}
+	if len(companyIDs) == 0 {
+		return db.Where("1 = 0")
+	}
+	return db.Where(clause, companyIDs)
+}
+
+// ensureCompanyInScope 校验写入目标公司在数据范围内。
+func ensureCompanyInScope(ctx context.Context, companyID int64) error {
+	if !domain.CompanyInScope(ctx, companyID) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ensureCruiseInScope 校验写入目标邮轮属于授权公司。
+func ensureCruiseInScope(ctx context.Context, db *gorm.DB, cruiseID int64) error {
+	return ensureInScope(ctx, db.Model(&domain.Cruise{}).Where("id = ?", cruiseID).Scopes(scopeByCompany(ctx, "company_id")))
+}
+
+// ensureVoyageInScope 校验写入目标航次属于授权公司。
+func ensureVoyageInScope(ctx context.Context, db *gorm.DB, voyageID int64) error {
+	return ensureInScope(ctx, db.Model(&domain.Voyage{}).Where("id = ?", voyageID).Scopes(scopeByCruise(ctx, "cruise_id")))
+}
+
+// ensureBookingInScope 校验订单所属航次属于授权公司。
+func ensureBookingInScope(ctx context.Context, db *gorm.DB, bookingID int64) error {
+	return ensureInScope(ctx, db.Model(&domain.Booking{}).Where("id = ?", bookingID).Scopes(scopeByVoyage(ctx, "voyage_id")))
+}
+
+// ensureSKUInScope 校验舱房 SKU 所属航次属于授权公司。
+func ensureSKUInScope(ctx context.Context, db *gorm.DB, skuID int64) error {
+	return ensureInScope(ctx, db.Model(&domain.CabinSKU{}).Where("id = ?", skuID).Scopes(scopeByVoyage(ctx, "voyage_id")))
+}
+
+// ensureCabinTypeInScope 校验舱型所属邮轮属于授权公司。
+func ensureCabinTypeInScope(ctx context.Context, db *gorm.DB, cabinTypeID int64) error {
+	return ensureInScope(ctx, db.Model(&domain.CabinType{}).Where("id = ?", cabinTypeID).Scopes(scopeByCruise(ctx, "cruise_id")))
+}
+
+// ensureAllCompanies 校验上下文未受公司范围限制；不归属任何公司的平台级数据（如分销商账号、月结账单）仅平台管理员可写。
+func ensureAllCompanies(ctx context.Context) error {
+	if _, ok := domain.CompanyScopeFromContext(ctx); ok {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// scopeAllCompaniesOnly 使受限上下文查不到平台级数据。
+func scopeAllCompaniesOnly(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if _, ok := domain.CompanyScopeFromContext(ctx); ok {
+			return db.Where("1 = 0")
+		}
+		return db
+	}
+}
+
+func ensureInScope(ctx context.Context, query *gorm.DB) error {
+	if _, ok := domain.CompanyScopeFromContext(ctx); !ok {
+		return nil
+	}
+	var count int64
+	if err := query.WithContext(ctx).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// scopedRowsAffected 在受限上下文中把未命中任何行的更新/删除视为记录不存在，避免越权操作被静默当作成功。
+func scopedRowsAffected(ctx context.Context, result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if _, ok := domain.CompanyScopeFromContext(ctx); ok && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
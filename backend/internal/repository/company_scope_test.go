@@ -0,0 +1,498 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newCompanyScopeTestDB 准备两家公司各自的一条邮轮、航次、舱房、库存与订单：
+// 公司 1 -> 邮轮 10 -> 航次 100 -> 舱房 1000 -> 订单 5000；公司 2 -> 20 -> 200 -> 2000 -> 6000。
+func newCompanyScopeTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.CruiseCompany{}, &domain.Cruise{}, &domain.Voyage{}, &domain.VoyageItinerary{},
+		&domain.VoyageCabinTypeCurrent{}, &domain.CabinSKU{}, &domain.CabinInventory{}, &domain.CabinPrice{},
+		&domain.User{}, &domain.Booking{}, &domain.OrderStatusLog{}, &domain.StaffCompany{}))
+
+	depart := time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)
+	for _, n := range []int64{1, 2} {
+		require.NoError(t, db.Create(&domain.CruiseCompany{ID: n, Name: "公司"}).Error)
+		require.NoError(t, db.Create(&domain.Cruise{ID: n * 10, CompanyID: n, Name: "邮轮", Code: fmt.Sprintf("C%d", n), Status: 1}).Error)
+		require.NoError(t, db.Create(&domain.Voyage{ID: n * 100, CruiseID: n * 10, Code: fmt.Sprintf("V%d", n), DepartDate: depart, ReturnDate: depart.AddDate(0, 0, 5)}).Error)
+		require.NoError(t, db.Create(&domain.CabinSKU{ID: n * 1000, VoyageID: n * 100, Code: fmt.Sprintf("SKU%d", n)}).Error)
+		require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: n * 1000, Total: 10}).Error)
+		require.NoError(t, db.Create(&domain.Booking{ID: n*1000 + 4000, VoyageID: n * 100, CabinSKUID: n * 1000, Status: domain.OrderStatusPaid}).Error)
+	}
+	return db
+}
+
+func TestCompanyScope_ReadsOnlyReturnAssignedCompanies(t *testing.T) {
+	db := newCompanyScopeTestDB(t)
+	scoped := domain.WithCompanyScope(context.Background(), []int64{1})
+
+	companies, total, err := NewCompanyRepository(db).List(scoped, "", 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.EqualValues(t, 1, companies[0].ID)
+	_, err = NewCompanyRepository(db).GetByID(scoped, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	cruises := NewCruiseRepository(db)
+	items, total, err := cruises.List(scoped, 0, "", nil, "", 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.EqualValues(t, 10, items[0].ID)
+	items, total, err = cruises.List(scoped, 2, "", nil, "", 1, 20)
+	require.NoError(t, err)
+	assert.Zero(t, total, "filtering by a foreign company must not bypass the scope")
+	assert.Empty(t, items)
+	_, err = cruises.GetByID(scoped, 20)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	voyages := NewVoyageRepository(db)
+	list, err := voyages.List(scoped)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.EqualValues(t, 100, list[0].ID)
+	_, err = voyages.GetByID(scoped, 200)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = voyages.GetByID(scoped, 100)
+	assert.NoError(t, err)
+
+	cabins := NewCabinRepository(db)
+	skus, total, err := cabins.ListSKUFiltered(scoped, domain.CabinSKUFilter{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.EqualValues(t, 1000, skus[0].ID)
+	_, err = cabins.GetSKUByID(scoped, 2000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = cabins.GetInventoryBySKU(scoped, 2000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	bookings := NewBookingRepository(db)
+	orders, total, err := bookings.ListWithFilter(scoped, BookingFilter{}, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.EqualValues(t, 5000, orders[0].ID)
+	exported, err := bookings.ListForExport(scoped, BookingFilter{}, 10)
+	require.NoError(t, err)
+	assert.Len(t, exported, 1)
+	_, total, err = bookings.List(scoped, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	_, err = bookings.GetByID(scoped, 6000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// 未携带数据范围的上下文（平台管理员、C 端与后台任务）不受限制
+	_, total, err = bookings.ListWithFilter(context.Background(), BookingFilter{}, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+}
+
+func TestCompanyScope_CrossTenantWritesAreDenied(t *testing.T) {
+	db := newCompanyScopeTestDB(t)
+	scoped := domain.WithCompanyScope(context.Background(), []int64{1})
+
+	cruises := NewCruiseRepository(db)
+	assert.ErrorIs(t, cruises.Create(scoped, &domain.Cruise{CompanyID: 2, Name: "越权"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, cruises.Update(scoped, &domain.Cruise{ID: 20, CompanyID: 1, Name: "抢占"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, cruises.Update(scoped, &domain.Cruise{ID: 10, CompanyID: 2, Name: "转出"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, cruises.Delete(scoped, 20), gorm.ErrRecordNotFound)
+	assert.Error(t, cruises.BatchUpdateStatus(scoped, []int64{10, 20}, 0))
+	assert.NoError(t, cruises.Create(scoped, &domain.Cruise{CompanyID: 1, Name: "自营", Code: "OWN"}))
+
+	voyages := NewVoyageRepository(db)
+	assert.ErrorIs(t, voyages.Create(scoped, &domain.Voyage{CruiseID: 20, Code: "X"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, voyages.Update(scoped, &domain.Voyage{ID: 200, CruiseID: 10, Code: "X"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, voyages.Delete(scoped, 200), gorm.ErrRecordNotFound)
+
+	cabins := NewCabinRepository(db)
+	assert.ErrorIs(t, cabins.CreateSKU(scoped, &domain.CabinSKU{VoyageID: 200, Code: "X"}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, cabins.AdjustInventoryAtomic(scoped, 2000, 5), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, cabins.SetAlertThreshold(scoped, 2000, 1), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, cabins.UpsertPrice(scoped, &domain.CabinPrice{CabinSKUID: 2000, Occupancy: 2, PriceCents: 1}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, cabins.DeleteSKU(scoped, 2000), gorm.ErrRecordNotFound)
+
+	bookings := NewBookingRepository(db)
+	assert.ErrorIs(t, bookings.TransitionStatus(scoped, 6000, domain.OrderStatusCancelled, 1, "越权"), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, bookings.Delete(scoped, 6000), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, bookings.Create(scoped, &domain.Booking{VoyageID: 200, CabinSKUID: 2000}), gorm.ErrRecordNotFound)
+
+	// 越权写入均未落库
+	var cruise domain.Cruise
+	require.NoError(t, db.First(&cruise, 20).Error)
+	assert.EqualValues(t, 2, cruise.CompanyID)
+	var inventory domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", 2000).First(&inventory).Error)
+	assert.Equal(t, 10, inventory.Total)
+	var booking domain.Booking
+	require.NoError(t, db.First(&booking, 6000).Error)
+	assert.Equal(t, domain.OrderStatusPaid, booking.Status)
+}
+
+func TestCompanyScope_EmptyAssignmentSeesNothing(t *testing.T) {
+	db := newCompanyScopeTestDB(t)
+	none := domain.WithCompanyScope(context.Background(), nil)
+
+	_, total, err := NewCompanyRepository(db).List(none, "", 1, 20)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	_, total, err = NewCruiseRepository(db).List(none, 0, "", nil, "", 1, 20)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	voyages, err := NewVoyageRepository(db).List(none)
+	require.NoError(t, err)
+	assert.Empty(t, voyages)
+	_, total, err = NewBookingRepository(db).ListWithFilter(none, BookingFilter{}, 1, 20)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	_, err = NewCabinRepository(db).GetSKUByID(none, 1000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, NewCruiseRepository(db).Create(none, &domain.Cruise{CompanyID: 1, Name: "越权"}), gorm.ErrRecordNotFound)
+}
+
+// seedCompanyScopeResources 在 newCompanyScopeTestDB 的基础上为两家公司各准备一份后台资源，
+// 资源 ID 与公司对应（ID 1 属于公司 1，ID 2 属于公司 2），舱型 ID 为公司号 × 300。
+func seedCompanyScopeResources(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	require.NoError(t, db.AutoMigrate(&domain.CabinTypeCategory{}, &domain.CabinType{}, &domain.VoyageCabinTypePriceVersion{},
+		&domain.Payment{}, &domain.Refund{}, &domain.DynamicPricingRule{}, &domain.DynamicPricingProposal{}, &domain.DynamicPricingProposalItem{},
+		&domain.VoyageDisruption{}, &domain.DisruptionBooking{}, &domain.WaitlistEntry{}, &domain.Agency{}, &domain.AgencyAllotment{},
+		&domain.AgencyNetRate{}, &domain.AgencyBooking{}, &domain.AgencyStatement{}, &domain.VoyageSeries{}, &domain.DeckPlan{}, &domain.DeckPlanCabin{},
+		&domain.FacilityCategory{}, &domain.Facility{}, &domain.CabinTypeMedia{}, &domain.CabinTypeCruiseBinding{}, &domain.Image{},
+		&domain.OperationLog{}, &domain.OperationLogChainHead{}))
+
+	future := time.Now().Add(30 * 24 * time.Hour)
+	require.NoError(t, db.Create(&domain.CabinTypeCategory{ID: 1, Name: "内舱", Code: "INSIDE"}).Error)
+	require.NoError(t, db.Create(&domain.Agency{ID: 1, Code: "AG", Name: "分销商", LoginName: "agency"}).Error)
+	require.NoError(t, db.Create(&domain.AgencyStatement{ID: 1, AgencyID: 1, Period: "2026-07"}).Error)
+	require.NoError(t, db.Create(&domain.DynamicPricingRule{ID: 3, Name: "全部航次", Status: 1}).Error)
+	require.NoError(t, db.Create(&domain.FacilityCategory{ID: 1, Name: "餐饮"}).Error)
+	require.NoError(t, NewOperationLogRepository(db).Create(context.Background(), &domain.OperationLog{StaffID: 1, Operation: "update", Resource: "cruise", ResourceID: 20}))
+	for _, n := range []int64{1, 2} {
+		voyageID, skuID, bookingID, cabinTypeID := n*100, n*1000, n*1000+4000, n*300
+		require.NoError(t, db.Create(&domain.CabinType{ID: cabinTypeID, CruiseID: n * 10, CategoryID: 1, Name: "舱型"}).Error)
+		require.NoError(t, db.Model(&domain.CabinSKU{}).Where("id = ?", skuID).Update("cabin_type_id", cabinTypeID).Error)
+		require.NoError(t, db.Create(&domain.VoyageCabinTypePriceVersion{ID: n, VoyageID: voyageID, CabinTypeID: cabinTypeID, EffectiveAt: future}).Error)
+		require.NoError(t, db.Create(&domain.Payment{ID: n, OrderID: bookingID, Status: "paid", AmountCents: 100}).Error)
+		require.NoError(t, db.Create(&domain.Refund{ID: n, PaymentID: n, AmountCents: 10, Status: "pending"}).Error)
+		require.NoError(t, db.Create(&domain.DynamicPricingRule{ID: n, Name: "规则", VoyageID: voyageID, Status: 1}).Error)
+		require.NoError(t, db.Create(&domain.DynamicPricingProposal{ID: n, Status: domain.PricingProposalStatusPending,
+			Items: []domain.DynamicPricingProposalItem{{VoyageID: voyageID, CabinTypeID: cabinTypeID}}}).Error)
+		require.NoError(t, db.Create(&domain.VoyageDisruption{ID: n, VoyageID: voyageID, Type: domain.VoyageDisruptionCancelled, Status: domain.VoyageDisruptionStatusOpen}).Error)
+		require.NoError(t, db.Create(&domain.DisruptionBooking{ID: n, DisruptionID: n, BookingID: bookingID, UserID: 1, Status: domain.DisruptionBookingPending}).Error)
+		require.NoError(t, db.Create(&domain.WaitlistEntry{ID: n, UserID: 1, VoyageID: voyageID, CabinTypeID: cabinTypeID, Guests: 2, Status: domain.WaitlistStatusWaiting}).Error)
+		require.NoError(t, db.Create(&domain.AgencyAllotment{ID: n, AgencyID: 1, VoyageID: voyageID, CabinSKUID: skuID, Quantity: 1, ReleaseAt: future}).Error)
+		require.NoError(t, db.Create(&domain.AgencyNetRate{ID: n, AgencyID: 1, VoyageID: voyageID, CabinTypeID: cabinTypeID, NetPriceCents: 1}).Error)
+		require.NoError(t, db.Create(&domain.AgencyBooking{ID: n, AgencyID: 1, BookingID: bookingID, AllotmentID: n, NetCents: 1}).Error)
+		require.NoError(t, db.Create(&domain.VoyageSeries{ID: n, Name: "系列", TemplateVoyageID: voyageID, CruiseID: n * 10, CodePrefix: fmt.Sprintf("S%d", n), Frequency: "daily", RepeatInterval: 1}).Error)
+		require.NoError(t, db.Model(&domain.Voyage{}).Where("id = ?", voyageID).Update("series_id", n).Error)
+		require.NoError(t, db.Create(&domain.DeckPlan{ID: n, CruiseID: n * 10, Deck: "5"}).Error)
+		require.NoError(t, db.Create(&domain.Facility{ID: n, CategoryID: 1, CruiseID: n * 10, Name: "餐厅"}).Error)
+		require.NoError(t, db.Create(&domain.CabinTypeMedia{ID: n, CabinTypeID: cabinTypeID, MediaType: "image", URL: "u", Title: "t"}).Error)
+		require.NoError(t, db.Create(&domain.CabinTypeCruiseBinding{CabinTypeID: cabinTypeID, CruiseID: n * 10}).Error)
+		require.NoError(t, db.Create(&domain.Image{ID: n, EntityType: "cruise", EntityID: n * 10, URL: "u"}).Error)
+	}
+}
+
+func TestCompanyScope_AdminResourcesHideForeignCompanies(t *testing.T) {
+	db := newCompanyScopeTestDB(t)
+	seedCompanyScopeResources(t, db)
+	ctx := context.Background()
+	scoped := domain.WithCompanyScope(ctx, []int64{1})
+
+	t.Run("analytics", func(t *testing.T) {
+		repo := NewAnalyticsRepository(db)
+		facts, err := repo.ListBookingFacts(scoped, domain.AnalyticsFactFilter{From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, facts, 1)
+		assert.EqualValues(t, 5000, facts[0].BookingID)
+		facts, err = repo.ListBookingFacts(scoped, domain.AnalyticsFactFilter{From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour), CompanyID: 2})
+		require.NoError(t, err)
+		assert.Empty(t, facts)
+		capacity, err := repo.ListCapacityFacts(scoped, []int64{100, 200})
+		require.NoError(t, err)
+		require.Len(t, capacity, 1)
+		assert.EqualValues(t, 100, capacity[0].VoyageID)
+
+		overview, err := repo.InventoryOverview(scoped)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, overview.TotalCabins)
+		ranking, err := repo.CabinHotnessRanking(scoped, 10)
+		require.NoError(t, err)
+		require.Len(t, ranking, 1)
+		assert.EqualValues(t, 1000, ranking[0].CabinSKUID)
+		views, err := repo.PageViewStats(scoped)
+		require.NoError(t, err)
+		for _, view := range views {
+			assert.EqualValues(t, 1, view.Views, view.Page)
+		}
+		_, err = repo.WeeklyTrend(scoped)
+		require.NoError(t, err)
+		_, err = repo.Trend(scoped, 7)
+		require.NoError(t, err)
+	})
+
+	t.Run("payments and refunds", func(t *testing.T) {
+		payments := NewPaymentRepository(db)
+		_, err := payments.FindByID(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, err = payments.FindByID(scoped, 1)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, payments.UpdateStatus(scoped, 2, "refunded"), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, payments.Create(scoped, &domain.Payment{OrderID: 6000, Status: "pending"}), gorm.ErrRecordNotFound)
+
+		refunds := NewRefundRepository(db)
+		assert.ErrorIs(t, refunds.Create(scoped, &domain.Refund{PaymentID: 2, AmountCents: 1}), gorm.ErrRecordNotFound)
+		sum, err := refunds.SumByPaymentID(scoped, 2)
+		require.NoError(t, err)
+		assert.Zero(t, sum)
+		sum, err = refunds.SumByPaymentID(ctx, 2)
+		require.NoError(t, err)
+		assert.EqualValues(t, 10, sum)
+	})
+
+	t.Run("price versions", func(t *testing.T) {
+		repo := NewVoyageCabinTypePriceRepository(db)
+		_, err := repo.GetVersion(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		pending, total, err := repo.ListPendingVersions(scoped, 0, 0, time.Now(), 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.EqualValues(t, 1, pending[0].ID)
+		assert.ErrorIs(t, repo.CancelVersion(scoped, 2, 1, time.Now()), domain.ErrPriceVersionNotPending)
+		assert.ErrorIs(t, repo.CreateVersion(scoped, &domain.VoyageCabinTypePriceVersion{VoyageID: 200, CabinTypeID: 600, EffectiveAt: time.Now()}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.UpsertCurrent(scoped, &domain.VoyageCabinTypeCurrent{VoyageID: 200, CabinTypeID: 600}), gorm.ErrRecordNotFound)
+		version, err := repo.GetVersion(ctx, 2)
+		require.NoError(t, err)
+		assert.Nil(t, version.CancelledAt)
+	})
+
+	t.Run("dynamic pricing", func(t *testing.T) {
+		repo := NewDynamicPricingRepository(db)
+		rules, err := repo.ListRules(scoped, false)
+		require.NoError(t, err)
+		ids := make([]int64, 0, len(rules))
+		for _, rule := range rules {
+			ids = append(ids, rule.ID)
+		}
+		assert.ElementsMatch(t, []int64{1, 3}, ids, "platform-wide rules stay readable")
+		_, err = repo.GetRule(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.CreateRule(scoped, &domain.DynamicPricingRule{Name: "越权", VoyageID: 200}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.UpdateRule(scoped, &domain.DynamicPricingRule{ID: 3, Name: "改全局"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.DeleteRule(scoped, 2), gorm.ErrRecordNotFound)
+		_, err = repo.GetProposal(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, total, err := repo.ListProposals(scoped, "", 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		stats, err := repo.ListInventoryStats(scoped, []int64{100, 200})
+		require.NoError(t, err)
+		require.Len(t, stats, 1)
+		assert.EqualValues(t, 100, stats[0].VoyageID)
+	})
+
+	t.Run("disruptions", func(t *testing.T) {
+		repo := NewVoyageDisruptionRepository(db)
+		_, err := repo.GetByID(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, total, err := repo.List(scoped, 0, "", 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		_, total, err = repo.ListBookings(scoped, domain.DisruptionBookingFilter{DisruptionID: 2}, 1, 20)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		_, err = repo.GetBooking(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, err = repo.FindBooking(scoped, 2, 6000)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		offered, err := repo.Offer(scoped, []int64{2}, domain.DisruptionOffer{At: time.Now()}, nil)
+		require.NoError(t, err)
+		assert.Zero(t, offered)
+		_, err = repo.Close(scoped, 2, time.Now())
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("waitlist", func(t *testing.T) {
+		repo := NewWaitlistRepository(db)
+		items, total, err := repo.ListEntries(scoped, domain.WaitlistFilter{}, 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.EqualValues(t, 1, items[0].ID)
+		_, total, err = repo.ListEntries(scoped, domain.WaitlistFilter{VoyageID: 200}, 1, 20)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("agencies and allotments", func(t *testing.T) {
+		repo := NewAgencyRepository(db)
+		allotments, err := repo.ListAllotments(scoped, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, allotments, 1)
+		assert.EqualValues(t, 1, allotments[0].ID)
+		_, err = repo.GetAllotment(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, err = repo.ReleaseAllotment(scoped, 2, time.Now())
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.CreateAllotment(scoped, &domain.AgencyAllotment{AgencyID: 1, VoyageID: 200, CabinSKUID: 2000, Quantity: 1, ReleaseAt: time.Now().Add(time.Hour)}), gorm.ErrRecordNotFound)
+		rates, err := repo.ListNetRates(scoped, 1)
+		require.NoError(t, err)
+		assert.Len(t, rates, 1)
+		assert.ErrorIs(t, repo.UpsertNetRate(scoped, &domain.AgencyNetRate{AgencyID: 1, VoyageID: 200, CabinTypeID: 600}), gorm.ErrRecordNotFound)
+		_, total, err := repo.ListAgencyBookings(scoped, 1, 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+
+		// 分销商账号与月结账单跨公司汇总，受限员工只能查看名录
+		_, total, err = repo.ListAgencies(scoped, "", 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.ErrorIs(t, repo.UpdateAgency(scoped, &domain.Agency{ID: 1, Code: "AG", Name: "改名", LoginName: "agency"}), gorm.ErrRecordNotFound)
+		_, err = repo.GetStatement(scoped, 1)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, total, err = repo.ListStatements(scoped, 1, 1, 20)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("voyage series", func(t *testing.T) {
+		repo := NewVoyageSeriesRepository(db)
+		_, err := repo.GetByID(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, total, err := repo.List(scoped, 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+		voyages, err := repo.ListVoyages(scoped, 2)
+		require.NoError(t, err)
+		assert.Empty(t, voyages)
+		series := &domain.VoyageSeries{Name: "越权", CruiseID: 20, CodePrefix: "X", Frequency: "daily", RepeatInterval: 1}
+		assert.ErrorIs(t, repo.CreateWithVoyages(scoped, series, nil), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("cabin types", func(t *testing.T) {
+		repo := NewCabinTypeRepository(db)
+		_, err := repo.GetByID(scoped, 600)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		_, total, err := repo.ListByCruise(scoped, 20, 1, 20)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.ErrorIs(t, repo.Create(scoped, &domain.CabinType{CruiseID: 20, CategoryID: 1, Name: "越权"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Update(scoped, &domain.CabinType{ID: 600, CruiseID: 10, CategoryID: 1, Name: "抢占"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Update(scoped, &domain.CabinType{ID: 300, CruiseID: 20, CategoryID: 1, Name: "转出"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Delete(scoped, 600), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("deck plans", func(t *testing.T) {
+		repo := NewDeckPlanRepository(db)
+		_, err := repo.GetByID(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		plans, err := repo.ListByCruise(scoped, 20)
+		require.NoError(t, err)
+		assert.Empty(t, plans)
+		assert.ErrorIs(t, repo.Create(scoped, &domain.DeckPlan{CruiseID: 20, Deck: "9"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Delete(scoped, 2), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.ReplaceCabins(scoped, 2, nil), gorm.ErrRecordNotFound)
+		inventories, err := repo.ListInventoriesBySKUs(scoped, []int64{1000, 2000})
+		require.NoError(t, err)
+		assert.Len(t, inventories, 1)
+	})
+
+	t.Run("cabin import and export", func(t *testing.T) {
+		repo := NewCabinImportRepository(db)
+		skus, err := repo.FindSKUsByCodes(scoped, []string{"SKU1", "SKU2"})
+		require.NoError(t, err)
+		require.Len(t, skus, 1)
+		assert.EqualValues(t, 1000, skus[0].ID)
+		inventories, err := repo.ListInventoriesBySKUs(scoped, []int64{1000, 2000})
+		require.NoError(t, err)
+		assert.Len(t, inventories, 1)
+		total := 99
+		item := domain.CabinImportItem{SKU: domain.CabinSKU{ID: 2000, VoyageID: 200, Code: "SKU2"}, InventoryTotal: &total}
+		assert.ErrorIs(t, repo.ApplyImport(scoped, []domain.CabinImportItem{item}, "import"), gorm.ErrRecordNotFound)
+		item = domain.CabinImportItem{SKU: domain.CabinSKU{VoyageID: 200, Code: "NEW"}}
+		assert.ErrorIs(t, repo.ApplyImport(scoped, []domain.CabinImportItem{item}, "import"), gorm.ErrRecordNotFound)
+		var inventory domain.CabinInventory
+		require.NoError(t, db.Where("cabin_sku_id = ?", 2000).First(&inventory).Error)
+		assert.Equal(t, 10, inventory.Total)
+	})
+
+	t.Run("inventories", func(t *testing.T) {
+		inventories, err := NewCabinRepository(db).ListAllInventories(scoped)
+		require.NoError(t, err)
+		require.Len(t, inventories, 1)
+		assert.EqualValues(t, 1000, inventories[0].CabinSKUID)
+	})
+
+	t.Run("facilities", func(t *testing.T) {
+		repo := NewFacilityRepository(db)
+		_, err := repo.GetByID(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		items, err := repo.ListByCruiseAndCategory(scoped, 20, 0)
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		assert.ErrorIs(t, repo.Create(scoped, &domain.Facility{CategoryID: 1, CruiseID: 20, Name: "越权"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Update(scoped, &domain.Facility{ID: 2, CategoryID: 1, CruiseID: 10, Name: "抢占"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Update(scoped, &domain.Facility{ID: 1, CategoryID: 1, CruiseID: 20, Name: "转出"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Delete(scoped, 2), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("cabin type media and bindings", func(t *testing.T) {
+		media := NewCabinTypeMediaRepository(db)
+		_, err := media.GetByID(scoped, 2)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		items, err := media.ListByCabinType(scoped, 600)
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		assert.ErrorIs(t, media.Create(scoped, &domain.CabinTypeMedia{CabinTypeID: 600, MediaType: "image", URL: "u", Title: "t"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, media.Update(scoped, &domain.CabinTypeMedia{ID: 2, CabinTypeID: 300, MediaType: "image", URL: "u", Title: "t"}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, media.Delete(scoped, 2), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, media.SetPrimary(scoped, 600, "image", 2), gorm.ErrRecordNotFound)
+
+		bindings := NewCabinTypeBindingRepository(db)
+		ids, err := bindings.ListCabinTypeIDsByCruise(scoped, 20)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+		assert.ErrorIs(t, bindings.ReplaceCruiseBindings(scoped, 600, []int64{10}), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, bindings.ReplaceCruiseBindings(scoped, 300, []int64{20}), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("images", func(t *testing.T) {
+		repo := NewImageRepository(db)
+		images, err := repo.ListByEntity(scoped, "cruise", 20)
+		require.NoError(t, err)
+		assert.Empty(t, images)
+		images, err = repo.ListByEntity(scoped, "cruise", 10)
+		require.NoError(t, err)
+		assert.Len(t, images, 1)
+		assert.ErrorIs(t, repo.ReplaceImages(scoped, "cruise", 20, nil), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.DeleteByEntity(scoped, "cruise", 20), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.UpdateSortOrder(scoped, 2, 9), gorm.ErrRecordNotFound)
+		assert.ErrorIs(t, repo.Create(scoped, &domain.Image{EntityType: "banner", EntityID: 1, URL: "u"}), gorm.ErrRecordNotFound, "images of unowned entities are platform-level")
+		images, err = repo.ListByEntity(ctx, "cruise", 20)
+		require.NoError(t, err)
+		assert.Len(t, images, 1)
+	})
+
+	t.Run("operation logs", func(t *testing.T) {
+		repo := NewOperationLogRepository(db)
+		_, total, err := repo.List(scoped, domain.OperationLogFilter{}, 1, 20)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		exported, err := repo.ListForExport(scoped, domain.OperationLogFilter{}, 0)
+		require.NoError(t, err)
+		assert.Empty(t, exported)
+		_, total, err = repo.List(ctx, domain.OperationLogFilter{}, 1, 20)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, total)
+	})
+}
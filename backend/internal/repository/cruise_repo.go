@@ -18,20 +18,29 @@ func NewCruiseRepository(db *gorm.DB) *CruiseRepository {
 	return &CruiseRepository{db: db}
 }
 
-// Create 插入一条新的邮轮记录。
+// Create 插入一条新的邮轮记录，所属公司必须在数据范围内。
 func (r *CruiseRepository) Create(ctx context.Context, cruise *domain.Cruise) error {
+	if err := ensureCompanyInScope(ctx, cruise.CompanyID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(cruise).Error
 }
 
-// Update 保存邮轮的所有字段修改。
+// Update 保存邮轮的所有字段修改；受限员工既不能修改范围外的邮轮，也不能把邮轮转到范围外的公司。
 func (r *CruiseRepository) Update(ctx context.Context, cruise *domain.Cruise) error {
+	if err := ensureCruiseInScope(ctx, r.db, cruise.ID); err != nil {
+		return err
+	}
+	if err := ensureCompanyInScope(ctx, cruise.CompanyID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Save(cruise).Error
 }
 
 // GetByID 根据主键查询邮轮记录，同时预加载所属公司信息。
 func (r *CruiseRepository) GetByID(ctx context.Context, id int64) (*domain.Cruise, error) {
 	var cruise domain.Cruise
-	if err := r.db.WithContext(ctx).Preload("Company").First(&cruise, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByCompany(ctx, "company_id")).Preload("Company").First(&cruise, id).Error; err != nil {
 		return nil, err
 	}
 	return &cruise, nil
@@ -41,7 +50,7 @@ func (r *CruiseRepository) GetByID(ctx context.Context, id int64) (*domain.Cruis
 func (r *CruiseRepository) List(ctx context.Context, companyID int64, keyword string, status *int16, sortBy string, page, pageSize int) ([]domain.Cruise, int64, error) {
 	var items []domain.Cruise
 	var total int64
-	q := r.db.WithContext(ctx).Model(&domain.Cruise{})
+	q := r.db.WithContext(ctx).Model(&domain.Cruise{}).Scopes(scopeByCompany(ctx, "company_id"))
 	if companyID > 0 {
 		q = q.Where("company_id = ?", companyID)
 	}
@@ -80,7 +89,7 @@ func (r *CruiseRepository) ListPublic(ctx context.Context, companyID int64, keyw
 
 // Delete 软删除指定的邮轮记录。
 func (r *CruiseRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.Cruise{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByCompany(ctx, "company_id")).Delete(&domain.Cruise{}, id))
 }
 
 // BatchUpdateStatus 批量更新邮轮状态，并在目标数量不匹配时回滚。
@@ -89,7 +98,7 @@ func (r *CruiseRepository) BatchUpdateStatus(ctx context.Context, ids []int64, s
 		return nil
 	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		res := tx.Model(&domain.Cruise{}).Where("id IN ?", ids).Update("status", status)
+		res := tx.Model(&domain.Cruise{}).Where("id IN ?", ids).Scopes(scopeByCompany(ctx, "company_id")).Update("status", status)
 		if res.Error != nil {
 			return res.Error
 		}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"gorm.io/gorm"
@@ -81,21 +82,42 @@ func (r *CustomDestinationRepository) GetByLabel(ctx context.Context, name, coun
 
 // UpsertByNameCountry 按名称+国家更新或新增自定义目的地。
 func (r *CustomDestinationRepository) UpsertByNameCountry(ctx context.Context, dest *domain.CustomDestination) error {
-	return r.db.WithContext(ctx).
-		Clauses(clause.OnConflict{
-			Columns: []clause.Column{{Name: "name"}, {Name: "country"}},
-			DoUpdates: clause.Assignments(map[string]any{
-				"latitude":    dest.Latitude,
-				"longitude":   dest.Longitude,
-				"keywords":    dest.Keywords,
-				"description": dest.Description,
-				"status":      dest.Status,
-				"sort_order":  dest.SortOrder,
-				"updated_at":  gorm.Expr("NOW()"),
-				"deleted_at":  nil,
-			}),
-		}).
-		Create(dest).Error
+	return upsertCustomDestination(r.db.WithContext(ctx), dest)
+}
+
+// UpsertBatch 在单个事务内按名称+国家批量更新或新增自定义目的地，任一失败整体回滚。
+func (r *CustomDestinationRepository) UpsertBatch(ctx context.Context, dests []domain.CustomDestination) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range dests {
+			if err := upsertCustomDestination(tx, &dests[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func upsertCustomDestination(db *gorm.DB, dest *domain.CustomDestination) error {
+	disabled := dest.Status == 0
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}, {Name: "country"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"latitude":    dest.Latitude,
+			"longitude":   dest.Longitude,
+			"keywords":    dest.Keywords,
+			"description": dest.Description,
+			"status":      dest.Status,
+			"sort_order":  dest.SortOrder,
+			"updated_at":  time.Now(),
+			"deleted_at":  nil,
+		}),
+	}).Create(dest).Error
+	if err != nil || !disabled {
+		return err
+	}
+	// status 列带默认值 1，新增停用目的地时 Create 会忽略零值并回填默认值，需回写
+	dest.Status = 0
+	return db.Model(&domain.CustomDestination{}).Where("id = ?", dest.ID).Update("status", 0).Error
 }
 
 // Delete 软删除指定的自定义目的地。
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newCustomDestinationTestRepo(t *testing.T) (*CustomDestinationRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.CustomDestination{}))
+	require.NoError(t, db.Exec(`CREATE UNIQUE INDEX idx_custom_destinations_name_country ON custom_destinations (name, country)`).Error)
+	return NewCustomDestinationRepository(db), db
+}
+
+func TestCustomDestinationRepository_UpsertBatch(t *testing.T) {
+	repo, db := newCustomDestinationTestRepo(t)
+	ctx := context.Background()
+	lat, lng := 25.7617, -80.1918
+	existing := domain.CustomDestination{Name: "迈阿密", Country: "美国", Keywords: "miami", Status: 1}
+	require.NoError(t, db.Create(&existing).Error)
+
+	err := repo.UpsertBatch(ctx, []domain.CustomDestination{
+		{Name: "迈阿密", Country: "美国", Latitude: &lat, Longitude: &lng, Keywords: "迈阿密,miami", Status: 1, SortOrder: 90},
+		{Name: "雷克雅未克", Country: "冰岛", Latitude: &lat, Longitude: &lng, Status: 0},
+	})
+	require.NoError(t, err)
+
+	items, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	var updated, created domain.CustomDestination
+	require.NoError(t, db.Where("name = ?", "迈阿密").First(&updated).Error)
+	require.NoError(t, db.Where("name = ?", "雷克雅未克").First(&created).Error)
+	assert.Equal(t, existing.ID, updated.ID)
+	assert.Equal(t, "迈阿密,miami", updated.Keywords)
+	assert.Equal(t, 90, updated.SortOrder)
+	assert.Equal(t, int16(0), created.Status, "停用目的地不应被列默认值覆盖")
+}
+
+func TestCustomDestinationRepository_UpsertBatchRollsBack(t *testing.T) {
+	repo, db := newCustomDestinationTestRepo(t)
+	require.NoError(t, db.Exec(`CREATE TRIGGER reject_boom BEFORE INSERT ON custom_destinations WHEN NEW.name = 'boom' BEGIN SELECT RAISE(ABORT, 'boom'); END`).Error)
+
+	err := repo.UpsertBatch(context.Background(), []domain.CustomDestination{
+		{Name: "温哥华", Country: "加拿大", Status: 1},
+		{Name: "boom", Country: "x", Status: 1},
+	})
+	require.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&domain.CustomDestination{}).Count(&count).Error)
+	assert.EqualValues(t, 0, count, "整批导入应回滚")
+}
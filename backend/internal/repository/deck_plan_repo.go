@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// DeckPlanRepository 提供甲板平面图、舱位坐标以及舱位图所需库存、价格与舱型数据的访问实现。
+type DeckPlanRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.DeckPlanRepository = (*DeckPlanRepository)(nil)
+
+// NewDeckPlanRepository 创建甲板平面图仓储实例。
+func NewDeckPlanRepository(db *gorm.DB) *DeckPlanRepository {
+	return &DeckPlanRepository{db: db}
+}
+
+// Create 插入甲板平面图；同一邮轮的甲板层已有平面图时返回 ErrDeckPlanExists。
+func (r *DeckPlanRepository) Create(ctx context.Context, plan *domain.DeckPlan) error {
+	if err := ensureCruiseInScope(ctx, r.db, plan.CruiseID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureDeckFree(tx, plan.CruiseID, plan.Deck, 0); err != nil {
+			return err
+		}
+		return tx.Omit("Cabins").Create(plan).Error
+	})
+}
+
+// Update 保存平面图基础信息（不含舱位坐标）；甲板层被同邮轮其他平面图占用时返回 ErrDeckPlanExists。
+func (r *DeckPlanRepository) Update(ctx context.Context, plan *domain.DeckPlan) error {
+	if err := r.ensurePlanInScope(ctx, plan.ID); err != nil {
+		return err
+	}
+	if err := ensureCruiseInScope(ctx, r.db, plan.CruiseID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureDeckFree(tx, plan.CruiseID, plan.Deck, plan.ID); err != nil {
+			return err
+		}
+		return tx.Omit("Cabins").Save(plan).Error
+	})
+}
+
+// ensurePlanInScope 校验平面图所属邮轮在数据范围内。
+func (r *DeckPlanRepository) ensurePlanInScope(ctx context.Context, id int64) error {
+	return ensureInScope(ctx, r.db.Model(&domain.DeckPlan{}).Where("id = ?", id).Scopes(scopeByCruise(ctx, "cruise_id")))
+}
+
+func ensureDeckFree(tx *gorm.DB, cruiseID int64, deck string, exceptID int64) error {
+	var count int64
+	if err := tx.Model(&domain.DeckPlan{}).Where("cruise_id = ? AND deck = ? AND id <> ?", cruiseID, deck, exceptID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrDeckPlanExists
+	}
+	return nil
+}
+
+// GetByID 查询平面图及其舱位坐标。
+func (r *DeckPlanRepository) GetByID(ctx context.Context, id int64) (*domain.DeckPlan, error) {
+	var plan domain.DeckPlan
+	if err := r.db.WithContext(ctx).Scopes(scopeByCruise(ctx, "cruise_id")).Preload("Cabins", orderByCabinNumber).First(&plan, id).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// ListByCruise 查询邮轮的全部甲板平面图（含舱位坐标），按排序值与甲板层排序。
+func (r *DeckPlanRepository) ListByCruise(ctx context.Context, cruiseID int64) ([]domain.DeckPlan, error) {
+	plans := []domain.DeckPlan{}
+	err := r.db.WithContext(ctx).Preload("Cabins", orderByCabinNumber).
+		Where("cruise_id = ?", cruiseID).Scopes(scopeByCruise(ctx, "cruise_id")).Order("sort_order asc, deck asc").Find(&plans).Error
+	return plans, err
+}
+
+func orderByCabinNumber(db *gorm.DB) *gorm.DB {
+	return db.Order("cabin_number asc")
+}
+
+// Delete 在单个事务内删除平面图及其舱位坐标。
+func (r *DeckPlanRepository) Delete(ctx context.Context, id int64) error {
+	if err := r.ensurePlanInScope(ctx, id); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("deck_plan_id = ?", id).Delete(&domain.DeckPlanCabin{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.DeckPlan{}, id).Error
+	})
+}
+
+// ReplaceCabins 在单个事务内以新的舱位坐标整体替换平面图原有坐标。
+func (r *DeckPlanRepository) ReplaceCabins(ctx context.Context, planID int64, cabins []domain.DeckPlanCabin) error {
+	if err := r.ensurePlanInScope(ctx, planID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("deck_plan_id = ?", planID).Delete(&domain.DeckPlanCabin{}).Error; err != nil {
+			return err
+		}
+		if len(cabins) == 0 {
+			return nil
+		}
+		for i := range cabins {
+			cabins[i].ID = 0
+			cabins[i].DeckPlanID = planID
+		}
+		return tx.CreateInBatches(cabins, 200).Error
+	})
+}
+
+// ListInventoriesBySKUs 批量查询舱房库存。
+func (r *DeckPlanRepository) ListInventoriesBySKUs(ctx context.Context, skuIDs []int64) ([]domain.CabinInventory, error) {
+	items := []domain.CabinInventory{}
+	if len(skuIDs) == 0 {
+		return items, nil
+	}
+	err := r.db.WithContext(ctx).Where("cabin_sku_id IN ?", skuIDs).Scopes(scopeBySKU(ctx, "cabin_sku_id")).Find(&items).Error
+	return items, err
+}
+
+// ListPricesBySKUs 批量查询舱房日历价格。
+func (r *DeckPlanRepository) ListPricesBySKUs(ctx context.Context, skuIDs []int64) ([]domain.CabinPrice, error) {
+	items := []domain.CabinPrice{}
+	if len(skuIDs) == 0 {
+		return items, nil
+	}
+	err := r.db.WithContext(ctx).Where("cabin_sku_id IN ?", skuIDs).Scopes(scopeBySKU(ctx, "cabin_sku_id")).Order("date asc, occupancy asc").Find(&items).Error
+	return items, err
+}
+
+// ListCabinTypesByIDs 批量查询舱房类型。
+func (r *DeckPlanRepository) ListCabinTypesByIDs(ctx context.Context, ids []int64) ([]domain.CabinType, error) {
+	items := []domain.CabinType{}
+	if len(ids) == 0 {
+		return items, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Scopes(scopeByCruise(ctx, "cruise_id")).Find(&items).Error
+	return items, err
+}
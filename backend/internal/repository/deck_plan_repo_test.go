@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newDeckPlanTestRepo(t *testing.T) (*DeckPlanRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.DeckPlan{}, &domain.DeckPlanCabin{}, &domain.CabinInventory{}, &domain.CabinPrice{}, &domain.CabinType{}))
+	return NewDeckPlanRepository(db), db
+}
+
+func TestDeckPlanRepository_CRUDAndCabins(t *testing.T) {
+	repo, _ := newDeckPlanTestRepo(t)
+	ctx := context.Background()
+	lower := &domain.DeckPlan{CruiseID: 1, Deck: "8", Name: "8 层", Width: 800, Height: 300, SortOrder: 2}
+	upper := &domain.DeckPlan{CruiseID: 1, Deck: "9", SortOrder: 1}
+	require.NoError(t, repo.Create(ctx, lower))
+	require.NoError(t, repo.Create(ctx, upper))
+	require.NoError(t, repo.Create(ctx, &domain.DeckPlan{CruiseID: 2, Deck: "8"}))
+	assert.ErrorIs(t, repo.Create(ctx, &domain.DeckPlan{CruiseID: 1, Deck: "8"}), domain.ErrDeckPlanExists)
+
+	upper.Deck = "8"
+	assert.ErrorIs(t, repo.Update(ctx, upper), domain.ErrDeckPlanExists)
+	upper.Deck = "10"
+	require.NoError(t, repo.Update(ctx, upper))
+
+	require.NoError(t, repo.ReplaceCabins(ctx, lower.ID, []domain.DeckPlanCabin{
+		{CabinNumber: "8002", X: 60, Y: 10, Width: 50, Height: 40},
+		{CabinNumber: "8001", X: 0, Y: 10, Width: 50, Height: 40},
+	}))
+	require.NoError(t, repo.ReplaceCabins(ctx, lower.ID, []domain.DeckPlanCabin{
+		{ID: 99, CabinNumber: "8003", X: 120, Y: 10, Width: 50, Height: 40},
+		{CabinNumber: "8001", X: 0, Y: 10, Width: 50, Height: 40},
+	}))
+	loaded, err := repo.GetByID(ctx, lower.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Cabins, 2, "替换后不保留旧坐标")
+	assert.Equal(t, "8001", loaded.Cabins[0].CabinNumber, "按舱房号排序")
+	assert.Equal(t, lower.ID, loaded.Cabins[1].DeckPlanID)
+
+	plans, err := repo.ListByCruise(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+	assert.Equal(t, "10", plans[0].Deck, "按排序值排序")
+	assert.Len(t, plans[1].Cabins, 2)
+
+	require.NoError(t, repo.Delete(ctx, lower.ID))
+	_, err = repo.GetByID(ctx, lower.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.NoError(t, repo.Create(ctx, &domain.DeckPlan{CruiseID: 1, Deck: "8"}), "删除后甲板层可再次使用")
+	plans, err = repo.ListByCruise(ctx, 1)
+	require.NoError(t, err)
+	for _, plan := range plans {
+		assert.Empty(t, plan.Cabins, "舱位坐标随平面图删除")
+	}
+}
+
+func TestDeckPlanRepository_BatchLookups(t *testing.T) {
+	repo, db := newDeckPlanTestRepo(t)
+	ctx := context.Background()
+	require.NoError(t, db.Create(&[]domain.CabinInventory{{CabinSKUID: 1, Total: 1}, {CabinSKUID: 2, Total: 1, Sold: 1}, {CabinSKUID: 3, Total: 1}}).Error)
+	require.NoError(t, db.Create(&[]domain.CabinPrice{{CabinSKUID: 1, Occupancy: 2, PriceCents: 100}, {CabinSKUID: 3, Occupancy: 2, PriceCents: 300}}).Error)
+	require.NoError(t, db.Create(&[]domain.CabinType{{CruiseID: 1, CategoryID: 1, Name: "海景房"}, {CruiseID: 1, CategoryID: 1, Name: "阳台房"}}).Error)
+
+	inventories, err := repo.ListInventoriesBySKUs(ctx, []int64{1, 2})
+	require.NoError(t, err)
+	assert.Len(t, inventories, 2)
+	prices, err := repo.ListPricesBySKUs(ctx, []int64{1, 2})
+	require.NoError(t, err)
+	require.Len(t, prices, 1)
+	assert.EqualValues(t, 100, prices[0].PriceCents)
+	types, err := repo.ListCabinTypesByIDs(ctx, []int64{2})
+	require.NoError(t, err)
+	require.Len(t, types, 1)
+	assert.Equal(t, "阳台房", types[0].Name)
+
+	empty, err := repo.ListInventoriesBySKUs(ctx, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, empty)
+}
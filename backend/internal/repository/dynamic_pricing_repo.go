@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// pricingSoldStatuses 为计入销售速度的订单状态。
+var pricingSoldStatuses = []string{
+	domain.OrderStatusPaid,
+	domain.OrderStatusConfirmed,
+	domain.OrderStatusPendingTravel,
+	domain.OrderStatusTraveling,
+	domain.OrderStatusCompleted,
+}
+
+// DynamicPricingRepository 提供动态调价规则与提案的数据访问实现。
+type DynamicPricingRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.DynamicPricingRepository = (*DynamicPricingRepository)(nil)
+
+func NewDynamicPricingRepository(db *gorm.DB) *DynamicPricingRepository {
+	return &DynamicPricingRepository{db: db}
+}
+
+// scopePricingRules 在受限上下文中只保留授权航次上的规则与适用全部航次（voyage_id = 0）的平台规则。
+func scopePricingRules(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, "(voyage_id = 0 OR voyage_id IN ("+voyageIDsInScope+"))")
+	}
+}
+
+// scopePricingProposals 在受限上下文中只保留全部明细都落在授权航次上的提案。
+func scopePricingProposals(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, "id NOT IN (SELECT proposal_id FROM dynamic_pricing_proposal_items WHERE voyage_id NOT IN ("+voyageIDsInScope+"))")
+	}
+}
+
+// ensurePricingRuleWritable 校验规则的适用航次可写；适用全部航次的平台规则仅平台管理员可写。
+func (r *DynamicPricingRepository) ensurePricingRuleWritable(ctx context.Context, voyageID int64) error {
+	if voyageID == 0 {
+		return ensureAllCompanies(ctx)
+	}
+	return ensureVoyageInScope(ctx, r.db, voyageID)
+}
+
+// ensureStoredRuleWritable 校验已保存的规则可由当前上下文修改。
+func (r *DynamicPricingRepository) ensureStoredRuleWritable(ctx context.Context, id int64) error {
+	return ensureInScope(ctx, r.db.Model(&domain.DynamicPricingRule{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Scopes(scopeByVoyage(ctx, "voyage_id")))
+}
+
+func (r *DynamicPricingRepository) ListRules(ctx context.Context, activeOnly bool) ([]domain.DynamicPricingRule, error) {
+	var items []domain.DynamicPricingRule
+	q := r.db.WithContext(ctx).Where("deleted_at IS NULL").Scopes(scopePricingRules(ctx))
+	if activeOnly {
+		q = q.Where("status = ?", 1)
+	}
+	if err := q.Order("priority desc, id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *DynamicPricingRepository) GetRule(ctx context.Context, id int64) (*domain.DynamicPricingRule, error) {
+	var item domain.DynamicPricingRule
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).Scopes(scopePricingRules(ctx)).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *DynamicPricingRepository) CreateRule(ctx context.Context, rule *domain.DynamicPricingRule) error {
+	if err := r.ensurePricingRuleWritable(ctx, rule.VoyageID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *DynamicPricingRepository) UpdateRule(ctx context.Context, rule *domain.DynamicPricingRule) error {
+	if err := r.ensureStoredRuleWritable(ctx, rule.ID); err != nil {
+		return err
+	}
+	if err := r.ensurePricingRuleWritable(ctx, rule.VoyageID); err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Model(&domain.DynamicPricingRule{}).
+		Where("id = ? AND deleted_at IS NULL", rule.ID).
+		Select("*").Omit("id", "created_at", "deleted_at").
+		Updates(rule)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *DynamicPricingRepository) DeleteRule(ctx context.Context, id int64) error {
+	if err := r.ensureStoredRuleWritable(ctx, id); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&domain.DynamicPricingRule{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", time.Now()).Error
+}
+
+// CreateProposal 在同一事务中写入提案及其明细。
+func (r *DynamicPricingRepository) CreateProposal(ctx context.Context, proposal *domain.DynamicPricingProposal) error {
+	checked := make(map[int64]bool, len(proposal.Items))
+	for _, item := range proposal.Items {
+		if checked[item.VoyageID] {
+			continue
+		}
+		if err := ensureVoyageInScope(ctx, r.db, item.VoyageID); err != nil {
+			return err
+		}
+		checked[item.VoyageID] = true
+	}
+	return r.db.WithContext(ctx).Create(proposal).Error
+}
+
+func (r *DynamicPricingRepository) GetProposal(ctx context.Context, id int64) (*domain.DynamicPricingProposal, error) {
+	var item domain.DynamicPricingProposal
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("voyage_id asc, cabin_type_id asc") }).
+		Scopes(scopePricingProposals(ctx)).
+		First(&item, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *DynamicPricingRepository) ListProposals(ctx context.Context, status string, page, pageSize int) ([]domain.DynamicPricingProposal, int64, error) {
+	var items []domain.DynamicPricingProposal
+	var total int64
+	q := r.db.WithContext(ctx).Model(&domain.DynamicPricingProposal{}).Scopes(scopePricingProposals(ctx))
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := q.Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// MarkProposalReviewed 以 status = pending 为条件写入审批结果，防止并发重复审批。
+func (r *DynamicPricingRepository) MarkProposalReviewed(ctx context.Context, proposal *domain.DynamicPricingProposal) error {
+	result := r.db.WithContext(ctx).Model(&domain.DynamicPricingProposal{}).
+		Where("id = ? AND status = ?", proposal.ID, domain.PricingProposalStatusPending).
+		Scopes(scopePricingProposals(ctx)).
+		Updates(map[string]interface{}{
+			"status":      proposal.Status,
+			"reviewed_by": proposal.ReviewedBy,
+			"reviewed_at": proposal.ReviewedAt,
+			"review_note": proposal.ReviewNote,
+			"updated_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPricingProposalReviewed
+	}
+	return nil
+}
+
+// UpdateProposalItems 在同一事务中回写明细状态、说明与生成的版本 ID。
+func (r *DynamicPricingRepository) UpdateProposalItems(ctx context.Context, items []domain.DynamicPricingProposalItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			if err := tx.Model(&domain.DynamicPricingProposalItem{}).
+				Where("id = ?", item.ID).
+				Updates(map[string]interface{}{
+					"status":     item.Status,
+					"version_id": item.VersionID,
+					"reason":     item.Reason,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListInventoryStats 按航次 + 舱型汇总舱房库存总量与已售量。
+func (r *DynamicPricingRepository) ListInventoryStats(ctx context.Context, voyageIDs []int64) ([]domain.PricingInventoryStat, error) {
+	out := make([]domain.PricingInventoryStat, 0)
+	if len(voyageIDs) == 0 {
+		return out, nil
+	}
+	err := r.db.WithContext(ctx).
+		Table("cabin_skus AS s").
+		Select("s.voyage_id AS voyage_id, s.cabin_type_id AS cabin_type_id, COALESCE(SUM(inv.total), 0) AS total, COALESCE(SUM(inv.sold), 0) AS sold").
+		Joins("JOIN cabin_inventories inv ON inv.cabin_sku_id = s.id").
+		Where("s.voyage_id IN ?", voyageIDs).
+		Scopes(scopeByVoyage(ctx, "s.voyage_id")).
+		Group("s.voyage_id, s.cabin_type_id").
+		Scan(&out).Error
+	return out, err
+}
+
+// ListSalesStats 统计 since 之后按航次 + 舱型售出的订单数。
+func (r *DynamicPricingRepository) ListSalesStats(ctx context.Context, voyageIDs []int64, since time.Time) ([]domain.PricingSalesStat, error) {
+	out := make([]domain.PricingSalesStat, 0)
+	if len(voyageIDs) == 0 {
+		return out, nil
+	}
+	err := r.db.WithContext(ctx).
+		Table("bookings AS b").
+		Select("b.voyage_id AS voyage_id, s.cabin_type_id AS cabin_type_id, COUNT(*) AS sold").
+		Joins("JOIN cabin_skus s ON s.id = b.cabin_sku_id").
+		Where("b.voyage_id IN ? AND b.created_at >= ? AND b.status IN ?", voyageIDs, since, pricingSoldStatuses).
+		Scopes(scopeByVoyage(ctx, "b.voyage_id")).
+		Group("b.voyage_id, s.cabin_type_id").
+		Scan(&out).Error
+	return out, err
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newDynamicPricingTestRepo 创建 SQLite 内存库并返回动态调价仓储实例。
+func newDynamicPricingTestRepo(t *testing.T) *DynamicPricingRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&domain.DynamicPricingRule{},
+		&domain.DynamicPricingProposal{},
+		&domain.DynamicPricingProposalItem{},
+		&domain.CabinSKU{},
+		&domain.CabinInventory{},
+		&domain.Booking{},
+	))
+	return NewDynamicPricingRepository(db)
+}
+
+func TestDynamicPricingRepository_RuleCRUD(t *testing.T) {
+	repo := newDynamicPricingTestRepo(t)
+	ctx := context.Background()
+
+	low := &domain.DynamicPricingRule{Name: "低优先级", VelocityWindowDays: 7, AdjustPercent: 5, Priority: 1, Status: 1}
+	high := &domain.DynamicPricingRule{Name: "高优先级", VelocityWindowDays: 7, AdjustPercent: 10, Priority: 9, Status: 1}
+	require.NoError(t, repo.CreateRule(ctx, low))
+	require.NoError(t, repo.CreateRule(ctx, high))
+
+	rules, err := repo.ListRules(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, high.ID, rules[0].ID, "按优先级降序")
+
+	low.Status = 0
+	require.NoError(t, repo.UpdateRule(ctx, low))
+	rules, err = repo.ListRules(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1, "停用规则不参与试算")
+
+	require.NoError(t, repo.DeleteRule(ctx, high.ID))
+	_, err = repo.GetRule(ctx, high.ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	assert.True(t, errors.Is(repo.UpdateRule(ctx, high), gorm.ErrRecordNotFound))
+	rules, err = repo.ListRules(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+}
+
+func TestDynamicPricingRepository_ProposalReview(t *testing.T) {
+	repo := newDynamicPricingTestRepo(t)
+	ctx := context.Background()
+
+	proposal := &domain.DynamicPricingProposal{
+		Status:    domain.PricingProposalStatusPending,
+		CreatedBy: 3,
+		Items: []domain.DynamicPricingProposalItem{
+			{VoyageID: 2, CabinTypeID: 10, CurrentSalePriceCents: 100, ProposedSalePriceCents: 110, Status: domain.PricingProposalItemStatusPending},
+			{VoyageID: 1, CabinTypeID: 10, CurrentSalePriceCents: 200, ProposedSalePriceCents: 220, Status: domain.PricingProposalItemStatusPending},
+		},
+	}
+	require.NoError(t, repo.CreateProposal(ctx, proposal))
+
+	got, err := repo.GetProposal(ctx, proposal.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Items, 2)
+	assert.Equal(t, int64(1), got.Items[0].VoyageID)
+
+	now := time.Now()
+	got.Status = domain.PricingProposalStatusApproved
+	got.ReviewedBy = 5
+	got.ReviewedAt = &now
+	require.NoError(t, repo.MarkProposalReviewed(ctx, got))
+	assert.True(t, errors.Is(repo.MarkProposalReviewed(ctx, got), domain.ErrPricingProposalReviewed), "重复审批应被拒绝")
+
+	got.Items[0].Status = domain.PricingProposalItemStatusApplied
+	got.Items[0].VersionID = 42
+	require.NoError(t, repo.UpdateProposalItems(ctx, got.Items))
+
+	reloaded, err := repo.GetProposal(ctx, proposal.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PricingProposalStatusApproved, reloaded.Status)
+	assert.Equal(t, int64(5), reloaded.ReviewedBy)
+	assert.Equal(t, int64(42), reloaded.Items[0].VersionID)
+
+	list, total, err := repo.ListProposals(ctx, domain.PricingProposalStatusPending, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, list)
+}
+
+func TestDynamicPricingRepository_Stats(t *testing.T) {
+	repo := newDynamicPricingTestRepo(t)
+	ctx := context.Background()
+	db := repo.db
+
+	skus := []domain.CabinSKU{
+		{ID: 1, VoyageID: 1, CabinTypeID: 10, Code: "A1"},
+		{ID: 2, VoyageID: 1, CabinTypeID: 10, Code: "A2"},
+		{ID: 3, VoyageID: 1, CabinTypeID: 20, Code: "B1"},
+		{ID: 4, VoyageID: 2, CabinTypeID: 10, Code: "C1"},
+	}
+	require.NoError(t, db.Create(&skus).Error)
+	require.NoError(t, db.Create(&[]domain.CabinInventory{
+		{CabinSKUID: 1, Total: 5, Sold: 4},
+		{CabinSKUID: 2, Total: 5, Sold: 1},
+		{CabinSKUID: 3, Total: 8, Sold: 0},
+		{CabinSKUID: 4, Total: 3, Sold: 3},
+	}).Error)
+
+	inv, err := repo.ListInventoryStats(ctx, []int64{1})
+	require.NoError(t, err)
+	require.Len(t, inv, 2)
+	byType := map[int64]domain.PricingInventoryStat{}
+	for _, s := range inv {
+		byType[s.CabinTypeID] = s
+	}
+	assert.Equal(t, int64(10), byType[10].Total)
+	assert.Equal(t, int64(5), byType[10].Sold)
+
+	now := time.Now()
+	require.NoError(t, db.Create(&[]domain.Booking{
+		{UserID: 1, VoyageID: 1, CabinSKUID: 1, Status: domain.OrderStatusPaid, CreatedAt: now},
+		{UserID: 2, VoyageID: 1, CabinSKUID: 2, Status: domain.OrderStatusConfirmed, CreatedAt: now},
+		{UserID: 3, VoyageID: 1, CabinSKUID: 1, Status: domain.OrderStatusCancelled, CreatedAt: now},
+		{UserID: 4, VoyageID: 1, CabinSKUID: 3, Status: domain.OrderStatusPaid, CreatedAt: now.AddDate(0, 0, -30)},
+	}).Error)
+
+	sales, err := repo.ListSalesStats(ctx, []int64{1}, now.AddDate(0, 0, -7))
+	require.NoError(t, err)
+	require.Len(t, sales, 1)
+	assert.Equal(t, int64(10), sales[0].CabinTypeID)
+	assert.Equal(t, int64(2), sales[0].Sold)
+
+	empty, err := repo.ListSalesStats(ctx, nil, now)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
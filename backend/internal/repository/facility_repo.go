@@ -19,18 +19,27 @@ func NewFacilityRepository(db *gorm.DB) *FacilityRepository {
 
 // Create 插入一条新的设施记录。
 func (r *FacilityRepository) Create(ctx context.Context, facility *domain.Facility) error {
+	if err := ensureCruiseInScope(ctx, r.db, facility.CruiseID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(facility).Error
 }
 
-// Update 保存设施的所有字段修改。
+// Update 保存设施的所有字段修改；原邮轮与目标邮轮都须在数据范围内。
 func (r *FacilityRepository) Update(ctx context.Context, facility *domain.Facility) error {
+	if err := ensureInScope(ctx, r.db.Model(&domain.Facility{}).Where("id = ?", facility.ID).Scopes(scopeByCruise(ctx, "cruise_id"))); err != nil {
+		return err
+	}
+	if err := ensureCruiseInScope(ctx, r.db, facility.CruiseID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Save(facility).Error
 }
 
 // GetByID 根据主键查询设施记录。
 func (r *FacilityRepository) GetByID(ctx context.Context, id int64) (*domain.Facility, error) {
 	var item domain.Facility
-	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByCruise(ctx, "cruise_id")).First(&item, id).Error; err != nil {
 		return nil, err
 	}
 	return &item, nil
@@ -39,7 +48,7 @@ func (r *FacilityRepository) GetByID(ctx context.Context, id int64) (*domain.Fac
 // ListByCruise 查询指定邮轮下的所有设施，按排序权重和 ID 降序排列。
 func (r *FacilityRepository) ListByCruise(ctx context.Context, cruiseID int64) ([]domain.Facility, error) {
 	var items []domain.Facility
-	if err := r.db.WithContext(ctx).Model(&domain.Facility{}).Where("cruise_id = ?", cruiseID).Order("sort_order desc, id desc").Find(&items).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&domain.Facility{}).Where("cruise_id = ?", cruiseID).Scopes(scopeByCruise(ctx, "cruise_id")).Order("sort_order desc, id desc").Find(&items).Error; err != nil {
 		return nil, err
 	}
 	return items, nil
@@ -48,7 +57,7 @@ func (r *FacilityRepository) ListByCruise(ctx context.Context, cruiseID int64) (
 // ListByCruiseAndCategory 按邮轮和分类筛选设施。
 func (r *FacilityRepository) ListByCruiseAndCategory(ctx context.Context, cruiseID, categoryID int64) ([]domain.Facility, error) {
 	var items []domain.Facility
-	q := r.db.WithContext(ctx).Model(&domain.Facility{}).Where("cruise_id = ?", cruiseID)
+	q := r.db.WithContext(ctx).Model(&domain.Facility{}).Where("cruise_id = ?", cruiseID).Scopes(scopeByCruise(ctx, "cruise_id"))
 	if categoryID > 0 {
 		q = q.Where("category_id = ?", categoryID)
 	}
@@ -60,5 +69,5 @@ func (r *FacilityRepository) ListByCruiseAndCategory(ctx context.Context, cruise
 
 // Delete 软删除指定的设施记录。
 func (r *FacilityRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.Facility{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByCruise(ctx, "cruise_id")).Delete(&domain.Facility{}, id))
 }
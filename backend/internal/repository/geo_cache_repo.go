@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RouteMapCacheRepository 提供航线地图缓存的数据库操作。
+type RouteMapCacheRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.RouteMapCacheRepository = (*RouteMapCacheRepository)(nil)
+
+// NewRouteMapCacheRepository 创建航线地图缓存仓储实例。
+func NewRouteMapCacheRepository(db *gorm.DB) *RouteMapCacheRepository {
+	return &RouteMapCacheRepository{db: db}
+}
+
+// Get 查询未过期的航线地图缓存。
+func (r *RouteMapCacheRepository) Get(ctx context.Context, key string, now time.Time) (*domain.RouteMapCache, error) {
+	var entry domain.RouteMapCache
+	err := r.db.WithContext(ctx).
+		Where("cache_key = ? AND (expires_at IS NULL OR expires_at > ?)", key, now).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Upsert 按缓存键写入或覆盖航线地图。
+func (r *RouteMapCacheRepository) Upsert(ctx context.Context, entry *domain.RouteMapCache) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "cache_key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"provider", "resolution_km", "stop_count", "payload", "expires_at", "updated_at"}),
+		}).
+		Create(entry).Error
+}
+
+// Delete 删除指定缓存键，未传键时不做任何操作。
+func (r *RouteMapCacheRepository) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("cache_key IN ?", keys).Delete(&domain.RouteMapCache{}).Error
+}
+
+// PurgeExpired 清理已过期的航线地图缓存，返回删除条数。
+func (r *RouteMapCacheRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&domain.RouteMapCache{})
+	return result.RowsAffected, result.Error
+}
+
+// GeocodeCacheRepository 提供地理编码缓存的数据库操作。
+type GeocodeCacheRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.GeocodeCacheRepository = (*GeocodeCacheRepository)(nil)
+
+// NewGeocodeCacheRepository 创建地理编码缓存仓储实例。
+func NewGeocodeCacheRepository(db *gorm.DB) *GeocodeCacheRepository {
+	return &GeocodeCacheRepository{db: db}
+}
+
+// Get 查询未过期的地理编码缓存。
+func (r *GeocodeCacheRepository) Get(ctx context.Context, provider, query string, now time.Time) (*domain.GeocodeCache, error) {
+	var entry domain.GeocodeCache
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND query = ? AND expires_at > ?", provider, query, now).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Upsert 按服务商与查询词写入或覆盖地理编码结果。
+func (r *GeocodeCacheRepository) Upsert(ctx context.Context, entry *domain.GeocodeCache) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "query"}},
+			DoUpdates: clause.AssignmentColumns([]string{"payload", "expires_at", "updated_at"}),
+		}).
+		Create(entry).Error
+}
+
+// PurgeExpired 清理已过期的地理编码缓存，返回删除条数。
+func (r *GeocodeCacheRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.GeocodeCache{})
+	return result.RowsAffected, result.Error
+}
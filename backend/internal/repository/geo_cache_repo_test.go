@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newGeoCacheTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.RouteMapCache{}, &domain.GeocodeCache{}))
+	return db
+}
+
+func TestRouteMapCacheRepository_UpsertGetDelete(t *testing.T) {
+	repo := NewRouteMapCacheRepository(newGeoCacheTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Hour)
+
+	require.NoError(t, repo.Upsert(ctx, &domain.RouteMapCache{CacheKey: "k1", Provider: "searoute_offline", ResolutionKM: 20, StopCount: 2, Payload: `{"provider":"searoute_offline"}`, ExpiresAt: &expires}))
+	require.NoError(t, repo.Upsert(ctx, &domain.RouteMapCache{CacheKey: "k1", Provider: "searoute", ResolutionKM: 20, StopCount: 2, Payload: `{"provider":"searoute"}`}))
+	require.NoError(t, repo.Upsert(ctx, &domain.RouteMapCache{CacheKey: "k2", Provider: "searoute_offline", ResolutionKM: 20, StopCount: 3, Payload: `{}`, ExpiresAt: &expires}))
+
+	entry, err := repo.Get(ctx, "k1", now.Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "searoute", entry.Provider, "覆盖后的远程航线长期有效")
+	assert.Nil(t, entry.ExpiresAt)
+
+	_, err = repo.Get(ctx, "k2", now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "过期缓存视为未命中")
+
+	purged, err := repo.PurgeExpired(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, purged)
+
+	require.NoError(t, repo.Delete(ctx))
+	require.NoError(t, repo.Delete(ctx, "k1", "missing"))
+	_, err = repo.Get(ctx, "k1", now)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGeocodeCacheRepository_UpsertGetPurge(t *testing.T) {
+	repo := NewGeocodeCacheRepository(newGeoCacheTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	require.NoError(t, repo.Upsert(ctx, &domain.GeocodeCache{Provider: "nominatim", Query: "福冈", Payload: `[]`, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, repo.Upsert(ctx, &domain.GeocodeCache{Provider: "nominatim", Query: "福冈", Payload: `[{"name":"福冈"}]`, ExpiresAt: now.Add(24 * time.Hour)}))
+
+	entry, err := repo.Get(ctx, "nominatim", "福冈", now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, `[{"name":"福冈"}]`, entry.Payload)
+
+	_, err = repo.Get(ctx, "other", "福冈", now)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	purged, err := repo.PurgeExpired(ctx, now.Add(25*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, purged)
+	_, err = repo.Get(ctx, "nominatim", "福冈", now)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository 基于 idempotency_keys 表实现幂等记录存储，过期判断以调用方传入的 now 为准。
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.IdempotencyStore = (*IdempotencyRepository)(nil)
+
+// NewIdempotencyRepository 创建幂等记录仓储实例。
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve 以 upsert 占用键：键不存在或已过期时写入成功，多实例并发时只有一个请求能占用同一个键。
+func (r *IdempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, bool, error) {
+	record.StatusCode, record.ContentType, record.ResponseBody = 0, "", ""
+	record.CreatedAt, record.UpdatedAt = now, now
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "idem_key"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"fingerprint": record.Fingerprint, "status_code": 0, "content_type": "", "response_body": "",
+				"expires_at": record.ExpiresAt, "created_at": now, "updated_at": now,
+			}),
+			Where: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "idempotency_keys.expires_at <= ?", Vars: []any{now}}}},
+		}).
+		Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil, true, nil
+	}
+	var existing domain.IdempotencyRecord
+	if err := r.db.WithContext(ctx).Where("idem_key = ?", record.Key).First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+// Complete 保存原请求的响应。
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, statusCode int, contentType, body string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.IdempotencyRecord{}).
+		Where("idem_key = ?", key).
+		Updates(map[string]any{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+			"expires_at":    expiresAt,
+			"updated_at":    time.Now(),
+		}).Error
+}
+
+// Release 删除仍在处理中的记录，已完成的记录不受影响。
+func (r *IdempotencyRepository) Release(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("idem_key = ? AND status_code = 0", key).Delete(&domain.IdempotencyRecord{}).Error
+}
+
+// PurgeExpired 删除已过期的记录。
+func (r *IdempotencyRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestIdempotencyRepository_ReserveCompleteRelease(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.IdempotencyRecord{}))
+	repo := NewIdempotencyRepository(db)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	existing, ok, err := repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "k1", Fingerprint: "fp1", ExpiresAt: now.Add(time.Minute)}, now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, existing)
+
+	existing, ok, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "k1", Fingerprint: "fp2", ExpiresAt: now.Add(time.Minute)}, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.False(t, ok, "未过期的键不能被再次占用")
+	require.NotNil(t, existing)
+	assert.Equal(t, "fp1", existing.Fingerprint)
+	assert.False(t, existing.Completed())
+
+	require.NoError(t, repo.Complete(ctx, "k1", 200, "application/json", `{"id":1}`, now.Add(24*time.Hour)))
+	existing, ok, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "k1", Fingerprint: "fp1", ExpiresAt: now.Add(time.Minute)}, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok, "完成后的记录在整个有效期内保留")
+	assert.True(t, existing.Completed())
+	assert.Equal(t, `{"id":1}`, existing.ResponseBody)
+
+	require.NoError(t, repo.Release(ctx, "k1"))
+	_, ok, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "k1", Fingerprint: "fp1", ExpiresAt: now.Add(time.Minute)}, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok, "Release 不删除已完成的记录")
+
+	_, ok, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "k2", Fingerprint: "fp", ExpiresAt: now.Add(time.Minute)}, now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, repo.Release(ctx, "k2"))
+	_, ok, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "k2", Fingerprint: "fp", ExpiresAt: now.Add(time.Minute)}, now)
+	require.NoError(t, err)
+	assert.True(t, ok, "释放后的键可以立即重试")
+
+	existing, ok, err = repo.Reserve(ctx, &domain.IdempotencyRecord{Key: "k2", Fingerprint: "other", ExpiresAt: now.Add(2 * time.Minute)}, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, ok, "过期（含处理超时）的记录可被重新占用")
+	assert.Nil(t, existing)
+
+	purged, err := repo.PurgeExpired(ctx, now.Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, purged)
+}
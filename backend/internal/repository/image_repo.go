@@ -17,8 +17,52 @@ func NewImageRepository(db *gorm.DB) *ImageRepository {
 	return &ImageRepository{db: db}
 }
 
+// imageEntityScopes 把图片关联的实体类型映射到授权公司名下实体的 ID 子查询；
+// 未列出的实体类型不归属任何公司，受公司范围限制的员工不能读写。
+var imageEntityScopes = map[string]string{
+	"cruise":     cruiseIDsInScope,
+	"voyage":     voyageIDsInScope,
+	"cabin":      skuIDsInScope,
+	"cabin_type": cabinTypeIDsInScope,
+	"facility":   facilityIDsInScope,
+}
+
+// scopeImageEntity 按图片关联实体的归属公司过滤。
+func scopeImageEntity(ctx context.Context, entityType string) func(*gorm.DB) *gorm.DB {
+	subquery, ok := imageEntityScopes[entityType]
+	if !ok {
+		return scopeAllCompaniesOnly(ctx)
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, "entity_id IN ("+subquery+")")
+	}
+}
+
+// ensureImageEntityInScope 校验写入图片的关联实体属于授权公司。
+func ensureImageEntityInScope(ctx context.Context, db *gorm.DB, entityType string, entityID int64) error {
+	companyIDs, ok := domain.CompanyScopeFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	subquery, known := imageEntityScopes[entityType]
+	if !known || len(companyIDs) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	var count int64
+	if err := db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+subquery+") scoped WHERE scoped.id = ?", companyIDs, entityID).Scan(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // Create 新增图片记录。
 func (r *ImageRepository) Create(ctx context.Context, img *domain.Image) error {
+	if err := ensureImageEntityInScope(ctx, r.db, img.EntityType, img.EntityID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(img).Error
 }
 
@@ -28,6 +72,7 @@ func (r *ImageRepository) ListByEntity(ctx context.Context, entityType string, e
 	err := r.db.WithContext(ctx).
 		Model(&domain.Image{}).
 		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Scopes(scopeImageEntity(ctx, entityType)).
 		Order("sort_order asc, id asc").
 		Find(&items).Error
 	if err != nil {
@@ -38,6 +83,9 @@ func (r *ImageRepository) ListByEntity(ctx context.Context, entityType string, e
 
 // DeleteByEntity 删除指定实体下的全部图片记录。
 func (r *ImageRepository) DeleteByEntity(ctx context.Context, entityType string, entityID int64) error {
+	if err := ensureImageEntityInScope(ctx, r.db, entityType, entityID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).
 		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
 		Delete(&domain.Image{}).Error
@@ -45,11 +93,23 @@ func (r *ImageRepository) DeleteByEntity(ctx context.Context, entityType string,
 
 // UpdateSortOrder 更新单张图片的排序权重。
 func (r *ImageRepository) UpdateSortOrder(ctx context.Context, id int64, sortOrder int) error {
+	if _, ok := domain.CompanyScopeFromContext(ctx); ok {
+		var img domain.Image
+		if err := r.db.WithContext(ctx).Select("entity_type", "entity_id").First(&img, id).Error; err != nil {
+			return err
+		}
+		if err := ensureImageEntityInScope(ctx, r.db, img.EntityType, img.EntityID); err != nil {
+			return err
+		}
+	}
 	return r.db.WithContext(ctx).Model(&domain.Image{}).Where("id = ?", id).Update("sort_order", sortOrder).Error
 }
 
 // ReplaceImages 在事务内先删除旧图再批量插入新图，保证原子性。
 func (r *ImageRepository) ReplaceImages(ctx context.Context, entityType string, entityID int64, images []*domain.Image) error {
+	if err := ensureImageEntityInScope(ctx, r.db, entityType, entityID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Delete(&domain.Image{}).Error; err != nil {
 			return err
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// ImportJobRepository 提供异步批量导入任务的数据访问实现。
+type ImportJobRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.ImportJobRepository = (*ImportJobRepository)(nil)
+
+// NewImportJobRepository 创建导入任务仓储实例。
+func NewImportJobRepository(db *gorm.DB) *ImportJobRepository {
+	return &ImportJobRepository{db: db}
+}
+
+func (r *ImportJobRepository) Create(ctx context.Context, job *domain.ImportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *ImportJobRepository) Update(ctx context.Context, job *domain.ImportJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+func (r *ImportJobRepository) GetByID(ctx context.Context, id int64) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List 分页查询任务，列表不加载体积较大的错误报告。
+func (r *ImportJobRepository) List(ctx context.Context, kind, status string, page, pageSize int) ([]domain.ImportJob, int64, error) {
+	q := r.db.WithContext(ctx).Model(&domain.ImportJob{})
+	if kind != "" {
+		q = q.Where("kind = ?", kind)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	items := []domain.ImportJob{}
+	if err := q.Omit("error_report").Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// FailUnfinished 把排队中或处理中的任务标记为失败；任务在内存中执行，服务重启后无法继续。
+func (r *ImportJobRepository) FailUnfinished(ctx context.Context, message string, finishedAt time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.ImportJob{}).
+		Where("status IN ?", []string{domain.ImportJobPending, domain.ImportJobRunning}).
+		Updates(map[string]any{"status": domain.ImportJobFailed, "message": message, "finished_at": finishedAt})
+	return result.RowsAffected, result.Error
+}
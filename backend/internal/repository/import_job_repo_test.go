@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newImportJobTestRepo(t *testing.T) *ImportJobRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.ImportJob{}))
+	return NewImportJobRepository(db)
+}
+
+func TestImportJobRepository_ListAndFailUnfinished(t *testing.T) {
+	repo := newImportJobTestRepo(t)
+	ctx := context.Background()
+	jobs := []*domain.ImportJob{
+		{Kind: "custom_destinations", Status: domain.ImportJobSucceeded, Mode: "best_effort", Failed: 1, ErrorReport: "_row,_error\n2,bad\n"},
+		{Kind: "custom_destinations", Status: domain.ImportJobRunning, Mode: "all_or_nothing"},
+		{Kind: "ports", Status: domain.ImportJobPending, Mode: "all_or_nothing"},
+	}
+	for _, job := range jobs {
+		require.NoError(t, repo.Create(ctx, job))
+	}
+
+	items, total, err := repo.List(ctx, "custom_destinations", "", 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, items, 2)
+	assert.Equal(t, jobs[1].ID, items[0].ID, "按 ID 倒序")
+	assert.Empty(t, items[1].ErrorReport, "列表不加载错误报告")
+
+	loaded, err := repo.GetByID(ctx, jobs[0].ID)
+	require.NoError(t, err)
+	assert.Contains(t, loaded.ErrorReport, "2,bad")
+
+	finished := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	n, err := repo.FailUnfinished(ctx, "interrupted", finished)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	failed, total, err := repo.List(ctx, "", domain.ImportJobFailed, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.Equal(t, "interrupted", failed[0].Message)
+	require.NotNil(t, failed[0].FinishedAt)
+
+	loaded.Status = domain.ImportJobFailed
+	require.NoError(t, repo.Update(ctx, loaded))
+	_, total, err = repo.List(ctx, "", domain.ImportJobSucceeded, 1, 20)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
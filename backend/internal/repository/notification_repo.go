@@ -32,6 +32,13 @@ func (r *NotificationRepository) ListPending(ctx context.Context, limit int) ([]
 	return list, err
 }
 
+// CountPending 统计尚未投递的待处理通知数。
+func (r *NotificationRepository) CountPending(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Notification{}).Where("status = ?", "pending").Count(&count).Error
+	return count, err
+}
+
 // MarkSent 将通知状态标记为 "sent"（已发送）。
 func (r *NotificationRepository) MarkSent(ctx context.Context, id int64) error {
 	return r.db.WithContext(ctx).
@@ -47,6 +47,17 @@ func TestNotificationRepository_ListPending(t *testing.T) {
 	assert.Equal(t, "pending", list[1].Status)
 }
 
+func TestNotificationRepository_CountPending(t *testing.T) {
+	repo := newNotificationTestRepo(t)
+	db := repo.db
+	require.NoError(t, db.Create(&domain.Notification{UserID: 1, Channel: "sms", Template: "a", Payload: "{}", Status: "pending"}).Error)
+	require.NoError(t, db.Create(&domain.Notification{UserID: 2, Channel: "sms", Template: "b", Payload: "{}", Status: "sent"}).Error)
+
+	count, err := repo.CountPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
 func TestNotificationRepository_ListPending_Limit(t *testing.T) {
 	repo := newNotificationTestRepo(t)
 	db := repo.db
@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// operationLogChainHeadID 是链头表中唯一一行的主键。
+const operationLogChainHeadID = 1
+
 // OperationLogRepository handles persistence for operation_logs.
 type OperationLogRepository struct {
 	db *gorm.DB
@@ -16,6 +22,135 @@ func NewOperationLogRepository(db *gorm.DB) *OperationLogRepository {
 	return &OperationLogRepository{db: db}
 }
 
+var _ domain.OperationLogRepository = (*OperationLogRepository)(nil)
+
+// Create 在同一事务中锁定链头、计算哈希、写入日志并推进链头，
+// 多实例并发写入时由链头行锁串行化，保证哈希链不会分叉。
 func (r *OperationLogRepository) Create(ctx context.Context, log *domain.OperationLog) error {
-	return r.db.WithContext(ctx).Create(log).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var head domain.OperationLogChainHead
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&head, operationLogChainHeadID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			head = domain.OperationLogChainHead{ID: operationLogChainHeadID}
+			err = tx.Create(&head).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if log.CreatedAt.IsZero() {
+			log.CreatedAt = time.Now()
+		}
+		// 数据库时间戳精度为微秒，先截断再参与哈希，保证读回后可重算
+		log.CreatedAt = log.CreatedAt.UTC().Truncate(time.Microsecond)
+		log.PrevHash = head.LastHash
+		log.Hash = log.ChainHash()
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+		return tx.Model(&head).Updates(map[string]any{"last_log_id": log.ID, "last_hash": log.Hash, "updated_at": time.Now()}).Error
+	})
+}
+
+// List 分页查询操作日志。日志含各公司数据的变更前后快照，受公司范围限制的员工查不到任何日志。
+func (r *OperationLogRepository) List(ctx context.Context, filter domain.OperationLogFilter, page, pageSize int) ([]domain.OperationLog, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := applyOperationLogFilter(r.db.WithContext(ctx).Model(&domain.OperationLog{}).Scopes(scopeAllCompaniesOnly(ctx)), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []domain.OperationLog
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error
+	return items, total, err
+}
+
+// ListForExport 查询用于导出的操作日志，与 List 一样仅平台管理员可见。
+func (r *OperationLogRepository) ListForExport(ctx context.Context, filter domain.OperationLogFilter, limit int) ([]domain.OperationLog, error) {
+	if limit <= 0 {
+		limit = 5000
+	}
+	var items []domain.OperationLog
+	err := applyOperationLogFilter(r.db.WithContext(ctx).Model(&domain.OperationLog{}).Scopes(scopeAllCompaniesOnly(ctx)), filter).
+		Order("id DESC").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// VerifyChain 按 ID 顺序逐条重算哈希，校验 prev_hash 衔接，并确认最后一条与链头一致（防止尾部被截断）。
+// 引入哈希链之前写入的历史日志 hash 为空，不参与校验。
+func (r *OperationLogRepository) VerifyChain(ctx context.Context) (*domain.OperationLogChainReport, error) {
+	report := &domain.OperationLogChainReport{Valid: true}
+	var prevHash string
+	var lastID int64
+	var batch []domain.OperationLog
+	result := r.db.WithContext(ctx).Where("hash <> ''").Order("id ASC").FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+		for i := range batch {
+			log := &batch[i]
+			switch {
+			case log.PrevHash != prevHash:
+				report.Valid, report.BrokenAtID, report.Reason = false, log.ID, "prev_hash does not match the preceding entry"
+			case log.ChainHash() != log.Hash:
+				report.Valid, report.BrokenAtID, report.Reason = false, log.ID, "entry content does not match its hash"
+			}
+			if !report.Valid {
+				return errStopChainVerify
+			}
+			report.Checked++
+			prevHash, lastID = log.Hash, log.ID
+		}
+		return nil
+	})
+	if result.Error != nil && !errors.Is(result.Error, errStopChainVerify) {
+		return nil, result.Error
+	}
+	if !report.Valid {
+		return report, nil
+	}
+
+	var head domain.OperationLogChainHead
+	err := r.db.WithContext(ctx).First(&head, operationLogChainHeadID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if head.LastHash != prevHash || head.LastLogID != lastID {
+		report.Valid, report.BrokenAtID, report.Reason = false, head.LastLogID, "chain head does not match the last entry"
+	}
+	return report, nil
+}
+
+var errStopChainVerify = errors.New("stop chain verification")
+
+func applyOperationLogFilter(query *gorm.DB, filter domain.OperationLogFilter) *gorm.DB {
+	if filter.StaffID > 0 {
+		query = query.Where("staff_id = ?", filter.StaffID)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.ResourceID > 0 {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Operation != "" {
+		query = query.Where("operation = ?", filter.Operation)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	return query
 }
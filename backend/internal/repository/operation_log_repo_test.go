@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newOperationLogTestRepo(t *testing.T) (*OperationLogRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.OperationLog{}, &domain.OperationLogChainHead{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewOperationLogRepository(db), db
+}
+
+func TestOperationLogRepositoryChainsEntries(t *testing.T) {
+	repo, db := newOperationLogTestRepo(t)
+	ctx := context.Background()
+	// 引入哈希链之前的历史日志没有 hash，不参与校验
+	if err := db.Create(&domain.OperationLog{StaffID: 1, Operation: "assign_role"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	first := &domain.OperationLog{StaffID: 1, Operation: "update", Resource: "cruises", ResourceID: 3, Method: "PUT"}
+	second := &domain.OperationLog{StaffID: 2, Operation: "delete", Resource: "voyages", ResourceID: 9, Method: "DELETE"}
+	for _, log := range []*domain.OperationLog{first, second} {
+		if err := repo.Create(ctx, log); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.PrevHash != "" || first.Hash == "" || second.PrevHash != first.Hash {
+		t.Fatalf("entries are not chained: %+v %+v", first, second)
+	}
+
+	report, err := repo.VerifyChain(ctx)
+	if err != nil || !report.Valid || report.Checked != 2 {
+		t.Fatalf("expected a valid chain of 2 entries, got %+v %v", report, err)
+	}
+
+	items, total, err := repo.List(ctx, domain.OperationLogFilter{Resource: "cruises", ResourceID: 3}, 1, 20)
+	if err != nil || total != 1 || len(items) != 1 || items[0].ID != first.ID {
+		t.Fatalf("unexpected filtered list: %+v %d %v", items, total, err)
+	}
+	items, err = repo.ListForExport(ctx, domain.OperationLogFilter{From: time.Now().Add(-time.Hour), Method: "DELETE"}, 10)
+	if err != nil || len(items) != 1 || items[0].ID != second.ID {
+		t.Fatalf("unexpected export rows: %+v %v", items, err)
+	}
+}
+
+func TestOperationLogRepositoryDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	seed := func(t *testing.T) (*OperationLogRepository, *gorm.DB, []*domain.OperationLog) {
+		repo, db := newOperationLogTestRepo(t)
+		logs := []*domain.OperationLog{
+			{StaffID: 1, Operation: "create", Resource: "cruises"},
+			{StaffID: 1, Operation: "update", Resource: "cruises", Changes: `{"name":{"before":"A","after":"B"}}`},
+			{StaffID: 1, Operation: "delete", Resource: "cruises"},
+		}
+		for _, log := range logs {
+			if err := repo.Create(ctx, log); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return repo, db, logs
+	}
+
+	repo, db, logs := seed(t)
+	db.Model(&domain.OperationLog{}).Where("id = ?", logs[1].ID).Update("changes", `{"name":{"before":"A","after":"C"}}`)
+	report, err := repo.VerifyChain(ctx)
+	if err != nil || report.Valid || report.BrokenAtID != logs[1].ID {
+		t.Fatalf("edited entry should break the chain: %+v %v", report, err)
+	}
+
+	repo, db, logs = seed(t)
+	db.Delete(&domain.OperationLog{}, logs[1].ID)
+	report, _ = repo.VerifyChain(ctx)
+	if report.Valid || report.BrokenAtID != logs[2].ID {
+		t.Fatalf("deleted entry should break the chain: %+v", report)
+	}
+
+	repo, db, logs = seed(t)
+	db.Delete(&domain.OperationLog{}, logs[2].ID)
+	report, _ = repo.VerifyChain(ctx)
+	if report.Valid {
+		t.Fatalf("truncated tail should be detected via the chain head: %+v", report)
+	}
+}
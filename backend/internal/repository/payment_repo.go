@@ -15,6 +15,9 @@ func NewPaymentRepository(db *gorm.DB) *PaymentRepository { return &PaymentRepos
 
 // Create 持久化一条新的支付记录。
 func (r *PaymentRepository) Create(ctx context.Context, p *domain.Payment) error {
+	if err := ensureBookingInScope(ctx, r.db, p.OrderID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(p).Error
 }
 
@@ -22,21 +25,22 @@ func (r *PaymentRepository) Create(ctx context.Context, p *domain.Payment) error
 // 当记录不存在时返回 gorm.ErrRecordNotFound 错误。
 func (r *PaymentRepository) FindByTradeNo(ctx context.Context, tradeNo string) (*domain.Payment, error) {
 	var p domain.Payment
-	err := r.db.WithContext(ctx).Where("trade_no = ?", tradeNo).First(&p).Error
+	err := r.db.WithContext(ctx).Scopes(scopeByBooking(ctx, "order_id")).Where("trade_no = ?", tradeNo).First(&p).Error
 	return &p, err
 }
 
 // FindByID 根据主键查找支付记录。
 func (r *PaymentRepository) FindByID(ctx context.Context, id int64) (*domain.Payment, error) {
 	var p domain.Payment
-	err := r.db.WithContext(ctx).First(&p, id).Error
+	err := r.db.WithContext(ctx).Scopes(scopeByBooking(ctx, "order_id")).First(&p, id).Error
 	return &p, err
 }
 
 // UpdateStatus 更新指定支付记录的状态字段。
 func (r *PaymentRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
-	return r.db.WithContext(ctx).
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).
 		Model(&domain.Payment{}).
 		Where("id = ?", id).
-		Update("status", status).Error
+		Scopes(scopeByBooking(ctx, "order_id")).
+		Update("status", status))
 }
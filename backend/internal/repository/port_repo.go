@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PortRepository 提供港口主数据的数据库操作。
+type PortRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.PortRepository = (*PortRepository)(nil)
+
+// NewPortRepository 创建港口仓储实例。
+func NewPortRepository(db *gorm.DB) *PortRepository {
+	return &PortRepository{db: db}
+}
+
+// Create 插入港口；代码已存在时返回 ErrPortCodeExists。
+func (r *PortRepository) Create(ctx context.Context, port *domain.Port) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensurePortCodeFree(tx, port.Code, 0); err != nil {
+			return err
+		}
+		return tx.Create(port).Error
+	})
+}
+
+// Update 保存港口的全部字段；代码被其他港口占用时返回 ErrPortCodeExists。
+func (r *PortRepository) Update(ctx context.Context, port *domain.Port) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensurePortCodeFree(tx, port.Code, port.ID); err != nil {
+			return err
+		}
+		return tx.Save(port).Error
+	})
+}
+
+func ensurePortCodeFree(tx *gorm.DB, code string, exceptID int64) error {
+	var count int64
+	if err := tx.Model(&domain.Port{}).Where("code = ? AND id <> ?", code, exceptID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrPortCodeExists
+	}
+	return nil
+}
+
+// GetByID 根据主键查询港口。
+func (r *PortRepository) GetByID(ctx context.Context, id int64) (*domain.Port, error) {
+	var item domain.Port
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetByCode 根据 UN/LOCODE 查询港口。
+func (r *PortRepository) GetByCode(ctx context.Context, code string) (*domain.Port, error) {
+	var item domain.Port
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// List 分页查询港口，按排序权重降序、ID 升序排列。
+func (r *PortRepository) List(ctx context.Context, filter domain.PortFilter, page, pageSize int) ([]domain.Port, int64, error) {
+	var items []domain.Port
+	var total int64
+	q := r.db.WithContext(ctx).Model(&domain.Port{})
+	if keyword := strings.TrimSpace(filter.Keyword); keyword != "" {
+		q = wherePortKeyword(q, keyword)
+	}
+	if filter.CountryCode != "" {
+		q = q.Where("country_code = ?", strings.ToUpper(filter.CountryCode))
+	}
+	if filter.Status != nil {
+		q = q.Where("status = ?", *filter.Status)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := q.Order("sort_order desc, id asc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// ListAll 查询全部港口，供导出使用。
+func (r *PortRepository) ListAll(ctx context.Context) ([]domain.Port, error) {
+	var items []domain.Port
+	if err := r.db.WithContext(ctx).Order("sort_order desc, id asc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SearchByKeyword 按关键词搜索启用港口（匹配代码、中英文名称、国家与关键词）。
+func (r *PortRepository) SearchByKeyword(ctx context.Context, keyword string, limit int) ([]domain.Port, error) {
+	trimmed := strings.TrimSpace(keyword)
+	if trimmed == "" {
+		return nil, nil
+	}
+	var items []domain.Port
+	err := wherePortKeyword(r.db.WithContext(ctx).Where("status = 1"), trimmed).
+		Order("sort_order desc, id asc").
+		Limit(limit).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// FindByName 按中文名、英文名（不区分大小写）或代码精确查找启用港口；country 非空时同时匹配国家中文名。
+// 同名港口按排序权重取第一个。
+func (r *PortRepository) FindByName(ctx context.Context, name, country string) (*domain.Port, error) {
+	trimmed := strings.TrimSpace(name)
+	q := r.db.WithContext(ctx).
+		Where("status = 1").
+		Where("name_zh = ? OR LOWER(name_en) = ? OR code = ?", trimmed, strings.ToLower(trimmed), strings.ToUpper(trimmed))
+	if country != "" {
+		q = q.Where("country = ?", country)
+	}
+	var item domain.Port
+	if err := q.Order("sort_order desc, id asc").First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpsertByCode 按代码更新或新增港口。
+func (r *PortRepository) UpsertByCode(ctx context.Context, port *domain.Port) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"name_zh", "name_en", "country", "country_code", "timezone", "latitude", "longitude", "keywords",
+				"terminal_name", "terminal_address", "terminal_notes", "source", "status", "sort_order", "updated_at",
+			}),
+		}).
+		Create(port).Error
+}
+
+// Delete 删除指定港口。行程仅保存港口名称与坐标快照，删除不影响已有航次。
+func (r *PortRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Delete(&domain.Port{}, id).Error
+}
+
+// wherePortKeyword 追加不区分大小写的关键词匹配条件。
+func wherePortKeyword(q *gorm.DB, keyword string) *gorm.DB {
+	pattern := "%" + strings.ToLower(keyword) + "%"
+	return q.Where("LOWER(code) LIKE ? OR name_zh LIKE ? OR LOWER(name_en) LIKE ? OR country LIKE ? OR LOWER(keywords) LIKE ?",
+		pattern, pattern, pattern, pattern, pattern)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newPortTestRepo 创建 SQLite 内存库并写入上海、福冈与一条停用港口。
+func newPortTestRepo(t *testing.T) *PortRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.Port{}))
+	repo := NewPortRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &domain.Port{Code: "CNSHA", NameZH: "上海", NameEN: "Shanghai", Country: "中国", CountryCode: "CN", Timezone: "Asia/Shanghai", Latitude: 31.2304, Longitude: 121.4737, Keywords: "上海,shanghai", Status: 1, SortOrder: 100}))
+	require.NoError(t, repo.Create(ctx, &domain.Port{Code: "JPFUK", NameZH: "福冈", NameEN: "Fukuoka", Country: "日本", CountryCode: "JP", Timezone: "Asia/Tokyo", Latitude: 33.5902, Longitude: 130.4017, Keywords: "福冈,博多,hakata", Status: 1, SortOrder: 90}))
+	closed := &domain.Port{Code: "JPXXX", NameZH: "停用港", NameEN: "Closed", Country: "日本", CountryCode: "JP", Timezone: "Asia/Tokyo", Status: 1}
+	require.NoError(t, repo.Create(ctx, closed))
+	closed.Status = 0
+	require.NoError(t, repo.Update(ctx, closed))
+	return repo
+}
+
+func TestPortRepository_CreateRejectsDuplicateCode(t *testing.T) {
+	repo := newPortTestRepo(t)
+	ctx := context.Background()
+
+	err := repo.Create(ctx, &domain.Port{Code: "CNSHA", NameZH: "上海二号"})
+	assert.ErrorIs(t, err, domain.ErrPortCodeExists)
+
+	fukuoka, err := repo.GetByCode(ctx, "JPFUK")
+	require.NoError(t, err)
+	fukuoka.Code = "CNSHA"
+	assert.ErrorIs(t, repo.Update(ctx, fukuoka), domain.ErrPortCodeExists)
+}
+
+func TestPortRepository_ListAndSearch(t *testing.T) {
+	repo := newPortTestRepo(t)
+	ctx := context.Background()
+
+	items, total, err := repo.List(ctx, domain.PortFilter{CountryCode: "jp"}, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	assert.Equal(t, "JPFUK", items[0].Code)
+
+	status := int16(0)
+	items, total, err = repo.List(ctx, domain.PortFilter{Status: &status}, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Equal(t, "JPXXX", items[0].Code)
+
+	found, err := repo.SearchByKeyword(ctx, "HAKATA", 10)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "福冈", found[0].NameZH)
+
+	found, err = repo.SearchByKeyword(ctx, "日本", 10)
+	require.NoError(t, err)
+	assert.Len(t, found, 1, "disabled ports must not be searchable")
+}
+
+func TestPortRepository_FindByName(t *testing.T) {
+	repo := newPortTestRepo(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"上海", "shanghai", "cnsha"} {
+		port, err := repo.FindByName(ctx, name, "")
+		require.NoError(t, err, name)
+		assert.Equal(t, "CNSHA", port.Code, name)
+	}
+	port, err := repo.FindByName(ctx, "福冈", "日本")
+	require.NoError(t, err)
+	assert.Equal(t, "JPFUK", port.Code)
+
+	_, err = repo.FindByName(ctx, "福冈", "中国")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = repo.FindByName(ctx, "停用港", "")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestPortRepository_UpsertByCode(t *testing.T) {
+	repo := newPortTestRepo(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.UpsertByCode(ctx, &domain.Port{Code: "CNSHA", NameZH: "上海", NameEN: "Shanghai", Country: "中国", CountryCode: "CN", Timezone: "Asia/Shanghai", Latitude: 31.35, Longitude: 121.5, TerminalName: "吴淞口国际邮轮港", Status: 1}))
+	require.NoError(t, repo.UpsertByCode(ctx, &domain.Port{Code: "KRPUS", NameZH: "釜山", NameEN: "Busan", Country: "韩国", CountryCode: "KR", Timezone: "Asia/Seoul", Latitude: 35.1796, Longitude: 129.0756, Status: 1}))
+
+	shanghai, err := repo.GetByCode(ctx, "CNSHA")
+	require.NoError(t, err)
+	assert.Equal(t, 31.35, shanghai.Latitude)
+	assert.Equal(t, "吴淞口国际邮轮港", shanghai.TerminalName)
+
+	all, err := repo.ListAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	require.NoError(t, repo.Delete(ctx, shanghai.ID))
+	_, err = repo.GetByID(ctx, shanghai.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
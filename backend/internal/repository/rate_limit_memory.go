@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+// rateLimitSweepInterval 是内存限流器清理已装满令牌桶的间隔。
+const rateLimitSweepInterval = time.Minute
+
+type memoryRateLimitBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     domain.RateLimit
+}
+
+// MemoryRateLimitStore 在进程内存中维护令牌桶，仅适用于单实例部署或本地开发。
+// 已重新装满的桶等同于不存在，会被定期清理，避免按 IP 计数时内存无限增长。
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryRateLimitBucket
+	lastSweep time.Time
+}
+
+var _ domain.RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// NewMemoryRateLimitStore 创建内存令牌桶存储。
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*memoryRateLimitBucket{}}
+}
+
+// Take 从桶中取出一个令牌，桶不存在时视为已装满。
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryRateLimitBucket{tokens: limit.Capacity(), updatedAt: now}
+		s.buckets[key] = bucket
+	}
+	bucket.limit = limit
+	if now.After(bucket.updatedAt) {
+		bucket.tokens = limit.Refill(bucket.tokens, now.Sub(bucket.updatedAt))
+		bucket.updatedAt = now
+	}
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+	return limit.Decide(allowed, bucket.tokens), nil
+}
+
+// sweep 删除已重新装满的桶，调用方需持有锁。
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if bucket.limit.Refill(bucket.tokens, now.Sub(bucket.updatedAt)) >= bucket.limit.Capacity() {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTokenBucketScript 原子地补充并取出令牌，桶以 hash 保存剩余令牌数 t 与上次更新时间 ts（毫秒）。
+// 桶在装满所需时间后自动过期，过期等同于装满。
+var redisTokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+local state = redis.call('HMGET', KEYS[1], 't', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) * rate)
+  ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 't', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimitStore 基于 Redis 实现令牌桶存储，多副本部署时共享同一个桶。
+// 补充令牌使用调用方传入的 now，各实例需保持时钟同步。
+type RedisRateLimitStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ domain.RateLimitStore = (*RedisRateLimitStore)(nil)
+
+// NewRedisRateLimitStore 创建 Redis 令牌桶存储，prefix 为所有键的公共前缀。
+func NewRedisRateLimitStore(client redis.UniversalClient, prefix string) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client, prefix: prefix}
+}
+
+// Take 从桶中取出一个令牌。
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateLimitDecision, error) {
+	rate := limit.TokensPerMillisecond()
+	ttl := limit.Period
+	if rate > 0 {
+		ttl = time.Duration(limit.Capacity()/rate) * time.Millisecond
+	}
+	result, err := redisTokenBucketScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Capacity(), rate, now.UnixMilli(), redisTTL(ttl).Milliseconds()+1).Slice()
+	if err != nil {
+		return domain.RateLimitDecision{}, err
+	}
+	allowed, _ := result[0].(int64)
+	text, _ := result[1].(string)
+	tokens, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return domain.RateLimitDecision{}, err
+	}
+	return limit.Decide(allowed == 1, tokens), nil
+}
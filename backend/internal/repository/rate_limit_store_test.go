@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertTokenBucketSemantics(t *testing.T, store domain.RateLimitStore) {
+	t.Helper()
+	ctx := context.Background()
+	limit := domain.RateLimit{Requests: 1, Period: time.Second, Burst: 3}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	for want := 2; want >= 0; want-- {
+		decision, err := store.Take(ctx, "login:ip:1.2.3.4", limit, now)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 3, decision.Limit)
+		assert.Equal(t, want, decision.Remaining)
+	}
+
+	decision, err := store.Take(ctx, "login:ip:1.2.3.4", limit, now.Add(500*time.Millisecond))
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed, "突发额度用完后拒绝")
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+	assert.Equal(t, 2500*time.Millisecond, decision.ResetAfter)
+
+	decision, err = store.Take(ctx, "login:ip:5.6.7.8", limit, now.Add(500*time.Millisecond))
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "不同键互不影响")
+
+	decision, err = store.Take(ctx, "login:ip:1.2.3.4", limit, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "按速率补充令牌")
+	assert.Equal(t, 0, decision.Remaining)
+
+	decision, err = store.Take(ctx, "login:ip:1.2.3.4", limit, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 2, decision.Remaining, "补充不超过桶容量")
+}
+
+func TestMemoryRateLimitStore_TokenBucket(t *testing.T) {
+	assertTokenBucketSemantics(t, NewMemoryRateLimitStore())
+
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+	limit := domain.RateLimit{Requests: 10, Period: time.Second}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	_, err := store.Take(ctx, "sweep", limit, now)
+	require.NoError(t, err)
+	_, err = store.Take(ctx, "other", limit, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.NotContains(t, store.buckets, "sweep", "已装满的桶会被清理")
+}
+
+func TestRedisRateLimitStore_TokenBucket(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	assertTokenBucketSemantics(t, NewRedisRateLimitStore(client, "test:ratelimit:"))
+
+	assert.True(t, server.Exists("test:ratelimit:login:ip:1.2.3.4"))
+	assert.Positive(t, server.TTL("test:ratelimit:login:ip:1.2.3.4"), "桶带有过期时间")
+}
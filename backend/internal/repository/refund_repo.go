@@ -15,6 +15,9 @@ func NewRefundRepository(db *gorm.DB) *RefundRepository { return &RefundReposito
 
 // Create 持久化一条新的退款记录。
 func (r *RefundRepository) Create(ctx context.Context, refund *domain.Refund) error {
+	if err := ensureInScope(ctx, r.db.Model(&domain.Payment{}).Where("id = ?", refund.PaymentID).Scopes(scopeByBooking(ctx, "order_id"))); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(refund).Error
 }
 
@@ -25,6 +28,7 @@ func (r *RefundRepository) SumByPaymentID(ctx context.Context, paymentID int64)
 	err := r.db.WithContext(ctx).
 		Model(&domain.Refund{}).
 		Where("payment_id = ? AND status != ?", paymentID, "cancelled").
+		Scopes(scopeByPayment(ctx, "payment_id")).
 		Select("COALESCE(SUM(amount_cents), 0)").
 		Scan(&total).Error
 	return total, err
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// StaffCompanyRepository 提供员工公司数据范围的数据库操作。
+type StaffCompanyRepository struct {
+	db *gorm.DB
+}
+
+// NewStaffCompanyRepository 创建员工公司数据范围仓储。
+func NewStaffCompanyRepository(db *gorm.DB) *StaffCompanyRepository {
+	return &StaffCompanyRepository{db: db}
+}
+
+var _ domain.StaffCompanyRepository = (*StaffCompanyRepository)(nil)
+
+// GetScope 返回员工的公司数据范围：平台管理员标记取自 staffs.all_companies，授权公司 ID 升序排列。
+// 员工不存在时返回空范围（不能访问任何公司）。
+func (r *StaffCompanyRepository) GetScope(ctx context.Context, staffID int64) (domain.StaffCompanyScope, error) {
+	var flags []bool
+	if err := r.db.WithContext(ctx).Model(&domain.Staff{}).
+		Where("id = ?", staffID).
+		Limit(1).
+		Pluck("all_companies", &flags).Error; err != nil {
+		return domain.StaffCompanyScope{}, err
+	}
+	if len(flags) > 0 && flags[0] {
+		return domain.StaffCompanyScope{AllCompanies: true}, nil
+	}
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&domain.StaffCompany{}).
+		Where("staff_id = ?", staffID).
+		Order("company_id ASC").
+		Pluck("company_id", &ids).Error
+	return domain.StaffCompanyScope{CompanyIDs: ids}, err
+}
+
+// ReplaceScope 在事务中整体替换员工的公司数据范围；标记为平台管理员时清空逐个公司的授权记录。
+func (r *StaffCompanyRepository) ReplaceScope(ctx context.Context, staffID int64, scope domain.StaffCompanyScope) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Staff{}).Where("id = ?", staffID).UpdateColumn("all_companies", scope.AllCompanies)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("staff_id = ?", staffID).Delete(&domain.StaffCompany{}).Error; err != nil {
+			return err
+		}
+		if scope.AllCompanies || len(scope.CompanyIDs) == 0 {
+			return nil
+		}
+		rows := make([]domain.StaffCompany, 0, len(scope.CompanyIDs))
+		for _, companyID := range scope.CompanyIDs {
+			rows = append(rows, domain.StaffCompany{StaffID: staffID, CompanyID: companyID})
+		}
+		return tx.Create(&rows).Error
+	})
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestStaffCompanyRepositoryReplaceScope(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.Staff{}, &domain.StaffCompany{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, staff := range []domain.Staff{{ID: 7, Username: "partner"}, {ID: 8, Username: "other"}} {
+		if err := db.Create(&staff).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := NewStaffCompanyRepository(db)
+	ctx := context.Background()
+
+	if scope, err := repo.GetScope(ctx, 7); err != nil || scope.AllCompanies || len(scope.CompanyIDs) != 0 {
+		t.Fatalf("staff without assignments must not see any company, got %+v %v", scope, err)
+	}
+	if err := repo.ReplaceScope(ctx, 7, domain.StaffCompanyScope{CompanyIDs: []int64{3, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ReplaceScope(ctx, 8, domain.StaffCompanyScope{CompanyIDs: []int64{9}}); err != nil {
+		t.Fatal(err)
+	}
+	scope, err := repo.GetScope(ctx, 7)
+	if err != nil || scope.AllCompanies || len(scope.CompanyIDs) != 2 || scope.CompanyIDs[0] != 1 || scope.CompanyIDs[1] != 3 {
+		t.Fatalf("unexpected scope: %+v %v", scope, err)
+	}
+
+	if err := repo.ReplaceScope(ctx, 7, domain.StaffCompanyScope{AllCompanies: true, CompanyIDs: []int64{3}}); err != nil {
+		t.Fatal(err)
+	}
+	if scope, _ := repo.GetScope(ctx, 7); !scope.AllCompanies || len(scope.CompanyIDs) != 0 {
+		t.Fatalf("platform admins are not limited to assignments, got %+v", scope)
+	}
+	var rows int64
+	db.Model(&domain.StaffCompany{}).Where("staff_id = ?", 7).Count(&rows)
+	if rows != 0 {
+		t.Fatalf("granting every company should clear assignments, got %d rows", rows)
+	}
+
+	if err := repo.ReplaceScope(ctx, 7, domain.StaffCompanyScope{}); err != nil {
+		t.Fatal(err)
+	}
+	if scope, _ := repo.GetScope(ctx, 7); scope.AllCompanies || len(scope.CompanyIDs) != 0 {
+		t.Fatalf("clearing should revoke every company, got %+v", scope)
+	}
+	if scope, _ := repo.GetScope(ctx, 8); len(scope.CompanyIDs) != 1 {
+		t.Fatalf("other staff must be untouched, got %+v", scope)
+	}
+	if err := repo.ReplaceScope(ctx, 404, domain.StaffCompanyScope{}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound for unknown staff, got %v", err)
+	}
+}
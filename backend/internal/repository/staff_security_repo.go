@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StaffSecurityRepository 提供员工登录锁定、密码历史、重置令牌与 TOTP 状态的数据库操作。
+type StaffSecurityRepository struct {
+	db *gorm.DB
+}
+
+// NewStaffSecurityRepository 创建员工账号安全仓储实例。
+func NewStaffSecurityRepository(db *gorm.DB) *StaffSecurityRepository {
+	return &StaffSecurityRepository{db: db}
+}
+
+var _ domain.StaffSecurityRepository = (*StaffSecurityRepository)(nil)
+
+// RecordLoginFailure 在事务内累加连续失败次数；达到 maxAttempts 时锁定账号并清零计数，
+// 使锁定结束后重新获得完整的尝试次数。返回值非 nil 表示本次失败触发了锁定。
+func (r *StaffSecurityRepository) RecordLoginFailure(ctx context.Context, staffID int64, maxAttempts int, lockedUntil time.Time) (*time.Time, error) {
+	var locked *time.Time
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var staff domain.Staff
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "failed_login_count").First(&staff, staffID).Error; err != nil {
+			return err
+		}
+		updates := map[string]any{"failed_login_count": staff.FailedLoginCount + 1}
+		if maxAttempts > 0 && staff.FailedLoginCount+1 >= maxAttempts {
+			updates = map[string]any{"failed_login_count": 0, "locked_until": lockedUntil}
+			locked = &lockedUntil
+		}
+		return tx.Model(&domain.Staff{}).Where("id = ?", staffID).UpdateColumns(updates).Error
+	})
+	return locked, err
+}
+
+// RecordLoginSuccess 清零失败计数、解除锁定并记录最后登录时间。
+func (r *StaffSecurityRepository) RecordLoginSuccess(ctx context.Context, staffID int64, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.Staff{}).Where("id = ?", staffID).UpdateColumns(map[string]any{
+		"failed_login_count": 0,
+		"locked_until":       nil,
+		"last_login_at":      now,
+	}).Error
+}
+
+// UpdatePassword 在事务内替换密码：旧哈希写入历史并裁剪到最近 keepHistory 条，
+// 同时清除强制改密标记与登录锁定。
+func (r *StaffSecurityRepository) UpdatePassword(ctx context.Context, staffID int64, hash string, changedAt time.Time, keepHistory int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var staff domain.Staff
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "password_hash").First(&staff, staffID).Error; err != nil {
+			return err
+		}
+		if staff.PasswordHash != "" && keepHistory > 0 {
+			if err := tx.Create(&domain.StaffPasswordHistory{StaffID: staffID, PasswordHash: staff.PasswordHash, CreatedAt: changedAt}).Error; err != nil {
+				return err
+			}
+			keep := tx.Model(&domain.StaffPasswordHistory{}).Select("id").Where("staff_id = ?", staffID).Order("id DESC").Limit(keepHistory)
+			if err := tx.Where("staff_id = ? AND id NOT IN (?)", staffID, keep).Delete(&domain.StaffPasswordHistory{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&domain.Staff{}).Where("id = ?", staffID).UpdateColumns(map[string]any{
+			"password_hash":        hash,
+			"password_changed_at":  changedAt,
+			"must_change_password": false,
+			"failed_login_count":   0,
+			"locked_until":         nil,
+			"updated_at":           changedAt,
+		}).Error
+	})
+}
+
+// ListPasswordHistory 按时间倒序返回最近 limit 条历史密码哈希。
+func (r *StaffSecurityRepository) ListPasswordHistory(ctx context.Context, staffID int64, limit int) ([]string, error) {
+	var hashes []string
+	err := r.db.WithContext(ctx).Model(&domain.StaffPasswordHistory{}).
+		Where("staff_id = ?", staffID).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("password_hash", &hashes).Error
+	return hashes, err
+}
+
+// SetTOTP 写入 TOTP 密钥与启用时间；secret 为空时同时清除启用状态。
+// 写入待确认密钥或清除绑定时重置已用时间步，正式启用时保留（启用前已消耗确认所用的验证码）。
+func (r *StaffSecurityRepository) SetTOTP(ctx context.Context, staffID int64, secret string, enabledAt *time.Time) error {
+	if secret == "" {
+		enabledAt = nil
+	}
+	columns := map[string]any{
+		"totp_secret":     secret,
+		"totp_enabled_at": enabledAt,
+	}
+	if enabledAt == nil {
+		columns["totp_last_step"] = 0
+	}
+	result := r.db.WithContext(ctx).Model(&domain.Staff{}).Where("id = ?", staffID).UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ConsumeTOTPStep 用条件更新记录已使用的时间步，同一验证码（及更早的验证码）无法被再次使用。
+func (r *StaffSecurityRepository) ConsumeTOTPStep(ctx context.Context, staffID int64, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.Staff{}).
+		Where("id = ? AND totp_last_step < ?", staffID, step).
+		UpdateColumn("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreateResetToken 作废该员工所有未使用的重置令牌后写入新令牌，保证同一时刻只有一个有效令牌。
+func (r *StaffSecurityRepository) CreateResetToken(ctx context.Context, token *domain.StaffPasswordResetToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.StaffPasswordResetToken{}).
+			Where("staff_id = ? AND used_at IS NULL", token.StaffID).
+			UpdateColumn("used_at", token.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// FindResetToken 查询未使用且未过期的重置令牌，用于在消耗令牌前先校验新密码。
+func (r *StaffSecurityRepository) FindResetToken(ctx context.Context, tokenHash string, now time.Time) (*domain.StaffPasswordResetToken, error) {
+	var token domain.StaffPasswordResetToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumeResetToken 以条件更新原子地标记令牌已使用，并发请求中只有一个能成功。
+func (r *StaffSecurityRepository) ConsumeResetToken(ctx context.Context, tokenHash string, now time.Time) (*domain.StaffPasswordResetToken, error) {
+	var token domain.StaffPasswordResetToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).First(&token).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&domain.StaffPasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			UpdateColumn("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		token.UsedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
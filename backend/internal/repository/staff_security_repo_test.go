@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newStaffSecurityTestRepo(t *testing.T) (*StaffSecurityRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.Staff{}, &domain.StaffPasswordHistory{}, &domain.StaffPasswordResetToken{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&domain.Staff{ID: 1, Username: "alice", PasswordHash: "h0", Status: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewStaffSecurityRepository(db), db
+}
+
+func TestStaffSecurityRepositoryLoginFailuresLockAccount(t *testing.T) {
+	repo, db := newStaffSecurityTestRepo(t)
+	ctx := context.Background()
+	until := time.Now().Add(15 * time.Minute).Truncate(time.Second)
+
+	for i := 0; i < 2; i++ {
+		if locked, err := repo.RecordLoginFailure(ctx, 1, 3, until); err != nil || locked != nil {
+			t.Fatalf("attempt %d should not lock: %v %v", i+1, locked, err)
+		}
+	}
+	locked, err := repo.RecordLoginFailure(ctx, 1, 3, until)
+	if err != nil || locked == nil || !locked.Equal(until) {
+		t.Fatalf("third failure should lock until %v, got %v (%v)", until, locked, err)
+	}
+	var staff domain.Staff
+	db.First(&staff, 1)
+	if staff.FailedLoginCount != 0 || staff.LockedUntil == nil {
+		t.Fatalf("expected counter reset and lock set, got %+v", staff)
+	}
+
+	if err := repo.RecordLoginSuccess(ctx, 1, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	var unlocked domain.Staff
+	db.First(&unlocked, 1)
+	if unlocked.LockedUntil != nil || unlocked.LastLoginAt == nil {
+		t.Fatalf("expected lock cleared and last login recorded, got %+v", unlocked)
+	}
+	if _, err := repo.RecordLoginFailure(ctx, 404, 3, until); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestStaffSecurityRepositoryPasswordHistoryIsTrimmed(t *testing.T) {
+	repo, db := newStaffSecurityTestRepo(t)
+	ctx := context.Background()
+	db.Model(&domain.Staff{}).Where("id = 1").Updates(map[string]any{"must_change_password": true, "failed_login_count": 2})
+
+	for _, hash := range []string{"h1", "h2", "h3"} {
+		if err := repo.UpdatePassword(ctx, 1, hash, time.Now(), 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history, err := repo.ListPasswordHistory(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0] != "h2" || history[1] != "h1" {
+		t.Fatalf("expected the two most recent previous hashes, got %v", history)
+	}
+	var staff domain.Staff
+	db.First(&staff, 1)
+	if staff.PasswordHash != "h3" || staff.MustChangePassword || staff.FailedLoginCount != 0 || staff.PasswordChangedAt == nil {
+		t.Fatalf("unexpected staff after password change %+v", staff)
+	}
+}
+
+func TestStaffSecurityRepositoryTOTPStepsCannotBeReplayed(t *testing.T) {
+	repo, db := newStaffSecurityTestRepo(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	if err := repo.SetTOTP(ctx, 1, "SECRET", &now); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := repo.ConsumeTOTPStep(ctx, 1, 100); !ok {
+		t.Fatal("first use of a step should succeed")
+	}
+	if ok, _ := repo.ConsumeTOTPStep(ctx, 1, 100); ok {
+		t.Fatal("replaying the same step must fail")
+	}
+	if ok, _ := repo.ConsumeTOTPStep(ctx, 1, 99); ok {
+		t.Fatal("older steps must fail")
+	}
+	if err := repo.SetTOTP(ctx, 1, "SECRET", &now); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := repo.ConsumeTOTPStep(ctx, 1, 100); ok {
+		t.Fatal("activating the binding must keep the consumed step")
+	}
+
+	if err := repo.SetTOTP(ctx, 1, "", &now); err != nil {
+		t.Fatal(err)
+	}
+	var staff domain.Staff
+	db.First(&staff, 1)
+	if staff.TOTPSecret != "" || staff.TOTPEnabledAt != nil || staff.TOTPLastStep != 0 {
+		t.Fatalf("expected TOTP to be cleared, got %+v", staff)
+	}
+	if err := repo.SetTOTP(ctx, 404, "SECRET", nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestStaffSecurityRepositoryResetTokensAreSingleUse(t *testing.T) {
+	repo, _ := newStaffSecurityTestRepo(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	first := &domain.StaffPasswordResetToken{StaffID: 1, TokenHash: "first", CreatedBy: 9, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	if err := repo.CreateResetToken(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	second := &domain.StaffPasswordResetToken{StaffID: 1, TokenHash: "second", CreatedBy: 9, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	if err := repo.CreateResetToken(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ConsumeResetToken(ctx, "first", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("issuing a new token should revoke the previous one, got %v", err)
+	}
+	if _, err := repo.ConsumeResetToken(ctx, "second", now.Add(2*time.Hour)); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expired token must be rejected, got %v", err)
+	}
+	if found, err := repo.FindResetToken(ctx, "second", now); err != nil || found.UsedAt != nil {
+		t.Fatalf("lookup must not consume the token, got %+v (%v)", found, err)
+	}
+	token, err := repo.ConsumeResetToken(ctx, "second", now)
+	if err != nil || token.StaffID != 1 || token.UsedAt == nil {
+		t.Fatalf("unexpected token %+v (%v)", token, err)
+	}
+	if _, err := repo.ConsumeResetToken(ctx, "second", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("token must be single-use, got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"gorm.io/gorm"
@@ -30,3 +33,216 @@ func (r *UserRepository) FindOrCreateByPhone(phone string) (*domain.User, error)
 	}
 	return &u, result.Error
 }
+
+// identityColumns 把账号渠道映射到 users 表中的唯一标识列。
+var identityColumns = map[string]string{
+	domain.IdentityPhone:  "phone",
+	domain.IdentityWechat: "wx_open_id",
+	domain.IdentityAlipay: "alipay_uid",
+}
+
+// GetByID 根据 ID 查询用户。
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	var u domain.User
+	if err := r.db.WithContext(ctx).First(&u, id).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// FindOrCreateByWechat 根据微信 OpenID 查找用户，不存在则创建；每次登录刷新 UnionID 与会话密钥。
+func (r *UserRepository) FindOrCreateByWechat(ctx context.Context, openID, unionID, sessionKey string) (*domain.User, error) {
+	db := r.db.WithContext(ctx)
+	var u domain.User
+	err := db.Where("wx_open_id = ?", openID).First(&u).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		u = domain.User{WxOpenID: openID, WxUnionID: unionID, WxSessionKey: sessionKey, Status: 1}
+		if err := db.Create(&u).Error; err != nil {
+			// 并发登录时可能被其他请求先创建，重查后按已有用户刷新会话
+			if err2 := db.Where("wx_open_id = ?", openID).First(&u).Error; err2 != nil {
+				return nil, err
+			}
+		} else {
+			return &u, nil
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	updates := map[string]any{"wx_session_key": sessionKey, "updated_at": time.Now()}
+	if unionID != "" {
+		updates["wx_union_id"] = unionID
+	}
+	if err := db.Model(&u).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// BindIdentity 在单个事务内把手机号或第三方账号绑定到用户：
+// 标识已属于其他用户时返回 ErrIdentityTaken；用户已绑定同渠道的其他第三方账号时返回 ErrIdentityConflict（手机号允许更换）。
+func (r *UserRepository) BindIdentity(ctx context.Context, userID int64, provider, identifier string) error {
+	column, ok := identityColumns[provider]
+	if !ok {
+		return fmt.Errorf("unsupported identity provider %q", provider)
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var u domain.User
+		if err := tx.First(&u, userID).Error; err != nil {
+			return err
+		}
+		current := userIdentity(&u, provider)
+		if current == identifier {
+			return nil
+		}
+		if current != "" && provider != domain.IdentityPhone {
+			return domain.ErrIdentityConflict
+		}
+		var count int64
+		if err := tx.Model(&domain.User{}).Where(column+" = ? AND id <> ?", identifier, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return domain.ErrIdentityTaken
+		}
+		return tx.Model(&u).Updates(map[string]any{column: identifier, "updated_at": time.Now()}).Error
+	})
+}
+
+func userIdentity(u *domain.User, provider string) string {
+	switch provider {
+	case domain.IdentityPhone:
+		return u.Phone
+	case domain.IdentityWechat:
+		return u.WxOpenID
+	}
+	return u.AlipayUID
+}
+
+// UpdateProfile 更新用户昵称、头像与邮箱。
+func (r *UserRepository) UpdateProfile(ctx context.Context, id int64, nickname, avatarURL, email string) error {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Updates(map[string]any{
+		"nickname":   nickname,
+		"avatar_url": avatarURL,
+		"email":      email,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountActiveBookings 统计用户未结束（未完成、未取消、未退款）的订单数。
+func (r *UserRepository) CountActiveBookings(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Booking{}).
+		Where("user_id = ? AND status NOT IN ?", userID, []string{domain.OrderStatusCompleted, domain.OrderStatusCancelled, domain.OrderStatusRefunded}).
+		Count(&count).Error
+	return count, err
+}
+
+// ScheduleDeletion 记录注销申请并设置冷静期结束时间；已申请的用户保留原申请时间。
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, id int64, requestedAt, scheduledAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NULL AND anonymized_at IS NULL", id).
+		Updates(map[string]any{"deletion_requested_at": requestedAt, "deletion_scheduled_at": scheduledAt, "updated_at": requestedAt})
+	return result.Error
+}
+
+// CancelDeletion 撤销冷静期内的注销申请，返回是否存在可撤销的申请。
+func (r *UserRepository) CancelDeletion(ctx context.Context, id int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&domain.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL AND anonymized_at IS NULL", id).
+		Updates(map[string]any{"deletion_requested_at": nil, "deletion_scheduled_at": nil, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListDueDeletions 查询冷静期已结束、待匿名化的用户 ID。
+func (r *UserRepository) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&domain.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ? AND anonymized_at IS NULL", now).
+		Order("deletion_scheduled_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Anonymize 在单个事务内注销用户：清空登录标识与个人资料，匿名化出行乘客（未出现在订单中的直接删除），
+// 删除通知记录；订单、支付、退款等财务记录保留，仍通过 user_id 关联到已匿名化的用户。
+func (r *UserRepository) Anonymize(ctx context.Context, id int64, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.User{}).Where("id = ? AND anonymized_at IS NULL", id).Updates(map[string]any{
+			"phone":                 "",
+			"wx_open_id":            "",
+			"wx_union_id":           "",
+			"wx_session_key":        "",
+			"alipay_uid":            "",
+			"email":                 "",
+			"nickname":              "已注销用户",
+			"avatar_url":            "",
+			"status":                0,
+			"deletion_scheduled_at": nil,
+			"anonymized_at":         now,
+			"updated_at":            now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		referenced := tx.Model(&domain.BookingPassenger{}).Select("passenger_id")
+		if err := tx.Where("user_id = ? AND id NOT IN (?)", id, referenced).Delete(&domain.Passenger{}).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&domain.Passenger{}).Where("user_id = ?", id).Updates(map[string]any{
+			"name":              "已注销",
+			"english_name":      "",
+			"id_number":         "",
+			"phone":             "",
+			"email":             "",
+			"emergency_contact": "",
+			"emergency_phone":   "",
+			"special_needs":     "",
+			"birthday":          nil,
+			"is_favorite":       false,
+			"updated_at":        now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", id).Delete(&domain.Notification{}).Error
+	})
+}
+
+// ExportData 汇总用户资料、订单、出行乘客与通知，用于个人信息导出。
+func (r *UserRepository) ExportData(ctx context.Context, id int64) (*domain.UserData, error) {
+	db := r.db.WithContext(ctx)
+	var data domain.UserData
+	if err := db.First(&data.User, id).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", id).Order("id ASC").Find(&data.Bookings).Error; err != nil {
+		return nil, err
+	}
+	if len(data.Bookings) > 0 {
+		bookingIDs := make([]int64, len(data.Bookings))
+		for i, b := range data.Bookings {
+			bookingIDs[i] = b.ID
+		}
+		if err := db.Where("booking_id IN ?", bookingIDs).Order("id ASC").Find(&data.BookingPassengers).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := db.Where("user_id = ?", id).Order("id ASC").Find(&data.Passengers).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("user_id = ?", id).Order("id ASC").Find(&data.Notifications).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
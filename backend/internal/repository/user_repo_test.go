@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"gorm.io/driver/sqlite"
@@ -52,3 +55,192 @@ func TestUserRepositoryFindOrCreateByPhone_Idempotent(t *testing.T) {
 		t.Fatalf("expected same user ID on second call, got %d vs %d", u1.ID, u2.ID)
 	}
 }
+
+func newUserTestRepo(t *testing.T) *UserRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.User{}, &domain.Booking{}, &domain.BookingPassenger{}, &domain.Passenger{}, &domain.Notification{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewUserRepository(db)
+}
+
+func TestUserRepositoryFindOrCreateByWechat(t *testing.T) {
+	repo := newUserTestRepo(t)
+	ctx := context.Background()
+
+	// 仅有手机号或仅有微信的多个用户，空标识不能触发唯一约束
+	if _, err := repo.FindOrCreateByPhone("13800000001"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindOrCreateByPhone("13800000002"); err != nil {
+		t.Fatalf("second phone-only user should not conflict on empty openid: %v", err)
+	}
+	u1, err := repo.FindOrCreateByWechat(ctx, "openid-1", "", "key-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u2, err := repo.FindOrCreateByWechat(ctx, "openid-1", "union-1", "key-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u1.ID != u2.ID || u2.WxUnionID != "union-1" || u2.WxSessionKey != "key-2" {
+		t.Fatalf("expected the same user with refreshed session, got %+v vs %+v", u1, u2)
+	}
+	if _, err := repo.FindOrCreateByWechat(ctx, "openid-2", "", "key-3"); err != nil {
+		t.Fatalf("second wechat-only user should not conflict on empty phone: %v", err)
+	}
+	loaded, err := repo.GetByID(ctx, u1.ID)
+	if err != nil || loaded.WxSessionKey != "key-2" || loaded.Status != 1 {
+		t.Fatalf("unexpected stored user %+v (%v)", loaded, err)
+	}
+}
+
+func TestUserRepositoryBindIdentity(t *testing.T) {
+	repo := newUserTestRepo(t)
+	ctx := context.Background()
+	phoneUser, _ := repo.FindOrCreateByPhone("13800000001")
+	wxUser, _ := repo.FindOrCreateByWechat(ctx, "openid-1", "", "key")
+
+	if err := repo.BindIdentity(ctx, wxUser.ID, domain.IdentityPhone, "13800000001"); !errors.Is(err, domain.ErrIdentityTaken) {
+		t.Fatalf("expected ErrIdentityTaken, got %v", err)
+	}
+	if err := repo.BindIdentity(ctx, wxUser.ID, domain.IdentityPhone, "13800000009"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.BindIdentity(ctx, wxUser.ID, domain.IdentityPhone, "13800000008"); err != nil {
+		t.Fatalf("phone numbers can be replaced: %v", err)
+	}
+	if err := repo.BindIdentity(ctx, phoneUser.ID, domain.IdentityAlipay, "alipay-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.BindIdentity(ctx, phoneUser.ID, domain.IdentityAlipay, "alipay-1"); err != nil {
+		t.Fatalf("rebinding the same account should be a no-op: %v", err)
+	}
+	if err := repo.BindIdentity(ctx, phoneUser.ID, domain.IdentityAlipay, "alipay-2"); !errors.Is(err, domain.ErrIdentityConflict) {
+		t.Fatalf("expected ErrIdentityConflict, got %v", err)
+	}
+	if err := repo.BindIdentity(ctx, phoneUser.ID, domain.IdentityWechat, "openid-1"); !errors.Is(err, domain.ErrIdentityTaken) {
+		t.Fatalf("expected ErrIdentityTaken for wechat, got %v", err)
+	}
+	if err := repo.BindIdentity(ctx, 404, domain.IdentityAlipay, "alipay-3"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+	if err := repo.BindIdentity(ctx, phoneUser.ID, "github", "x"); err == nil {
+		t.Fatal("expected unsupported provider to be rejected")
+	}
+	loaded, _ := repo.GetByID(ctx, wxUser.ID)
+	if loaded.Phone != "13800000008" || loaded.WxOpenID != "openid-1" {
+		t.Fatalf("unexpected bound user %+v", loaded)
+	}
+}
+
+func TestUserRepositoryProfileAndDeletionSchedule(t *testing.T) {
+	repo := newUserTestRepo(t)
+	ctx := context.Background()
+	user, _ := repo.FindOrCreateByPhone("13800000001")
+
+	if err := repo.UpdateProfile(ctx, user.ID, "Alice", "https://cdn/a.png", "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.UpdateProfile(ctx, 404, "x", "", ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+	loaded, _ := repo.GetByID(ctx, user.ID)
+	if loaded.Nickname != "Alice" || loaded.AvatarURL != "https://cdn/a.png" || loaded.Email != "a@example.com" {
+		t.Fatalf("unexpected profile %+v", loaded)
+	}
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	if err := repo.ScheduleDeletion(ctx, user.ID, now, now.Add(15*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.ScheduleDeletion(ctx, user.ID, now.Add(time.Hour), now.Add(16*24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ = repo.GetByID(ctx, user.ID)
+	if loaded.DeletionScheduledAt == nil || !loaded.DeletionScheduledAt.Equal(now.Add(15*24*time.Hour)) {
+		t.Fatalf("repeated requests should keep the original schedule, got %+v", loaded.DeletionScheduledAt)
+	}
+	if ids, _ := repo.ListDueDeletions(ctx, now.Add(24*time.Hour), 10); len(ids) != 0 {
+		t.Fatalf("deletion is not due during the cooling-off period, got %v", ids)
+	}
+	if ids, _ := repo.ListDueDeletions(ctx, now.Add(15*24*time.Hour), 10); len(ids) != 1 || ids[0] != user.ID {
+		t.Fatalf("expected user to be due for deletion, got %v", ids)
+	}
+	if ok, err := repo.CancelDeletion(ctx, user.ID); err != nil || !ok {
+		t.Fatalf("expected cancellation, got %v (%v)", ok, err)
+	}
+	if ok, _ := repo.CancelDeletion(ctx, user.ID); ok {
+		t.Fatal("nothing left to cancel")
+	}
+	loaded, _ = repo.GetByID(ctx, user.ID)
+	if loaded.DeletionRequestedAt != nil || loaded.DeletionScheduledAt != nil {
+		t.Fatalf("cancellation should clear the schedule, got %+v", loaded)
+	}
+}
+
+func TestUserRepositoryAnonymizeKeepsFinancialRecords(t *testing.T) {
+	repo := newUserTestRepo(t)
+	ctx := context.Background()
+	user, _ := repo.FindOrCreateByPhone("13800000001")
+	other, _ := repo.FindOrCreateByPhone("13800000002")
+	_ = repo.BindIdentity(ctx, user.ID, domain.IdentityAlipay, "alipay-1")
+	db := repo.db
+	travelled := &domain.Passenger{UserID: user.ID, Name: "张三", IDNumber: "110101199001011234", Phone: "13800000001", IsFavorite: true, Birthday: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)}
+	unused := &domain.Passenger{UserID: user.ID, Name: "李四", IDNumber: "110101199001015678"}
+	otherPassenger := &domain.Passenger{UserID: other.ID, Name: "王五", IDNumber: "110101199001019999"}
+	for _, p := range []*domain.Passenger{travelled, unused, otherPassenger} {
+		if err := db.Create(p).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	booking := &domain.Booking{UserID: user.ID, VoyageID: 1, CabinSKUID: 1, Status: domain.OrderStatusCompleted, TotalCents: 100000, PaidCents: 100000}
+	if err := db.Create(booking).Error; err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&domain.BookingPassenger{BookingID: booking.ID, PassengerID: travelled.ID})
+	db.Create(&domain.Notification{UserID: user.ID, Channel: "sms", Payload: `{"phone":"13800000001"}`})
+	db.Create(&domain.Notification{UserID: other.ID, Channel: "sms"})
+
+	if n, err := repo.CountActiveBookings(ctx, user.ID); err != nil || n != 0 {
+		t.Fatalf("completed bookings are not active, got %d (%v)", n, err)
+	}
+	data, err := repo.ExportData(ctx, user.ID)
+	if err != nil || len(data.Bookings) != 1 || len(data.BookingPassengers) != 1 || len(data.Passengers) != 2 || len(data.Notifications) != 1 {
+		t.Fatalf("unexpected export %+v (%v)", data, err)
+	}
+
+	now := time.Date(2026, 11, 3, 12, 0, 0, 0, time.UTC)
+	if err := repo.Anonymize(ctx, user.ID, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Anonymize(ctx, user.ID, now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("anonymizing twice should report ErrRecordNotFound, got %v", err)
+	}
+	loaded, _ := repo.GetByID(ctx, user.ID)
+	if loaded.Phone != "" || loaded.AlipayUID != "" || loaded.Nickname != "已注销用户" || loaded.Status != 0 || loaded.AnonymizedAt == nil {
+		t.Fatalf("user PII should be anonymized, got %+v", loaded)
+	}
+	var passengers []domain.Passenger
+	db.Where("user_id = ?", user.ID).Find(&passengers)
+	if len(passengers) != 1 || passengers[0].ID != travelled.ID || passengers[0].IDNumber != "" || passengers[0].Name != "已注销" || passengers[0].IsFavorite {
+		t.Fatalf("only the passenger referenced by an order should remain, anonymized: %+v", passengers)
+	}
+	var kept domain.Booking
+	if err := db.First(&kept, booking.ID).Error; err != nil || kept.PaidCents != 100000 || kept.UserID != user.ID {
+		t.Fatalf("financial records must be retained, got %+v (%v)", kept, err)
+	}
+	var notifications, otherPassengers int64
+	db.Model(&domain.Notification{}).Count(&notifications)
+	db.Model(&domain.Passenger{}).Where("user_id = ?", other.ID).Count(&otherPassengers)
+	if notifications != 1 || otherPassengers != 1 {
+		t.Fatalf("other users' data must be untouched, notifications=%d passengers=%d", notifications, otherPassengers)
+	}
+	if again, err := repo.FindOrCreateByPhone("13800000001"); err != nil || again.ID == user.ID {
+		t.Fatalf("the released phone should register a fresh account, got %+v (%v)", again, err)
+	}
+}
@@ -15,15 +15,23 @@ type VoyageCabinTypePriceRepository struct {
 	db *gorm.DB
 }
 
+var _ domain.VoyageCabinTypePriceRepository = (*VoyageCabinTypePriceRepository)(nil)
+
 func NewVoyageCabinTypePriceRepository(db *gorm.DB) *VoyageCabinTypePriceRepository {
 	return &VoyageCabinTypePriceRepository{db: db}
 }
 
 func (r *VoyageCabinTypePriceRepository) CreateVersion(ctx context.Context, version *domain.VoyageCabinTypePriceVersion) error {
+	if err := ensureVoyageInScope(ctx, r.db, version.VoyageID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Create(version).Error
 }
 
 func (r *VoyageCabinTypePriceRepository) UpsertCurrent(ctx context.Context, current *domain.VoyageCabinTypeCurrent) error {
+	if err := ensureVoyageInScope(ctx, r.db, current.VoyageID); err != nil {
+		return err
+	}
 	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "voyage_id"}, {Name: "cabin_type_id"}},
 		DoUpdates: clause.AssignmentColumns([]string{"inventory_total", "settlement_price_cents", "sale_price_cents", "effective_at", "version_id", "updated_at"}),
@@ -32,7 +40,7 @@ func (r *VoyageCabinTypePriceRepository) UpsertCurrent(ctx context.Context, curr
 
 func (r *VoyageCabinTypePriceRepository) GetCurrent(ctx context.Context, voyageID, cabinTypeID int64) (*domain.VoyageCabinTypeCurrent, error) {
 	var item domain.VoyageCabinTypeCurrent
-	if err := r.db.WithContext(ctx).Where("voyage_id = ? AND cabin_type_id = ?", voyageID, cabinTypeID).First(&item).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).Where("voyage_id = ? AND cabin_type_id = ?", voyageID, cabinTypeID).First(&item).Error; err != nil {
 		return nil, err
 	}
 	return &item, nil
@@ -46,6 +54,7 @@ func (r *VoyageCabinTypePriceRepository) ListCurrentByVoyages(ctx context.Contex
 	if err := r.db.WithContext(ctx).
 		Model(&domain.VoyageCabinTypeCurrent{}).
 		Where("voyage_id IN ?", voyageIDs).
+		Scopes(scopeByVoyage(ctx, "voyage_id")).
 		Order("voyage_id asc, cabin_type_id asc").
 		Find(&items).Error; err != nil {
 		return nil, err
@@ -57,6 +66,7 @@ func (r *VoyageCabinTypePriceRepository) GetCurrentAt(ctx context.Context, voyag
 	var item domain.VoyageCabinTypeCurrent
 	err := r.db.WithContext(ctx).
 		Where("voyage_id = ? AND cabin_type_id = ? AND effective_at <= ?", voyageID, cabinTypeID, at).
+		Scopes(scopeByVoyage(ctx, "voyage_id")).
 		First(&item).Error
 	if err == nil {
 		return &item, nil
@@ -93,6 +103,7 @@ func (r *VoyageCabinTypePriceRepository) ListCurrentByVoyagesAt(ctx context.Cont
 	if err := r.db.WithContext(ctx).
 		Model(&domain.VoyageCabinTypeCurrent{}).
 		Where("voyage_id IN ? AND effective_at <= ?", voyageIDs, at).
+		Scopes(scopeByVoyage(ctx, "voyage_id")).
 		Order("voyage_id asc, cabin_type_id asc").
 		Find(&items).Error; err != nil {
 		return nil, err
@@ -105,7 +116,8 @@ func (r *VoyageCabinTypePriceRepository) GetLatestVersionAt(ctx context.Context,
 	var item domain.VoyageCabinTypePriceVersion
 	err := r.db.WithContext(ctx).
 		Model(&domain.VoyageCabinTypePriceVersion{}).
-		Where("voyage_id = ? AND cabin_type_id = ? AND effective_at <= ?", voyageID, cabinTypeID, at).
+		Where("voyage_id = ? AND cabin_type_id = ? AND effective_at <= ? AND cancelled_at IS NULL", voyageID, cabinTypeID, at).
+		Scopes(scopeByVoyage(ctx, "voyage_id")).
 		Order("effective_at desc, id desc").
 		First(&item).Error
 	if err != nil {
@@ -122,7 +134,8 @@ func (r *VoyageCabinTypePriceRepository) ListVersions(ctx context.Context, voyag
 	var total int64
 	q := r.db.WithContext(ctx).
 		Model(&domain.VoyageCabinTypePriceVersion{}).
-		Where("voyage_id = ? AND cabin_type_id = ?", voyageID, cabinTypeID)
+		Where("voyage_id = ? AND cabin_type_id = ?", voyageID, cabinTypeID).
+		Scopes(scopeByVoyage(ctx, "voyage_id"))
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -131,3 +144,64 @@ func (r *VoyageCabinTypePriceRepository) ListVersions(ctx context.Context, voyag
 	}
 	return items, total, nil
 }
+
+func (r *VoyageCabinTypePriceRepository) GetVersion(ctx context.Context, id int64) (*domain.VoyageCabinTypePriceVersion, error) {
+	var item domain.VoyageCabinTypePriceVersion
+	if err := r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ListPendingVersions 查询生效时间晚于 at 且未撤销的版本；voyageID/cabinTypeID 为 0 表示不限。
+func (r *VoyageCabinTypePriceRepository) ListPendingVersions(ctx context.Context, voyageID, cabinTypeID int64, at time.Time, page, pageSize int) ([]domain.VoyageCabinTypePriceVersion, int64, error) {
+	var items []domain.VoyageCabinTypePriceVersion
+	var total int64
+	q := r.db.WithContext(ctx).
+		Model(&domain.VoyageCabinTypePriceVersion{}).
+		Where("effective_at > ? AND cancelled_at IS NULL", at).
+		Scopes(scopeByVoyage(ctx, "voyage_id"))
+	if voyageID > 0 {
+		q = q.Where("voyage_id = ?", voyageID)
+	}
+	if cabinTypeID > 0 {
+		q = q.Where("cabin_type_id = ?", cabinTypeID)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := q.Order("effective_at asc, id asc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// CancelVersion 以“尚未生效且未撤销”为条件撤销版本，条件不满足（含不在数据范围内）时返回 domain.ErrPriceVersionNotPending。
+func (r *VoyageCabinTypePriceRepository) CancelVersion(ctx context.Context, id, staffID int64, at time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&domain.VoyageCabinTypePriceVersion{}).
+		Where("id = ? AND effective_at > ? AND cancelled_at IS NULL", id, at).
+		Scopes(scopeByVoyage(ctx, "voyage_id")).
+		Updates(map[string]interface{}{"cancelled_at": at, "cancelled_by": staffID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPriceVersionNotPending
+	}
+	return nil
+}
+
+// ListStaleCurrentKeys 找出存在已到期、未撤销且比当前态更新的版本的航次舱型，供调度器刷新当前态。
+func (r *VoyageCabinTypePriceRepository) ListStaleCurrentKeys(ctx context.Context, at time.Time) ([]domain.VoyageCabinTypeKey, error) {
+	out := make([]domain.VoyageCabinTypeKey, 0)
+	err := r.db.WithContext(ctx).
+		Table("voyage_cabin_type_price_versions AS v").
+		Select("DISTINCT v.voyage_id AS voyage_id, v.cabin_type_id AS cabin_type_id").
+		Joins("LEFT JOIN voyage_cabin_type_current c ON c.voyage_id = v.voyage_id AND c.cabin_type_id = v.cabin_type_id").
+		Where("v.effective_at <= ? AND v.cancelled_at IS NULL", at).
+		Where("c.voyage_id IS NULL OR v.effective_at > c.effective_at OR (v.effective_at = c.effective_at AND v.id > c.version_id)").
+		Order("v.voyage_id asc, v.cabin_type_id asc").
+		Scan(&out).Error
+	return out, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newPriceVersionTestRepo 创建 SQLite 内存库并返回航次舱型价格仓储实例。
+func newPriceVersionTestRepo(t *testing.T) *VoyageCabinTypePriceRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.VoyageCabinTypePriceVersion{}, &domain.VoyageCabinTypeCurrent{}))
+	return NewVoyageCabinTypePriceRepository(db)
+}
+
+func TestVoyageCabinTypePriceRepository_PendingAndCancel(t *testing.T) {
+	repo := newPriceVersionTestRepo(t)
+	ctx := context.Background()
+	now := time.Date(2026, 6, 1, 2, 0, 0, 0, time.UTC)
+
+	past := &domain.VoyageCabinTypePriceVersion{VoyageID: 1, CabinTypeID: 10, SalePriceCents: 100, EffectiveAt: now.Add(-time.Hour)}
+	future := &domain.VoyageCabinTypePriceVersion{VoyageID: 1, CabinTypeID: 10, SalePriceCents: 200, EffectiveAt: now.Add(time.Hour)}
+	other := &domain.VoyageCabinTypePriceVersion{VoyageID: 2, CabinTypeID: 10, SalePriceCents: 300, EffectiveAt: now.Add(2 * time.Hour)}
+	for _, v := range []*domain.VoyageCabinTypePriceVersion{past, future, other} {
+		require.NoError(t, repo.CreateVersion(ctx, v))
+	}
+
+	items, total, err := repo.ListPendingVersions(ctx, 0, 0, now, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, future.ID, items[0].ID, "按生效时间升序")
+
+	items, total, err = repo.ListPendingVersions(ctx, 2, 10, now, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, other.ID, items[0].ID)
+
+	assert.True(t, errors.Is(repo.CancelVersion(ctx, past.ID, 7, now), domain.ErrPriceVersionNotPending), "已生效版本不可撤销")
+	require.NoError(t, repo.CancelVersion(ctx, future.ID, 7, now))
+	assert.True(t, errors.Is(repo.CancelVersion(ctx, future.ID, 7, now), domain.ErrPriceVersionNotPending), "不可重复撤销")
+
+	got, err := repo.GetVersion(ctx, future.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.CancelledAt)
+	require.NotNil(t, got.CancelledBy)
+	assert.Equal(t, int64(7), *got.CancelledBy)
+
+	_, total, err = repo.ListPendingVersions(ctx, 1, 0, now, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+
+	// 撤销的版本即使到期也不参与当前价计算。
+	latest, err := repo.GetLatestVersionAt(ctx, 1, 10, now.Add(3*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, past.ID, latest.ID)
+}
+
+func TestVoyageCabinTypePriceRepository_ListStaleCurrentKeys(t *testing.T) {
+	repo := newPriceVersionTestRepo(t)
+	ctx := context.Background()
+	now := time.Date(2026, 6, 1, 2, 0, 0, 0, time.UTC)
+
+	v1 := &domain.VoyageCabinTypePriceVersion{VoyageID: 1, CabinTypeID: 10, EffectiveAt: now.Add(-2 * time.Hour)}
+	v2 := &domain.VoyageCabinTypePriceVersion{VoyageID: 1, CabinTypeID: 10, EffectiveAt: now.Add(-time.Minute)}
+	v3 := &domain.VoyageCabinTypePriceVersion{VoyageID: 2, CabinTypeID: 10, EffectiveAt: now.Add(-time.Hour)}
+	v4 := &domain.VoyageCabinTypePriceVersion{VoyageID: 3, CabinTypeID: 10, EffectiveAt: now.Add(-time.Hour)}
+	v5 := &domain.VoyageCabinTypePriceVersion{VoyageID: 3, CabinTypeID: 10, EffectiveAt: now.Add(time.Hour)}
+	for _, v := range []*domain.VoyageCabinTypePriceVersion{v1, v2, v3, v4, v5} {
+		require.NoError(t, repo.CreateVersion(ctx, v))
+	}
+	// 航次 1 当前态停留在 v1（v2 已到期未提升）；航次 3 当前态已是最新；航次 2 尚无当前态。
+	require.NoError(t, repo.UpsertCurrent(ctx, &domain.VoyageCabinTypeCurrent{VoyageID: 1, CabinTypeID: 10, EffectiveAt: v1.EffectiveAt, VersionID: v1.ID}))
+	require.NoError(t, repo.UpsertCurrent(ctx, &domain.VoyageCabinTypeCurrent{VoyageID: 3, CabinTypeID: 10, EffectiveAt: v4.EffectiveAt, VersionID: v4.ID}))
+
+	keys, err := repo.ListStaleCurrentKeys(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.VoyageCabinTypeKey{{VoyageID: 1, CabinTypeID: 10}, {VoyageID: 2, CabinTypeID: 10}}, keys)
+
+	require.NoError(t, repo.UpsertCurrent(ctx, &domain.VoyageCabinTypeCurrent{VoyageID: 1, CabinTypeID: 10, EffectiveAt: v2.EffectiveAt, VersionID: v2.ID}))
+	keys, err = repo.ListStaleCurrentKeys(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.VoyageCabinTypeKey{{VoyageID: 2, CabinTypeID: 10}}, keys)
+}
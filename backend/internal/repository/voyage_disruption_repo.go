@@ -0,0 +1,551 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// disruptionUnresolvedStatuses 为尚未处理的受影响订单状态。
+var disruptionUnresolvedStatuses = []string{domain.DisruptionBookingPending, domain.DisruptionBookingOffered}
+
+// scopeDisruptionBookings 按所属事件的航次过滤受影响订单，仅保留授权公司航次下的记录。
+func scopeDisruptionBookings(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return restrictToCompanies(ctx, db, "disruption_id IN (SELECT voyage_disruptions.id FROM voyage_disruptions WHERE voyage_disruptions.voyage_id IN ("+voyageIDsInScope+"))")
+	}
+}
+
+// VoyageDisruptionRepository 提供航次停航/变更事件与受影响订单处理的数据访问实现。
+type VoyageDisruptionRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.VoyageDisruptionRepository = (*VoyageDisruptionRepository)(nil)
+
+// NewVoyageDisruptionRepository 创建停航/变更仓储实例。
+func NewVoyageDisruptionRepository(db *gorm.DB) *VoyageDisruptionRepository {
+	return &VoyageDisruptionRepository{db: db}
+}
+
+// Open 创建停航/变更事件：标记航次（取消时同时关闭预订），快照航次下全部待出行订单并写入乘客通知。
+func (r *VoyageDisruptionRepository) Open(ctx context.Context, disruption *domain.VoyageDisruption, notify domain.DisruptionNotifier) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var voyage domain.Voyage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(scopeByCruise(ctx, "cruise_id")).First(&voyage, disruption.VoyageID).Error; err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&domain.VoyageDisruption{}).
+			Where("voyage_id = ? AND status = ?", disruption.VoyageID, domain.VoyageDisruptionStatusOpen).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return domain.ErrVoyageDisruptionOpen
+		}
+		updates := map[string]any{"disruption": disruption.Type, "updated_at": time.Now()}
+		if disruption.Type == domain.VoyageDisruptionCancelled {
+			updates["status"] = 0
+		}
+		if err := tx.Model(&domain.Voyage{}).Where("id = ?", voyage.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		items, err := snapshotAffectedBookingsTx(tx, voyage.ID)
+		if err != nil {
+			return err
+		}
+		disruption.Status = domain.VoyageDisruptionStatusOpen
+		disruption.AffectedCount = len(items)
+		if err := tx.Create(disruption).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].DisruptionID = disruption.ID
+			if err := tx.Create(&items[i]).Error; err != nil {
+				return err
+			}
+			if err := notifyDisruptionTx(tx, notify, &items[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// snapshotAffectedBookingsTx 按订单 ID 顺序快照航次下待出行订单的舱型、人数与金额。
+func snapshotAffectedBookingsTx(tx *gorm.DB, voyageID int64) ([]domain.DisruptionBooking, error) {
+	var bookings []domain.Booking
+	if err := tx.Where("voyage_id = ? AND status IN ?", voyageID, domain.DisruptionAffectedOrderStatuses).
+		Order("id asc").Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+	if len(bookings) == 0 {
+		return nil, nil
+	}
+	skuIDs := make([]int64, 0, len(bookings))
+	bookingIDs := make([]int64, 0, len(bookings))
+	for _, b := range bookings {
+		skuIDs = append(skuIDs, b.CabinSKUID)
+		bookingIDs = append(bookingIDs, b.ID)
+	}
+	var skus []domain.CabinSKU
+	if err := tx.Where("id IN ?", skuIDs).Find(&skus).Error; err != nil {
+		return nil, err
+	}
+	cabinTypes := make(map[int64]int64, len(skus))
+	for _, sku := range skus {
+		cabinTypes[sku.ID] = sku.CabinTypeID
+	}
+	var counts []struct {
+		BookingID int64
+		Guests    int
+	}
+	if err := tx.Model(&domain.BookingPassenger{}).
+		Select("booking_id, COUNT(*) AS guests").
+		Where("booking_id IN ?", bookingIDs).
+		Group("booking_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	guests := make(map[int64]int, len(counts))
+	for _, c := range counts {
+		guests[c.BookingID] = c.Guests
+	}
+
+	items := make([]domain.DisruptionBooking, len(bookings))
+	for i, b := range bookings {
+		items[i] = domain.DisruptionBooking{
+			BookingID:     b.ID,
+			UserID:        b.UserID,
+			CabinSKUID:    b.CabinSKUID,
+			CabinTypeID:   cabinTypes[b.CabinSKUID],
+			Guests:        guests[b.ID],
+			BookingStatus: b.Status,
+			TotalCents:    b.TotalCents,
+			PaidCents:     b.PaidCents,
+			Status:        domain.DisruptionBookingPending,
+		}
+	}
+	return items, nil
+}
+
+func (r *VoyageDisruptionRepository) GetByID(ctx context.Context, id int64) (*domain.VoyageDisruption, error) {
+	var item domain.VoyageDisruption
+	if err := r.db.WithContext(ctx).Scopes(scopeByVoyage(ctx, "voyage_id")).Preload("Voyage").First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *VoyageDisruptionRepository) List(ctx context.Context, voyageID int64, status string, page, pageSize int) ([]domain.VoyageDisruption, int64, error) {
+	q := r.db.WithContext(ctx).Model(&domain.VoyageDisruption{}).Scopes(scopeByVoyage(ctx, "voyage_id"))
+	if voyageID > 0 {
+		q = q.Where("voyage_id = ?", voyageID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	items := []domain.VoyageDisruption{}
+	if err := q.Preload("Voyage").Order("id desc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *VoyageDisruptionRepository) ListBookings(ctx context.Context, filter domain.DisruptionBookingFilter, page, pageSize int) ([]domain.DisruptionBooking, int64, error) {
+	q := r.db.WithContext(ctx).Model(&domain.DisruptionBooking{}).Scopes(scopeDisruptionBookings(ctx))
+	if filter.DisruptionID > 0 {
+		q = q.Where("disruption_id = ?", filter.DisruptionID)
+	}
+	if filter.UserID > 0 {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Resolution != "" {
+		q = q.Where("resolution = ?", filter.Resolution)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	items := []domain.DisruptionBooking{}
+	if err := q.Order("id asc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *VoyageDisruptionRepository) GetBooking(ctx context.Context, id int64) (*domain.DisruptionBooking, error) {
+	var item domain.DisruptionBooking
+	if err := r.db.WithContext(ctx).Scopes(scopeDisruptionBookings(ctx)).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *VoyageDisruptionRepository) FindBooking(ctx context.Context, disruptionID, bookingID int64) (*domain.DisruptionBooking, error) {
+	var item domain.DisruptionBooking
+	if err := r.db.WithContext(ctx).Scopes(scopeDisruptionBookings(ctx)).Where("disruption_id = ? AND booking_id = ?", disruptionID, bookingID).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Offer 为尚未处理的受影响订单记录替代方案并写入乘客通知，已处理的订单跳过。
+func (r *VoyageDisruptionRepository) Offer(ctx context.Context, ids []int64, offer domain.DisruptionOffer, notify domain.DisruptionNotifier) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	alternatives := make([]string, len(offer.AlternativeVoyageIDs))
+	for i, id := range offer.AlternativeVoyageIDs {
+		alternatives[i] = strconv.FormatInt(id, 10)
+	}
+	offered := 0
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var items []domain.DisruptionBooking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status IN ?", ids, disruptionUnresolvedStatuses).
+			Scopes(scopeDisruptionBookings(ctx)).
+			Order("id asc").Find(&items).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			item := &items[i]
+			item.Status = domain.DisruptionBookingOffered
+			item.OfferedOptions = strings.Join(offer.Options, ",")
+			item.AlternativeVoyageIDs = strings.Join(alternatives, ",")
+			item.WaivePriceDifference = offer.WaivePriceDifference
+			item.CreditBonusPercent = offer.CreditBonusPercent
+			item.OfferedAt = &offer.At
+			if err := tx.Model(&domain.DisruptionBooking{}).Where("id = ?", item.ID).Updates(map[string]any{
+				"status":                 item.Status,
+				"offered_options":        item.OfferedOptions,
+				"alternative_voyage_ids": item.AlternativeVoyageIDs,
+				"waive_price_difference": item.WaivePriceDifference,
+				"credit_bonus_percent":   item.CreditBonusPercent,
+				"offered_at":             offer.At,
+				"updated_at":             offer.At,
+			}).Error; err != nil {
+				return err
+			}
+			if err := notifyDisruptionTx(tx, notify, item); err != nil {
+				return err
+			}
+		}
+		offered = len(items)
+		return nil
+	})
+	return offered, err
+}
+
+// Resolve 在一个事务内处理单个受影响订单：
+//   - rebook：依次尝试占用候选舱房库存，创建新订单（沿用原订单状态、已付金额与乘客），多付部分原路退款；
+//   - refund：按已付金额全额退款，不适用退款阶梯规则；
+//   - credit：按已付金额加补偿比例发放抵用金。
+//
+// 原订单由停航流程直接关闭（不经订单状态机）并写入状态日志，原舱房库存退回。
+func (r *VoyageDisruptionRepository) Resolve(ctx context.Context, id int64, res domain.DisruptionResolution, notify domain.DisruptionNotifier) (*domain.DisruptionBooking, error) {
+	var item domain.DisruptionBooking
+	var fromStatus, toStatus string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(scopeDisruptionBookings(ctx)).First(&item, id).Error; err != nil {
+			return err
+		}
+		if item.Status == domain.DisruptionBookingResolved {
+			return domain.ErrDisruptionBookingResolved
+		}
+		var disruption domain.VoyageDisruption
+		if err := tx.First(&disruption, item.DisruptionID).Error; err != nil {
+			return err
+		}
+		if disruption.Status != domain.VoyageDisruptionStatusOpen {
+			return domain.ErrVoyageDisruptionClosed
+		}
+		var booking domain.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, item.BookingID).Error; err != nil {
+			return err
+		}
+		if !slices.Contains(domain.DisruptionAffectedOrderStatuses, booking.Status) {
+			return fmt.Errorf("%w: booking %d is %s", domain.ErrDisruptionBookingResolved, booking.ID, booking.Status)
+		}
+
+		reason := fmt.Sprintf("voyage disruption %d", disruption.ID)
+		closeStatus := domain.OrderStatusCancelled
+		remark := reason + ": " + res.Resolution
+		switch res.Resolution {
+		case domain.DisruptionResolutionRebook:
+			if err := ensureVoyageInScope(ctx, tx, res.TargetVoyageID); err != nil {
+				return err
+			}
+			newBooking, diff, err := rebookTx(tx, &booking, res)
+			if err != nil {
+				return err
+			}
+			item.NewBookingID, item.NewVoyageID, item.PriceDiffCents = newBooking.ID, newBooking.VoyageID, diff
+			// 支付记录随订单转到新订单，之后的退款按新订单的支付记录发起
+			if err := tx.Model(&domain.Payment{}).Where("order_id = ?", booking.ID).Update("order_id", newBooking.ID).Error; err != nil {
+				return err
+			}
+			if overpaid := booking.PaidCents - newBooking.PaidCents; overpaid > 0 {
+				refunded, err := createDisruptionRefundsTx(tx, newBooking.ID, overpaid, reason+" price difference")
+				if err != nil {
+					return err
+				}
+				item.RefundCents = refunded
+			}
+			remark = fmt.Sprintf("%s: rebooked to booking %d", reason, newBooking.ID)
+		case domain.DisruptionResolutionRefund:
+			refunded, err := createDisruptionRefundsTx(tx, booking.ID, booking.PaidCents, reason)
+			if err != nil {
+				return err
+			}
+			item.RefundCents = refunded
+			if booking.PaidCents > 0 {
+				closeStatus = domain.OrderStatusRefunding
+			}
+		case domain.DisruptionResolutionCredit:
+			amount := booking.PaidCents + booking.PaidCents*int64(res.CreditBonusPercent)/100
+			if amount > 0 {
+				credit := &domain.TravelCredit{
+					UserID:          booking.UserID,
+					AmountCents:     amount,
+					BalanceCents:    amount,
+					SourceBookingID: booking.ID,
+					DisruptionID:    disruption.ID,
+					Status:          domain.TravelCreditStatusActive,
+					ExpiresAt:       res.CreditExpiresAt,
+				}
+				if err := tx.Create(credit).Error; err != nil {
+					return err
+				}
+				item.CreditID = credit.ID
+			}
+		default:
+			return fmt.Errorf("unknown disruption resolution %q", res.Resolution)
+		}
+		if err := forceBookingStatusTx(tx, &booking, closeStatus, res.OperatorID, remark); err != nil {
+			return err
+		}
+		fromStatus, toStatus = booking.Status, closeStatus
+		if err := releaseBookingCabinTx(tx, booking.CabinSKUID); err != nil {
+			return err
+		}
+
+		item.Status = domain.DisruptionBookingResolved
+		item.Resolution = res.Resolution
+		item.ResolvedBy = res.OperatorID
+		item.ResolvedAt = &res.At
+		item.Remark = res.Remark
+		if err := tx.Model(&domain.DisruptionBooking{}).Where("id = ?", item.ID).Updates(map[string]any{
+			"status":           item.Status,
+			"resolution":       item.Resolution,
+			"new_booking_id":   item.NewBookingID,
+			"new_voyage_id":    item.NewVoyageID,
+			"price_diff_cents": item.PriceDiffCents,
+			"refund_cents":     item.RefundCents,
+			"credit_id":        item.CreditID,
+			"resolved_by":      item.ResolvedBy,
+			"resolved_at":      res.At,
+			"remark":           item.Remark,
+			"updated_at":       res.At,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.VoyageDisruption{}).Where("id = ?", disruption.ID).
+			Updates(map[string]any{"resolved_count": gorm.Expr("resolved_count + 1"), "updated_at": res.At}).Error; err != nil {
+			return err
+		}
+		return notifyDisruptionTx(tx, notify, &item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.OrderTransitions.WithLabelValues(fromStatus, toStatus).Inc()
+	return &item, nil
+}
+
+// rebookTx 在改签目标航次上按顺序占用第一间有余量的候选舱房并创建新订单，返回新订单与差价。
+// 新订单沿用原订单状态、渠道与乘客；免收差价时按原金额成交，否则按新金额成交、已付金额不超过新金额。
+// 已支付订单改签到更贵的舱房且未免收差价时返回 ErrRebookPriceDifference，避免新订单带着未付差价显示为已支付。
+func rebookTx(tx *gorm.DB, booking *domain.Booking, res domain.DisruptionResolution) (*domain.Booking, int64, error) {
+	var chosen *domain.DisruptionRebookCandidate
+	for i := range res.Candidates {
+		err := adjustInventoryTx(tx, res.Candidates[i].CabinSKUID, -1, "disruption_rebook")
+		if errors.Is(err, domain.ErrInsufficientInventory) || errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		chosen = &res.Candidates[i]
+		break
+	}
+	if chosen == nil {
+		return nil, 0, domain.ErrNoRebookCabin
+	}
+
+	diff := chosen.TotalCents - booking.TotalCents
+	total := chosen.TotalCents
+	if diff > 0 && res.WaivePriceDifference {
+		total = booking.TotalCents
+	} else if diff > 0 && booking.Status != domain.OrderStatusCreated && booking.Status != domain.OrderStatusPendingPayment {
+		return nil, 0, fmt.Errorf("%w: booking %d", domain.ErrRebookPriceDifference, booking.ID)
+	}
+	newBooking := &domain.Booking{
+		UserID:     booking.UserID,
+		VoyageID:   res.TargetVoyageID,
+		CabinSKUID: chosen.CabinSKUID,
+		Status:     booking.Status,
+		TotalCents: total,
+		PaidCents:  min(booking.PaidCents, total),
+		Channel:    booking.Channel,
+	}
+	if err := tx.Create(newBooking).Error; err != nil {
+		return nil, 0, err
+	}
+	var passengers []domain.BookingPassenger
+	if err := tx.Where("booking_id = ?", booking.ID).Order("id asc").Find(&passengers).Error; err != nil {
+		return nil, 0, err
+	}
+	for _, p := range passengers {
+		if err := tx.Create(&domain.BookingPassenger{BookingID: newBooking.ID, PassengerID: p.PassengerID}).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := tx.Create(&domain.OrderStatusLog{
+		OrderID:    newBooking.ID,
+		ToStatus:   newBooking.Status,
+		OperatorID: res.OperatorID,
+		Remark:     fmt.Sprintf("rebooked from booking %d", booking.ID),
+	}).Error; err != nil {
+		return nil, 0, err
+	}
+	return newBooking, diff, nil
+}
+
+// createDisruptionRefundsTx 按支付记录顺序为订单发起合计 amount 的待审核退款，返回实际发起金额。
+// 每笔支付的退款不超过其剩余可退金额；已支付记录不足以退回 amount 时返回 ErrDisruptionRefundShort，
+// 避免订单按退款中关闭而实际少退。
+func createDisruptionRefundsTx(tx *gorm.DB, bookingID, amount int64, reason string) (int64, error) {
+	if amount <= 0 {
+		return 0, nil
+	}
+	var payments []domain.Payment
+	if err := tx.Where("order_id = ? AND status = ?", bookingID, "paid").Order("id asc").Find(&payments).Error; err != nil {
+		return 0, err
+	}
+	var refunded int64
+	for _, payment := range payments {
+		if refunded >= amount {
+			break
+		}
+		var already int64
+		if err := tx.Model(&domain.Refund{}).
+			Where("payment_id = ? AND status != ?", payment.ID, "cancelled").
+			Select("COALESCE(SUM(amount_cents), 0)").
+			Scan(&already).Error; err != nil {
+			return 0, err
+		}
+		part := min(payment.AmountCents-already, amount-refunded)
+		if part <= 0 {
+			continue
+		}
+		if err := tx.Create(&domain.Refund{PaymentID: payment.ID, AmountCents: part, Reason: reason, Status: "pending"}).Error; err != nil {
+			return 0, err
+		}
+		refunded += part
+	}
+	if refunded < amount {
+		return 0, fmt.Errorf("%w: booking %d refunded %d of %d", domain.ErrDisruptionRefundShort, bookingID, refunded, amount)
+	}
+	return refunded, nil
+}
+
+// forceBookingStatusTx 由停航流程直接变更订单状态并写入状态日志；已支付订单的取消/退款不受常规状态机约束。
+func forceBookingStatusTx(tx *gorm.DB, booking *domain.Booking, status string, operatorID int64, remark string) error {
+	if err := tx.Model(&domain.Booking{}).Where("id = ?", booking.ID).Update("status", status).Error; err != nil {
+		return err
+	}
+	return tx.Create(&domain.OrderStatusLog{
+		OrderID:    booking.ID,
+		FromStatus: booking.Status,
+		ToStatus:   status,
+		OperatorID: operatorID,
+		Remark:     remark,
+	}).Error
+}
+
+// releaseBookingCabinTx 退回原订单占用的舱房库存；舱房没有库存记录时忽略。
+func releaseBookingCabinTx(tx *gorm.DB, skuID int64) error {
+	err := adjustInventoryTx(tx, skuID, 1, "disruption_release")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// notifyDisruptionTx 在当前事务内写入受影响订单的乘客通知。
+func notifyDisruptionTx(tx *gorm.DB, notify domain.DisruptionNotifier, item *domain.DisruptionBooking) error {
+	if notify == nil {
+		return nil
+	}
+	n, err := notify(item)
+	if err != nil || n == nil {
+		return err
+	}
+	return tx.Create(n).Error
+}
+
+// Close 结案：所有受影响订单处理完毕后关闭事件；行程变更结案时清除航次的变更标记，取消的航次保持取消标记。
+func (r *VoyageDisruptionRepository) Close(ctx context.Context, id int64, at time.Time) (*domain.VoyageDisruption, error) {
+	var disruption domain.VoyageDisruption
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Scopes(scopeByVoyage(ctx, "voyage_id")).First(&disruption, id).Error; err != nil {
+			return err
+		}
+		if disruption.Status != domain.VoyageDisruptionStatusOpen {
+			return domain.ErrVoyageDisruptionClosed
+		}
+		var unresolved int64
+		if err := tx.Model(&domain.DisruptionBooking{}).
+			Where("disruption_id = ? AND status IN ?", id, disruptionUnresolvedStatuses).
+			Count(&unresolved).Error; err != nil {
+			return err
+		}
+		if unresolved > 0 {
+			return fmt.Errorf("%w: %d remaining", domain.ErrDisruptionUnresolved, unresolved)
+		}
+		disruption.Status = domain.VoyageDisruptionStatusClosed
+		disruption.ClosedAt = &at
+		if err := tx.Model(&domain.VoyageDisruption{}).Where("id = ?", id).
+			Updates(map[string]any{"status": disruption.Status, "closed_at": at, "updated_at": at}).Error; err != nil {
+			return err
+		}
+		if disruption.Type == domain.VoyageDisruptionModified {
+			return tx.Model(&domain.Voyage{}).Where("id = ?", disruption.VoyageID).
+				Updates(map[string]any{"disruption": "", "updated_at": at}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &disruption, nil
+}
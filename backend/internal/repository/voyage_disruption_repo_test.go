@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newVoyageDisruptionTestRepo(t *testing.T) (*VoyageDisruptionRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.Voyage{}, &domain.CabinSKU{}, &domain.CabinInventory{}, &domain.InventoryLog{},
+		&domain.Booking{}, &domain.BookingPassenger{}, &domain.OrderStatusLog{}, &domain.Payment{}, &domain.Refund{}, &domain.Notification{},
+		&domain.VoyageDisruption{}, &domain.DisruptionBooking{}, &domain.TravelCredit{}))
+	return NewVoyageDisruptionRepository(db), db
+}
+
+// seedDisruptionVoyages 创建受影响航次（舱型 3 的两间舱房）与改签目标航次（舱型 3 的一间舱房），
+// 并在受影响航次上创建三笔已付订单与一笔已取消订单。
+func seedDisruptionVoyages(t *testing.T, db *gorm.DB) (bookings []domain.Booking, targetSKU int64) {
+	t.Helper()
+	depart := time.Date(2026, 8, 1, 16, 0, 0, 0, time.UTC)
+	disrupted := domain.Voyage{CruiseID: 7, Code: "SPEC-20260801", DepartDate: depart, ReturnDate: depart.AddDate(0, 0, 4), Status: 1}
+	target := domain.Voyage{CruiseID: 7, Code: "SPEC-20260808", DepartDate: depart.AddDate(0, 0, 7), ReturnDate: depart.AddDate(0, 0, 11), Status: 1}
+	require.NoError(t, db.Create(&disrupted).Error)
+	require.NoError(t, db.Create(&target).Error)
+	skus := []domain.CabinSKU{
+		{VoyageID: disrupted.ID, CabinTypeID: 3, Code: "SPEC-20260801-8001", Status: 1},
+		{VoyageID: disrupted.ID, CabinTypeID: 3, Code: "SPEC-20260801-8002", Status: 1},
+		{VoyageID: target.ID, CabinTypeID: 3, Code: "SPEC-20260808-8001", Status: 1},
+	}
+	require.NoError(t, db.Create(&skus).Error)
+	for _, sku := range skus {
+		require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: sku.ID, Total: 1}).Error)
+	}
+	bookings = []domain.Booking{
+		{UserID: 11, VoyageID: disrupted.ID, CabinSKUID: skus[0].ID, Status: domain.OrderStatusPaid, TotalCents: 500000, PaidCents: 500000},
+		{UserID: 12, VoyageID: disrupted.ID, CabinSKUID: skus[1].ID, Status: domain.OrderStatusConfirmed, TotalCents: 400000, PaidCents: 400000},
+		{UserID: 13, VoyageID: disrupted.ID, CabinSKUID: skus[1].ID, Status: domain.OrderStatusPaid, TotalCents: 300000, PaidCents: 300000},
+		{UserID: 14, VoyageID: disrupted.ID, CabinSKUID: skus[1].ID, Status: domain.OrderStatusCancelled, TotalCents: 300000},
+	}
+	require.NoError(t, db.Create(&bookings).Error)
+	require.NoError(t, db.Create(&domain.BookingPassenger{BookingID: bookings[0].ID, PassengerID: 101}).Error)
+	require.NoError(t, db.Create(&domain.BookingPassenger{BookingID: bookings[0].ID, PassengerID: 102}).Error)
+	for _, b := range bookings[:3] {
+		require.NoError(t, db.Create(&domain.Payment{OrderID: b.ID, AmountCents: b.PaidCents, Status: "paid"}).Error)
+	}
+	return bookings, skus[2].ID
+}
+
+func noticeFor(template string) domain.DisruptionNotifier {
+	return func(item *domain.DisruptionBooking) (*domain.Notification, error) {
+		payload, _ := json.Marshal(map[string]int64{"booking_id": item.BookingID})
+		return &domain.Notification{UserID: item.UserID, Channel: "sms", Template: template, Payload: string(payload), Status: "pending"}, nil
+	}
+}
+
+func TestVoyageDisruptionRepository_OpenSnapshotsAffectedBookings(t *testing.T) {
+	repo, db := newVoyageDisruptionTestRepo(t)
+	ctx := context.Background()
+	bookings, _ := seedDisruptionVoyages(t, db)
+
+	disruption := &domain.VoyageDisruption{VoyageID: bookings[0].VoyageID, Type: domain.VoyageDisruptionCancelled, Reason: "台风"}
+	require.NoError(t, repo.Open(ctx, disruption, noticeFor("notice")))
+	assert.Equal(t, 3, disruption.AffectedCount)
+	assert.Equal(t, domain.VoyageDisruptionStatusOpen, disruption.Status)
+
+	var voyage domain.Voyage
+	require.NoError(t, db.First(&voyage, bookings[0].VoyageID).Error)
+	assert.Equal(t, domain.VoyageDisruptionCancelled, voyage.Disruption)
+	assert.Equal(t, int16(0), voyage.Status, "取消的航次应关闭预订")
+
+	items, total, err := repo.ListBookings(ctx, domain.DisruptionBookingFilter{DisruptionID: disruption.ID}, 1, 20)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	assert.Equal(t, int64(3), items[0].CabinTypeID)
+	assert.Equal(t, 2, items[0].Guests)
+	assert.Equal(t, domain.OrderStatusConfirmed, items[1].BookingStatus)
+	var notices int64
+	db.Model(&domain.Notification{}).Where("template = ?", "notice").Count(&notices)
+	assert.EqualValues(t, 3, notices)
+
+	require.ErrorIs(t, repo.Open(ctx, &domain.VoyageDisruption{VoyageID: bookings[0].VoyageID, Type: domain.VoyageDisruptionModified}, nil), domain.ErrVoyageDisruptionOpen)
+
+	found, err := repo.FindBooking(ctx, disruption.ID, bookings[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), found.UserID)
+	loaded, err := repo.GetByID(ctx, disruption.ID)
+	require.NoError(t, err)
+	require.NotNil(t, loaded.Voyage)
+	assert.Equal(t, "SPEC-20260801", loaded.Voyage.Code)
+}
+
+func TestVoyageDisruptionRepository_OfferResolveAndClose(t *testing.T) {
+	repo, db := newVoyageDisruptionTestRepo(t)
+	ctx := context.Background()
+	bookings, targetSKU := seedDisruptionVoyages(t, db)
+	at := time.Date(2026, 7, 20, 9, 0, 0, 0, time.UTC)
+
+	disruption := &domain.VoyageDisruption{VoyageID: bookings[0].VoyageID, Type: domain.VoyageDisruptionModified}
+	require.NoError(t, repo.Open(ctx, disruption, nil))
+	items, _, err := repo.ListBookings(ctx, domain.DisruptionBookingFilter{DisruptionID: disruption.ID}, 1, 20)
+	require.NoError(t, err)
+
+	offered, err := repo.Offer(ctx, []int64{items[0].ID, items[1].ID}, domain.DisruptionOffer{
+		Options: []string{domain.DisruptionResolutionRebook, domain.DisruptionResolutionRefund}, AlternativeVoyageIDs: []int64{9, 10}, CreditBonusPercent: 10, At: at,
+	}, noticeFor("offer"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, offered)
+	row, err := repo.GetBooking(ctx, items[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DisruptionBookingOffered, row.Status)
+	assert.Equal(t, "rebook,refund", row.OfferedOptions)
+	assert.Equal(t, "9,10", row.AlternativeVoyageIDs)
+
+	// 改签到更便宜的舱房：新订单沿用状态与乘客，多付的 50000 分原路退回。
+	rebooked, err := repo.Resolve(ctx, items[0].ID, domain.DisruptionResolution{
+		Resolution: domain.DisruptionResolutionRebook, TargetVoyageID: 99,
+		Candidates: []domain.DisruptionRebookCandidate{{CabinSKUID: targetSKU, TotalCents: 450000}},
+		OperatorID: 5, At: at,
+	}, noticeFor("resolved"))
+	require.NoError(t, err)
+	assert.Equal(t, domain.DisruptionBookingResolved, rebooked.Status)
+	assert.EqualValues(t, -50000, rebooked.PriceDiffCents)
+	assert.EqualValues(t, 50000, rebooked.RefundCents)
+	var newBooking domain.Booking
+	require.NoError(t, db.First(&newBooking, rebooked.NewBookingID).Error)
+	assert.Equal(t, domain.OrderStatusPaid, newBooking.Status)
+	assert.EqualValues(t, 450000, newBooking.PaidCents)
+	assert.Equal(t, targetSKU, newBooking.CabinSKUID)
+	var passengers int64
+	db.Model(&domain.BookingPassenger{}).Where("booking_id = ?", newBooking.ID).Count(&passengers)
+	assert.EqualValues(t, 2, passengers)
+	var payment domain.Payment
+	require.NoError(t, db.Where("order_id = ?", newBooking.ID).First(&payment).Error, "支付记录随改签转到新订单，之后仍可退款")
+	var priceRefund domain.Refund
+	require.NoError(t, db.Where("payment_id = ?", payment.ID).First(&priceRefund).Error)
+	assert.EqualValues(t, 50000, priceRefund.AmountCents)
+	var old domain.Booking
+	require.NoError(t, db.First(&old, bookings[0].ID).Error)
+	assert.Equal(t, domain.OrderStatusCancelled, old.Status)
+	var targetInv domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", targetSKU).First(&targetInv).Error)
+	assert.Equal(t, 0, targetInv.Total)
+
+	// 目标舱房已无余量时改签失败，事务回滚。
+	_, err = repo.Resolve(ctx, items[1].ID, domain.DisruptionResolution{
+		Resolution: domain.DisruptionResolutionRebook, TargetVoyageID: 99,
+		Candidates: []domain.DisruptionRebookCandidate{{CabinSKUID: targetSKU, TotalCents: 400000}}, At: at,
+	}, nil)
+	require.ErrorIs(t, err, domain.ErrNoRebookCabin)
+
+	refunded, err := repo.Resolve(ctx, items[1].ID, domain.DisruptionResolution{Resolution: domain.DisruptionResolutionRefund, OperatorID: 5, At: at}, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 400000, refunded.RefundCents)
+	var refundedBooking domain.Booking
+	require.NoError(t, db.First(&refundedBooking, bookings[1].ID).Error)
+	assert.Equal(t, domain.OrderStatusRefunding, refundedBooking.Status, "全额退款不受状态机与退款规则限制")
+
+	_, err = repo.Close(ctx, disruption.ID, at)
+	require.ErrorIs(t, err, domain.ErrDisruptionUnresolved)
+
+	credited, err := repo.Resolve(ctx, items[2].ID, domain.DisruptionResolution{
+		Resolution: domain.DisruptionResolutionCredit, CreditBonusPercent: 10, CreditExpiresAt: at.AddDate(1, 0, 0), At: at,
+	}, nil)
+	require.NoError(t, err)
+	var credit domain.TravelCredit
+	require.NoError(t, db.First(&credit, credited.CreditID).Error)
+	assert.EqualValues(t, 330000, credit.AmountCents)
+	assert.Equal(t, int64(13), credit.UserID)
+
+	_, err = repo.Resolve(ctx, items[2].ID, domain.DisruptionResolution{Resolution: domain.DisruptionResolutionRefund, At: at}, nil)
+	require.ErrorIs(t, err, domain.ErrDisruptionBookingResolved)
+
+	closed, err := repo.Close(ctx, disruption.ID, at)
+	require.NoError(t, err)
+	assert.Equal(t, domain.VoyageDisruptionStatusClosed, closed.Status)
+	loaded, err := repo.GetByID(ctx, disruption.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, loaded.ResolvedCount)
+	assert.Empty(t, loaded.Voyage.Disruption, "行程变更结案后清除航次标记")
+
+	var refunds, notices int64
+	db.Model(&domain.Refund{}).Count(&refunds)
+	db.Model(&domain.Notification{}).Count(&notices)
+	assert.EqualValues(t, 2, refunds)
+	assert.EqualValues(t, 3, notices)
+}
+
+func TestVoyageDisruptionRepository_RebookPricierCabinAndShortRefund(t *testing.T) {
+	repo, db := newVoyageDisruptionTestRepo(t)
+	ctx := context.Background()
+	bookings, targetSKU := seedDisruptionVoyages(t, db)
+	at := time.Date(2026, 7, 20, 9, 0, 0, 0, time.UTC)
+
+	disruption := &domain.VoyageDisruption{VoyageID: bookings[0].VoyageID, Type: domain.VoyageDisruptionCancelled}
+	require.NoError(t, repo.Open(ctx, disruption, nil))
+	items, _, err := repo.ListBookings(ctx, domain.DisruptionBookingFilter{DisruptionID: disruption.ID}, 1, 20)
+	require.NoError(t, err)
+
+	// 已付订单改签到更贵的舱房且未免收差价：拒绝，事务回滚，目标舱房库存不变。
+	pricier := domain.DisruptionResolution{
+		Resolution: domain.DisruptionResolutionRebook, TargetVoyageID: 99,
+		Candidates: []domain.DisruptionRebookCandidate{{CabinSKUID: targetSKU, TotalCents: 600000}}, At: at,
+	}
+	_, err = repo.Resolve(ctx, items[0].ID, pricier, nil)
+	require.ErrorIs(t, err, domain.ErrRebookPriceDifference)
+	var targetInv domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", targetSKU).First(&targetInv).Error)
+	assert.Equal(t, 1, targetInv.Total)
+
+	// 免收差价时按原金额成交，新订单已付清。
+	pricier.WaivePriceDifference = true
+	rebooked, err := repo.Resolve(ctx, items[0].ID, pricier, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100000, rebooked.PriceDiffCents)
+	assert.Zero(t, rebooked.RefundCents)
+	var newBooking domain.Booking
+	require.NoError(t, db.First(&newBooking, rebooked.NewBookingID).Error)
+	assert.EqualValues(t, 500000, newBooking.TotalCents)
+	assert.Equal(t, newBooking.TotalCents, newBooking.PaidCents)
+
+	// 支付记录不足以退回已付金额时不按退款中关闭订单。
+	require.NoError(t, db.Where("order_id = ?", bookings[1].ID).Delete(&domain.Payment{}).Error)
+	_, err = repo.Resolve(ctx, items[1].ID, domain.DisruptionResolution{Resolution: domain.DisruptionResolutionRefund, At: at}, nil)
+	require.ErrorIs(t, err, domain.ErrDisruptionRefundShort)
+	var unresolved domain.Booking
+	require.NoError(t, db.First(&unresolved, bookings[1].ID).Error)
+	assert.Equal(t, domain.OrderStatusConfirmed, unresolved.Status)
+}
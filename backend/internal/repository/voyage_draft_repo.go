@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoyageDraftRepository 提供按航次草稿单事务写入航次及其舱位、库存与价格的数据库操作。
+type VoyageDraftRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.VoyageDraftRepository = (*VoyageDraftRepository)(nil)
+
+// NewVoyageDraftRepository 创建航次草稿仓储实例。
+func NewVoyageDraftRepository(db *gorm.DB) *VoyageDraftRepository {
+	return &VoyageDraftRepository{db: db}
+}
+
+// CreateDraft 在一个事务内写入单个航次草稿，航次编码或舱位编号冲突时整体回滚。
+func (r *VoyageDraftRepository) CreateDraft(ctx context.Context, draft *domain.VoyageDraft) error {
+	if err := ensureCruiseInScope(ctx, r.db, draft.Voyage.CruiseID); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureDraftCodesAvailable(tx, []domain.VoyageDraft{*draft}); err != nil {
+			return err
+		}
+		return createVoyageDraft(tx, 0, draft)
+	})
+}
+
+// ensureDraftCodesAvailable 在事务内复查草稿的航次编码与舱位编号是否已被占用。
+func ensureDraftCodesAvailable(tx *gorm.DB, drafts []domain.VoyageDraft) error {
+	voyageCodes := make([]string, 0, len(drafts))
+	var skuCodes []string
+	for _, draft := range drafts {
+		voyageCodes = append(voyageCodes, draft.Voyage.Code)
+		for _, cabin := range draft.Cabins {
+			skuCodes = append(skuCodes, cabin.SKU.Code)
+		}
+	}
+	if taken, err := existingCodes(tx, &domain.Voyage{}, voyageCodes); err != nil {
+		return err
+	} else if len(taken) > 0 {
+		return fmt.Errorf("%w: voyage codes %s", domain.ErrVoyageCodeConflict, strings.Join(taken, ","))
+	}
+	if taken, err := existingCodes(tx, &domain.CabinSKU{}, skuCodes); err != nil {
+		return err
+	} else if len(taken) > 0 {
+		return fmt.Errorf("%w: cabin codes %s", domain.ErrVoyageCodeConflict, strings.Join(taken, ","))
+	}
+	return nil
+}
+
+// createVoyageDraft 写入单个航次草稿，seriesID 为 0 表示不关联系列。
+// 状态为 0 的航次与舱位在插入后回写，避免被列默认值覆盖；立即生效的价格版本同时写入当前价。
+func createVoyageDraft(tx *gorm.DB, seriesID int64, draft *domain.VoyageDraft) error {
+	voyage := &draft.Voyage
+	itineraries := voyage.Itineraries
+	voyage.Itineraries = nil
+	voyage.SeriesID = seriesID
+	closed := voyage.Status == 0
+	if err := tx.Create(voyage).Error; err != nil {
+		return err
+	}
+	if closed {
+		if err := tx.Model(voyage).Update("status", 0).Error; err != nil {
+			return err
+		}
+	}
+	if len(itineraries) > 0 {
+		for i := range itineraries {
+			itineraries[i].ID = 0
+			itineraries[i].VoyageID = voyage.ID
+		}
+		if err := tx.Create(&itineraries).Error; err != nil {
+			return err
+		}
+	}
+	voyage.Itineraries = itineraries
+
+	for i := range draft.Cabins {
+		cabin := &draft.Cabins[i]
+		cabin.SKU.ID = 0
+		cabin.SKU.VoyageID = voyage.ID
+		offShelf := cabin.SKU.Status == 0
+		if err := tx.Create(&cabin.SKU).Error; err != nil {
+			return err
+		}
+		if offShelf {
+			if err := tx.Model(&cabin.SKU).Update("status", 0).Error; err != nil {
+				return err
+			}
+		}
+		inventory := domain.CabinInventory{CabinSKUID: cabin.SKU.ID, Total: cabin.InventoryTotal, AlertThreshold: cabin.AlertThreshold}
+		if err := tx.Create(&inventory).Error; err != nil {
+			return err
+		}
+		if len(cabin.Prices) > 0 {
+			for j := range cabin.Prices {
+				cabin.Prices[j].ID = 0
+				cabin.Prices[j].CabinSKUID = cabin.SKU.ID
+			}
+			if err := tx.Create(&cabin.Prices).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range draft.PriceVersions {
+		version := &draft.PriceVersions[i]
+		version.ID = 0
+		version.VoyageID = voyage.ID
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+		current := domain.VoyageCabinTypeCurrent{
+			VoyageID:             voyage.ID,
+			CabinTypeID:          version.CabinTypeID,
+			InventoryTotal:       version.InventoryTotal,
+			SettlementPriceCents: version.SettlementPriceCents,
+			SalePriceCents:       version.SalePriceCents,
+			EffectiveAt:          version.EffectiveAt,
+			VersionID:            version.ID,
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "voyage_id"}, {Name: "cabin_type_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"inventory_total", "settlement_price_cents", "sale_price_cents", "effective_at", "version_id", "updated_at"}),
+		}).Create(&current).Error; err != nil {
+			return err
+		}
+	}
+	for i := range draft.PendingPriceVersions {
+		version := &draft.PendingPriceVersions[i]
+		version.ID = 0
+		version.VoyageID = voyage.ID
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindExistingVoyageCodes 返回 codes 中已被航次占用的编码。
+func (r *VoyageDraftRepository) FindExistingVoyageCodes(ctx context.Context, codes []string) ([]string, error) {
+	return existingCodes(r.db.WithContext(ctx), &domain.Voyage{}, codes)
+}
+
+// FindExistingSKUCodes 返回 codes 中已被舱位占用的编号。
+func (r *VoyageDraftRepository) FindExistingSKUCodes(ctx context.Context, codes []string) ([]string, error) {
+	return existingCodes(r.db.WithContext(ctx), &domain.CabinSKU{}, codes)
+}
+
+// existingCodeBatch 限制单条 IN 查询的参数数量。
+const existingCodeBatch = 500
+
+// existingCodes 分批查询 model 表中已存在的 code。
+func existingCodes(db *gorm.DB, model any, codes []string) ([]string, error) {
+	taken := []string{}
+	for start := 0; start < len(codes); start += existingCodeBatch {
+		end := min(start+existingCodeBatch, len(codes))
+		var batch []string
+		if err := db.Model(model).Where("code IN ?", codes[start:end]).Order("code").Pluck("code", &batch).Error; err != nil {
+			return nil, err
+		}
+		taken = append(taken, batch...)
+	}
+	return taken, nil
+}
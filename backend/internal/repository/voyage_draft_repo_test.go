@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestVoyageDraftRepository_CreateDraftWithCalendarAndPendingPrices(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.Voyage{}, &domain.VoyageItinerary{}, &domain.CabinSKU{}, &domain.CabinInventory{}, &domain.CabinPrice{},
+		&domain.VoyageCabinTypePriceVersion{}, &domain.VoyageCabinTypeCurrent{}))
+	repo := NewVoyageDraftRepository(db)
+	ctx := context.Background()
+	depart := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	draft := seriesDraft("SPEC-20260601", depart)
+	draft.Cabins[0].Prices = []domain.CabinPrice{
+		{ID: 5, CabinSKUID: 9, Date: depart, Occupancy: 2, PriceCents: 399900},
+		{Date: depart.AddDate(0, 0, 1), Occupancy: 2, PriceCents: 419900},
+	}
+	draft.PendingPriceVersions = []domain.VoyageCabinTypePriceVersion{{CabinTypeID: 3, SalePriceCents: 359900, EffectiveAt: depart.AddDate(0, -1, 0)}}
+	require.NoError(t, repo.CreateDraft(ctx, &draft))
+	require.NotZero(t, draft.Voyage.ID)
+
+	var sku domain.CabinSKU
+	require.NoError(t, db.Where("code = ?", "SPEC-20260601-8001").First(&sku).Error)
+	var prices []domain.CabinPrice
+	require.NoError(t, db.Where("cabin_sku_id = ?", sku.ID).Order("date").Find(&prices).Error)
+	require.Len(t, prices, 2)
+	assert.NotEqual(t, int64(5), prices[0].ID)
+	assert.EqualValues(t, 419900, prices[1].PriceCents)
+
+	var versionCount, currentCount int64
+	db.Model(&domain.VoyageCabinTypePriceVersion{}).Where("voyage_id = ?", draft.Voyage.ID).Count(&versionCount)
+	db.Model(&domain.VoyageCabinTypeCurrent{}).Where("voyage_id = ?", draft.Voyage.ID).Count(&currentCount)
+	assert.EqualValues(t, 2, versionCount)
+	assert.EqualValues(t, 1, currentCount, "待生效版本不应写入当前价")
+
+	conflict := seriesDraft("SPEC-20260601", depart)
+	require.ErrorIs(t, repo.CreateDraft(ctx, &conflict), domain.ErrVoyageCodeConflict)
+}
@@ -28,6 +28,9 @@ func NewVoyageRepository(db *gorm.DB) *VoyageRepository { return &VoyageReposito
 // Create 插入一条新的航次记录。
 func (r *VoyageRepository) Create(ctx context.Context, v *domain.Voyage) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureCruiseInScope(ctx, tx, v.CruiseID); err != nil {
+			return err
+		}
 		itineraries := v.Itineraries
 		v.Itineraries = nil
 		if err := tx.Create(v).Error; err != nil {
@@ -46,6 +49,12 @@ func (r *VoyageRepository) Create(ctx context.Context, v *domain.Voyage) error {
 // Update 保存航次的所有字段修改。
 func (r *VoyageRepository) Update(ctx context.Context, v *domain.Voyage) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureVoyageInScope(ctx, tx, v.ID); err != nil {
+			return err
+		}
+		if err := ensureCruiseInScope(ctx, tx, v.CruiseID); err != nil {
+			return err
+		}
 		if err := tx.Model(&domain.Voyage{}).Where("id = ?", v.ID).
 			Updates(map[string]any{
 				"cruise_id":                   v.CruiseID,
@@ -84,6 +93,7 @@ func (r *VoyageRepository) Update(ctx context.Context, v *domain.Voyage) error {
 func (r *VoyageRepository) GetByID(ctx context.Context, id int64) (*domain.Voyage, error) {
 	var out domain.Voyage
 	if err := r.db.WithContext(ctx).
+		Scopes(scopeByCruise(ctx, "cruise_id")).
 		Preload("Cruise.Company").
 		Preload("Itineraries", func(db *gorm.DB) *gorm.DB {
 			return db.Order("day_no asc, stop_index asc")
@@ -114,6 +124,7 @@ func (r *VoyageRepository) GetByID(ctx context.Context, id int64) (*domain.Voyag
 func (r *VoyageRepository) List(ctx context.Context) ([]domain.Voyage, error) {
 	var out []domain.Voyage
 	err := r.db.WithContext(ctx).
+		Scopes(scopeByCruise(ctx, "cruise_id")).
 		Preload("Itineraries", func(db *gorm.DB) *gorm.DB {
 			return db.Order("day_no asc, stop_index asc")
 		}).
@@ -295,7 +306,7 @@ func (r *VoyageRepository) enrichVoyageMetrics(ctx context.Context, voyages []do
 
 // Delete 删除指定的航次记录。
 func (r *VoyageRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&domain.Voyage{}, id).Error
+	return scopedRowsAffected(ctx, r.db.WithContext(ctx).Scopes(scopeByCruise(ctx, "cruise_id")).Delete(&domain.Voyage{}, id))
 }
 
 // 编译时接口实现检查
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// VoyageSeriesRepository 提供航次系列及其批量生成航次的数据库操作。
+type VoyageSeriesRepository struct {
+	*VoyageDraftRepository
+	db *gorm.DB
+}
+
+var _ domain.VoyageSeriesRepository = (*VoyageSeriesRepository)(nil)
+
+// NewVoyageSeriesRepository 创建航次系列仓储实例。
+func NewVoyageSeriesRepository(db *gorm.DB) *VoyageSeriesRepository {
+	return &VoyageSeriesRepository{VoyageDraftRepository: NewVoyageDraftRepository(db), db: db}
+}
+
+// CreateWithVoyages 在一个事务内写入系列记录和全部航次草稿，任一航次编码或舱位编号冲突则整体回滚。
+func (r *VoyageSeriesRepository) CreateWithVoyages(ctx context.Context, series *domain.VoyageSeries, drafts []domain.VoyageDraft) error {
+	prices, err := json.Marshal(series.CabinPrices)
+	if err != nil {
+		return err
+	}
+	series.CabinPricesJSON = string(prices)
+	series.VoyageCount = len(drafts)
+	if err := ensureCruiseInScope(ctx, r.db, series.CruiseID); err != nil {
+		return err
+	}
+	for i := range drafts {
+		if drafts[i].Voyage.CruiseID != series.CruiseID {
+			if err := ensureCruiseInScope(ctx, r.db, drafts[i].Voyage.CruiseID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := ensureDraftCodesAvailable(tx, drafts); err != nil {
+			return err
+		}
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+		for i := range drafts {
+			if err := createVoyageDraft(tx, series.ID, &drafts[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID 根据 ID 查询航次系列。
+func (r *VoyageSeriesRepository) GetByID(ctx context.Context, id int64) (*domain.VoyageSeries, error) {
+	var series domain.VoyageSeries
+	if err := r.db.WithContext(ctx).Scopes(scopeByCruise(ctx, "cruise_id")).First(&series, id).Error; err != nil {
+		return nil, err
+	}
+	decodeSeriesCabinPrices(&series)
+	return &series, nil
+}
+
+// List 按创建时间倒序分页查询航次系列。
+func (r *VoyageSeriesRepository) List(ctx context.Context, page, pageSize int) ([]domain.VoyageSeries, int64, error) {
+	var total int64
+	query := r.db.WithContext(ctx).Model(&domain.VoyageSeries{}).Scopes(scopeByCruise(ctx, "cruise_id"))
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	items := []domain.VoyageSeries{}
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range items {
+		decodeSeriesCabinPrices(&items[i])
+	}
+	return items, total, nil
+}
+
+// ListVoyages 查询系列下的航次（按出发日期排序）。
+func (r *VoyageSeriesRepository) ListVoyages(ctx context.Context, seriesID int64) ([]domain.Voyage, error) {
+	items := []domain.Voyage{}
+	if err := r.db.WithContext(ctx).Where("series_id = ?", seriesID).Scopes(scopeByCruise(ctx, "cruise_id")).Order("depart_date ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func decodeSeriesCabinPrices(series *domain.VoyageSeries) {
+	if strings.TrimSpace(series.CabinPricesJSON) == "" {
+		return
+	}
+	var prices []domain.VoyageSeriesCabinPrice
+	if err := json.Unmarshal([]byte(series.CabinPricesJSON), &prices); err == nil {
+		series.CabinPrices = prices
+	}
+}
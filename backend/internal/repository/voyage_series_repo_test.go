@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newVoyageSeriesTestRepo(t *testing.T) (*VoyageSeriesRepository, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.VoyageSeries{}, &domain.Voyage{}, &domain.VoyageItinerary{}, &domain.CabinSKU{}, &domain.CabinInventory{},
+		&domain.VoyageCabinTypePriceVersion{}, &domain.VoyageCabinTypeCurrent{}))
+	return NewVoyageSeriesRepository(db), db
+}
+
+func seriesDraft(code string, depart time.Time) domain.VoyageDraft {
+	return domain.VoyageDraft{
+		Voyage: domain.Voyage{
+			CruiseID: 7, Code: code, DepartDate: depart, ReturnDate: depart.AddDate(0, 0, 4), Status: 0,
+			Itineraries: []domain.VoyageItinerary{{DayNo: 1, StopIndex: 1, City: "上海"}, {DayNo: 5, StopIndex: 1, City: "上海"}},
+		},
+		Cabins: []domain.VoyageDraftCabin{
+			{SKU: domain.CabinSKU{CabinTypeID: 3, Code: code + "-8001", Status: 1}, InventoryTotal: 1},
+			{SKU: domain.CabinSKU{CabinTypeID: 3, Code: code + "-8002", Status: 0}, InventoryTotal: 1, AlertThreshold: 1},
+		},
+		PriceVersions: []domain.VoyageCabinTypePriceVersion{{CabinTypeID: 3, InventoryTotal: 2, SettlementPriceCents: 300000, SalePriceCents: 399900, EffectiveAt: depart.AddDate(0, -3, 0)}},
+	}
+}
+
+func TestVoyageSeriesRepository_CreateWithVoyages(t *testing.T) {
+	repo, db := newVoyageSeriesTestRepo(t)
+	ctx := context.Background()
+	depart := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	series := &domain.VoyageSeries{Name: "五月周末", TemplateVoyageID: 1, CruiseID: 7, CodePrefix: "SPEC-", Frequency: domain.VoyageSeriesFrequencyWeekly, RepeatInterval: 1,
+		StartDate: depart, EndDate: depart.AddDate(0, 0, 7), CabinPrices: []domain.VoyageSeriesCabinPrice{{CabinTypeID: 3, SalePriceCents: 399900}}}
+	drafts := []domain.VoyageDraft{seriesDraft("SPEC-20260501", depart), seriesDraft("SPEC-20260508", depart.AddDate(0, 0, 7))}
+	require.NoError(t, repo.CreateWithVoyages(ctx, series, drafts))
+	require.NotZero(t, series.ID)
+
+	voyages, err := repo.ListVoyages(ctx, series.ID)
+	require.NoError(t, err)
+	require.Len(t, voyages, 2)
+	assert.Equal(t, "SPEC-20260501", voyages[0].Code)
+	assert.Equal(t, int16(0), voyages[0].Status, "关闭状态不应被列默认值覆盖")
+
+	var itineraryCount, inventoryCount, currentCount int64
+	db.Model(&domain.VoyageItinerary{}).Where("voyage_id = ?", voyages[1].ID).Count(&itineraryCount)
+	db.Model(&domain.CabinInventory{}).Count(&inventoryCount)
+	db.Model(&domain.VoyageCabinTypeCurrent{}).Where("voyage_id = ? AND version_id > 0", voyages[1].ID).Count(&currentCount)
+	assert.EqualValues(t, 2, itineraryCount)
+	assert.EqualValues(t, 4, inventoryCount)
+	assert.EqualValues(t, 1, currentCount)
+	var offShelf domain.CabinSKU
+	require.NoError(t, db.Where("code = ?", "SPEC-20260508-8002").First(&offShelf).Error)
+	assert.Equal(t, int16(0), offShelf.Status)
+	assert.Equal(t, voyages[1].ID, offShelf.VoyageID)
+
+	loaded, err := repo.GetByID(ctx, series.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.VoyageCount)
+	require.Len(t, loaded.CabinPrices, 1)
+	assert.EqualValues(t, 399900, loaded.CabinPrices[0].SalePriceCents)
+
+	items, total, err := repo.List(ctx, 1, 20)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Len(t, items, 1)
+}
+
+func TestVoyageSeriesRepository_CreateRollsBackOnCodeConflict(t *testing.T) {
+	repo, db := newVoyageSeriesTestRepo(t)
+	ctx := context.Background()
+	depart := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Create(&domain.CabinSKU{VoyageID: 99, CabinTypeID: 3, Code: "SPEC-20260508-8001"}).Error)
+
+	series := &domain.VoyageSeries{Name: "冲突", TemplateVoyageID: 1, CruiseID: 7, CodePrefix: "SPEC-", Frequency: domain.VoyageSeriesFrequencyDaily, RepeatInterval: 7, StartDate: depart, EndDate: depart}
+	err := repo.CreateWithVoyages(ctx, series, []domain.VoyageDraft{seriesDraft("SPEC-20260501", depart), seriesDraft("SPEC-20260508", depart.AddDate(0, 0, 7))})
+	require.ErrorIs(t, err, domain.ErrVoyageCodeConflict)
+	assert.Contains(t, err.Error(), "SPEC-20260508-8001")
+
+	var seriesCount, voyageCount int64
+	db.Model(&domain.VoyageSeries{}).Count(&seriesCount)
+	db.Model(&domain.Voyage{}).Count(&voyageCount)
+	assert.Zero(t, seriesCount)
+	assert.Zero(t, voyageCount)
+
+	taken, err := repo.FindExistingSKUCodes(ctx, []string{"SPEC-20260508-8001", "SPEC-20260508-8002"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SPEC-20260508-8001"}, taken)
+	taken, err = repo.FindExistingVoyageCodes(ctx, []string{"SPEC-20260501"})
+	require.NoError(t, err)
+	assert.Empty(t, taken)
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// waitlistActiveStatuses 为仍在排队或等待下单的候补状态。
+var waitlistActiveStatuses = []string{domain.WaitlistStatusWaiting, domain.WaitlistStatusOffered}
+
+// WaitlistRepository 提供售罄候补的数据访问实现。
+type WaitlistRepository struct {
+	db *gorm.DB
+}
+
+var _ domain.WaitlistRepository = (*WaitlistRepository)(nil)
+
+func NewWaitlistRepository(db *gorm.DB) *WaitlistRepository {
+	return &WaitlistRepository{db: db}
+}
+
+// CreateEntry 登记候补；用户在同一航次舱型上已有有效记录时返回 ErrWaitlistEntryActive。
+func (r *WaitlistRepository) CreateEntry(ctx context.Context, entry *domain.WaitlistEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&domain.WaitlistEntry{}).
+			Where("user_id = ? AND voyage_id = ? AND cabin_type_id = ? AND status IN ?", entry.UserID, entry.VoyageID, entry.CabinTypeID, waitlistActiveStatuses).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return domain.ErrWaitlistEntryActive
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+func (r *WaitlistRepository) GetEntry(ctx context.Context, id int64) (*domain.WaitlistEntry, error) {
+	var item domain.WaitlistEntry
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *WaitlistRepository) ListUserEntries(ctx context.Context, userID int64) ([]domain.WaitlistEntry, error) {
+	var items []domain.WaitlistEntry
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *WaitlistRepository) ListEntries(ctx context.Context, filter domain.WaitlistFilter, page, pageSize int) ([]domain.WaitlistEntry, int64, error) {
+	var items []domain.WaitlistEntry
+	var total int64
+	q := r.db.WithContext(ctx).Model(&domain.WaitlistEntry{}).Scopes(scopeByVoyage(ctx, "voyage_id"))
+	if filter.VoyageID > 0 {
+		q = q.Where("voyage_id = ?", filter.VoyageID)
+	}
+	if filter.CabinTypeID > 0 {
+		q = q.Where("cabin_type_id = ?", filter.CabinTypeID)
+	}
+	if filter.UserID > 0 {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := q.Order("id asc").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// CancelEntry 取消用户自己的候补记录；邀约中的记录同时删除独占占座并退回库存。
+// 记录不存在或不属于该用户时返回 gorm.ErrRecordNotFound，已结束时返回 ErrWaitlistEntryClosed。
+func (r *WaitlistRepository) CancelEntry(ctx context.Context, userID, id int64) (*domain.WaitlistEntry, error) {
+	var entry domain.WaitlistEntry
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND user_id = ?", id, userID).First(&entry).Error; err != nil {
+			return err
+		}
+		if !entry.Active() {
+			return domain.ErrWaitlistEntryClosed
+		}
+		if entry.Status == domain.WaitlistStatusOffered {
+			if err := releaseOfferHoldTx(tx, &entry); err != nil {
+				return err
+			}
+		}
+		entry.Status = domain.WaitlistStatusCancelled
+		return tx.Model(&domain.WaitlistEntry{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"status": entry.Status, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *WaitlistRepository) ListExpiredOfferIDs(ctx context.Context, at time.Time) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := r.db.WithContext(ctx).Model(&domain.WaitlistEntry{}).
+		Where("status = ? AND offer_expires_at <= ?", domain.WaitlistStatusOffered, at).
+		Order("id asc").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ExpireOffer 结束已过独占期的邀约：若用户在独占期内已下单则补记为已下单，否则删除占座并退回库存。
+// 邀约已被处理时返回 nil。
+func (r *WaitlistRepository) ExpireOffer(ctx context.Context, id int64, at time.Time) (*domain.WaitlistEntry, error) {
+	var entry domain.WaitlistEntry
+	handled := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, id).Error; err != nil {
+			return err
+		}
+		if entry.Status != domain.WaitlistStatusOffered || entry.OfferExpiresAt == nil || entry.OfferExpiresAt.After(at) {
+			return nil
+		}
+		handled = true
+
+		var booking domain.Booking
+		err := tx.Where("user_id = ? AND cabin_sku_id = ? AND created_at >= ? AND created_at < ?",
+			entry.UserID, entry.OfferedSKUID, entry.OfferedAt, entry.OfferExpiresAt).
+			Order("id asc").First(&booking).Error
+		switch {
+		case err == nil:
+			entry.Status = domain.WaitlistStatusBooked
+			entry.BookingID = booking.ID
+		case err == gorm.ErrRecordNotFound:
+			if err := releaseOfferHoldTx(tx, &entry); err != nil {
+				return err
+			}
+			entry.Status = domain.WaitlistStatusExpired
+		default:
+			return err
+		}
+		return tx.Model(&domain.WaitlistEntry{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"status": entry.Status, "booking_id": entry.BookingID, "updated_at": at}).Error
+	})
+	if err != nil || !handled {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *WaitlistRepository) ListWaitingTargets(ctx context.Context) ([]domain.WaitlistTarget, error) {
+	var items []domain.WaitlistTarget
+	err := r.db.WithContext(ctx).Model(&domain.WaitlistEntry{}).
+		Select("DISTINCT voyage_id, cabin_type_id").
+		Where("status = ?", domain.WaitlistStatusWaiting).
+		Order("voyage_id asc, cabin_type_id asc").
+		Scan(&items).Error
+	return items, err
+}
+
+// InTx 在同一事务内执行候补邀约流程。
+func (r *WaitlistRepository) InTx(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
+// LockInventoryTx 锁定 SKU 库存行，供发出邀约前判断可售余量。
+func (r *WaitlistRepository) LockInventoryTx(tx *gorm.DB, skuID int64) (*domain.CabinInventory, error) {
+	var inv domain.CabinInventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("cabin_sku_id = ?", skuID).First(&inv).Error; err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// NextWaitingTx 按登记顺序取出航次舱型下最早的排队记录；maxGuests 大于 0 时跳过人数超出舱房容量的记录。
+// 已持有该 SKU 有效占座的用户不参与本次邀约。没有可邀约记录时返回 nil。
+func (r *WaitlistRepository) NextWaitingTx(tx *gorm.DB, voyageID, cabinTypeID, skuID int64, maxGuests int, at time.Time) (*domain.WaitlistEntry, error) {
+	q := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("voyage_id = ? AND cabin_type_id = ? AND status = ?", voyageID, cabinTypeID, domain.WaitlistStatusWaiting).
+		Where("user_id NOT IN (?)", tx.Model(&domain.CabinHold{}).Select("user_id").Where("cabin_sku_id = ? AND expires_at > ?", skuID, at))
+	if maxGuests > 0 {
+		q = q.Where("guests <= ?", maxGuests)
+	}
+	var entry domain.WaitlistEntry
+	err := q.Order("created_at asc, id asc").First(&entry).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CreateOfferTx 为候补记录独占占座一间舱房：扣减库存、创建占座并把记录置为邀约中。
+func (r *WaitlistRepository) CreateOfferTx(tx *gorm.DB, entry *domain.WaitlistEntry, skuID int64, at, expiresAt time.Time) error {
+	if err := adjustInventoryTx(tx, skuID, -1, "waitlist_offer"); err != nil {
+		return err
+	}
+	hold := &domain.CabinHold{CabinSKUID: skuID, UserID: entry.UserID, Qty: 1, ExpiresAt: expiresAt}
+	if err := tx.Create(hold).Error; err != nil {
+		return err
+	}
+	result := tx.Model(&domain.WaitlistEntry{}).
+		Where("id = ? AND status = ?", entry.ID, domain.WaitlistStatusWaiting).
+		Updates(map[string]interface{}{
+			"status":           domain.WaitlistStatusOffered,
+			"offered_sku_id":   skuID,
+			"offer_hold_id":    hold.ID,
+			"offered_at":       at,
+			"offer_expires_at": expiresAt,
+			"updated_at":       at,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrWaitlistEntryClosed
+	}
+	entry.Status = domain.WaitlistStatusOffered
+	entry.OfferedSKUID = skuID
+	entry.OfferHoldID = hold.ID
+	entry.OfferedAt = &at
+	entry.OfferExpiresAt = &expiresAt
+	return nil
+}
+
+// CreateNotificationTx 在邀约事务内写入发件箱通知。
+func (r *WaitlistRepository) CreateNotificationTx(tx *gorm.DB, n *domain.Notification) error {
+	return tx.Create(n).Error
+}
+
+// FulfillOfferTx 在下单事务内把用户对该 SKU 仍在独占期内的邀约标记为已下单。
+func (r *WaitlistRepository) FulfillOfferTx(tx *gorm.DB, userID, skuID, bookingID int64, at time.Time) error {
+	return tx.Model(&domain.WaitlistEntry{}).
+		Where("user_id = ? AND offered_sku_id = ? AND status = ? AND offer_expires_at > ?", userID, skuID, domain.WaitlistStatusOffered, at).
+		Updates(map[string]interface{}{"status": domain.WaitlistStatusBooked, "booking_id": bookingID, "updated_at": at}).Error
+}
+
+// releaseOfferHoldTx 删除邀约的独占占座并退回一间库存。
+// 占座可能已被同用户后续占座时清理，但库存仍处于扣减状态，因此无论删除行数均退回。
+func releaseOfferHoldTx(tx *gorm.DB, entry *domain.WaitlistEntry) error {
+	if entry.OfferHoldID > 0 {
+		if err := tx.Delete(&domain.CabinHold{}, entry.OfferHoldID).Error; err != nil {
+			return err
+		}
+	}
+	return adjustInventoryTx(tx, entry.OfferedSKUID, 1, "waitlist_offer_release")
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newWaitlistTestRepo 创建 SQLite 内存库并返回候补仓储实例，SKU 1 剩余 1 间库存。
+func newWaitlistTestRepo(t *testing.T) *WaitlistRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&domain.WaitlistEntry{},
+		&domain.Notification{},
+		&domain.CabinSKU{},
+		&domain.CabinInventory{},
+		&domain.InventoryLog{},
+		&domain.CabinHold{},
+		&domain.Booking{},
+	))
+	require.NoError(t, db.Create(&domain.CabinSKU{ID: 1, VoyageID: 10, CabinTypeID: 100, Code: "A1", MaxGuests: 2}).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: 1, Total: 1}).Error)
+	return NewWaitlistRepository(db)
+}
+
+func createWaitlistEntry(t *testing.T, repo *WaitlistRepository, userID int64, guests int) *domain.WaitlistEntry {
+	t.Helper()
+	entry := &domain.WaitlistEntry{UserID: userID, VoyageID: 10, CabinTypeID: 100, Guests: guests, Status: domain.WaitlistStatusWaiting}
+	require.NoError(t, repo.CreateEntry(context.Background(), entry))
+	return entry
+}
+
+// offerTo 在事务内为下一位可邀约的候补创建独占占座。
+func offerTo(t *testing.T, repo *WaitlistRepository, at time.Time) *domain.WaitlistEntry {
+	t.Helper()
+	var offered *domain.WaitlistEntry
+	require.NoError(t, repo.InTx(context.Background(), func(tx *gorm.DB) error {
+		entry, err := repo.NextWaitingTx(tx, 10, 100, 1, 2, at)
+		if err != nil || entry == nil {
+			return err
+		}
+		offered = entry
+		return repo.CreateOfferTx(tx, entry, 1, at, at.Add(30*time.Minute))
+	}))
+	return offered
+}
+
+func TestWaitlistRepository_CreateEntryRejectsDuplicate(t *testing.T) {
+	repo := newWaitlistTestRepo(t)
+	ctx := context.Background()
+
+	entry := createWaitlistEntry(t, repo, 1, 2)
+	dup := &domain.WaitlistEntry{UserID: 1, VoyageID: 10, CabinTypeID: 100, Guests: 1, Status: domain.WaitlistStatusWaiting}
+	assert.True(t, errors.Is(repo.CreateEntry(ctx, dup), domain.ErrWaitlistEntryActive))
+	createWaitlistEntry(t, repo, 2, 1)
+
+	targets, err := repo.ListWaitingTargets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.WaitlistTarget{{VoyageID: 10, CabinTypeID: 100}}, targets)
+
+	items, total, err := repo.ListEntries(ctx, domain.WaitlistFilter{UserID: 1}, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, entry.ID, items[0].ID)
+}
+
+func TestWaitlistRepository_OfferSkipsOversizedAndExpires(t *testing.T) {
+	repo := newWaitlistTestRepo(t)
+	ctx := context.Background()
+	db := repo.db
+	now := time.Now().UTC()
+
+	createWaitlistEntry(t, repo, 3, 4)
+	first := createWaitlistEntry(t, repo, 1, 2)
+
+	offered := offerTo(t, repo, now)
+	require.NotNil(t, offered)
+	assert.Equal(t, first.ID, offered.ID, "人数超过 max_guests 的候补被跳过")
+	assert.NotZero(t, offered.OfferHoldID)
+	var inv domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 0, inv.Total)
+
+	assert.Nil(t, offerTo(t, repo, now), "没有符合条件的排队者")
+
+	ids, err := repo.ListExpiredOfferIDs(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+	got, err := repo.ExpireOffer(ctx, first.ID, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Nil(t, got, "独占期未过不处理")
+
+	later := now.Add(time.Hour)
+	ids, err = repo.ListExpiredOfferIDs(ctx, later)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{first.ID}, ids)
+	got, err = repo.ExpireOffer(ctx, first.ID, later)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, domain.WaitlistStatusExpired, got.Status)
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 1, inv.Total, "过期后退回库存")
+	var holds int64
+	require.NoError(t, db.Model(&domain.CabinHold{}).Count(&holds).Error)
+	assert.Zero(t, holds)
+
+	got, err = repo.ExpireOffer(ctx, first.ID, later)
+	require.NoError(t, err)
+	assert.Nil(t, got, "重复处理不再退回库存")
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 1, inv.Total)
+}
+
+func TestWaitlistRepository_ExpireOfferRecordsBookingWithinWindow(t *testing.T) {
+	repo := newWaitlistTestRepo(t)
+	ctx := context.Background()
+	db := repo.db
+	now := time.Now().UTC().Add(-time.Minute)
+
+	entry := createWaitlistEntry(t, repo, 1, 2)
+	require.NotNil(t, offerTo(t, repo, now))
+	booking := &domain.Booking{UserID: 1, VoyageID: 10, CabinSKUID: 1, Status: domain.OrderStatusCreated}
+	require.NoError(t, db.Create(booking).Error)
+
+	got, err := repo.ExpireOffer(ctx, entry.ID, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, domain.WaitlistStatusBooked, got.Status, "独占期内已下单则补记为已下单")
+	assert.Equal(t, booking.ID, got.BookingID)
+	var inv domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 0, inv.Total, "已下单的占座不退回")
+}
+
+func TestWaitlistRepository_CancelAndFulfill(t *testing.T) {
+	repo := newWaitlistTestRepo(t)
+	ctx := context.Background()
+	db := repo.db
+	now := time.Now().UTC()
+
+	first := createWaitlistEntry(t, repo, 1, 2)
+	second := createWaitlistEntry(t, repo, 2, 2)
+	require.NotNil(t, offerTo(t, repo, now))
+
+	_, err := repo.CancelEntry(ctx, 2, first.ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound), "不能取消他人的候补")
+	cancelled, err := repo.CancelEntry(ctx, 1, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WaitlistStatusCancelled, cancelled.Status)
+	assert.Equal(t, int64(1), cancelled.OfferedSKUID)
+	var inv domain.CabinInventory
+	require.NoError(t, db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	assert.Equal(t, 1, inv.Total, "取消邀约退回库存")
+	_, err = repo.CancelEntry(ctx, 1, first.ID)
+	assert.True(t, errors.Is(err, domain.ErrWaitlistEntryClosed))
+
+	require.NotNil(t, offerTo(t, repo, now))
+	require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return repo.FulfillOfferTx(tx, 2, 1, 99, now.Add(time.Minute))
+	}))
+	got, err := repo.GetEntry(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WaitlistStatusBooked, got.Status)
+	assert.Equal(t, int64(99), got.BookingID)
+}
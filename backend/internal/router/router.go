@@ -1,6 +1,7 @@
 package router
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/casbin/casbin/v2"
@@ -10,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
 )
 
 // Dependencies 聚合了所有处理器依赖，用于路由初始化时的依赖注入。
@@ -19,6 +21,10 @@ type Dependencies struct {
 	Cruise            *handler.CruiseHandler               // 邮轮处理器
 	CabinType         *handler.CabinTypeHandler            // 舱房类型处理器
 	CabinPricing      *handler.CabinPricingHandler         // 舱型价格管理处理器
+	DynamicPricing    *handler.DynamicPricingHandler       // 动态调价处理器
+	Agency            *handler.AgencyHandler               // 分销商管理处理器
+	AgencyPortal      *handler.AgencyPortalHandler         // 分销端处理器
+	Waitlist          *handler.WaitlistHandler             // 售罄候补处理器
 	CabinTypeCategory *handler.CabinTypeCategoryHandler    // 舱型大类处理器
 	CabinTypeMedia    *handler.CabinTypeMediaHandler       // 舱型媒体处理器
 	FacilityCategory  *handler.FacilityCategoryHandler     // 设施分类处理器
@@ -38,18 +44,42 @@ type Dependencies struct {
 	NotificationTpl   *handler.NotificationTemplateHandler // 通知模板处理器
 	ContentTemplate   *handler.ContentTemplateHandler      // 文案模板处理器
 	CustomDestination *handler.CustomDestinationHandler    // 自定义目的地处理器
+	Port              *handler.PortHandler                 // 港口主数据处理器
+	VoyageSeries      *handler.VoyageSeriesHandler         // 航次系列批量生成处理器
+	VoyageClone       *handler.VoyageCloneHandler          // 航次克隆处理器
+	VoyageDisruption  *handler.VoyageDisruptionHandler     // 航次停航/变更处理器
+	CabinImport       *handler.CabinImportHandler          // 舱房批量导入导出处理器
+	ImportJob         *handler.ImportJobHandler            // 异步导入任务处理器
+	DeckPlan          *handler.DeckPlanHandler             // 甲板平面图与舱位图处理器
+	OperationLog      *handler.OperationLogHandler         // 操作审计日志处理器
 	JWTSecret         string                               // JWT 签名密钥
+	AgencyJWTSecret   string                               // 分销端 JWT 签名密钥（与后台、C 端区分）
+	AgencyAPIKeys     middleware.AgencyKeyResolver         // 分销商 API Key 校验器
+	AgencyStatus      middleware.AgencyStatusChecker       // 分销商账户状态复核，停用后已签发的令牌立即失效
 	Enforcer          *casbin.Enforcer                     // Casbin RBAC 执行器
+	AuditRecorder     middleware.AuditRecorder             // 后台写操作审计记录器，为 nil 时不记录
+	CompanyScope      middleware.CompanyScopeResolver      // 员工公司数据范围查询，为 nil 时不限制
+	RateLimiter       *middleware.RateLimiter              // 接口限流器，为 nil 时不限流
+	Idempotency       middleware.IdempotencyConfig         // 下单、退款接口的 Idempotency-Key 幂等配置，Store 为 nil 时不启用
+	Logger            *zap.Logger                          // 请求日志记录器，为 nil 时使用 zap.L()
+	TrustedProxies    []string                             // 可信反向代理的 IP 或 CIDR，为空时客户端 IP 取 TCP 连接地址
+	Metrics           http.Handler                         // Prometheus 指标处理器，为 nil 时不注册 /metrics
 }
 
 // Setup 创建并配置 Gin 引擎，注册所有路由和中间件。
 // CR-04 修复：管理后台路由受 JWT + RBAC 中间件保护；所有处理器均通过依赖注入传入。
 func Setup(deps Dependencies) *gin.Engine {
 	r := gin.New()
+	// 只采信可信代理转发的 X-Forwarded-For，否则客户端可伪造该头为每个请求换取新的按 IP 限流令牌桶
+	if err := r.SetTrustedProxies(deps.TrustedProxies); err != nil {
+		zap.L().Error("router: invalid trusted proxies, using remote address", zap.Strings("trusted_proxies", deps.TrustedProxies), zap.Error(err))
+		_ = r.SetTrustedProxies(nil)
+	}
 
-	// 全局中间件：崩溃恢复 + 请求日志
+	// 全局中间件：请求日志与链路追踪（最外层，才能记录到崩溃恢复写出的 500）+ 崩溃恢复
+	r.Use(middleware.RequestLogger(deps.Logger))
+	r.Use(middleware.Metrics())
 	r.Use(gin.Recovery())
-	r.Use(gin.Logger())
 	r.Use(cors.New(cors.Config{
 		AllowOrigins: []string{
 			"http://localhost:3000",
@@ -71,14 +101,26 @@ func Setup(deps Dependencies) *gin.Engine {
 			"http://127.0.0.1:8080",
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Authorization", "Content-Type", middleware.HeaderIdempotencyKey, middleware.HeaderRequestID, "traceparent", "tracestate", "baggage"},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", middleware.HeaderIdempotentReplayed, middleware.HeaderRequestID},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	// 限流：全局处理按 IP 的规则，认证中间件之后再挂一次以处理按用户的规则（同一请求只计数一次）
+	rateLimit := middleware.RateLimit(deps.RateLimiter)
+	r.Use(rateLimit)
+	// 幂等：下单与退款接口携带 Idempotency-Key 时，重试直接重放首次响应。
+	// 本服务目前没有发起支付的接口，唯一的支付写入口 /pay/callback 由支付平台调用、不携带该请求头，
+	// 按 trade_no 在 PaymentService.HandleCallback 中去重，因此不挂幂等中间件；新增支付发起接口时需在此挂上 idempotent。
+	idempotent := middleware.Idempotency(deps.Idempotency)
 
 	// Swagger API 文档界面（无需认证）
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	// Prometheus 指标（不走用户认证；未配置令牌时仅在 metrics.public 显式开启后注册，此时任何人可读取）
+	if deps.Metrics != nil {
+		r.GET("/metrics", gin.WrapH(deps.Metrics))
+	}
 	if deps.Upload != nil {
 		r.Static(deps.Upload.PublicBasePath(), deps.Upload.StorageDir())
 	}
@@ -88,18 +130,29 @@ func Setup(deps Dependencies) *gin.Engine {
 	// --- 公开路由（无需认证） ---
 	auth := api.Group("/admin/auth")
 	{
-		auth.POST("/login", deps.Auth.Login) // 管理员登录
+		auth.POST("/login", deps.Auth.Login)                  // 管理员登录（含二次验证与过期改密）
+		auth.POST("/password/reset", deps.Auth.ResetPassword) // 使用一次性令牌重置密码
+		auth.POST("/totp/enroll", deps.Auth.EnrollTOTP)       // 凭账号密码开始绑定 TOTP
 	}
 
 	// --- 受保护的管理后台路由（需要 JWT + RBAC 认证） ---
 	admin := api.Group("/admin")
-	admin.Use(middleware.JWT(middleware.JWTConfig{Secret: deps.JWTSecret}))
+	admin.Use(middleware.JWT(middleware.JWTConfig{Secret: deps.JWTSecret}), rateLimit)
 	if deps.Enforcer != nil {
 		admin.Use(middleware.RBAC(deps.Enforcer))
 	}
+	if deps.CompanyScope != nil {
+		admin.Use(middleware.CompanyScope(deps.CompanyScope))
+	}
+	if deps.AuditRecorder != nil {
+		admin.Use(middleware.Audit(deps.AuditRecorder))
+	}
 
 	// 获取当前员工信息（已认证，任意角色）
 	admin.GET("/auth/profile", deps.Auth.GetProfile)
+	admin.PUT("/auth/password", deps.Auth.ChangePassword)     // 修改本人密码
+	admin.POST("/auth/totp/activate", deps.Auth.ActivateTOTP) // 启用本人二次验证
+	admin.DELETE("/auth/totp", deps.Auth.DisableTOTP)         // 关闭本人二次验证（强制角色不可关闭）
 
 	// 邮轮公司管理
 	companies := admin.Group("/companies")
@@ -155,9 +208,57 @@ func Setup(deps Dependencies) *gin.Engine {
 			pricing.GET("/voyages", deps.CabinPricing.ListVoyages)
 			pricing.POST("/batch-apply", deps.CabinPricing.BatchApply)
 			pricing.GET("/history", deps.CabinPricing.History)
+			pricing.GET("/pending-versions", deps.CabinPricing.PendingVersions)
+			pricing.POST("/versions/:id/cancel", deps.CabinPricing.CancelVersion)
+			pricing.POST("/versions/:id/rollback", deps.CabinPricing.Rollback)
+		}
+	}
+
+	if deps.DynamicPricing != nil {
+		dynamicPricing := admin.Group("/dynamic-pricing")
+		{
+			dynamicPricing.GET("/rules", deps.DynamicPricing.ListRules)
+			dynamicPricing.POST("/rules", deps.DynamicPricing.CreateRule)
+			dynamicPricing.PUT("/rules/:id", deps.DynamicPricing.UpdateRule)
+			dynamicPricing.DELETE("/rules/:id", deps.DynamicPricing.DeleteRule)
+			dynamicPricing.POST("/simulate", deps.DynamicPricing.Simulate)
+			dynamicPricing.GET("/proposals", deps.DynamicPricing.ListProposals)
+			dynamicPricing.POST("/proposals", deps.DynamicPricing.CreateProposal)
+			dynamicPricing.GET("/proposals/:id", deps.DynamicPricing.GetProposal)
+			dynamicPricing.POST("/proposals/:id/approve", deps.DynamicPricing.ApproveProposal)
+			dynamicPricing.POST("/proposals/:id/reject", deps.DynamicPricing.RejectProposal)
 		}
 	}
 
+	if deps.Agency != nil {
+		agencies := admin.Group("/agencies")
+		{
+			agencies.GET("", deps.Agency.List)
+			agencies.POST("", deps.Agency.Create)
+			agencies.GET("/:id", deps.Agency.Get)
+			agencies.PUT("/:id", deps.Agency.Update)
+			agencies.PUT("/:id/password", deps.Agency.ResetPassword)
+			agencies.GET("/:id/api-keys", deps.Agency.ListAPIKeys)
+			agencies.POST("/:id/api-keys", deps.Agency.CreateAPIKey)
+			agencies.DELETE("/:id/api-keys/:keyId", deps.Agency.RevokeAPIKey)
+			agencies.GET("/:id/allotments", deps.Agency.ListAllotments)
+			agencies.POST("/:id/allotments", deps.Agency.CreateAllotment)
+			agencies.POST("/:id/allotments/:allotmentId/release", deps.Agency.ReleaseAllotment)
+			agencies.GET("/:id/net-rates", deps.Agency.ListNetRates)
+			agencies.POST("/:id/net-rates", deps.Agency.UpsertNetRate)
+			agencies.DELETE("/:id/net-rates/:rateId", deps.Agency.DeleteNetRate)
+			agencies.GET("/:id/credit", deps.Agency.Credit)
+			agencies.GET("/:id/bookings", deps.Agency.ListBookings)
+			agencies.GET("/:id/statements", deps.Agency.ListStatements)
+			agencies.POST("/:id/statements", deps.Agency.IssueStatement)
+			agencies.POST("/:id/statements/:statementId/paid", deps.Agency.MarkStatementPaid)
+		}
+	}
+
+	if deps.Waitlist != nil {
+		admin.GET("/waitlist", deps.Waitlist.AdminList) // 查询售罄候补记录
+	}
+
 	// 设施分类管理
 	facilityCategories := admin.Group("/facility-categories")
 	{
@@ -210,7 +311,7 @@ func Setup(deps Dependencies) *gin.Engine {
 		{
 			customDest.GET("", deps.CustomDestination.List)
 			customDest.GET("/export", deps.CustomDestination.ExportCSV)
-			customDest.POST("/import", deps.CustomDestination.ImportCSV)
+			customDest.POST("/import", deps.CustomDestination.ImportCSV) // 支持 dry_run、mode、async、report=csv
 			customDest.GET("/:id", deps.CustomDestination.Get)
 			customDest.POST("", deps.CustomDestination.Create)
 			customDest.PUT("/:id", deps.CustomDestination.Update)
@@ -218,6 +319,53 @@ func Setup(deps Dependencies) *gin.Engine {
 		}
 	}
 
+	// 异步批量导入任务：查询状态与下载失败行错误报告
+	if deps.ImportJob != nil {
+		importJobs := admin.Group("/import-jobs")
+		{
+			importJobs.GET("", deps.ImportJob.List)
+			importJobs.GET("/:id", deps.ImportJob.Get)
+			importJobs.GET("/:id/errors", deps.ImportJob.Errors)
+		}
+	}
+
+	if deps.Port != nil {
+		ports := admin.Group("/ports")
+		{
+			ports.GET("", deps.Port.List)
+			ports.GET("/export", deps.Port.ExportCSV)
+			ports.POST("/import", deps.Port.ImportCSV)
+			ports.GET("/:id", deps.Port.Get)
+			ports.POST("", deps.Port.Create)
+			ports.PUT("/:id", deps.Port.Update)
+			ports.DELETE("/:id", deps.Port.Delete)
+		}
+	}
+
+	// 甲板平面图：维护甲板图片与舱位坐标，供前端渲染可点选的舱位图
+	if deps.DeckPlan != nil {
+		deckPlans := admin.Group("/deck-plans")
+		{
+			deckPlans.GET("", deps.DeckPlan.List)
+			deckPlans.GET("/:id", deps.DeckPlan.Get)
+			deckPlans.POST("", deps.DeckPlan.Create)
+			deckPlans.PUT("/:id", deps.DeckPlan.Update)
+			deckPlans.DELETE("/:id", deps.DeckPlan.Delete)
+			deckPlans.PUT("/:id/cabins", deps.DeckPlan.ReplaceCabins)
+		}
+	}
+
+	// 航次系列：按模板航次与重复规则预览并批量生成航次
+	if deps.VoyageSeries != nil {
+		series := admin.Group("/voyage-series")
+		{
+			series.GET("", deps.VoyageSeries.List)
+			series.POST("/preview", deps.VoyageSeries.Preview)
+			series.POST("", deps.VoyageSeries.Create)
+			series.GET("/:id", deps.VoyageSeries.Get)
+		}
+	}
+
 	// 航次、舱房管理（Sprint 2）—— 完整 CRUD
 	voyages := admin.Group("/voyages")
 	{
@@ -227,6 +375,27 @@ func Setup(deps Dependencies) *gin.Engine {
 		voyages.PUT("/:id", deps.Voyage.Update)    // 更新航次
 		voyages.DELETE("/:id", deps.Voyage.Delete) // 删除航次
 	}
+	if deps.VoyageClone != nil {
+		voyages.POST("/:id/clone", deps.VoyageClone.Clone) // 克隆航次（含舱位、库存与价格）
+	}
+	if deps.CabinImport != nil {
+		voyages.POST("/:id/cabins/import", deps.CabinImport.Import) // 批量导入舱房与库存（CSV/XLSX，支持 dry_run）
+		voyages.GET("/:id/cabins/export", deps.CabinImport.Export)  // 导出舱房与库存（CSV/XLSX）
+	}
+
+	// 航次取消/行程变更：快照受影响订单，批量发出改签/全额退款/抵用金方案并逐单跟踪处理结果
+	if deps.VoyageDisruption != nil {
+		voyages.POST("/:id/disruptions", deps.VoyageDisruption.Open)
+		disruptions := admin.Group("/disruptions")
+		{
+			disruptions.GET("", deps.VoyageDisruption.List)
+			disruptions.GET("/:id", deps.VoyageDisruption.Get)
+			disruptions.GET("/:id/bookings", deps.VoyageDisruption.Bookings)
+			disruptions.POST("/:id/offers", deps.VoyageDisruption.Offer)
+			disruptions.POST("/:id/resolve", deps.VoyageDisruption.Resolve)
+			disruptions.POST("/:id/close", deps.VoyageDisruption.Close)
+		}
+	}
 
 	cabins := admin.Group("/cabins")
 	{
@@ -247,11 +416,11 @@ func Setup(deps Dependencies) *gin.Engine {
 
 	bookingsAdmin := admin.Group("/bookings")
 	{
-		bookingsAdmin.GET("", deps.Booking.AdminList)          // 管理后台查询订单列表
-		bookingsAdmin.GET("/export", deps.Booking.AdminExport) // 管理后台导出订单 CSV
-		bookingsAdmin.GET("/:id", deps.Booking.AdminGet)       // 管理后台查询订单详情
-		bookingsAdmin.POST("", deps.Booking.Create)            // 管理后台创建订单
-		bookingsAdmin.PUT("/:id", deps.Booking.AdminUpdate)    // 管理后台更新订单状态
+		bookingsAdmin.GET("", deps.Booking.AdminList)           // 管理后台查询订单列表
+		bookingsAdmin.GET("/export", deps.Booking.AdminExport)  // 管理后台导出订单 CSV
+		bookingsAdmin.GET("/:id", deps.Booking.AdminGet)        // 管理后台查询订单详情
+		bookingsAdmin.POST("", idempotent, deps.Booking.Create) // 管理后台创建订单
+		bookingsAdmin.PUT("/:id", deps.Booking.AdminUpdate)     // 管理后台更新订单状态
 		bookingsAdmin.DELETE("/:id", deps.Booking.AdminDelete)
 	}
 
@@ -265,14 +434,56 @@ func Setup(deps Dependencies) *gin.Engine {
 	{
 		users.POST("/login", deps.User.Login)
 		users.POST("/sms-code", deps.User.SendCode)
-		users.Use(cUserJWT)
+		users.POST("/wx-login", deps.User.WechatLogin)
+		users.Use(cUserJWT, rateLimit)
 		users.GET("/profile", deps.User.Profile)
+		users.POST("/wx-phone", deps.User.BindWechatPhone)
+		users.PUT("/profile", deps.User.UpdateProfile)
+		users.PUT("/phone", deps.User.ChangePhone)
+		users.POST("/deletion", deps.User.RequestDeletion)
+		users.DELETE("/deletion", deps.User.CancelDeletion)
+		users.GET("/export", deps.User.ExportData)
 	}
 
 	bookings := api.Group("/bookings")
 	{
-		bookings.Use(cUserJWT)
-		bookings.POST("", deps.Booking.Create)
+		bookings.Use(cUserJWT, rateLimit)
+		bookings.POST("", idempotent, deps.Booking.Create)
+	}
+
+	// --- 售罄候补（需要用户认证） ---
+	if deps.Waitlist != nil {
+		waitlist := api.Group("/waitlist")
+		{
+			waitlist.Use(cUserJWT, rateLimit)
+			waitlist.POST("", deps.Waitlist.Join)
+			waitlist.GET("", deps.Waitlist.Mine)
+			waitlist.DELETE("/:id", deps.Waitlist.Cancel)
+		}
+	}
+
+	// --- 停航/变更替代方案（需要用户认证） ---
+	if deps.VoyageDisruption != nil {
+		disruptions := api.Group("/disruptions")
+		{
+			disruptions.Use(cUserJWT, rateLimit)
+			disruptions.GET("", deps.VoyageDisruption.Mine)
+			disruptions.POST("/:id/accept", deps.VoyageDisruption.Accept)
+		}
+	}
+
+	// --- B2B 分销端（旅行社账户登录或 X-API-Key 对接） ---
+	if deps.AgencyPortal != nil {
+		agencyPortal := api.Group("/agency")
+		{
+			agencyPortal.POST("/auth/login", deps.AgencyPortal.Login)
+			agencyPortal.Use(middleware.AgencyAuth(middleware.AgencyAuthConfig{Secret: deps.AgencyJWTSecret, APIKeys: deps.AgencyAPIKeys, Agencies: deps.AgencyStatus}), rateLimit)
+			agencyPortal.GET("/profile", deps.AgencyPortal.Profile)
+			agencyPortal.GET("/allotments", deps.AgencyPortal.Allotments)
+			agencyPortal.GET("/bookings", deps.AgencyPortal.Bookings)
+			agencyPortal.POST("/bookings", idempotent, deps.AgencyPortal.CreateBooking)
+			agencyPortal.GET("/statements", deps.AgencyPortal.Statements)
+		}
 	}
 
 	// --- 支付回调（公开路由，由支付平台调用） ---
@@ -287,16 +498,21 @@ func Setup(deps Dependencies) *gin.Engine {
 	api.GET("/cabin-types", deps.CabinType.List)                // 舱房类型列表
 	api.GET("/facility-categories", deps.FacilityCategory.List) // 设施分类列表
 	api.GET("/facilities", deps.Facility.ListByCruise)          // 设施列表（按邮轮）
+	if deps.DeckPlan != nil {
+		api.GET("/voyages/:id/deck-map", deps.DeckPlan.DeckMap) // 航次舱位图（按甲板选择舱房）
+	}
 
 	// --- 退款（需要用户认证） ---
 	refunds := api.Group("/refunds")
 	{
-		refunds.Use(cUserJWT)
-		refunds.POST("", deps.Refund.Create)
+		refunds.Use(cUserJWT, rateLimit)
+		refunds.POST("", idempotent, deps.Refund.Create)
 	}
 
 	// --- 管理后台统计分析 ---
 	admin.GET("/analytics/summary", deps.Analytics.Summary)
+	admin.GET("/analytics/query", deps.Analytics.Query)
+	admin.GET("/analytics/export", deps.Analytics.Export)
 
 	staffs := admin.Group("/staffs")
 	{
@@ -306,6 +522,20 @@ func Setup(deps Dependencies) *gin.Engine {
 		staffs.PUT("/:id", deps.Staff.Update)
 		staffs.DELETE("/:id", deps.Staff.Delete)
 		staffs.PUT("/:id/assign-role", deps.Staff.AssignRole)
+		staffs.POST("/:id/reset-password", deps.Staff.ResetPassword) // 签发一次性密码重置令牌
+		staffs.DELETE("/:id/totp", deps.Staff.ResetTOTP)             // 清除员工二次验证绑定
+		staffs.GET("/:id/companies", deps.Staff.GetCompanies)        // 查询员工可访问的公司
+		staffs.PUT("/:id/companies", deps.Staff.SetCompanies)        // 设置员工的公司数据范围，空列表表示收回全部授权
+	}
+
+	// 操作审计日志：查询、导出与哈希链防篡改校验
+	if deps.OperationLog != nil {
+		operationLogs := admin.Group("/operation-logs")
+		{
+			operationLogs.GET("", deps.OperationLog.List)
+			operationLogs.GET("/export", deps.OperationLog.ExportCSV)
+			operationLogs.GET("/verify", deps.OperationLog.Verify)
+		}
 	}
 
 	admin.GET("/shop-info", deps.ShopInfo.Get)
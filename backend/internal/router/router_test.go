@@ -11,6 +11,8 @@ import (
 	"github.com/casbin/casbin/v2"
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/handler"
+	"github.com/cruisebooking/backend/internal/middleware"
+	"github.com/cruisebooking/backend/internal/repository"
 	"github.com/cruisebooking/backend/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -159,3 +161,94 @@ p, admin, /api/v1/admin/cabins/batch-status, PUT
 	r.ServeHTTP(w2, req2)
 	assert.Equal(t, http.StatusForbidden, w2.Code)
 }
+
+type routerAgencyPortalSvcStub struct{}
+
+func (s *routerAgencyPortalSvcStub) Login(context.Context, string, string) (string, time.Time, *domain.Agency, error) {
+	return "", time.Time{}, nil, service.ErrAgencyInvalidCredentials
+}
+func (s *routerAgencyPortalSvcStub) GetAgency(_ context.Context, id int64) (*domain.Agency, error) {
+	return &domain.Agency{ID: id}, nil
+}
+func (s *routerAgencyPortalSvcStub) Credit(context.Context, int64) (*service.AgencyCredit, error) {
+	return &service.AgencyCredit{}, nil
+}
+func (s *routerAgencyPortalSvcStub) ListAllotments(context.Context, int64, int64) ([]domain.AgencyAllotment, error) {
+	return nil, nil
+}
+func (s *routerAgencyPortalSvcStub) ListBookings(context.Context, int64, int, int) ([]domain.AgencyBookingView, int64, error) {
+	return nil, 0, nil
+}
+func (s *routerAgencyPortalSvcStub) ListStatements(context.Context, int64, int, int) ([]domain.AgencyStatement, int64, error) {
+	return nil, 0, nil
+}
+
+func TestSetup_AgencyTokensIsolatedFromAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deps := Dependencies{
+		JWTSecret:       "test-secret",
+		AgencyJWTSecret: "test-secret:agency",
+		Enforcer:        &casbin.Enforcer{},
+		Auth:            &handler.AuthHandler{},
+		Company:         &handler.CompanyHandler{},
+		Cruise:          &handler.CruiseHandler{},
+		CabinType:       &handler.CabinTypeHandler{},
+		Voyage:          handler.NewVoyageHandler(&routerVoyageSvcStub{}),
+		PortCity:        handler.NewPortCityHandler(&routerPortCitySvcStub{}),
+		ContentTemplate: handler.NewContentTemplateHandler(&routerContentTemplateSvcStub{}),
+		Agency:          handler.NewAgencyHandler(nil),
+		AgencyPortal:    handler.NewAgencyPortalHandler(&routerAgencyPortalSvcStub{}, nil),
+	}
+	r := Setup(deps)
+
+	sign := func(secret string, roles []interface{}) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "5", "roles": roles, "exp": time.Now().Add(time.Hour).Unix()})
+		signed, _ := token.SignedString([]byte(secret))
+		return signed
+	}
+	do := func(path, token string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	agencyToken := sign("test-secret:agency", []interface{}{"agency"})
+	assert.Equal(t, http.StatusOK, do("/api/v1/agency/profile", agencyToken))
+	assert.Equal(t, http.StatusUnauthorized, do("/api/v1/admin/agencies", agencyToken), "分销端令牌不能访问后台")
+	assert.Equal(t, http.StatusUnauthorized, do("/api/v1/agency/profile", sign("test-secret", []interface{}{"admin"})), "后台令牌不能访问分销端")
+}
+
+func TestSetup_RateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(trustedProxies []string) *gin.Engine {
+		return Setup(Dependencies{
+			Voyage:    handler.NewVoyageHandler(&routerVoyageSvcStub{}),
+			JWTSecret: "test-secret",
+			Enforcer:  &casbin.Enforcer{},
+			RateLimiter: middleware.NewRateLimiter(repository.NewMemoryRateLimitStore(), []middleware.RateLimitRule{
+				{Name: "api", Path: "/api/v1", Limit: domain.RateLimit{Requests: 1, Period: time.Minute, Burst: 1}},
+			}),
+			TrustedProxies: trustedProxies,
+		})
+	}
+	request := func(r *gin.Engine, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/voyages/101", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	direct := newRouter(nil)
+	assert.Equal(t, http.StatusOK, request(direct, "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request(direct, "198.51.100.2"), "伪造的 X-Forwarded-For 不能换取新的令牌桶")
+
+	proxied := newRouter([]string{"203.0.113.0/24"})
+	assert.Equal(t, http.StatusOK, request(proxied, "198.51.100.1"))
+	assert.Equal(t, http.StatusOK, request(proxied, "198.51.100.2"), "可信代理转发的客户端 IP 分别计数")
+	assert.Equal(t, http.StatusTooManyRequests, request(proxied, "198.51.100.1"))
+}
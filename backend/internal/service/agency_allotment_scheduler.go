@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// dueAllotmentReleaser 释放已到期的分销商配额，由 AgencyService 实现。
+type dueAllotmentReleaser interface {
+	ReleaseDueAllotments(ctx context.Context) (int, error)
+}
+
+// AgencyAllotmentScheduler 定期把到达释放日期的分销商配额未用部分退回公共库存。
+// RunOnce 返回本轮释放的配额数。
+type AgencyAllotmentScheduler struct {
+	*periodicJob
+}
+
+// NewAgencyAllotmentScheduler 创建配额释放调度器；interval 非正数时默认 1 分钟。
+func NewAgencyAllotmentScheduler(releaser dueAllotmentReleaser, interval time.Duration) *AgencyAllotmentScheduler {
+	return &AgencyAllotmentScheduler{periodicJob: newPeriodicJob("agency_allotment_scheduler: release due allotments", interval, releaser.ReleaseDueAllotments)}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAllotmentReleaser struct {
+	released int
+	err      error
+}
+
+func (r *stubAllotmentReleaser) ReleaseDueAllotments(_ context.Context) (int, error) {
+	return r.released, r.err
+}
+
+func TestAgencyAllotmentScheduler_RunOnce(t *testing.T) {
+	releaser := &stubAllotmentReleaser{released: 3}
+	scheduler := NewAgencyAllotmentScheduler(releaser, 0)
+	assert.Equal(t, time.Minute, scheduler.interval)
+	assert.Equal(t, 3, scheduler.RunOnce(context.Background()))
+
+	releaser.released, releaser.err = 1, errors.New("allotment 9: db down")
+	assert.Equal(t, 1, scheduler.RunOnce(context.Background()), "部分失败时仍返回已释放数量")
+}
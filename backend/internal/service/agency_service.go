@@ -0,0 +1,520 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	agencyTokenRole        = "agency" // 分销端令牌角色，与 middleware.AgencyRole 一致，不在后台 RBAC 策略中
+	agencyAPIKeyPrefix     = "ak_"    // API Key 固定前缀
+	agencyMinPasswordLen   = 8        // 分销端登录密码最小长度
+	agencyStatementPattern = "2006-01"
+)
+
+var (
+	// ErrInvalidAgencyRequest 表示分销商相关请求参数不合法。
+	ErrInvalidAgencyRequest = errors.New("invalid agency request")
+	// ErrAgencyNotFound 表示分销商或其名下的 API Key、配额、账单不存在。
+	ErrAgencyNotFound = errors.New("agency record not found")
+	// ErrAgencyLoginNameTaken 表示登录账户名已被其他分销商使用。
+	ErrAgencyLoginNameTaken = errors.New("agency login name already taken")
+	// ErrAgencyInvalidCredentials 表示分销端账户名、密码或 API Key 无效。
+	ErrAgencyInvalidCredentials = errors.New("invalid agency credentials")
+	// ErrAgencyDisabled 表示分销商已停用。
+	ErrAgencyDisabled = errors.New("agency disabled")
+	// ErrAgencyAllotmentExhausted 表示分销商在该航次舱房上没有可用配额。
+	ErrAgencyAllotmentExhausted = errors.New("agency allotment exhausted")
+	// ErrAgencyCreditExceeded 表示本次下单将超出分销商信用额度。
+	ErrAgencyCreditExceeded = errors.New("agency credit limit exceeded")
+	// ErrAgencyInventoryInsufficient 表示公共库存不足以预留配额。
+	ErrAgencyInventoryInsufficient = errors.New("insufficient inventory for allotment")
+	// ErrAgencyStatementExists 表示该账期的月结账单已生成。
+	ErrAgencyStatementExists = errors.New("agency statement already exists")
+	// ErrAgencyStatementNotIssued 表示账单已结清，不能重复结算。
+	ErrAgencyStatementNotIssued = errors.New("agency statement is not issued")
+)
+
+// AgencyRepo 定义分销商服务所需的数据访问能力，事务内方法供预订流程复用同一事务。
+type AgencyRepo interface {
+	domain.AgencyRepository
+	LockAgencyTx(tx *gorm.DB, id int64) (*domain.Agency, error)
+	ConsumeAllotmentTx(tx *gorm.DB, agencyID, voyageID, skuID int64, at time.Time) (*domain.AgencyAllotment, error)
+	FindNetRateTx(tx *gorm.DB, agencyID, voyageID, skuID int64) (*domain.AgencyNetRate, error)
+	OutstandingCreditTx(tx *gorm.DB, agencyID int64) (int64, error)
+	CreateAgencyBookingTx(tx *gorm.DB, booking *domain.AgencyBooking) error
+}
+
+// agencySKUStore 提供舱房 SKU 查询，用于校验配额的航次归属。
+type agencySKUStore interface {
+	GetSKUByID(ctx context.Context, id int64) (*domain.CabinSKU, error)
+}
+
+// AgencyBookingRequest 描述一次分销下单请求。
+type AgencyBookingRequest struct {
+	AgencyID   int64  // 分销商 ID
+	VoyageID   int64  // 航次 ID
+	CabinSKUID int64  // 舱房 SKU ID
+	Guests     int    // 入住人数
+	AgencyRef  string // 分销商自有订单号
+}
+
+// AgencyReservation 是分销下单在事务内完成配额占用、净价计算与信用校验后的结果。
+type AgencyReservation struct {
+	AgencyID    int64  // 分销商 ID
+	UserID      int64  // 分销商渠道下单用户 ID
+	AllotmentID int64  // 占用的配额 ID
+	NetCents    int64  // 结算净价（分）
+	AgencyRef   string // 分销商自有订单号
+}
+
+// AgencyCredit 汇总分销商信用额度占用情况。
+type AgencyCredit struct {
+	LimitCents     int64 `json:"limit_cents"`     // 信用额度
+	UsedCents      int64 `json:"used_cents"`      // 已占用（未结清的有效订单净价）
+	AvailableCents int64 `json:"available_cents"` // 剩余可用额度
+}
+
+// AgencyService 负责分销商账户、API Key、配额、净价、信用额度与月结账单，
+// 并作为 BookingService 的分销渠道在预订事务内占用配额与校验信用。
+type AgencyService struct {
+	repo        AgencyRepo
+	skus        agencySKUStore
+	jwtSecret   string
+	expireHours int
+	now         func() time.Time
+}
+
+// NewAgencyService 创建分销商服务。jwtSecret 应与后台、C 端令牌密钥区分，避免令牌跨端复用。
+func NewAgencyService(repo AgencyRepo, skus agencySKUStore, jwtSecret string, expireHours int) *AgencyService {
+	return &AgencyService{repo: repo, skus: skus, jwtSecret: jwtSecret, expireHours: expireHours, now: time.Now}
+}
+
+func (s *AgencyService) ListAgencies(ctx context.Context, keyword string, page, pageSize int) ([]domain.Agency, int64, error) {
+	return s.repo.ListAgencies(ctx, strings.TrimSpace(keyword), page, pageSize)
+}
+
+func (s *AgencyService) GetAgency(ctx context.Context, id int64) (*domain.Agency, error) {
+	agency, err := s.repo.GetAgency(ctx, id)
+	return agency, translateAgencyNotFound(err)
+}
+
+// CreateAgency 创建分销商账户并设置分销端登录密码。
+func (s *AgencyService) CreateAgency(ctx context.Context, agency *domain.Agency, password string) error {
+	if err := normalizeAgency(agency); err != nil {
+		return err
+	}
+	if len(password) < agencyMinPasswordLen {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidAgencyRequest, agencyMinPasswordLen)
+	}
+	if err := s.ensureLoginNameFree(ctx, agency.LoginName, 0); err != nil {
+		return err
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	agency.PasswordHash = hash
+	return translateAgencyNotFound(s.repo.CreateAgency(ctx, agency))
+}
+
+// UpdateAgency 更新分销商资料、佣金比例、信用额度与状态，不修改密码。
+func (s *AgencyService) UpdateAgency(ctx context.Context, agency *domain.Agency) error {
+	if agency.ID <= 0 {
+		return fmt.Errorf("%w: id is required", ErrInvalidAgencyRequest)
+	}
+	if err := normalizeAgency(agency); err != nil {
+		return err
+	}
+	if err := s.ensureLoginNameFree(ctx, agency.LoginName, agency.ID); err != nil {
+		return err
+	}
+	return translateAgencyNotFound(s.repo.UpdateAgency(ctx, agency))
+}
+
+// ResetPassword 重置分销端登录密码。
+func (s *AgencyService) ResetPassword(ctx context.Context, id int64, password string) error {
+	if len(password) < agencyMinPasswordLen {
+		return fmt.Errorf("%w: password must be at least %d characters", ErrInvalidAgencyRequest, agencyMinPasswordLen)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return translateAgencyNotFound(s.repo.UpdateAgencyPassword(ctx, id, hash))
+}
+
+// Login 校验分销端账户名与密码，签发角色为 agency 的 JWT 令牌。
+func (s *AgencyService) Login(ctx context.Context, loginName, password string) (string, time.Time, *domain.Agency, error) {
+	agency, err := s.repo.GetAgencyByLoginName(ctx, strings.TrimSpace(loginName))
+	if err != nil || agency.PasswordHash == "" || !VerifyPassword(agency.PasswordHash, password) {
+		return "", time.Time{}, nil, ErrAgencyInvalidCredentials
+	}
+	if agency.Status != 1 {
+		return "", time.Time{}, nil, ErrAgencyDisabled
+	}
+	token, err := GenerateJWT(agency.ID, []string{agencyTokenRole}, s.jwtSecret, s.expireHours)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	now := s.now()
+	_ = s.repo.TouchAgencyLogin(ctx, agency.ID, now)
+	return token, now.Add(time.Duration(s.expireHours) * time.Hour), agency, nil
+}
+
+// CreateAPIKey 为分销商生成 API Key，明文仅在创建时返回一次，库中只保存摘要。
+// Key 格式为 ak_<前缀>.<密钥>，前缀用于定位记录。
+func (s *AgencyService) CreateAPIKey(ctx context.Context, agencyID int64, name string) (*domain.AgencyAPIKey, string, error) {
+	if _, err := s.GetAgency(ctx, agencyID); err != nil {
+		return nil, "", err
+	}
+	prefix, err := randomHex(6)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, "", err
+	}
+	prefix = agencyAPIKeyPrefix + prefix
+	plain := prefix + "." + secret
+	key := &domain.AgencyAPIKey{
+		AgencyID: agencyID,
+		Name:     strings.TrimSpace(name),
+		Prefix:   prefix,
+		KeyHash:  hashAPIKey(plain),
+	}
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, "", translateAgencyNotFound(err)
+	}
+	return key, plain, nil
+}
+
+func (s *AgencyService) ListAPIKeys(ctx context.Context, agencyID int64) ([]domain.AgencyAPIKey, error) {
+	return s.repo.ListAPIKeys(ctx, agencyID)
+}
+
+func (s *AgencyService) RevokeAPIKey(ctx context.Context, agencyID, keyID int64) error {
+	return translateAgencyNotFound(s.repo.RevokeAPIKey(ctx, agencyID, keyID, s.now()))
+}
+
+// ResolveAPIKey 校验 API Key 并返回所属分销商 ID，供分销端鉴权中间件使用。
+func (s *AgencyService) ResolveAPIKey(ctx context.Context, plain string) (int64, error) {
+	prefix, _, ok := strings.Cut(strings.TrimSpace(plain), ".")
+	if !ok || !strings.HasPrefix(prefix, agencyAPIKeyPrefix) {
+		return 0, ErrAgencyInvalidCredentials
+	}
+	key, err := s.repo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil || key.RevokedAt != nil {
+		return 0, ErrAgencyInvalidCredentials
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(strings.TrimSpace(plain)))) != 1 {
+		return 0, ErrAgencyInvalidCredentials
+	}
+	agency, err := s.repo.GetAgency(ctx, key.AgencyID)
+	if err != nil {
+		return 0, ErrAgencyInvalidCredentials
+	}
+	if agency.Status != 1 {
+		return 0, ErrAgencyDisabled
+	}
+	_ = s.repo.TouchAPIKey(ctx, key.ID, s.now())
+	return agency.ID, nil
+}
+
+// CheckAgencyActive 确认分销商账户存在且处于启用状态，供分销端鉴权复核已签发令牌对应的账户。
+func (s *AgencyService) CheckAgencyActive(ctx context.Context, agencyID int64) error {
+	agency, err := s.repo.GetAgency(ctx, agencyID)
+	if err != nil {
+		return ErrAgencyInvalidCredentials
+	}
+	if agency.Status != 1 {
+		return ErrAgencyDisabled
+	}
+	return nil
+}
+
+// CreateAllotment 为分销商预留航次舱房配额，并立即从公共库存中扣减。
+func (s *AgencyService) CreateAllotment(ctx context.Context, allotment *domain.AgencyAllotment) error {
+	if allotment.Quantity <= 0 {
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidAgencyRequest)
+	}
+	if !allotment.ReleaseAt.After(s.now()) {
+		return fmt.Errorf("%w: release_at must be in the future", ErrInvalidAgencyRequest)
+	}
+	if _, err := s.GetAgency(ctx, allotment.AgencyID); err != nil {
+		return err
+	}
+	sku, err := s.skus.GetSKUByID(ctx, allotment.CabinSKUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: cabin sku not found", ErrInvalidAgencyRequest)
+		}
+		return err
+	}
+	if allotment.VoyageID == 0 {
+		allotment.VoyageID = sku.VoyageID
+	}
+	if sku.VoyageID != allotment.VoyageID {
+		return fmt.Errorf("%w: cabin sku does not belong to voyage", ErrInvalidAgencyRequest)
+	}
+	allotment.Used = 0
+	allotment.ReleasedAt = nil
+	err = s.repo.CreateAllotment(ctx, allotment)
+	switch {
+	case errors.Is(err, domain.ErrInsufficientInventory):
+		return fmt.Errorf("%w: %v", ErrAgencyInventoryInsufficient, err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("%w: cabin inventory not found", ErrInvalidAgencyRequest)
+	}
+	return err
+}
+
+func (s *AgencyService) ListAllotments(ctx context.Context, agencyID, voyageID int64) ([]domain.AgencyAllotment, error) {
+	return s.repo.ListAllotments(ctx, agencyID, voyageID)
+}
+
+// ReleaseAllotment 提前释放配额，未使用部分退回公共库存，返回退回数量。
+func (s *AgencyService) ReleaseAllotment(ctx context.Context, agencyID, allotmentID int64) (int, error) {
+	allotment, err := s.repo.GetAllotment(ctx, allotmentID)
+	if err != nil {
+		return 0, translateAgencyNotFound(err)
+	}
+	if allotment.AgencyID != agencyID {
+		return 0, ErrAgencyNotFound
+	}
+	return s.repo.ReleaseAllotment(ctx, allotmentID, s.now())
+}
+
+// ReleaseDueAllotments 释放所有已到释放日期的配额，返回本轮释放的配额数；单条失败不影响其余配额。
+func (s *AgencyService) ReleaseDueAllotments(ctx context.Context) (int, error) {
+	now := s.now()
+	ids, err := s.repo.ListDueAllotmentIDs(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	released := 0
+	var errs []error
+	for _, id := range ids {
+		if _, err := s.repo.ReleaseAllotment(ctx, id, now); err != nil {
+			errs = append(errs, fmt.Errorf("allotment %d: %w", id, err))
+			continue
+		}
+		released++
+	}
+	return released, errors.Join(errs...)
+}
+
+// UpsertNetRate 设置分销商在航次舱型上的固定净价。
+func (s *AgencyService) UpsertNetRate(ctx context.Context, rate *domain.AgencyNetRate) error {
+	if rate.VoyageID <= 0 || rate.CabinTypeID <= 0 {
+		return fmt.Errorf("%w: voyage_id and cabin_type_id are required", ErrInvalidAgencyRequest)
+	}
+	if rate.NetPriceCents < 0 {
+		return fmt.Errorf("%w: net_price_cents must not be negative", ErrInvalidAgencyRequest)
+	}
+	if _, err := s.GetAgency(ctx, rate.AgencyID); err != nil {
+		return err
+	}
+	return translateAgencyNotFound(s.repo.UpsertNetRate(ctx, rate))
+}
+
+func (s *AgencyService) ListNetRates(ctx context.Context, agencyID int64) ([]domain.AgencyNetRate, error) {
+	return s.repo.ListNetRates(ctx, agencyID)
+}
+
+func (s *AgencyService) DeleteNetRate(ctx context.Context, agencyID, id int64) error {
+	return s.repo.DeleteNetRate(ctx, agencyID, id)
+}
+
+// Credit 返回分销商信用额度与已占用额度。
+func (s *AgencyService) Credit(ctx context.Context, agencyID int64) (*AgencyCredit, error) {
+	agency, err := s.GetAgency(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.repo.OutstandingCredit(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	return &AgencyCredit{LimitCents: agency.CreditLimitCents, UsedCents: used, AvailableCents: agency.CreditLimitCents - used}, nil
+}
+
+func (s *AgencyService) ListBookings(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyBookingView, int64, error) {
+	return s.repo.ListAgencyBookings(ctx, agencyID, page, pageSize)
+}
+
+// IssueStatement 为分销商生成指定账期（YYYY-MM，上海时间）的月结账单，仅允许已结束的月份。
+func (s *AgencyService) IssueStatement(ctx context.Context, agencyID int64, period string, staffID int64) (*domain.AgencyStatement, error) {
+	from, err := time.ParseInLocation(agencyStatementPattern, strings.TrimSpace(period), shanghaiLocation)
+	if err != nil {
+		return nil, fmt.Errorf("%w: period must be YYYY-MM", ErrInvalidAgencyRequest)
+	}
+	to := from.AddDate(0, 1, 0)
+	if to.After(s.now()) {
+		return nil, fmt.Errorf("%w: period has not ended", ErrInvalidAgencyRequest)
+	}
+	if _, err := s.GetAgency(ctx, agencyID); err != nil {
+		return nil, err
+	}
+	statement := &domain.AgencyStatement{
+		AgencyID: agencyID,
+		Period:   from.Format(agencyStatementPattern),
+		Status:   domain.AgencyStatementStatusIssued,
+		IssuedBy: staffID,
+	}
+	if err := s.repo.CreateStatement(ctx, statement, from.UTC(), to.UTC()); err != nil {
+		if errors.Is(err, domain.ErrAgencyStatementExists) {
+			return nil, fmt.Errorf("%w: %s", ErrAgencyStatementExists, statement.Period)
+		}
+		return nil, translateAgencyNotFound(err)
+	}
+	return statement, nil
+}
+
+func (s *AgencyService) ListStatements(ctx context.Context, agencyID int64, page, pageSize int) ([]domain.AgencyStatement, int64, error) {
+	return s.repo.ListStatements(ctx, agencyID, page, pageSize)
+}
+
+// MarkStatementPaid 标记账单已结清，结清后其订单不再占用信用额度。
+func (s *AgencyService) MarkStatementPaid(ctx context.Context, agencyID, statementID int64) (*domain.AgencyStatement, error) {
+	statement, err := s.repo.GetStatement(ctx, statementID)
+	if err != nil {
+		return nil, translateAgencyNotFound(err)
+	}
+	if statement.AgencyID != agencyID {
+		return nil, ErrAgencyNotFound
+	}
+	if err := s.repo.MarkStatementPaid(ctx, statementID, s.now()); err != nil {
+		if errors.Is(err, domain.ErrAgencyStatementNotIssued) {
+			return nil, ErrAgencyStatementNotIssued
+		}
+		return nil, err
+	}
+	return s.repo.GetStatement(ctx, statementID)
+}
+
+// ReserveTx 在预订事务内锁定分销商、占用一间配额、计算净价并校验信用额度。
+// 固定净价优先，否则按售价扣除默认佣金；信用额度为已占用额度 + 本单净价的硬上限。
+func (s *AgencyService) ReserveTx(tx *gorm.DB, req AgencyBookingRequest, listPriceCents int64) (*AgencyReservation, error) {
+	agency, err := s.repo.LockAgencyTx(tx, req.AgencyID)
+	if err != nil {
+		return nil, translateAgencyNotFound(err)
+	}
+	if agency.Status != 1 {
+		return nil, ErrAgencyDisabled
+	}
+	allotment, err := s.repo.ConsumeAllotmentTx(tx, agency.ID, req.VoyageID, req.CabinSKUID, s.now())
+	if err != nil {
+		if errors.Is(err, domain.ErrAllotmentExhausted) {
+			return nil, ErrAgencyAllotmentExhausted
+		}
+		return nil, err
+	}
+	net := agencyNetPrice(listPriceCents, agency.CommissionPercent)
+	rate, err := s.repo.FindNetRateTx(tx, agency.ID, req.VoyageID, req.CabinSKUID)
+	if err != nil {
+		return nil, err
+	}
+	if rate != nil {
+		net = rate.NetPriceCents
+	} else if listPriceCents <= 0 {
+		return nil, fmt.Errorf("%w: no price configured for cabin sku %d", ErrInvalidAgencyRequest, req.CabinSKUID)
+	}
+	outstanding, err := s.repo.OutstandingCreditTx(tx, agency.ID)
+	if err != nil {
+		return nil, err
+	}
+	if outstanding+net > agency.CreditLimitCents {
+		return nil, fmt.Errorf("%w: used %d + %d > limit %d", ErrAgencyCreditExceeded, outstanding, net, agency.CreditLimitCents)
+	}
+	return &AgencyReservation{
+		AgencyID:    agency.ID,
+		UserID:      agency.UserID,
+		AllotmentID: allotment.ID,
+		NetCents:    net,
+		AgencyRef:   strings.TrimSpace(req.AgencyRef),
+	}, nil
+}
+
+// RecordTx 在预订事务内写入分销订单记录。
+func (s *AgencyService) RecordTx(tx *gorm.DB, reservation *AgencyReservation, booking *domain.Booking) error {
+	return s.repo.CreateAgencyBookingTx(tx, &domain.AgencyBooking{
+		AgencyID:    reservation.AgencyID,
+		BookingID:   booking.ID,
+		AllotmentID: reservation.AllotmentID,
+		NetCents:    reservation.NetCents,
+		AgencyRef:   reservation.AgencyRef,
+		CreatedAt:   s.now(),
+	})
+}
+
+func (s *AgencyService) ensureLoginNameFree(ctx context.Context, loginName string, selfID int64) error {
+	existing, err := s.repo.GetAgencyByLoginName(ctx, loginName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != selfID {
+		return ErrAgencyLoginNameTaken
+	}
+	return nil
+}
+
+// normalizeAgency 校验并规范化分销商资料。
+func normalizeAgency(agency *domain.Agency) error {
+	agency.Code = strings.TrimSpace(agency.Code)
+	agency.Name = strings.TrimSpace(agency.Name)
+	agency.LoginName = strings.TrimSpace(agency.LoginName)
+	if agency.Code == "" || agency.Name == "" || agency.LoginName == "" {
+		return fmt.Errorf("%w: code, name and login_name are required", ErrInvalidAgencyRequest)
+	}
+	if agency.CommissionPercent < 0 || agency.CommissionPercent >= 100 {
+		return fmt.Errorf("%w: commission_percent must be in [0, 100)", ErrInvalidAgencyRequest)
+	}
+	if agency.CreditLimitCents < 0 {
+		return fmt.Errorf("%w: credit_limit_cents must not be negative", ErrInvalidAgencyRequest)
+	}
+	if agency.Status != 0 && agency.Status != 1 {
+		return fmt.Errorf("%w: status must be 0 or 1", ErrInvalidAgencyRequest)
+	}
+	return nil
+}
+
+// agencyNetPrice 按默认佣金比例计算净价，四舍五入到分。
+func agencyNetPrice(listPriceCents int64, commissionPercent float64) int64 {
+	return int64(math.Round(float64(listPriceCents) * (1 - commissionPercent/100)))
+}
+
+func hashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func translateAgencyNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAgencyNotFound
+	}
+	return err
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type agencyTestEnv struct {
+	db       *gorm.DB
+	agencies *AgencyService
+	bookings *BookingService
+	now      time.Time
+}
+
+// newAgencyTestEnv 使用 SQLite 内存库组装分销商服务与预订服务，售价固定为 10000 分。
+func newAgencyTestEnv(t *testing.T) *agencyTestEnv {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&domain.User{},
+		&domain.Agency{},
+		&domain.AgencyAPIKey{},
+		&domain.AgencyAllotment{},
+		&domain.AgencyNetRate{},
+		&domain.AgencyBooking{},
+		&domain.AgencyStatement{},
+		&domain.CabinSKU{},
+		&domain.CabinInventory{},
+		&domain.InventoryLog{},
+		&domain.Booking{},
+	))
+	require.NoError(t, db.Create(&domain.CabinSKU{ID: 1, VoyageID: 10, CabinTypeID: 100, Code: "A1"}).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: 1, Total: 10}).Error)
+
+	env := &agencyTestEnv{db: db, now: time.Date(2026, 9, 15, 4, 0, 0, 0, time.UTC)}
+	env.agencies = NewAgencyService(repository.NewAgencyRepository(db), repository.NewCabinRepository(db), "agency-secret", 24)
+	env.agencies.now = func() time.Time { return env.now }
+	env.bookings = NewBookingService(repository.NewBookingRepository(db), fakePriceService{}, &fakeHoldService{})
+	env.bookings.SetAgencyChannel(env.agencies)
+	return env
+}
+
+func (e *agencyTestEnv) createAgency(t *testing.T, commission float64, creditLimit int64) *domain.Agency {
+	t.Helper()
+	agency := &domain.Agency{Code: "TA01", Name: "海天旅行社", LoginName: "haitian", Status: 1, CommissionPercent: commission, CreditLimitCents: creditLimit}
+	require.NoError(t, e.agencies.CreateAgency(context.Background(), agency, "s3cret-pass"))
+	return agency
+}
+
+func (e *agencyTestEnv) inventoryTotal(t *testing.T) int {
+	t.Helper()
+	var inv domain.CabinInventory
+	require.NoError(t, e.db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	return inv.Total
+}
+
+func TestAgencyService_CreateAgencyValidation(t *testing.T) {
+	env := newAgencyTestEnv(t)
+	ctx := context.Background()
+
+	err := env.agencies.CreateAgency(ctx, &domain.Agency{Code: "X", Name: "x", LoginName: "x", Status: 1}, "short")
+	assert.True(t, errors.Is(err, ErrInvalidAgencyRequest))
+	err = env.agencies.CreateAgency(ctx, &domain.Agency{Code: "X", Name: "x", LoginName: "x", Status: 1, CommissionPercent: 100}, "long-enough")
+	assert.True(t, errors.Is(err, ErrInvalidAgencyRequest))
+
+	agency := env.createAgency(t, 10, 0)
+	assert.NotEmpty(t, agency.PasswordHash)
+	err = env.agencies.CreateAgency(ctx, &domain.Agency{Code: "TA02", Name: "y", LoginName: "haitian", Status: 1}, "long-enough")
+	assert.True(t, errors.Is(err, ErrAgencyLoginNameTaken))
+}
+
+func TestAgencyService_LoginAndAPIKey(t *testing.T) {
+	env := newAgencyTestEnv(t)
+	ctx := context.Background()
+	agency := env.createAgency(t, 10, 0)
+
+	_, _, _, err := env.agencies.Login(ctx, "haitian", "wrong-pass")
+	assert.True(t, errors.Is(err, ErrAgencyInvalidCredentials))
+	token, expireAt, got, err := env.agencies.Login(ctx, "haitian", "s3cret-pass")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, env.now.Add(24*time.Hour), expireAt)
+	assert.Equal(t, agency.ID, got.ID)
+
+	key, plain, err := env.agencies.CreateAPIKey(ctx, agency.ID, "OTA 对接")
+	require.NoError(t, err)
+	assert.NotContains(t, key.KeyHash, plain)
+	id, err := env.agencies.ResolveAPIKey(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, agency.ID, id)
+
+	_, err = env.agencies.ResolveAPIKey(ctx, key.Prefix+".forged")
+	assert.True(t, errors.Is(err, ErrAgencyInvalidCredentials))
+
+	require.NoError(t, env.agencies.RevokeAPIKey(ctx, agency.ID, key.ID))
+	_, err = env.agencies.ResolveAPIKey(ctx, plain)
+	assert.True(t, errors.Is(err, ErrAgencyInvalidCredentials), "吊销后不可再用")
+
+	agency.Status = 0
+	require.NoError(t, env.agencies.UpdateAgency(ctx, agency))
+	_, _, _, err = env.agencies.Login(ctx, "haitian", "s3cret-pass")
+	assert.True(t, errors.Is(err, ErrAgencyDisabled))
+}
+
+func TestAgencyService_CreateAllotmentValidation(t *testing.T) {
+	env := newAgencyTestEnv(t)
+	ctx := context.Background()
+	agency := env.createAgency(t, 10, 0)
+
+	past := &domain.AgencyAllotment{AgencyID: agency.ID, CabinSKUID: 1, Quantity: 1, ReleaseAt: env.now.Add(-time.Hour)}
+	assert.True(t, errors.Is(env.agencies.CreateAllotment(ctx, past), ErrInvalidAgencyRequest))
+
+	wrongVoyage := &domain.AgencyAllotment{AgencyID: agency.ID, VoyageID: 11, CabinSKUID: 1, Quantity: 1, ReleaseAt: env.now.Add(time.Hour)}
+	assert.True(t, errors.Is(env.agencies.CreateAllotment(ctx, wrongVoyage), ErrInvalidAgencyRequest))
+
+	tooMany := &domain.AgencyAllotment{AgencyID: agency.ID, CabinSKUID: 1, Quantity: 11, ReleaseAt: env.now.Add(time.Hour)}
+	assert.True(t, errors.Is(env.agencies.CreateAllotment(ctx, tooMany), ErrAgencyInventoryInsufficient))
+
+	ok := &domain.AgencyAllotment{AgencyID: agency.ID, CabinSKUID: 1, Quantity: 3, ReleaseAt: env.now.Add(time.Hour)}
+	require.NoError(t, env.agencies.CreateAllotment(ctx, ok))
+	assert.Equal(t, int64(10), ok.VoyageID, "未传航次时取 SKU 所属航次")
+	assert.Equal(t, 7, env.inventoryTotal(t))
+}
+
+func TestBookingService_CreateForAgency(t *testing.T) {
+	env := newAgencyTestEnv(t)
+	ctx := context.Background()
+	agency := env.createAgency(t, 10, 20000)
+
+	req := AgencyBookingRequest{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Guests: 2, AgencyRef: "OTA-1"}
+	_, err := env.bookings.CreateForAgency(ctx, req)
+	assert.True(t, errors.Is(err, ErrAgencyAllotmentExhausted), "没有配额不能下单")
+
+	allotment := &domain.AgencyAllotment{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Quantity: 3, ReleaseAt: env.now.Add(48 * time.Hour)}
+	require.NoError(t, env.agencies.CreateAllotment(ctx, allotment))
+
+	booking, err := env.bookings.CreateForAgency(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, domain.BookingChannelAgency, booking.Channel)
+	assert.Equal(t, agency.UserID, booking.UserID)
+	assert.Equal(t, int64(9000), booking.TotalCents, "默认按 10% 佣金计算净价")
+
+	require.NoError(t, env.agencies.UpsertNetRate(ctx, &domain.AgencyNetRate{AgencyID: agency.ID, VoyageID: 10, CabinTypeID: 100, NetPriceCents: 8000}))
+	booking, err = env.bookings.CreateForAgency(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8000), booking.TotalCents, "固定净价优先")
+
+	_, err = env.bookings.CreateForAgency(ctx, req)
+	assert.True(t, errors.Is(err, ErrAgencyCreditExceeded), "9000 + 8000 + 8000 超出 20000 额度")
+
+	allotments, err := env.agencies.ListAllotments(ctx, agency.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, allotments, 1)
+	assert.Equal(t, 2, allotments[0].Used, "超额失败时配额占用随事务回滚")
+
+	credit, err := env.agencies.Credit(ctx, agency.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(17000), credit.UsedCents)
+	assert.Equal(t, int64(3000), credit.AvailableCents)
+
+	views, total, err := env.agencies.ListBookings(ctx, agency.ID, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "OTA-1", views[0].AgencyRef)
+}
+
+func TestAgencyService_ReleaseDueAllotments(t *testing.T) {
+	env := newAgencyTestEnv(t)
+	ctx := context.Background()
+	agency := env.createAgency(t, 0, 100000)
+
+	allotment := &domain.AgencyAllotment{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Quantity: 4, ReleaseAt: env.now.Add(time.Hour)}
+	require.NoError(t, env.agencies.CreateAllotment(ctx, allotment))
+	_, err := env.bookings.CreateForAgency(ctx, AgencyBookingRequest{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Guests: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 6, env.inventoryTotal(t))
+
+	released, err := env.agencies.ReleaseDueAllotments(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, released, "未到释放日期")
+
+	env.now = env.now.Add(2 * time.Hour)
+	_, err = env.bookings.CreateForAgency(ctx, AgencyBookingRequest{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Guests: 2})
+	assert.True(t, errors.Is(err, ErrAgencyAllotmentExhausted), "过了释放日期不能再用配额")
+
+	released, err = env.agencies.ReleaseDueAllotments(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Equal(t, 9, env.inventoryTotal(t), "未用的 3 间退回公共库存")
+
+	_, err = env.agencies.ReleaseAllotment(ctx, agency.ID+1, allotment.ID)
+	assert.True(t, errors.Is(err, ErrAgencyNotFound), "不能释放其他分销商的配额")
+}
+
+func TestAgencyService_Statements(t *testing.T) {
+	env := newAgencyTestEnv(t)
+	ctx := context.Background()
+	agency := env.createAgency(t, 0, 15000)
+
+	allotment := &domain.AgencyAllotment{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Quantity: 3, ReleaseAt: env.now.AddDate(0, 2, 0)}
+	require.NoError(t, env.agencies.CreateAllotment(ctx, allotment))
+	req := AgencyBookingRequest{AgencyID: agency.ID, VoyageID: 10, CabinSKUID: 1, Guests: 2}
+	_, err := env.bookings.CreateForAgency(ctx, req)
+	require.NoError(t, err)
+	_, err = env.bookings.CreateForAgency(ctx, req)
+	assert.True(t, errors.Is(err, ErrAgencyCreditExceeded))
+
+	_, err = env.agencies.IssueStatement(ctx, agency.ID, "2026-09", 1)
+	assert.True(t, errors.Is(err, ErrInvalidAgencyRequest), "当月尚未结束")
+	_, err = env.agencies.IssueStatement(ctx, agency.ID, "2026/09", 1)
+	assert.True(t, errors.Is(err, ErrInvalidAgencyRequest))
+
+	env.now = time.Date(2026, 10, 1, 1, 0, 0, 0, time.UTC)
+	statement, err := env.agencies.IssueStatement(ctx, agency.ID, "2026-09", 7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, statement.BookingCount)
+	assert.Equal(t, int64(10000), statement.TotalCents)
+	assert.Equal(t, int64(7), statement.IssuedBy)
+
+	_, err = env.agencies.IssueStatement(ctx, agency.ID, "2026-09", 7)
+	assert.True(t, errors.Is(err, ErrAgencyStatementExists))
+
+	paid, err := env.agencies.MarkStatementPaid(ctx, agency.ID, statement.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AgencyStatementStatusPaid, paid.Status)
+	_, err = env.agencies.MarkStatementPaid(ctx, agency.ID, statement.ID)
+	assert.True(t, errors.Is(err, ErrAgencyStatementNotIssued))
+
+	_, err = env.bookings.CreateForAgency(ctx, req)
+	require.NoError(t, err, "结清账单后额度恢复")
+}
+
+func TestAgencyNetPrice(t *testing.T) {
+	assert.Equal(t, int64(8833), agencyNetPrice(9999, 11.66))
+	assert.Equal(t, int64(10000), agencyNetPrice(10000, 0))
+}
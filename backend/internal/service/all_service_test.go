@@ -315,7 +315,9 @@ func TestUserAuthServiceAll(t *testing.T) {
 	svc.SendSMS("error", "1234")
 	svc.VerifySMS("123", "1234")
 	svc.VerifySMS("123", "wrong")
-	svc.WechatLogin("code")
+	if _, err := svc.WechatLogin(context.Background(), "code"); !errors.Is(err, ErrWechatNotConfigured) {
+		t.Fatalf("expected ErrWechatNotConfigured, got %v", err)
+	}
 }
 
 // 其他服务测试
@@ -347,9 +349,12 @@ func (m *mockPriceRepo) Create(ctx context.Context, price *domain.CabinPrice) er
 
 type mockHoldSvc struct{}
 
-func (m *mockHoldSvc) HoldWithTx(tx *gorm.DB, sku, u int64, q int) bool {
+func (m *mockHoldSvc) TryHoldWithTx(tx *gorm.DB, sku, u int64, q int) error {
 	_ = tx
-	return sku != 99
+	if sku == 99 {
+		return errors.New("hold failed")
+	}
+	return nil
 }
 
 type mockPriceSvc struct{}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/xlsx"
+)
+
+const (
+	AnalyticsGranularityDay   = "day"
+	AnalyticsGranularityWeek  = "week"
+	AnalyticsGranularityMonth = "month"
+
+	AnalyticsDimensionCompany       = "company"
+	AnalyticsDimensionCruise        = "cruise"
+	AnalyticsDimensionVoyage        = "voyage"
+	AnalyticsDimensionCabinCategory = "cabin_category"
+	AnalyticsDimensionChannel       = "channel"
+
+	AnalyticsExportCSV  = "csv"
+	AnalyticsExportXLSX = "xlsx"
+
+	analyticsMaxRangeDays = 366 // 单次查询最多跨越的自然日数
+)
+
+// ErrInvalidAnalyticsQuery 表示分析查询参数不合法。
+var ErrInvalidAnalyticsQuery = errors.New("invalid analytics query")
+
+// analyticsDimensionOrder 固定维度输出顺序，保证同一查询的分组键与导出列稳定。
+var analyticsDimensionOrder = []string{
+	AnalyticsDimensionCompany,
+	AnalyticsDimensionCruise,
+	AnalyticsDimensionVoyage,
+	AnalyticsDimensionCabinCategory,
+	AnalyticsDimensionChannel,
+}
+
+// analyticsSoldStatuses 为计入 GMV / 已支付订单的状态（含后续退款中、已退款）。
+var analyticsSoldStatuses = map[string]bool{
+	domain.OrderStatusPaid:          true,
+	domain.OrderStatusConfirmed:     true,
+	domain.OrderStatusPendingTravel: true,
+	domain.OrderStatusTraveling:     true,
+	domain.OrderStatusCompleted:     true,
+	domain.OrderStatusRefunding:     true,
+	domain.OrderStatusRefunded:      true,
+}
+
+// analyticsRefundStatuses 为计入退款率分子的状态。
+var analyticsRefundStatuses = map[string]bool{
+	domain.OrderStatusRefunding: true,
+	domain.OrderStatusRefunded:  true,
+}
+
+// AnalyticsQuery 描述一次可配置的分析查询。
+// 日期均按 Asia/Shanghai 自然日解释，EndDate 含当天。
+type AnalyticsQuery struct {
+	StartDate   string   // 起始日期 YYYY-MM-DD
+	EndDate     string   // 截止日期 YYYY-MM-DD（含）
+	Granularity string   // 时间粒度：day/week/month，默认 day
+	Dimensions  []string // 分组维度：company/cruise/voyage/cabin_category/channel
+	CompanyID   int64    // 邮轮公司过滤
+	CruiseID    int64    // 邮轮过滤
+	VoyageID    int64    // 航次过滤
+	CategoryID  int64    // 舱型大类过滤
+	Channel     string   // 销售渠道过滤
+}
+
+// AnalyticsQueryRow 表示一个时间桶 + 维度组合下的指标。
+type AnalyticsQueryRow struct {
+	Bucket       string  `json:"bucket"`                  // 时间桶起始日期（月粒度为 YYYY-MM）
+	CompanyID    int64   `json:"company_id,omitempty"`    // 邮轮公司 ID
+	CompanyName  string  `json:"company_name,omitempty"`  // 邮轮公司名称
+	CruiseID     int64   `json:"cruise_id,omitempty"`     // 邮轮 ID
+	CruiseName   string  `json:"cruise_name,omitempty"`   // 邮轮名称
+	VoyageID     int64   `json:"voyage_id,omitempty"`     // 航次 ID
+	VoyageCode   string  `json:"voyage_code,omitempty"`   // 航次编码
+	CategoryID   int64   `json:"category_id,omitempty"`   // 舱型大类 ID
+	CategoryName string  `json:"category_name,omitempty"` // 舱型大类名称
+	Channel      string  `json:"channel,omitempty"`       // 销售渠道
+	GMVCents     int64   `json:"gmv_cents"`               // 成交总额（分）
+	Orders       int64   `json:"orders"`                  // 下单数
+	PaidOrders   int64   `json:"paid_orders"`             // 已支付订单数
+	RefundOrders int64   `json:"refund_orders"`           // 退款订单数
+	RefundRate   float64 `json:"refund_rate"`             // 退款率 = 退款订单 / 已支付订单
+	SoldCabins   int64   `json:"sold_cabins"`             // 售出舱房数（不含退款）
+	Capacity     int64   `json:"capacity"`                // 对应航次舱房总量
+	LoadFactor   float64 `json:"load_factor"`             // 上座率 = 售出舱房 / 舱房总量
+	AOVCents     int64   `json:"aov_cents"`               // 客单价 = GMV / 已支付订单（分）
+}
+
+// AnalyticsQueryResult 是分析查询的返回结果。
+type AnalyticsQueryResult struct {
+	StartDate   string              `json:"start_date"`
+	EndDate     string              `json:"end_date"`
+	Granularity string              `json:"granularity"`
+	Dimensions  []string            `json:"dimensions"`
+	Rows        []AnalyticsQueryRow `json:"rows"`
+	Totals      AnalyticsQueryRow   `json:"totals"`
+}
+
+// analyticsAccumulator 在聚合过程中累积单个分组的指标与涉及的航次。
+type analyticsAccumulator struct {
+	row     AnalyticsQueryRow
+	voyages map[int64]struct{}
+}
+
+// Query 执行可配置分析查询：按时间粒度与维度分组，计算 GMV、订单数、退款率、上座率与客单价。
+func (s *AnalyticsService) Query(ctx context.Context, q AnalyticsQuery) (*AnalyticsQueryResult, error) {
+	normalized, from, to, err := normalizeAnalyticsQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	facts, err := s.repo.ListBookingFacts(ctx, domain.AnalyticsFactFilter{
+		From:       from.UTC(),
+		To:         to.UTC(),
+		CompanyID:  normalized.CompanyID,
+		CruiseID:   normalized.CruiseID,
+		VoyageID:   normalized.VoyageID,
+		CategoryID: normalized.CategoryID,
+		Channel:    normalized.Channel,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dims := make(map[string]bool, len(normalized.Dimensions))
+	for _, d := range normalized.Dimensions {
+		dims[d] = true
+	}
+
+	groups := map[string]*analyticsAccumulator{}
+	totals := &analyticsAccumulator{voyages: map[int64]struct{}{}}
+	for _, fact := range facts {
+		bucket := analyticsBucket(fact.CreatedAt, normalized.Granularity)
+		row := AnalyticsQueryRow{Bucket: bucket}
+		if dims[AnalyticsDimensionCompany] {
+			row.CompanyID, row.CompanyName = fact.CompanyID, fact.CompanyName
+		}
+		if dims[AnalyticsDimensionCruise] {
+			row.CruiseID, row.CruiseName = fact.CruiseID, fact.CruiseName
+		}
+		if dims[AnalyticsDimensionVoyage] {
+			row.VoyageID, row.VoyageCode = fact.VoyageID, fact.VoyageCode
+		}
+		if dims[AnalyticsDimensionCabinCategory] {
+			row.CategoryID, row.CategoryName = fact.CategoryID, fact.CategoryName
+		}
+		if dims[AnalyticsDimensionChannel] {
+			row.Channel = fact.Channel
+		}
+
+		key := analyticsGroupKey(row)
+		acc, ok := groups[key]
+		if !ok {
+			acc = &analyticsAccumulator{row: row, voyages: map[int64]struct{}{}}
+			groups[key] = acc
+		}
+		acc.add(fact)
+		totals.add(fact)
+	}
+
+	if len(normalized.Dimensions) == 0 {
+		for _, bucket := range analyticsBuckets(from, to, normalized.Granularity) {
+			if _, ok := groups[bucket]; !ok {
+				groups[bucket] = &analyticsAccumulator{row: AnalyticsQueryRow{Bucket: bucket}, voyages: map[int64]struct{}{}}
+			}
+		}
+	}
+
+	capacity, err := s.loadCapacityIndex(ctx, totals.voyages)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]AnalyticsQueryRow, 0, len(groups))
+	for _, acc := range groups {
+		categoryID := normalized.CategoryID
+		if dims[AnalyticsDimensionCabinCategory] {
+			categoryID = acc.row.CategoryID
+		}
+		rows = append(rows, acc.finish(capacity, categoryID))
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Bucket != rows[j].Bucket {
+			return rows[i].Bucket < rows[j].Bucket
+		}
+		return analyticsGroupKey(rows[i]) < analyticsGroupKey(rows[j])
+	})
+
+	return &AnalyticsQueryResult{
+		StartDate:   normalized.StartDate,
+		EndDate:     normalized.EndDate,
+		Granularity: normalized.Granularity,
+		Dimensions:  normalized.Dimensions,
+		Rows:        rows,
+		Totals:      totals.finish(capacity, normalized.CategoryID),
+	}, nil
+}
+
+// Export 执行分析查询并按 format（csv/xlsx）导出为文件字节。
+func (s *AnalyticsService) Export(ctx context.Context, q AnalyticsQuery, format string) ([]byte, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = AnalyticsExportCSV
+	}
+	if format != AnalyticsExportCSV && format != AnalyticsExportXLSX {
+		return nil, fmt.Errorf("%w: unsupported export format %q", ErrInvalidAnalyticsQuery, format)
+	}
+
+	result, err := s.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	table := analyticsExportTable(result)
+
+	if format == AnalyticsExportXLSX {
+		return xlsx.Encode("analytics", table)
+	}
+	buf := bytes.NewBuffer(nil)
+	writer := csv.NewWriter(buf)
+	for _, row := range table {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case string:
+				record[i] = sanitizeCSVCell(v)
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', 4, 64)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// loadCapacityIndex 加载航次 × 舱型大类的舱房总量索引。
+func (s *AnalyticsService) loadCapacityIndex(ctx context.Context, voyages map[int64]struct{}) (map[int64]map[int64]int64, error) {
+	index := map[int64]map[int64]int64{}
+	if len(voyages) == 0 {
+		return index, nil
+	}
+	ids := make([]int64, 0, len(voyages))
+	for id := range voyages {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	facts, err := s.repo.ListCapacityFacts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, fact := range facts {
+		if index[fact.VoyageID] == nil {
+			index[fact.VoyageID] = map[int64]int64{}
+		}
+		index[fact.VoyageID][fact.CategoryID] += fact.Capacity
+	}
+	return index, nil
+}
+
+func (a *analyticsAccumulator) add(fact domain.AnalyticsBookingFact) {
+	a.row.Orders++
+	a.voyages[fact.VoyageID] = struct{}{}
+	if !analyticsSoldStatuses[fact.Status] {
+		return
+	}
+	a.row.PaidOrders++
+	a.row.GMVCents += fact.TotalCents
+	if analyticsRefundStatuses[fact.Status] {
+		a.row.RefundOrders++
+		return
+	}
+	a.row.SoldCabins++
+}
+
+// finish 计算派生指标；categoryID 非 0 时仅统计该舱型大类的舱房总量。
+func (a *analyticsAccumulator) finish(capacity map[int64]map[int64]int64, categoryID int64) AnalyticsQueryRow {
+	row := a.row
+	for voyageID := range a.voyages {
+		for cat, total := range capacity[voyageID] {
+			if categoryID == 0 || cat == categoryID {
+				row.Capacity += total
+			}
+		}
+	}
+	if row.PaidOrders > 0 {
+		row.RefundRate = roundRatio(float64(row.RefundOrders) / float64(row.PaidOrders))
+		row.AOVCents = row.GMVCents / row.PaidOrders
+	}
+	if row.Capacity > 0 {
+		row.LoadFactor = roundRatio(float64(row.SoldCabins) / float64(row.Capacity))
+	}
+	return row
+}
+
+// normalizeAnalyticsQuery 校验并补全查询参数，返回上海时区的 [from, to) 时间区间。
+func normalizeAnalyticsQuery(q AnalyticsQuery) (AnalyticsQuery, time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(q.StartDate), shanghaiLocation)
+	if err != nil {
+		return q, time.Time{}, time.Time{}, fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidAnalyticsQuery)
+	}
+	end, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(q.EndDate), shanghaiLocation)
+	if err != nil {
+		return q, time.Time{}, time.Time{}, fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrInvalidAnalyticsQuery)
+	}
+	if end.Before(start) {
+		return q, time.Time{}, time.Time{}, fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidAnalyticsQuery)
+	}
+	to := end.AddDate(0, 0, 1)
+	if to.Sub(start) > analyticsMaxRangeDays*24*time.Hour {
+		return q, time.Time{}, time.Time{}, fmt.Errorf("%w: date range must not exceed %d days", ErrInvalidAnalyticsQuery, analyticsMaxRangeDays)
+	}
+
+	q.StartDate = start.Format("2006-01-02")
+	q.EndDate = end.Format("2006-01-02")
+	q.Granularity = strings.ToLower(strings.TrimSpace(q.Granularity))
+	switch q.Granularity {
+	case "":
+		q.Granularity = AnalyticsGranularityDay
+	case AnalyticsGranularityDay, AnalyticsGranularityWeek, AnalyticsGranularityMonth:
+	default:
+		return q, time.Time{}, time.Time{}, fmt.Errorf("%w: granularity must be day, week or month", ErrInvalidAnalyticsQuery)
+	}
+
+	requested := map[string]bool{}
+	for _, d := range q.Dimensions {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		if !isAnalyticsDimension(d) {
+			return q, time.Time{}, time.Time{}, fmt.Errorf("%w: unsupported dimension %q", ErrInvalidAnalyticsQuery, d)
+		}
+		requested[d] = true
+	}
+	dims := make([]string, 0, len(requested))
+	for _, d := range analyticsDimensionOrder {
+		if requested[d] {
+			dims = append(dims, d)
+		}
+	}
+	q.Dimensions = dims
+	q.Channel = strings.TrimSpace(q.Channel)
+	return q, start, to, nil
+}
+
+func isAnalyticsDimension(d string) bool {
+	for _, known := range analyticsDimensionOrder {
+		if known == d {
+			return true
+		}
+	}
+	return false
+}
+
+// analyticsBucket 将时间换算到上海时区后按粒度取桶：日/周（周一起始）/月。
+func analyticsBucket(t time.Time, granularity string) string {
+	local := t.In(shanghaiLocation)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, shanghaiLocation)
+	switch granularity {
+	case AnalyticsGranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset).Format("2006-01-02")
+	case AnalyticsGranularityMonth:
+		return day.Format("2006-01")
+	default:
+		return day.Format("2006-01-02")
+	}
+}
+
+// analyticsBuckets 枚举 [from, to) 区间覆盖的全部时间桶，用于补齐无数据的时间点。
+func analyticsBuckets(from, to time.Time, granularity string) []string {
+	buckets := make([]string, 0)
+	seen := map[string]bool{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		bucket := analyticsBucket(day, granularity)
+		if !seen[bucket] {
+			seen[bucket] = true
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets
+}
+
+func analyticsGroupKey(row AnalyticsQueryRow) string {
+	if row.CompanyID == 0 && row.CruiseID == 0 && row.VoyageID == 0 && row.CategoryID == 0 && row.Channel == "" {
+		return row.Bucket
+	}
+	return fmt.Sprintf("%s|%d|%d|%d|%d|%s", row.Bucket, row.CompanyID, row.CruiseID, row.VoyageID, row.CategoryID, row.Channel)
+}
+
+func roundRatio(v float64) float64 {
+	return float64(int64(v*10000+0.5)) / 10000
+}
+
+// analyticsExportTable 将查询结果展开为导出表格（首行为表头，末行为合计）。
+func analyticsExportTable(result *AnalyticsQueryResult) [][]any {
+	header := []any{"bucket"}
+	for _, d := range result.Dimensions {
+		switch d {
+		case AnalyticsDimensionCompany:
+			header = append(header, "company_id", "company_name")
+		case AnalyticsDimensionCruise:
+			header = append(header, "cruise_id", "cruise_name")
+		case AnalyticsDimensionVoyage:
+			header = append(header, "voyage_id", "voyage_code")
+		case AnalyticsDimensionCabinCategory:
+			header = append(header, "category_id", "category_name")
+		case AnalyticsDimensionChannel:
+			header = append(header, "channel")
+		}
+	}
+	header = append(header, "gmv_cents", "orders", "paid_orders", "refund_orders", "refund_rate", "sold_cabins", "capacity", "load_factor", "aov_cents")
+
+	table := [][]any{header}
+	appendRow := func(row AnalyticsQueryRow, bucket string) {
+		record := []any{bucket}
+		for _, d := range result.Dimensions {
+			switch d {
+			case AnalyticsDimensionCompany:
+				record = append(record, row.CompanyID, row.CompanyName)
+			case AnalyticsDimensionCruise:
+				record = append(record, row.CruiseID, row.CruiseName)
+			case AnalyticsDimensionVoyage:
+				record = append(record, row.VoyageID, row.VoyageCode)
+			case AnalyticsDimensionCabinCategory:
+				record = append(record, row.CategoryID, row.CategoryName)
+			case AnalyticsDimensionChannel:
+				record = append(record, row.Channel)
+			}
+		}
+		record = append(record, row.GMVCents, row.Orders, row.PaidOrders, row.RefundOrders, row.RefundRate, row.SoldCabins, row.Capacity, row.LoadFactor, row.AOVCents)
+		table = append(table, record)
+	}
+	for _, row := range result.Rows {
+		appendRow(row, row.Bucket)
+	}
+	appendRow(result.Totals, "total")
+	return table
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAnalyticsFactRepo 在 fakeAnalyticsRepo 基础上返回预置的事实行。
+type fakeAnalyticsFactRepo struct {
+	fakeAnalyticsRepo
+	bookings      []domain.AnalyticsBookingFact
+	capacities    []domain.AnalyticsCapacityFact
+	lastFilter    domain.AnalyticsFactFilter
+	lastVoyageIDs []int64
+}
+
+func (f *fakeAnalyticsFactRepo) ListBookingFacts(_ context.Context, filter domain.AnalyticsFactFilter) ([]domain.AnalyticsBookingFact, error) {
+	f.lastFilter = filter
+	return f.bookings, nil
+}
+
+func (f *fakeAnalyticsFactRepo) ListCapacityFacts(_ context.Context, voyageIDs []int64) ([]domain.AnalyticsCapacityFact, error) {
+	f.lastVoyageIDs = voyageIDs
+	return f.capacities, nil
+}
+
+func newAnalyticsQueryFixture() *fakeAnalyticsFactRepo {
+	// 2026-03-01 23:30 上海时间 = 2026-03-01 15:30 UTC；2026-03-01 16:30 UTC 已是上海 3 月 2 日。
+	return &fakeAnalyticsFactRepo{
+		bookings: []domain.AnalyticsBookingFact{
+			{BookingID: 1, CreatedAt: time.Date(2026, 3, 1, 15, 30, 0, 0, time.UTC), Status: domain.OrderStatusPaid, TotalCents: 10000, Channel: "direct", CompanyID: 1, CompanyName: "皇家", VoyageID: 11, CategoryID: 1, CategoryName: "内舱"},
+			{BookingID: 2, CreatedAt: time.Date(2026, 3, 1, 16, 30, 0, 0, time.UTC), Status: domain.OrderStatusRefunded, TotalCents: 20000, Channel: "direct", CompanyID: 1, CompanyName: "皇家", VoyageID: 11, CategoryID: 2, CategoryName: "阳台"},
+			{BookingID: 3, CreatedAt: time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC), Status: domain.OrderStatusCancelled, TotalCents: 30000, Channel: "agency", CompanyID: 1, CompanyName: "皇家", VoyageID: 11, CategoryID: 1, CategoryName: "内舱"},
+			{BookingID: 4, CreatedAt: time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC), Status: domain.OrderStatusConfirmed, TotalCents: 40000, Channel: "agency", CompanyID: 2, CompanyName: "地中海", VoyageID: 21, CategoryID: 1, CategoryName: "内舱"},
+		},
+		capacities: []domain.AnalyticsCapacityFact{
+			{VoyageID: 11, CategoryID: 1, Capacity: 4},
+			{VoyageID: 11, CategoryID: 2, Capacity: 6},
+			{VoyageID: 21, CategoryID: 1, Capacity: 10},
+		},
+	}
+}
+
+func TestAnalyticsQuery_DailyBucketsInShanghaiTime(t *testing.T) {
+	repo := newAnalyticsQueryFixture()
+	svc := NewAnalyticsService(repo)
+
+	result, err := svc.Query(context.Background(), AnalyticsQuery{StartDate: "2026-03-01", EndDate: "2026-03-03"})
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2026, 2, 28, 16, 0, 0, 0, time.UTC), repo.lastFilter.From)
+	assert.Equal(t, time.Date(2026, 3, 3, 16, 0, 0, 0, time.UTC), repo.lastFilter.To)
+
+	require.Len(t, result.Rows, 3, "无维度时应补齐区间内每一天")
+	assert.Equal(t, "2026-03-01", result.Rows[0].Bucket)
+	assert.Equal(t, int64(1), result.Rows[0].Orders)
+	assert.Equal(t, "2026-03-02", result.Rows[1].Bucket)
+	assert.Equal(t, int64(3), result.Rows[1].Orders)
+	assert.Equal(t, int64(60000), result.Rows[1].GMVCents)
+	assert.Equal(t, "2026-03-03", result.Rows[2].Bucket)
+	assert.Equal(t, int64(0), result.Rows[2].Orders)
+
+	totals := result.Totals
+	assert.Equal(t, int64(4), totals.Orders)
+	assert.Equal(t, int64(3), totals.PaidOrders)
+	assert.Equal(t, int64(70000), totals.GMVCents)
+	assert.Equal(t, int64(1), totals.RefundOrders)
+	assert.InDelta(t, 0.3333, totals.RefundRate, 0.0001)
+	assert.Equal(t, int64(23333), totals.AOVCents)
+	assert.Equal(t, int64(20), totals.Capacity)
+	assert.InDelta(t, 0.1, totals.LoadFactor, 0.0001)
+	assert.ElementsMatch(t, []int64{11, 21}, repo.lastVoyageIDs)
+}
+
+func TestAnalyticsQuery_GroupByCompanyAndCategoryWeekly(t *testing.T) {
+	svc := NewAnalyticsService(newAnalyticsQueryFixture())
+
+	result, err := svc.Query(context.Background(), AnalyticsQuery{
+		StartDate:   "2026-03-01",
+		EndDate:     "2026-03-10",
+		Granularity: "week",
+		Dimensions:  []string{"cabin_category", "company", "cabin_category"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{AnalyticsDimensionCompany, AnalyticsDimensionCabinCategory}, result.Dimensions)
+
+	// 2026-03-01 是周日，归属 2026-02-23 开始的一周；3 月 2 日（周一）开始新的一周。
+	require.Len(t, result.Rows, 4)
+	assert.Equal(t, "2026-02-23", result.Rows[0].Bucket)
+	assert.Equal(t, int64(1), result.Rows[0].CategoryID)
+	assert.Equal(t, int64(4), result.Rows[0].Capacity)
+	assert.InDelta(t, 0.25, result.Rows[0].LoadFactor, 0.0001)
+
+	var agencyInterior *AnalyticsQueryRow
+	for i := range result.Rows {
+		row := result.Rows[i]
+		if row.Bucket == "2026-03-02" && row.CompanyID == 2 {
+			agencyInterior = &result.Rows[i]
+		}
+	}
+	require.NotNil(t, agencyInterior)
+	assert.Equal(t, "地中海", agencyInterior.CompanyName)
+	assert.Equal(t, int64(10), agencyInterior.Capacity)
+	assert.Equal(t, int64(40000), agencyInterior.AOVCents)
+}
+
+func TestAnalyticsQuery_MonthlyByChannel(t *testing.T) {
+	svc := NewAnalyticsService(newAnalyticsQueryFixture())
+
+	result, err := svc.Query(context.Background(), AnalyticsQuery{
+		StartDate:   "2026-03-01",
+		EndDate:     "2026-03-31",
+		Granularity: "MONTH",
+		Dimensions:  []string{"channel"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 2)
+	assert.Equal(t, "2026-03", result.Rows[0].Bucket)
+	assert.Equal(t, "agency", result.Rows[0].Channel)
+	assert.Equal(t, int64(2), result.Rows[0].Orders)
+	assert.Equal(t, "direct", result.Rows[1].Channel)
+	assert.InDelta(t, 0.5, result.Rows[1].RefundRate, 0.0001)
+}
+
+func TestAnalyticsQuery_InvalidInput(t *testing.T) {
+	svc := NewAnalyticsService(newAnalyticsQueryFixture())
+	cases := []AnalyticsQuery{
+		{StartDate: "2026/03/01", EndDate: "2026-03-02"},
+		{StartDate: "2026-03-02", EndDate: "2026-03-01"},
+		{StartDate: "2025-01-01", EndDate: "2026-03-01"},
+		{StartDate: "2026-03-01", EndDate: "2026-03-02", Granularity: "hour"},
+		{StartDate: "2026-03-01", EndDate: "2026-03-02", Dimensions: []string{"route"}},
+	}
+	for _, q := range cases {
+		_, err := svc.Query(context.Background(), q)
+		assert.True(t, errors.Is(err, ErrInvalidAnalyticsQuery), "query %+v should be rejected", q)
+	}
+}
+
+func TestAnalyticsExport_CSVAndXLSX(t *testing.T) {
+	svc := NewAnalyticsService(newAnalyticsQueryFixture())
+	q := AnalyticsQuery{StartDate: "2026-03-01", EndDate: "2026-03-02", Dimensions: []string{"voyage"}}
+
+	data, err := svc.Export(context.Background(), q, "csv")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, "bucket,voyage_id,voyage_code,gmv_cents,orders,paid_orders,refund_orders,refund_rate,sold_cabins,capacity,load_factor,aov_cents", lines[0])
+	assert.True(t, strings.HasPrefix(lines[len(lines)-1], "total,"))
+
+	data, err = svc.Export(context.Background(), q, "xlsx")
+	require.NoError(t, err)
+	_, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	_, err = svc.Export(context.Background(), q, "pdf")
+	assert.True(t, errors.Is(err, ErrInvalidAnalyticsQuery))
+}
@@ -28,6 +28,12 @@ func (f fakeAnalyticsRepo) InventoryOverview(_ context.Context) (*domain.Invento
 func (f fakeAnalyticsRepo) PageViewStats(_ context.Context) ([]domain.PageViewData, error) {
 	return nil, nil
 }
+func (f fakeAnalyticsRepo) ListBookingFacts(_ context.Context, _ domain.AnalyticsFactFilter) ([]domain.AnalyticsBookingFact, error) {
+	return nil, nil
+}
+func (f fakeAnalyticsRepo) ListCapacityFacts(_ context.Context, _ []int64) ([]domain.AnalyticsCapacityFact, error) {
+	return nil, nil
+}
 
 func TestAnalyticsTodaySales(t *testing.T) {
 	svc := NewAnalyticsService(fakeAnalyticsRepo{})
@@ -2,27 +2,78 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+var (
+	ErrStaffInvalidCredentials     = errors.New("invalid credentials")
+	ErrStaffAccountDisabled        = errors.New("account disabled")
+	ErrStaffAccountLocked          = errors.New("account temporarily locked")
+	ErrStaffNotFound               = errors.New("staff not found")
+	ErrStaffPasswordPolicy         = errors.New("password does not meet policy")
+	ErrStaffPasswordReused         = errors.New("password was used recently")
+	ErrStaffPasswordChangeRequired = errors.New("password change required")
+	ErrStaffResetTokenInvalid      = errors.New("reset token is invalid or expired")
+	ErrStaffTOTPRequired           = errors.New("totp code required")
+	ErrStaffTOTPInvalid            = errors.New("invalid totp code")
+	ErrStaffTOTPEnrollmentRequired = errors.New("totp enrollment required")
+	ErrStaffTOTPAlreadyEnabled     = errors.New("totp already enabled")
+	ErrStaffTOTPRequiredByRole     = errors.New("totp is mandatory for this role")
+)
+
+// StaffSecurityAuditLogger 记录管理员发起的密码重置、二次验证重置等安全操作。
+type StaffSecurityAuditLogger interface {
+	LogSecurityEvent(ctx context.Context, operatorID, targetStaffID int64, operation, details string) error
+}
+
+// StaffManageGuard 校验操作者能否管理目标员工，受公司范围限制的操作者不能管理范围外的员工。
+type StaffManageGuard interface {
+	EnsureManageable(ctx context.Context, staffID int64) error
+}
+
 // AuthService 提供员工认证相关的业务逻辑，
 // 包括密码哈希验证、JWT 令牌生成和员工登录功能。
+// 配置 StaffSecurityRepository 后额外启用登录锁定、密码有效期与历史校验、一次性重置令牌和 TOTP 二次验证。
 type AuthService struct {
 	staffRepo   domain.StaffRepository // 员工数据仓储接口
 	jwtSecret   string                 // JWT 签名密钥
 	expireHours int                    // JWT 过期时间（小时）
+
+	security domain.StaffSecurityRepository // 账号安全状态仓储，nil 时仅校验密码
+	policy   StaffSecurityPolicy
+	audit    StaffSecurityAuditLogger
+	guard    StaffManageGuard
 }
 
 // NewAuthService 创建认证服务实例，通过依赖注入传入员工仓储和 JWT 配置。
 func NewAuthService(staffRepo domain.StaffRepository, jwtSecret string, expireHours int) *AuthService {
-	return &AuthService{staffRepo: staffRepo, jwtSecret: jwtSecret, expireHours: expireHours}
+	return &AuthService{staffRepo: staffRepo, jwtSecret: jwtSecret, expireHours: expireHours, policy: DefaultStaffSecurityPolicy()}
+}
+
+// NewAuthServiceWithSecurity 创建启用账号安全策略的认证服务；audit 可为 nil。
+func NewAuthServiceWithSecurity(staffRepo domain.StaffRepository, security domain.StaffSecurityRepository, policy StaffSecurityPolicy, audit StaffSecurityAuditLogger, jwtSecret string, expireHours int) *AuthService {
+	return &AuthService{
+		staffRepo:   staffRepo,
+		jwtSecret:   jwtSecret,
+		expireHours: expireHours,
+		security:    security,
+		policy:      policy.withDefaults(),
+		audit:       audit,
+	}
 }
 
 // HashPassword 使用 bcrypt 算法对明文密码进行哈希处理。
@@ -48,21 +99,79 @@ func GenerateJWT(staffID int64, roles []string, secret string, expireHours int)
 	return token.SignedString([]byte(secret))
 }
 
+// StaffLoginInput 是员工登录的完整输入：启用二次验证时需附带 TOTPCode，
+// 密码过期或需首次修改时需附带 NewPassword，校验通过后在同一次登录中完成改密。
+type StaffLoginInput struct {
+	Username    string
+	Password    string
+	TOTPCode    string
+	NewPassword string
+}
+
+// TOTPEnrollment 是开始绑定 TOTP 时返回的密钥与 otpauth 链接。
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// StaffPasswordReset 是管理员发起重置后返回的一次性令牌，明文只在此处出现一次。
+type StaffPasswordReset struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // Login 验证员工凭据并返回签名后的 JWT 令牌及其过期时间。
 // 验证流程：查找用户 → 检查账户状态 → 验证密码 → 签发令牌。
 func (s *AuthService) Login(ctx context.Context, username, password string) (string, time.Time, error) {
-	// 根据用户名查找员工
-	staff, err := s.staffRepo.GetByUsername(ctx, username)
+	return s.LoginStaff(ctx, StaffLoginInput{Username: username, Password: password})
+}
+
+// LoginStaff 执行完整的员工登录流程：
+// 账号状态与锁定检查 → 密码校验 → 二次验证码校验 → 密码有效期检查 → 消耗验证码 → 改密与首次绑定确认 → 记录登录并签发令牌。
+// 密码或验证码错误都会累计失败次数，达到上限后账号被临时锁定；已使用过的验证码不会改动任何账号状态。
+func (s *AuthService) LoginStaff(ctx context.Context, in StaffLoginInput) (string, time.Time, error) {
+	now := s.now()
+	staff, err := s.authenticate(ctx, in.Username, in.Password, now)
 	if err != nil {
-		return "", time.Time{}, errors.New("invalid credentials")
+		return "", time.Time{}, err
 	}
-	// 检查账户是否已启用
-	if staff.Status != 1 {
-		return "", time.Time{}, errors.New("account disabled")
-	}
-	// 验证密码
-	if !VerifyPassword(staff.PasswordHash, password) {
-		return "", time.Time{}, errors.New("invalid credentials")
+
+	if s.security != nil {
+		step, activate, err := s.checkSecondFactor(ctx, staff, in.TOTPCode, now)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		rotate := s.passwordExpired(staff, now)
+		if rotate {
+			// 先做只读校验，缺少或不合规的新密码不会消耗验证码
+			if in.NewPassword == "" {
+				return "", time.Time{}, ErrStaffPasswordChangeRequired
+			}
+			if err := s.checkNewPassword(ctx, staff, in.NewPassword); err != nil {
+				return "", time.Time{}, err
+			}
+		}
+		// 验证码必须在任何状态变更（改密、激活二次验证）之前消耗，重放的验证码不能改动账号
+		if step >= 0 {
+			if ok, err := s.security.ConsumeTOTPStep(ctx, staff.ID, step); err != nil {
+				return "", time.Time{}, err
+			} else if !ok {
+				return "", time.Time{}, ErrStaffTOTPInvalid
+			}
+		}
+		if rotate {
+			if err := s.setPassword(ctx, staff, in.NewPassword, now); err != nil {
+				return "", time.Time{}, err
+			}
+		}
+		if activate {
+			if err := s.security.SetTOTP(ctx, staff.ID, staff.TOTPSecret, &now); err != nil {
+				return "", time.Time{}, err
+			}
+		}
+		if err := s.security.RecordLoginSuccess(ctx, staff.ID, now); err != nil {
+			return "", time.Time{}, err
+		}
 	}
 
 	// TODO: 从数据库 staff_roles 表加载角色（Sprint 2）
@@ -82,3 +191,346 @@ func (s *AuthService) GetProfile(ctx context.Context, subStr string) (*domain.St
 	}
 	return s.staffRepo.GetByID(ctx, id)
 }
+
+// ChangePassword 由已登录员工修改自己的密码，需要校验当前密码。
+func (s *AuthService) ChangePassword(ctx context.Context, staffID int64, oldPassword, newPassword string) error {
+	if s.security == nil {
+		return errors.New("staff security store not configured")
+	}
+	now := s.now()
+	staff, err := s.getStaff(ctx, staffID)
+	if err != nil {
+		return err
+	}
+	if err := checkLocked(staff, now); err != nil {
+		return err
+	}
+	if !VerifyPassword(staff.PasswordHash, oldPassword) {
+		return s.loginFailed(ctx, staff, now)
+	}
+	return s.setPassword(ctx, staff, newPassword, now)
+}
+
+// SetStaffManageGuard 设置管理员重置密码与二次验证前的目标员工校验。
+func (s *AuthService) SetStaffManageGuard(guard StaffManageGuard) {
+	s.guard = guard
+}
+
+// IssuePasswordReset 由管理员为员工签发一次性重置令牌（也用于新账号的首次设置密码），
+// 同一员工此前未使用的令牌随之作废。库中只保存令牌摘要。
+func (s *AuthService) IssuePasswordReset(ctx context.Context, staffID, operatorID int64) (*StaffPasswordReset, error) {
+	if s.security == nil {
+		return nil, errors.New("staff security store not configured")
+	}
+	now := s.now()
+	if _, err := s.getStaff(ctx, staffID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureManageable(ctx, staffID); err != nil {
+		return nil, err
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	record := &domain.StaffPasswordResetToken{
+		StaffID:   staffID,
+		TokenHash: hashResetToken(token),
+		CreatedBy: operatorID,
+		ExpiresAt: now.Add(s.policy.ResetTokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.security.CreateResetToken(ctx, record); err != nil {
+		return nil, err
+	}
+	s.logSecurityEvent(ctx, operatorID, staffID, "reset_password", fmt.Sprintf("expires_at=%s", record.ExpiresAt.Format(time.RFC3339)))
+	return &StaffPasswordReset{Token: token, ExpiresAt: record.ExpiresAt}, nil
+}
+
+// ResetPassword 使用一次性令牌设置新密码，成功后令牌失效并解除登录锁定。
+// 新密码先通过策略校验再消耗令牌，避免因密码不合规而浪费令牌。
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if s.security == nil {
+		return errors.New("staff security store not configured")
+	}
+	now := s.now()
+	hash := hashResetToken(token)
+	record, err := s.security.FindResetToken(ctx, hash, now)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrStaffResetTokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+	staff, err := s.getStaff(ctx, record.StaffID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkNewPassword(ctx, staff, newPassword); err != nil {
+		return err
+	}
+	if _, err := s.security.ConsumeResetToken(ctx, hash, now); errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrStaffResetTokenInvalid
+	} else if err != nil {
+		return err
+	}
+	return s.setPassword(ctx, staff, newPassword, now)
+}
+
+// BeginTOTPEnrollment 校验账号密码后生成待确认的 TOTP 密钥；
+// 在强制二次验证的角色首次登录前也可调用。密钥在登录或 ActivateTOTP 校验通过首个验证码后才正式启用。
+func (s *AuthService) BeginTOTPEnrollment(ctx context.Context, username, password string) (*TOTPEnrollment, error) {
+	if s.security == nil {
+		return nil, errors.New("staff security store not configured")
+	}
+	staff, err := s.authenticate(ctx, username, password, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if staff.TOTPEnabledAt != nil {
+		return nil, ErrStaffTOTPAlreadyEnabled
+	}
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.security.SetTOTP(ctx, staff.ID, secret, nil); err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{Secret: secret, URI: TOTPProvisioningURI(s.policy.TOTPIssuer, staff.Username, secret)}, nil
+}
+
+// ActivateTOTP 由已登录员工提交首个验证码以启用二次验证。
+func (s *AuthService) ActivateTOTP(ctx context.Context, staffID int64, code string) error {
+	if s.security == nil {
+		return errors.New("staff security store not configured")
+	}
+	now := s.now()
+	staff, err := s.getStaff(ctx, staffID)
+	if err != nil {
+		return err
+	}
+	if staff.TOTPEnabledAt != nil {
+		return ErrStaffTOTPAlreadyEnabled
+	}
+	if staff.TOTPSecret == "" {
+		return ErrStaffTOTPEnrollmentRequired
+	}
+	step, ok := VerifyTOTP(staff.TOTPSecret, code, now)
+	if !ok {
+		return ErrStaffTOTPInvalid
+	}
+	if ok, err := s.security.ConsumeTOTPStep(ctx, staff.ID, step); err != nil {
+		return err
+	} else if !ok {
+		return ErrStaffTOTPInvalid
+	}
+	return s.security.SetTOTP(ctx, staff.ID, staff.TOTPSecret, &now)
+}
+
+// DisableTOTP 由员工本人关闭二次验证，需同时提供密码和当前验证码；强制二次验证的角色不允许关闭。
+func (s *AuthService) DisableTOTP(ctx context.Context, staffID int64, password, code string) error {
+	if s.security == nil {
+		return errors.New("staff security store not configured")
+	}
+	now := s.now()
+	staff, err := s.getStaff(ctx, staffID)
+	if err != nil {
+		return err
+	}
+	if s.policy.RequiresTOTP(staff.Role) {
+		return ErrStaffTOTPRequiredByRole
+	}
+	if err := checkLocked(staff, now); err != nil {
+		return err
+	}
+	if !VerifyPassword(staff.PasswordHash, password) {
+		return s.loginFailed(ctx, staff, now)
+	}
+	if staff.TOTPEnabledAt == nil {
+		return ErrStaffTOTPEnrollmentRequired
+	}
+	step, ok := VerifyTOTP(staff.TOTPSecret, code, now)
+	if !ok {
+		if err := s.loginFailed(ctx, staff, now); errors.Is(err, ErrStaffAccountLocked) {
+			return err
+		}
+		return ErrStaffTOTPInvalid
+	}
+	if ok, err := s.security.ConsumeTOTPStep(ctx, staff.ID, step); err != nil {
+		return err
+	} else if !ok {
+		return ErrStaffTOTPInvalid
+	}
+	return s.security.SetTOTP(ctx, staff.ID, "", nil)
+}
+
+// ResetTOTP 由管理员清除员工的二次验证绑定（如丢失手机），强制角色的员工下次登录需重新绑定。
+func (s *AuthService) ResetTOTP(ctx context.Context, staffID, operatorID int64) error {
+	if s.security == nil {
+		return errors.New("staff security store not configured")
+	}
+	if _, err := s.getStaff(ctx, staffID); err != nil {
+		return err
+	}
+	if err := s.ensureManageable(ctx, staffID); err != nil {
+		return err
+	}
+	if err := s.security.SetTOTP(ctx, staffID, "", nil); err != nil {
+		return err
+	}
+	s.logSecurityEvent(ctx, operatorID, staffID, "reset_totp", "")
+	return nil
+}
+
+// authenticate 检查账号状态与锁定并校验密码，密码错误时累计失败次数。
+func (s *AuthService) authenticate(ctx context.Context, username, password string, now time.Time) (*domain.Staff, error) {
+	// 根据用户名查找员工
+	staff, err := s.staffRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrStaffInvalidCredentials
+	}
+	// 检查账户是否已启用
+	if staff.Status != 1 {
+		return nil, ErrStaffAccountDisabled
+	}
+	if err := checkLocked(staff, now); err != nil {
+		return nil, err
+	}
+	// 验证密码
+	if !VerifyPassword(staff.PasswordHash, password) {
+		return nil, s.loginFailed(ctx, staff, now)
+	}
+	return staff, nil
+}
+
+// checkLocked 在校验密码或验证码之前拒绝处于锁定期的账号，避免锁定期内继续猜测凭据。
+func checkLocked(staff *domain.Staff, now time.Time) error {
+	if staff.LockedUntil != nil && now.Before(*staff.LockedUntil) {
+		return fmt.Errorf("%w until %s", ErrStaffAccountLocked, staff.LockedUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkSecondFactor 校验 TOTP 验证码，返回需要记录的时间步（-1 表示无需记录）以及是否需要正式启用待确认的密钥。
+func (s *AuthService) checkSecondFactor(ctx context.Context, staff *domain.Staff, code string, now time.Time) (int64, bool, error) {
+	enabled := staff.TOTPEnabledAt != nil
+	if !enabled && (staff.TOTPSecret == "" || code == "") {
+		if s.policy.RequiresTOTP(staff.Role) {
+			return -1, false, ErrStaffTOTPEnrollmentRequired
+		}
+		return -1, false, nil
+	}
+	if code == "" {
+		return -1, false, ErrStaffTOTPRequired
+	}
+	step, ok := VerifyTOTP(staff.TOTPSecret, code, now)
+	if !ok {
+		if err := s.loginFailed(ctx, staff, now); errors.Is(err, ErrStaffAccountLocked) {
+			return -1, false, err
+		}
+		return -1, false, ErrStaffTOTPInvalid
+	}
+	return step, !enabled, nil
+}
+
+// loginFailed 累计一次失败，触发锁定时返回 ErrStaffAccountLocked，否则返回 ErrStaffInvalidCredentials。
+func (s *AuthService) loginFailed(ctx context.Context, staff *domain.Staff, now time.Time) error {
+	if s.security == nil {
+		return ErrStaffInvalidCredentials
+	}
+	lockedUntil, err := s.security.RecordLoginFailure(ctx, staff.ID, s.policy.MaxFailedLogins, now.Add(s.policy.LockDuration))
+	if err != nil {
+		logger.FromContext(ctx).Error("auth: record login failure failed", zap.Int64("staff_id", staff.ID), zap.Error(err))
+		return ErrStaffInvalidCredentials
+	}
+	if lockedUntil != nil {
+		return fmt.Errorf("%w until %s", ErrStaffAccountLocked, lockedUntil.Format(time.RFC3339))
+	}
+	return ErrStaffInvalidCredentials
+}
+
+// passwordExpired 判断是否需要在本次登录时修改密码；从未记录改密时间的存量账号不视为过期。
+func (s *AuthService) passwordExpired(staff *domain.Staff, now time.Time) bool {
+	if staff.MustChangePassword {
+		return true
+	}
+	return staff.PasswordChangedAt != nil && !now.Before(staff.PasswordChangedAt.Add(s.policy.PasswordMaxAge))
+}
+
+// checkNewPassword 校验复杂度，并确认新密码与当前密码及最近的历史密码都不相同。
+func (s *AuthService) checkNewPassword(ctx context.Context, staff *domain.Staff, password string) error {
+	if err := s.policy.ValidatePassword(password, staff.Username); err != nil {
+		return err
+	}
+	if staff.PasswordHash != "" && VerifyPassword(staff.PasswordHash, password) {
+		return ErrStaffPasswordReused
+	}
+	history, err := s.security.ListPasswordHistory(ctx, staff.ID, s.policy.PasswordHistory)
+	if err != nil {
+		return err
+	}
+	for _, hash := range history {
+		if VerifyPassword(hash, password) {
+			return ErrStaffPasswordReused
+		}
+	}
+	return nil
+}
+
+func (s *AuthService) setPassword(ctx context.Context, staff *domain.Staff, password string, now time.Time) error {
+	if err := s.checkNewPassword(ctx, staff, password); err != nil {
+		return err
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.security.UpdatePassword(ctx, staff.ID, hash, now, s.policy.PasswordHistory); err != nil {
+		return err
+	}
+	staff.PasswordHash, staff.PasswordChangedAt, staff.MustChangePassword = hash, &now, false
+	return nil
+}
+
+func (s *AuthService) getStaff(ctx context.Context, id int64) (*domain.Staff, error) {
+	staff, err := s.staffRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && staff == nil) {
+		return nil, ErrStaffNotFound
+	}
+	return staff, err
+}
+
+// ensureManageable 在受公司范围限制的上下文中校验目标员工；未配置校验时拒绝受限操作者，避免越权接管账号。
+func (s *AuthService) ensureManageable(ctx context.Context, staffID int64) error {
+	if s.guard != nil {
+		return s.guard.EnsureManageable(ctx, staffID)
+	}
+	if _, scoped := domain.CompanyScopeFromContext(ctx); scoped {
+		return ErrStaffManageDenied
+	}
+	return nil
+}
+
+func (s *AuthService) logSecurityEvent(ctx context.Context, operatorID, targetID int64, operation, details string) {
+	if s.audit == nil {
+		return
+	}
+	if err := s.audit.LogSecurityEvent(ctx, operatorID, targetID, operation, details); err != nil {
+		logger.FromContext(ctx).Error("auth: write security audit log failed", zap.String("operation", operation), zap.Int64("target_staff_id", targetID), zap.Error(err))
+	}
+}
+
+func (s *AuthService) now() time.Time {
+	if s.policy.Now != nil {
+		return s.policy.Now()
+	}
+	return time.Now()
+}
+
+// hashResetToken 计算重置令牌的 SHA-256 摘要，令牌本身具备足够熵，无需加盐。
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 	"github.com/cruisebooking/backend/internal/repository"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestAuthService_Full(t *testing.T) {
@@ -39,3 +43,326 @@ func TestAuthService_Full(t *testing.T) {
 
 	_, _ = svc.GetProfile(ctx, "1")
 }
+
+type fakeStaffSecurityAudit struct{ operations []string }
+
+func (a *fakeStaffSecurityAudit) LogSecurityEvent(_ context.Context, _, _ int64, operation, _ string) error {
+	a.operations = append(a.operations, operation)
+	return nil
+}
+
+// newSecureAuthService 基于内存 SQLite 创建启用账号安全策略的认证服务，now 指向可由测试推进的时钟。
+func newSecureAuthService(t *testing.T, now *time.Time) (*AuthService, *repository.StaffRepository, *fakeStaffSecurityAudit) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.Staff{}, &domain.StaffPasswordHistory{}, &domain.StaffPasswordResetToken{}); err != nil {
+		t.Fatal(err)
+	}
+	staffRepo := repository.NewStaffRepository(db)
+	audit := &fakeStaffSecurityAudit{}
+	policy := StaffSecurityPolicy{MaxFailedLogins: 3, LockDuration: 10 * time.Minute, PasswordHistory: 2, Now: func() time.Time { return *now }}
+	return NewAuthServiceWithSecurity(staffRepo, repository.NewStaffSecurityRepository(db), policy, audit, "secret", 1), staffRepo, audit
+}
+
+func createStaff(t *testing.T, repo *repository.StaffRepository, staff *domain.Staff, password string) {
+	t.Helper()
+	staff.PasswordHash, _ = HashPassword(password)
+	staff.Status = 1
+	if err := repo.Create(context.Background(), staff); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthServiceLocksAccountAfterRepeatedFailures(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, _ := newSecureAuthService(t, &now)
+	ctx := context.Background()
+	createStaff(t, repo, &domain.Staff{Username: "op", Role: "operator"}, "Initial-Pass1")
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := svc.Login(ctx, "op", "wrong"); !errors.Is(err, ErrStaffInvalidCredentials) {
+			t.Fatalf("attempt %d: expected invalid credentials, got %v", i+1, err)
+		}
+	}
+	if _, _, err := svc.Login(ctx, "op", "wrong"); !errors.Is(err, ErrStaffAccountLocked) {
+		t.Fatalf("third failure should lock the account, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "op", "Initial-Pass1"); !errors.Is(err, ErrStaffAccountLocked) {
+		t.Fatalf("correct password must be refused while locked, got %v", err)
+	}
+	now = now.Add(10 * time.Minute)
+	if _, _, err := svc.Login(ctx, "op", "Initial-Pass1"); err != nil {
+		t.Fatalf("login should succeed after the lock expires: %v", err)
+	}
+}
+
+func TestAuthServiceForcedRotationAndPasswordHistory(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, _ := newSecureAuthService(t, &now)
+	ctx := context.Background()
+	staff := &domain.Staff{Username: "clerk", Role: "operator", MustChangePassword: true}
+	createStaff(t, repo, staff, "Initial-Pass1")
+
+	if _, _, err := svc.Login(ctx, "clerk", "Initial-Pass1"); !errors.Is(err, ErrStaffPasswordChangeRequired) {
+		t.Fatalf("expected ErrStaffPasswordChangeRequired, got %v", err)
+	}
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "clerk", Password: "Initial-Pass1", NewPassword: "Initial-Pass1"}); !errors.Is(err, ErrStaffPasswordReused) {
+		t.Fatalf("current password cannot be reused, got %v", err)
+	}
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "clerk", Password: "Initial-Pass1", NewPassword: "clerk-Pass-2024"}); !errors.Is(err, ErrStaffPasswordPolicy) {
+		t.Fatalf("password containing the username must be rejected, got %v", err)
+	}
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "clerk", Password: "Initial-Pass1", NewPassword: "Second-Pass2"}); err != nil {
+		t.Fatalf("rotation during login should succeed: %v", err)
+	}
+
+	if err := svc.ChangePassword(ctx, staff.ID, "Second-Pass2", "Initial-Pass1"); !errors.Is(err, ErrStaffPasswordReused) {
+		t.Fatalf("recent passwords cannot be reused, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, staff.ID, "wrong", "Third-Pass3"); !errors.Is(err, ErrStaffInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, staff.ID, "Second-Pass2", "Third-Pass3"); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(91 * 24 * time.Hour)
+	if _, _, err := svc.Login(ctx, "clerk", "Third-Pass3"); !errors.Is(err, ErrStaffPasswordChangeRequired) {
+		t.Fatalf("expired passwords must be rotated, got %v", err)
+	}
+}
+
+func TestAuthServiceAdminResetTokenIsSingleUse(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, audit := newSecureAuthService(t, &now)
+	ctx := context.Background()
+	staff := &domain.Staff{Username: "newbie", Role: "support"}
+	if err := repo.Create(ctx, staff); err != nil {
+		t.Fatal(err)
+	}
+
+	reset, err := svc.IssuePasswordReset(ctx, staff.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.ResetPassword(ctx, reset.Token, "weak"); !errors.Is(err, ErrStaffPasswordPolicy) {
+		t.Fatalf("expected ErrStaffPasswordPolicy, got %v", err)
+	}
+	if err := svc.ResetPassword(ctx, reset.Token, "Fresh-Pass1"); err != nil {
+		t.Fatalf("a rejected password must not consume the token: %v", err)
+	}
+	if err := svc.ResetPassword(ctx, reset.Token, "Another-Pass2"); !errors.Is(err, ErrStaffResetTokenInvalid) {
+		t.Fatalf("token must be single-use, got %v", err)
+	}
+	if _, _, err := svc.Login(ctx, "newbie", "Fresh-Pass1"); err != nil {
+		t.Fatalf("login with the reset password failed: %v", err)
+	}
+
+	expired, _ := svc.IssuePasswordReset(ctx, staff.ID, 1)
+	now = now.Add(25 * time.Hour)
+	if err := svc.ResetPassword(ctx, expired.Token, "Another-Pass2"); !errors.Is(err, ErrStaffResetTokenInvalid) {
+		t.Fatalf("expired token must be rejected, got %v", err)
+	}
+	if _, err := svc.IssuePasswordReset(ctx, 404, 1); !errors.Is(err, ErrStaffNotFound) {
+		t.Fatalf("expected ErrStaffNotFound, got %v", err)
+	}
+	if len(audit.operations) != 2 || audit.operations[0] != "reset_password" {
+		t.Fatalf("expected reset operations to be audited, got %v", audit.operations)
+	}
+}
+
+func TestAuthServiceTOTPEnforcedForRole(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, audit := newSecureAuthService(t, &now)
+	ctx := context.Background()
+	staff := &domain.Staff{Username: "cfo", Role: "finance"}
+	createStaff(t, repo, staff, "Finance-Pass1")
+
+	if _, _, err := svc.Login(ctx, "cfo", "Finance-Pass1"); !errors.Is(err, ErrStaffTOTPEnrollmentRequired) {
+		t.Fatalf("finance staff must enrol TOTP first, got %v", err)
+	}
+	enrollment, err := svc.BeginTOTPEnrollment(ctx, "cfo", "Finance-Pass1")
+	if err != nil || !strings.HasPrefix(enrollment.URI, "otpauth://totp/") {
+		t.Fatalf("unexpected enrollment %+v (%v)", enrollment, err)
+	}
+	code, _ := TOTPCode(enrollment.Secret, now)
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "cfo", Password: "Finance-Pass1", TOTPCode: code}); err != nil {
+		t.Fatalf("first valid code should activate TOTP and log in: %v", err)
+	}
+	if _, err := svc.BeginTOTPEnrollment(ctx, "cfo", "Finance-Pass1"); !errors.Is(err, ErrStaffTOTPAlreadyEnabled) {
+		t.Fatalf("expected ErrStaffTOTPAlreadyEnabled, got %v", err)
+	}
+
+	if _, _, err := svc.Login(ctx, "cfo", "Finance-Pass1"); !errors.Is(err, ErrStaffTOTPRequired) {
+		t.Fatalf("expected ErrStaffTOTPRequired, got %v", err)
+	}
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "cfo", Password: "Finance-Pass1", TOTPCode: code}); !errors.Is(err, ErrStaffTOTPInvalid) {
+		t.Fatalf("a used code must not be replayed, got %v", err)
+	}
+	now = now.Add(30 * time.Second)
+	next, _ := TOTPCode(enrollment.Secret, now)
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "cfo", Password: "Finance-Pass1", TOTPCode: next}); err != nil {
+		t.Fatalf("next code should be accepted: %v", err)
+	}
+	if err := svc.DisableTOTP(ctx, staff.ID, "Finance-Pass1", next); !errors.Is(err, ErrStaffTOTPRequiredByRole) {
+		t.Fatalf("mandatory TOTP cannot be disabled, got %v", err)
+	}
+
+	if err := svc.ResetTOTP(ctx, staff.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.Login(ctx, "cfo", "Finance-Pass1"); !errors.Is(err, ErrStaffTOTPEnrollmentRequired) {
+		t.Fatalf("after an admin reset the staff must re-enrol, got %v", err)
+	}
+	if len(audit.operations) != 1 || audit.operations[0] != "reset_totp" {
+		t.Fatalf("expected reset_totp audit, got %v", audit.operations)
+	}
+}
+
+func TestAuthServiceOptionalTOTPForOtherRoles(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, _ := newSecureAuthService(t, &now)
+	ctx := context.Background()
+	staff := &domain.Staff{Username: "agent", Role: "support"}
+	createStaff(t, repo, staff, "Support-Pass1")
+
+	if _, _, err := svc.Login(ctx, "agent", "Support-Pass1"); err != nil {
+		t.Fatalf("TOTP is optional for support: %v", err)
+	}
+	enrollment, err := svc.BeginTOTPEnrollment(ctx, "agent", "Support-Pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.Login(ctx, "agent", "Support-Pass1"); err != nil {
+		t.Fatalf("a pending enrollment must not block login: %v", err)
+	}
+	if err := svc.ActivateTOTP(ctx, staff.ID, "000000"); !errors.Is(err, ErrStaffTOTPInvalid) {
+		t.Fatalf("expected ErrStaffTOTPInvalid, got %v", err)
+	}
+	code, _ := TOTPCode(enrollment.Secret, now)
+	if err := svc.ActivateTOTP(ctx, staff.ID, code); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.Login(ctx, "agent", "Support-Pass1"); !errors.Is(err, ErrStaffTOTPRequired) {
+		t.Fatalf("enabled TOTP must be required, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	code, _ = TOTPCode(enrollment.Secret, now)
+	if err := svc.DisableTOTP(ctx, staff.ID, "Support-Pass1", code); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.Login(ctx, "agent", "Support-Pass1"); err != nil {
+		t.Fatalf("login without TOTP after disabling: %v", err)
+	}
+}
+
+func TestAuthServiceReplayedTOTPCodeLeavesStateUnchanged(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, _ := newSecureAuthService(t, &now)
+	ctx := context.Background()
+	staff := &domain.Staff{Username: "teller", Role: "support", MustChangePassword: true}
+	createStaff(t, repo, staff, "Initial-Pass1")
+
+	enrollment, err := svc.BeginTOTPEnrollment(ctx, "teller", "Initial-Pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := TOTPCode(enrollment.Secret, now)
+	if err := svc.ActivateTOTP(ctx, staff.ID, code); err != nil {
+		t.Fatal(err)
+	}
+	before, err := repo.GetByID(ctx, staff.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "teller", Password: "Initial-Pass1", TOTPCode: code, NewPassword: "Second-Pass2"}); !errors.Is(err, ErrStaffTOTPInvalid) {
+		t.Fatalf("a used code must not be replayed, got %v", err)
+	}
+	after, err := repo.GetByID(ctx, staff.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.PasswordHash != before.PasswordHash || !after.MustChangePassword {
+		t.Fatal("a replayed code must not rotate the password")
+	}
+	if after.TOTPSecret != before.TOTPSecret || after.TOTPEnabledAt == nil || !after.TOTPEnabledAt.Equal(*before.TOTPEnabledAt) {
+		t.Fatal("a replayed code must not change the TOTP binding")
+	}
+
+	now = now.Add(30 * time.Second)
+	next, _ := TOTPCode(enrollment.Secret, now)
+	if _, _, err := svc.LoginStaff(ctx, StaffLoginInput{Username: "teller", Password: "Initial-Pass1", TOTPCode: next, NewPassword: "Second-Pass2"}); err != nil {
+		t.Fatalf("a fresh code should rotate the password and log in: %v", err)
+	}
+}
+
+func TestAuthServiceScopedOperatorCannotTakeOverPlatformAdmin(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, _ := newSecureAuthService(t, &now)
+	admin := &domain.Staff{Username: "root", Role: "super_admin", AllCompanies: true}
+	createStaff(t, repo, admin, "Str0ng-Passw0rd")
+	scoped := domain.WithCompanyScope(context.Background(), []int64{1})
+
+	if _, err := svc.IssuePasswordReset(scoped, admin.ID, 9); !errors.Is(err, ErrStaffManageDenied) {
+		t.Fatalf("scoped operators must be refused without a guard, got %v", err)
+	}
+
+	store := &fakeStaffCompanyStore{scopes: map[int64]domain.StaffCompanyScope{admin.ID: {AllCompanies: true}}}
+	staffSvc := NewStaffService(newFakeStaffRepo())
+	staffSvc.SetCompanyScope(store, fakeScopedCompanyLookup{})
+	svc.SetStaffManageGuard(staffSvc)
+
+	if _, err := svc.IssuePasswordReset(scoped, admin.ID, 9); !errors.Is(err, ErrStaffManageDenied) {
+		t.Fatalf("expected ErrStaffManageDenied for password reset, got %v", err)
+	}
+	if err := svc.ResetTOTP(scoped, admin.ID, 9); !errors.Is(err, ErrStaffManageDenied) {
+		t.Fatalf("expected ErrStaffManageDenied for totp reset, got %v", err)
+	}
+	if _, err := svc.IssuePasswordReset(context.Background(), admin.ID, 9); err != nil {
+		t.Fatalf("unrestricted admins must still reset platform admins: %v", err)
+	}
+}
+
+func TestAuthServiceSessionOperationsHonourLock(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	svc, repo, _ := newSecureAuthService(t, &now)
+	ctx := context.Background()
+	staff := &domain.Staff{Username: "desk", Role: "support"}
+	createStaff(t, repo, staff, "Support-Pass1")
+	enrollment, err := svc.BeginTOTPEnrollment(ctx, "desk", "Support-Pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := TOTPCode(enrollment.Secret, now)
+	if err := svc.ActivateTOTP(ctx, staff.ID, code); err != nil {
+		t.Fatal(err)
+	}
+
+	// 关闭二次验证时输错验证码同样计入失败次数
+	for i := 0; i < 2; i++ {
+		if err := svc.DisableTOTP(ctx, staff.ID, "Support-Pass1", "000000"); !errors.Is(err, ErrStaffTOTPInvalid) {
+			t.Fatalf("attempt %d: expected ErrStaffTOTPInvalid, got %v", i+1, err)
+		}
+	}
+	if err := svc.ChangePassword(ctx, staff.ID, "wrong", "Fresh-Pass2"); !errors.Is(err, ErrStaffAccountLocked) {
+		t.Fatalf("third failure should lock the account, got %v", err)
+	}
+	if err := svc.ChangePassword(ctx, staff.ID, "Support-Pass1", "Fresh-Pass2"); !errors.Is(err, ErrStaffAccountLocked) {
+		t.Fatalf("correct password must be refused while locked, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	code, _ = TOTPCode(enrollment.Secret, now)
+	if err := svc.DisableTOTP(ctx, staff.ID, "Support-Pass1", code); !errors.Is(err, ErrStaffAccountLocked) {
+		t.Fatalf("disabling TOTP must be refused while locked, got %v", err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if err := svc.ChangePassword(ctx, staff.ID, "Support-Pass1", "Fresh-Pass2"); err != nil {
+		t.Fatalf("change password should succeed after the lock expires: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+// InMemoryAuthStateStore 提供进程内的认证风控状态存储，仅适用于单实例部署、本地开发和测试环境。
+// 过期键在访问时惰性删除，PurgeExpired 负责清理从未再被访问的键。
+type InMemoryAuthStateStore struct {
+	mu     sync.Mutex
+	states map[string]memoryAuthState
+}
+
+type memoryAuthState struct {
+	count     int64
+	value     string
+	expiresAt time.Time
+}
+
+var _ domain.AuthStateStore = (*InMemoryAuthStateStore)(nil)
+
+// NewInMemoryAuthStateStore 创建内存风控状态存储。
+func NewInMemoryAuthStateStore() *InMemoryAuthStateStore {
+	return &InMemoryAuthStateStore{states: make(map[string]memoryAuthState)}
+}
+
+// live 返回 now 时刻仍有效的状态，已过期的键会被顺带删除；调用方需持有锁。
+func (s *InMemoryAuthStateStore) live(key string, now time.Time) (memoryAuthState, bool) {
+	state, ok := s.states[key]
+	if ok && !now.Before(state.expiresAt) {
+		delete(s.states, key)
+		return memoryAuthState{}, false
+	}
+	return state, ok
+}
+
+// SetNX 仅当键不存在或已过期时写入。
+func (s *InMemoryAuthStateStore) SetNX(_ context.Context, key string, ttl time.Duration, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.live(key, now); ok {
+		return false, nil
+	}
+	s.states[key] = memoryAuthState{expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Set 写入或覆盖键并重置过期时间。
+func (s *InMemoryAuthStateStore) Set(_ context.Context, key string, ttl time.Duration, now time.Time) error {
+	s.mu.Lock()
+	s.states[key] = memoryAuthState{expiresAt: now.Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+// SetValue 写入或覆盖带取值的键并重置过期时间。
+func (s *InMemoryAuthStateStore) SetValue(_ context.Context, key, value string, ttl time.Duration, now time.Time) error {
+	s.mu.Lock()
+	s.states[key] = memoryAuthState{value: value, expiresAt: now.Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+// GetValue 返回键在 now 时刻的取值。
+func (s *InMemoryAuthStateStore) GetValue(_ context.Context, key string, now time.Time) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.live(key, now)
+	return state.value, ok, nil
+}
+
+// Exists 判断键在 now 时刻是否仍有效。
+func (s *InMemoryAuthStateStore) Exists(_ context.Context, key string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.live(key, now)
+	return ok, nil
+}
+
+// Incr 计数加一并返回新值，首次创建时设置过期时间。
+func (s *InMemoryAuthStateStore) Incr(_ context.Context, key string, ttl time.Duration, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.live(key, now)
+	if !ok {
+		state.expiresAt = now.Add(ttl)
+	}
+	state.count++
+	s.states[key] = state
+	return state.count, nil
+}
+
+// Delete 删除指定键。
+func (s *InMemoryAuthStateStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	for _, key := range keys {
+		delete(s.states, key)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// PurgeExpired 清理已过期的键，返回删除条数。
+func (s *InMemoryAuthStateStore) PurgeExpired(_ context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var purged int64
+	for key, state := range s.states {
+		if !now.Before(state.expiresAt) {
+			delete(s.states, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// AuthStateCleanupScheduler 定期清理已过期的认证风控状态，避免状态表无限增长。
+// RunOnce 返回本轮删除的记录数。
+type AuthStateCleanupScheduler struct {
+	*periodicJob
+}
+
+// NewAuthStateCleanupScheduler 创建风控状态清理调度器；interval 非正数时默认 1 分钟。
+func NewAuthStateCleanupScheduler(store domain.AuthStateStore, interval time.Duration) *AuthStateCleanupScheduler {
+	run := func(ctx context.Context) (int, error) {
+		n, err := store.PurgeExpired(ctx, time.Now())
+		return int(n), err
+	}
+	return &AuthStateCleanupScheduler{periodicJob: newPeriodicJob("auth_state_cleanup_scheduler: purge expired", interval, run)}
+}
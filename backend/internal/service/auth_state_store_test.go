@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInMemoryAuthStateStoreExpiryAndPurge(t *testing.T) {
+	store := NewInMemoryAuthStateStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	if ok, _ := store.SetNX(ctx, "a", time.Minute, now); !ok {
+		t.Fatal("expected first SetNX to succeed")
+	}
+	if ok, _ := store.SetNX(ctx, "a", time.Minute, now.Add(time.Second)); ok {
+		t.Fatal("expected SetNX on a live key to fail")
+	}
+	if ok, _ := store.SetNX(ctx, "a", time.Minute, now.Add(time.Minute)); !ok {
+		t.Fatal("expected SetNX on an expired key to succeed")
+	}
+	for want := int64(1); want <= 2; want++ {
+		if n, _ := store.Incr(ctx, "b", time.Minute, now.Add(time.Duration(want)*30*time.Second)); n != want {
+			t.Fatalf("expected counter %d, got %d", want, n)
+		}
+	}
+	if exists, _ := store.Exists(ctx, "b", now.Add(90*time.Second)); exists {
+		t.Fatal("counter should keep the expiry of its first increment")
+	}
+	_ = store.Set(ctx, "c", time.Hour, now)
+	if n, _ := store.PurgeExpired(ctx, now.Add(2*time.Minute)); n != 1 {
+		t.Fatalf("expected only the expired key to be purged, got %d", n)
+	}
+	if exists, _ := store.Exists(ctx, "c", now.Add(2*time.Minute)); !exists {
+		t.Fatal("live key must survive purge")
+	}
+}
+
+func TestUserAuthStateSharedAcrossInstances(t *testing.T) {
+	now := time.Now()
+	states := NewInMemoryAuthStateStore()
+	policy := UserAuthPolicy{
+		CodeTTL:        time.Minute,
+		ResendInterval: time.Minute,
+		MaxAttempts:    2,
+		LockDuration:   time.Hour,
+		Now:            func() time.Time { return now },
+		Accounts:       newFakeAccountStore(7),
+		States:         states,
+	}
+	codes := &fakeCodeStore{ok: false}
+	replicaA := NewUserAuthServiceWithPolicy(codes, policy)
+	replicaB := NewUserAuthServiceWithPolicy(codes, policy)
+
+	if err := replicaA.SendSMS("13800000000", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaB.SendSMS("13800000000", "1234"); !errors.Is(err, ErrSMSTooFrequent) {
+		t.Fatalf("resend interval must apply across instances, got %v", err)
+	}
+	replicaA.VerifySMS("13800000000", "bad")
+	replicaB.VerifySMS("13800000000", "bad")
+	codes.ok = true
+	if replicaA.VerifySMS("13800000000", "1234") {
+		t.Fatal("failures counted on both instances should lock the phone")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := replicaB.SendSMS("13900000000", "5678"); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaB.AuthorizeBinding(7, "13900000000", "5678"); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaA.BindAccount(context.Background(), 7, "alipay", "2088"); err != nil {
+		t.Fatalf("binding window opened on another instance should be honoured: %v", err)
+	}
+	if err := replicaB.BindAccount(context.Background(), 7, "wechat", "wx-code-7"); !errors.Is(err, ErrBindingConfirmationRequired) {
+		t.Fatalf("binding window should be consumed, got %v", err)
+	}
+}
+
+type failingAuthStateStore struct{ *InMemoryAuthStateStore }
+
+func (failingAuthStateStore) Exists(context.Context, string, time.Time) (bool, error) {
+	return false, errors.New("store down")
+}
+
+func TestUserAuthStateStoreFailureFailsClosed(t *testing.T) {
+	svc := NewUserAuthServiceWithPolicy(&fakeCodeStore{ok: true}, UserAuthPolicy{
+		Accounts: newFakeAccountStore(7),
+		States:   failingAuthStateStore{NewInMemoryAuthStateStore()},
+	})
+	if err := svc.SendSMS("13800000000", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	if svc.VerifySMS("13800000000", "1234") {
+		t.Fatal("verification must fail when the state store is unavailable")
+	}
+	if err := svc.BindAccount(context.Background(), 7, "alipay", "2088"); err == nil {
+		t.Fatal("binding must fail when the state store is unavailable")
+	}
+}
+
+func TestAuthStateCleanupSchedulerRunOnce(t *testing.T) {
+	store := NewInMemoryAuthStateStore()
+	ctx := context.Background()
+	_ = store.Set(ctx, "expired", time.Millisecond, time.Now().Add(-time.Minute))
+	_ = store.Set(ctx, "live", time.Hour, time.Now())
+
+	if n := NewAuthStateCleanupScheduler(store, time.Minute).RunOnce(ctx); n != 1 {
+		t.Fatalf("expected one purged key, got %d", n)
+	}
+}
+
+func TestAuthStateCodeStoreVerifiesAcrossInstances(t *testing.T) {
+	states := NewInMemoryAuthStateStore()
+	replicaA := NewUserAuthServiceWithPolicy(NewAuthStateCodeStore(states, time.Minute), UserAuthPolicy{States: states})
+	replicaB := NewUserAuthServiceWithPolicy(NewAuthStateCodeStore(states, time.Minute), UserAuthPolicy{States: states})
+
+	if err := replicaA.SendSMS("13800000000", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	if replicaB.VerifySMS("13800000000", "9999") {
+		t.Fatal("wrong code must be rejected")
+	}
+	if !replicaB.VerifySMS("13800000000", "1234") {
+		t.Fatal("code issued on one instance should verify on another")
+	}
+	if stored, _, _ := states.GetValue(context.Background(), smsValueKey("13800000000"), time.Now()); stored == "1234" {
+		t.Fatal("sms code must not be stored in plain text")
+	}
+}
@@ -20,16 +20,32 @@ type PriceService interface {
 	FindPrice(ctx context.Context, skuID int64, date time.Time, occupancy int) (int64, bool, error)
 }
 
-// HoldService 定义库存占用能力。
+// ErrBookingSoldOut 表示舱房库存不足无法占座，用户可登记候补。
+var ErrBookingSoldOut = errors.New("cannot hold inventory: sold out")
+
+// HoldService 定义库存占用能力，库存不足时错误包装 domain.ErrInsufficientInventory。
 type HoldService interface {
-	HoldWithTx(tx *gorm.DB, skuID int64, userID int64, qty int) bool
+	TryHoldWithTx(tx *gorm.DB, skuID int64, userID int64, qty int) error
+}
+
+// WaitlistBookingHook 在下单事务内核销用户对该 SKU 的候补独占邀约，由 WaitlistService 实现。
+type WaitlistBookingHook interface {
+	FulfillOfferTx(tx *gorm.DB, userID, skuID, bookingID int64) error
+}
+
+// AgencyBookingChannel 定义分销渠道在预订事务内的配额占用、净价与信用校验能力，由 AgencyService 实现。
+type AgencyBookingChannel interface {
+	ReserveTx(tx *gorm.DB, req AgencyBookingRequest, listPriceCents int64) (*AgencyReservation, error)
+	RecordTx(tx *gorm.DB, reservation *AgencyReservation, booking *domain.Booking) error
 }
 
 // BookingService 负责预订创建流程编排。
 type BookingService struct {
-	repo  BookingRepo
-	price PriceService
-	hold  HoldService
+	repo     BookingRepo
+	price    PriceService
+	hold     HoldService
+	agency   AgencyBookingChannel
+	waitlist WaitlistBookingHook
 }
 
 // NewBookingService 创建预订服务实例。
@@ -37,6 +53,16 @@ func NewBookingService(repo BookingRepo, price PriceService, hold HoldService) *
 	return &BookingService{repo: repo, price: price, hold: hold}
 }
 
+// SetAgencyChannel 注入分销渠道，未注入时 CreateForAgency 不可用。
+func (s *BookingService) SetAgencyChannel(channel AgencyBookingChannel) {
+	s.agency = channel
+}
+
+// SetWaitlist 注入候补服务，下单时核销候补独占邀约。
+func (s *BookingService) SetWaitlist(waitlist WaitlistBookingHook) {
+	s.waitlist = waitlist
+}
+
 // Create 创建预订并在事务内完成库存占用与金额计算，返回已创建订单。
 func (s *BookingService) Create(ctx context.Context, userID, voyageID, skuID int64, guests int) (*domain.Booking, error) {
 	if s.repo == nil || s.price == nil || s.hold == nil {
@@ -46,7 +72,10 @@ func (s *BookingService) Create(ctx context.Context, userID, voyageID, skuID int
 	var created domain.Booking
 
 	err := s.repo.InTx(func(tx *gorm.DB, create func(b *domain.Booking) error) error {
-		if !s.hold.HoldWithTx(tx, skuID, userID, 1) {
+		if err := s.hold.TryHoldWithTx(tx, skuID, userID, 1); err != nil {
+			if errors.Is(err, domain.ErrInsufficientInventory) {
+				return ErrBookingSoldOut
+			}
 			return errors.New("cannot hold inventory")
 		}
 
@@ -56,7 +85,56 @@ func (s *BookingService) Create(ctx context.Context, userID, voyageID, skuID int
 		}
 
 		created = domain.Booking{UserID: userID, VoyageID: voyageID, CabinSKUID: skuID, Status: "created", TotalCents: price}
-		return create(&created)
+		if err := create(&created); err != nil {
+			return err
+		}
+		if s.waitlist != nil {
+			return s.waitlist.FulfillOfferTx(tx, userID, skuID, created.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// CreateForAgency 以分销渠道创建预订：在同一事务内占用分销商配额（配额创建时已扣减库存，不再占座）、
+// 按净价计价并校验信用额度，订单记在分销商渠道用户名下。
+func (s *BookingService) CreateForAgency(ctx context.Context, req AgencyBookingRequest) (*domain.Booking, error) {
+	if s.repo == nil || s.price == nil || s.agency == nil {
+		return nil, errors.New("booking dependencies not ready")
+	}
+
+	var created domain.Booking
+
+	err := s.repo.InTx(func(tx *gorm.DB, create func(b *domain.Booking) error) error {
+		price, found, err := s.price.FindPrice(ctx, req.CabinSKUID, time.Now(), req.Guests)
+		if err != nil {
+			return err
+		}
+		if !found {
+			price = 0
+		}
+
+		reservation, err := s.agency.ReserveTx(tx, req, price)
+		if err != nil {
+			return err
+		}
+
+		created = domain.Booking{
+			UserID:     reservation.UserID,
+			VoyageID:   req.VoyageID,
+			CabinSKUID: req.CabinSKUID,
+			Status:     domain.OrderStatusCreated,
+			TotalCents: reservation.NetCents,
+			Channel:    domain.BookingChannelAgency,
+		}
+		if err := create(&created); err != nil {
+			return err
+		}
+		return s.agency.RecordTx(tx, reservation, &created)
 	})
 	if err != nil {
 		return nil, err
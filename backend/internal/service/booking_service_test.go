@@ -38,9 +38,9 @@ func (f fakePriceService) FindPrice(_ context.Context, _ int64, _ time.Time, _ i
 
 type fakeHoldService struct{ ok bool }
 
-func (f *fakeHoldService) HoldWithTx(_ *gorm.DB, _ int64, _ int64, _ int) bool {
+func (f *fakeHoldService) TryHoldWithTx(_ *gorm.DB, _ int64, _ int64, _ int) error {
 	f.ok = true
-	return true
+	return nil
 }
 
 func TestBookingServiceCreate(t *testing.T) {
@@ -113,3 +113,24 @@ func TestBookingServiceCreate_RollbackHoldAndInventoryOnCreateFail(t *testing.T)
 		t.Fatalf("expected hold rollback with zero records, got %d", holdCount)
 	}
 }
+
+func TestBookingServiceCreate_SoldOutReturnsWaitlistableError(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&domain.CabinInventory{}, &domain.InventoryLog{}, &domain.CabinHold{}, &domain.Booking{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&domain.CabinInventory{CabinSKUID: 3, Total: 0}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	holdSvc := NewCabinHoldService(repository.NewCabinHoldRepository(db), time.Minute)
+	svc := NewBookingService(repository.NewBookingRepository(db), fakePriceService{}, holdSvc)
+
+	_, err = svc.Create(context.Background(), 1, 2, 3, 2)
+	if !errors.Is(err, ErrBookingSoldOut) {
+		t.Fatalf("expected ErrBookingSoldOut, got %v", err)
+	}
+}
@@ -29,7 +29,8 @@ type cabinAdminRepo interface {
 
 // CabinAdminService 提供后台舱位、库存与价格管理能力。
 type CabinAdminService struct {
-	repo cabinAdminRepo
+	repo     cabinAdminRepo
+	released InventoryReleaseListener
 }
 
 // NewCabinAdminService 创建舱位后台管理服务。
@@ -37,6 +38,11 @@ func NewCabinAdminService(repo cabinAdminRepo) *CabinAdminService {
 	return &CabinAdminService{repo: repo}
 }
 
+// SetReleaseListener 注入库存释放监听，调增库存后通知候补服务发出邀约。
+func (s *CabinAdminService) SetReleaseListener(listener InventoryReleaseListener) {
+	s.released = listener
+}
+
 // ListByVoyage 按航次查询舱位 SKU 列表。
 func (s *CabinAdminService) ListByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error) {
 	return s.repo.ListSKUByVoyage(ctx, voyageID)
@@ -107,7 +113,13 @@ func (s *CabinAdminService) AdjustInventory(ctx context.Context, skuID int64, de
 	if err := s.repo.AdjustInventoryAtomic(ctx, skuID, delta); err != nil {
 		return err
 	}
-	return s.repo.AppendInventoryLog(ctx, &domain.InventoryLog{CabinSKUID: skuID, Change: delta, Reason: reason})
+	if err := s.repo.AppendInventoryLog(ctx, &domain.InventoryLog{CabinSKUID: skuID, Change: delta, Reason: reason}); err != nil {
+		return err
+	}
+	if delta > 0 && s.released != nil {
+		s.released.OnInventoryReleased(ctx, skuID)
+	}
+	return nil
 }
 
 // ListPrices 查询指定 SKU 的价格列表。
@@ -0,0 +1,477 @@
+package service
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/xlsx"
+	"gorm.io/gorm"
+)
+
+const (
+	CabinFileCSV  = "csv"  // 舱房导入导出格式：CSV
+	CabinFileXLSX = "xlsx" // 舱房导入导出格式：Excel
+
+	maxCabinImportRows       = 5000           // 单次导入允许的最大数据行数
+	maxCabinImportBytes      = 10 << 20       // 单次导入文件大小上限
+	defaultCabinImportTotal  = 1              // 新增舱房未填写库存时的默认库存总量
+	cabinImportInventoryNote = "cabin import" // 导入调整库存时写入库存日志的原因
+)
+
+var (
+	// ErrInvalidCabinImport 表示导入文件或导出参数不合法。
+	ErrInvalidCabinImport = errors.New("invalid cabin import")
+	// ErrCabinImportVoyageNotFound 表示导入导出的目标航次不存在。
+	ErrCabinImportVoyageNotFound = errors.New("voyage not found")
+)
+
+// cabinFileHeader 是舱房导出列顺序，导入时按表头名称映射，列顺序不限；locked、sold 仅供参考，导入时忽略。
+var cabinFileHeader = []string{
+	"code", "cabin_type_id", "deck", "area", "max_guests", "position", "orientation", "has_window", "has_balcony",
+	"bed_type", "amenities", "grade", "status", "inventory_total", "alert_threshold", "locked", "sold",
+}
+
+// cabinImportRequired 是导入时必须存在的列，其余列缺失时新增舱房取零值、已有舱房保持原值。
+var cabinImportRequired = []string{"code", "cabin_type_id"}
+
+// CabinImportVoyages 提供导入导出的目标航次。
+type CabinImportVoyages interface {
+	GetByID(ctx context.Context, id int64) (*domain.Voyage, error)
+}
+
+// CabinImportCabins 提供航次下已有的舱房 SKU。
+type CabinImportCabins interface {
+	ListSKUByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error)
+}
+
+// CabinImportBindings 提供邮轮绑定的舱型，导入的舱型必须属于航次所在邮轮。
+type CabinImportBindings interface {
+	ListCabinTypeIDsByCruise(ctx context.Context, cruiseID int64) ([]int64, error)
+}
+
+// CabinImportRowError 描述导入中单行的失败原因，Row 为文件中的行号（含表头）。
+type CabinImportRowError struct {
+	Row     int    `json:"row"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// CabinImportSummary 汇总导入结果。任一行校验失败时整个文件不写入；
+// dry_run 时只校验不写入，Created/Updated 为预计新增与覆盖的行数。
+type CabinImportSummary struct {
+	DryRun  bool                  `json:"dry_run"`
+	Applied bool                  `json:"applied"`
+	Total   int                   `json:"total"`
+	Created int                   `json:"created"`
+	Updated int                   `json:"updated"`
+	Failed  int                   `json:"failed"`
+	Errors  []CabinImportRowError `json:"errors"`
+	SKUs    []domain.CabinSKU     `json:"-"` // 已写入的舱房，供处理器同步搜索索引
+}
+
+// cabinImportRecord 是一行待导入数据及其表头映射。
+type cabinImportRecord struct {
+	row    int
+	code   string
+	values map[string]string
+}
+
+// CabinImportService 提供按航次批量导入导出舱房 SKU 与库存（CSV/XLSX），按舱房编号新增或覆盖。
+type CabinImportService struct {
+	repo     domain.CabinImportRepository
+	voyages  CabinImportVoyages
+	cabins   CabinImportCabins
+	bindings CabinImportBindings
+	released InventoryReleaseListener
+}
+
+// NewCabinImportService 创建舱房批量导入导出服务。
+func NewCabinImportService(repo domain.CabinImportRepository, voyages CabinImportVoyages, cabins CabinImportCabins, bindings CabinImportBindings) *CabinImportService {
+	return &CabinImportService{repo: repo, voyages: voyages, cabins: cabins, bindings: bindings}
+}
+
+// SetReleaseListener 注入库存释放监听，导入调增库存后通知候补服务发出邀约。
+func (s *CabinImportService) SetReleaseListener(listener InventoryReleaseListener) {
+	s.released = listener
+}
+
+// Import 校验并导入航次舱房。全部行通过校验且非 dryRun 时在单个事务内写入，否则只返回逐行校验结果。
+func (s *CabinImportService) Import(ctx context.Context, voyageID int64, format string, reader io.Reader, dryRun bool) (*CabinImportSummary, error) {
+	voyage, err := s.voyage(ctx, voyageID)
+	if err != nil {
+		return nil, err
+	}
+	table, err := readCabinFile(format, reader)
+	if err != nil {
+		return nil, err
+	}
+	summary := &CabinImportSummary{DryRun: dryRun, Errors: []CabinImportRowError{}}
+	records, err := cabinImportRecords(table, summary)
+	if err != nil || len(records) == 0 {
+		return summary, err
+	}
+	allowed, err := s.bindings.ListCabinTypeIDsByCruise(ctx, voyage.CruiseID)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(records))
+	for _, record := range records {
+		codes = append(codes, record.code)
+	}
+	existing, err := s.repo.FindSKUsByCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]domain.CabinSKU, len(existing))
+	ids := make([]int64, 0, len(existing))
+	for _, sku := range existing {
+		byCode[sku.Code] = sku
+		ids = append(ids, sku.ID)
+	}
+	inventories, err := s.repo.ListInventoriesBySKUs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	bySKU := make(map[int64]domain.CabinInventory, len(inventories))
+	for _, inventory := range inventories {
+		bySKU[inventory.CabinSKUID] = inventory
+	}
+
+	items := make([]domain.CabinImportItem, 0, len(records))
+	for _, record := range records {
+		current, found := byCode[record.code]
+		item, err := cabinImportItem(record, voyageID, current, found, bySKU[current.ID], allowed)
+		if err != nil {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, CabinImportRowError{Row: record.row, Code: record.code, Message: err.Error()})
+			continue
+		}
+		if found {
+			summary.Updated++
+		} else {
+			summary.Created++
+		}
+		items = append(items, item)
+	}
+	if dryRun || summary.Failed > 0 {
+		return summary, nil
+	}
+	if err := s.repo.ApplyImport(ctx, items, cabinImportInventoryNote); err != nil {
+		if errors.Is(err, domain.ErrCabinInventoryBelowCommitted) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCabinImport, err)
+		}
+		return nil, err
+	}
+	summary.Applied = true
+	summary.SKUs = make([]domain.CabinSKU, 0, len(items))
+	for _, item := range items {
+		summary.SKUs = append(summary.SKUs, item.SKU)
+		if previous, ok := bySKU[item.SKU.ID]; ok && item.InventoryTotal != nil && *item.InventoryTotal > previous.Total && s.released != nil {
+			s.released.OnInventoryReleased(ctx, item.SKU.ID)
+		}
+	}
+	return summary, nil
+}
+
+// Export 按舱房编号排序导出航次舱房与库存，导出的文件可直接修改后重新导入。
+func (s *CabinImportService) Export(ctx context.Context, voyageID int64, format string) ([]byte, error) {
+	format, err := normalizeCabinFileFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.voyage(ctx, voyageID); err != nil {
+		return nil, err
+	}
+	skus, err := s.cabins.ListSKUByVoyage(ctx, voyageID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(skus, func(a, b domain.CabinSKU) int { return cmp.Compare(a.Code, b.Code) })
+	ids := make([]int64, 0, len(skus))
+	for _, sku := range skus {
+		ids = append(ids, sku.ID)
+	}
+	inventories, err := s.repo.ListInventoriesBySKUs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	bySKU := make(map[int64]domain.CabinInventory, len(inventories))
+	for _, inventory := range inventories {
+		bySKU[inventory.CabinSKUID] = inventory
+	}
+
+	table := make([][]any, 0, len(skus)+1)
+	header := make([]any, len(cabinFileHeader))
+	for i, name := range cabinFileHeader {
+		header[i] = name
+	}
+	table = append(table, header)
+	for _, sku := range skus {
+		inventory := bySKU[sku.ID]
+		table = append(table, []any{
+			sku.Code, sku.CabinTypeID, sku.Deck, sku.Area, sku.MaxGuests, sku.Position, sku.Orientation,
+			strconv.FormatBool(sku.HasWindow), strconv.FormatBool(sku.HasBalcony), sku.BedType, sku.Amenities, sku.Grade,
+			int(sku.Status), inventory.Total, inventory.AlertThreshold, inventory.Locked, inventory.Sold,
+		})
+	}
+	if format == CabinFileXLSX {
+		return xlsx.Encode("cabins", table)
+	}
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	for _, row := range table {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case string:
+				record[i] = sanitizeCSVCell(v)
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				record[i] = fmt.Sprint(v)
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *CabinImportService) voyage(ctx context.Context, voyageID int64) (*domain.Voyage, error) {
+	voyage, err := s.voyages.GetByID(ctx, voyageID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCabinImportVoyageNotFound
+	}
+	return voyage, err
+}
+
+func normalizeCabinFileFormat(format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		return CabinFileCSV, nil
+	case CabinFileCSV, CabinFileXLSX:
+		return format, nil
+	}
+	return "", fmt.Errorf("%w: unsupported format %q", ErrInvalidCabinImport, format)
+}
+
+// readCabinFile 将 CSV 或 XLSX 文件读取为字符串表格。
+func readCabinFile(format string, reader io.Reader) ([][]string, error) {
+	format, err := normalizeCabinFileFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxCabinImportBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxCabinImportBytes {
+		return nil, fmt.Errorf("%w: file exceeds %d bytes", ErrInvalidCabinImport, maxCabinImportBytes)
+	}
+	if format == CabinFileXLSX {
+		table, err := xlsx.Read(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCabinImport, err)
+		}
+		return table, nil
+	}
+	parsed := csv.NewReader(bytes.NewReader(data))
+	parsed.FieldsPerRecord = -1
+	parsed.TrimLeadingSpace = true
+	table, err := parsed.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCabinImport, err)
+	}
+	return table, nil
+}
+
+// cabinImportRecords 按表头映射数据行，跳过空行；编号为空或在文件内重复的行记入 summary。
+func cabinImportRecords(table [][]string, summary *CabinImportSummary) ([]cabinImportRecord, error) {
+	if len(table) == 0 {
+		return nil, nil
+	}
+	columns := make(map[string]int, len(table[0]))
+	for idx, name := range table[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = idx
+	}
+	for _, name := range cabinImportRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidCabinImport, name)
+		}
+	}
+	seen := make(map[string]int)
+	records := make([]cabinImportRecord, 0, len(table)-1)
+	for i, row := range table[1:] {
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		if summary.Total++; summary.Total > maxCabinImportRows {
+			return nil, fmt.Errorf("%w: at most %d rows per import", ErrInvalidCabinImport, maxCabinImportRows)
+		}
+		record := cabinImportRecord{row: i + 2, values: make(map[string]string, len(columns))}
+		for name, col := range columns {
+			if col < len(row) {
+				record.values[name] = strings.TrimSpace(row[col])
+			} else {
+				record.values[name] = ""
+			}
+		}
+		record.code = record.values["code"]
+		var message string
+		switch first, dup := seen[record.code]; {
+		case record.code == "":
+			message = "code is required"
+		case len(record.code) > 80:
+			message = "code must be at most 80 characters"
+		case dup:
+			message = fmt.Sprintf("duplicate code, first seen on row %d", first)
+		}
+		if message != "" {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, CabinImportRowError{Row: record.row, Code: record.code, Message: message})
+			continue
+		}
+		seen[record.code] = record.row
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// cabinImportItem 将一行数据合并到已有舱房（或新舱房）上并完成校验；文件中缺失的列、留空的数值与布尔列保持原值。
+func cabinImportItem(record cabinImportRecord, voyageID int64, current domain.CabinSKU, found bool, inventory domain.CabinInventory, allowedTypes []int64) (domain.CabinImportItem, error) {
+	item := domain.CabinImportItem{Row: record.row}
+	sku := current
+	if found {
+		if current.VoyageID != voyageID {
+			return item, fmt.Errorf("code already used by voyage %d", current.VoyageID)
+		}
+	} else {
+		sku = domain.CabinSKU{VoyageID: voyageID, Code: record.code, Status: 1}
+	}
+	value := func(name string) (string, bool) {
+		raw, ok := record.values[name]
+		return raw, ok && raw != ""
+	}
+
+	if raw, ok := value("cabin_type_id"); ok || !found {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return item, errors.New("cabin_type_id must be a positive integer")
+		}
+		if !slices.Contains(allowedTypes, id) {
+			return item, fmt.Errorf("cabin type %d is not bound to the voyage's cruise", id)
+		}
+		sku.CabinTypeID = id
+	}
+	if raw, ok := value("area"); ok {
+		area, err := strconv.ParseFloat(raw, 64)
+		if err != nil || area < 0 {
+			return item, errors.New("area must be a non-negative number")
+		}
+		sku.Area = area
+	}
+	if raw, ok := value("max_guests"); ok {
+		guests, err := strconv.Atoi(raw)
+		if err != nil || guests < 0 {
+			return item, errors.New("max_guests must be a non-negative integer")
+		}
+		sku.MaxGuests = guests
+	}
+	if raw, ok := value("position"); ok {
+		raw = strings.ToLower(raw)
+		if raw != "fore" && raw != "mid" && raw != "aft" {
+			return item, errors.New("position must be fore, mid or aft")
+		}
+		sku.Position = raw
+	}
+	if raw, ok := value("orientation"); ok {
+		raw = strings.ToLower(raw)
+		if raw != "port" && raw != "starboard" {
+			return item, errors.New("orientation must be port or starboard")
+		}
+		sku.Orientation = raw
+	}
+	for name, target := range map[string]*bool{"has_window": &sku.HasWindow, "has_balcony": &sku.HasBalcony} {
+		if raw, ok := value(name); ok {
+			flag, err := parseCabinImportBool(raw)
+			if err != nil {
+				return item, fmt.Errorf("%s must be true or false", name)
+			}
+			*target = flag
+		}
+	}
+	if raw, ok := value("status"); ok {
+		if raw != "0" && raw != "1" {
+			return item, errors.New("status must be 0 or 1")
+		}
+		sku.Status = int16(raw[0] - '0')
+	}
+	texts := map[string]struct {
+		target *string
+		limit  int
+	}{
+		"deck":      {&sku.Deck, 20},
+		"bed_type":  {&sku.BedType, 100},
+		"grade":     {&sku.Grade, 50},
+		"amenities": {&sku.Amenities, 0},
+	}
+	for name, field := range texts {
+		raw, present := record.values[name]
+		if !present {
+			continue
+		}
+		if field.limit > 0 && len([]rune(raw)) > field.limit {
+			return item, fmt.Errorf("%s must be at most %d characters", name, field.limit)
+		}
+		*field.target = raw
+	}
+
+	if raw, ok := value("inventory_total"); ok {
+		total, err := strconv.Atoi(raw)
+		if err != nil || total < 0 {
+			return item, errors.New("inventory_total must be a non-negative integer")
+		}
+		if found && total < inventory.Locked+inventory.Sold {
+			return item, fmt.Errorf("inventory_total %d is below locked %d and sold %d", total, inventory.Locked, inventory.Sold)
+		}
+		item.InventoryTotal = &total
+	} else if !found {
+		total := defaultCabinImportTotal
+		item.InventoryTotal = &total
+	}
+	if raw, ok := value("alert_threshold"); ok {
+		threshold, err := strconv.Atoi(raw)
+		if err != nil || threshold < 0 {
+			return item, errors.New("alert_threshold must be a non-negative integer")
+		}
+		item.AlertThreshold = &threshold
+	}
+	item.SKU = sku
+	return item, nil
+}
+
+// parseCabinImportBool 解析表格中的布尔值，兼容 true/false、1/0、yes/no 与 是/否。
+func parseCabinImportBool(raw string) (bool, error) {
+	switch strings.ToLower(raw) {
+	case "true", "1", "yes", "y", "是":
+		return true, nil
+	case "false", "0", "no", "n", "否":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", raw)
+}
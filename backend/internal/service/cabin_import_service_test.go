@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/xlsx"
+)
+
+type cabinImportRepoStub struct {
+	skus        []domain.CabinSKU
+	inventories []domain.CabinInventory
+	applied     []domain.CabinImportItem
+	applyErr    error
+}
+
+func (s *cabinImportRepoStub) FindSKUsByCodes(_ context.Context, codes []string) ([]domain.CabinSKU, error) {
+	out := []domain.CabinSKU{}
+	for _, sku := range s.skus {
+		for _, code := range codes {
+			if sku.Code == code {
+				out = append(out, sku)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s *cabinImportRepoStub) ListInventoriesBySKUs(context.Context, []int64) ([]domain.CabinInventory, error) {
+	return s.inventories, nil
+}
+
+func (s *cabinImportRepoStub) ApplyImport(_ context.Context, items []domain.CabinImportItem, _ string) error {
+	if s.applyErr != nil {
+		return s.applyErr
+	}
+	s.applied = items
+	return nil
+}
+
+func newCabinImportTestService(repo *cabinImportRepoStub) (*CabinImportService, *recordingReleaseListener) {
+	voyages := seriesTemplateStub{voyage: &domain.Voyage{ID: 5, CruiseID: 7, Code: "SPEC-20260801"}}
+	cabins := seriesCabinStub{skus: repo.skus}
+	svc := NewCabinImportService(repo, voyages, cabins, seriesBindingStub{cabinTypeIDs: []int64{31, 32}})
+	listener := &recordingReleaseListener{}
+	svc.SetReleaseListener(listener)
+	return svc, listener
+}
+
+func existingCabinImportRepo() *cabinImportRepoStub {
+	return &cabinImportRepoStub{
+		skus: []domain.CabinSKU{
+			{ID: 1, VoyageID: 5, CabinTypeID: 31, Code: "A-8001", Deck: "8", Position: "fore", HasWindow: true, BedType: "大床", Status: 1},
+			{ID: 2, VoyageID: 6, CabinTypeID: 31, Code: "B-8001", Status: 1},
+		},
+		inventories: []domain.CabinInventory{{CabinSKUID: 1, Total: 2, Locked: 1, Sold: 1, AlertThreshold: 1}},
+	}
+}
+
+func TestCabinImportService_ImportCSVUpsertsByCode(t *testing.T) {
+	repo := existingCabinImportRepo()
+	svc, listener := newCabinImportTestService(repo)
+	file := "\ufeffCode,cabin_type_id,deck,position,orientation,has_balcony,status,inventory_total\n" +
+		"A-8001,32,9,,starboard,是,,3\n" +
+		"\n" +
+		"A-8002,31,8,aft,port,false,0,\n"
+
+	summary, err := svc.Import(context.Background(), 5, "csv", strings.NewReader(file), false)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !summary.Applied || summary.Total != 2 || summary.Created != 1 || summary.Updated != 1 || summary.Failed != 0 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	updated, created := repo.applied[0], repo.applied[1]
+	if updated.SKU.ID != 1 || updated.SKU.CabinTypeID != 32 || updated.SKU.Deck != "9" || updated.SKU.Orientation != "starboard" || !updated.SKU.HasBalcony {
+		t.Fatalf("unexpected updated sku: %+v", updated.SKU)
+	}
+	if updated.SKU.Position != "fore" || !updated.SKU.HasWindow || updated.SKU.BedType != "大床" || updated.SKU.Status != 1 {
+		t.Fatalf("blank cells and missing columns should keep existing values: %+v", updated.SKU)
+	}
+	if *updated.InventoryTotal != 3 || updated.AlertThreshold != nil {
+		t.Fatalf("unexpected inventory update: %+v", updated)
+	}
+	if created.SKU.ID != 0 || created.SKU.VoyageID != 5 || created.SKU.Status != 0 || *created.InventoryTotal != defaultCabinImportTotal {
+		t.Fatalf("unexpected created item: %+v", created)
+	}
+	if len(summary.SKUs) != 2 || len(listener.skuIDs) != 1 || listener.skuIDs[0] != 1 {
+		t.Fatalf("raised inventory should notify waitlist: skus=%d released=%v", len(summary.SKUs), listener.skuIDs)
+	}
+}
+
+func TestCabinImportService_DryRunReportsRowErrors(t *testing.T) {
+	repo := existingCabinImportRepo()
+	svc, _ := newCabinImportTestService(repo)
+	rows := [][]any{
+		{"code", "cabin_type_id", "position", "has_window", "max_guests", "inventory_total"},
+		{"A-8001", 31, "fore", "true", 2, 1},
+		{"B-8001", 31, "mid", "", "", ""},
+		{"C-8001", 99, "", "", "", ""},
+		{"C-8002", 31, "bow", "", "", ""},
+		{"C-8003", 31, "", "maybe", "", ""},
+		{"", 31, "", "", "", ""},
+		{"C-8004", 31, "", "", -1, ""},
+		{"C-8005", 31, "aft", "no", 4, 2},
+		{"C-8005", 31, "", "", "", ""},
+	}
+	data, err := xlsx.Encode("cabins", rows)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	summary, err := svc.Import(context.Background(), 5, "xlsx", bytes.NewReader(data), true)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if summary.Applied || repo.applied != nil {
+		t.Fatal("dry run must not write")
+	}
+	if summary.Total != 9 || summary.Created != 1 || summary.Updated != 0 || summary.Failed != 8 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	want := map[int]string{
+		2:  "below locked",
+		3:  "already used by voyage 6",
+		4:  "not bound",
+		5:  "position",
+		6:  "has_window",
+		7:  "code is required",
+		8:  "max_guests",
+		10: "duplicate code, first seen on row 9",
+	}
+	for _, rowErr := range summary.Errors {
+		if !strings.Contains(rowErr.Message, want[rowErr.Row]) || want[rowErr.Row] == "" {
+			t.Fatalf("unexpected error for row %d: %q", rowErr.Row, rowErr.Message)
+		}
+		delete(want, rowErr.Row)
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing row errors: %v", want)
+	}
+
+	summary, err = svc.Import(context.Background(), 5, "xlsx", bytes.NewReader(data), false)
+	if err != nil || summary.Applied || repo.applied != nil {
+		t.Fatalf("files with row errors must not be applied: %+v (%v)", summary, err)
+	}
+}
+
+func TestCabinImportService_ImportRejectsInvalidFiles(t *testing.T) {
+	svc, _ := newCabinImportTestService(existingCabinImportRepo())
+	ctx := context.Background()
+	if _, err := svc.Import(ctx, 404, "csv", strings.NewReader("code,cabin_type_id\n"), false); !errors.Is(err, ErrCabinImportVoyageNotFound) {
+		t.Fatalf("expected ErrCabinImportVoyageNotFound, got %v", err)
+	}
+	cases := []struct {
+		format string
+		body   string
+	}{
+		{"json", "code,cabin_type_id\n"},
+		{"csv", "code,deck\nA-1,8\n"},
+		{"csv", "code,cabin_type_id\n\"A-1,31\n"},
+		{"xlsx", "code,cabin_type_id\n"},
+	}
+	for i, tc := range cases {
+		if _, err := svc.Import(ctx, 5, tc.format, strings.NewReader(tc.body), true); !errors.Is(err, ErrInvalidCabinImport) {
+			t.Fatalf("case %d: expected ErrInvalidCabinImport, got %v", i, err)
+		}
+	}
+	summary, err := svc.Import(ctx, 5, "", strings.NewReader(""), false)
+	if err != nil || summary.Total != 0 {
+		t.Fatalf("empty file should produce empty summary: %+v (%v)", summary, err)
+	}
+
+	repo := existingCabinImportRepo()
+	repo.applyErr = domain.ErrCabinInventoryBelowCommitted
+	svc, _ = newCabinImportTestService(repo)
+	if _, err := svc.Import(ctx, 5, "csv", strings.NewReader("code,cabin_type_id\nA-9001,31\n"), false); !errors.Is(err, ErrInvalidCabinImport) {
+		t.Fatalf("expected concurrent inventory change to be reported as ErrInvalidCabinImport, got %v", err)
+	}
+}
+
+func TestCabinImportService_ExportRoundTrips(t *testing.T) {
+	repo := existingCabinImportRepo()
+	repo.skus = []domain.CabinSKU{
+		{ID: 3, VoyageID: 5, CabinTypeID: 32, Code: "=B-1", Area: 18.5, HasBalcony: true, Status: 0},
+		repo.skus[0],
+	}
+	svc, _ := newCabinImportTestService(repo)
+
+	data, err := svc.Export(context.Background(), 5, "csv")
+	if err != nil {
+		t.Fatalf("export csv: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(cabinFileHeader, ",") {
+		t.Fatalf("unexpected header: %v", records)
+	}
+	if records[1][0] != "'=B-1" || records[1][3] != "18.5" || records[1][8] != "true" || records[1][12] != "0" {
+		t.Fatalf("unexpected first row (sorted by code, formula-escaped): %v", records[1])
+	}
+	if records[2][0] != "A-8001" || records[2][13] != "2" || records[2][15] != "1" || records[2][16] != "1" {
+		t.Fatalf("unexpected second row: %v", records[2])
+	}
+
+	data, err = svc.Export(context.Background(), 5, "xlsx")
+	if err != nil {
+		t.Fatalf("export xlsx: %v", err)
+	}
+	summary, err := svc.Import(context.Background(), 5, "xlsx", bytes.NewReader(data), true)
+	if err != nil || summary.Failed != 0 || summary.Updated != 2 || summary.Created != 0 {
+		t.Fatalf("exported workbook should re-import cleanly: %+v (%v)", summary, err)
+	}
+	if _, err := svc.Export(context.Background(), 5, "pdf"); !errors.Is(err, ErrInvalidCabinImport) {
+		t.Fatalf("expected ErrInvalidCabinImport for unsupported format, got %v", err)
+	}
+}
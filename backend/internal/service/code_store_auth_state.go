@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+// AuthStateCodeStore 把短信验证码的哈希存放在认证风控状态的共享存储中，多副本部署时任一实例都能校验。
+// 验证码的有效期与失败锁定仍由 UserAuthService 控制，这里的 ttl 只决定记录的保留时长。
+type AuthStateCodeStore struct {
+	states domain.AuthStateStore
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+var _ CodeStore = (*AuthStateCodeStore)(nil)
+
+// NewAuthStateCodeStore 创建共享验证码存储；ttl 非正数时默认 5 分钟。
+func NewAuthStateCodeStore(states domain.AuthStateStore, ttl time.Duration) *AuthStateCodeStore {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &AuthStateCodeStore{states: states, ttl: ttl, now: time.Now}
+}
+
+func smsValueKey(phone string) string { return "sms:value:" + phone }
+
+// Save 保存手机号对应验证码的哈希，覆盖此前发出的验证码。
+func (s *AuthStateCodeStore) Save(phone, code string) error {
+	return s.states.SetValue(context.Background(), smsValueKey(phone), hashSMSCode(phone, code), s.ttl, s.now())
+}
+
+// Verify 校验手机号验证码是否匹配；存储不可用时按不匹配处理。
+func (s *AuthStateCodeStore) Verify(phone, code string) bool {
+	stored, ok, err := s.states.GetValue(context.Background(), smsValueKey(phone), s.now())
+	if err != nil {
+		logAuthStateError("load sms code", err)
+		return false
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(stored), []byte(hashSMSCode(phone, code))) == 1
+}
+
+func hashSMSCode(phone, code string) string {
+	sum := sha256.Sum256([]byte(phone + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
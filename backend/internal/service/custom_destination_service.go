@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/csvimport"
 )
 
 // CustomDestinationRepo 定义自定义目的地仓储需要实现的接口。
@@ -20,10 +23,28 @@ type CustomDestinationRepo interface {
 	SearchByKeyword(ctx context.Context, keyword string) ([]domain.CustomDestination, error)
 	GetByLabel(ctx context.Context, name, country string) (*domain.CustomDestination, error)
 	UpsertByNameCountry(ctx context.Context, dest *domain.CustomDestination) error
+	UpsertBatch(ctx context.Context, dests []domain.CustomDestination) error
 	Delete(ctx context.Context, id int64) error
 }
 
+// ImportKindCustomDestinations 为港口城市词典导入任务的类型。
+const ImportKindCustomDestinations = "custom_destinations"
+
+// customDestinationHeaderAliases 允许使用中文表头导入。
+var customDestinationHeaderAliases = map[string]string{
+	"名称":  "name",
+	"国家":  "country",
+	"纬度":  "latitude",
+	"经度":  "longitude",
+	"关键词": "keywords",
+	"排序":  "sort_order",
+	"状态":  "status",
+	"描述":  "description",
+}
+
+// CustomDestinationImportSummary 为港口城市词典导入结果，Imported 为实际写入的行数。
 type CustomDestinationImportSummary struct {
+	*csvimport.Result
 	Imported int `json:"imported"`
 }
 
@@ -85,82 +106,101 @@ func (s *CustomDestinationService) ExportCSV(ctx context.Context) ([]byte, error
 	return []byte(buf.String()), nil
 }
 
-func (s *CustomDestinationService) ImportCSV(ctx context.Context, reader io.Reader) (*CustomDestinationImportSummary, error) {
-	parsed := csv.NewReader(reader)
-	records, err := parsed.ReadAll()
+// ImportCSV 按表头映射导入港口城市词典 CSV，逐行校验并收集错误；按名称+国家新增或覆盖。
+// 必需列为 name、country、latitude、longitude，其余列缺失时取默认值。
+func (s *CustomDestinationService) ImportCSV(ctx context.Context, reader io.Reader, opts csvimport.Options) (*CustomDestinationImportSummary, error) {
+	result, err := csvimport.Run(ctx, reader, s.importSpec(), opts)
 	if err != nil {
 		return nil, err
 	}
-	if len(records) <= 1 {
-		return &CustomDestinationImportSummary{Imported: 0}, nil
-	}
-	summary := &CustomDestinationImportSummary{}
-	for idx, row := range records[1:] {
-		if len(row) < 8 {
-			return nil, fmt.Errorf("row %d has insufficient columns", idx+2)
-		}
-		name := strings.TrimSpace(row[0])
-		country := strings.TrimSpace(row[1])
-		if name == "" || country == "" {
-			return nil, fmt.Errorf("row %d requires name and country", idx+2)
-		}
-		latitude, err := parseRequiredFloat(row[2], idx+2, "latitude")
-		if err != nil {
-			return nil, err
-		}
-		longitude, err := parseRequiredFloat(row[3], idx+2, "longitude")
-		if err != nil {
-			return nil, err
-		}
-		sortOrder, err := parseOptionalInt(row[5], 0)
-		if err != nil {
-			return nil, fmt.Errorf("row %d invalid sort_order: %w", idx+2, err)
-		}
-		statusValue, err := parseOptionalInt(row[6], 1)
-		if err != nil {
-			return nil, fmt.Errorf("row %d invalid status: %w", idx+2, err)
-		}
-		dest := &domain.CustomDestination{
-			Name:        name,
-			Country:     country,
-			Latitude:    &latitude,
-			Longitude:   &longitude,
-			Keywords:    strings.TrimSpace(row[4]),
-			SortOrder:   sortOrder,
-			Status:      int16(statusValue),
-			Description: strings.TrimSpace(row[7]),
-		}
-		if err := s.repo.UpsertByNameCountry(ctx, dest); err != nil {
-			return nil, err
-		}
-		summary.Imported++
+	summary := &CustomDestinationImportSummary{Result: result}
+	if result.Committed {
+		summary.Imported = result.Succeeded
 	}
 	return summary, nil
 }
 
-func (s *CustomDestinationService) Delete(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+// RunImport 供异步导入任务调用的导入入口。
+func (s *CustomDestinationService) RunImport(ctx context.Context, reader io.Reader, opts csvimport.Options) (*csvimport.Result, error) {
+	summary, err := s.ImportCSV(ctx, reader, opts)
+	if err != nil {
+		return nil, err
+	}
+	return summary.Result, nil
 }
 
-func formatFloatPointer(value *float64) string {
-	if value == nil {
-		return ""
+func (s *CustomDestinationService) importSpec() csvimport.Spec[domain.CustomDestination] {
+	return csvimport.Spec[domain.CustomDestination]{
+		Required: []string{"name", "country", "latitude", "longitude"},
+		Aliases:  customDestinationHeaderAliases,
+		Parse:    parseCustomDestinationRow,
+		Key: func(dest domain.CustomDestination) string {
+			return dest.Name + "\x00" + dest.Country
+		},
+		Save: s.repo.UpsertBatch,
 	}
-	return strconv.FormatFloat(*value, 'f', -1, 64)
 }
 
-func parseRequiredFloat(raw string, rowNo int, field string) (float64, error) {
-	trimmed := strings.TrimSpace(raw)
-	if trimmed == "" {
-		return 0, fmt.Errorf("row %d missing %s", rowNo, field)
+func parseCustomDestinationRow(_ context.Context, row csvimport.Row) (domain.CustomDestination, error) {
+	dest := domain.CustomDestination{
+		Name:        row.Get("name"),
+		Country:     row.Get("country"),
+		Keywords:    row.Get("keywords"),
+		Description: row.Get("description"),
+	}
+	var errs []error
+	if dest.Name == "" {
+		errs = append(errs, csvimport.ColumnError("name", "is required"))
+	}
+	if dest.Country == "" {
+		errs = append(errs, csvimport.ColumnError("country", "is required"))
 	}
-	value, err := strconv.ParseFloat(trimmed, 64)
+	latitude, err := parseCoordinate(row.Get("latitude"), 90)
 	if err != nil {
-		return 0, fmt.Errorf("row %d invalid %s: %w", rowNo, field, err)
+		errs = append(errs, csvimport.ColumnError("latitude", "%v", err))
+	}
+	longitude, err := parseCoordinate(row.Get("longitude"), 180)
+	if err != nil {
+		errs = append(errs, csvimport.ColumnError("longitude", "%v", err))
+	}
+	dest.Latitude, dest.Longitude = &latitude, &longitude
+	if dest.SortOrder, err = parseOptionalInt(row.Get("sort_order"), 0); err != nil {
+		errs = append(errs, csvimport.ColumnError("sort_order", "must be an integer"))
+	}
+	status, err := parseOptionalInt(row.Get("status"), 1)
+	if err != nil || (status != 0 && status != 1) {
+		errs = append(errs, csvimport.ColumnError("status", "must be 0 or 1"))
+	}
+	dest.Status = int16(status)
+	return dest, errors.Join(errs...)
+}
+
+// parseCoordinate 解析经纬度，limit 为绝对值上限（纬度 90，经度 180）。
+func parseCoordinate(raw string, limit float64) (float64, error) {
+	if raw == "" {
+		return 0, errors.New("is required")
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(value) {
+		return 0, errors.New("must be a number")
+	}
+	if value < -limit || value > limit {
+		return 0, fmt.Errorf("must be between %g and %g", -limit, limit)
 	}
 	return value, nil
 }
 
+func (s *CustomDestinationService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func formatFloatPointer(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
 func parseOptionalInt(raw string, fallback int) (int, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
@@ -3,10 +3,12 @@ package service
 import (
 	"bytes"
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/csvimport"
 )
 
 type customDestinationCSVRepoStub struct {
@@ -42,6 +44,13 @@ func (s *customDestinationCSVRepoStub) UpsertByNameCountry(_ context.Context, de
 	s.upserted = append(s.upserted, *dest)
 	return nil
 }
+func (s *customDestinationCSVRepoStub) UpsertBatch(_ context.Context, dests []domain.CustomDestination) error {
+	if s.upsertErr != nil {
+		return s.upsertErr
+	}
+	s.upserted = append(s.upserted, dests...)
+	return nil
+}
 
 func TestCustomDestinationServiceExportCSV(t *testing.T) {
 	repo := &customDestinationCSVRepoStub{listItems: []domain.CustomDestination{{
@@ -67,7 +76,7 @@ func TestCustomDestinationServiceImportCSV(t *testing.T) {
 	svc := NewCustomDestinationService(repo)
 	input := bytes.NewBufferString("name,country,latitude,longitude,keywords,sort_order,status,description\n布宜诺斯艾利斯,阿根廷,-34.6037,-58.3816,\"布宜诺斯艾利斯,buenos aires\",90,1,manual\n")
 
-	summary, err := svc.ImportCSV(context.Background(), input)
+	summary, err := svc.ImportCSV(context.Background(), input, csvimport.Options{})
 	if err != nil {
 		t.Fatalf("ImportCSV returned error: %v", err)
 	}
@@ -90,9 +99,58 @@ func TestCustomDestinationServiceImportCSVRejectsMissingCoords(t *testing.T) {
 	svc := NewCustomDestinationService(repo)
 	input := bytes.NewBufferString("name,country,latitude,longitude,keywords,sort_order,status,description\n雷克雅未克,冰岛,,,reykjavik,80,1,manual\n")
 
-	_, err := svc.ImportCSV(context.Background(), input)
-	if err == nil || !strings.Contains(err.Error(), "row 2") {
-		t.Fatalf("expected missing coordinate row error, got %v", err)
+	summary, err := svc.ImportCSV(context.Background(), input, csvimport.Options{})
+	if err != nil {
+		t.Fatalf("ImportCSV returned error: %v", err)
+	}
+	if summary.Imported != 0 || summary.Failed != 1 || len(repo.upserted) != 0 {
+		t.Fatalf("expected row to be rejected without writes, got %+v upserted=%+v", summary, repo.upserted)
+	}
+	if len(summary.Errors) != 2 || summary.Errors[0].Row != 2 || summary.Errors[0].Column != "latitude" || summary.Errors[1].Column != "longitude" {
+		t.Fatalf("expected missing coordinate row errors, got %+v", summary.Errors)
+	}
+}
+
+func TestCustomDestinationServiceImportCSVModes(t *testing.T) {
+	input := "\ufeff名称,国家,纬度,经度,状态\n" +
+		"温哥华,加拿大,49.2827,-123.1207,0\n" +
+		"奥克兰,新西兰,-95,174.76,1\n" +
+		"温哥华,加拿大,49.2827,-123.1207,1\n" +
+		"悉尼,澳大利亚,-33.8688,151.2093,2\n" +
+		"惠灵顿,新西兰,-41.2865,174.7762,\n"
+
+	repo := &customDestinationCSVRepoStub{}
+	svc := NewCustomDestinationService(repo)
+	summary, err := svc.ImportCSV(context.Background(), strings.NewReader(input), csvimport.Options{})
+	if err != nil {
+		t.Fatalf("ImportCSV returned error: %v", err)
+	}
+	if summary.Committed || summary.Total != 5 || summary.Succeeded != 2 || summary.Failed != 3 || len(repo.upserted) != 0 {
+		t.Fatalf("all_or_nothing should not write when any row fails: %+v", summary)
+	}
+	want := map[int]string{3: "latitude", 4: "", 5: "status"}
+	for _, rowErr := range summary.Errors {
+		column, ok := want[rowErr.Row]
+		if !ok || rowErr.Column != column {
+			t.Fatalf("unexpected row error: %+v", rowErr)
+		}
+	}
+
+	summary, err = svc.ImportCSV(context.Background(), strings.NewReader(input), csvimport.Options{Mode: csvimport.ModeBestEffort, DryRun: true})
+	if err != nil || summary.Committed || summary.Succeeded != 2 || len(repo.upserted) != 0 {
+		t.Fatalf("dry run must not write: %+v (%v)", summary, err)
+	}
+
+	summary, err = svc.ImportCSV(context.Background(), strings.NewReader(input), csvimport.Options{Mode: csvimport.ModeBestEffort})
+	if err != nil || !summary.Committed || summary.Imported != 2 || len(repo.upserted) != 2 {
+		t.Fatalf("best_effort should write valid rows: %+v (%v)", summary, err)
+	}
+	if repo.upserted[0].Status != 0 || repo.upserted[1].Status != 1 || *repo.upserted[1].Longitude != 174.7762 {
+		t.Fatalf("unexpected imported rows: %+v", repo.upserted)
+	}
+
+	if _, err := svc.ImportCSV(context.Background(), strings.NewReader("name,country\n迈阿密,美国\n"), csvimport.Options{}); !errors.Is(err, csvimport.ErrInvalidFile) {
+		t.Fatalf("expected missing coordinate columns to be rejected, got %v", err)
 	}
 }
 
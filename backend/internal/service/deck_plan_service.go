@@ -0,0 +1,401 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	DeckCabinAvailable   = "available"   // 可选
+	DeckCabinSoldOut     = "sold_out"    // 已售罄
+	DeckCabinTooSmall    = "too_small"   // 最大入住人数小于查询人数
+	DeckCabinUnavailable = "unavailable" // 舱房下架或航次停售
+)
+
+const (
+	deckMapDefaultGuests = 2    // 未指定人数时按双人入住取价
+	deckPlanMaxCabins    = 1000 // 单张平面图的舱位坐标上限
+)
+
+var (
+	// ErrInvalidDeckPlan 表示甲板平面图或舱位坐标参数不合法。
+	ErrInvalidDeckPlan = errors.New("invalid deck plan")
+	// ErrDeckPlanNotFound 表示平面图、邮轮或航次不存在。
+	ErrDeckPlanNotFound = errors.New("deck plan not found")
+	// ErrDeckPlanConflict 表示同一邮轮的甲板层已有平面图。
+	ErrDeckPlanConflict = errors.New("deck plan conflict")
+)
+
+// DeckPlanVoyages 提供舱位图所属航次。
+type DeckPlanVoyages interface {
+	GetByID(ctx context.Context, id int64) (*domain.Voyage, error)
+}
+
+// DeckPlanCabins 提供航次下的舱房 SKU。
+type DeckPlanCabins interface {
+	ListSKUByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error)
+}
+
+// DeckPlanCruises 提供平面图所属邮轮，用于创建时校验。
+type DeckPlanCruises interface {
+	GetByID(ctx context.Context, id int64) (*domain.Cruise, error)
+}
+
+// VoyageDeckMap 是航次按甲板分层的舱位图。
+type VoyageDeckMap struct {
+	VoyageID int64         `json:"voyage_id"`
+	CruiseID int64         `json:"cruise_id"`
+	Bookable bool          `json:"bookable"` // 航次是否开放预订
+	Guests   int           `json:"guests"`   // 取价与容量判断使用的入住人数
+	Decks    []DeckMapDeck `json:"decks"`
+}
+
+// DeckMapDeck 是一层甲板的舱位；有平面图时附带图片与尺寸。
+type DeckMapDeck struct {
+	Deck           string         `json:"deck"`
+	Name           string         `json:"name,omitempty"`
+	ImageURL       string         `json:"image_url,omitempty"`
+	Width          int            `json:"width,omitempty"`
+	Height         int            `json:"height,omitempty"`
+	AvailableCount int            `json:"available_count"`
+	Cabins         []DeckMapCabin `json:"cabins"`
+}
+
+// DeckMapCabin 是舱位图上的单个舱房，ID 即下单时的 cabin_sku_id。
+type DeckMapCabin struct {
+	ID            int64         `json:"id"`
+	Code          string        `json:"code"`
+	CabinNumber   string        `json:"cabin_number"`
+	CabinTypeID   int64         `json:"cabin_type_id"`
+	CabinTypeName string        `json:"cabin_type_name,omitempty"`
+	Position      string        `json:"position,omitempty"`
+	Orientation   string        `json:"orientation,omitempty"`
+	HasWindow     bool          `json:"has_window"`
+	HasBalcony    bool          `json:"has_balcony"`
+	MaxGuests     int           `json:"max_guests"`
+	Area          float64       `json:"area,omitempty"`
+	BedType       string        `json:"bed_type,omitempty"`
+	Amenities     string        `json:"amenities,omitempty"`
+	Grade         string        `json:"grade,omitempty"`
+	Status        string        `json:"status"` // available / sold_out / too_small / unavailable
+	Available     bool          `json:"available"`
+	PriceCents    int64         `json:"price_cents,omitempty"` // 出发日按入住人数的日历价格（分），未定价时为空
+	Shape         *DeckMapShape `json:"shape,omitempty"`       // 平面图上的区域，未标注时为空
+}
+
+// DeckMapShape 是舱房在平面图上的矩形区域（像素）。
+type DeckMapShape struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// DeckPlanService 维护邮轮甲板平面图，并为航次生成按甲板分层的舱位图。
+type DeckPlanService struct {
+	repo    domain.DeckPlanRepository
+	voyages DeckPlanVoyages
+	cabins  DeckPlanCabins
+	cruises DeckPlanCruises
+}
+
+// NewDeckPlanService 创建甲板平面图服务。
+func NewDeckPlanService(repo domain.DeckPlanRepository, voyages DeckPlanVoyages, cabins DeckPlanCabins, cruises DeckPlanCruises) *DeckPlanService {
+	return &DeckPlanService{repo: repo, voyages: voyages, cabins: cabins, cruises: cruises}
+}
+
+// Create 创建甲板平面图。
+func (s *DeckPlanService) Create(ctx context.Context, plan *domain.DeckPlan) error {
+	if err := normalizeDeckPlan(plan); err != nil {
+		return err
+	}
+	if _, err := s.cruises.GetByID(ctx, plan.CruiseID); err != nil {
+		return translateDeckPlanError(err)
+	}
+	plan.ID = 0
+	return translateDeckPlanError(s.repo.Create(ctx, plan))
+}
+
+// Update 更新平面图基础信息，所属邮轮不可变更；舱位坐标通过 ReplaceCabins 维护。
+func (s *DeckPlanService) Update(ctx context.Context, plan *domain.DeckPlan) error {
+	existing, err := s.Get(ctx, plan.ID)
+	if err != nil {
+		return err
+	}
+	plan.CruiseID = existing.CruiseID
+	plan.CreatedAt = existing.CreatedAt
+	if err := normalizeDeckPlan(plan); err != nil {
+		return err
+	}
+	for _, cabin := range existing.Cabins {
+		if err := checkCabinInsidePlan(plan, cabin); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Update(ctx, plan); err != nil {
+		return translateDeckPlanError(err)
+	}
+	plan.Cabins = existing.Cabins
+	return nil
+}
+
+// Get 查询平面图及其舱位坐标。
+func (s *DeckPlanService) Get(ctx context.Context, id int64) (*domain.DeckPlan, error) {
+	plan, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateDeckPlanError(err)
+	}
+	return plan, nil
+}
+
+// ListByCruise 查询邮轮的全部甲板平面图。
+func (s *DeckPlanService) ListByCruise(ctx context.Context, cruiseID int64) ([]domain.DeckPlan, error) {
+	if cruiseID <= 0 {
+		return nil, fmt.Errorf("%w: cruise_id is required", ErrInvalidDeckPlan)
+	}
+	return s.repo.ListByCruise(ctx, cruiseID)
+}
+
+// Delete 删除平面图及其舱位坐标。
+func (s *DeckPlanService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ReplaceCabins 校验并整体替换平面图的舱位坐标，返回更新后的平面图。
+func (s *DeckPlanService) ReplaceCabins(ctx context.Context, planID int64, cabins []domain.DeckPlanCabin) (*domain.DeckPlan, error) {
+	plan, err := s.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cabins) > deckPlanMaxCabins {
+		return nil, fmt.Errorf("%w: at most %d cabins per deck plan", ErrInvalidDeckPlan, deckPlanMaxCabins)
+	}
+	seen := make(map[string]bool, len(cabins))
+	for i := range cabins {
+		cabin := &cabins[i]
+		cabin.CabinNumber = strings.TrimSpace(cabin.CabinNumber)
+		switch {
+		case cabin.CabinNumber == "" || len(cabin.CabinNumber) > 50:
+			return nil, fmt.Errorf("%w: cabin %d: cabin_number is required and at most 50 characters", ErrInvalidDeckPlan, i+1)
+		case seen[cabin.CabinNumber]:
+			return nil, fmt.Errorf("%w: duplicate cabin_number %s", ErrInvalidDeckPlan, cabin.CabinNumber)
+		}
+		seen[cabin.CabinNumber] = true
+		if err := checkCabinInsidePlan(plan, *cabin); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.ReplaceCabins(ctx, planID, cabins); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, planID)
+}
+
+// VoyageDeckMap 生成航次的舱位图：按甲板分层列出全部舱房及其可选状态、舱型、属性与出发日价格。
+// 有平面图的甲板按平面图排序并附带舱位坐标，其余甲板排在后面；deck 非空时只返回该层。
+func (s *DeckPlanService) VoyageDeckMap(ctx context.Context, voyageID int64, guests int, deck string) (*VoyageDeckMap, error) {
+	if guests <= 0 {
+		guests = deckMapDefaultGuests
+	}
+	voyage, err := s.voyages.GetByID(ctx, voyageID)
+	if err != nil {
+		return nil, translateDeckPlanError(err)
+	}
+	skus, err := s.cabins.ListSKUByVoyage(ctx, voyageID)
+	if err != nil {
+		return nil, err
+	}
+	// 排序与过滤不修改仓储返回的切片
+	skus = slices.Clone(skus)
+	deck = strings.TrimSpace(deck)
+	if deck != "" {
+		skus = slices.DeleteFunc(skus, func(sku domain.CabinSKU) bool { return strings.TrimSpace(sku.Deck) != deck })
+	}
+	plans, err := s.repo.ListByCruise(ctx, voyage.CruiseID)
+	if err != nil {
+		return nil, err
+	}
+
+	skuIDs := make([]int64, 0, len(skus))
+	typeIDs := []int64{}
+	for _, sku := range skus {
+		skuIDs = append(skuIDs, sku.ID)
+		if !slices.Contains(typeIDs, sku.CabinTypeID) {
+			typeIDs = append(typeIDs, sku.CabinTypeID)
+		}
+	}
+	inventories, err := s.repo.ListInventoriesBySKUs(ctx, skuIDs)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[int64]int, len(inventories))
+	for _, inv := range inventories {
+		available[inv.CabinSKUID] = inv.Total - inv.Locked - inv.Sold
+	}
+	prices, err := s.repo.ListPricesBySKUs(ctx, skuIDs)
+	if err != nil {
+		return nil, err
+	}
+	priceBySKU := map[int64]int64{}
+	for _, price := range prices {
+		if price.Occupancy != guests || !sameDay(price.Date, voyage.DepartDate) {
+			continue
+		}
+		if _, ok := priceBySKU[price.CabinSKUID]; !ok || price.PriceType == "" || price.PriceType == "base" {
+			priceBySKU[price.CabinSKUID] = price.PriceCents
+		}
+	}
+	cabinTypes, err := s.repo.ListCabinTypesByIDs(ctx, typeIDs)
+	if err != nil {
+		return nil, err
+	}
+	typeNames := make(map[int64]string, len(cabinTypes))
+	for _, ct := range cabinTypes {
+		typeNames[ct.ID] = ct.Name
+	}
+
+	result := &VoyageDeckMap{
+		VoyageID: voyage.ID,
+		CruiseID: voyage.CruiseID,
+		Bookable: voyage.Status == 1 && voyage.Disruption == "",
+		Guests:   guests,
+		Decks:    []DeckMapDeck{},
+	}
+	decks := map[string]*DeckMapDeck{}
+	shapes := map[string]map[string]DeckMapShape{}
+	order := []string{}
+	for _, plan := range plans {
+		if deck != "" && plan.Deck != deck {
+			continue
+		}
+		decks[plan.Deck] = &DeckMapDeck{Deck: plan.Deck, Name: plan.Name, ImageURL: plan.ImageURL, Width: plan.Width, Height: plan.Height, Cabins: []DeckMapCabin{}}
+		shapes[plan.Deck] = make(map[string]DeckMapShape, len(plan.Cabins))
+		for _, cabin := range plan.Cabins {
+			shapes[plan.Deck][cabin.CabinNumber] = DeckMapShape{X: cabin.X, Y: cabin.Y, Width: cabin.Width, Height: cabin.Height}
+		}
+		order = append(order, plan.Deck)
+	}
+	unplanned := []string{}
+	slices.SortFunc(skus, func(a, b domain.CabinSKU) int { return cmp.Compare(a.Code, b.Code) })
+	for _, sku := range skus {
+		skuDeck := strings.TrimSpace(sku.Deck)
+		group, ok := decks[skuDeck]
+		if !ok {
+			group = &DeckMapDeck{Deck: skuDeck, Cabins: []DeckMapCabin{}}
+			decks[skuDeck] = group
+			unplanned = append(unplanned, skuDeck)
+		}
+		cabin := DeckMapCabin{
+			ID:            sku.ID,
+			Code:          sku.Code,
+			CabinNumber:   deckCabinNumber(voyage.Code, sku.Code),
+			CabinTypeID:   sku.CabinTypeID,
+			CabinTypeName: typeNames[sku.CabinTypeID],
+			Position:      sku.Position,
+			Orientation:   sku.Orientation,
+			HasWindow:     sku.HasWindow,
+			HasBalcony:    sku.HasBalcony,
+			MaxGuests:     sku.MaxGuests,
+			Area:          sku.Area,
+			BedType:       sku.BedType,
+			Amenities:     sku.Amenities,
+			Grade:         sku.Grade,
+			PriceCents:    priceBySKU[sku.ID],
+		}
+		switch {
+		case !result.Bookable || sku.Status != 1:
+			cabin.Status = DeckCabinUnavailable
+		case available[sku.ID] <= 0:
+			cabin.Status = DeckCabinSoldOut
+		case sku.MaxGuests > 0 && sku.MaxGuests < guests:
+			cabin.Status = DeckCabinTooSmall
+		default:
+			cabin.Status = DeckCabinAvailable
+			cabin.Available = true
+			group.AvailableCount++
+		}
+		if shape, ok := shapes[skuDeck][cabin.CabinNumber]; ok {
+			cabin.Shape = &shape
+		}
+		group.Cabins = append(group.Cabins, cabin)
+	}
+	slices.SortFunc(unplanned, compareDecks)
+	for _, name := range append(order, unplanned...) {
+		result.Decks = append(result.Decks, *decks[name])
+	}
+	return result, nil
+}
+
+// deckCabinNumber 由舱房编号得到船上舱房号：去掉“航次编码-”前缀（克隆与系列生成的舱房编号即此格式）。
+func deckCabinNumber(voyageCode, skuCode string) string {
+	if voyageCode != "" && strings.HasPrefix(skuCode, voyageCode+"-") {
+		return strings.TrimPrefix(skuCode, voyageCode+"-")
+	}
+	return skuCode
+}
+
+// compareDecks 按甲板层数值排序，非数字的甲板按字符串排在数字甲板之后。
+func compareDecks(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return cmp.Compare(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return cmp.Compare(a, b)
+}
+
+func normalizeDeckPlan(plan *domain.DeckPlan) error {
+	plan.Deck = strings.TrimSpace(plan.Deck)
+	plan.Name = strings.TrimSpace(plan.Name)
+	plan.ImageURL = strings.TrimSpace(plan.ImageURL)
+	switch {
+	case plan.CruiseID <= 0:
+		return fmt.Errorf("%w: cruise_id is required", ErrInvalidDeckPlan)
+	case plan.Deck == "" || len(plan.Deck) > 20:
+		return fmt.Errorf("%w: deck is required and at most 20 characters", ErrInvalidDeckPlan)
+	case len(plan.Name) > 100:
+		return fmt.Errorf("%w: name must be at most 100 characters", ErrInvalidDeckPlan)
+	case len(plan.ImageURL) > 500:
+		return fmt.Errorf("%w: image_url must be at most 500 characters", ErrInvalidDeckPlan)
+	case plan.Width < 0 || plan.Height < 0:
+		return fmt.Errorf("%w: width and height must not be negative", ErrInvalidDeckPlan)
+	}
+	return nil
+}
+
+// checkCabinInsidePlan 校验舱位区域尺寸为正且位于平面图范围内（平面图未设置尺寸时不校验边界）。
+func checkCabinInsidePlan(plan *domain.DeckPlan, cabin domain.DeckPlanCabin) error {
+	if cabin.X < 0 || cabin.Y < 0 || cabin.Width <= 0 || cabin.Height <= 0 {
+		return fmt.Errorf("%w: cabin %s must have a non-negative position and positive size", ErrInvalidDeckPlan, cabin.CabinNumber)
+	}
+	if (plan.Width > 0 && cabin.X+cabin.Width > plan.Width) || (plan.Height > 0 && cabin.Y+cabin.Height > plan.Height) {
+		return fmt.Errorf("%w: cabin %s lies outside the %dx%d plan", ErrInvalidDeckPlan, cabin.CabinNumber, plan.Width, plan.Height)
+	}
+	return nil
+}
+
+func translateDeckPlanError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrDeckPlanNotFound
+	case errors.Is(err, domain.ErrDeckPlanExists):
+		return fmt.Errorf("%w: %v", ErrDeckPlanConflict, err)
+	}
+	return err
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type deckPlanRepoStub struct {
+	plans       map[int64]domain.DeckPlan
+	inventories []domain.CabinInventory
+	prices      []domain.CabinPrice
+	cabinTypes  []domain.CabinType
+}
+
+func newDeckPlanRepoStub() *deckPlanRepoStub {
+	return &deckPlanRepoStub{plans: map[int64]domain.DeckPlan{}}
+}
+
+func (s *deckPlanRepoStub) Create(_ context.Context, plan *domain.DeckPlan) error {
+	for _, existing := range s.plans {
+		if existing.CruiseID == plan.CruiseID && existing.Deck == plan.Deck {
+			return domain.ErrDeckPlanExists
+		}
+	}
+	plan.ID = int64(len(s.plans) + 1)
+	s.plans[plan.ID] = *plan
+	return nil
+}
+
+func (s *deckPlanRepoStub) Update(_ context.Context, plan *domain.DeckPlan) error {
+	stored := *plan
+	stored.Cabins = s.plans[plan.ID].Cabins
+	s.plans[plan.ID] = stored
+	return nil
+}
+
+func (s *deckPlanRepoStub) GetByID(_ context.Context, id int64) (*domain.DeckPlan, error) {
+	plan, ok := s.plans[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &plan, nil
+}
+
+func (s *deckPlanRepoStub) ListByCruise(_ context.Context, cruiseID int64) ([]domain.DeckPlan, error) {
+	plans := []domain.DeckPlan{}
+	for id := int64(1); id <= int64(len(s.plans)); id++ {
+		if plan, ok := s.plans[id]; ok && plan.CruiseID == cruiseID {
+			plans = append(plans, plan)
+		}
+	}
+	return plans, nil
+}
+
+func (s *deckPlanRepoStub) Delete(_ context.Context, id int64) error {
+	delete(s.plans, id)
+	return nil
+}
+
+func (s *deckPlanRepoStub) ReplaceCabins(_ context.Context, planID int64, cabins []domain.DeckPlanCabin) error {
+	plan := s.plans[planID]
+	plan.Cabins = cabins
+	s.plans[planID] = plan
+	return nil
+}
+
+func (s *deckPlanRepoStub) ListInventoriesBySKUs(context.Context, []int64) ([]domain.CabinInventory, error) {
+	return s.inventories, nil
+}
+
+func (s *deckPlanRepoStub) ListPricesBySKUs(context.Context, []int64) ([]domain.CabinPrice, error) {
+	return s.prices, nil
+}
+
+func (s *deckPlanRepoStub) ListCabinTypesByIDs(context.Context, []int64) ([]domain.CabinType, error) {
+	return s.cabinTypes, nil
+}
+
+type deckPlanCruiseStub struct{}
+
+func (deckPlanCruiseStub) GetByID(_ context.Context, id int64) (*domain.Cruise, error) {
+	if id != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &domain.Cruise{ID: id}, nil
+}
+
+func TestDeckPlanServiceManagesPlans(t *testing.T) {
+	repo := newDeckPlanRepoStub()
+	svc := NewDeckPlanService(repo, seriesTemplateStub{}, seriesCabinStub{}, deckPlanCruiseStub{})
+	ctx := context.Background()
+
+	plan := &domain.DeckPlan{CruiseID: 1, Deck: " 8 ", Width: 400, Height: 200}
+	if err := svc.Create(ctx, plan); err != nil || plan.Deck != "8" {
+		t.Fatalf("Create returned %v, plan %+v", err, plan)
+	}
+	if err := svc.Create(ctx, &domain.DeckPlan{CruiseID: 1, Deck: "8"}); !errors.Is(err, ErrDeckPlanConflict) {
+		t.Fatalf("expected ErrDeckPlanConflict, got %v", err)
+	}
+	if err := svc.Create(ctx, &domain.DeckPlan{CruiseID: 7, Deck: "9"}); !errors.Is(err, ErrDeckPlanNotFound) {
+		t.Fatalf("expected ErrDeckPlanNotFound for unknown cruise, got %v", err)
+	}
+	if err := svc.Create(ctx, &domain.DeckPlan{CruiseID: 1, Deck: ""}); !errors.Is(err, ErrInvalidDeckPlan) {
+		t.Fatalf("expected ErrInvalidDeckPlan for empty deck, got %v", err)
+	}
+
+	invalid := [][]domain.DeckPlanCabin{
+		{{CabinNumber: "8001", Width: 10, Height: 10}, {CabinNumber: "8001", Width: 10, Height: 10}},
+		{{CabinNumber: " ", Width: 10, Height: 10}},
+		{{CabinNumber: "8001", Width: 0, Height: 10}},
+		{{CabinNumber: "8001", X: 395, Width: 10, Height: 10}},
+	}
+	for i, cabins := range invalid {
+		if _, err := svc.ReplaceCabins(ctx, plan.ID, cabins); !errors.Is(err, ErrInvalidDeckPlan) {
+			t.Fatalf("case %d: expected ErrInvalidDeckPlan, got %v", i, err)
+		}
+	}
+	updated, err := svc.ReplaceCabins(ctx, plan.ID, []domain.DeckPlanCabin{{CabinNumber: " 8001 ", X: 300, Y: 100, Width: 50, Height: 50}})
+	if err != nil || len(updated.Cabins) != 1 || updated.Cabins[0].CabinNumber != "8001" {
+		t.Fatalf("ReplaceCabins returned %v, plan %+v", err, updated)
+	}
+
+	shrunk := &domain.DeckPlan{ID: plan.ID, CruiseID: 99, Deck: "8", Width: 320, Height: 200}
+	if err := svc.Update(ctx, shrunk); !errors.Is(err, ErrInvalidDeckPlan) {
+		t.Fatalf("shrinking the plan below its cabins should fail, got %v", err)
+	}
+	renamed := &domain.DeckPlan{ID: plan.ID, CruiseID: 99, Deck: "8", Name: "海景甲板", Width: 400, Height: 200}
+	if err := svc.Update(ctx, renamed); err != nil || renamed.CruiseID != 1 || len(renamed.Cabins) != 1 {
+		t.Fatalf("Update returned %v, plan %+v", err, renamed)
+	}
+	if err := svc.Delete(ctx, plan.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if _, err := svc.Get(ctx, plan.ID); !errors.Is(err, ErrDeckPlanNotFound) {
+		t.Fatalf("expected ErrDeckPlanNotFound after delete, got %v", err)
+	}
+	if _, err := svc.ListByCruise(ctx, 0); !errors.Is(err, ErrInvalidDeckPlan) {
+		t.Fatalf("expected ErrInvalidDeckPlan without cruise, got %v", err)
+	}
+}
+
+func TestDeckPlanServiceBuildsVoyageDeckMap(t *testing.T) {
+	depart := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	voyage := &domain.Voyage{ID: 5, CruiseID: 1, Code: "MSC2612", DepartDate: depart, Status: 1}
+	cabins := seriesCabinStub{skus: []domain.CabinSKU{
+		{ID: 11, CabinTypeID: 3, Code: "MSC2612-8002", Deck: "8", MaxGuests: 2, Position: "fore", Orientation: "port", HasWindow: true, Status: 1},
+		{ID: 12, CabinTypeID: 3, Code: "MSC2612-8001", Deck: "8", MaxGuests: 4, Status: 1},
+		{ID: 13, CabinTypeID: 4, Code: "MSC2612-8003", Deck: "8", MaxGuests: 4, Status: 1},
+		{ID: 14, CabinTypeID: 4, Code: "MSC2612-8004", Deck: "8", MaxGuests: 4, Status: 0},
+		{ID: 21, CabinTypeID: 4, Code: "MSC2612-12001", Deck: "12", MaxGuests: 4, Status: 1},
+		{ID: 22, CabinTypeID: 4, Code: "MSC2612-3001", Deck: "3", MaxGuests: 4, Status: 1},
+	}}
+	repo := newDeckPlanRepoStub()
+	repo.plans[1] = domain.DeckPlan{ID: 1, CruiseID: 1, Deck: "8", Name: "8 层", ImageURL: "https://cdn/8.png", Width: 800, Height: 300,
+		Cabins: []domain.DeckPlanCabin{{CabinNumber: "8001", X: 10, Y: 20, Width: 30, Height: 40}}}
+	repo.inventories = []domain.CabinInventory{
+		{CabinSKUID: 11, Total: 1}, {CabinSKUID: 12, Total: 1}, {CabinSKUID: 13, Total: 1, Sold: 1},
+		{CabinSKUID: 14, Total: 1}, {CabinSKUID: 21, Total: 1}, {CabinSKUID: 22, Total: 1},
+	}
+	repo.prices = []domain.CabinPrice{
+		{CabinSKUID: 12, Date: depart, Occupancy: 3, PriceCents: 900000, PriceType: "base"},
+		{CabinSKUID: 12, Date: depart.Add(24 * time.Hour), Occupancy: 3, PriceCents: 1, PriceType: "base"},
+		{CabinSKUID: 12, Date: depart, Occupancy: 3, PriceCents: 950000, PriceType: "holiday"},
+		{CabinSKUID: 12, Date: depart, Occupancy: 2, PriceCents: 700000, PriceType: "base"},
+	}
+	repo.cabinTypes = []domain.CabinType{{ID: 3, Name: "海景房"}, {ID: 4, Name: "阳台房"}}
+	svc := NewDeckPlanService(repo, seriesTemplateStub{voyage: voyage}, cabins, deckPlanCruiseStub{})
+	ctx := context.Background()
+
+	deckMap, err := svc.VoyageDeckMap(ctx, 5, 3, "")
+	if err != nil {
+		t.Fatalf("VoyageDeckMap returned error: %v", err)
+	}
+	if !deckMap.Bookable || deckMap.Guests != 3 || len(deckMap.Decks) != 3 {
+		t.Fatalf("unexpected deck map: %+v", deckMap)
+	}
+	if deckMap.Decks[0].Deck != "8" || deckMap.Decks[1].Deck != "3" || deckMap.Decks[2].Deck != "12" {
+		t.Fatalf("planned decks should come first, then decks in numeric order: %+v", deckMap.Decks)
+	}
+	deck := deckMap.Decks[0]
+	if deck.ImageURL != "https://cdn/8.png" || deck.AvailableCount != 1 || len(deck.Cabins) != 4 {
+		t.Fatalf("unexpected deck: %+v", deck)
+	}
+	wantStatus := []string{DeckCabinAvailable, DeckCabinTooSmall, DeckCabinSoldOut, DeckCabinUnavailable}
+	for i, cabin := range deck.Cabins {
+		if cabin.Status != wantStatus[i] {
+			t.Fatalf("cabin %s status = %s, want %s", cabin.Code, cabin.Status, wantStatus[i])
+		}
+	}
+	first := deck.Cabins[0]
+	if first.ID != 12 || first.CabinNumber != "8001" || first.CabinTypeName != "海景房" || first.PriceCents != 900000 || !first.Available {
+		t.Fatalf("unexpected cabin: %+v", first)
+	}
+	if first.Shape == nil || *first.Shape != (DeckMapShape{X: 10, Y: 20, Width: 30, Height: 40}) {
+		t.Fatalf("cabin should carry its plan coordinates: %+v", first.Shape)
+	}
+	if second := deck.Cabins[1]; second.Shape != nil || second.Position != "fore" || !second.HasWindow || second.PriceCents != 0 {
+		t.Fatalf("unexpected cabin: %+v", second)
+	}
+
+	only, err := svc.VoyageDeckMap(ctx, 5, 0, "12")
+	if err != nil || only.Guests != 2 || len(only.Decks) != 1 || only.Decks[0].Cabins[0].ID != 21 {
+		t.Fatalf("deck filter should return a single deck: %+v (%v)", only, err)
+	}
+
+	voyage.Disruption = "cancelled"
+	closed, _ := svc.VoyageDeckMap(ctx, 5, 2, "8")
+	if closed.Bookable || closed.Decks[0].AvailableCount != 0 || closed.Decks[0].Cabins[0].Status != DeckCabinUnavailable {
+		t.Fatalf("disrupted voyages should not be selectable: %+v", closed)
+	}
+	if _, err := svc.VoyageDeckMap(ctx, 404, 2, ""); !errors.Is(err, ErrDeckPlanNotFound) {
+		t.Fatalf("expected ErrDeckPlanNotFound for unknown voyage, got %v", err)
+	}
+}
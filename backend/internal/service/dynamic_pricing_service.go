@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultVelocityWindowDays = 7  // 默认销售速度统计窗口（天）
+	maxVelocityWindowDays     = 90 // 销售速度统计窗口上限（天）
+)
+
+var (
+	// ErrInvalidPricingRule 表示动态调价规则参数不合法。
+	ErrInvalidPricingRule = errors.New("invalid dynamic pricing rule")
+	// ErrNoPricingChanges 表示当前没有任何航次舱型命中规则产生价格变化。
+	ErrNoPricingChanges = errors.New("no price changes proposed")
+	// ErrDynamicPricingNotFound 表示调价规则或提案不存在。
+	ErrDynamicPricingNotFound = errors.New("dynamic pricing record not found")
+	// ErrPricingProposalNotPending 表示提案已审批或已驳回，不能重复处理。
+	ErrPricingProposalNotPending = errors.New("pricing proposal is not pending")
+)
+
+// dynamicPricingVoyageStore 提供航次出发日期查询。
+type dynamicPricingVoyageStore interface {
+	GetByID(ctx context.Context, id int64) (*domain.Voyage, error)
+}
+
+// dynamicPricingPriceStore 提供当前价查询与价格版本写入，由 VoyageCabinTypePriceService 实现。
+type dynamicPricingPriceStore interface {
+	ListCurrentByVoyages(ctx context.Context, voyageIDs []int64) ([]domain.VoyageCabinTypeCurrent, error)
+	ApplyVersionAndRefreshCurrent(ctx context.Context, version *domain.VoyageCabinTypePriceVersion) error
+}
+
+// DynamicPricingRequest 描述一次调价试算/提案的范围。
+type DynamicPricingRequest struct {
+	VoyageIDs   []int64    // 参与调价的航次
+	CabinTypeID int64      // 仅对指定舱型调价，0 表示全部舱型
+	EffectiveAt *time.Time // 新价格生效时间，nil 表示审批即生效
+}
+
+// DynamicPricingService 基于上座率、距出发天数与销售速度的规则引擎生成调价提案，
+// 提案经审批后才写入价格版本。
+type DynamicPricingService struct {
+	repo    domain.DynamicPricingRepository
+	voyages dynamicPricingVoyageStore
+	prices  dynamicPricingPriceStore
+	now     func() time.Time
+}
+
+// NewDynamicPricingService 创建动态调价服务。
+func NewDynamicPricingService(repo domain.DynamicPricingRepository, voyages dynamicPricingVoyageStore, prices dynamicPricingPriceStore) *DynamicPricingService {
+	return &DynamicPricingService{repo: repo, voyages: voyages, prices: prices, now: time.Now}
+}
+
+func (s *DynamicPricingService) ListRules(ctx context.Context) ([]domain.DynamicPricingRule, error) {
+	return s.repo.ListRules(ctx, false)
+}
+
+func (s *DynamicPricingService) GetRule(ctx context.Context, id int64) (*domain.DynamicPricingRule, error) {
+	rule, err := s.repo.GetRule(ctx, id)
+	return rule, translateDynamicPricingNotFound(err)
+}
+
+func (s *DynamicPricingService) CreateRule(ctx context.Context, rule *domain.DynamicPricingRule) error {
+	if err := normalizePricingRule(rule); err != nil {
+		return err
+	}
+	return translateDynamicPricingNotFound(s.repo.CreateRule(ctx, rule))
+}
+
+func (s *DynamicPricingService) UpdateRule(ctx context.Context, rule *domain.DynamicPricingRule) error {
+	if rule.ID <= 0 {
+		return fmt.Errorf("%w: id is required", ErrInvalidPricingRule)
+	}
+	if err := normalizePricingRule(rule); err != nil {
+		return err
+	}
+	return translateDynamicPricingNotFound(s.repo.UpdateRule(ctx, rule))
+}
+
+func (s *DynamicPricingService) DeleteRule(ctx context.Context, id int64) error {
+	return translateDynamicPricingNotFound(s.repo.DeleteRule(ctx, id))
+}
+
+// Simulate 试算调价结果（dry-run），不写入任何数据。
+func (s *DynamicPricingService) Simulate(ctx context.Context, req DynamicPricingRequest) ([]domain.DynamicPricingProposalItem, error) {
+	return s.evaluate(ctx, req)
+}
+
+// Propose 试算并保存为待审批提案；没有任何价格变化时返回 ErrNoPricingChanges。
+func (s *DynamicPricingService) Propose(ctx context.Context, req DynamicPricingRequest, staffID int64) (*domain.DynamicPricingProposal, error) {
+	items, err := s.evaluate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNoPricingChanges
+	}
+	proposal := &domain.DynamicPricingProposal{
+		Status:      domain.PricingProposalStatusPending,
+		EffectiveAt: req.EffectiveAt,
+		CreatedBy:   staffID,
+		Items:       items,
+	}
+	if err := s.repo.CreateProposal(ctx, proposal); err != nil {
+		return nil, translateDynamicPricingNotFound(err)
+	}
+	return proposal, nil
+}
+
+func (s *DynamicPricingService) ListProposals(ctx context.Context, status string, page, pageSize int) ([]domain.DynamicPricingProposal, int64, error) {
+	return s.repo.ListProposals(ctx, status, page, pageSize)
+}
+
+func (s *DynamicPricingService) GetProposal(ctx context.Context, id int64) (*domain.DynamicPricingProposal, error) {
+	proposal, err := s.repo.GetProposal(ctx, id)
+	return proposal, translateDynamicPricingNotFound(err)
+}
+
+// Approve 审批通过提案并逐条生成价格版本。
+// 先以条件更新占用提案，避免并发审批重复生成版本；
+// 若某航次舱型的当前价版本在提案生成后已被他人修改，则跳过该明细，避免覆盖人工调价。
+func (s *DynamicPricingService) Approve(ctx context.Context, id, staffID int64, note string) (*domain.DynamicPricingProposal, error) {
+	proposal, err := s.pendingProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().In(shanghaiLocation)
+	proposal.Status = domain.PricingProposalStatusApproved
+	proposal.ReviewedBy = staffID
+	proposal.ReviewedAt = &now
+	proposal.ReviewNote = strings.TrimSpace(note)
+	if err := s.markReviewed(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	voyageIDs := make([]int64, 0, len(proposal.Items))
+	for _, item := range proposal.Items {
+		voyageIDs = append(voyageIDs, item.VoyageID)
+	}
+	currents, err := s.prices.ListCurrentByVoyages(ctx, uniqueInt64s(voyageIDs))
+	if err != nil {
+		return nil, err
+	}
+	currentVersion := make(map[[2]int64]int64, len(currents))
+	for _, cur := range currents {
+		currentVersion[[2]int64{cur.VoyageID, cur.CabinTypeID}] = cur.VersionID
+	}
+
+	effectiveAt := now
+	if proposal.EffectiveAt != nil && proposal.EffectiveAt.After(now) {
+		effectiveAt = proposal.EffectiveAt.In(shanghaiLocation)
+	}
+	createdBy := staffID
+	var applyErr error
+	for i := range proposal.Items {
+		item := &proposal.Items[i]
+		if currentVersion[[2]int64{item.VoyageID, item.CabinTypeID}] != item.BaseVersionID {
+			item.Status = domain.PricingProposalItemStatusSkipped
+			item.Reason = strings.TrimSpace(item.Reason + "；当前价已变更，已跳过")
+			continue
+		}
+		version := &domain.VoyageCabinTypePriceVersion{
+			VoyageID:             item.VoyageID,
+			CabinTypeID:          item.CabinTypeID,
+			InventoryTotal:       item.InventoryTotal,
+			SettlementPriceCents: item.SettlementPriceCents,
+			SalePriceCents:       item.ProposedSalePriceCents,
+			EffectiveAt:          effectiveAt,
+			CreatedBy:            &createdBy,
+		}
+		if applyErr = s.prices.ApplyVersionAndRefreshCurrent(ctx, version); applyErr != nil {
+			break
+		}
+		item.Status = domain.PricingProposalItemStatusApplied
+		item.VersionID = version.ID
+	}
+	// 即使中途失败也回写已生效的明细，便于追溯哪些航次舱型已调价。
+	if err := s.repo.UpdateProposalItems(ctx, proposal.Items); err != nil {
+		return nil, err
+	}
+	if applyErr != nil {
+		return nil, applyErr
+	}
+	return proposal, nil
+}
+
+// Reject 驳回提案，不生成任何价格版本。
+func (s *DynamicPricingService) Reject(ctx context.Context, id, staffID int64, note string) (*domain.DynamicPricingProposal, error) {
+	proposal, err := s.pendingProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().In(shanghaiLocation)
+	proposal.Status = domain.PricingProposalStatusRejected
+	proposal.ReviewedBy = staffID
+	proposal.ReviewedAt = &now
+	proposal.ReviewNote = strings.TrimSpace(note)
+	if err := s.markReviewed(ctx, proposal); err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+func (s *DynamicPricingService) markReviewed(ctx context.Context, proposal *domain.DynamicPricingProposal) error {
+	err := s.repo.MarkProposalReviewed(ctx, proposal)
+	if errors.Is(err, domain.ErrPricingProposalReviewed) {
+		return ErrPricingProposalNotPending
+	}
+	return err
+}
+
+func (s *DynamicPricingService) pendingProposal(ctx context.Context, id int64) (*domain.DynamicPricingProposal, error) {
+	proposal, err := s.GetProposal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != domain.PricingProposalStatusPending {
+		return nil, ErrPricingProposalNotPending
+	}
+	return proposal, nil
+}
+
+// evaluate 对指定航次的当前价逐一匹配规则，返回发生价格变化的明细。
+func (s *DynamicPricingService) evaluate(ctx context.Context, req DynamicPricingRequest) ([]domain.DynamicPricingProposalItem, error) {
+	voyageIDs := uniqueInt64s(req.VoyageIDs)
+	if len(voyageIDs) == 0 {
+		return nil, fmt.Errorf("%w: voyage_ids cannot be empty", ErrInvalidPricingRule)
+	}
+	rules, err := s.repo.ListRules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]domain.DynamicPricingProposalItem, 0)
+	if len(rules) == 0 {
+		return items, nil
+	}
+
+	currents, err := s.prices.ListCurrentByVoyages(ctx, voyageIDs)
+	if err != nil {
+		return nil, err
+	}
+	inventoryStats, err := s.repo.ListInventoryStats(ctx, voyageIDs)
+	if err != nil {
+		return nil, err
+	}
+	inventory := make(map[[2]int64]domain.PricingInventoryStat, len(inventoryStats))
+	for _, stat := range inventoryStats {
+		inventory[[2]int64{stat.VoyageID, stat.CabinTypeID}] = stat
+	}
+
+	now := s.now().In(shanghaiLocation)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, shanghaiLocation)
+
+	// 不同规则可配置不同的统计窗口，按窗口分别统计销量。
+	sales := map[int]map[[2]int64]int64{}
+	for i := range rules {
+		if rules[i].VelocityWindowDays <= 0 {
+			rules[i].VelocityWindowDays = defaultVelocityWindowDays
+		}
+		window := rules[i].VelocityWindowDays
+		if _, ok := sales[window]; ok {
+			continue
+		}
+		stats, err := s.repo.ListSalesStats(ctx, voyageIDs, today.AddDate(0, 0, -window))
+		if err != nil {
+			return nil, err
+		}
+		byKey := make(map[[2]int64]int64, len(stats))
+		for _, stat := range stats {
+			byKey[[2]int64{stat.VoyageID, stat.CabinTypeID}] = stat.Sold
+		}
+		sales[window] = byKey
+	}
+
+	daysToDeparture := map[int64]int{}
+	for _, voyageID := range voyageIDs {
+		voyage, err := s.voyages.GetByID(ctx, voyageID)
+		if err != nil {
+			return nil, err
+		}
+		depart := voyage.DepartDate.In(shanghaiLocation)
+		departDay := time.Date(depart.Year(), depart.Month(), depart.Day(), 0, 0, 0, 0, shanghaiLocation)
+		daysToDeparture[voyageID] = int(math.Round(departDay.Sub(today).Hours() / 24))
+	}
+
+	for _, cur := range currents {
+		if req.CabinTypeID > 0 && cur.CabinTypeID != req.CabinTypeID {
+			continue
+		}
+		days, ok := daysToDeparture[cur.VoyageID]
+		if !ok || days < 0 {
+			continue // 非本次范围或已出发的航次不再调价
+		}
+		key := [2]int64{cur.VoyageID, cur.CabinTypeID}
+		loadFactor := pricingLoadFactor(inventory[key])
+
+		for _, rule := range rules {
+			velocity := float64(sales[rule.VelocityWindowDays][key]) / float64(rule.VelocityWindowDays)
+			if !pricingRuleMatches(rule, cur, loadFactor, days, velocity) {
+				continue
+			}
+			proposed, bound := applyPricingRule(rule, cur)
+			if proposed != cur.SalePriceCents {
+				reason := fmt.Sprintf("规则「%s」：上座率 %.1f%%，距出发 %d 天，日均售出 %.2f 间，调整 %+.1f%%%s",
+					rule.Name, loadFactor*100, days, velocity, rule.AdjustPercent, bound)
+				items = append(items, domain.DynamicPricingProposalItem{
+					VoyageID:               cur.VoyageID,
+					CabinTypeID:            cur.CabinTypeID,
+					RuleID:                 rule.ID,
+					BaseVersionID:          cur.VersionID,
+					InventoryTotal:         cur.InventoryTotal,
+					SettlementPriceCents:   cur.SettlementPriceCents,
+					CurrentSalePriceCents:  cur.SalePriceCents,
+					ProposedSalePriceCents: proposed,
+					LoadFactor:             roundRatio(loadFactor),
+					DaysToDeparture:        days,
+					Velocity:               roundRatio(velocity),
+					Reason:                 reason,
+					Status:                 domain.PricingProposalItemStatusPending,
+				})
+			}
+			break // 仅应用优先级最高的命中规则
+		}
+	}
+	return items, nil
+}
+
+// pricingLoadFactor 计算上座率 = 已售 / 库存总量。
+func pricingLoadFactor(stat domain.PricingInventoryStat) float64 {
+	if stat.Total <= 0 {
+		if stat.Sold > 0 {
+			return 1
+		}
+		return 0
+	}
+	return math.Min(float64(stat.Sold)/float64(stat.Total), 1)
+}
+
+func pricingRuleMatches(rule domain.DynamicPricingRule, cur domain.VoyageCabinTypeCurrent, loadFactor float64, days int, velocity float64) bool {
+	if rule.VoyageID > 0 && rule.VoyageID != cur.VoyageID {
+		return false
+	}
+	if rule.CabinTypeID > 0 && rule.CabinTypeID != cur.CabinTypeID {
+		return false
+	}
+	if rule.MinLoadFactor != nil && loadFactor < *rule.MinLoadFactor {
+		return false
+	}
+	if rule.MaxLoadFactor != nil && loadFactor >= *rule.MaxLoadFactor {
+		return false
+	}
+	if rule.MinDaysToDeparture != nil && days < *rule.MinDaysToDeparture {
+		return false
+	}
+	if rule.MaxDaysToDeparture != nil && days > *rule.MaxDaysToDeparture {
+		return false
+	}
+	if rule.MinVelocity != nil && velocity < *rule.MinVelocity {
+		return false
+	}
+	if rule.MaxVelocity != nil && velocity >= *rule.MaxVelocity {
+		return false
+	}
+	return true
+}
+
+// applyPricingRule 按比例调整当前售价并取整到元，再以结算价为基准夹在下限/上限之间。
+// 第二个返回值描述是否触及边界，用于调价说明。
+func applyPricingRule(rule domain.DynamicPricingRule, cur domain.VoyageCabinTypeCurrent) (int64, string) {
+	proposed := int64(math.Round(float64(cur.SalePriceCents)*(1+rule.AdjustPercent/100)/100)) * 100
+	floor := int64(math.Ceil(float64(cur.SettlementPriceCents) * (1 + rule.FloorMarkupPercent/100)))
+	if proposed < floor {
+		return floor, "（触及下限）"
+	}
+	if rule.CeilingMarkupPercent != nil {
+		ceiling := int64(math.Floor(float64(cur.SettlementPriceCents) * (1 + *rule.CeilingMarkupPercent/100)))
+		if proposed > ceiling {
+			return ceiling, "（触及上限）"
+		}
+	}
+	return proposed, ""
+}
+
+// normalizePricingRule 校验规则参数并补全默认值。
+func normalizePricingRule(rule *domain.DynamicPricingRule) error {
+	if rule == nil {
+		return fmt.Errorf("%w: rule is required", ErrInvalidPricingRule)
+	}
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPricingRule)
+	}
+	if rule.VelocityWindowDays == 0 {
+		rule.VelocityWindowDays = defaultVelocityWindowDays
+	}
+	if rule.VelocityWindowDays < 1 || rule.VelocityWindowDays > maxVelocityWindowDays {
+		return fmt.Errorf("%w: velocity_window_days must be between 1 and %d", ErrInvalidPricingRule, maxVelocityWindowDays)
+	}
+	for _, lf := range []*float64{rule.MinLoadFactor, rule.MaxLoadFactor} {
+		if lf != nil && (*lf < 0 || *lf > 1) {
+			return fmt.Errorf("%w: load factor bounds must be between 0 and 1", ErrInvalidPricingRule)
+		}
+	}
+	if rule.MinLoadFactor != nil && rule.MaxLoadFactor != nil && *rule.MinLoadFactor >= *rule.MaxLoadFactor {
+		return fmt.Errorf("%w: min_load_factor must be less than max_load_factor", ErrInvalidPricingRule)
+	}
+	if rule.MinDaysToDeparture != nil && rule.MaxDaysToDeparture != nil && *rule.MinDaysToDeparture > *rule.MaxDaysToDeparture {
+		return fmt.Errorf("%w: min_days_to_departure must not exceed max_days_to_departure", ErrInvalidPricingRule)
+	}
+	if rule.MinVelocity != nil && rule.MaxVelocity != nil && *rule.MinVelocity >= *rule.MaxVelocity {
+		return fmt.Errorf("%w: min_velocity must be less than max_velocity", ErrInvalidPricingRule)
+	}
+	if rule.AdjustPercent <= -100 || rule.AdjustPercent > 300 {
+		return fmt.Errorf("%w: adjust_percent must be within (-100, 300]", ErrInvalidPricingRule)
+	}
+	if rule.FloorMarkupPercent < 0 {
+		return fmt.Errorf("%w: floor_markup_percent must not be negative", ErrInvalidPricingRule)
+	}
+	if rule.CeilingMarkupPercent != nil && *rule.CeilingMarkupPercent < rule.FloorMarkupPercent {
+		return fmt.Errorf("%w: ceiling_markup_percent must not be below floor_markup_percent", ErrInvalidPricingRule)
+	}
+	if rule.Status != 0 && rule.Status != 1 {
+		return fmt.Errorf("%w: status must be 0 or 1", ErrInvalidPricingRule)
+	}
+	return nil
+}
+
+func translateDynamicPricingNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDynamicPricingNotFound
+	}
+	return err
+}
+
+// uniqueInt64s 去重并排除非正数 ID，返回升序结果。
+func uniqueInt64s(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type fakeDynamicPricingRepo struct {
+	rules        []domain.DynamicPricingRule
+	inventory    []domain.PricingInventoryStat
+	sales        map[int][]domain.PricingSalesStat // 按统计窗口（天）返回的销量
+	proposals    map[int64]*domain.DynamicPricingProposal
+	salesSince   []time.Time
+	updatedItems []domain.DynamicPricingProposalItem
+}
+
+func (f *fakeDynamicPricingRepo) ListRules(_ context.Context, _ bool) ([]domain.DynamicPricingRule, error) {
+	return append([]domain.DynamicPricingRule(nil), f.rules...), nil
+}
+
+func (f *fakeDynamicPricingRepo) GetRule(_ context.Context, id int64) (*domain.DynamicPricingRule, error) {
+	for i := range f.rules {
+		if f.rules[i].ID == id {
+			return &f.rules[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeDynamicPricingRepo) CreateRule(_ context.Context, rule *domain.DynamicPricingRule) error {
+	rule.ID = int64(len(f.rules) + 1)
+	f.rules = append(f.rules, *rule)
+	return nil
+}
+
+func (f *fakeDynamicPricingRepo) UpdateRule(_ context.Context, rule *domain.DynamicPricingRule) error {
+	for i := range f.rules {
+		if f.rules[i].ID == rule.ID {
+			f.rules[i] = *rule
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (f *fakeDynamicPricingRepo) DeleteRule(_ context.Context, _ int64) error { return nil }
+
+func (f *fakeDynamicPricingRepo) CreateProposal(_ context.Context, proposal *domain.DynamicPricingProposal) error {
+	if f.proposals == nil {
+		f.proposals = map[int64]*domain.DynamicPricingProposal{}
+	}
+	proposal.ID = int64(len(f.proposals) + 1)
+	for i := range proposal.Items {
+		proposal.Items[i].ID = int64(i + 1)
+		proposal.Items[i].ProposalID = proposal.ID
+	}
+	stored := *proposal
+	stored.Items = append([]domain.DynamicPricingProposalItem(nil), proposal.Items...)
+	f.proposals[proposal.ID] = &stored
+	return nil
+}
+
+func (f *fakeDynamicPricingRepo) GetProposal(_ context.Context, id int64) (*domain.DynamicPricingProposal, error) {
+	p, ok := f.proposals[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	out := *p
+	out.Items = append([]domain.DynamicPricingProposalItem(nil), p.Items...)
+	return &out, nil
+}
+
+func (f *fakeDynamicPricingRepo) ListProposals(_ context.Context, _ string, _, _ int) ([]domain.DynamicPricingProposal, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeDynamicPricingRepo) MarkProposalReviewed(_ context.Context, proposal *domain.DynamicPricingProposal) error {
+	stored := f.proposals[proposal.ID]
+	if stored.Status != domain.PricingProposalStatusPending {
+		return domain.ErrPricingProposalReviewed
+	}
+	stored.Status = proposal.Status
+	stored.ReviewedBy = proposal.ReviewedBy
+	stored.ReviewNote = proposal.ReviewNote
+	return nil
+}
+
+func (f *fakeDynamicPricingRepo) UpdateProposalItems(_ context.Context, items []domain.DynamicPricingProposalItem) error {
+	f.updatedItems = append([]domain.DynamicPricingProposalItem(nil), items...)
+	return nil
+}
+
+func (f *fakeDynamicPricingRepo) ListInventoryStats(_ context.Context, _ []int64) ([]domain.PricingInventoryStat, error) {
+	return f.inventory, nil
+}
+
+func (f *fakeDynamicPricingRepo) ListSalesStats(_ context.Context, _ []int64, since time.Time) ([]domain.PricingSalesStat, error) {
+	f.salesSince = append(f.salesSince, since)
+	today := time.Date(2026, 5, 1, 0, 0, 0, 0, shanghaiLocation)
+	return f.sales[int(today.Sub(since).Hours()/24)], nil
+}
+
+type fakeDynamicPricingVoyages map[int64]time.Time
+
+func (f fakeDynamicPricingVoyages) GetByID(_ context.Context, id int64) (*domain.Voyage, error) {
+	depart, ok := f[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &domain.Voyage{ID: id, DepartDate: depart}, nil
+}
+
+type fakeDynamicPricingPrices struct {
+	currents []domain.VoyageCabinTypeCurrent
+	applied  []domain.VoyageCabinTypePriceVersion
+}
+
+func (f *fakeDynamicPricingPrices) ListCurrentByVoyages(_ context.Context, voyageIDs []int64) ([]domain.VoyageCabinTypeCurrent, error) {
+	out := make([]domain.VoyageCabinTypeCurrent, 0)
+	for _, cur := range f.currents {
+		for _, id := range voyageIDs {
+			if cur.VoyageID == id {
+				out = append(out, cur)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeDynamicPricingPrices) ApplyVersionAndRefreshCurrent(_ context.Context, version *domain.VoyageCabinTypePriceVersion) error {
+	version.ID = int64(100 + len(f.applied))
+	f.applied = append(f.applied, *version)
+	return nil
+}
+
+func intPtr(v int) *int { return &v }
+
+// newDynamicPricingFixture 构造 2026-05-01 10:00（上海）的调价场景：
+// 航次 1 距出发 20 天，舱型 10 上座率 90%、舱型 20 上座率 10%；航次 2 已出发。
+func newDynamicPricingFixture() (*DynamicPricingService, *fakeDynamicPricingRepo, *fakeDynamicPricingPrices) {
+	repo := &fakeDynamicPricingRepo{
+		rules: []domain.DynamicPricingRule{
+			{ID: 1, Name: "旺销提价", MinLoadFactor: floatPtr(0.8), VelocityWindowDays: 7, AdjustPercent: 10, FloorMarkupPercent: 5, CeilingMarkupPercent: floatPtr(80), Priority: 10, Status: 1},
+			{ID: 2, Name: "临近滞销降价", MaxLoadFactor: floatPtr(0.3), MaxDaysToDeparture: intPtr(30), VelocityWindowDays: 14, MaxVelocity: floatPtr(1), AdjustPercent: -20, FloorMarkupPercent: 5, Priority: 5, Status: 1},
+		},
+		inventory: []domain.PricingInventoryStat{
+			{VoyageID: 1, CabinTypeID: 10, Total: 10, Sold: 9},
+			{VoyageID: 1, CabinTypeID: 20, Total: 10, Sold: 1},
+		},
+		sales: map[int][]domain.PricingSalesStat{
+			14: {{VoyageID: 1, CabinTypeID: 20, Sold: 7}},
+		},
+	}
+	prices := &fakeDynamicPricingPrices{currents: []domain.VoyageCabinTypeCurrent{
+		{VoyageID: 1, CabinTypeID: 10, InventoryTotal: 10, SettlementPriceCents: 100000, SalePriceCents: 150000, VersionID: 7},
+		{VoyageID: 1, CabinTypeID: 20, InventoryTotal: 10, SettlementPriceCents: 100000, SalePriceCents: 120000, VersionID: 8},
+		{VoyageID: 2, CabinTypeID: 10, InventoryTotal: 10, SettlementPriceCents: 100000, SalePriceCents: 120000, VersionID: 9},
+	}}
+	voyages := fakeDynamicPricingVoyages{
+		1: time.Date(2026, 5, 21, 0, 0, 0, 0, shanghaiLocation),
+		2: time.Date(2026, 4, 30, 0, 0, 0, 0, shanghaiLocation),
+	}
+	svc := NewDynamicPricingService(repo, voyages, prices)
+	svc.now = func() time.Time { return time.Date(2026, 5, 1, 10, 0, 0, 0, shanghaiLocation) }
+	return svc, repo, prices
+}
+
+func TestDynamicPricingSimulate_MatchesRulesAndClamps(t *testing.T) {
+	svc, repo, prices := newDynamicPricingFixture()
+
+	items, err := svc.Simulate(context.Background(), DynamicPricingRequest{VoyageIDs: []int64{2, 1, 1}})
+	require.NoError(t, err)
+	require.Len(t, items, 2, "已出发航次不参与调价")
+
+	assert.Equal(t, int64(1), items[0].RuleID)
+	assert.Equal(t, int64(165000), items[0].ProposedSalePriceCents)
+	assert.Equal(t, int64(7), items[0].BaseVersionID)
+	assert.Equal(t, 20, items[0].DaysToDeparture)
+	assert.InDelta(t, 0.9, items[0].LoadFactor, 0.0001)
+
+	// 120000 × 0.8 = 96000 低于结算价 × 1.05 = 105000，触及下限。
+	assert.Equal(t, int64(2), items[1].RuleID)
+	assert.Equal(t, int64(105000), items[1].ProposedSalePriceCents)
+	assert.InDelta(t, 0.5, items[1].Velocity, 0.0001)
+	assert.Contains(t, items[1].Reason, "触及下限")
+
+	assert.Len(t, repo.salesSince, 2, "不同窗口分别统计销量")
+	assert.Empty(t, prices.applied, "试算不得写入价格版本")
+	assert.Empty(t, repo.proposals)
+}
+
+func TestDynamicPricingSimulate_CeilingAndCabinFilter(t *testing.T) {
+	svc, repo, _ := newDynamicPricingFixture()
+	repo.rules[0].AdjustPercent = 50
+
+	items, err := svc.Simulate(context.Background(), DynamicPricingRequest{VoyageIDs: []int64{1}, CabinTypeID: 10})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, int64(180000), items[0].ProposedSalePriceCents)
+	assert.Contains(t, items[0].Reason, "触及上限")
+}
+
+func TestDynamicPricingSimulate_RequiresVoyages(t *testing.T) {
+	svc, _, _ := newDynamicPricingFixture()
+	_, err := svc.Simulate(context.Background(), DynamicPricingRequest{VoyageIDs: []int64{0}})
+	assert.True(t, errors.Is(err, ErrInvalidPricingRule))
+}
+
+func TestDynamicPricingPropose_NoChanges(t *testing.T) {
+	svc, repo, _ := newDynamicPricingFixture()
+	repo.rules = repo.rules[:1]
+	repo.rules[0].MinLoadFactor = floatPtr(0.95)
+
+	_, err := svc.Propose(context.Background(), DynamicPricingRequest{VoyageIDs: []int64{1}}, 3)
+	assert.True(t, errors.Is(err, ErrNoPricingChanges))
+}
+
+func TestDynamicPricingApprove_AppliesAndSkipsChangedPrices(t *testing.T) {
+	svc, repo, prices := newDynamicPricingFixture()
+	effectiveAt := time.Date(2026, 5, 3, 0, 0, 0, 0, shanghaiLocation)
+
+	proposal, err := svc.Propose(context.Background(), DynamicPricingRequest{VoyageIDs: []int64{1}, EffectiveAt: &effectiveAt}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PricingProposalStatusPending, proposal.Status)
+	assert.Empty(t, prices.applied, "提案审批前不得写入价格版本")
+
+	// 提案生成后舱型 20 被人工改价。
+	prices.currents[1].VersionID = 99
+
+	approved, err := svc.Approve(context.Background(), proposal.ID, 5, " ok ")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PricingProposalStatusApproved, approved.Status)
+	assert.Equal(t, "ok", approved.ReviewNote)
+
+	require.Len(t, prices.applied, 1)
+	assert.Equal(t, int64(165000), prices.applied[0].SalePriceCents)
+	assert.True(t, prices.applied[0].EffectiveAt.Equal(effectiveAt))
+	require.NotNil(t, prices.applied[0].CreatedBy)
+	assert.Equal(t, int64(5), *prices.applied[0].CreatedBy)
+
+	require.Len(t, repo.updatedItems, 2)
+	assert.Equal(t, domain.PricingProposalItemStatusApplied, repo.updatedItems[0].Status)
+	assert.Equal(t, int64(100), repo.updatedItems[0].VersionID)
+	assert.Equal(t, domain.PricingProposalItemStatusSkipped, repo.updatedItems[1].Status)
+
+	_, err = svc.Approve(context.Background(), proposal.ID, 5, "")
+	assert.True(t, errors.Is(err, ErrPricingProposalNotPending))
+}
+
+func TestDynamicPricingReject(t *testing.T) {
+	svc, repo, prices := newDynamicPricingFixture()
+	proposal, err := svc.Propose(context.Background(), DynamicPricingRequest{VoyageIDs: []int64{1}}, 3)
+	require.NoError(t, err)
+
+	rejected, err := svc.Reject(context.Background(), proposal.ID, 5, "价格过高")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PricingProposalStatusRejected, rejected.Status)
+	assert.Equal(t, domain.PricingProposalStatusRejected, repo.proposals[proposal.ID].Status)
+	assert.Empty(t, prices.applied)
+
+	_, err = svc.Approve(context.Background(), proposal.ID, 5, "")
+	assert.True(t, errors.Is(err, ErrPricingProposalNotPending))
+	_, err = svc.GetProposal(context.Background(), 404)
+	assert.True(t, errors.Is(err, ErrDynamicPricingNotFound))
+}
+
+func TestDynamicPricingRuleValidation(t *testing.T) {
+	svc, _, _ := newDynamicPricingFixture()
+
+	rule := &domain.DynamicPricingRule{Name: " 默认 ", AdjustPercent: 5, Status: 1}
+	require.NoError(t, svc.CreateRule(context.Background(), rule))
+	assert.Equal(t, "默认", rule.Name)
+	assert.Equal(t, defaultVelocityWindowDays, rule.VelocityWindowDays)
+
+	invalid := []domain.DynamicPricingRule{
+		{Name: ""},
+		{Name: "x", MinLoadFactor: floatPtr(1.2)},
+		{Name: "x", MinLoadFactor: floatPtr(0.5), MaxLoadFactor: floatPtr(0.5)},
+		{Name: "x", MinDaysToDeparture: intPtr(10), MaxDaysToDeparture: intPtr(5)},
+		{Name: "x", AdjustPercent: -100},
+		{Name: "x", FloorMarkupPercent: -1},
+		{Name: "x", FloorMarkupPercent: 20, CeilingMarkupPercent: floatPtr(10)},
+		{Name: "x", VelocityWindowDays: 91},
+		{Name: "x", Status: 2},
+	}
+	for _, r := range invalid {
+		r := r
+		assert.True(t, errors.Is(svc.CreateRule(context.Background(), &r), ErrInvalidPricingRule), "rule %+v should be rejected", r)
+	}
+
+	err := svc.UpdateRule(context.Background(), &domain.DynamicPricingRule{ID: 404, Name: "x"})
+	assert.True(t, errors.Is(err, ErrDynamicPricingNotFound))
+}
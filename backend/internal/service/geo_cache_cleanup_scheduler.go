@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// geoCachePurger 清理已过期的缓存记录，由航线地图与地理编码缓存仓储实现。
+type geoCachePurger interface {
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// GeoCacheCleanupScheduler 定期删除已过期的航线地图与地理编码缓存，避免缓存表无限增长。
+// RunOnce 返回本轮删除的记录数。
+type GeoCacheCleanupScheduler struct {
+	*periodicJob
+}
+
+// NewGeoCacheCleanupScheduler 创建缓存清理调度器；interval 非正数时默认 1 分钟。
+func NewGeoCacheCleanupScheduler(interval time.Duration, purgers ...geoCachePurger) *GeoCacheCleanupScheduler {
+	run := func(ctx context.Context) (int, error) {
+		now := time.Now()
+		total := 0
+		var errs []error
+		for _, purger := range purgers {
+			n, err := purger.PurgeExpired(ctx, now)
+			total += int(n)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return total, errors.Join(errs...)
+	}
+	return &GeoCacheCleanupScheduler{periodicJob: newPeriodicJob("geo_cache_cleanup_scheduler: purge expired", interval, run)}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubGeoCachePurger struct {
+	purged int64
+	err    error
+}
+
+func (p *stubGeoCachePurger) PurgeExpired(context.Context, time.Time) (int64, error) {
+	return p.purged, p.err
+}
+
+func TestGeoCacheCleanupScheduler_RunOnce(t *testing.T) {
+	routes := &stubGeoCachePurger{purged: 2}
+	geocodes := &stubGeoCachePurger{purged: 3}
+	scheduler := NewGeoCacheCleanupScheduler(0, routes, geocodes)
+	assert.Equal(t, time.Minute, scheduler.interval)
+	assert.Equal(t, 5, scheduler.RunOnce(context.Background()))
+
+	routes.purged, routes.err = 0, errors.New("db down")
+	assert.Equal(t, 3, scheduler.RunOnce(context.Background()), "单个缓存清理失败不影响其他缓存")
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -39,8 +40,13 @@ func (s *CabinHoldService) Hold(skuID int64, userID int64, qty int) bool {
 
 // HoldWithTx 在指定事务中执行占座，并保证同用户同 SKU 的串行化处理。
 func (s *CabinHoldService) HoldWithTx(tx *gorm.DB, skuID int64, userID int64, qty int) bool {
+	return s.TryHoldWithTx(tx, skuID, userID, qty) == nil
+}
+
+// TryHoldWithTx 与 HoldWithTx 相同，但返回失败原因；库存不足时错误包装 domain.ErrInsufficientInventory。
+func (s *CabinHoldService) TryHoldWithTx(tx *gorm.DB, skuID int64, userID int64, qty int) error {
 	if s.repo == nil || skuID <= 0 || userID <= 0 || qty <= 0 {
-		return false
+		return errors.New("invalid hold request")
 	}
 
 	lockKey := fmt.Sprintf("%d:%d", skuID, userID)
@@ -51,14 +57,14 @@ func (s *CabinHoldService) HoldWithTx(tx *gorm.DB, skuID int64, userID int64, qt
 
 	exists, err := s.repo.ExistsActiveHoldTx(tx, skuID, userID, now)
 	if err != nil {
-		return false
+		return err
 	}
 	if exists {
-		return true
+		return nil
 	}
 
 	if err := s.repo.AdjustInventoryTx(tx, skuID, -qty, "cabin_hold"); err != nil {
-		return false
+		return err
 	}
 
 	expiresAt := now.Add(s.holdTTL)
@@ -69,10 +75,10 @@ func (s *CabinHoldService) HoldWithTx(tx *gorm.DB, skuID int64, userID int64, qt
 		ExpiresAt:  expiresAt,
 	}); err != nil {
 		_ = s.repo.AdjustInventoryTx(tx, skuID, qty, "cabin_hold_rollback")
-		return false
+		return err
 	}
 
-	return true
+	return nil
 }
 
 // loadLock 获取指定键对应的互斥锁，不存在时创建。
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+// IdempotencyCleanupScheduler 定期清理已过期的幂等记录，避免记录表无限增长。
+// RunOnce 返回本轮删除的记录数。
+type IdempotencyCleanupScheduler struct {
+	*periodicJob
+}
+
+// NewIdempotencyCleanupScheduler 创建幂等记录清理调度器；interval 非正数时默认 1 分钟。
+func NewIdempotencyCleanupScheduler(store domain.IdempotencyStore, interval time.Duration) *IdempotencyCleanupScheduler {
+	run := func(ctx context.Context) (int, error) {
+		n, err := store.PurgeExpired(ctx, time.Now())
+		return int(n), err
+	}
+	return &IdempotencyCleanupScheduler{periodicJob: newPeriodicJob("idempotency_cleanup_scheduler: purge expired", interval, run)}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+type stubIdempotencyStore struct {
+	domain.IdempotencyStore
+	purgedAt time.Time
+}
+
+func (s *stubIdempotencyStore) PurgeExpired(_ context.Context, now time.Time) (int64, error) {
+	s.purgedAt = now
+	return 3, nil
+}
+
+func TestIdempotencyCleanupSchedulerRunOnce(t *testing.T) {
+	store := &stubIdempotencyStore{}
+	if n := NewIdempotencyCleanupScheduler(store, time.Minute).RunOnce(context.Background()); n != 3 {
+		t.Fatalf("expected three purged records, got %d", n)
+	}
+	if store.purgedAt.IsZero() {
+		t.Fatal("expected PurgeExpired to be called with the current time")
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/csvimport"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidImportJob 表示导入任务的类型或参数无效。
+	ErrInvalidImportJob = errors.New("invalid import job")
+	// ErrImportJobNotFound 表示导入任务不存在。
+	ErrImportJobNotFound = errors.New("import job not found")
+)
+
+// importJobTimeout 为单个异步导入任务的最长执行时间。
+const importJobTimeout = 10 * time.Minute
+
+// ImportRunner 执行一类数据的导入，异步任务与同步接口共用同一套导入逻辑。
+type ImportRunner func(ctx context.Context, reader io.Reader, opts csvimport.Options) (*csvimport.Result, error)
+
+// ImportJobRepo 定义导入任务服务依赖的仓储能力。
+type ImportJobRepo interface {
+	Create(ctx context.Context, job *domain.ImportJob) error
+	Update(ctx context.Context, job *domain.ImportJob) error
+	GetByID(ctx context.Context, id int64) (*domain.ImportJob, error)
+	List(ctx context.Context, kind, status string, page, pageSize int) ([]domain.ImportJob, int64, error)
+	FailUnfinished(ctx context.Context, message string, finishedAt time.Time) (int64, error)
+}
+
+// ImportJobService 在后台协程中执行批量导入并记录任务状态，并发数受 workers 限制。
+type ImportJobService struct {
+	repo    ImportJobRepo
+	mu      sync.RWMutex
+	runners map[string]ImportRunner
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	timeout time.Duration
+	now     func() time.Time
+}
+
+// NewImportJobService 创建导入任务服务；workers 非正数时默认 2。
+func NewImportJobService(repo ImportJobRepo, workers int) *ImportJobService {
+	if workers <= 0 {
+		workers = 2
+	}
+	return &ImportJobService{
+		repo:    repo,
+		runners: map[string]ImportRunner{},
+		slots:   make(chan struct{}, workers),
+		timeout: importJobTimeout,
+		now:     time.Now,
+	}
+}
+
+// Register 注册一类导入的执行函数，kind 即任务的 kind 字段。
+func (s *ImportJobService) Register(kind string, runner ImportRunner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runners[kind] = runner
+}
+
+// Submit 创建排队中的导入任务并在后台执行，立即返回任务以便轮询状态。
+func (s *ImportJobService) Submit(ctx context.Context, kind, fileName string, data []byte, opts csvimport.Options, createdBy int64) (*domain.ImportJob, error) {
+	s.mu.RLock()
+	runner, ok := s.runners[kind]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidImportJob, kind)
+	}
+	opts, err := opts.Normalize()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportJob, err)
+	}
+	job := &domain.ImportJob{
+		Kind:     kind,
+		Status:   domain.ImportJobPending,
+		Mode:     opts.Mode,
+		DryRun:   opts.DryRun,
+		FileName: strings.TrimSpace(fileName),
+	}
+	if createdBy > 0 {
+		job.CreatedBy = &createdBy
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	s.wg.Add(1)
+	go s.process(*job, runner, data, opts)
+	return job, nil
+}
+
+// Wait 等待所有已提交的任务结束，用于优雅退出。
+func (s *ImportJobService) Wait() {
+	s.wg.Wait()
+}
+
+// process 占用一个并发槽执行导入，并把结果与错误报告写回任务。
+func (s *ImportJobService) process(job domain.ImportJob, runner ImportRunner, data []byte, opts csvimport.Options) {
+	defer s.wg.Done()
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	started := s.now()
+	job.Status = domain.ImportJobRunning
+	job.StartedAt = &started
+	if err := s.repo.Update(ctx, &job); err != nil {
+		logger.FromContext(ctx).Error("import job: mark running failed", zap.Int64("job_id", job.ID), zap.Error(err))
+	}
+
+	result, err := runImport(ctx, runner, data, opts)
+	if err == nil {
+		var report []byte
+		report, err = result.ErrorReport()
+		job.Total, job.Succeeded, job.Failed, job.Committed = result.Total, result.Succeeded, result.Failed, result.Committed
+		job.ErrorReport = string(report)
+	}
+	job.Status = domain.ImportJobSucceeded
+	if err != nil {
+		job.Status = domain.ImportJobFailed
+		job.Message = err.Error()
+	}
+	finished := s.now()
+	job.FinishedAt = &finished
+	// 导入可能已耗尽 ctx 的时限，结果写回使用独立的上下文
+	if err := s.repo.Update(context.Background(), &job); err != nil {
+		logger.FromContext(ctx).Error("import job: save result failed", zap.Int64("job_id", job.ID), zap.Error(err))
+	}
+}
+
+// runImport 执行导入并把 panic 转为任务失败，避免后台协程拖垮进程。
+func runImport(ctx context.Context, runner ImportRunner, data []byte, opts csvimport.Options) (result *csvimport.Result, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result, err = nil, fmt.Errorf("import panicked: %v", recovered)
+		}
+	}()
+	return runner(ctx, bytes.NewReader(data), opts)
+}
+
+// Get 查询导入任务状态。
+func (s *ImportJobService) Get(ctx context.Context, id int64) (*domain.ImportJob, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrImportJobNotFound
+	}
+	return job, err
+}
+
+// List 按类型与状态分页查询导入任务。
+func (s *ImportJobService) List(ctx context.Context, kind, status string, page, pageSize int) ([]domain.ImportJob, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.repo.List(ctx, strings.TrimSpace(kind), strings.TrimSpace(status), page, pageSize)
+}
+
+// ErrorReport 返回任务的失败行错误报告（CSV）；任务尚未结束或没有失败行时返回空。
+func (s *ImportJobService) ErrorReport(ctx context.Context, id int64) ([]byte, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(job.ErrorReport), nil
+}
+
+// RecoverInterrupted 在启动时把上次进程退出前未完成的任务标记为失败，提示重新提交。
+func (s *ImportJobService) RecoverInterrupted(ctx context.Context) (int64, error) {
+	return s.repo.FailUnfinished(ctx, "interrupted by server restart, please resubmit", s.now())
+}
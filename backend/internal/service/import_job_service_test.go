@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/csvimport"
+	"gorm.io/gorm"
+)
+
+type importJobRepoStub struct {
+	mu       sync.Mutex
+	jobs     map[int64]domain.ImportJob
+	statuses map[int64][]string
+	failed   string
+}
+
+func newImportJobRepoStub() *importJobRepoStub {
+	return &importJobRepoStub{jobs: map[int64]domain.ImportJob{}, statuses: map[int64][]string{}}
+}
+
+func (s *importJobRepoStub) Create(_ context.Context, job *domain.ImportJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.ID = int64(len(s.jobs) + 1)
+	s.jobs[job.ID] = *job
+	s.statuses[job.ID] = []string{job.Status}
+	return nil
+}
+
+func (s *importJobRepoStub) Update(_ context.Context, job *domain.ImportJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = *job
+	s.statuses[job.ID] = append(s.statuses[job.ID], job.Status)
+	return nil
+}
+
+func (s *importJobRepoStub) GetByID(_ context.Context, id int64) (*domain.ImportJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &job, nil
+}
+
+func (s *importJobRepoStub) List(_ context.Context, kind, status string, page, pageSize int) ([]domain.ImportJob, int64, error) {
+	return nil, int64(page*1000 + pageSize), nil
+}
+
+func (s *importJobRepoStub) FailUnfinished(_ context.Context, message string, _ time.Time) (int64, error) {
+	s.failed = message
+	return 3, nil
+}
+
+func csvImportRunner(_ context.Context, reader io.Reader, opts csvimport.Options) (*csvimport.Result, error) {
+	spec := csvimport.Spec[string]{
+		Required: []string{"code"},
+		Parse: func(_ context.Context, row csvimport.Row) (string, error) {
+			if row.Get("code") == "bad" {
+				return "", csvimport.ColumnError("code", "is invalid")
+			}
+			return row.Get("code"), nil
+		},
+		Save: func(context.Context, []string) error { return nil },
+	}
+	return csvimport.Run(context.Background(), reader, spec, opts)
+}
+
+func TestImportJobServiceRunsJobsInBackground(t *testing.T) {
+	repo := newImportJobRepoStub()
+	svc := NewImportJobService(repo, 1)
+	svc.now = func() time.Time { return time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC) }
+	svc.Register("codes", csvImportRunner)
+	svc.Register("broken", func(context.Context, io.Reader, csvimport.Options) (*csvimport.Result, error) {
+		return nil, errors.New("invalid import file: missing required columns code")
+	})
+	svc.Register("panics", func(context.Context, io.Reader, csvimport.Options) (*csvimport.Result, error) {
+		panic("boom")
+	})
+	ctx := context.Background()
+
+	job, err := svc.Submit(ctx, "codes", " codes.csv ", []byte("code\nA-1\nbad\nA-2\n"), csvimport.Options{Mode: "BEST_EFFORT"}, 9)
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+	if job.Status != domain.ImportJobPending || job.Mode != csvimport.ModeBestEffort || job.FileName != "codes.csv" || *job.CreatedBy != 9 {
+		t.Fatalf("unexpected submitted job: %+v", job)
+	}
+	broken, _ := svc.Submit(ctx, "broken", "x.csv", nil, csvimport.Options{}, 0)
+	panicked, _ := svc.Submit(ctx, "panics", "y.csv", nil, csvimport.Options{DryRun: true}, 0)
+	svc.Wait()
+
+	done, err := svc.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if done.Status != domain.ImportJobSucceeded || done.Total != 3 || done.Succeeded != 2 || done.Failed != 1 || !done.Committed {
+		t.Fatalf("unexpected finished job: %+v", done)
+	}
+	if done.StartedAt == nil || done.FinishedAt == nil || strings.Join(repo.statuses[job.ID], ",") != "pending,running,succeeded" {
+		t.Fatalf("unexpected job lifecycle: %v", repo.statuses[job.ID])
+	}
+	report, err := svc.ErrorReport(ctx, job.ID)
+	if err != nil || !strings.Contains(string(report), "3,code: is invalid,bad") {
+		t.Fatalf("unexpected error report: %q (%v)", report, err)
+	}
+
+	failed, _ := svc.Get(ctx, broken.ID)
+	if failed.Status != domain.ImportJobFailed || !strings.Contains(failed.Message, "missing required columns") {
+		t.Fatalf("file errors should fail the job: %+v", failed)
+	}
+	failed, _ = svc.Get(ctx, panicked.ID)
+	if failed.Status != domain.ImportJobFailed || !strings.Contains(failed.Message, "boom") || !failed.DryRun {
+		t.Fatalf("panics should fail the job: %+v", failed)
+	}
+}
+
+func TestImportJobServiceValidatesAndRecovers(t *testing.T) {
+	repo := newImportJobRepoStub()
+	svc := NewImportJobService(repo, 0)
+	svc.Register("codes", csvImportRunner)
+	ctx := context.Background()
+
+	if _, err := svc.Submit(ctx, "ports", "p.csv", nil, csvimport.Options{}, 0); !errors.Is(err, ErrInvalidImportJob) {
+		t.Fatalf("expected ErrInvalidImportJob for unknown kind, got %v", err)
+	}
+	if _, err := svc.Submit(ctx, "codes", "c.csv", nil, csvimport.Options{Mode: "partial"}, 0); !errors.Is(err, ErrInvalidImportJob) {
+		t.Fatalf("expected ErrInvalidImportJob for unknown mode, got %v", err)
+	}
+	if len(repo.jobs) != 0 {
+		t.Fatalf("invalid submissions must not create jobs: %+v", repo.jobs)
+	}
+	if _, err := svc.Get(ctx, 404); !errors.Is(err, ErrImportJobNotFound) {
+		t.Fatalf("expected ErrImportJobNotFound, got %v", err)
+	}
+	if _, err := svc.ErrorReport(ctx, 404); !errors.Is(err, ErrImportJobNotFound) {
+		t.Fatalf("expected ErrImportJobNotFound, got %v", err)
+	}
+	if _, total, _ := svc.List(ctx, " codes ", "", 0, 500); total != 1020 {
+		t.Fatalf("expected page defaults to be applied, got %d", total)
+	}
+	n, err := svc.RecoverInterrupted(ctx)
+	if err != nil || n != 3 || !strings.Contains(repo.failed, "resubmit") {
+		t.Fatalf("unexpected recovery: %d %q (%v)", n, repo.failed, err)
+	}
+}
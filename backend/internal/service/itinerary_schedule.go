@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+// ErrInvalidItinerarySchedule 表示行程时间表存在无法保存的问题（时间格式错误、停靠顺序冲突、超出航次日期等）。
+var ErrInvalidItinerarySchedule = errors.New("invalid itinerary schedule")
+
+// defaultItineraryTimezone 是行程中所有停靠港都无法确定时区时使用的时区。
+const defaultItineraryTimezone = "Asia/Shanghai"
+
+// 行程时间表问题代码。
+const (
+	ItineraryIssueInvalidTime            = "invalid_time"             // ETA/ETD 不是 HH:MM 或 HH:MM:SS
+	ItineraryIssueDepartureBeforeArrival = "departure_before_arrival" // 同一站离港早于靠港
+	ItineraryIssueStopOverlap            = "stop_overlap"             // 靠港早于上一站离港（停靠重叠或顺序颠倒）
+	ItineraryIssueDaysExceedDates        = "days_exceed_dates"        // 行程天数超出出发/返航日期
+	ItineraryIssueDaysShortOfDates       = "days_short_of_dates"      // 行程天数少于出发/返航日期（警告）
+	ItineraryIssueTimezoneAssumed        = "timezone_assumed"         // 停靠港无时区，沿用相邻站点时区（警告）
+)
+
+// ItineraryScheduleError 汇总导致行程被拒绝的全部问题。
+type ItineraryScheduleError struct {
+	Issues []domain.ItineraryIssue
+}
+
+// Error 返回首个问题描述及其余问题数量。
+func (e *ItineraryScheduleError) Error() string {
+	if len(e.Issues) == 0 {
+		return ErrInvalidItinerarySchedule.Error()
+	}
+	msg := fmt.Sprintf("%s: %s", ErrInvalidItinerarySchedule, e.Issues[0].Message)
+	if len(e.Issues) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Issues)-1)
+	}
+	return msg
+}
+
+// Unwrap 使 errors.Is(err, ErrInvalidItinerarySchedule) 成立。
+func (e *ItineraryScheduleError) Unwrap() error { return ErrInvalidItinerarySchedule }
+
+// itineraryClockLayouts 是 ETA/ETD 接受的时间格式。
+var itineraryClockLayouts = []string{"15:04", "15:04:05"}
+
+// buildItinerarySchedule 校验航次行程时间表并返回汇总结果。
+// ETA/ETD 按停靠港当地时区换算为绝对时间后，按 (DayNo, StopIndex) 顺序检查同站离港不早于靠港、
+// 下一站靠港不早于上一站离港；行程天数超出出发/返航日期时拒绝，少于日期跨度时给出警告。
+// 停靠港缺少时区时沿用前一站（首站取后续第一个已知时区），并回写到行程的 Timezone 字段。
+// 未设置出发日期时只做相对顺序校验，不检查日期跨度。
+func buildItinerarySchedule(voyage *domain.Voyage) (*domain.VoyageSchedule, error) {
+	schedule := &domain.VoyageSchedule{Warnings: []domain.ItineraryIssue{}}
+	if voyage == nil || len(voyage.Itineraries) == 0 {
+		return schedule, nil
+	}
+	order := make([]int, len(voyage.Itineraries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		left, right := voyage.Itineraries[order[a]], voyage.Itineraries[order[b]]
+		if left.DayNo != right.DayNo {
+			return left.DayNo < right.DayNo
+		}
+		return left.StopIndex < right.StopIndex
+	})
+
+	var issues []domain.ItineraryIssue
+	locations := resolveItineraryLocations(voyage.Itineraries, order, &schedule.Warnings)
+	baseYear, baseMonth, baseDay := 2000, time.January, 1
+	if !voyage.DepartDate.IsZero() {
+		baseYear, baseMonth, baseDay = voyage.DepartDate.Date()
+	}
+
+	var lastDeparture time.Time
+	var lastStop *domain.VoyageItinerary
+	seaDays := map[int]bool{}
+	for _, idx := range order {
+		item := &voyage.Itineraries[idx]
+		isSea := isSeaCruiseStop(item.City, item.Summary)
+		if current, seen := seaDays[item.DayNo]; !seen || current {
+			seaDays[item.DayNo] = isSea
+		}
+		if isSea {
+			continue
+		}
+		loc := locations[idx]
+		at := func(field string, raw *string) (time.Time, bool) {
+			if raw == nil || strings.TrimSpace(*raw) == "" {
+				return time.Time{}, false
+			}
+			clock, err := parseItineraryClock(*raw)
+			if err != nil {
+				issues = append(issues, itineraryIssue(ItineraryIssueInvalidTime, item, fmt.Sprintf("%s %q of %s must be HH:MM", field, *raw, item.City)))
+				return time.Time{}, false
+			}
+			return time.Date(baseYear, baseMonth, baseDay+item.DayNo-1, clock.Hour(), clock.Minute(), clock.Second(), 0, loc), true
+		}
+		arrival, hasArrival := at("eta_time", item.ETATime)
+		departure, hasDeparture := at("etd_time", item.ETDTime)
+		if hasArrival && hasDeparture && departure.Before(arrival) {
+			issues = append(issues, itineraryIssue(ItineraryIssueDepartureBeforeArrival, item, fmt.Sprintf("departure %s of %s is before arrival %s", *item.ETDTime, item.City, *item.ETATime)))
+		}
+		first, hasFirst := arrival, hasArrival
+		if !hasFirst {
+			first, hasFirst = departure, hasDeparture
+		}
+		if hasFirst && lastStop != nil && first.Before(lastDeparture) {
+			issues = append(issues, itineraryIssue(ItineraryIssueStopOverlap, item, fmt.Sprintf("%s (day %d) starts at %s, before %s (day %d) ends at %s",
+				item.City, item.DayNo, first.UTC().Format(time.RFC3339), lastStop.City, lastStop.DayNo, lastDeparture.UTC().Format(time.RFC3339))))
+		}
+		// 以本站最晚的时间作为下一站的比较基准，离港早于靠港时不重复报告后续站点
+		switch {
+		case hasDeparture && (!hasArrival || departure.After(arrival)):
+			lastDeparture, lastStop = departure, item
+		case hasArrival:
+			lastDeparture, lastStop = arrival, item
+		}
+	}
+
+	for _, isSea := range seaDays {
+		schedule.ItineraryDays++
+		if isSea {
+			schedule.SeaDays++
+		}
+	}
+	schedule.PortDays = schedule.ItineraryDays - schedule.SeaDays
+	if !voyage.DepartDate.IsZero() && !voyage.ReturnDate.IsZero() {
+		schedule.DateSpanDays = calendarDaysBetween(voyage.DepartDate, voyage.ReturnDate) + 1
+		maxDay := voyage.Itineraries[order[len(order)-1]].DayNo
+		switch {
+		case maxDay > schedule.DateSpanDays:
+			issues = append(issues, domain.ItineraryIssue{Code: ItineraryIssueDaysExceedDates, DayNo: maxDay,
+				Message: fmt.Sprintf("itinerary has %d days but depart/return dates span %d days", maxDay, schedule.DateSpanDays)})
+		case maxDay < schedule.DateSpanDays:
+			schedule.Warnings = append(schedule.Warnings, domain.ItineraryIssue{Code: ItineraryIssueDaysShortOfDates,
+				Message: fmt.Sprintf("itinerary has %d days but depart/return dates span %d days", maxDay, schedule.DateSpanDays)})
+		}
+	}
+	if len(issues) > 0 {
+		return nil, &ItineraryScheduleError{Issues: issues}
+	}
+	return schedule, nil
+}
+
+// resolveItineraryLocations 按行程顺序确定每站的时区并回写 Timezone 字段。
+// 缺少或无法识别时区的站点沿用前一站时区，首段缺失时取后续第一个已知时区，全部缺失时使用默认时区；
+// 有靠离港时间的停靠港沿用时区时追加警告。
+func resolveItineraryLocations(itineraries []domain.VoyageItinerary, order []int, warnings *[]domain.ItineraryIssue) map[int]*time.Location {
+	known := make(map[int]*time.Location, len(order))
+	var fallback *time.Location
+	for _, idx := range order {
+		name := strings.TrimSpace(itineraries[idx].Timezone)
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			known[idx] = loc
+			if fallback == nil {
+				fallback = loc
+			}
+		}
+	}
+	if fallback == nil {
+		fallback, _ = time.LoadLocation(defaultItineraryTimezone)
+	}
+	locations := make(map[int]*time.Location, len(order))
+	current := fallback
+	for _, idx := range order {
+		item := &itineraries[idx]
+		if loc, ok := known[idx]; ok {
+			current = loc
+		} else if !isSeaCruiseStop(item.City, item.Summary) && (hasItineraryClock(item.ETATime) || hasItineraryClock(item.ETDTime)) {
+			*warnings = append(*warnings, itineraryIssue(ItineraryIssueTimezoneAssumed, item,
+				fmt.Sprintf("timezone of %s is unknown, times are read in %s", item.City, current.String())))
+		}
+		locations[idx] = current
+		item.Timezone = current.String()
+	}
+	return locations
+}
+
+// parseItineraryClock 解析 HH:MM 或 HH:MM:SS（兼容全角冒号）。
+func parseItineraryClock(raw string) (time.Time, error) {
+	value := strings.ReplaceAll(strings.TrimSpace(raw), "：", ":")
+	var lastErr error
+	for _, layout := range itineraryClockLayouts {
+		parsed, err := time.Parse(layout, value)
+		if err == nil {
+			return parsed, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
+
+func hasItineraryClock(raw *string) bool {
+	return raw != nil && strings.TrimSpace(*raw) != ""
+}
+
+// calendarDaysBetween 返回两个日期（忽略时刻）相差的自然日数。
+func calendarDaysBetween(from, to time.Time) int {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	start := time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)
+	end := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+func itineraryIssue(code string, item *domain.VoyageItinerary, message string) domain.ItineraryIssue {
+	return domain.ItineraryIssue{Code: code, DayNo: item.DayNo, StopIndex: item.StopIndex, Message: message}
+}
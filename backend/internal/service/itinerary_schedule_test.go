@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clockPtr(value string) *string { return &value }
+
+func scheduleVoyage(depart, ret string, items ...domain.VoyageItinerary) *domain.Voyage {
+	v := &domain.Voyage{Itineraries: items}
+	if depart != "" {
+		v.DepartDate, _ = time.Parse("2006-01-02", depart)
+		v.ReturnDate, _ = time.Parse("2006-01-02", ret)
+	}
+	return v
+}
+
+func TestBuildItineraryScheduleCountsSeaDaysAcrossTimezones(t *testing.T) {
+	voyage := scheduleVoyage("2026-05-01", "2026-05-04",
+		domain.VoyageItinerary{DayNo: 4, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai", ETATime: clockPtr("07:00")},
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai", ETDTime: clockPtr("17:00")},
+		domain.VoyageItinerary{DayNo: 2, StopIndex: 1, City: "福冈", Timezone: "Asia/Tokyo", ETATime: clockPtr("08：00"), ETDTime: clockPtr("17:00")},
+		domain.VoyageItinerary{DayNo: 3, StopIndex: 1, City: "海上巡游"},
+	)
+
+	schedule, err := buildItinerarySchedule(voyage)
+	require.NoError(t, err)
+	assert.Equal(t, 4, schedule.DateSpanDays)
+	assert.Equal(t, 4, schedule.ItineraryDays)
+	assert.Equal(t, 1, schedule.SeaDays)
+	assert.Equal(t, 3, schedule.PortDays)
+	assert.Empty(t, schedule.Warnings)
+	assert.Equal(t, "Asia/Tokyo", voyage.Itineraries[3].Timezone, "海上巡游沿用上一站时区")
+}
+
+func TestBuildItineraryScheduleComparesStopsInLocalTimezones(t *testing.T) {
+	// 长崎 10:00 JST 离港即 09:00 CST，上海 09:30 CST 靠港晚于离港
+	valid := scheduleVoyage("",
+		"",
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 1, City: "长崎", Timezone: "Asia/Tokyo", ETDTime: clockPtr("10:00")},
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 2, City: "上海", Timezone: "Asia/Shanghai", ETATime: clockPtr("09:30")},
+	)
+	_, err := buildItinerarySchedule(valid)
+	require.NoError(t, err)
+
+	// 上海 10:00 CST 离港即 11:00 JST，福冈 10:30 JST 靠港早于离港
+	overlap := scheduleVoyage("",
+		"",
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai", ETDTime: clockPtr("10:00")},
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 2, City: "福冈", Timezone: "Asia/Tokyo", ETATime: clockPtr("10:30")},
+	)
+	_, err = buildItinerarySchedule(overlap)
+	var scheduleErr *ItineraryScheduleError
+	require.ErrorAs(t, err, &scheduleErr)
+	require.Len(t, scheduleErr.Issues, 1)
+	assert.Equal(t, ItineraryIssueStopOverlap, scheduleErr.Issues[0].Code)
+	assert.Equal(t, 2, scheduleErr.Issues[0].StopIndex)
+}
+
+func TestBuildItineraryScheduleRejectsInvalidSchedules(t *testing.T) {
+	voyage := scheduleVoyage("2026-05-01", "2026-05-02",
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai", ETATime: clockPtr("8点"), ETDTime: clockPtr("17:00")},
+		domain.VoyageItinerary{DayNo: 2, StopIndex: 1, City: "福冈", Timezone: "Asia/Tokyo", ETATime: clockPtr("18:00"), ETDTime: clockPtr("08:00")},
+		domain.VoyageItinerary{DayNo: 2, StopIndex: 2, City: "长崎", Timezone: "Asia/Tokyo", ETATime: clockPtr("17:00")},
+		domain.VoyageItinerary{DayNo: 3, StopIndex: 1, City: "海上巡游"},
+	)
+
+	_, err := buildItinerarySchedule(voyage)
+	require.True(t, errors.Is(err, ErrInvalidItinerarySchedule))
+	var scheduleErr *ItineraryScheduleError
+	require.ErrorAs(t, err, &scheduleErr)
+	codes := make([]string, 0, len(scheduleErr.Issues))
+	for _, issue := range scheduleErr.Issues {
+		codes = append(codes, issue.Code)
+	}
+	assert.Equal(t, []string{ItineraryIssueInvalidTime, ItineraryIssueDepartureBeforeArrival, ItineraryIssueStopOverlap, ItineraryIssueDaysExceedDates}, codes)
+	assert.Contains(t, err.Error(), "(and 3 more)")
+}
+
+func TestBuildItineraryScheduleWarnsOnAssumedTimezoneAndShortItinerary(t *testing.T) {
+	voyage := scheduleVoyage("2026-05-01", "2026-05-05",
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 1, City: "可可岛（哥斯达黎加）", ETDTime: clockPtr("18:00")},
+		domain.VoyageItinerary{DayNo: 2, StopIndex: 1, City: "福冈", Timezone: "Asia/Tokyo", ETATime: clockPtr("08:00")},
+		domain.VoyageItinerary{DayNo: 3, StopIndex: 1, City: "海上巡游"},
+	)
+
+	schedule, err := buildItinerarySchedule(voyage)
+	require.NoError(t, err)
+	require.Len(t, schedule.Warnings, 2)
+	assert.Equal(t, ItineraryIssueTimezoneAssumed, schedule.Warnings[0].Code)
+	assert.Equal(t, 1, schedule.Warnings[0].DayNo)
+	assert.Equal(t, ItineraryIssueDaysShortOfDates, schedule.Warnings[1].Code)
+	assert.Equal(t, "Asia/Tokyo", voyage.Itineraries[0].Timezone, "首站缺少时区时取后续第一个已知时区")
+	assert.Equal(t, 5, schedule.DateSpanDays)
+	assert.Equal(t, 1, schedule.SeaDays)
+}
+
+// timezoneCityResolverStub 按港口主数据返回坐标与时区。
+type timezoneCityResolverStub struct{}
+
+func (timezoneCityResolverStub) ResolveLabel(ctx context.Context, label string) (*ResolvedPortCity, error) {
+	port, err := testPorts.FindByName(ctx, label, "")
+	if err != nil {
+		return nil, nil
+	}
+	return resolvedFromPort(port), nil
+}
+
+func TestVoyageServiceCreateValidatesItinerarySchedule(t *testing.T) {
+	repo := &voyageRepoStub{}
+	svc := NewVoyageService(repo, nil).SetCityResolver(timezoneCityResolverStub{})
+	ctx := context.Background()
+
+	voyage := scheduleVoyage("2026-05-01", "2026-05-03",
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 1, City: "上海", ETDTime: clockPtr("17:00")},
+		domain.VoyageItinerary{DayNo: 2, StopIndex: 1, City: "福冈", ETATime: clockPtr("08:00"), ETDTime: clockPtr("18:00")},
+		domain.VoyageItinerary{DayNo: 3, StopIndex: 1, City: "上海", ETATime: clockPtr("08:00")},
+	)
+	require.NoError(t, svc.Create(ctx, voyage))
+	require.NotNil(t, repo.created)
+	assert.Equal(t, "Asia/Tokyo", repo.created.Itineraries[1].Timezone)
+	require.NotNil(t, voyage.Schedule)
+	assert.Equal(t, 3, voyage.Schedule.PortDays)
+
+	repo.created = nil
+	invalid := scheduleVoyage("2026-05-01", "2026-05-01",
+		domain.VoyageItinerary{DayNo: 1, StopIndex: 1, City: "上海", ETDTime: clockPtr("17:00")},
+		domain.VoyageItinerary{DayNo: 2, StopIndex: 1, City: "福冈", ETATime: clockPtr("08:00")},
+	)
+	err := svc.Create(ctx, invalid)
+	assert.ErrorIs(t, err, ErrInvalidItinerarySchedule)
+	assert.Nil(t, repo.created, "时间表不合法时不应保存")
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -11,6 +15,16 @@ import (
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/cruisebooking/backend/internal/pkg/searoute"
+	"github.com/cruisebooking/backend/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	seaRouteProviderRemote  = "searoute"         // 外部 SeaRoute 服务生成的航线
+	seaRouteProviderOffline = "searoute_offline" // 内置离线航路网络生成的航线
 )
 
 // SeaRouteClientConfig 定义海上航线客户端的配置参数。
@@ -18,14 +32,23 @@ type SeaRouteClientConfig struct {
 	Endpoint     string        // 外部航线服务 API 端点地址
 	Timeout      time.Duration // HTTP 请求超时时间
 	ResolutionKM int           // 航线分辨率（公里），用于路线简化
+	// DisableOffline 为 true 时不使用内置离线航线兜底，外部服务不可用时不返回航线。
+	DisableOffline bool
+	// OfflineCacheTTL 为外部服务不可用时离线兜底航线的缓存时长，过期后重新请求外部服务；默认 6 小时。
+	OfflineCacheTTL time.Duration
 }
 
 // SeaRouteClient 提供与外部海上航线服务的交互能力。
-// 用于获取航次的航线地理坐标和距离信息。
+// 用于获取航次的航线地理坐标和距离信息；外部服务未配置或不可用时回退到内置离线航线规划。
 type SeaRouteClient struct {
 	endpoint     string
 	resolutionKM int
 	httpClient   *http.Client
+	offline      *searoute.Router
+	ports        PortCoordinateLookup
+	cache        RouteMapCacheStore
+	offlineTTL   time.Duration
+	now          func() time.Time
 }
 
 // seaRouteResponse 定义外部航线服务返回的 JSON 响应结构。
@@ -48,24 +71,16 @@ type routeStop struct {
 	longitude float64 // 经度
 }
 
-// knownPortCoordinates 是已知港口的经纬度坐标映射，用于在无法获取外部数据时作为备选。
-var knownPortCoordinates = map[string][2]float64{
-	"上海":  {31.2304, 121.4737},
-	"天津":  {39.0842, 117.2009},
-	"大连":  {38.9140, 121.6147},
-	"青岛":  {36.0671, 120.3826},
-	"厦门":  {24.4798, 118.0894},
-	"香港":  {22.3193, 114.1694},
-	"福冈":  {33.5902, 130.4017},
-	"长崎":  {32.7503, 129.8777},
-	"鹿儿岛": {31.5966, 130.5571},
-	"济州":  {33.4996, 126.5312},
-	"西归浦": {33.2541, 126.5601},
-	"釜山":  {35.1796, 129.0756},
-	"仁川":  {37.4563, 126.7052},
-	"横滨":  {35.4437, 139.6380},
-	"神户":  {34.6901, 135.1955},
-	"大阪":  {34.6937, 135.5023},
+// PortCoordinateLookup 定义航线规划按停靠港名称查找港口主数据坐标的能力。
+type PortCoordinateLookup interface {
+	FindByName(ctx context.Context, name, country string) (*domain.Port, error)
+}
+
+// RouteMapCacheStore 定义航线地图持久化缓存的读写能力。
+type RouteMapCacheStore interface {
+	Get(ctx context.Context, key string, now time.Time) (*domain.RouteMapCache, error)
+	Upsert(ctx context.Context, entry *domain.RouteMapCache) error
+	Delete(ctx context.Context, keys ...string) error
 }
 
 // seaCruisePlaceholders 是海上巡游日的占位符列表，用于识别航程中的海上巡游日。
@@ -73,6 +88,7 @@ var seaCruisePlaceholders = []string{"海上巡游", "海上巡航", "海上观
 
 // NewSeaRouteClient 创建海上航线客户端实例。
 // 如果未设置超时，则默认为 8 秒；如果未设置分辨率，则默认为 20 公里。
+// 除非 DisableOffline 为 true，否则启用内置离线航线作为兜底。
 func NewSeaRouteClient(cfg SeaRouteClientConfig) *SeaRouteClient {
 	if cfg.Timeout <= 0 {
 		cfg.Timeout = 8 * time.Second
@@ -80,25 +96,156 @@ func NewSeaRouteClient(cfg SeaRouteClientConfig) *SeaRouteClient {
 	if cfg.ResolutionKM <= 0 {
 		cfg.ResolutionKM = 20
 	}
-	return &SeaRouteClient{
+	if cfg.OfflineCacheTTL <= 0 {
+		cfg.OfflineCacheTTL = 6 * time.Hour
+	}
+	client := &SeaRouteClient{
 		endpoint:     strings.TrimSpace(cfg.Endpoint),
 		resolutionKM: cfg.ResolutionKM,
-		httpClient:   &http.Client{Timeout: cfg.Timeout},
+		httpClient:   &http.Client{Timeout: cfg.Timeout, Transport: tracing.Transport(nil)},
+		offlineTTL:   cfg.OfflineCacheTTL,
+		now:          time.Now,
 	}
+	if !cfg.DisableOffline {
+		client.offline = searoute.Default()
+	}
+	return client
+}
+
+// SetPortLookup 注入港口主数据，用于为未存储坐标的行程按港口名称补全坐标。
+func (c *SeaRouteClient) SetPortLookup(ports PortCoordinateLookup) *SeaRouteClient {
+	c.ports = ports
+	return c
+}
+
+// SetRouteCache 注入航线地图持久化缓存，相同停靠港坐标与分辨率的航线只规划一次。
+func (c *SeaRouteClient) SetRouteCache(cache RouteMapCacheStore) *SeaRouteClient {
+	c.cache = cache
+	return c
 }
 
 // BuildVoyageRouteMap 根据航次的行程列表构建航线地图模型。
 // 返回包含所有航段坐标、总距离和分辨率的航线地图数据。
-// 如果未配置外部服务端点或行程不足以构成航线，则返回 nil。
+// 已注入缓存时先按停靠港坐标查找缓存；未命中时优先请求外部航线服务，
+// 未配置端点、请求失败或无可用航线时使用内置离线航线规划，规划结果写回缓存。
+// 行程不足以构成航线或两种方式均无法规划时返回 nil。
 func (c *SeaRouteClient) BuildVoyageRouteMap(ctx context.Context, itineraries []domain.VoyageItinerary) (*domain.VoyageRouteMap, error) {
-	if c == nil || c.endpoint == "" {
+	if c == nil {
 		return nil, nil
 	}
-	stops := normalizeVoyageRouteStops(itineraries)
+	stops := c.normalizeVoyageRouteStops(ctx, itineraries)
 	if len(stops) < 2 {
 		return nil, nil
 	}
+	key := c.routeCacheKey(stops)
+	if cached := c.loadCachedRouteMap(ctx, key); cached != nil {
+		return cached, nil
+	}
+	routeMap, err := c.planRouteMap(ctx, stops)
+	if routeMap != nil {
+		c.storeCachedRouteMap(ctx, key, len(stops), routeMap)
+	}
+	return routeMap, err
+}
+
+// InvalidateVoyageRoute 在行程变更后删除旧行程对应的航线缓存；新旧停靠港坐标一致时保留缓存。
+func (c *SeaRouteClient) InvalidateVoyageRoute(ctx context.Context, previous, current []domain.VoyageItinerary) error {
+	if c == nil || c.cache == nil {
+		return nil
+	}
+	previousStops := c.normalizeVoyageRouteStops(ctx, previous)
+	if len(previousStops) < 2 {
+		return nil
+	}
+	previousKey := c.routeCacheKey(previousStops)
+	if currentStops := c.normalizeVoyageRouteStops(ctx, current); len(currentStops) >= 2 && c.routeCacheKey(currentStops) == previousKey {
+		return nil
+	}
+	return c.cache.Delete(ctx, previousKey)
+}
+
+// planRouteMap 优先请求外部航线服务，不可用时回退到内置离线航线规划。
+func (c *SeaRouteClient) planRouteMap(ctx context.Context, stops []routeStop) (*domain.VoyageRouteMap, error) {
+	if c.endpoint != "" {
+		routeMap, err := c.buildRemoteRouteMap(ctx, stops)
+		if routeMap != nil || c.offline == nil {
+			return routeMap, err
+		}
+		if err != nil {
+			logger.FromContext(ctx).Warn("maritime route: remote searoute unavailable, falling back to offline router", zap.Error(err))
+		}
+	}
+	if c.offline == nil {
+		return nil, nil
+	}
+	return c.buildOfflineRouteMap(stops), nil
+}
+
+// routeCacheKey 按规划模式、分辨率与有序停靠港坐标（保留 5 位小数）生成缓存键。
+// 规划模式区分是否配置外部服务，切换配置后不会命中另一模式下的缓存。
+func (c *SeaRouteClient) routeCacheKey(stops []routeStop) string {
+	mode := seaRouteProviderOffline
+	if c.endpoint != "" {
+		mode = seaRouteProviderRemote
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s|%d", mode, c.resolutionKM)
+	for _, stop := range stops {
+		fmt.Fprintf(&builder, "|%.5f,%.5f", stop.latitude, stop.longitude)
+	}
+	sum := sha256.Sum256([]byte(builder.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCachedRouteMap 读取未过期的缓存航线，未命中或缓存不可用时返回 nil。
+func (c *SeaRouteClient) loadCachedRouteMap(ctx context.Context, key string) *domain.VoyageRouteMap {
+	if c.cache == nil {
+		return nil
+	}
+	entry, err := c.cache.Get(ctx, key, c.now())
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Warn("maritime route: load route cache failed", zap.Error(err))
+		}
+		return nil
+	}
+	var routeMap domain.VoyageRouteMap
+	if err := json.Unmarshal([]byte(entry.Payload), &routeMap); err != nil {
+		logger.FromContext(ctx).Warn("maritime route: decode route cache failed", zap.String("key", key), zap.Error(err))
+		return nil
+	}
+	return &routeMap
+}
 
+// storeCachedRouteMap 写入航线缓存。外部服务已配置却回退到离线规划的航线只缓存 offlineTTL，
+// 到期后重新尝试外部服务；其余航线由坐标唯一确定，长期有效。
+func (c *SeaRouteClient) storeCachedRouteMap(ctx context.Context, key string, stopCount int, routeMap *domain.VoyageRouteMap) {
+	if c.cache == nil {
+		return
+	}
+	payload, err := json.Marshal(routeMap)
+	if err != nil {
+		logger.FromContext(ctx).Warn("maritime route: encode route cache failed", zap.Error(err))
+		return
+	}
+	entry := &domain.RouteMapCache{
+		CacheKey:     key,
+		Provider:     routeMap.Provider,
+		ResolutionKM: routeMap.ResolutionKM,
+		StopCount:    stopCount,
+		Payload:      string(payload),
+	}
+	if c.endpoint != "" && routeMap.Provider != seaRouteProviderRemote {
+		expiresAt := c.now().Add(c.offlineTTL)
+		entry.ExpiresAt = &expiresAt
+	}
+	if err := c.cache.Upsert(ctx, entry); err != nil {
+		logger.FromContext(ctx).Warn("maritime route: store route cache failed", zap.Error(err))
+	}
+}
+
+// buildRemoteRouteMap 逐段请求外部航线服务并合并为航线地图；任一航段无可用航线时返回 nil。
+func (c *SeaRouteClient) buildRemoteRouteMap(ctx context.Context, stops []routeStop) (*domain.VoyageRouteMap, error) {
 	coordinates := make([][][]float64, 0, len(stops)-1)
 	totalDistance := 0.0
 	for index := 0; index < len(stops)-1; index += 1 {
@@ -120,7 +267,7 @@ func (c *SeaRouteClient) BuildVoyageRouteMap(ctx context.Context, itineraries []
 		return nil, nil
 	}
 	return &domain.VoyageRouteMap{
-		Provider:     "searoute",
+		Provider:     seaRouteProviderRemote,
 		GeometryType: "MultiLineString",
 		Coordinates:  coordinates,
 		DistanceKM:   totalDistance,
@@ -128,15 +275,50 @@ func (c *SeaRouteClient) BuildVoyageRouteMap(ctx context.Context, itineraries []
 	}, nil
 }
 
+// buildOfflineRouteMap 使用内置航路网络逐段规划航线，按分辨率插值后输出与外部服务一致的 [经度, 纬度] 坐标；
+// 任一航段无法规划时返回 nil。
+func (c *SeaRouteClient) buildOfflineRouteMap(stops []routeStop) *domain.VoyageRouteMap {
+	coordinates := make([][][]float64, 0, len(stops)-1)
+	totalDistance := 0.0
+	for index := 0; index < len(stops)-1; index += 1 {
+		from := searoute.Point{Lat: stops[index].latitude, Lon: stops[index].longitude}
+		to := searoute.Point{Lat: stops[index+1].latitude, Lon: stops[index+1].longitude}
+		route, err := c.offline.Route(from, to)
+		if err != nil {
+			zap.L().Warn("maritime route: offline route unavailable", zap.String("from", stops[index].city), zap.String("to", stops[index+1].city), zap.Error(err))
+			return nil
+		}
+		points := searoute.Densify(route.Points, float64(c.resolutionKM))
+		line := make([][]float64, 0, len(points))
+		for _, point := range points {
+			line = append(line, []float64{roundCoordinate(point.Lon), roundCoordinate(point.Lat)})
+		}
+		coordinates = append(coordinates, line)
+		totalDistance += route.DistanceKM
+	}
+	return &domain.VoyageRouteMap{
+		Provider:     seaRouteProviderOffline,
+		GeometryType: "MultiLineString",
+		Coordinates:  coordinates,
+		DistanceKM:   math.Round(totalDistance*10) / 10,
+		ResolutionKM: c.resolutionKM,
+	}
+}
+
+// roundCoordinate 将坐标保留 5 位小数（约 1 米精度），控制响应体积。
+func roundCoordinate(value float64) float64 {
+	return math.Round(value*1e5) / 1e5
+}
+
 // normalizeVoyageRouteStops 将航次行程规范化为停靠港列表。
-// 过滤掉海上巡游日，并去除重复的连续停靠港。
-func normalizeVoyageRouteStops(itineraries []domain.VoyageItinerary) []routeStop {
+// 过滤掉海上巡游日，并去除重复的连续停靠港；任一停靠港无法确定坐标时返回 nil。
+func (c *SeaRouteClient) normalizeVoyageRouteStops(ctx context.Context, itineraries []domain.VoyageItinerary) []routeStop {
 	stops := make([]routeStop, 0, len(itineraries))
 	for _, item := range itineraries {
 		if isSeaCruiseStop(item.City, item.Summary) {
 			continue
 		}
-		lat, lon, ok := resolveItineraryCoordinate(item)
+		lat, lon, ok := c.resolveItineraryCoordinate(ctx, item)
 		if !ok {
 			return nil
 		}
@@ -152,14 +334,31 @@ func normalizeVoyageRouteStops(itineraries []domain.VoyageItinerary) []routeStop
 }
 
 // resolveItineraryCoordinate 解析航次行程的经纬度坐标。
-// 优先使用行程中已存储的坐标，其次使用已知港口坐标映射。
-func resolveItineraryCoordinate(item domain.VoyageItinerary) (float64, float64, bool) {
+// 优先使用行程中已存储的坐标，其次按 "名称（国家）" 或裸名称查找港口主数据，
+// 原名称未命中时再去掉"港口"、"港"、"市"等后缀重试。
+func (c *SeaRouteClient) resolveItineraryCoordinate(ctx context.Context, item domain.VoyageItinerary) (float64, float64, bool) {
 	if item.Latitude != nil && item.Longitude != nil {
 		return *item.Latitude, *item.Longitude, true
 	}
-	normalized := normalizePortName(item.City)
-	if point, ok := knownPortCoordinates[normalized]; ok {
-		return point[0], point[1], true
+	if c.ports == nil {
+		return 0, 0, false
+	}
+	name, country := parseLabelParts(strings.TrimSpace(item.City))
+	if name == "" {
+		name = strings.TrimSpace(item.City)
+	}
+	for i, candidate := range []string{name, normalizePortName(name)} {
+		if candidate == "" || (i > 0 && candidate == name) {
+			continue
+		}
+		port, err := c.ports.FindByName(ctx, candidate, country)
+		if err == nil && port != nil {
+			return port.Latitude, port.Longitude, true
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Warn("maritime route: lookup port failed", zap.String("port", candidate), zap.Error(err))
+			return 0, 0, false
+		}
 	}
 	return 0, 0, false
 }
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+// AuditSnapshotLoader 按 ID 读取资源的当前状态，用于计算写操作前后的差异。
+type AuditSnapshotLoader func(ctx context.Context, id int64) (any, error)
+
+// AuditSnapshotOf 把 GetByID 形式的查询方法适配为 AuditSnapshotLoader。
+func AuditSnapshotOf[T any](get func(ctx context.Context, id int64) (T, error)) AuditSnapshotLoader {
+	return func(ctx context.Context, id int64) (any, error) {
+		return get(ctx, id)
+	}
+}
+
+// defaultOperationLogExportMaxRows 是操作日志单次导出的最大行数。
+const defaultOperationLogExportMaxRows = 10000
+
+// auditRedacted 是脱敏字段的替换值。
+const auditRedacted = "***"
+
+var operationLogCSVHeader = []string{"id", "created_at", "staff_id", "method", "route", "operation", "resource", "resource_id", "status_code", "ip_address", "user_agent", "details", "changes", "hash"}
+
+// OperationAuditService 负责后台写操作的审计：补全变更前后差异、脱敏后写入哈希链，并提供查询、导出与链校验。
+type OperationAuditService struct {
+	repo    domain.OperationLogRepository
+	loaders map[string]AuditSnapshotLoader
+	maxRows int
+}
+
+// NewOperationAuditService 创建操作审计服务。
+func NewOperationAuditService(repo domain.OperationLogRepository) *OperationAuditService {
+	return &OperationAuditService{repo: repo, loaders: map[string]AuditSnapshotLoader{}, maxRows: defaultOperationLogExportMaxRows}
+}
+
+// RegisterSnapshot 为资源类型（路由中 /admin/ 后的第一段，如 cruises）注册快照读取函数。
+// 未注册的资源只记录请求体，不计算前后差异。
+func (s *OperationAuditService) RegisterSnapshot(resource string, loader AuditSnapshotLoader) *OperationAuditService {
+	s.loaders[resource] = loader
+	return s
+}
+
+// Snapshot 读取资源当前状态并转换为字段映射，资源不存在或未注册读取函数时返回 nil。
+func (s *OperationAuditService) Snapshot(ctx context.Context, resource string, id int64) any {
+	loader, ok := s.loaders[resource]
+	if !ok || id <= 0 {
+		return nil
+	}
+	value, err := loader(ctx, id)
+	if err != nil || value == nil {
+		return nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	return toAuditMap(value)
+}
+
+// Record 补全执行后快照与差异并写入操作日志。失败的请求只记录请求本身，不计算差异。
+func (s *OperationAuditService) Record(ctx context.Context, entry domain.AuditEntry) error {
+	log := &domain.OperationLog{
+		StaffID:    entry.StaffID,
+		Operation:  truncateAuditField(entry.Operation, 50),
+		Resource:   truncateAuditField(entry.Resource, 50),
+		ResourceID: entry.ResourceID,
+		Details:    auditDetails(entry),
+		IPAddress:  truncateAuditField(entry.IPAddress, 50),
+		UserAgent:  truncateAuditField(entry.UserAgent, 500),
+		Method:     entry.Method,
+		Route:      truncateAuditField(entry.Route, 200),
+		StatusCode: entry.StatusCode,
+	}
+	if entry.StatusCode < http.StatusBadRequest {
+		before, _ := entry.Before.(map[string]any)
+		var after map[string]any
+		if entry.Method != http.MethodDelete {
+			after, _ = s.Snapshot(ctx, entry.Resource, entry.ResourceID).(map[string]any)
+		}
+		if changes := DiffAuditSnapshots(before, after); len(changes) > 0 {
+			data, _ := json.Marshal(changes)
+			log.Changes = string(data)
+		}
+	}
+	return s.repo.Create(ctx, log)
+}
+
+// List 按条件分页查询操作日志。
+func (s *OperationAuditService) List(ctx context.Context, filter domain.OperationLogFilter, page, pageSize int) ([]domain.OperationLog, int64, error) {
+	return s.repo.List(ctx, filter, page, pageSize)
+}
+
+// ExportCSV 按条件导出操作日志，单元格做 CSV 注入过滤。
+func (s *OperationAuditService) ExportCSV(ctx context.Context, filter domain.OperationLogFilter) ([]byte, error) {
+	items, err := s.repo.ListForExport(ctx, filter, s.maxRows)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	if err := writer.Write(operationLogCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		row := []string{
+			strconv.FormatInt(item.ID, 10),
+			item.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(item.StaffID, 10),
+			item.Method,
+			item.Route,
+			item.Operation,
+			item.Resource,
+			strconv.FormatInt(item.ResourceID, 10),
+			strconv.Itoa(item.StatusCode),
+			item.IPAddress,
+			item.UserAgent,
+			item.Details,
+			item.Changes,
+			item.Hash,
+		}
+		for i := range row {
+			row[i] = sanitizeCSVCell(row[i])
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// VerifyChain 校验操作日志哈希链是否完整。
+func (s *OperationAuditService) VerifyChain(ctx context.Context) (*domain.OperationLogChainReport, error) {
+	return s.repo.VerifyChain(ctx)
+}
+
+// AuditFieldChange 是单个字段的变更前后值。
+type AuditFieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// DiffAuditSnapshots 比较两个快照的顶层字段，返回发生变化的字段。
+// before 为 nil 表示新建，after 为 nil 表示删除，此时所有字段都视为变化。
+func DiffAuditSnapshots(before, after map[string]any) map[string]AuditFieldChange {
+	if before == nil && after == nil {
+		return nil
+	}
+	changes := map[string]AuditFieldChange{}
+	for key, old := range before {
+		if updated, ok := after[key]; !ok || !reflect.DeepEqual(old, updated) {
+			changes[key] = AuditFieldChange{Before: old, After: after[key]}
+		}
+	}
+	for key, updated := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = AuditFieldChange{After: updated}
+		}
+	}
+	return changes
+}
+
+// toAuditMap 把任意值按 JSON 序列化规则转换为字段映射并脱敏，json:"-" 字段（如密码哈希）不会进入审计记录。
+func toAuditMap(value any) map[string]any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	redactAuditValue(fields)
+	return fields
+}
+
+// auditDetails 生成操作详情：JSON 请求体脱敏后原样保留，其他类型（如文件上传）只记录内容类型与大小。
+func auditDetails(entry domain.AuditEntry) string {
+	details := map[string]any{}
+	if len(entry.RequestBody) > 0 {
+		var body any
+		if strings.Contains(entry.ContentType, "json") && json.Unmarshal(entry.RequestBody, &body) == nil {
+			details["request"] = redactAuditValue(body)
+		} else {
+			details["content_type"] = entry.ContentType
+			details["request_bytes"] = len(entry.RequestBody)
+		}
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(details)
+	return string(data)
+}
+
+// redactAuditValue 递归替换密码、令牌、密钥等敏感字段的值。
+func redactAuditValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if isSensitiveAuditKey(key) {
+				v[key] = auditRedacted
+				continue
+			}
+			v[key] = redactAuditValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactAuditValue(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveAuditKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range []string{"password", "secret", "token", "api_key", "apikey", "totp_code", "sms_code"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateAuditField(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	// 按字节截断时回退到完整的 UTF-8 字符边界
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit]
+}
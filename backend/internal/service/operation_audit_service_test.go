@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+type fakeOperationLogRepo struct {
+	logs []domain.OperationLog
+}
+
+func (f *fakeOperationLogRepo) Create(_ context.Context, log *domain.OperationLog) error {
+	log.ID = int64(len(f.logs) + 1)
+	f.logs = append(f.logs, *log)
+	return nil
+}
+
+func (f *fakeOperationLogRepo) List(context.Context, domain.OperationLogFilter, int, int) ([]domain.OperationLog, int64, error) {
+	return f.logs, int64(len(f.logs)), nil
+}
+
+func (f *fakeOperationLogRepo) ListForExport(context.Context, domain.OperationLogFilter, int) ([]domain.OperationLog, error) {
+	return f.logs, nil
+}
+
+func (f *fakeOperationLogRepo) VerifyChain(context.Context) (*domain.OperationLogChainReport, error) {
+	return &domain.OperationLogChainReport{Valid: true, Checked: int64(len(f.logs))}, nil
+}
+
+type auditedCruise struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Status int    `json:"status"`
+	Secret string `json:"api_secret"`
+}
+
+func TestOperationAuditServiceRecordsFieldDiff(t *testing.T) {
+	repo := &fakeOperationLogRepo{}
+	current := &auditedCruise{ID: 3, Name: "海洋量子号", Status: 1, Secret: "s1"}
+	svc := NewOperationAuditService(repo).RegisterSnapshot("cruises", func(_ context.Context, id int64) (any, error) {
+		if current == nil {
+			return nil, errors.New("not found")
+		}
+		copied := *current
+		return &copied, nil
+	})
+	ctx := context.Background()
+
+	before := svc.Snapshot(ctx, "cruises", 3)
+	current.Name, current.Secret = "海洋光谱号", "s2"
+	err := svc.Record(ctx, domain.AuditEntry{
+		StaffID: 1, Method: http.MethodPut, Operation: "update", Resource: "cruises", ResourceID: 3, StatusCode: http.StatusOK,
+		ContentType: "application/json", RequestBody: []byte(`{"name":"海洋光谱号","password":"Plain-Text1"}`), Before: before,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes map[string]AuditFieldChange
+	if err := json.Unmarshal([]byte(repo.logs[0].Changes), &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes["name"].Before != "海洋量子号" || changes["name"].After != "海洋光谱号" {
+		t.Fatalf("expected only the name change (secrets redacted on both sides), got %s", repo.logs[0].Changes)
+	}
+	if strings.Contains(repo.logs[0].Details, "Plain-Text1") || !strings.Contains(repo.logs[0].Details, auditRedacted) {
+		t.Fatalf("passwords in request bodies must be redacted: %s", repo.logs[0].Details)
+	}
+
+	before = svc.Snapshot(ctx, "cruises", 3)
+	current = nil
+	if err := svc.Record(ctx, domain.AuditEntry{Method: http.MethodDelete, Resource: "cruises", ResourceID: 3, StatusCode: http.StatusOK, Before: before}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(repo.logs[1].Changes, `"before":"海洋光谱号","after":null`) {
+		t.Fatalf("deletes should record the removed state, got %s", repo.logs[1].Changes)
+	}
+
+	if err := svc.Record(ctx, domain.AuditEntry{Method: http.MethodPost, Resource: "ports", StatusCode: http.StatusBadRequest,
+		ContentType: "multipart/form-data", RequestBody: []byte("binary")}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.logs[2].Changes != "" || !strings.Contains(repo.logs[2].Details, `"request_bytes":6`) {
+		t.Fatalf("non-JSON bodies should only be summarised: %+v", repo.logs[2])
+	}
+}
+
+func TestOperationAuditServiceExportCSVSanitizesCells(t *testing.T) {
+	repo := &fakeOperationLogRepo{logs: []domain.OperationLog{{ID: 1, StaffID: 2, Operation: "update", Resource: "cruises", UserAgent: "=HYPERLINK(\"x\")", Hash: "abc"}}}
+	data, err := NewOperationAuditService(repo).ExportCSV(context.Background(), domain.OperationLogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][0] != "id" || rows[1][10] != `'=HYPERLINK("x")` {
+		t.Fatalf("unexpected csv rows: %v", rows)
+	}
+}
+
+func TestDiffAuditSnapshotsCreate(t *testing.T) {
+	changes := DiffAuditSnapshots(nil, map[string]any{"name": "A"})
+	if len(changes) != 1 || changes["name"].Before != nil || changes["name"].After != "A" {
+		t.Fatalf("creates should list every field as new, got %+v", changes)
+	}
+	if DiffAuditSnapshots(nil, nil) != nil {
+		t.Fatal("no snapshots means no diff")
+	}
+}
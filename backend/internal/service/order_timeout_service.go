@@ -2,11 +2,12 @@ package service
 
 import (
 	"context"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // OrderTimeoutRepo 订单超时查询接口。
@@ -24,6 +25,7 @@ type InventoryReleaser interface {
 type OrderTimeoutService struct {
 	orderRepo     OrderTimeoutRepo
 	inventoryRepo InventoryReleaser
+	released      InventoryReleaseListener
 	mu            sync.Mutex
 }
 
@@ -35,7 +37,12 @@ func NewOrderTimeoutService(orderRepo OrderTimeoutRepo, inventoryRepo InventoryR
 	}
 }
 
-// CloseExpiredOrders 关闭超时未支付的订单并释放库存。
+// SetReleaseListener 注入库存释放监听，关闭超时订单后通知候补服务发出邀约。
+func (s *OrderTimeoutService) SetReleaseListener(listener InventoryReleaseListener) {
+	s.released = listener
+}
+
+// CloseExpiredOrders 关闭超时未支付的订单并释放库存；分销订单归还分销商配额，不释放到公共库存。
 func (s *OrderTimeoutService) CloseExpiredOrders(ctx context.Context, timeout time.Duration) (int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -53,12 +60,20 @@ func (s *OrderTimeoutService) CloseExpiredOrders(ctx context.Context, timeout ti
 		if err := s.orderRepo.TransitionStatus(ctx, order.ID, domain.OrderStatusCancelled, 0, "timeout auto close"); err != nil {
 			continue
 		}
+		// 分销订单占用的是分销商配额而非公共库存，配额已在状态变更事务内归还
+		if order.Channel == domain.BookingChannelAgency {
+			closed++
+			continue
+		}
 		if err := s.inventoryRepo.ReleaseLocked(ctx, order.CabinSKUID, 1); err != nil {
 			if rbErr := s.orderRepo.TransitionStatus(ctx, order.ID, order.Status, 0, "rollback: inventory release failed"); rbErr != nil {
-				log.Printf("order_timeout: rollback order %d failed: %v (original: %v)", order.ID, rbErr, err)
+				logger.FromContext(ctx).Error("order_timeout: rollback order failed", zap.Int64("order_id", order.ID), zap.Error(rbErr), zap.NamedError("original", err))
 			}
 			continue
 		}
+		if s.released != nil {
+			s.released.OnInventoryReleased(ctx, order.CabinSKUID)
+		}
 		closed++
 	}
 	return closed, nil
@@ -135,3 +135,22 @@ func TestCloseExpiredOrdersConcurrentIdempotent(t *testing.T) {
 		t.Fatalf("expected inventory release once, got %d", inv.releaseCalls)
 	}
 }
+
+func TestCloseExpiredOrdersLeavesAgencyCabinsInAllotment(t *testing.T) {
+	repo := &fakeOrderTimeoutRepo{orders: map[int64]domain.Booking{
+		1: {ID: 1, CabinSKUID: 101, Status: domain.OrderStatusPendingPayment, Channel: domain.BookingChannelAgency},
+	}}
+	inv := &fakeInventoryReleaser{}
+	svc := NewOrderTimeoutService(repo, inv)
+
+	closed, err := svc.CloseExpiredOrders(context.Background(), 15*time.Minute)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if closed != 1 {
+		t.Fatalf("expected closed count 1, got %d", closed)
+	}
+	if inv.releaseCalls != 0 {
+		t.Fatalf("agency cabins return to the allotment, not public stock; got %d releases", inv.releaseCalls)
+	}
+}
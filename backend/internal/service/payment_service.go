@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
 )
 
 // 支付状态常量。
@@ -139,55 +140,75 @@ func NewPaymentCallbackService(
 	}
 }
 
+// 支付回调处理结果，作为 cruise_payment_callbacks_total 的 outcome 标签。
+const (
+	paymentCallbackPaid           = "paid"            // 支付成功并确认订单
+	paymentCallbackDuplicate      = "duplicate"       // 重复回调，已支付无副作用
+	paymentCallbackRejected       = "rejected"        // 未知提供商或签名校验失败
+	paymentCallbackAmountMismatch = "amount_mismatch" // 支付金额与订单金额不一致
+	paymentCallbackError          = "error"           // 解析或落库失败
+	paymentProviderUnknown        = "unknown"         // 未配置的提供商统一记为 unknown，避免标签基数膨胀
+)
+
 // HandleCallback 处理支付提供商回调。可以针对同一个 trade_no 多次调用（幂等）。
 //
 // 流程：验证签名 → 提取 trade_no → 幂等性检查 → 金额校验 → 更新支付状态 → 更新预订状态。
+// 每次回调按提供商与处理结果计入 cruise_payment_callbacks_total。
 func (s *PaymentCallbackServiceImpl) HandleCallback(ctx context.Context, provider string, body []byte, signature string) error {
+	outcome, err := s.handleCallback(ctx, provider, body, signature)
+	if _, ok := s.verifiers[provider]; !ok {
+		provider = paymentProviderUnknown
+	}
+	metrics.PaymentCallbacks.WithLabelValues(provider, outcome).Inc()
+	return err
+}
+
+func (s *PaymentCallbackServiceImpl) handleCallback(ctx context.Context, provider string, body []byte, signature string) (string, error) {
 	v, ok := s.verifiers[provider]
 	if !ok {
-		return fmt.Errorf("unknown payment provider: %q", provider)
+		return paymentCallbackRejected, fmt.Errorf("unknown payment provider: %q", provider)
 	}
 
 	// 步骤 1：验证签名以拒绝伪造。
 	if err := v.Verify(body, signature); err != nil {
-		return fmt.Errorf("callback verification failed: %w", err)
+		return paymentCallbackRejected, fmt.Errorf("callback verification failed: %w", err)
 	}
 
 	// 步骤 2：提取提供商交易号。
 	tradeNo, err := v.ExtractTradeNo(body)
 	if err != nil {
-		return err
+		return paymentCallbackError, err
 	}
 
 	// 步骤 3：幂等性 — 如果已支付，则返回成功且无副作用。
 	payment, err := s.payRepo.FindByTradeNo(ctx, tradeNo)
 	if err != nil {
-		return fmt.Errorf("find payment by trade_no %q: %w", tradeNo, err)
+		return paymentCallbackError, fmt.Errorf("find payment by trade_no %q: %w", tradeNo, err)
 	}
 	if payment.Status == PaymentStatusPaid {
-		return nil
+		return paymentCallbackDuplicate, nil
 	}
 
 	// 步骤 4：获取订单金额并校验支付金额必须等于订单金额。
 	order, err := s.bookingGetter.GetByID(ctx, payment.OrderID)
 	if err != nil {
-		return fmt.Errorf("get order: %w", err)
+		return paymentCallbackError, fmt.Errorf("get order: %w", err)
 	}
 	if payment.AmountCents != order.TotalCents {
-		return fmt.Errorf("payment amount %d does not match order amount %d", payment.AmountCents, order.TotalCents)
+		return paymentCallbackAmountMismatch, fmt.Errorf("payment amount %d does not match order amount %d", payment.AmountCents, order.TotalCents)
 	}
 
 	// 步骤 5：将支付标记为已支付。
 	if err := s.payRepo.UpdateStatus(ctx, payment.ID, PaymentStatusPaid); err != nil {
-		return fmt.Errorf("update payment status: %w", err)
+		return paymentCallbackError, fmt.Errorf("update payment status: %w", err)
 	}
 
 	// 步骤 6：确认关联的预订。
 	if err := s.bookingRepo.UpdateStatus(ctx, payment.OrderID, domain.OrderStatusPaid); err != nil {
-		return fmt.Errorf("update booking status: %w", err)
+		return paymentCallbackError, fmt.Errorf("update booking status: %w", err)
 	}
 
-	return nil
+	return paymentCallbackPaid, nil
 }
 
 // PaymentCallbackData 从支付回调中提取的数据。
@@ -10,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -260,6 +262,8 @@ func TestHandleCallback_Idempotent_AlreadyPaid(t *testing.T) {
 	svc := makeCallbackSvc(payRepo, bookRepo, bookGetter, secret)
 	body, _ := json.Marshal(map[string]string{"trade_no": "TX001"})
 	sig := makeHMACSig(t, secret, body)
+	duplicates := metrics.PaymentCallbacks.WithLabelValues("wechat", "duplicate")
+	before := testutil.ToFloat64(duplicates)
 
 	err := svc.HandleCallback(context.Background(), "wechat", body, sig)
 
@@ -267,6 +271,7 @@ func TestHandleCallback_Idempotent_AlreadyPaid(t *testing.T) {
 	// 已支付：无副作用。
 	assert.Empty(t, payRepo.statuses)
 	assert.Empty(t, bookRepo.statuses)
+	assert.Equal(t, 1.0, testutil.ToFloat64(duplicates)-before)
 }
 
 func TestHandleCallback_InvalidSignature(t *testing.T) {
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// periodicJob 以固定间隔在后台重复执行一项任务，供各类调度器复用。
+type periodicJob struct {
+	name     string
+	run      func(ctx context.Context) (int, error)
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	mu       sync.Mutex
+	started  bool
+}
+
+// newPeriodicJob 创建后台任务；interval 非正数时默认 1 分钟。
+func newPeriodicJob(name string, interval time.Duration, run func(ctx context.Context) (int, error)) *periodicJob {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &periodicJob{name: name, run: run, interval: interval}
+}
+
+// Start 启动后台协程并立即执行一次；重复调用只会生效一次。
+func (j *periodicJob) Start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.started {
+		return
+	}
+	j.started = true
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for {
+			j.RunOnce(context.Background())
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台协程并等待当前轮次结束。
+func (j *periodicJob) Stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.started {
+		return
+	}
+	close(j.stop)
+	<-j.done
+	j.started = false
+}
+
+// RunOnce 执行一轮任务并返回处理数量；出错时记录日志，仍返回已处理数量。
+func (j *periodicJob) RunOnce(ctx context.Context) int {
+	ctx, cancel := context.WithTimeout(ctx, j.interval)
+	defer cancel()
+	n, err := j.run(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error(j.name+" failed", zap.Error(err))
+	}
+	return n
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,6 +12,12 @@ import (
 	"time"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"github.com/cruisebooking/backend/internal/pkg/tracing"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // PortCityOption 表示港口城市下拉选项的展示结构。
@@ -18,6 +25,7 @@ type PortCityOption struct {
 	Label       string `json:"label"`                  // 显示标签，格式如 "上海（中国）"
 	CityName    string `json:"city_name,omitempty"`    // 城市名称
 	CountryName string `json:"country_name,omitempty"` // 国家名称
+	PortCode    string `json:"port_code,omitempty"`    // 港口主数据 UN/LOCODE，非港口结果为空
 	IsSpecial   bool   `json:"is_special,omitempty"`   // 是否为特殊目的地（如自定义目的地）
 }
 
@@ -28,6 +36,8 @@ type ResolvedPortCity struct {
 	CountryName string   // 国家名称
 	Latitude    *float64 // 纬度坐标
 	Longitude   *float64 // 经度坐标
+	PortCode    string   // 港口主数据 UN/LOCODE，非港口结果为空
+	Timezone    string   // 港口 IANA 时区，非港口结果为空
 	IsSpecial   bool     // 是否为特殊目的地
 }
 
@@ -41,27 +51,41 @@ type PortCityLookupService interface {
 type PortCityServiceConfig struct {
 	Endpoint string        // 外部城市搜索服务 API 端点地址
 	Timeout  time.Duration // HTTP 请求超时时间
+	CacheTTL time.Duration // 外部搜索结果缓存时长，默认 7 天
+}
+
+// GeocodeCacheStore 定义外部地理编码结果缓存的读写能力。
+type GeocodeCacheStore interface {
+	Get(ctx context.Context, provider, query string, now time.Time) (*domain.GeocodeCache, error)
+	Upsert(ctx context.Context, entry *domain.GeocodeCache) error
 }
 
+const (
+	geocodeProviderNominatim = "nominatim" // 地理编码缓存中 Nominatim 结果的服务商标识
+	geocodeQueryMaxLen       = 200         // 可缓存查询词的最大字符数，与 geocode_cache.query 列宽一致
+)
+
+// PortMasterLookup 定义港口城市搜索与行程坐标解析所需的港口主数据查询能力。
+type PortMasterLookup interface {
+	SearchByKeyword(ctx context.Context, keyword string, limit int) ([]domain.Port, error)
+	FindByName(ctx context.Context, name, country string) (*domain.Port, error)
+}
+
+// portCitySearchLimit 是港口主数据参与下拉搜索的最大条数。
+const portCitySearchLimit = 10
+
 // PortCityService 提供港口城市搜索和解析的业务逻辑。
+// 查找顺序：港口主数据 → 自定义目的地 → 外部地理编码服务。
 type PortCityService struct {
 	endpoint   string
 	httpClient *http.Client
+	ports      PortMasterLookup      // 港口主数据
 	customRepo CustomDestinationRepo // 自定义目的地仓储
+	geocache   GeocodeCacheStore     // 外部搜索结果缓存
+	cacheTTL   time.Duration
+	now        func() time.Time
 }
 
-// localPortCityEntry 定义本地港口城市词典的条目结构。
-type localPortCityEntry struct {
-	Label       string   // 显示标签
-	CityName    string   // 城市名称
-	CountryName string   // 国家名称
-	Latitude    float64  // 纬度
-	Longitude   float64  // 经度
-	Keywords    []string // 搜索关键词列表
-}
-
-var localPortCityCatalog = []localPortCityEntry{}
-
 // nominatimResult 定义 Nominatim 地理编码服务返回的结果结构。
 type nominatimResult struct {
 	Lat         string            `json:"lat"`         // 纬度
@@ -97,12 +121,29 @@ func NewPortCityService(cfg PortCityServiceConfig) *PortCityService {
 	if cfg.Timeout <= 0 {
 		cfg.Timeout = 8 * time.Second
 	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 7 * 24 * time.Hour
+	}
 	return &PortCityService{
 		endpoint:   strings.TrimSpace(cfg.Endpoint),
-		httpClient: &http.Client{Timeout: cfg.Timeout},
+		httpClient: &http.Client{Timeout: cfg.Timeout, Transport: tracing.Transport(nil)},
+		cacheTTL:   cfg.CacheTTL,
+		now:        time.Now,
 	}
 }
 
+// SetPortRepo 注入港口主数据，搜索与解析时优先命中港口。
+func (s *PortCityService) SetPortRepo(ports PortMasterLookup) *PortCityService {
+	s.ports = ports
+	return s
+}
+
+// SetGeocodeCache 注入外部搜索结果缓存，相同查询词在缓存有效期内不再请求外部服务。
+func (s *PortCityService) SetGeocodeCache(cache GeocodeCacheStore) *PortCityService {
+	s.geocache = cache
+	return s
+}
+
 // SetCustomDestinationRepo 注入自定义目的地仓储，以便搜索时合并自定义目的地结果。
 func (s *PortCityService) SetCustomDestinationRepo(repo CustomDestinationRepo) *PortCityService {
 	s.customRepo = repo
@@ -115,7 +156,7 @@ func (s *PortCityService) Search(ctx context.Context, keyword string) ([]PortCit
 		return []PortCityOption{}, nil
 	}
 	items := make([]PortCityOption, 0, 8)
-	items = append(items, searchLocalPortCities(trimmed)...)
+	items = append(items, s.searchPorts(ctx, trimmed)...)
 	// 搜索自定义目的地（数据库）
 	if s.customRepo != nil {
 		customResults, _ := s.customRepo.SearchByKeyword(ctx, trimmed)
@@ -159,7 +200,7 @@ func (s *PortCityService) ResolveLabel(ctx context.Context, label string) (*Reso
 	if trimmed == "海上巡游" {
 		return &ResolvedPortCity{Label: trimmed, IsSpecial: true}, nil
 	}
-	if resolved := resolveLocalPortCity(trimmed); resolved != nil {
+	if resolved := s.resolvePort(ctx, trimmed); resolved != nil {
 		return resolved, nil
 	}
 	// 解析 "名称（国家）" 格式并尝试匹配自定义目的地
@@ -212,10 +253,75 @@ func parseLabelParts(label string) (string, string) {
 	return "", ""
 }
 
+// searchRemote 查询外部地理编码服务；已注入缓存时先按规范化查询词读取缓存，
+// 未命中时请求外部服务并写回缓存（空结果同样缓存），请求失败不写缓存。
 func (s *PortCityService) searchRemote(ctx context.Context, keyword string) ([]nominatimResult, error) {
 	if s == nil || s.endpoint == "" {
 		return []nominatimResult{}, nil
 	}
+	query := geocodeCacheQuery(keyword)
+	if cached, ok := s.loadGeocodeCache(ctx, query); ok {
+		return cached, nil
+	}
+	results, err := s.fetchRemote(ctx, keyword)
+	if err != nil {
+		return nil, err
+	}
+	s.storeGeocodeCache(ctx, query, results)
+	return results, nil
+}
+
+// geocodeCacheQuery 规范化缓存查询词：去除首尾空白、合并连续空白并转小写。
+func geocodeCacheQuery(keyword string) string {
+	return strings.ToLower(strings.Join(strings.Fields(keyword), " "))
+}
+
+// loadGeocodeCache 读取未过期的外部搜索缓存。
+func (s *PortCityService) loadGeocodeCache(ctx context.Context, query string) ([]nominatimResult, bool) {
+	if s.geocache == nil {
+		return nil, false
+	}
+	entry, err := s.geocache.Get(ctx, geocodeProviderNominatim, query, s.now())
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Warn("port city: load geocode cache failed", zap.Error(err))
+		}
+		return nil, false
+	}
+	var results []nominatimResult
+	if err := json.Unmarshal([]byte(entry.Payload), &results); err != nil {
+		logger.FromContext(ctx).Warn("port city: decode geocode cache failed", zap.String("query", query), zap.Error(err))
+		return nil, false
+	}
+	return results, true
+}
+
+// storeGeocodeCache 写入外部搜索缓存；超长查询词不缓存，写入失败只记录日志。
+func (s *PortCityService) storeGeocodeCache(ctx context.Context, query string, results []nominatimResult) {
+	if s.geocache == nil || utf8.RuneCountInString(query) > geocodeQueryMaxLen {
+		return
+	}
+	if results == nil {
+		results = []nominatimResult{}
+	}
+	payload, err := json.Marshal(results)
+	if err != nil {
+		logger.FromContext(ctx).Warn("port city: encode geocode cache failed", zap.Error(err))
+		return
+	}
+	entry := &domain.GeocodeCache{
+		Provider:  geocodeProviderNominatim,
+		Query:     query,
+		Payload:   string(payload),
+		ExpiresAt: s.now().Add(s.cacheTTL),
+	}
+	if err := s.geocache.Upsert(ctx, entry); err != nil {
+		logger.FromContext(ctx).Warn("port city: store geocode cache failed", zap.Error(err))
+	}
+}
+
+// fetchRemote 请求 Nominatim 地理编码服务。
+func (s *PortCityService) fetchRemote(ctx context.Context, keyword string) ([]nominatimResult, error) {
 	requestURL, err := url.Parse(s.endpoint)
 	if err != nil {
 		return nil, err
@@ -316,38 +422,27 @@ func isSeaCruiseKeyword(keyword string) bool {
 	return false
 }
 
-func searchLocalPortCities(keyword string) []PortCityOption {
+// searchPorts 查询港口主数据，名称或关键词以输入开头的港口排在包含匹配之前。
+func (s *PortCityService) searchPorts(ctx context.Context, keyword string) []PortCityOption {
 	trimmed := normalizeSearchKeyword(keyword)
-	if trimmed == "" {
+	if s.ports == nil || trimmed == "" {
 		return []PortCityOption{}
 	}
-	prefixItems := make([]PortCityOption, 0, 6)
-	fuzzyItems := make([]PortCityOption, 0, 6)
-	for _, entry := range localPortCityCatalog {
-		matchedPrefix := false
-		matchedContains := false
-		for _, candidate := range entry.Keywords {
-			normalizedCandidate := normalizeSearchKeyword(candidate)
-			if normalizedCandidate == "" {
-				continue
-			}
-			if strings.HasPrefix(normalizedCandidate, trimmed) || strings.HasPrefix(trimmed, normalizedCandidate) {
-				matchedPrefix = true
-				break
-			}
-			if strings.Contains(normalizedCandidate, trimmed) || strings.Contains(trimmed, normalizedCandidate) {
-				matchedContains = true
-			}
-		}
-		if !matchedPrefix && !matchedContains {
-			continue
-		}
+	ports, err := s.ports.SearchByKeyword(ctx, keyword, portCitySearchLimit)
+	if err != nil {
+		logger.FromContext(ctx).Warn("port city: search ports failed", zap.Error(err))
+		return []PortCityOption{}
+	}
+	prefixItems := make([]PortCityOption, 0, len(ports))
+	fuzzyItems := make([]PortCityOption, 0, len(ports))
+	for _, port := range ports {
 		option := PortCityOption{
-			Label:       entry.Label,
-			CityName:    entry.CityName,
-			CountryName: entry.CountryName,
+			Label:       port.Label(),
+			CityName:    port.NameZH,
+			CountryName: port.Country,
+			PortCode:    port.Code,
 		}
-		if matchedPrefix {
+		if portMatchesPrefix(port, trimmed) {
 			prefixItems = appendUniquePortCityOption(prefixItems, option)
 		} else {
 			fuzzyItems = appendUniquePortCityOption(fuzzyItems, option)
@@ -356,6 +451,17 @@ func searchLocalPortCities(keyword string) []PortCityOption {
 	return append(prefixItems, fuzzyItems...)
 }
 
+// portMatchesPrefix 判断港口代码、中英文名称或任一关键词是否以规范化后的输入开头。
+func portMatchesPrefix(port domain.Port, keyword string) bool {
+	candidates := append([]string{port.Code, port.NameZH, port.NameEN}, strings.Split(port.Keywords, ",")...)
+	for _, candidate := range candidates {
+		if normalized := normalizeSearchKeyword(candidate); normalized != "" && strings.HasPrefix(normalized, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
 func normalizeMultilingualValue(raw string) string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
@@ -388,23 +494,37 @@ func containsHan(text string) bool {
 	return false
 }
 
-func resolveLocalPortCity(label string) *ResolvedPortCity {
-	trimmed := strings.TrimSpace(label)
-	for _, entry := range localPortCityCatalog {
-		if entry.Label != trimmed {
-			continue
-		}
-		lat := entry.Latitude
-		lon := entry.Longitude
-		return &ResolvedPortCity{
-			Label:       entry.Label,
-			CityName:    entry.CityName,
-			CountryName: entry.CountryName,
-			Latitude:    &lat,
-			Longitude:   &lon,
+// resolvePort 将 "名称（国家）" 标签或裸名称（中文名、英文名、UN/LOCODE）解析为港口主数据。
+func (s *PortCityService) resolvePort(ctx context.Context, label string) *ResolvedPortCity {
+	if s.ports == nil {
+		return nil
+	}
+	name, country := parseLabelParts(label)
+	if name == "" {
+		name = label
+	}
+	port, err := s.ports.FindByName(ctx, name, country)
+	if err != nil || port == nil {
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.FromContext(ctx).Warn("port city: resolve port failed", zap.String("label", label), zap.Error(err))
 		}
+		return nil
+	}
+	return resolvedFromPort(port)
+}
+
+// resolvedFromPort 将港口主数据转换为解析结果。
+func resolvedFromPort(port *domain.Port) *ResolvedPortCity {
+	lat, lon := port.Latitude, port.Longitude
+	return &ResolvedPortCity{
+		Label:       port.Label(),
+		CityName:    port.NameZH,
+		CountryName: port.Country,
+		Latitude:    &lat,
+		Longitude:   &lon,
+		PortCode:    port.Code,
+		Timezone:    port.Timezone,
 	}
-	return nil
 }
 
 func appendUniquePortCityOption(items []PortCityOption, option PortCityOption) []PortCityOption {
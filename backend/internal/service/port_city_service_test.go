@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
 )
 
 type customDestinationRepoStub struct {
@@ -53,6 +54,9 @@ func (s *customDestinationRepoStub) GetByLabel(_ context.Context, name, country
 func (s *customDestinationRepoStub) UpsertByNameCountry(_ context.Context, _ *domain.CustomDestination) error {
 	return nil
 }
+func (s *customDestinationRepoStub) UpsertBatch(_ context.Context, _ []domain.CustomDestination) error {
+	return nil
+}
 func (s *customDestinationRepoStub) Delete(_ context.Context, _ int64) error { return nil }
 
 func TestPortCityServiceSearchFormatsCityCountryAndIncludesSeaCruise(t *testing.T) {
@@ -190,3 +194,105 @@ func TestPortCityServiceResolveLabelReturnsCoordinates(t *testing.T) {
 		t.Fatalf("expected special option without coordinates, got %+v", special)
 	}
 }
+
+func TestPortCityServiceUsesPortMasterData(t *testing.T) {
+	svc := NewPortCityService(PortCityServiceConfig{}).SetPortRepo(testPorts)
+	svc.SetCustomDestinationRepo(&customDestinationRepoStub{searchResults: []domain.CustomDestination{{Name: "博多湾小岛", Country: "日本", Latitude: floatPtr(33.6), Longitude: floatPtr(130.3)}}})
+
+	items, err := svc.Search(context.Background(), "博多")
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(items) != 2 || items[0].Label != "福冈（日本）" || items[0].PortCode != "JPFUK" || items[1].Label != "博多湾小岛（日本）" {
+		t.Fatalf("expected port master result before custom destinations, got %+v", items)
+	}
+
+	for _, label := range []string{"福冈（日本）", "福冈"} {
+		resolved, err := svc.ResolveLabel(context.Background(), label)
+		if err != nil {
+			t.Fatalf("ResolveLabel(%s) returned error: %v", label, err)
+		}
+		if resolved == nil || resolved.Label != "福冈（日本）" || *resolved.Latitude != 33.5902 || resolved.Timezone != "Asia/Tokyo" || resolved.PortCode != "JPFUK" {
+			t.Fatalf("expected port master resolution for %s, got %+v", label, resolved)
+		}
+	}
+
+	resolved, err := svc.ResolveLabel(context.Background(), "福冈（中国）")
+	if err != nil || resolved != nil {
+		t.Fatalf("expected country mismatch to fall through, got %+v, %v", resolved, err)
+	}
+}
+
+// geocodeCacheStub 是按服务商与查询词索引的内存地理编码缓存。
+type geocodeCacheStub struct {
+	entries map[string]domain.GeocodeCache
+}
+
+func (s *geocodeCacheStub) Get(_ context.Context, provider, query string, now time.Time) (*domain.GeocodeCache, error) {
+	entry, ok := s.entries[provider+"|"+query]
+	if !ok || !entry.ExpiresAt.After(now) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (s *geocodeCacheStub) Upsert(_ context.Context, entry *domain.GeocodeCache) error {
+	s.entries[entry.Provider+"|"+entry.Query] = *entry
+	return nil
+}
+
+func TestPortCityServiceCachesRemoteSearchResults(t *testing.T) {
+	requests := 0
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failing {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.URL.Query().Get("q") == "无结果" {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"lat": "37.4563", "lon": "126.7052", "address": map[string]any{"city": "仁川", "country": "韩国", "country_code": "kr"}},
+		})
+	}))
+	defer server.Close()
+
+	cache := &geocodeCacheStub{entries: map[string]domain.GeocodeCache{}}
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	svc := NewPortCityService(PortCityServiceConfig{Endpoint: server.URL, Timeout: 2 * time.Second, CacheTTL: time.Hour}).SetGeocodeCache(cache)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, keyword := range []string{"Incheon  Port", " incheon port"} {
+		items, err := svc.Search(ctx, keyword)
+		if err != nil || len(items) != 1 || items[0].Label != "仁川（韩国）" {
+			t.Fatalf("%q: expected remote result, got %+v, %v", keyword, items, err)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected normalized keyword to hit cache, got %d requests", requests)
+	}
+	if entry := cache.entries["nominatim|incheon port"]; !entry.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("expected cache entry to expire after ttl, got %+v", entry)
+	}
+
+	if _, err := svc.Search(ctx, "无结果"); err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	_, _ = svc.Search(ctx, "无结果")
+	if requests != 2 {
+		t.Fatalf("expected empty results to be cached, got %d requests", requests)
+	}
+
+	now = now.Add(2 * time.Hour)
+	failing = true
+	if items, _ := svc.Search(ctx, "incheon port"); len(items) != 0 || requests != 3 {
+		t.Fatalf("expired cache should query remote again, got %+v, %d requests", items, requests)
+	}
+	if entry := cache.entries["nominatim|incheon port"]; entry.ExpiresAt.After(now) {
+		t.Fatalf("failed request must not refresh cache, got %+v", entry)
+	}
+}
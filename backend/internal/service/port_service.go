@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+const maxPortImportRows = 5000 // 单次 CSV 导入允许的最大数据行数
+
+var (
+	// ErrInvalidPort 表示港口字段或导入文件不合法。
+	ErrInvalidPort = errors.New("invalid port")
+	// ErrPortNotFound 表示港口不存在。
+	ErrPortNotFound = errors.New("port not found")
+	// ErrPortCodeExists 表示 UN/LOCODE 已被其他港口占用。
+	ErrPortCodeExists = errors.New("port code already exists")
+)
+
+// unLocodePattern 校验 UN/LOCODE：2 位国家代码 + 3 位地点代码（字母或数字 2-9）。
+var unLocodePattern = regexp.MustCompile(`^[A-Z]{2}[A-Z2-9]{3}$`)
+
+// portCSVHeader 是港口 CSV 导出列顺序，导入时按表头名称映射，列顺序不限。
+var portCSVHeader = []string{
+	"code", "name_zh", "name_en", "country", "country_code", "timezone", "latitude", "longitude",
+	"keywords", "terminal_name", "terminal_address", "terminal_notes", "status", "sort_order",
+}
+
+// portCSVRequired 是导入时必须存在的列。
+var portCSVRequired = []string{"code", "name_zh", "timezone", "latitude", "longitude"}
+
+// PortRepo 定义港口服务所需的数据访问能力。
+type PortRepo interface {
+	domain.PortRepository
+}
+
+// PortImportRowError 描述 CSV 导入中单行的失败原因，Row 为文件中的行号（含表头）。
+type PortImportRowError struct {
+	Row     int    `json:"row"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// PortImportSummary 汇总 CSV 导入结果；合法行按代码新增或覆盖，非法行跳过并记录原因。
+type PortImportSummary struct {
+	Total    int                  `json:"total"`
+	Imported int                  `json:"imported"`
+	Failed   int                  `json:"failed"`
+	Errors   []PortImportRowError `json:"errors"`
+}
+
+// PortService 提供港口主数据的维护与 CSV 导入导出。
+type PortService struct {
+	repo PortRepo
+}
+
+// NewPortService 创建港口服务实例。
+func NewPortService(repo PortRepo) *PortService {
+	return &PortService{repo: repo}
+}
+
+// Create 校验并创建港口。
+func (s *PortService) Create(ctx context.Context, port *domain.Port) error {
+	if err := normalizePort(port); err != nil {
+		return err
+	}
+	if port.Source == "" {
+		port.Source = domain.PortSourceAdmin
+	}
+	return translatePortError(s.repo.Create(ctx, port))
+}
+
+// Update 校验并覆盖港口的可编辑字段。
+func (s *PortService) Update(ctx context.Context, id int64, input *domain.Port) (*domain.Port, error) {
+	existing, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := normalizePort(input); err != nil {
+		return nil, err
+	}
+	input.ID = existing.ID
+	input.Source = existing.Source
+	input.CreatedAt = existing.CreatedAt
+	if err := translatePortError(s.repo.Update(ctx, input)); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// Get 查询港口详情。
+func (s *PortService) Get(ctx context.Context, id int64) (*domain.Port, error) {
+	port, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translatePortError(err)
+	}
+	return port, nil
+}
+
+// List 分页查询港口。
+func (s *PortService) List(ctx context.Context, filter domain.PortFilter, page, pageSize int) ([]domain.Port, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 200 {
+		pageSize = 20
+	}
+	return s.repo.List(ctx, filter, page, pageSize)
+}
+
+// Delete 删除港口。
+func (s *PortService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ExportCSV 导出全部港口，列顺序与导入模板一致。
+func (s *PortService) ExportCSV(ctx context.Context) ([]byte, error) {
+	items, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	buf := &strings.Builder{}
+	writer := csv.NewWriter(buf)
+	if err := writer.Write(portCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		row := []string{
+			item.Code,
+			item.NameZH,
+			item.NameEN,
+			item.Country,
+			item.CountryCode,
+			item.Timezone,
+			strconv.FormatFloat(item.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(item.Longitude, 'f', -1, 64),
+			item.Keywords,
+			item.TerminalName,
+			item.TerminalAddress,
+			item.TerminalNotes,
+			strconv.Itoa(int(item.Status)),
+			strconv.Itoa(item.SortOrder),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+// ImportCSV 按表头名称映射导入港口并按代码新增或覆盖。
+// 表头缺少必填列或文件无法解析时整体失败；单行校验失败只跳过该行，并在汇总中返回行号与原因。
+func (s *PortService) ImportCSV(ctx context.Context, reader io.Reader) (*PortImportSummary, error) {
+	parsed := csv.NewReader(reader)
+	parsed.FieldsPerRecord = -1
+	parsed.TrimLeadingSpace = true
+	summary := &PortImportSummary{Errors: []PortImportRowError{}}
+	header, err := parsed.Read()
+	if errors.Is(err, io.EOF) {
+		return summary, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPort, err)
+	}
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = idx
+	}
+	for _, name := range portCSVRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidPort, name)
+		}
+	}
+
+	seen := make(map[string]int)
+	for {
+		record, err := parsed.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPort, err)
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		if summary.Total++; summary.Total > maxPortImportRows {
+			return nil, fmt.Errorf("%w: at most %d rows per import", ErrInvalidPort, maxPortImportRows)
+		}
+		rowNo, _ := parsed.FieldPos(0)
+		field := func(name string) string {
+			if col, ok := columns[name]; ok && col < len(record) {
+				return strings.TrimSpace(record[col])
+			}
+			return ""
+		}
+		port, err := portFromCSVRow(field)
+		if err == nil {
+			if first, dup := seen[port.Code]; dup {
+				err = fmt.Errorf("%w: duplicate code, first seen on row %d", ErrInvalidPort, first)
+			} else {
+				seen[port.Code] = rowNo
+			}
+		}
+		if err == nil {
+			err = s.repo.UpsertByCode(ctx, port)
+		}
+		if err != nil {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, PortImportRowError{Row: rowNo, Code: strings.ToUpper(field("code")), Message: err.Error()})
+			continue
+		}
+		summary.Imported++
+	}
+	return summary, nil
+}
+
+// portFromCSVRow 将一行 CSV 转换为港口并完成校验，status 缺省为启用。
+func portFromCSVRow(field func(string) string) (*domain.Port, error) {
+	latitude, err := strconv.ParseFloat(field("latitude"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid latitude", ErrInvalidPort)
+	}
+	longitude, err := strconv.ParseFloat(field("longitude"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid longitude", ErrInvalidPort)
+	}
+	status, err := parseOptionalInt(field("status"), 1)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid status", ErrInvalidPort)
+	}
+	sortOrder, err := parseOptionalInt(field("sort_order"), 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid sort_order", ErrInvalidPort)
+	}
+	port := &domain.Port{
+		Code:            field("code"),
+		NameZH:          field("name_zh"),
+		NameEN:          field("name_en"),
+		Country:         field("country"),
+		CountryCode:     field("country_code"),
+		Timezone:        field("timezone"),
+		Latitude:        latitude,
+		Longitude:       longitude,
+		Keywords:        field("keywords"),
+		TerminalName:    field("terminal_name"),
+		TerminalAddress: field("terminal_address"),
+		TerminalNotes:   field("terminal_notes"),
+		Source:          domain.PortSourceCSVImport,
+		Status:          int16(status),
+		SortOrder:       sortOrder,
+	}
+	if err := normalizePort(port); err != nil {
+		return nil, err
+	}
+	return port, nil
+}
+
+// normalizePort 规范化并校验港口字段：代码与国家代码转大写，国家代码缺省取 UN/LOCODE 前两位。
+func normalizePort(port *domain.Port) error {
+	if port == nil {
+		return fmt.Errorf("%w: port is required", ErrInvalidPort)
+	}
+	port.Code = strings.ToUpper(strings.TrimSpace(port.Code))
+	port.NameZH = strings.TrimSpace(port.NameZH)
+	port.NameEN = strings.TrimSpace(port.NameEN)
+	port.Country = strings.TrimSpace(port.Country)
+	port.CountryCode = strings.ToUpper(strings.TrimSpace(port.CountryCode))
+	port.Timezone = strings.TrimSpace(port.Timezone)
+	if !unLocodePattern.MatchString(port.Code) {
+		return fmt.Errorf("%w: code must be a 5-character UN/LOCODE", ErrInvalidPort)
+	}
+	if port.CountryCode == "" {
+		port.CountryCode = port.Code[:2]
+	}
+	if port.NameZH == "" {
+		return fmt.Errorf("%w: name_zh is required", ErrInvalidPort)
+	}
+	if port.Timezone == "" {
+		return fmt.Errorf("%w: timezone is required", ErrInvalidPort)
+	}
+	if _, err := time.LoadLocation(port.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %s", ErrInvalidPort, port.Timezone)
+	}
+	if port.Latitude < -90 || port.Latitude > 90 || port.Longitude < -180 || port.Longitude > 180 {
+		return fmt.Errorf("%w: coordinates out of range", ErrInvalidPort)
+	}
+	if port.Status != 0 && port.Status != 1 {
+		return fmt.Errorf("%w: status must be 0 or 1", ErrInvalidPort)
+	}
+	return nil
+}
+
+func translatePortError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrPortNotFound
+	case errors.Is(err, domain.ErrPortCodeExists):
+		return ErrPortCodeExists
+	default:
+		return err
+	}
+}
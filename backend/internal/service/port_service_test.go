@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// portRepoStub 是按代码索引的内存港口仓储。
+type portRepoStub struct {
+	items  map[string]*domain.Port
+	nextID int64
+}
+
+func newPortRepoStub(ports ...domain.Port) *portRepoStub {
+	stub := &portRepoStub{items: map[string]*domain.Port{}}
+	for _, port := range ports {
+		copyPort := port
+		stub.nextID++
+		copyPort.ID = stub.nextID
+		stub.items[port.Code] = &copyPort
+	}
+	return stub
+}
+
+func (s *portRepoStub) Create(_ context.Context, port *domain.Port) error {
+	if _, exists := s.items[port.Code]; exists {
+		return domain.ErrPortCodeExists
+	}
+	s.nextID++
+	port.ID = s.nextID
+	copyPort := *port
+	s.items[port.Code] = &copyPort
+	return nil
+}
+func (s *portRepoStub) Update(_ context.Context, port *domain.Port) error {
+	if existing, ok := s.items[port.Code]; ok && existing.ID != port.ID {
+		return domain.ErrPortCodeExists
+	}
+	for code, item := range s.items {
+		if item.ID == port.ID {
+			delete(s.items, code)
+		}
+	}
+	copyPort := *port
+	s.items[port.Code] = &copyPort
+	return nil
+}
+func (s *portRepoStub) GetByID(_ context.Context, id int64) (*domain.Port, error) {
+	for _, item := range s.items {
+		if item.ID == id {
+			copyPort := *item
+			return &copyPort, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (s *portRepoStub) GetByCode(_ context.Context, code string) (*domain.Port, error) {
+	if item, ok := s.items[code]; ok {
+		copyPort := *item
+		return &copyPort, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (s *portRepoStub) List(ctx context.Context, _ domain.PortFilter, _, _ int) ([]domain.Port, int64, error) {
+	items, _ := s.ListAll(ctx)
+	return items, int64(len(items)), nil
+}
+func (s *portRepoStub) ListAll(context.Context) ([]domain.Port, error) {
+	items := make([]domain.Port, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, *item)
+	}
+	return items, nil
+}
+func (s *portRepoStub) SearchByKeyword(context.Context, string, int) ([]domain.Port, error) {
+	return nil, nil
+}
+func (s *portRepoStub) FindByName(context.Context, string, string) (*domain.Port, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (s *portRepoStub) UpsertByCode(_ context.Context, port *domain.Port) error {
+	if existing, ok := s.items[port.Code]; ok {
+		port.ID = existing.ID
+	} else {
+		s.nextID++
+		port.ID = s.nextID
+	}
+	copyPort := *port
+	s.items[port.Code] = &copyPort
+	return nil
+}
+func (s *portRepoStub) Delete(_ context.Context, id int64) error {
+	for code, item := range s.items {
+		if item.ID == id {
+			delete(s.items, code)
+		}
+	}
+	return nil
+}
+
+func TestPortServiceCreateValidatesAndNormalizes(t *testing.T) {
+	repo := newPortRepoStub()
+	svc := NewPortService(repo)
+	ctx := context.Background()
+
+	port := &domain.Port{Code: " cnsha ", NameZH: "上海", Timezone: "Asia/Shanghai", Latitude: 31.2304, Longitude: 121.4737, Status: 1}
+	if err := svc.Create(ctx, port); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if port.Code != "CNSHA" || port.CountryCode != "CN" || port.Source != domain.PortSourceAdmin {
+		t.Fatalf("expected normalized port, got %+v", port)
+	}
+
+	cases := map[string]domain.Port{
+		"bad code":     {Code: "SHA", NameZH: "上海", Timezone: "Asia/Shanghai"},
+		"missing name": {Code: "CNSHB", Timezone: "Asia/Shanghai"},
+		"bad timezone": {Code: "CNSHB", NameZH: "上海", Timezone: "Asia/Atlantis"},
+		"latitude":     {Code: "CNSHB", NameZH: "上海", Timezone: "Asia/Shanghai", Latitude: 91},
+		"status":       {Code: "CNSHB", NameZH: "上海", Timezone: "Asia/Shanghai", Status: 3},
+	}
+	for name, input := range cases {
+		candidate := input
+		if err := svc.Create(ctx, &candidate); !errors.Is(err, ErrInvalidPort) {
+			t.Fatalf("%s: expected ErrInvalidPort, got %v", name, err)
+		}
+	}
+
+	duplicate := &domain.Port{Code: "CNSHA", NameZH: "上海", Timezone: "Asia/Shanghai"}
+	if err := svc.Create(ctx, duplicate); !errors.Is(err, ErrPortCodeExists) {
+		t.Fatalf("expected ErrPortCodeExists, got %v", err)
+	}
+}
+
+func TestPortServiceUpdateKeepsSourceAndReportsMissing(t *testing.T) {
+	repo := newPortRepoStub(domain.Port{Code: "JPFUK", NameZH: "福冈", Timezone: "Asia/Tokyo", Source: "system_port_city_dictionary_seed_v1", Status: 1})
+	svc := NewPortService(repo)
+	ctx := context.Background()
+
+	updated, err := svc.Update(ctx, 1, &domain.Port{Code: "JPFUK", NameZH: "福冈", NameEN: "Fukuoka", Timezone: "Asia/Tokyo", Latitude: 33.6065, Longitude: 130.396, TerminalName: "博多港国际码头", Status: 1})
+	if err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if updated.Source != "system_port_city_dictionary_seed_v1" || updated.TerminalName != "博多港国际码头" {
+		t.Fatalf("unexpected updated port: %+v", updated)
+	}
+	if _, err := svc.Update(ctx, 99, &domain.Port{Code: "JPFUK", NameZH: "福冈", Timezone: "Asia/Tokyo"}); !errors.Is(err, ErrPortNotFound) {
+		t.Fatalf("expected ErrPortNotFound, got %v", err)
+	}
+	if err := svc.Delete(ctx, 99); !errors.Is(err, ErrPortNotFound) {
+		t.Fatalf("expected ErrPortNotFound on delete, got %v", err)
+	}
+}
+
+func TestPortServiceImportCSVReportsRowErrors(t *testing.T) {
+	repo := newPortRepoStub(domain.Port{Code: "CNSHA", NameZH: "上海", Timezone: "Asia/Shanghai", Latitude: 31.2304, Longitude: 121.4737, Status: 1})
+	svc := NewPortService(repo)
+
+	csvBody := "\ufeffname_zh,code,latitude,longitude,timezone,terminal_name,status\n" +
+		"上海,cnsha,31.35,121.50,Asia/Shanghai,吴淞口国际邮轮港,1\n" +
+		"釜山,KRPUS,35.1796,129.0756,Asia/Seoul,,0\n" +
+		"坏坐标,JPBAD,abc,130,Asia/Tokyo,,\n" +
+		"\n" +
+		"釜山重复,KRPUS,35.1,129.0,Asia/Seoul,,\n"
+	summary, err := svc.ImportCSV(context.Background(), strings.NewReader(csvBody))
+	if err != nil {
+		t.Fatalf("ImportCSV returned error: %v", err)
+	}
+	if summary.Total != 4 || summary.Imported != 2 || summary.Failed != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if summary.Errors[0].Row != 4 || summary.Errors[0].Code != "JPBAD" || summary.Errors[1].Row != 6 {
+		t.Fatalf("unexpected row errors: %+v", summary.Errors)
+	}
+	if repo.items["CNSHA"].TerminalName != "吴淞口国际邮轮港" || repo.items["CNSHA"].Latitude != 31.35 {
+		t.Fatalf("expected existing port to be overwritten, got %+v", repo.items["CNSHA"])
+	}
+	if busan := repo.items["KRPUS"]; busan == nil || busan.Status != 0 || busan.Source != domain.PortSourceCSVImport || busan.CountryCode != "KR" {
+		t.Fatalf("expected disabled Busan port imported, got %+v", busan)
+	}
+
+	if _, err := svc.ImportCSV(context.Background(), strings.NewReader("code,name_zh\nCNSHA,上海\n")); !errors.Is(err, ErrInvalidPort) {
+		t.Fatalf("expected missing column error, got %v", err)
+	}
+}
+
+func TestPortServiceExportCSVRoundTrips(t *testing.T) {
+	repo := newPortRepoStub(domain.Port{Code: "CNSHA", NameZH: "上海", NameEN: "Shanghai", Country: "中国", CountryCode: "CN", Timezone: "Asia/Shanghai", Latitude: 31.2304, Longitude: 121.4737, TerminalNotes: "地铁 3 号线, 吴淞码头站", Status: 1, SortOrder: 100})
+	svc := NewPortService(repo)
+
+	data, err := svc.ExportCSV(context.Background())
+	if err != nil {
+		t.Fatalf("ExportCSV returned error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), strings.Join(portCSVHeader, ",")) {
+		t.Fatalf("expected csv header, got %s", data)
+	}
+	summary, err := NewPortService(newPortRepoStub()).ImportCSV(context.Background(), strings.NewReader(string(data)))
+	if err != nil || summary.Imported != 1 || summary.Failed != 0 {
+		t.Fatalf("expected exported csv to import cleanly, got %+v, %v", summary, err)
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// dueVersionPromoter 提升已到期价格版本，由 VoyageCabinTypePriceService 实现。
+type dueVersionPromoter interface {
+	PromoteDueVersions(ctx context.Context) (int, error)
+}
+
+// PriceVersionScheduler 定期把到期的未来价格版本刷新进 voyage_cabin_type_current，
+// 避免当前态停留在旧价格直到下一次写入。RunOnce 返回刷新的航次舱型数量。
+type PriceVersionScheduler struct {
+	*periodicJob
+}
+
+// NewPriceVersionScheduler 创建价格版本调度器；interval 非正数时默认 1 分钟。
+func NewPriceVersionScheduler(promoter dueVersionPromoter, interval time.Duration) *PriceVersionScheduler {
+	return &PriceVersionScheduler{periodicJob: newPeriodicJob("price_version_scheduler: promote due versions", interval, promoter.PromoteDueVersions)}
+}
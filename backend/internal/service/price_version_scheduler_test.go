@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingPromoter struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (p *countingPromoter) PromoteDueVersions(_ context.Context) (int, error) {
+	p.calls.Add(1)
+	return 2, p.err
+}
+
+func TestPriceVersionScheduler_RunOnce(t *testing.T) {
+	promoter := &countingPromoter{}
+	scheduler := NewPriceVersionScheduler(promoter, 0)
+	assert.Equal(t, time.Minute, scheduler.interval)
+	assert.Equal(t, 2, scheduler.RunOnce(context.Background()))
+
+	promoter.err = errors.New("db down")
+	assert.Equal(t, 2, scheduler.RunOnce(context.Background()), "部分失败时仍返回已提升数量")
+}
+
+func TestPriceVersionScheduler_StartStop(t *testing.T) {
+	promoter := &countingPromoter{}
+	scheduler := NewPriceVersionScheduler(promoter, 5*time.Millisecond)
+	scheduler.Start()
+	scheduler.Start()
+	assert.Eventually(t, func() bool { return promoter.calls.Load() >= 2 }, time.Second, time.Millisecond)
+	scheduler.Stop()
+	scheduler.Stop()
+
+	calls := promoter.calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, calls, promoter.calls.Load(), "停止后不再执行")
+}
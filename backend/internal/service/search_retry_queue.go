@@ -3,6 +3,8 @@ package service
 import (
 	"sync"
 	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
 )
 
 // retryTask 表示一次待重试的索引任务。
@@ -50,8 +52,10 @@ func (q *SearchRetryQueue) Start() {
 				task.attempts++
 				if task.attempts < q.maxRetry {
 					time.AfterFunc(time.Duration(task.attempts)*time.Second, func() {
-						q.Enqueue(task.doc)
+						q.push(task)
 					})
+				} else {
+					metrics.SearchRetryExhausted.Inc()
 				}
 			}
 		}
@@ -63,8 +67,22 @@ func (q *SearchRetryQueue) Enqueue(doc interface{}) {
 	if q == nil {
 		return
 	}
+	q.push(retryTask{doc: doc})
+}
+
+// push 投递任务并保留已重试次数，使达到上限的任务不再重新入队。
+func (q *SearchRetryQueue) push(task retryTask) {
 	select {
-	case q.buffer <- retryTask{doc: doc}:
+	case q.buffer <- task:
 	default:
+		metrics.SearchRetryDropped.Inc()
+	}
+}
+
+// Len 返回队列中等待索引的任务数。
+func (q *SearchRetryQueue) Len() int {
+	if q == nil {
+		return 0
 	}
+	return len(q.buffer)
 }
@@ -5,6 +5,9 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 type flakyIndexer struct {
@@ -39,3 +42,17 @@ func TestSearchRetryQueueRetrySuccess(t *testing.T) {
 		t.Fatalf("expected retry call, got %d", calls)
 	}
 }
+
+func TestSearchRetryQueueCountsDroppedTasks(t *testing.T) {
+	q := NewSearchRetryQueue(nil, 3, 1)
+	before := testutil.ToFloat64(metrics.SearchRetryDropped)
+	q.Enqueue(map[string]any{"id": 1})
+	q.Enqueue(map[string]any{"id": 2})
+
+	if q.Len() != 1 {
+		t.Fatalf("expected 1 queued task, got %d", q.Len())
+	}
+	if got := testutil.ToFloat64(metrics.SearchRetryDropped) - before; got != 1 {
+		t.Fatalf("expected 1 dropped task, got %v", got)
+	}
+}
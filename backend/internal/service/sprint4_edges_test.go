@@ -31,6 +31,12 @@ func (m mockAnalyticsRepo) InventoryOverview(_ context.Context) (*domain.Invento
 func (m mockAnalyticsRepo) PageViewStats(_ context.Context) ([]domain.PageViewData, error) {
 	return nil, nil
 }
+func (m mockAnalyticsRepo) ListBookingFacts(_ context.Context, _ domain.AnalyticsFactFilter) ([]domain.AnalyticsBookingFact, error) {
+	return nil, nil
+}
+func (m mockAnalyticsRepo) ListCapacityFacts(_ context.Context, _ []int64) ([]domain.AnalyticsCapacityFact, error) {
+	return nil, nil
+}
 
 func TestAnalyticsWeeklyTrendEdge(t *testing.T) {
 	svc := NewAnalyticsService(mockAnalyticsRepo{})
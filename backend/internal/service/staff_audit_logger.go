@@ -38,3 +38,17 @@ func (l *StaffOperationLogger) LogRoleChange(ctx context.Context, entry StaffRol
 		Details:    details,
 	})
 }
+
+// LogSecurityEvent 记录密码重置、二次验证重置等账号安全操作日志。
+func (l *StaffOperationLogger) LogSecurityEvent(ctx context.Context, operatorID, targetStaffID int64, operation, details string) error {
+	if l == nil || l.repo == nil {
+		return nil
+	}
+	return l.repo.Create(ctx, &domain.OperationLog{
+		StaffID:    operatorID,
+		Operation:  operation,
+		Resource:   "staff",
+		ResourceID: targetStaffID,
+		Details:    details,
+	})
+}
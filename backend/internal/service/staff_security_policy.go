@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StaffSecurityPolicy 定义员工账号的密码复杂度、历史、有效期、登录锁定与二次验证规则。
+// 各字段为零值时由 withDefaults 取 DefaultStaffSecurityPolicy 中的对应值。
+type StaffSecurityPolicy struct {
+	MinPasswordLength int           // 密码最小长度
+	MinCharClasses    int           // 至少包含的字符类别数（大写、小写、数字、符号）
+	PasswordHistory   int           // 新密码不得与当前密码及最近 N 个历史密码相同
+	PasswordMaxAge    time.Duration // 密码有效期，到期后登录时必须修改
+	MaxFailedLogins   int           // 连续失败多少次后锁定
+	LockDuration      time.Duration // 锁定时长
+	ResetTokenTTL     time.Duration // 管理员签发的重置令牌有效期
+	TOTPRequiredRoles []string      // 必须启用 TOTP 二次验证的角色
+	TOTPIssuer        string        // 验证器 App 中显示的签发方名称
+	Now               func() time.Time
+}
+
+// DefaultStaffSecurityPolicy 返回默认的员工账号安全策略：财务与超级管理员强制启用二次验证。
+func DefaultStaffSecurityPolicy() StaffSecurityPolicy {
+	return StaffSecurityPolicy{
+		MinPasswordLength: 10,
+		MinCharClasses:    3,
+		PasswordHistory:   5,
+		PasswordMaxAge:    90 * 24 * time.Hour,
+		MaxFailedLogins:   5,
+		LockDuration:      15 * time.Minute,
+		ResetTokenTTL:     24 * time.Hour,
+		TOTPRequiredRoles: []string{"super_admin", "finance"},
+		TOTPIssuer:        "CruiseBooking",
+		Now:               time.Now,
+	}
+}
+
+func (p StaffSecurityPolicy) withDefaults() StaffSecurityPolicy {
+	def := DefaultStaffSecurityPolicy()
+	if p.MinPasswordLength <= 0 {
+		p.MinPasswordLength = def.MinPasswordLength
+	}
+	if p.MinCharClasses <= 0 {
+		p.MinCharClasses = def.MinCharClasses
+	}
+	if p.PasswordHistory <= 0 {
+		p.PasswordHistory = def.PasswordHistory
+	}
+	if p.PasswordMaxAge <= 0 {
+		p.PasswordMaxAge = def.PasswordMaxAge
+	}
+	if p.MaxFailedLogins <= 0 {
+		p.MaxFailedLogins = def.MaxFailedLogins
+	}
+	if p.LockDuration <= 0 {
+		p.LockDuration = def.LockDuration
+	}
+	if p.ResetTokenTTL <= 0 {
+		p.ResetTokenTTL = def.ResetTokenTTL
+	}
+	if p.TOTPRequiredRoles == nil {
+		p.TOTPRequiredRoles = def.TOTPRequiredRoles
+	}
+	if p.TOTPIssuer == "" {
+		p.TOTPIssuer = def.TOTPIssuer
+	}
+	if p.Now == nil {
+		p.Now = def.Now
+	}
+	return p
+}
+
+// RequiresTOTP 判断该角色是否必须启用二次验证。
+func (p StaffSecurityPolicy) RequiresTOTP(role string) bool {
+	for _, r := range p.TOTPRequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePassword 校验密码长度、字符类别，并禁止密码中包含登录用户名。
+func (p StaffSecurityPolicy) ValidatePassword(password, username string) error {
+	p = p.withDefaults()
+	if utf8.RuneCountInString(password) < p.MinPasswordLength {
+		return fmt.Errorf("%w: at least %d characters required", ErrStaffPasswordPolicy, p.MinPasswordLength)
+	}
+	if len(password) > 72 {
+		// bcrypt 只使用前 72 字节，超出部分不参与校验
+		return fmt.Errorf("%w: at most 72 bytes allowed", ErrStaffPasswordPolicy)
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsSpace(r):
+			return fmt.Errorf("%w: whitespace is not allowed", ErrStaffPasswordPolicy)
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{upper, lower, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < p.MinCharClasses {
+		return fmt.Errorf("%w: use at least %d of uppercase, lowercase, digits and symbols", ErrStaffPasswordPolicy, p.MinCharClasses)
+	}
+	if username = strings.TrimSpace(username); len(username) >= 3 && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("%w: must not contain the username", ErrStaffPasswordPolicy)
+	}
+	return nil
+}
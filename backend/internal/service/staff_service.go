@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
 )
@@ -20,6 +22,29 @@ type StaffService struct {
 	repo        StaffRepository
 	roleSyncer  StaffRoleSyncer
 	auditLogger StaffRoleAuditLogger
+	policy      StaffSecurityPolicy
+	scopes      StaffCompanyScopeStore
+	companies   StaffCompanyLookup
+}
+
+var (
+	// ErrStaffCompanyNotFound 表示授权的公司不存在，或不在操作者自身的数据范围内。
+	ErrStaffCompanyNotFound = errors.New("company not found")
+	// ErrStaffCompanyScopeDenied 表示受限员工试图把他人标记为可访问全部公司的平台管理员。
+	ErrStaffCompanyScopeDenied = errors.New("scoped staff cannot grant access to every company")
+	// ErrStaffManageDenied 表示受限员工试图管理平台管理员或授权了其范围外公司的员工。
+	ErrStaffManageDenied = errors.New("scoped staff cannot manage staff outside their companies")
+)
+
+// StaffCompanyScopeStore 定义员工公司数据范围的读写接口。
+type StaffCompanyScopeStore interface {
+	GetScope(ctx context.Context, staffID int64) (domain.StaffCompanyScope, error)
+	ReplaceScope(ctx context.Context, staffID int64, scope domain.StaffCompanyScope) error
+}
+
+// StaffCompanyLookup 校验公司是否存在，查询受操作者自身的数据范围限制。
+type StaffCompanyLookup interface {
+	GetByID(ctx context.Context, id int64) (*domain.CruiseCompany, error)
 }
 
 type StaffRoleSyncer interface {
@@ -61,6 +86,147 @@ func (s *StaffService) Create(ctx context.Context, name, email, role string) (*d
 	return staff, nil
 }
 
+// SetSecurityPolicy 设置创建账号时使用的密码策略，未设置时使用 DefaultStaffSecurityPolicy。
+func (s *StaffService) SetSecurityPolicy(policy StaffSecurityPolicy) {
+	s.policy = policy
+}
+
+// SetCompanyScope 注入员工公司数据范围的存储与公司校验能力。
+func (s *StaffService) SetCompanyScope(scopes StaffCompanyScopeStore, companies StaffCompanyLookup) {
+	s.scopes, s.companies = scopes, companies
+}
+
+// GetCompanyScope 返回员工的公司数据范围，没有授权任何公司且不是平台管理员时不能访问任何公司的数据。
+func (s *StaffService) GetCompanyScope(ctx context.Context, staffID int64) (domain.StaffCompanyScope, error) {
+	if s.scopes == nil {
+		return domain.StaffCompanyScope{}, errors.New("company scope is not configured")
+	}
+	if staff, err := s.repo.GetByID(ctx, staffID); err != nil || staff == nil {
+		return domain.StaffCompanyScope{}, ErrStaffNotFound
+	}
+	return s.scopes.GetScope(ctx, staffID)
+}
+
+// EnsureManageable 校验操作者能否管理目标员工：受公司范围限制的操作者只能管理授权公司都在自己范围内的员工，
+// 不能管理可访问全部公司的平台管理员，避免借重置密码、修改授权或角色提升自身权限。不受限的操作者不做校验。
+func (s *StaffService) EnsureManageable(ctx context.Context, staffID int64) error {
+	allowed, scoped := domain.CompanyScopeFromContext(ctx)
+	if !scoped {
+		return nil
+	}
+	if s.scopes == nil {
+		return errors.New("company scope is not configured")
+	}
+	target, err := s.scopes.GetScope(ctx, staffID)
+	if err != nil {
+		return err
+	}
+	if target.AllCompanies {
+		return ErrStaffManageDenied
+	}
+	inScope := make(map[int64]bool, len(allowed))
+	for _, id := range allowed {
+		inScope[id] = true
+	}
+	for _, id := range target.CompanyIDs {
+		if !inScope[id] {
+			return ErrStaffManageDenied
+		}
+	}
+	return nil
+}
+
+// AssignCompanies 整体替换员工的公司数据范围：AllCompanies 标记为可访问全部公司的平台管理员，
+// 否则只能访问 CompanyIDs 中的公司，为空表示收回全部授权。
+// 公司校验在操作者的上下文中进行，因此受限员工只能授予自己范围内的公司，且不能授予全部公司。
+func (s *StaffService) AssignCompanies(ctx context.Context, staffID int64, scope domain.StaffCompanyScope, operatorID int64) error {
+	if s.scopes == nil || s.companies == nil {
+		return errors.New("company scope is not configured")
+	}
+	if staff, err := s.repo.GetByID(ctx, staffID); err != nil || staff == nil {
+		return ErrStaffNotFound
+	}
+	if err := s.EnsureManageable(ctx, staffID); err != nil {
+		return err
+	}
+	if scope.AllCompanies {
+		if _, scoped := domain.CompanyScopeFromContext(ctx); scoped {
+			return ErrStaffCompanyScopeDenied
+		}
+		if err := s.scopes.ReplaceScope(ctx, staffID, domain.StaffCompanyScope{AllCompanies: true}); err != nil {
+			return err
+		}
+		s.logCompanyScope(ctx, operatorID, staffID, "all_companies=true")
+		return nil
+	}
+	unique := make([]int64, 0, len(scope.CompanyIDs))
+	seen := make(map[int64]bool, len(scope.CompanyIDs))
+	for _, id := range scope.CompanyIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.companies.GetByID(ctx, id); err != nil {
+			return fmt.Errorf("%w: %d", ErrStaffCompanyNotFound, id)
+		}
+		unique = append(unique, id)
+	}
+	if err := s.scopes.ReplaceScope(ctx, staffID, domain.StaffCompanyScope{CompanyIDs: unique}); err != nil {
+		return err
+	}
+	s.logCompanyScope(ctx, operatorID, staffID, fmt.Sprintf("company_ids=%v", unique))
+	return nil
+}
+
+func (s *StaffService) logCompanyScope(ctx context.Context, operatorID, staffID int64, details string) {
+	if logger, ok := s.auditLogger.(StaffSecurityAuditLogger); ok {
+		_ = logger.LogSecurityEvent(ctx, operatorID, staffID, "assign_companies", details)
+	}
+}
+
+// StaffAccountInput 是创建可登录员工账号的输入。
+// Password 为空时账号暂不可登录，由管理员签发一次性重置令牌交给员工自行设置密码。
+type StaffAccountInput struct {
+	Name     string
+	Email    string
+	Role     string
+	Username string
+	Password string
+}
+
+// CreateAccount 创建带登录用户名的员工账号；管理员设置的初始密码需符合密码策略，且员工首次登录时必须修改。
+func (s *StaffService) CreateAccount(ctx context.Context, in StaffAccountInput) (*domain.Staff, error) {
+	if !domain.IsValidStaffRole(in.Role) {
+		return nil, errors.New("invalid role")
+	}
+	username := strings.TrimSpace(in.Username)
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+	staff := &domain.Staff{
+		Username: username,
+		RealName: in.Name,
+		Email:    in.Email,
+		Role:     in.Role,
+		Status:   1,
+	}
+	if in.Password != "" {
+		if err := s.policy.ValidatePassword(in.Password, username); err != nil {
+			return nil, err
+		}
+		hash, err := HashPassword(in.Password)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		staff.PasswordHash, staff.PasswordChangedAt, staff.MustChangePassword = hash, &now, true
+	}
+	if err := s.repo.Create(ctx, staff); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
 func (s *StaffService) AssignRole(ctx context.Context, id int64, role string, operatorID int64) error {
 	if !domain.IsValidStaffRole(role) {
 		return errors.New("invalid role")
@@ -72,6 +238,9 @@ func (s *StaffService) AssignRole(ctx context.Context, id int64, role string, op
 	if staff == nil {
 		return errors.New("staff not found")
 	}
+	if err := s.EnsureManageable(ctx, id); err != nil {
+		return err
+	}
 	oldRole := staff.Role
 	staff.Role = role
 	if err := s.repo.Update(ctx, staff); err != nil {
@@ -116,9 +285,15 @@ func (s *StaffService) GetByID(ctx context.Context, id int64) (*domain.Staff, er
 }
 
 func (s *StaffService) Update(ctx context.Context, staff *domain.Staff) error {
+	if err := s.EnsureManageable(ctx, staff.ID); err != nil {
+		return err
+	}
 	return s.repo.Update(ctx, staff)
 }
 
 func (s *StaffService) Delete(ctx context.Context, id int64) error {
+	if err := s.EnsureManageable(ctx, id); err != nil {
+		return err
+	}
 	return s.repo.Delete(ctx, id)
 }
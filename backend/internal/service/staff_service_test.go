@@ -150,3 +150,103 @@ func TestStaffServiceAssignRoleReturnsErrorWhenCasbinSyncFails(t *testing.T) {
 	// F-9: 验证 Casbin 同步失败后数据库角色已回滚
 	assert.Equal(t, "operator", repo.staff[1].Role, "role should be rolled back after casbin sync failure")
 }
+
+func TestStaffServiceCreateAccountAppliesPasswordPolicy(t *testing.T) {
+	repo := newFakeStaffRepo()
+	svc := NewStaffService(repo)
+	ctx := context.Background()
+
+	_, err := svc.CreateAccount(ctx, StaffAccountInput{Name: "王五", Email: "w@example.com", Role: "finance", Username: "wangwu", Password: "short"})
+	assert.ErrorIs(t, err, ErrStaffPasswordPolicy)
+	_, err = svc.CreateAccount(ctx, StaffAccountInput{Name: "王五", Role: "finance", Password: "Str0ng-Passw0rd"})
+	assert.Error(t, err, "username is required")
+
+	staff, err := svc.CreateAccount(ctx, StaffAccountInput{Name: "王五", Email: "w@example.com", Role: "finance", Username: " wangwu ", Password: "Str0ng-Passw0rd"})
+	assert.NoError(t, err)
+	assert.Equal(t, "wangwu", staff.Username)
+	assert.True(t, staff.MustChangePassword, "admin-chosen passwords must be changed on first login")
+	assert.True(t, VerifyPassword(staff.PasswordHash, "Str0ng-Passw0rd"))
+
+	invited, err := svc.CreateAccount(ctx, StaffAccountInput{Name: "赵六", Role: "support", Username: "zhaoliu"})
+	assert.NoError(t, err)
+	assert.Empty(t, invited.PasswordHash, "accounts without a password wait for a reset token")
+}
+
+type fakeStaffCompanyStore struct {
+	scopes map[int64]domain.StaffCompanyScope
+}
+
+func (f *fakeStaffCompanyStore) GetScope(_ context.Context, staffID int64) (domain.StaffCompanyScope, error) {
+	return f.scopes[staffID], nil
+}
+
+func (f *fakeStaffCompanyStore) ReplaceScope(_ context.Context, staffID int64, scope domain.StaffCompanyScope) error {
+	f.scopes[staffID] = scope
+	return nil
+}
+
+// fakeScopedCompanyLookup 模拟受数据范围限制的公司仓储：公司 1、2 存在。
+type fakeScopedCompanyLookup struct{}
+
+func (fakeScopedCompanyLookup) GetByID(ctx context.Context, id int64) (*domain.CruiseCompany, error) {
+	if (id == 1 || id == 2) && domain.CompanyInScope(ctx, id) {
+		return &domain.CruiseCompany{ID: id}, nil
+	}
+	return nil, errors.New("record not found")
+}
+
+func TestStaffServiceAssignCompanies(t *testing.T) {
+	repo := newFakeStaffRepo()
+	repo.staff[1] = &domain.Staff{ID: 1, Role: "operator"}
+	store := &fakeStaffCompanyStore{scopes: map[int64]domain.StaffCompanyScope{}}
+	svc := NewStaffService(repo)
+	ctx := context.Background()
+
+	assert.Error(t, svc.AssignCompanies(ctx, 1, domain.StaffCompanyScope{CompanyIDs: []int64{1}}, 9), "scope store must be configured")
+	svc.SetCompanyScope(store, fakeScopedCompanyLookup{})
+
+	assert.NoError(t, svc.AssignCompanies(ctx, 1, domain.StaffCompanyScope{CompanyIDs: []int64{2, 1, 2}}, 9))
+	scope, err := svc.GetCompanyScope(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StaffCompanyScope{CompanyIDs: []int64{2, 1}}, scope)
+
+	assert.ErrorIs(t, svc.AssignCompanies(ctx, 1, domain.StaffCompanyScope{CompanyIDs: []int64{3}}, 9), ErrStaffCompanyNotFound)
+	assert.ErrorIs(t, svc.AssignCompanies(ctx, 404, domain.StaffCompanyScope{CompanyIDs: []int64{1}}, 9), ErrStaffNotFound)
+
+	assert.NoError(t, svc.AssignCompanies(ctx, 1, domain.StaffCompanyScope{CompanyIDs: []int64{1}}, 9))
+	scoped := domain.WithCompanyScope(ctx, []int64{1})
+	assert.ErrorIs(t, svc.AssignCompanies(scoped, 1, domain.StaffCompanyScope{CompanyIDs: []int64{2}}, 9), ErrStaffCompanyNotFound, "scoped staff cannot grant foreign companies")
+	assert.ErrorIs(t, svc.AssignCompanies(scoped, 1, domain.StaffCompanyScope{AllCompanies: true}, 9), ErrStaffCompanyScopeDenied, "scoped staff cannot grant every company")
+	assert.NoError(t, svc.AssignCompanies(scoped, 1, domain.StaffCompanyScope{}, 9), "an empty assignment revokes access instead of lifting it")
+	assert.Equal(t, domain.StaffCompanyScope{CompanyIDs: []int64{}}, store.scopes[1])
+
+	assert.NoError(t, svc.AssignCompanies(ctx, 1, domain.StaffCompanyScope{AllCompanies: true, CompanyIDs: []int64{1}}, 9), "platform admins may grant every company")
+	assert.Equal(t, domain.StaffCompanyScope{AllCompanies: true}, store.scopes[1])
+}
+
+func TestStaffServiceScopedOperatorCannotManageForeignStaff(t *testing.T) {
+	repo := newFakeStaffRepo()
+	repo.staff[1] = &domain.Staff{ID: 1, Role: "operator"}
+	repo.staff[2] = &domain.Staff{ID: 2, Role: "super_admin"}
+	repo.staff[3] = &domain.Staff{ID: 3, Role: "operator"}
+	store := &fakeStaffCompanyStore{scopes: map[int64]domain.StaffCompanyScope{
+		1: {CompanyIDs: []int64{1}},
+		2: {AllCompanies: true},
+		3: {CompanyIDs: []int64{1, 2}},
+	}}
+	svc := NewStaffService(repo)
+	svc.SetCompanyScope(store, fakeScopedCompanyLookup{})
+	scoped := domain.WithCompanyScope(context.Background(), []int64{1})
+
+	for _, id := range []int64{2, 3} {
+		assert.ErrorIs(t, svc.AssignCompanies(scoped, id, domain.StaffCompanyScope{CompanyIDs: []int64{1}}, 9), ErrStaffManageDenied)
+		assert.ErrorIs(t, svc.AssignRole(scoped, id, "operator", 9), ErrStaffManageDenied)
+		assert.ErrorIs(t, svc.Update(scoped, repo.staff[id]), ErrStaffManageDenied)
+		assert.ErrorIs(t, svc.Delete(scoped, id), ErrStaffManageDenied)
+	}
+	assert.Equal(t, domain.StaffCompanyScope{AllCompanies: true}, store.scopes[2], "a scoped operator must not overwrite a platform admin's scope")
+	assert.Equal(t, "super_admin", repo.staff[2].Role)
+
+	assert.NoError(t, svc.AssignCompanies(scoped, 1, domain.StaffCompanyScope{CompanyIDs: []int64{1}}, 9), "staff inside the operator's companies stay manageable")
+	assert.NoError(t, svc.EnsureManageable(context.Background(), 2), "unrestricted admins manage everyone")
+}
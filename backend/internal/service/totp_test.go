@@ -0,0 +1,64 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret 是 RFC 6238 附录 B 中 SHA1 测试向量使用的 ASCII 密钥 "12345678901234567890"。
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPMatchesRFC6238Vectors(t *testing.T) {
+	key, err := decodeTOTPSecret(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors := map[int64]string{
+		59:         "94287082",
+		1111111109: "07081804",
+		1111111111: "14050471",
+		1234567890: "89005924",
+		2000000000: "69279037",
+	}
+	for unix, want := range vectors {
+		if got := hotp(key, uint64(totpStep(time.Unix(unix, 0))), 8); got != want {
+			t.Fatalf("T=%d: expected %s, got %s", unix, want, got)
+		}
+	}
+	if code, _ := TOTPCode(rfc6238Secret, time.Unix(59, 0)); code != "287082" {
+		t.Fatalf("expected 6-digit code 287082, got %s", code)
+	}
+}
+
+func TestVerifyTOTPAllowsOneStepOfClockSkew(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_800_000_000, 0)
+	code, _ := TOTPCode(secret, now.Add(-totpPeriod*time.Second))
+
+	step, ok := VerifyTOTP(secret, code, now)
+	if !ok || step != totpStep(now)-1 {
+		t.Fatalf("previous step should be accepted, got %d %v", step, ok)
+	}
+	if _, ok := VerifyTOTP(secret, code, now.Add(2*totpPeriod*time.Second)); ok {
+		t.Fatal("codes older than the skew window must be rejected")
+	}
+	for _, bad := range []string{"", "12345", "abcdef"} {
+		if _, ok := VerifyTOTP(secret, bad, now); ok {
+			t.Fatalf("malformed code %q must be rejected", bad)
+		}
+	}
+	if _, ok := VerifyTOTP("not base32!", "123456", now); ok {
+		t.Fatal("invalid secret must be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("CruiseBooking", "alice", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/CruiseBooking:alice?") || !strings.Contains(uri, "secret=ABC") || !strings.Contains(uri, "issuer=CruiseBooking") {
+		t.Fatalf("unexpected provisioning uri %s", uri)
+	}
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DefaultAccountDeletionCoolingOff 为注销申请的默认冷静期。
+const DefaultAccountDeletionCoolingOff = 15 * 24 * time.Hour
+
+var (
+	// ErrProfileInvalid 表示个人资料参数非法。
+	ErrProfileInvalid = errors.New("invalid profile")
+	// ErrSMSVerificationFailed 表示敏感操作的短信验证码校验失败。
+	ErrSMSVerificationFailed = errors.New("sms verification failed")
+	// ErrAccountHasActiveOrders 表示用户仍有未结束的订单，暂不能注销。
+	ErrAccountHasActiveOrders = errors.New("account has active orders")
+	// ErrDeletionNotRequested 表示用户没有可撤销的注销申请。
+	ErrDeletionNotRequested = errors.New("account deletion not requested")
+)
+
+// SMSVerifier 定义敏感操作所需的短信验证码校验能力，由 UserAuthService 实现。
+type SMSVerifier interface {
+	VerifySMS(phone, code string) bool
+}
+
+// UserSelfServiceStore 定义用户自助管理（资料、换绑手机号、注销、数据导出）的持久化能力。
+type UserSelfServiceStore interface {
+	GetByID(ctx context.Context, id int64) (*domain.User, error)
+	UpdateProfile(ctx context.Context, id int64, nickname, avatarURL, email string) error
+	BindIdentity(ctx context.Context, userID int64, provider, identifier string) error
+	CountActiveBookings(ctx context.Context, userID int64) (int64, error)
+	ScheduleDeletion(ctx context.Context, id int64, requestedAt, scheduledAt time.Time) error
+	CancelDeletion(ctx context.Context, id int64) (bool, error)
+	ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]int64, error)
+	Anonymize(ctx context.Context, id int64, now time.Time) error
+	ExportData(ctx context.Context, id int64) (*domain.UserData, error)
+}
+
+// UserProfile 是返回给用户本人的个人资料。
+type UserProfile struct {
+	ID                  int64      `json:"id"`
+	Phone               string     `json:"phone"`
+	Nickname            string     `json:"nickname"`
+	AvatarURL           string     `json:"avatar_url"`
+	Email               string     `json:"email"`
+	WechatBound         bool       `json:"wechat_bound"`
+	AlipayBound         bool       `json:"alipay_bound"`
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"` // 注销申请时间，冷静期内返回
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"` // 冷静期结束、执行注销的时间
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// UserProfileUpdate 是用户可自助修改的资料字段。
+type UserProfileUpdate struct {
+	Nickname  string
+	AvatarURL string
+	Email     string
+}
+
+// UserDataExport 是个人信息导出文件的内容。
+type UserDataExport struct {
+	ExportedAt    time.Time           `json:"exported_at"`
+	Profile       UserProfile         `json:"profile"`
+	Orders        []ExportedOrder     `json:"orders"`
+	Passengers    []ExportedPassenger `json:"passengers"`
+	Notifications []ExportedNotice    `json:"notifications"`
+}
+
+// ExportedOrder 是导出的订单及其出行乘客。
+type ExportedOrder struct {
+	domain.Booking
+	PassengerIDs []int64 `json:"passenger_ids"`
+}
+
+// ExportedPassenger 是导出的出行乘客资料。
+type ExportedPassenger struct {
+	ID               int64      `json:"id"`
+	Name             string     `json:"name"`
+	EnglishName      string     `json:"english_name"`
+	IDType           string     `json:"id_type"`
+	IDNumber         string     `json:"id_number"`
+	Phone            string     `json:"phone"`
+	Email            string     `json:"email"`
+	EmergencyContact string     `json:"emergency_contact"`
+	EmergencyPhone   string     `json:"emergency_phone"`
+	SpecialNeeds     string     `json:"special_needs"`
+	Birthday         *time.Time `json:"birthday,omitempty"`
+	IsFavorite       bool       `json:"is_favorite"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ExportedNotice 是导出的通知记录。
+type ExportedNotice struct {
+	ID        int64     `json:"id"`
+	Channel   string    `json:"channel"`
+	Template  string    `json:"template"`
+	Payload   string    `json:"payload"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserAccountService 提供 C 端用户自助管理：修改资料、短信验证换绑手机号、带冷静期的注销与个人信息导出。
+type UserAccountService struct {
+	store      UserSelfServiceStore
+	sms        SMSVerifier
+	coolingOff time.Duration
+	now        func() time.Time
+}
+
+// NewUserAccountService 创建用户自助管理服务；coolingOff 非正数时使用 DefaultAccountDeletionCoolingOff。
+func NewUserAccountService(store UserSelfServiceStore, sms SMSVerifier, coolingOff time.Duration) *UserAccountService {
+	if coolingOff <= 0 {
+		coolingOff = DefaultAccountDeletionCoolingOff
+	}
+	return &UserAccountService{store: store, sms: sms, coolingOff: coolingOff, now: time.Now}
+}
+
+// Profile 返回当前用户的个人资料。
+func (s *UserAccountService) Profile(ctx context.Context, userID int64) (*UserProfile, error) {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toUserProfile(user), nil
+}
+
+// UpdateProfile 修改昵称、头像与邮箱；三项整体覆盖，空字符串表示清空。
+func (s *UserAccountService) UpdateProfile(ctx context.Context, userID int64, update UserProfileUpdate) (*UserProfile, error) {
+	update.Nickname = strings.TrimSpace(update.Nickname)
+	update.AvatarURL = strings.TrimSpace(update.AvatarURL)
+	update.Email = strings.TrimSpace(update.Email)
+	if err := validateProfileUpdate(update); err != nil {
+		return nil, err
+	}
+	if _, err := s.activeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateProfile(ctx, userID, update.Nickname, update.AvatarURL, update.Email); err != nil {
+		return nil, translateAccountError(err)
+	}
+	return s.Profile(ctx, userID)
+}
+
+func validateProfileUpdate(update UserProfileUpdate) error {
+	if utf8.RuneCountInString(update.Nickname) > 50 {
+		return fmt.Errorf("%w: nickname must be at most 50 characters", ErrProfileInvalid)
+	}
+	if update.AvatarURL != "" {
+		u, err := url.Parse(update.AvatarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(update.AvatarURL) > 500 {
+			return fmt.Errorf("%w: avatar_url must be an http(s) url of at most 500 characters", ErrProfileInvalid)
+		}
+	}
+	if update.Email != "" {
+		addr, err := mail.ParseAddress(update.Email)
+		if err != nil || addr.Address != update.Email || len(update.Email) > 100 {
+			return fmt.Errorf("%w: invalid email", ErrProfileInvalid)
+		}
+	}
+	return nil
+}
+
+// ChangePhone 换绑手机号：新手机号须通过短信验证；已绑定手机号的用户还须通过原手机号的短信验证。
+// 新手机号已属于其他用户时返回 ErrThirdPartyAlreadyBound。
+func (s *UserAccountService) ChangePhone(ctx context.Context, userID int64, phone, code, oldCode string) (*UserProfile, error) {
+	phone = strings.TrimSpace(phone)
+	if phone == "" || len(phone) > 20 || strings.TrimSpace(code) == "" {
+		return nil, ErrBindPayloadInvalid
+	}
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Phone == phone {
+		return toUserProfile(user), nil
+	}
+	if user.Phone != "" && !s.sms.VerifySMS(user.Phone, oldCode) {
+		return nil, fmt.Errorf("%w: current phone", ErrSMSVerificationFailed)
+	}
+	if !s.sms.VerifySMS(phone, code) {
+		return nil, fmt.Errorf("%w: new phone", ErrSMSVerificationFailed)
+	}
+	if err := s.store.BindIdentity(ctx, userID, domain.IdentityPhone, phone); err != nil {
+		return nil, translateBindError(err)
+	}
+	return s.Profile(ctx, userID)
+}
+
+// RequestDeletion 申请注销账号：仍有未结束订单时拒绝；已绑定手机号的用户须通过短信验证。
+// 申请后进入冷静期，期间可撤销，到期后由 AccountDeletionScheduler 执行匿名化；重复申请保留原冷静期。
+func (s *UserAccountService) RequestDeletion(ctx context.Context, userID int64, code string) (*UserProfile, error) {
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Phone != "" && !s.sms.VerifySMS(user.Phone, strings.TrimSpace(code)) {
+		return nil, ErrSMSVerificationFailed
+	}
+	active, err := s.store.CountActiveBookings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if active > 0 {
+		return nil, fmt.Errorf("%w: %d order(s) not finished", ErrAccountHasActiveOrders, active)
+	}
+	now := s.now()
+	if err := s.store.ScheduleDeletion(ctx, userID, now, now.Add(s.coolingOff)); err != nil {
+		return nil, err
+	}
+	return s.Profile(ctx, userID)
+}
+
+// CancelDeletion 在冷静期内撤销注销申请。
+func (s *UserAccountService) CancelDeletion(ctx context.Context, userID int64) (*UserProfile, error) {
+	if _, err := s.activeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	cancelled, err := s.store.CancelDeletion(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, ErrDeletionNotRequested
+	}
+	return s.Profile(ctx, userID)
+}
+
+// ProcessDueDeletions 对冷静期已结束的用户执行匿名化，返回本轮完成注销的用户数。
+// 冷静期内新下单且订单尚未结束的用户跳过，待订单结束后的下一轮再处理。
+func (s *UserAccountService) ProcessDueDeletions(ctx context.Context, limit int) (int, error) {
+	now := s.now()
+	ids, err := s.store.ListDueDeletions(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	done := 0
+	var errs []error
+	for _, id := range ids {
+		active, err := s.store.CountActiveBookings(ctx, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if active > 0 {
+			logger.FromContext(ctx).Info("account deletion: postponed, orders not finished", zap.Int64("user_id", id), zap.Int64("active_orders", active))
+			continue
+		}
+		if err := s.store.Anonymize(ctx, id, now); err != nil {
+			errs = append(errs, fmt.Errorf("anonymize user %d: %w", id, err))
+			continue
+		}
+		done++
+	}
+	return done, errors.Join(errs...)
+}
+
+// Export 导出用户本人的资料、订单、出行乘客与通知。
+func (s *UserAccountService) Export(ctx context.Context, userID int64) (*UserDataExport, error) {
+	if _, err := s.activeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	data, err := s.store.ExportData(ctx, userID)
+	if err != nil {
+		return nil, translateAccountError(err)
+	}
+	passengersByBooking := make(map[int64][]int64, len(data.Bookings))
+	for _, bp := range data.BookingPassengers {
+		passengersByBooking[bp.BookingID] = append(passengersByBooking[bp.BookingID], bp.PassengerID)
+	}
+	out := &UserDataExport{
+		ExportedAt:    s.now(),
+		Profile:       *toUserProfile(&data.User),
+		Orders:        make([]ExportedOrder, 0, len(data.Bookings)),
+		Passengers:    make([]ExportedPassenger, 0, len(data.Passengers)),
+		Notifications: make([]ExportedNotice, 0, len(data.Notifications)),
+	}
+	for _, b := range data.Bookings {
+		ids := passengersByBooking[b.ID]
+		if ids == nil {
+			ids = []int64{}
+		}
+		out.Orders = append(out.Orders, ExportedOrder{Booking: b, PassengerIDs: ids})
+	}
+	for _, p := range data.Passengers {
+		exported := ExportedPassenger{
+			ID:               p.ID,
+			Name:             p.Name,
+			EnglishName:      p.EnglishName,
+			IDType:           p.IDType,
+			IDNumber:         p.IDNumber,
+			Phone:            p.Phone,
+			Email:            p.Email,
+			EmergencyContact: p.EmergencyContact,
+			EmergencyPhone:   p.EmergencyPhone,
+			SpecialNeeds:     p.SpecialNeeds,
+			IsFavorite:       p.IsFavorite,
+			CreatedAt:        p.CreatedAt,
+		}
+		if !p.Birthday.IsZero() {
+			birthday := p.Birthday
+			exported.Birthday = &birthday
+		}
+		out.Passengers = append(out.Passengers, exported)
+	}
+	for _, n := range data.Notifications {
+		out.Notifications = append(out.Notifications, ExportedNotice{
+			ID:        n.ID,
+			Channel:   n.Channel,
+			Template:  n.Template,
+			Payload:   n.Payload,
+			Status:    n.Status,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (s *UserAccountService) activeUser(ctx context.Context, userID int64) (*domain.User, error) {
+	if userID <= 0 {
+		return nil, ErrUserNotFound
+	}
+	user, err := s.store.GetByID(ctx, userID)
+	if err != nil {
+		return nil, translateAccountError(err)
+	}
+	if user.Status == 0 || user.AnonymizedAt != nil {
+		return nil, ErrUserDisabled
+	}
+	return user, nil
+}
+
+func translateAccountError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+func toUserProfile(user *domain.User) *UserProfile {
+	return &UserProfile{
+		ID:                  user.ID,
+		Phone:               user.Phone,
+		Nickname:            user.Nickname,
+		AvatarURL:           user.AvatarURL,
+		Email:               user.Email,
+		WechatBound:         user.WxOpenID != "",
+		AlipayBound:         user.AlipayUID != "",
+		DeletionRequestedAt: user.DeletionRequestedAt,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+	}
+}
+
+// AccountDeletionScheduler 定期对冷静期已结束的注销申请执行匿名化。
+// RunOnce 返回本轮完成注销的用户数。
+type AccountDeletionScheduler struct {
+	*periodicJob
+}
+
+// NewAccountDeletionScheduler 创建注销执行调度器；interval 非正数时默认 1 分钟。
+func NewAccountDeletionScheduler(svc *UserAccountService, interval time.Duration) *AccountDeletionScheduler {
+	run := func(ctx context.Context) (int, error) {
+		return svc.ProcessDueDeletions(ctx, 100)
+	}
+	return &AccountDeletionScheduler{periodicJob: newPeriodicJob("account_deletion_scheduler: anonymize due accounts", interval, run)}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// fakeSelfServiceStore 在 fakeAccountStore 之上模拟资料、注销与导出相关的持久化。
+type fakeSelfServiceStore struct {
+	*fakeAccountStore
+	activeBookings map[int64]int64
+	anonymized     []int64
+	data           domain.UserData
+}
+
+func newFakeSelfServiceStore(ids ...int64) *fakeSelfServiceStore {
+	return &fakeSelfServiceStore{fakeAccountStore: newFakeAccountStore(ids...), activeBookings: map[int64]int64{}}
+}
+
+func (f *fakeSelfServiceStore) UpdateProfile(_ context.Context, id int64, nickname, avatarURL, email string) error {
+	user, ok := f.users[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	user.Nickname, user.AvatarURL, user.Email = nickname, avatarURL, email
+	return nil
+}
+
+func (f *fakeSelfServiceStore) CountActiveBookings(_ context.Context, userID int64) (int64, error) {
+	return f.activeBookings[userID], nil
+}
+
+func (f *fakeSelfServiceStore) ScheduleDeletion(_ context.Context, id int64, requestedAt, scheduledAt time.Time) error {
+	if user := f.users[id]; user.DeletionScheduledAt == nil {
+		user.DeletionRequestedAt, user.DeletionScheduledAt = &requestedAt, &scheduledAt
+	}
+	return nil
+}
+
+func (f *fakeSelfServiceStore) CancelDeletion(_ context.Context, id int64) (bool, error) {
+	user := f.users[id]
+	if user.DeletionScheduledAt == nil {
+		return false, nil
+	}
+	user.DeletionRequestedAt, user.DeletionScheduledAt = nil, nil
+	return true, nil
+}
+
+func (f *fakeSelfServiceStore) ListDueDeletions(_ context.Context, now time.Time, _ int) ([]int64, error) {
+	var ids []int64
+	for id, user := range f.users {
+		if user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(now) && user.AnonymizedAt == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeSelfServiceStore) Anonymize(_ context.Context, id int64, now time.Time) error {
+	user := f.users[id]
+	user.Phone, user.Status, user.AnonymizedAt, user.DeletionScheduledAt = "", 0, &now, nil
+	f.anonymized = append(f.anonymized, id)
+	return nil
+}
+
+func (f *fakeSelfServiceStore) ExportData(_ context.Context, id int64) (*domain.UserData, error) {
+	data := f.data
+	data.User = *f.users[id]
+	return &data, nil
+}
+
+// fakeSMSVerifier 按手机号返回预设的验证码。
+type fakeSMSVerifier map[string]string
+
+func (f fakeSMSVerifier) VerifySMS(phone, code string) bool {
+	return code != "" && f[phone] == code
+}
+
+func TestUserAccountUpdateProfile(t *testing.T) {
+	store := newFakeSelfServiceStore(1)
+	svc := NewUserAccountService(store, fakeSMSVerifier{}, 0)
+	ctx := context.Background()
+
+	profile, err := svc.UpdateProfile(ctx, 1, UserProfileUpdate{Nickname: " 海风 ", AvatarURL: "https://cdn.example.com/a.png", Email: "a@example.com"})
+	if err != nil || profile.Nickname != "海风" || profile.Email != "a@example.com" {
+		t.Fatalf("unexpected profile %+v (%v)", profile, err)
+	}
+	invalid := []UserProfileUpdate{
+		{Email: "not-an-email"},
+		{Email: "Alice <a@example.com>"},
+		{AvatarURL: "javascript:alert(1)"},
+		{Nickname: string(make([]rune, 51))},
+	}
+	for i, update := range invalid {
+		if _, err := svc.UpdateProfile(ctx, 1, update); !errors.Is(err, ErrProfileInvalid) {
+			t.Fatalf("case %d: expected ErrProfileInvalid, got %v", i, err)
+		}
+	}
+	if _, err := svc.UpdateProfile(ctx, 404, UserProfileUpdate{}); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserAccountChangePhoneRequiresBothCodes(t *testing.T) {
+	store := newFakeSelfServiceStore(1, 2)
+	store.users[1].Phone = "13800000001"
+	store.users[2].Phone = "13800000002"
+	sms := fakeSMSVerifier{"13800000001": "111111", "13900000009": "999999", "13800000002": "222222"}
+	svc := NewUserAccountService(store, sms, 0)
+	ctx := context.Background()
+
+	if _, err := svc.ChangePhone(ctx, 1, "13900000009", "999999", "bad"); !errors.Is(err, ErrSMSVerificationFailed) {
+		t.Fatalf("old phone must be re-verified, got %v", err)
+	}
+	if _, err := svc.ChangePhone(ctx, 1, "13900000009", "bad", "111111"); !errors.Is(err, ErrSMSVerificationFailed) {
+		t.Fatalf("new phone must be verified, got %v", err)
+	}
+	if _, err := svc.ChangePhone(ctx, 1, "13800000002", "222222", "111111"); !errors.Is(err, ErrThirdPartyAlreadyBound) {
+		t.Fatalf("expected ErrThirdPartyAlreadyBound, got %v", err)
+	}
+	profile, err := svc.ChangePhone(ctx, 1, "13900000009", "999999", "111111")
+	if err != nil || profile.Phone != "13900000009" {
+		t.Fatalf("unexpected profile %+v (%v)", profile, err)
+	}
+	if _, err := svc.ChangePhone(ctx, 1, "", "999999", ""); !errors.Is(err, ErrBindPayloadInvalid) {
+		t.Fatalf("expected ErrBindPayloadInvalid, got %v", err)
+	}
+}
+
+func TestUserAccountDeletionLifecycle(t *testing.T) {
+	store := newFakeSelfServiceStore(1)
+	store.users[1].Phone = "13800000001"
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	svc := NewUserAccountService(store, fakeSMSVerifier{"13800000001": "111111"}, 7*24*time.Hour)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := svc.CancelDeletion(ctx, 1); !errors.Is(err, ErrDeletionNotRequested) {
+		t.Fatalf("expected ErrDeletionNotRequested, got %v", err)
+	}
+	if _, err := svc.RequestDeletion(ctx, 1, "bad"); !errors.Is(err, ErrSMSVerificationFailed) {
+		t.Fatalf("expected ErrSMSVerificationFailed, got %v", err)
+	}
+	store.activeBookings[1] = 1
+	if _, err := svc.RequestDeletion(ctx, 1, "111111"); !errors.Is(err, ErrAccountHasActiveOrders) {
+		t.Fatalf("expected ErrAccountHasActiveOrders, got %v", err)
+	}
+	store.activeBookings[1] = 0
+	profile, err := svc.RequestDeletion(ctx, 1, "111111")
+	if err != nil || profile.DeletionScheduledAt == nil || !profile.DeletionScheduledAt.Equal(now.Add(7*24*time.Hour)) {
+		t.Fatalf("unexpected deletion schedule %+v (%v)", profile, err)
+	}
+	if _, err := svc.CancelDeletion(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RequestDeletion(ctx, 1, "111111"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := svc.ProcessDueDeletions(ctx, 10); err != nil || n != 0 {
+		t.Fatalf("nothing is due during the cooling-off period, got %d (%v)", n, err)
+	}
+	now = now.Add(7 * 24 * time.Hour)
+	store.activeBookings[1] = 1
+	if n, _ := svc.ProcessDueDeletions(ctx, 10); n != 0 {
+		t.Fatal("accounts with unfinished orders should be postponed")
+	}
+	store.activeBookings[1] = 0
+	if n := NewAccountDeletionScheduler(svc, time.Minute).RunOnce(ctx); n != 1 || len(store.anonymized) != 1 {
+		t.Fatalf("expected the account to be anonymized, got %d", n)
+	}
+	if _, err := svc.Profile(ctx, 1); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("anonymized account should be unusable, got %v", err)
+	}
+}
+
+func TestUserAccountExport(t *testing.T) {
+	store := newFakeSelfServiceStore(1)
+	store.users[1].Phone = "13800000001"
+	store.data = domain.UserData{
+		Bookings:          []domain.Booking{{ID: 10, UserID: 1, TotalCents: 5000}, {ID: 11, UserID: 1}},
+		BookingPassengers: []domain.BookingPassenger{{BookingID: 10, PassengerID: 3}},
+		Passengers:        []domain.Passenger{{ID: 3, Name: "张三", IDNumber: "110101199001011234"}},
+		Notifications:     []domain.Notification{{ID: 5, Channel: "sms", Status: "sent"}},
+	}
+	svc := NewUserAccountService(store, fakeSMSVerifier{}, 0)
+
+	export, err := svc.Export(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if export.Profile.Phone != "13800000001" || len(export.Orders) != 2 || len(export.Passengers) != 1 || len(export.Notifications) != 1 {
+		t.Fatalf("unexpected export %+v", export)
+	}
+	if ids := export.Orders[0].PassengerIDs; len(ids) != 1 || ids[0] != 3 {
+		t.Fatalf("expected order passengers, got %v", ids)
+	}
+	if export.Orders[1].PassengerIDs == nil || export.Passengers[0].Birthday != nil {
+		t.Fatalf("empty values should be exported as [] and omitted birthday, got %+v", export)
+	}
+}
@@ -1,13 +1,18 @@
 package service
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 var (
@@ -29,6 +34,14 @@ var (
 	ErrThirdPartyAlreadyBound = errors.New("third-party account already bound")
 	// ErrBindPayloadInvalid 表示绑定参数非法。
 	ErrBindPayloadInvalid = errors.New("invalid bind payload")
+	// ErrAccountAlreadyBound 表示用户已绑定同一渠道的其他账号。
+	ErrAccountAlreadyBound = errors.New("account already bound to another identity of this provider")
+	// ErrAccountStoreUnavailable 表示用户账号存储未就绪。
+	ErrAccountStoreUnavailable = errors.New("account store unavailable")
+	// ErrUserNotFound 表示用户不存在。
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserDisabled 表示用户已被停用，不能登录或绑定账号。
+	ErrUserDisabled = errors.New("user disabled")
 )
 
 const defaultBindConfirmWindow = 5 * time.Minute
@@ -39,6 +52,13 @@ type CodeStore interface {
 	Verify(phone, code string) bool
 }
 
+// UserAccountStore 定义用户登录标识与账号绑定的持久化能力。
+type UserAccountStore interface {
+	GetByID(ctx context.Context, id int64) (*domain.User, error)
+	FindOrCreateByWechat(ctx context.Context, openID, unionID, sessionKey string) (*domain.User, error)
+	BindIdentity(ctx context.Context, userID int64, provider, identifier string) error
+}
+
 // UserAuthPolicy 定义短信验证码认证策略参数。
 type UserAuthPolicy struct {
 	CodeTTL          time.Duration
@@ -47,6 +67,9 @@ type UserAuthPolicy struct {
 	LockDuration     time.Duration
 	Now              func() time.Time
 	AlipaySignSecret string
+	Accounts         UserAccountStore      // 用户账号存储，微信登录与账号绑定依赖它
+	Wechat           WechatAuthClient      // 微信接口客户端，未配置时微信登录不可用
+	States           domain.AuthStateStore // 重发间隔、失败次数、锁定与绑定窗口的共享存储，未设置时使用进程内存储
 }
 
 // UserAuthService 提供短信验证码发送、校验与风控能力。
@@ -59,15 +82,11 @@ type UserAuthService struct {
 	maxAttempts    int
 	lockDuration   time.Duration
 
-	mu                     sync.Mutex
-	lastSentAt             map[string]time.Time
-	expiresAt              map[string]time.Time
-	failedAttempts         map[string]int
-	lockedUntil            map[string]time.Time
-	alipaySignSecret       string
-	bindConfirmWindow      time.Duration
-	bindingAuthorizedUntil map[int64]time.Time
-	boundAccounts          map[string]int64
+	states            domain.AuthStateStore
+	alipaySignSecret  string
+	bindConfirmWindow time.Duration
+	accounts          UserAccountStore
+	wechat            WechatAuthClient
 }
 
 // NewUserAuthService 使用默认策略创建用户认证服务。
@@ -96,6 +115,9 @@ func NewUserAuthServiceWithPolicy(store CodeStore, policy UserAuthPolicy) *UserA
 	if policy.Now == nil {
 		policy.Now = time.Now
 	}
+	if policy.States == nil {
+		policy.States = NewInMemoryAuthStateStore()
+	}
 
 	alipaySecret := policy.AlipaySignSecret
 	if alipaySecret == "" {
@@ -103,23 +125,27 @@ func NewUserAuthServiceWithPolicy(store CodeStore, policy UserAuthPolicy) *UserA
 	}
 
 	return &UserAuthService{
-		store:                  store,
-		now:                    policy.Now,
-		codeTTL:                policy.CodeTTL,
-		resendInterval:         policy.ResendInterval,
-		maxAttempts:            policy.MaxAttempts,
-		lockDuration:           policy.LockDuration,
-		lastSentAt:             make(map[string]time.Time),
-		expiresAt:              make(map[string]time.Time),
-		failedAttempts:         make(map[string]int),
-		lockedUntil:            make(map[string]time.Time),
-		alipaySignSecret:       alipaySecret,
-		bindConfirmWindow:      defaultBindConfirmWindow,
-		bindingAuthorizedUntil: make(map[int64]time.Time),
-		boundAccounts:          make(map[string]int64),
+		store:             store,
+		now:               policy.Now,
+		codeTTL:           policy.CodeTTL,
+		resendInterval:    policy.ResendInterval,
+		maxAttempts:       policy.MaxAttempts,
+		lockDuration:      policy.LockDuration,
+		states:            policy.States,
+		alipaySignSecret:  alipaySecret,
+		bindConfirmWindow: defaultBindConfirmWindow,
+		accounts:          policy.Accounts,
+		wechat:            policy.Wechat,
 	}
 }
 
+// 风控状态键：重发间隔、验证码有效期、连续失败次数、锁定与绑定授权窗口。
+func smsSentKey(phone string) string     { return "sms:sent:" + phone }
+func smsCodeKey(phone string) string     { return "sms:code:" + phone }
+func smsFailKey(phone string) string     { return "sms:fail:" + phone }
+func smsLockKey(phone string) string     { return "sms:lock:" + phone }
+func bindingAuthKey(userID int64) string { return "bind:auth:" + strconv.FormatInt(userID, 10) }
+
 // SendSMS 发送验证码并记录发送频率与过期时间。
 //
 //go:noinline
@@ -131,27 +157,26 @@ func (s *UserAuthService) SendSMS(phone, code string) error {
 		return ErrPhoneOrCodeRequired
 	}
 
+	ctx := context.Background()
 	now := s.now()
-	s.mu.Lock()
-	lastSentAt := s.lastSentAt[phone]
-	if !lastSentAt.IsZero() && now.Sub(lastSentAt) < s.resendInterval {
-		s.mu.Unlock()
+	reserved, err := s.states.SetNX(ctx, smsSentKey(phone), s.resendInterval, now)
+	if err != nil {
+		return err
+	}
+	if !reserved {
 		return ErrSMSTooFrequent
 	}
-	s.mu.Unlock()
 
 	if err := s.store.Save(phone, code); err != nil {
+		// 发送失败不占用重发间隔
+		_ = s.states.Delete(ctx, smsSentKey(phone))
 		return err
 	}
 
-	s.mu.Lock()
-	s.lastSentAt[phone] = now
-	s.expiresAt[phone] = now.Add(s.codeTTL)
-	s.failedAttempts[phone] = 0
-	delete(s.lockedUntil, phone)
-	s.mu.Unlock()
-
-	return nil
+	if err := s.states.Set(ctx, smsCodeKey(phone), s.codeTTL, now); err != nil {
+		return err
+	}
+	return s.states.Delete(ctx, smsFailKey(phone), smsLockKey(phone))
 }
 
 // VerifySMS 校验验证码并根据失败次数执行锁定策略。
@@ -162,42 +187,124 @@ func (s *UserAuthService) VerifySMS(phone, code string) bool {
 		return false
 	}
 
+	ctx := context.Background()
 	now := s.now()
-	s.mu.Lock()
-	if until := s.lockedUntil[phone]; !until.IsZero() && now.Before(until) {
-		s.mu.Unlock()
+	locked, err := s.states.Exists(ctx, smsLockKey(phone), now)
+	if err != nil || locked {
+		logAuthStateError("check sms lock", err)
 		return false
 	}
-	if expireAt := s.expiresAt[phone]; expireAt.IsZero() || now.After(expireAt) {
-		s.mu.Unlock()
+	valid, err := s.states.Exists(ctx, smsCodeKey(phone), now)
+	if err != nil || !valid {
+		logAuthStateError("check sms code", err)
 		return false
 	}
-	s.mu.Unlock()
 
 	if s.store.Verify(phone, code) {
-		s.mu.Lock()
-		s.failedAttempts[phone] = 0
-		delete(s.lockedUntil, phone)
-		s.mu.Unlock()
+		logAuthStateError("reset sms failures", s.states.Delete(ctx, smsFailKey(phone), smsLockKey(phone)))
 		return true
 	}
 
-	s.mu.Lock()
-	s.failedAttempts[phone]++
-	if s.failedAttempts[phone] >= s.maxAttempts {
-		s.failedAttempts[phone] = 0
-		s.lockedUntil[phone] = now.Add(s.lockDuration)
+	failures, err := s.states.Incr(ctx, smsFailKey(phone), s.codeTTL, now)
+	if err != nil {
+		logAuthStateError("count sms failure", err)
+		return false
+	}
+	if failures >= int64(s.maxAttempts) {
+		logAuthStateError("lock sms verification", s.states.Set(ctx, smsLockKey(phone), s.lockDuration, now))
+		logAuthStateError("reset sms failures", s.states.Delete(ctx, smsFailKey(phone)))
 	}
-	s.mu.Unlock()
 
 	return false
 }
 
-// WechatLogin 处理微信登录流程并返回用户标识。
-//
-//go:noinline
-func (s *UserAuthService) WechatLogin(openID string) string {
-	return openID
+// logAuthStateError 记录风控状态存储故障；校验路径上存储不可用时按校验失败处理。
+func logAuthStateError(action string, err error) {
+	if err != nil {
+		zap.L().Warn("user auth: "+action+" failed", zap.Error(err))
+	}
+}
+
+// WechatLogin 用 wx.login 返回的 code 调用 code2session 换取 OpenID，按 OpenID 查找或创建用户，
+// 并保存 UnionID 与会话密钥（供后续解密手机号授权数据）。OpenID 只信任微信接口的返回。
+func (s *UserAuthService) WechatLogin(ctx context.Context, code string) (*domain.User, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, ErrWechatCodeInvalid
+	}
+	if s.wechat == nil || s.accounts == nil {
+		return nil, ErrWechatNotConfigured
+	}
+	session, err := s.wechat.Code2Session(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.accounts.FindOrCreateByWechat(ctx, session.OpenID, session.UnionID, session.SessionKey)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == 0 {
+		return nil, ErrUserDisabled
+	}
+	return user, nil
+}
+
+// BindWechatPhone 解密小程序手机号授权数据（getPhoneNumber 的 encryptedData/iv）并绑定到当前用户。
+// code 非空时先重新调用 code2session 刷新会话密钥，且要求会话属于当前用户；手机号已属于其他用户时返回 ErrThirdPartyAlreadyBound。
+func (s *UserAuthService) BindWechatPhone(ctx context.Context, userID int64, code, encryptedData, iv string) (*domain.User, error) {
+	if userID <= 0 || strings.TrimSpace(encryptedData) == "" || strings.TrimSpace(iv) == "" {
+		return nil, ErrBindPayloadInvalid
+	}
+	if s.wechat == nil || s.accounts == nil {
+		return nil, ErrWechatNotConfigured
+	}
+	user, err := s.activeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sessionKey := user.WxSessionKey
+	if code = strings.TrimSpace(code); code != "" {
+		session, err := s.wechat.Code2Session(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if session.OpenID != user.WxOpenID {
+			return nil, ErrWechatCodeInvalid
+		}
+		if _, err := s.accounts.FindOrCreateByWechat(ctx, session.OpenID, session.UnionID, session.SessionKey); err != nil {
+			return nil, err
+		}
+		sessionKey = session.SessionKey
+	}
+	if user.WxOpenID == "" || sessionKey == "" {
+		return nil, ErrWechatCodeInvalid
+	}
+	phone, err := s.wechat.DecryptPhoneNumber(sessionKey, encryptedData, iv)
+	if err != nil {
+		return nil, err
+	}
+	number := phone.PurePhoneNumber
+	if phone.CountryCode != "" && phone.CountryCode != "86" {
+		number = "+" + phone.CountryCode + number
+	}
+	if err := s.accounts.BindIdentity(ctx, userID, domain.IdentityPhone, number); err != nil {
+		return nil, translateBindError(err)
+	}
+	return s.accounts.GetByID(ctx, userID)
+}
+
+func (s *UserAuthService) activeUser(ctx context.Context, userID int64) (*domain.User, error) {
+	user, err := s.accounts.GetByID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == 0 {
+		return nil, ErrUserDisabled
+	}
+	return user, nil
 }
 
 // AlipayLogin 校验支付宝回调签名并返回已验签 UID。
@@ -230,28 +337,60 @@ func (s *UserAuthService) signAlipayUID(uid string) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
-// BindAccount 绑定第三方账号（微信/支付宝/手机号）。
-func (s *UserAuthService) BindAccount(userID int64, provider string, identifier string) error {
+// BindAccount 在绑定窗口内把第三方账号（微信/支付宝/手机号）写入用户记录。
+// provider 为 wechat 时 identifier 必须是 wx.login 返回的 code，OpenID 只取自 code2session，不信任调用方传入的值。
+// 标识已属于其他用户时返回 ErrThirdPartyAlreadyBound，用户已绑定同渠道其他账号时返回 ErrAccountAlreadyBound。
+func (s *UserAuthService) BindAccount(ctx context.Context, userID int64, provider string, identifier string) error {
 	provider = strings.TrimSpace(strings.ToLower(provider))
 	identifier = strings.TrimSpace(identifier)
-	if userID <= 0 || provider == "" || identifier == "" {
+	if userID <= 0 || identifier == "" {
 		return ErrBindPayloadInvalid
 	}
+	switch provider {
+	case domain.IdentityPhone, domain.IdentityWechat, domain.IdentityAlipay:
+	default:
+		return ErrBindPayloadInvalid
+	}
+	if s.accounts == nil {
+		return ErrAccountStoreUnavailable
+	}
 
-	now := s.now()
-	accountKey := provider + ":" + identifier
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if until := s.bindingAuthorizedUntil[userID]; until.IsZero() || now.After(until) {
+	authorized, err := s.states.Exists(ctx, bindingAuthKey(userID), s.now())
+	if err != nil {
+		return err
+	}
+	if !authorized {
 		return ErrBindingConfirmationRequired
 	}
-	if boundUserID, exists := s.boundAccounts[accountKey]; exists && boundUserID != userID {
+	if _, err := s.activeUser(ctx, userID); err != nil {
+		return err
+	}
+	if provider == domain.IdentityWechat {
+		if s.wechat == nil {
+			return ErrWechatNotConfigured
+		}
+		session, err := s.wechat.Code2Session(ctx, identifier)
+		if err != nil {
+			return err
+		}
+		identifier = session.OpenID
+	}
+	if err := s.accounts.BindIdentity(ctx, userID, provider, identifier); err != nil {
+		return translateBindError(err)
+	}
+	return s.states.Delete(ctx, bindingAuthKey(userID))
+}
+
+func translateBindError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrIdentityTaken):
 		return ErrThirdPartyAlreadyBound
+	case errors.Is(err, domain.ErrIdentityConflict):
+		return ErrAccountAlreadyBound
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrUserNotFound
 	}
-	s.boundAccounts[accountKey] = userID
-	delete(s.bindingAuthorizedUntil, userID)
-	return nil
+	return err
 }
 
 // AuthorizeBinding 通过短信验证码完成绑定前身份确认，验证通过后开启短时绑定窗口。
@@ -263,8 +402,5 @@ func (s *UserAuthService) AuthorizeBinding(userID int64, phone string, code stri
 	if !s.VerifySMS(phone, code) {
 		return ErrBindingConfirmationRequired
 	}
-	s.mu.Lock()
-	s.bindingAuthorizedUntil[userID] = s.now().Add(s.bindConfirmWindow)
-	s.mu.Unlock()
-	return nil
+	return s.states.Set(context.Background(), bindingAuthKey(userID), s.bindConfirmWindow, s.now())
 }
@@ -1,6 +1,8 @@
 package service_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -29,7 +31,8 @@ func TestUserAuthServiceBlackbox(t *testing.T) {
 	if !svc.VerifySMS("13800000000", "1234") {
 		t.Fatal("VerifySMS should be true")
 	}
-	if got := svc.WechatLogin("wx-open-id"); got != "wx-open-id" {
-		t.Fatalf("WechatLogin mismatch: %s", got)
+	// 未配置 code2session 时不能再把客户端上送的 OpenID 当作登录身份
+	if _, err := svc.WechatLogin(context.Background(), "wx-open-id"); !errors.Is(err, service.ErrWechatNotConfigured) {
+		t.Fatalf("WechatLogin should require code2session, got %v", err)
 	}
 }
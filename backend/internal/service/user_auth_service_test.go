@@ -1,8 +1,13 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
 )
 
 type fakeCodeStore struct {
@@ -16,6 +21,68 @@ func (f *fakeCodeStore) Save(phone, code string) error {
 }
 func (f *fakeCodeStore) Verify(phone, code string) bool { return f.ok }
 
+// fakeAccountStore 在内存中模拟 users 表的登录标识唯一约束。
+type fakeAccountStore struct {
+	users map[int64]*domain.User
+}
+
+func newFakeAccountStore(ids ...int64) *fakeAccountStore {
+	store := &fakeAccountStore{users: map[int64]*domain.User{}}
+	for _, id := range ids {
+		store.users[id] = &domain.User{ID: id, Status: 1}
+	}
+	return store
+}
+
+func (f *fakeAccountStore) GetByID(_ context.Context, id int64) (*domain.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeAccountStore) FindOrCreateByWechat(_ context.Context, openID, unionID, sessionKey string) (*domain.User, error) {
+	for _, user := range f.users {
+		if user.WxOpenID == openID {
+			user.WxUnionID, user.WxSessionKey = unionID, sessionKey
+			copied := *user
+			return &copied, nil
+		}
+	}
+	user := &domain.User{ID: int64(len(f.users) + 100), WxOpenID: openID, WxUnionID: unionID, WxSessionKey: sessionKey, Status: 1}
+	f.users[user.ID] = user
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeAccountStore) BindIdentity(_ context.Context, userID int64, provider, identifier string) error {
+	field := func(u *domain.User) *string {
+		switch provider {
+		case domain.IdentityPhone:
+			return &u.Phone
+		case domain.IdentityWechat:
+			return &u.WxOpenID
+		}
+		return &u.AlipayUID
+	}
+	user, ok := f.users[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for id, other := range f.users {
+		if id != userID && *field(other) == identifier {
+			return domain.ErrIdentityTaken
+		}
+	}
+	if current := *field(user); current != "" && current != identifier && provider != domain.IdentityPhone {
+		return domain.ErrIdentityConflict
+	}
+	*field(user) = identifier
+	return nil
+}
+
 func TestUserAuthVerifySMS(t *testing.T) {
 	now := time.Now()
 	svc := NewUserAuthServiceWithPolicy(&fakeCodeStore{ok: true}, UserAuthPolicy{
@@ -124,6 +191,7 @@ func TestUserAuthBindAccount(t *testing.T) {
 		MaxAttempts:    5,
 		LockDuration:   time.Minute,
 		Now:            func() time.Time { return now },
+		Accounts:       newFakeAccountStore(1),
 	})
 	// 先发 SMS 验证码
 	if err := svc.SendSMS("13800000000", "1234"); err != nil {
@@ -132,16 +200,16 @@ func TestUserAuthBindAccount(t *testing.T) {
 	if err := svc.AuthorizeBinding(1, "13800000000", "1234"); err != nil {
 		t.Fatalf("expected authorize success: %v", err)
 	}
-	err := svc.BindAccount(1, "alipay", "alipay_uid_001")
+	err := svc.BindAccount(context.Background(), 1, "alipay", "alipay_uid_001")
 	if err != nil {
 		t.Fatal("expected bind success")
 	}
 }
 
 func TestUserAuthBindAccountRequiresConfirmation(t *testing.T) {
-	svc := NewUserAuthService(&fakeCodeStore{ok: true})
+	svc := NewUserAuthServiceWithPolicy(&fakeCodeStore{ok: true}, UserAuthPolicy{Accounts: newFakeAccountStore(1)})
 
-	err := svc.BindAccount(1, "alipay", "alipay_uid_001")
+	err := svc.BindAccount(context.Background(), 1, "alipay", "alipay_uid_001")
 	if err == nil {
 		t.Fatal("expected bind to fail without confirmation")
 	}
@@ -156,6 +224,7 @@ func TestUserAuthBindAccountRejectsDuplicateIdentifier(t *testing.T) {
 		MaxAttempts:    5,
 		LockDuration:   time.Minute,
 		Now:            func() time.Time { return now },
+		Accounts:       newFakeAccountStore(1, 2),
 	})
 
 	if err := svc.SendSMS("13800000001", "0001"); err != nil {
@@ -164,7 +233,7 @@ func TestUserAuthBindAccountRejectsDuplicateIdentifier(t *testing.T) {
 	if err := svc.AuthorizeBinding(1, "13800000001", "0001"); err != nil {
 		t.Fatalf("authorize user1 failed: %v", err)
 	}
-	if err := svc.BindAccount(1, "alipay", "alipay_uid_001"); err != nil {
+	if err := svc.BindAccount(context.Background(), 1, "alipay", "alipay_uid_001"); err != nil {
 		t.Fatalf("user1 bind failed: %v", err)
 	}
 
@@ -174,8 +243,11 @@ func TestUserAuthBindAccountRejectsDuplicateIdentifier(t *testing.T) {
 	if err := svc.AuthorizeBinding(2, "13800000002", "0002"); err != nil {
 		t.Fatalf("authorize user2 failed: %v", err)
 	}
-	err := svc.BindAccount(2, "alipay", "alipay_uid_001")
-	if err == nil {
-		t.Fatal("expected duplicate binding to be rejected")
+	err := svc.BindAccount(context.Background(), 2, "alipay", "alipay_uid_001")
+	if !errors.Is(err, ErrThirdPartyAlreadyBound) {
+		t.Fatalf("expected duplicate binding to be rejected, got %v", err)
+	}
+	if err := svc.BindAccount(context.Background(), 2, "alipay", "alipay_uid_002"); err != nil {
+		t.Fatalf("failed binding must keep the confirmation window open: %v", err)
 	}
 }
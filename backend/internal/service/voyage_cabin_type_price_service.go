@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
@@ -11,6 +12,15 @@ import (
 
 var shanghaiLocation = mustLoadLocation("Asia/Shanghai")
 
+var (
+	// ErrPriceVersionNotFound 表示价格版本不存在。
+	ErrPriceVersionNotFound = errors.New("price version not found")
+	// ErrPriceVersionNotPending 表示价格版本已生效或已撤销，不能撤销。
+	ErrPriceVersionNotPending = errors.New("price version is not pending")
+	// ErrPriceVersionNotRollbackable 表示目标版本尚未生效或已撤销，不能作为回滚来源。
+	ErrPriceVersionNotRollbackable = errors.New("price version cannot be rolled back to")
+)
+
 func mustLoadLocation(name string) *time.Location {
 	loc, err := time.LoadLocation(name)
 	if err != nil {
@@ -22,10 +32,11 @@ func mustLoadLocation(name string) *time.Location {
 // VoyageCabinTypePriceService 提供航次舱型价格版本与当前态管理能力。
 type VoyageCabinTypePriceService struct {
 	repo domain.VoyageCabinTypePriceRepository
+	now  func() time.Time
 }
 
 func NewVoyageCabinTypePriceService(repo domain.VoyageCabinTypePriceRepository) *VoyageCabinTypePriceService {
-	return &VoyageCabinTypePriceService{repo: repo}
+	return &VoyageCabinTypePriceService{repo: repo, now: time.Now}
 }
 
 func (s *VoyageCabinTypePriceService) CreateVersion(ctx context.Context, version *domain.VoyageCabinTypePriceVersion) error {
@@ -66,22 +77,26 @@ func (s *VoyageCabinTypePriceService) ApplyVersionAndSetCurrent(ctx context.Cont
 // ApplyVersionAndRefreshCurrent writes history and recalculates current by effective_at <= now(Asia/Shanghai).
 func (s *VoyageCabinTypePriceService) ApplyVersionAndRefreshCurrent(ctx context.Context, version *domain.VoyageCabinTypePriceVersion) error {
 	if version.EffectiveAt.IsZero() {
-		version.EffectiveAt = time.Now().In(shanghaiLocation)
+		version.EffectiveAt = s.now().In(shanghaiLocation)
 	}
 	if err := s.repo.CreateVersion(ctx, version); err != nil {
 		return err
 	}
+	_, err := s.refreshCurrent(ctx, version.VoyageID, version.CabinTypeID, s.now().In(shanghaiLocation))
+	return err
+}
 
-	now := time.Now().In(shanghaiLocation)
-	latest, err := s.repo.GetLatestVersionAt(ctx, version.VoyageID, version.CabinTypeID, now)
+// refreshCurrent 把 now 时点最新的有效版本写入当前态；没有任何有效版本时返回 false。
+func (s *VoyageCabinTypePriceService) refreshCurrent(ctx context.Context, voyageID, cabinTypeID int64, now time.Time) (bool, error) {
+	latest, err := s.repo.GetLatestVersionAt(ctx, voyageID, cabinTypeID, now)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if latest == nil {
-		return nil
+		return false, nil
 	}
 
-	return s.repo.UpsertCurrent(ctx, &domain.VoyageCabinTypeCurrent{
+	return true, s.repo.UpsertCurrent(ctx, &domain.VoyageCabinTypeCurrent{
 		VoyageID:             latest.VoyageID,
 		CabinTypeID:          latest.CabinTypeID,
 		InventoryTotal:       latest.InventoryTotal,
@@ -93,8 +108,96 @@ func (s *VoyageCabinTypePriceService) ApplyVersionAndRefreshCurrent(ctx context.
 	})
 }
 
+// PromoteDueVersions 将已到生效时间的未来版本提升为当前价，返回刷新的航次舱型数量。
+// 由 PriceVersionScheduler 定期调用；单个航次舱型失败不影响其余组合。
+func (s *VoyageCabinTypePriceService) PromoteDueVersions(ctx context.Context) (int, error) {
+	now := s.now().In(shanghaiLocation)
+	keys, err := s.repo.ListStaleCurrentKeys(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	promoted := 0
+	var errs []error
+	for _, key := range keys {
+		ok, err := s.refreshCurrent(ctx, key.VoyageID, key.CabinTypeID, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("voyage %d cabin type %d: %w", key.VoyageID, key.CabinTypeID, err))
+			continue
+		}
+		if ok {
+			promoted++
+		}
+	}
+	return promoted, errors.Join(errs...)
+}
+
+// ListPendingVersions 查询尚未生效且未撤销的未来版本，按生效时间升序。
+func (s *VoyageCabinTypePriceService) ListPendingVersions(ctx context.Context, voyageID, cabinTypeID int64, page, pageSize int) ([]domain.VoyageCabinTypePriceVersion, int64, error) {
+	return s.repo.ListPendingVersions(ctx, voyageID, cabinTypeID, s.now().In(shanghaiLocation), page, pageSize)
+}
+
+// CancelPendingVersion 撤销尚未生效的未来版本；已生效或已撤销的版本返回 ErrPriceVersionNotPending。
+func (s *VoyageCabinTypePriceService) CancelPendingVersion(ctx context.Context, id, staffID int64) (*domain.VoyageCabinTypePriceVersion, error) {
+	now := s.now().In(shanghaiLocation)
+	if err := s.repo.CancelVersion(ctx, id, staffID, now); err != nil {
+		if errors.Is(err, domain.ErrPriceVersionNotPending) {
+			if _, getErr := s.getVersion(ctx, id); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrPriceVersionNotPending
+		}
+		return nil, err
+	}
+	version, err := s.getVersion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// 撤销与调度器提升存在竞态时，重新计算一次当前态，确保不会停留在已撤销版本上。
+	if _, err := s.refreshCurrent(ctx, version.VoyageID, version.CabinTypeID, now); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// RollbackToVersion 一键回滚：复制一个已生效过的历史版本为新版本并立即生效，原历史记录保持不变。
+func (s *VoyageCabinTypePriceService) RollbackToVersion(ctx context.Context, id, staffID int64) (*domain.VoyageCabinTypePriceVersion, error) {
+	source, err := s.getVersion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().In(shanghaiLocation)
+	if source.CancelledAt != nil || source.EffectiveAt.After(now) {
+		return nil, ErrPriceVersionNotRollbackable
+	}
+	sourceID := source.ID
+	version := &domain.VoyageCabinTypePriceVersion{
+		VoyageID:             source.VoyageID,
+		CabinTypeID:          source.CabinTypeID,
+		InventoryTotal:       source.InventoryTotal,
+		SettlementPriceCents: source.SettlementPriceCents,
+		SalePriceCents:       source.SalePriceCents,
+		EffectiveAt:          now,
+		SourceVersionID:      &sourceID,
+	}
+	if staffID > 0 {
+		version.CreatedBy = &staffID
+	}
+	if err := s.ApplyVersionAndRefreshCurrent(ctx, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func (s *VoyageCabinTypePriceService) getVersion(ctx context.Context, id int64) (*domain.VoyageCabinTypePriceVersion, error) {
+	version, err := s.repo.GetVersion(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPriceVersionNotFound
+	}
+	return version, err
+}
+
 func (s *VoyageCabinTypePriceService) GetCurrent(ctx context.Context, voyageID, cabinTypeID int64) (*domain.VoyageCabinTypeCurrent, error) {
-	now := time.Now().In(shanghaiLocation)
+	now := s.now().In(shanghaiLocation)
 	current, err := s.repo.GetCurrentAt(ctx, voyageID, cabinTypeID, now)
 	if err == nil {
 		return current, nil
@@ -106,7 +209,7 @@ func (s *VoyageCabinTypePriceService) GetCurrent(ctx context.Context, voyageID,
 }
 
 func (s *VoyageCabinTypePriceService) ListCurrentByVoyages(ctx context.Context, voyageIDs []int64) ([]domain.VoyageCabinTypeCurrent, error) {
-	now := time.Now().In(shanghaiLocation)
+	now := s.now().In(shanghaiLocation)
 	return s.repo.ListCurrentByVoyagesAt(ctx, voyageIDs, now)
 }
 
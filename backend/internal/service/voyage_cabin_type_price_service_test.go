@@ -17,6 +17,11 @@ type fakeVoyageCabinTypePriceRepo struct {
 	latest       *domain.VoyageCabinTypePriceVersion
 	currentAt    *domain.VoyageCabinTypeCurrent
 	currentAtErr error
+	versions     map[int64]*domain.VoyageCabinTypePriceVersion
+	staleKeys    []domain.VoyageCabinTypeKey
+	cancelErr    error
+	cancelledAt  time.Time
+	upsertCount  int
 }
 
 func (f *fakeVoyageCabinTypePriceRepo) CreateVersion(ctx context.Context, version *domain.VoyageCabinTypePriceVersion) error {
@@ -32,6 +37,7 @@ func (f *fakeVoyageCabinTypePriceRepo) CreateVersion(ctx context.Context, versio
 func (f *fakeVoyageCabinTypePriceRepo) UpsertCurrent(ctx context.Context, current *domain.VoyageCabinTypeCurrent) error {
 	copied := *current
 	f.upserted = &copied
+	f.upsertCount++
 	return nil
 }
 
@@ -65,6 +71,29 @@ func (f *fakeVoyageCabinTypePriceRepo) ListVersions(ctx context.Context, voyageI
 	return []domain.VoyageCabinTypePriceVersion{}, 0, nil
 }
 
+func (f *fakeVoyageCabinTypePriceRepo) GetVersion(ctx context.Context, id int64) (*domain.VoyageCabinTypePriceVersion, error) {
+	if v, ok := f.versions[id]; ok {
+		return v, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeVoyageCabinTypePriceRepo) ListPendingVersions(ctx context.Context, voyageID, cabinTypeID int64, at time.Time, page, pageSize int) ([]domain.VoyageCabinTypePriceVersion, int64, error) {
+	return []domain.VoyageCabinTypePriceVersion{}, 0, nil
+}
+
+func (f *fakeVoyageCabinTypePriceRepo) CancelVersion(ctx context.Context, id, staffID int64, at time.Time) error {
+	if f.cancelErr != nil {
+		return f.cancelErr
+	}
+	f.cancelledAt = at
+	return nil
+}
+
+func (f *fakeVoyageCabinTypePriceRepo) ListStaleCurrentKeys(ctx context.Context, at time.Time) ([]domain.VoyageCabinTypeKey, error) {
+	return f.staleKeys, nil
+}
+
 func TestVoyageCabinTypePriceService_ApplyVersionAndRefreshCurrent(t *testing.T) {
 	repo := &fakeVoyageCabinTypePriceRepo{
 		latest: &domain.VoyageCabinTypePriceVersion{
@@ -114,3 +143,82 @@ func TestVoyageCabinTypePriceService_GetCurrentPropagatesError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, current)
 }
+
+func TestVoyageCabinTypePriceService_PromoteDueVersions(t *testing.T) {
+	repo := &fakeVoyageCabinTypePriceRepo{
+		staleKeys: []domain.VoyageCabinTypeKey{{VoyageID: 12, CabinTypeID: 34}},
+		latest:    &domain.VoyageCabinTypePriceVersion{ID: 90, VoyageID: 12, CabinTypeID: 34, SalePriceCents: 19900, EffectiveAt: time.Now().Add(-time.Second)},
+	}
+	svc := NewVoyageCabinTypePriceService(repo)
+
+	promoted, err := svc.PromoteDueVersions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, promoted)
+	assert.Equal(t, int64(90), repo.upserted.VersionID)
+	assert.Equal(t, int64(19900), repo.upserted.SalePriceCents)
+
+	repo.staleKeys = nil
+	promoted, err = svc.PromoteDueVersions(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, promoted)
+}
+
+func TestVoyageCabinTypePriceService_CancelPendingVersion(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, shanghaiLocation)
+	repo := &fakeVoyageCabinTypePriceRepo{
+		versions: map[int64]*domain.VoyageCabinTypePriceVersion{
+			5: {ID: 5, VoyageID: 12, CabinTypeID: 34, EffectiveAt: now.Add(time.Hour)},
+		},
+		latest: &domain.VoyageCabinTypePriceVersion{ID: 4, VoyageID: 12, CabinTypeID: 34, EffectiveAt: now.Add(-time.Hour)},
+	}
+	svc := NewVoyageCabinTypePriceService(repo)
+	svc.now = func() time.Time { return now }
+
+	version, err := svc.CancelPendingVersion(context.Background(), 5, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), version.ID)
+	assert.True(t, repo.cancelledAt.Equal(now))
+	assert.Equal(t, int64(4), repo.upserted.VersionID, "撤销后当前态回到最新有效版本")
+
+	repo.cancelErr = domain.ErrPriceVersionNotPending
+	_, err = svc.CancelPendingVersion(context.Background(), 5, 7)
+	assert.True(t, errors.Is(err, ErrPriceVersionNotPending))
+	_, err = svc.CancelPendingVersion(context.Background(), 404, 7)
+	assert.True(t, errors.Is(err, ErrPriceVersionNotFound))
+}
+
+func TestVoyageCabinTypePriceService_RollbackToVersion(t *testing.T) {
+	now := time.Date(2026, 6, 1, 10, 0, 0, 0, shanghaiLocation)
+	cancelledAt := now.Add(-time.Hour)
+	repo := &fakeVoyageCabinTypePriceRepo{
+		versions: map[int64]*domain.VoyageCabinTypePriceVersion{
+			3: {ID: 3, VoyageID: 12, CabinTypeID: 34, InventoryTotal: 8, SettlementPriceCents: 10000, SalePriceCents: 15000, EffectiveAt: now.AddDate(0, 0, -10)},
+			6: {ID: 6, VoyageID: 12, CabinTypeID: 34, EffectiveAt: now.Add(time.Hour)},
+			7: {ID: 7, VoyageID: 12, CabinTypeID: 34, EffectiveAt: now.Add(-2 * time.Hour), CancelledAt: &cancelledAt},
+		},
+	}
+	repo.latest = &domain.VoyageCabinTypePriceVersion{ID: 101, VoyageID: 12, CabinTypeID: 34, SalePriceCents: 15000, EffectiveAt: now}
+	svc := NewVoyageCabinTypePriceService(repo)
+	svc.now = func() time.Time { return now }
+
+	version, err := svc.RollbackToVersion(context.Background(), 3, 9)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), version.ID)
+	assert.Equal(t, int64(15000), repo.created.SalePriceCents)
+	assert.Equal(t, 8, repo.created.InventoryTotal)
+	assert.True(t, repo.created.EffectiveAt.Equal(now))
+	if assert.NotNil(t, repo.created.SourceVersionID) {
+		assert.Equal(t, int64(3), *repo.created.SourceVersionID)
+	}
+	if assert.NotNil(t, repo.created.CreatedBy) {
+		assert.Equal(t, int64(9), *repo.created.CreatedBy)
+	}
+	assert.Equal(t, int64(101), repo.upserted.VersionID)
+
+	_, err = svc.RollbackToVersion(context.Background(), 6, 9)
+	assert.True(t, errors.Is(err, ErrPriceVersionNotRollbackable), "未来版本不能作为回滚来源")
+	_, err = svc.RollbackToVersion(context.Background(), 7, 9)
+	assert.True(t, errors.Is(err, ErrPriceVersionNotRollbackable), "已撤销版本不能作为回滚来源")
+	_, err = svc.RollbackToVersion(context.Background(), 404, 9)
+	assert.True(t, errors.Is(err, ErrPriceVersionNotFound))
+}
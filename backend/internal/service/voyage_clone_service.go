@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// maxClonePendingPriceVersions 是克隆时复制的待生效价格版本上限。
+const maxClonePendingPriceVersions = 200
+
+var (
+	// ErrInvalidVoyageClone 表示克隆参数不合法（缺少编码/出发日期、复制选项冲突等）。
+	ErrInvalidVoyageClone = errors.New("invalid voyage clone")
+	// ErrVoyageCloneSourceNotFound 表示被克隆的航次不存在。
+	ErrVoyageCloneSourceNotFound = errors.New("source voyage not found")
+	// ErrVoyageCloneConflict 表示新航次编码或克隆出的舱位编号已被占用。
+	ErrVoyageCloneConflict = errors.New("voyage clone code conflict")
+)
+
+// VoyageCloneRepo 定义航次克隆所需的数据访问能力。
+type VoyageCloneRepo interface {
+	domain.VoyageDraftRepository
+}
+
+// VoyageCloneSource 提供被克隆的航次（含行程）。
+type VoyageCloneSource interface {
+	GetByID(ctx context.Context, id int64) (*domain.Voyage, error)
+}
+
+// VoyageCloneCabinSource 提供被克隆航次的舱位 SKU、库存与日历价格。
+type VoyageCloneCabinSource interface {
+	ListSKUByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error)
+	GetInventoryBySKU(ctx context.Context, skuID int64) (domain.CabinInventory, error)
+	ListPricesBySKU(ctx context.Context, skuID int64) ([]domain.CabinPrice, error)
+}
+
+// VoyageClonePriceSource 提供被克隆航次的当前舱型价格与待生效价格版本。
+type VoyageClonePriceSource interface {
+	ListCurrentByVoyages(ctx context.Context, voyageIDs []int64) ([]domain.VoyageCabinTypeCurrent, error)
+	ListPendingVersions(ctx context.Context, voyageID, cabinTypeID int64, at time.Time, page, pageSize int) ([]domain.VoyageCabinTypePriceVersion, int64, error)
+}
+
+// VoyageCloneOptions 描述克隆目标与复制范围。
+type VoyageCloneOptions struct {
+	Code          string    // 新航次编码
+	DepartDate    time.Time // 新出发日期（时刻沿用源航次）
+	Status        *int16    // 新航次状态，nil 表示沿用源航次
+	Itineraries   bool      // 复制行程
+	Cabins        bool      // 复制舱位 SKU
+	Inventory     bool      // 复制库存总量与预警阈值（需复制舱位）
+	PriceCalendar bool      // 复制并平移日历价格（需复制舱位）
+	PriceVersions bool      // 复制当前舱型价格及待生效价格版本
+}
+
+// VoyageCloneReport 汇总克隆创建的数据。
+type VoyageCloneReport struct {
+	SourceVoyageID       int64                  `json:"source_voyage_id"`       // 源航次 ID
+	Voyage               *domain.Voyage         `json:"voyage"`                 // 新航次（含行程）
+	DayOffset            int                    `json:"day_offset"`             // 出发日期平移天数
+	Itineraries          int                    `json:"itineraries"`            // 复制的行程站点数
+	CabinSKUs            int                    `json:"cabin_skus"`             // 复制的舱位数
+	InventoryTotal       int                    `json:"inventory_total"`        // 复制的库存总量
+	CalendarPrices       int                    `json:"calendar_prices"`        // 复制的日历价格条数
+	PriceVersions        int                    `json:"price_versions"`         // 立即生效的价格版本数
+	PendingPriceVersions int                    `json:"pending_price_versions"` // 平移后仍待生效的价格版本数
+	Skipped              []string               `json:"skipped"`                // 未复制的数据及原因
+	Schedule             *domain.VoyageSchedule `json:"schedule,omitempty"`     // 行程时间表校验结果
+}
+
+// VoyageCloneService 将已有航次复制为新出发日期的航次。
+type VoyageCloneService struct {
+	repo    VoyageCloneRepo
+	voyages VoyageCloneSource
+	cabins  VoyageCloneCabinSource
+	prices  VoyageClonePriceSource
+	now     func() time.Time
+}
+
+// NewVoyageCloneService 创建航次克隆服务实例。
+func NewVoyageCloneService(repo VoyageCloneRepo, voyages VoyageCloneSource, cabins VoyageCloneCabinSource, prices VoyageClonePriceSource) *VoyageCloneService {
+	return &VoyageCloneService{repo: repo, voyages: voyages, cabins: cabins, prices: prices, now: time.Now}
+}
+
+// Clone 按选项在一个事务内复制航次：行程按 DayNo 原样复制，日历价格与待生效价格版本按出发日偏移平移，
+// 当前舱型价格作为立即生效的新版本写入；平移后已过期的待生效版本跳过并在报告中说明。
+func (s *VoyageCloneService) Clone(ctx context.Context, sourceID int64, opts VoyageCloneOptions, staffID int64) (*VoyageCloneReport, error) {
+	opts.Code = strings.TrimSpace(opts.Code)
+	switch {
+	case opts.Code == "":
+		return nil, fmt.Errorf("%w: code is required", ErrInvalidVoyageClone)
+	case opts.DepartDate.IsZero():
+		return nil, fmt.Errorf("%w: depart_date is required", ErrInvalidVoyageClone)
+	case !opts.Cabins && (opts.Inventory || opts.PriceCalendar):
+		return nil, fmt.Errorf("%w: copying inventory or price calendar requires copying cabins", ErrInvalidVoyageClone)
+	case opts.Status != nil && *opts.Status != 0 && *opts.Status != 1:
+		return nil, fmt.Errorf("%w: status must be 0 or 1", ErrInvalidVoyageClone)
+	}
+	source, err := s.voyages.GetByID(ctx, sourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVoyageCloneSourceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if source.DepartDate.IsZero() || source.ReturnDate.IsZero() {
+		return nil, fmt.Errorf("%w: source voyage has no dates", ErrInvalidVoyageClone)
+	}
+	template := *source
+	if !opts.Itineraries {
+		template.Itineraries = nil
+	}
+	cabins, err := s.sourceCabins(ctx, source.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := cloneVoyageDraft(&template, opts.Code, opts.DepartDate, cabins)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVoyageClone, err)
+	}
+	if opts.Status != nil {
+		draft.Voyage.Status = *opts.Status
+	}
+	schedule, err := buildItinerarySchedule(&draft.Voyage)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VoyageCloneReport{
+		SourceVoyageID: source.ID,
+		DayOffset:      calendarDaysBetween(source.DepartDate, opts.DepartDate),
+		Skipped:        []string{},
+		Schedule:       schedule,
+	}
+	if opts.PriceVersions {
+		if err := s.clonePriceVersions(ctx, source.ID, report.DayOffset, staffID, &draft, report); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ensureCodesAvailable(ctx, &draft); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateDraft(ctx, &draft); err != nil {
+		if errors.Is(err, domain.ErrVoyageCodeConflict) {
+			return nil, fmt.Errorf("%w: %v", ErrVoyageCloneConflict, err)
+		}
+		return nil, err
+	}
+
+	report.Voyage = &draft.Voyage
+	report.Itineraries = len(draft.Voyage.Itineraries)
+	report.CabinSKUs = len(draft.Cabins)
+	for _, cabin := range draft.Cabins {
+		report.InventoryTotal += cabin.InventoryTotal
+		report.CalendarPrices += len(cabin.Prices)
+	}
+	report.PriceVersions = len(draft.PriceVersions)
+	report.PendingPriceVersions = len(draft.PendingPriceVersions)
+	return report, nil
+}
+
+// sourceCabins 按选项加载源航次的舱位、库存与日历价格；未复制库存时新舱位库存为 0。
+func (s *VoyageCloneService) sourceCabins(ctx context.Context, voyageID int64, opts VoyageCloneOptions) ([]domain.VoyageDraftCabin, error) {
+	if !opts.Cabins {
+		return nil, nil
+	}
+	skus, err := s.cabins.ListSKUByVoyage(ctx, voyageID)
+	if err != nil {
+		return nil, err
+	}
+	cabins := make([]domain.VoyageDraftCabin, 0, len(skus))
+	for _, sku := range skus {
+		cabin := domain.VoyageDraftCabin{SKU: sku}
+		if opts.Inventory {
+			inventory, err := s.cabins.GetInventoryBySKU(ctx, sku.ID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			cabin.InventoryTotal, cabin.AlertThreshold = inventory.Total, inventory.AlertThreshold
+		}
+		if opts.PriceCalendar {
+			prices, err := s.cabins.ListPricesBySKU(ctx, sku.ID)
+			if err != nil {
+				return nil, err
+			}
+			cabin.Prices = prices
+		}
+		cabins = append(cabins, cabin)
+	}
+	return cabins, nil
+}
+
+// clonePriceVersions 将源航次当前舱型价格转为立即生效的版本，并平移待生效版本的生效时间。
+func (s *VoyageCloneService) clonePriceVersions(ctx context.Context, voyageID int64, offset int, staffID int64, draft *domain.VoyageDraft, report *VoyageCloneReport) error {
+	now := s.now()
+	var createdBy *int64
+	if staffID > 0 {
+		createdBy = &staffID
+	}
+	current, err := s.prices.ListCurrentByVoyages(ctx, []int64{voyageID})
+	if err != nil {
+		return err
+	}
+	for _, item := range current {
+		draft.PriceVersions = append(draft.PriceVersions, domain.VoyageCabinTypePriceVersion{
+			CabinTypeID:          item.CabinTypeID,
+			InventoryTotal:       item.InventoryTotal,
+			SettlementPriceCents: item.SettlementPriceCents,
+			SalePriceCents:       item.SalePriceCents,
+			EffectiveAt:          now,
+			CreatedBy:            createdBy,
+		})
+	}
+	pending, total, err := s.prices.ListPendingVersions(ctx, voyageID, 0, now, 1, maxClonePendingPriceVersions)
+	if err != nil {
+		return err
+	}
+	if total > int64(len(pending)) {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("only the first %d of %d pending price versions were copied", len(pending), total))
+	}
+	for _, version := range pending {
+		effectiveAt := version.EffectiveAt.AddDate(0, 0, offset)
+		if !effectiveAt.After(now) {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("pending price version %d of cabin type %d would take effect at %s, which has passed",
+				version.ID, version.CabinTypeID, effectiveAt.Format(time.RFC3339)))
+			continue
+		}
+		draft.PendingPriceVersions = append(draft.PendingPriceVersions, domain.VoyageCabinTypePriceVersion{
+			CabinTypeID:          version.CabinTypeID,
+			InventoryTotal:       version.InventoryTotal,
+			SettlementPriceCents: version.SettlementPriceCents,
+			SalePriceCents:       version.SalePriceCents,
+			EffectiveAt:          effectiveAt,
+			CreatedBy:            createdBy,
+		})
+	}
+	return nil
+}
+
+// ensureCodesAvailable 预先检查新航次编码与舱位编号，冲突时返回全部已占用的编码。
+func (s *VoyageCloneService) ensureCodesAvailable(ctx context.Context, draft *domain.VoyageDraft) error {
+	taken, err := s.repo.FindExistingVoyageCodes(ctx, []string{draft.Voyage.Code})
+	if err != nil {
+		return err
+	}
+	skuCodes := make([]string, 0, len(draft.Cabins))
+	for _, cabin := range draft.Cabins {
+		skuCodes = append(skuCodes, cabin.SKU.Code)
+	}
+	takenSKUs, err := s.repo.FindExistingSKUCodes(ctx, skuCodes)
+	if err != nil {
+		return err
+	}
+	taken = append(taken, takenSKUs...)
+	if len(taken) > 0 {
+		return fmt.Errorf("%w: %s already exist", ErrVoyageCloneConflict, strings.Join(taken, ","))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+type voyageCloneRepoStub struct {
+	takenVoyages []string
+	takenSKUs    []string
+	createErr    error
+	draft        *domain.VoyageDraft
+}
+
+func (s *voyageCloneRepoStub) CreateDraft(_ context.Context, draft *domain.VoyageDraft) error {
+	if s.createErr != nil {
+		return s.createErr
+	}
+	draft.Voyage.ID = 77
+	s.draft = draft
+	return nil
+}
+
+func (s *voyageCloneRepoStub) FindExistingVoyageCodes(_ context.Context, codes []string) ([]string, error) {
+	return intersectCodes(codes, s.takenVoyages), nil
+}
+
+func (s *voyageCloneRepoStub) FindExistingSKUCodes(_ context.Context, codes []string) ([]string, error) {
+	return intersectCodes(codes, s.takenSKUs), nil
+}
+
+type cloneCabinStub struct {
+	seriesCabinStub
+	prices map[int64][]domain.CabinPrice
+}
+
+func (s cloneCabinStub) ListPricesBySKU(_ context.Context, skuID int64) ([]domain.CabinPrice, error) {
+	return s.prices[skuID], nil
+}
+
+type clonePriceStub struct {
+	seriesPriceStub
+	pending []domain.VoyageCabinTypePriceVersion
+}
+
+func (s clonePriceStub) ListPendingVersions(context.Context, int64, int64, time.Time, int, int) ([]domain.VoyageCabinTypePriceVersion, int64, error) {
+	return s.pending, int64(len(s.pending)), nil
+}
+
+func newVoyageCloneTestService(repo *voyageCloneRepoStub) *VoyageCloneService {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	etd := "17:00"
+	source := &domain.Voyage{
+		ID: 5, CruiseID: 7, Code: "SPEC-20260405", BriefInfo: "上海-福冈-上海", Status: 1,
+		DepartDate: time.Date(2026, 4, 5, 16, 0, 0, 0, shanghai), ReturnDate: time.Date(2026, 4, 8, 8, 0, 0, 0, shanghai),
+		Itineraries: []domain.VoyageItinerary{
+			{ID: 101, VoyageID: 5, DayNo: 1, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai", ETDTime: &etd},
+			{ID: 102, VoyageID: 5, DayNo: 4, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai"},
+		},
+	}
+	cabins := cloneCabinStub{
+		seriesCabinStub: seriesCabinStub{
+			skus: []domain.CabinSKU{
+				{ID: 1, VoyageID: 5, CabinTypeID: 31, Code: "SPEC-20260405-8001", Status: 1},
+				{ID: 2, VoyageID: 5, CabinTypeID: 32, Code: "B9001", Status: 0},
+			},
+			inventories: map[int64]domain.CabinInventory{1: {CabinSKUID: 1, Total: 2, Sold: 1, AlertThreshold: 1}},
+		},
+		prices: map[int64][]domain.CabinPrice{1: {
+			{ID: 11, CabinSKUID: 1, Date: time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC), Occupancy: 2, PriceCents: 399900},
+			{ID: 12, CabinSKUID: 1, Date: time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC), Occupancy: 2, PriceCents: 419900},
+		}},
+	}
+	prices := clonePriceStub{
+		seriesPriceStub: seriesPriceStub{current: []domain.VoyageCabinTypeCurrent{
+			{VoyageID: 5, CabinTypeID: 31, InventoryTotal: 2, SettlementPriceCents: 300000, SalePriceCents: 399900},
+		}},
+		pending: []domain.VoyageCabinTypePriceVersion{
+			{ID: 21, VoyageID: 5, CabinTypeID: 31, SalePriceCents: 359900, EffectiveAt: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+			{ID: 22, VoyageID: 5, CabinTypeID: 31, SalePriceCents: 379900, EffectiveAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	svc := NewVoyageCloneService(repo, seriesTemplateStub{voyage: source}, cabins, prices)
+	svc.now = func() time.Time { return time.Date(2026, 3, 20, 10, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestVoyageCloneServiceCopiesCabinsAndShiftsPrices(t *testing.T) {
+	repo := &voyageCloneRepoStub{}
+	svc := newVoyageCloneTestService(repo)
+	opts := VoyageCloneOptions{Code: " SPEC-20260419 ", DepartDate: time.Date(2026, 4, 19, 0, 0, 0, 0, time.UTC),
+		Itineraries: true, Cabins: true, Inventory: true, PriceCalendar: true, PriceVersions: true}
+
+	report, err := svc.Clone(context.Background(), 5, opts, 9)
+	if err != nil {
+		t.Fatalf("Clone returned error: %v", err)
+	}
+	draft := repo.draft
+	if draft.Voyage.Code != "SPEC-20260419" || draft.Voyage.DepartDate.Day() != 19 || draft.Voyage.ReturnDate.Day() != 22 || draft.Voyage.DepartDate.Hour() != 16 {
+		t.Fatalf("expected voyage shifted to 2026-04-19 keeping departure clock, got %+v", draft.Voyage)
+	}
+	if len(draft.Cabins) != 2 || draft.Cabins[0].SKU.Code != "SPEC-20260419-8001" || draft.Cabins[1].SKU.Code != "SPEC-20260419-B9001" {
+		t.Fatalf("expected cabin codes rewritten, got %+v", draft.Cabins)
+	}
+	if draft.Cabins[0].InventoryTotal != 2 || draft.Cabins[0].AlertThreshold != 1 || draft.Cabins[1].InventoryTotal != 0 {
+		t.Fatalf("expected inventory totals copied, got %+v", draft.Cabins)
+	}
+	if got := draft.Cabins[0].Prices[1]; got.Date.Format("2006-01-02") != "2026-04-20" || got.ID != 0 || got.PriceCents != 419900 {
+		t.Fatalf("expected calendar price shifted by 14 days, got %+v", got)
+	}
+	if len(draft.PriceVersions) != 1 || draft.PriceVersions[0].SalePriceCents != 399900 || *draft.PriceVersions[0].CreatedBy != 9 {
+		t.Fatalf("expected current price copied as version, got %+v", draft.PriceVersions)
+	}
+	if len(draft.PendingPriceVersions) != 1 || draft.PendingPriceVersions[0].EffectiveAt.Format("2006-01-02") != "2026-04-15" {
+		t.Fatalf("expected one pending version shifted by 14 days, got %+v", draft.PendingPriceVersions)
+	}
+	if report.Voyage.ID != 77 || report.DayOffset != 14 || report.Itineraries != 2 || report.CabinSKUs != 2 || report.InventoryTotal != 2 ||
+		report.CalendarPrices != 2 || report.PriceVersions != 1 || report.PendingPriceVersions != 1 || report.Schedule == nil {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Skipped) != 1 || !strings.Contains(report.Skipped[0], "version 22") {
+		t.Fatalf("expected past pending version reported as skipped, got %v", report.Skipped)
+	}
+}
+
+func TestVoyageCloneServiceHonoursOptions(t *testing.T) {
+	repo := &voyageCloneRepoStub{}
+	svc := newVoyageCloneTestService(repo)
+	closed := int16(0)
+	opts := VoyageCloneOptions{Code: "SPEC-20260419", DepartDate: time.Date(2026, 4, 19, 0, 0, 0, 0, time.UTC), Status: &closed}
+
+	report, err := svc.Clone(context.Background(), 5, opts, 0)
+	if err != nil {
+		t.Fatalf("Clone returned error: %v", err)
+	}
+	if repo.draft.Voyage.Status != 0 || len(repo.draft.Voyage.Itineraries) != 0 || len(repo.draft.Cabins) != 0 || len(repo.draft.PriceVersions) != 0 {
+		t.Fatalf("expected bare closed voyage, got %+v", repo.draft)
+	}
+	if report.Itineraries != 0 || report.CabinSKUs != 0 || report.Schedule == nil {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	opts = VoyageCloneOptions{Code: "SPEC-20260419", DepartDate: time.Date(2026, 4, 19, 0, 0, 0, 0, time.UTC), Cabins: true}
+	if _, err := svc.Clone(context.Background(), 5, opts, 0); err != nil || repo.draft.Cabins[0].InventoryTotal != 0 || len(repo.draft.Cabins[0].Prices) != 0 {
+		t.Fatalf("expected cabins without inventory or prices, got %+v, %v", repo.draft.Cabins, err)
+	}
+}
+
+func TestVoyageCloneServiceRejectsInvalidAndConflicts(t *testing.T) {
+	repo := &voyageCloneRepoStub{takenSKUs: []string{"SPEC-20260419-8001"}}
+	svc := newVoyageCloneTestService(repo)
+	depart := time.Date(2026, 4, 19, 0, 0, 0, 0, time.UTC)
+
+	if _, err := svc.Clone(context.Background(), 5, VoyageCloneOptions{DepartDate: depart}, 0); !errors.Is(err, ErrInvalidVoyageClone) {
+		t.Fatalf("expected ErrInvalidVoyageClone for missing code, got %v", err)
+	}
+	if _, err := svc.Clone(context.Background(), 5, VoyageCloneOptions{Code: "X", DepartDate: depart, Inventory: true}, 0); !errors.Is(err, ErrInvalidVoyageClone) {
+		t.Fatalf("expected ErrInvalidVoyageClone for inventory without cabins, got %v", err)
+	}
+	if _, err := svc.Clone(context.Background(), 404, VoyageCloneOptions{Code: "X", DepartDate: depart}, 0); !errors.Is(err, ErrVoyageCloneSourceNotFound) {
+		t.Fatalf("expected ErrVoyageCloneSourceNotFound, got %v", err)
+	}
+	_, err := svc.Clone(context.Background(), 5, VoyageCloneOptions{Code: "SPEC-20260419", DepartDate: depart, Cabins: true}, 0)
+	if !errors.Is(err, ErrVoyageCloneConflict) || !strings.Contains(err.Error(), "SPEC-20260419-8001") || repo.draft != nil {
+		t.Fatalf("expected ErrVoyageCloneConflict listing taken codes, got %v", err)
+	}
+
+	repo.takenSKUs, repo.createErr = nil, domain.ErrVoyageCodeConflict
+	if _, err := svc.Clone(context.Background(), 5, VoyageCloneOptions{Code: "SPEC-20260419", DepartDate: depart}, 0); !errors.Is(err, ErrVoyageCloneConflict) {
+		t.Fatalf("expected repository conflict mapped to ErrVoyageCloneConflict, got %v", err)
+	}
+}
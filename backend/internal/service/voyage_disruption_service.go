@@ -0,0 +1,531 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	disruptionNoticeTemplate    = "voyage_disruption_notice"   // 停航/变更告知通知模板标识
+	disruptionOfferTemplate     = "voyage_disruption_offer"    // 替代方案通知模板标识
+	disruptionResolvedTemplate  = "voyage_disruption_resolved" // 处理结果通知模板标识
+	disruptionDefaultOccupancy  = 2                            // 订单未登记乘客时按双人入住计价
+	disruptionMaxBatch          = 500                          // 单次批量发出方案/处理的订单上限
+	disruptionMaxCreditBonus    = 100                          // 抵用金额外补偿比例上限（%）
+	defaultTravelCreditValidity = 365 * 24 * time.Hour         // 抵用金默认有效期
+)
+
+var (
+	// ErrInvalidDisruption 表示停航/变更请求参数不合法。
+	ErrInvalidDisruption = errors.New("invalid voyage disruption request")
+	// ErrDisruptionNotFound 表示航次、停航/变更事件或受影响订单不存在。
+	ErrDisruptionNotFound = errors.New("voyage disruption not found")
+	// ErrDisruptionConflict 表示事件或订单状态不允许当前操作（已有处理中事件、已结案、已处理、无可改签舱房等）。
+	ErrDisruptionConflict = errors.New("voyage disruption conflict")
+)
+
+// VoyageDisruptionVoyages 提供受影响航次与改签目标航次。
+type VoyageDisruptionVoyages interface {
+	GetByID(ctx context.Context, id int64) (*domain.Voyage, error)
+}
+
+// VoyageDisruptionCabins 提供改签目标航次的舱房。
+type VoyageDisruptionCabins interface {
+	ListSKUByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error)
+}
+
+// DisruptionOfferRequest 描述批量发给受影响订单的替代方案。
+type DisruptionOfferRequest struct {
+	BookingIDs           []int64  // 订单 ID，空表示全部未处理订单
+	Options              []string // 可选方案：rebook / refund / credit
+	AlternativeVoyageIDs []int64  // 可改签航次，提供 rebook 时必填
+	WaivePriceDifference bool     // 改签到更贵舱房时免收差价
+	CreditBonusPercent   int      // 抵用金额外补偿比例（0-100）
+}
+
+// DisruptionResolveRequest 描述后台对受影响订单的批量处理。
+type DisruptionResolveRequest struct {
+	BookingIDs           []int64 // 订单 ID，空表示全部未处理订单
+	Resolution           string  // rebook / refund / credit
+	TargetVoyageID       int64   // 改签目标航次
+	TargetCabinSKUID     int64   // 指定改签舱房，0 表示按原舱型自动匹配
+	WaivePriceDifference bool    // 免收改签差价
+	CreditBonusPercent   int     // 抵用金额外补偿比例（0-100）
+	Remark               string  // 处理备注
+}
+
+// DisruptionResolveResult 是批量处理中单个订单的结果。
+type DisruptionResolveResult struct {
+	BookingID int64                     `json:"booking_id"`      // 订单 ID
+	Item      *domain.DisruptionBooking `json:"item,omitempty"`  // 处理后的受影响订单记录
+	Error     string                    `json:"error,omitempty"` // 处理失败原因
+}
+
+// disruptionNotice 是停航/变更通知的负载。
+type disruptionNotice struct {
+	DisruptionID         int64  `json:"disruption_id"`
+	DisruptionBookingID  int64  `json:"disruption_booking_id"`
+	BookingID            int64  `json:"booking_id"`
+	VoyageID             int64  `json:"voyage_id"`
+	Type                 string `json:"type,omitempty"`
+	Reason               string `json:"reason,omitempty"`
+	Options              string `json:"options,omitempty"`
+	AlternativeVoyageIDs string `json:"alternative_voyage_ids,omitempty"`
+	Resolution           string `json:"resolution,omitempty"`
+	NewBookingID         int64  `json:"new_booking_id,omitempty"`
+	NewVoyageID          int64  `json:"new_voyage_id,omitempty"`
+	PriceDiffCents       int64  `json:"price_diff_cents,omitempty"`
+	RefundCents          int64  `json:"refund_cents,omitempty"`
+	CreditID             int64  `json:"credit_id,omitempty"`
+}
+
+// VoyageDisruptionService 负责航次取消/行程变更的处理流程：标记航次并快照受影响订单、
+// 批量发出替代方案（改签、全额退款、抵用金）、逐单处理并通知乘客，全部处理后结案。
+type VoyageDisruptionService struct {
+	repo           domain.VoyageDisruptionRepository
+	voyages        VoyageDisruptionVoyages
+	cabins         VoyageDisruptionCabins
+	price          PriceService
+	creditValidity time.Duration
+	now            func() time.Time
+}
+
+// NewVoyageDisruptionService 创建停航/变更处理服务；抵用金默认有效期一年。
+func NewVoyageDisruptionService(repo domain.VoyageDisruptionRepository, voyages VoyageDisruptionVoyages, cabins VoyageDisruptionCabins, price PriceService) *VoyageDisruptionService {
+	return &VoyageDisruptionService{
+		repo:           repo,
+		voyages:        voyages,
+		cabins:         cabins,
+		price:          price,
+		creditValidity: defaultTravelCreditValidity,
+		now:            time.Now,
+	}
+}
+
+// Open 将航次标记为取消或变更（取消时关闭预订），快照受影响订单；notify 为 true 时通知全部受影响乘客。
+func (s *VoyageDisruptionService) Open(ctx context.Context, voyageID int64, disruptionType, reason string, notify bool, staffID int64) (*domain.VoyageDisruption, error) {
+	disruptionType = strings.TrimSpace(disruptionType)
+	if disruptionType != domain.VoyageDisruptionCancelled && disruptionType != domain.VoyageDisruptionModified {
+		return nil, fmt.Errorf("%w: type must be cancelled or modified", ErrInvalidDisruption)
+	}
+	disruption := &domain.VoyageDisruption{VoyageID: voyageID, Type: disruptionType, Reason: strings.TrimSpace(reason)}
+	if staffID > 0 {
+		disruption.CreatedBy = &staffID
+	}
+	var notifier domain.DisruptionNotifier
+	if notify {
+		notifier = func(item *domain.DisruptionBooking) (*domain.Notification, error) {
+			return disruptionNotification(item.UserID, disruptionNoticeTemplate, disruptionNotice{
+				DisruptionID:        disruption.ID,
+				DisruptionBookingID: item.ID,
+				BookingID:           item.BookingID,
+				VoyageID:            disruption.VoyageID,
+				Type:                disruption.Type,
+				Reason:              disruption.Reason,
+			})
+		}
+	}
+	if err := s.repo.Open(ctx, disruption, notifier); err != nil {
+		return nil, translateDisruptionError(err)
+	}
+	return disruption, nil
+}
+
+// Get 查询停航/变更事件。
+func (s *VoyageDisruptionService) Get(ctx context.Context, id int64) (*domain.VoyageDisruption, error) {
+	disruption, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, translateDisruptionError(err)
+	}
+	return disruption, nil
+}
+
+// List 按航次与状态分页查询停航/变更事件。
+func (s *VoyageDisruptionService) List(ctx context.Context, voyageID int64, status string, page, pageSize int) ([]domain.VoyageDisruption, int64, error) {
+	page, pageSize = normalizeDisruptionPage(page, pageSize)
+	return s.repo.List(ctx, voyageID, strings.TrimSpace(status), page, pageSize)
+}
+
+// ListBookings 分页查询事件下的受影响订单及其处理结果。
+func (s *VoyageDisruptionService) ListBookings(ctx context.Context, filter domain.DisruptionBookingFilter, page, pageSize int) ([]domain.DisruptionBooking, int64, error) {
+	page, pageSize = normalizeDisruptionPage(page, pageSize)
+	filter.Status = strings.TrimSpace(filter.Status)
+	filter.Resolution = strings.TrimSpace(filter.Resolution)
+	return s.repo.ListBookings(ctx, filter, page, pageSize)
+}
+
+// ListMine 查询用户收到的停航/变更替代方案与处理结果。
+func (s *VoyageDisruptionService) ListMine(ctx context.Context, userID int64) ([]domain.DisruptionBooking, error) {
+	items, _, err := s.repo.ListBookings(ctx, domain.DisruptionBookingFilter{UserID: userID}, 1, 100)
+	return items, err
+}
+
+// Offer 为受影响订单批量发出替代方案并通知乘客，返回发出的订单数；已处理的订单跳过。
+func (s *VoyageDisruptionService) Offer(ctx context.Context, disruptionID int64, req DisruptionOfferRequest) (int, error) {
+	disruption, err := s.openDisruption(ctx, disruptionID)
+	if err != nil {
+		return 0, err
+	}
+	options := make([]string, 0, len(req.Options))
+	for _, option := range req.Options {
+		option = strings.TrimSpace(option)
+		if !validDisruptionResolution(option) {
+			return 0, fmt.Errorf("%w: unknown option %q", ErrInvalidDisruption, option)
+		}
+		if !slices.Contains(options, option) {
+			options = append(options, option)
+		}
+	}
+	if len(options) == 0 {
+		return 0, fmt.Errorf("%w: at least one option is required", ErrInvalidDisruption)
+	}
+	if req.CreditBonusPercent < 0 || req.CreditBonusPercent > disruptionMaxCreditBonus {
+		return 0, fmt.Errorf("%w: credit_bonus_percent must be between 0 and %d", ErrInvalidDisruption, disruptionMaxCreditBonus)
+	}
+	var alternatives []int64
+	if slices.Contains(options, domain.DisruptionResolutionRebook) {
+		if len(req.AlternativeVoyageIDs) == 0 {
+			return 0, fmt.Errorf("%w: alternative_voyage_ids are required for rebook", ErrInvalidDisruption)
+		}
+		for _, id := range req.AlternativeVoyageIDs {
+			if slices.Contains(alternatives, id) {
+				continue
+			}
+			if _, err := s.rebookTarget(ctx, disruption, id); err != nil {
+				return 0, err
+			}
+			alternatives = append(alternatives, id)
+		}
+	}
+	items, err := s.targetBookings(ctx, disruptionID, req.BookingIDs)
+	if err != nil {
+		return 0, err
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		if item.Status != domain.DisruptionBookingResolved {
+			ids = append(ids, item.ID)
+		}
+	}
+	offer := domain.DisruptionOffer{
+		Options:              options,
+		AlternativeVoyageIDs: alternatives,
+		WaivePriceDifference: req.WaivePriceDifference,
+		CreditBonusPercent:   req.CreditBonusPercent,
+		At:                   s.now(),
+	}
+	return s.repo.Offer(ctx, ids, offer, func(item *domain.DisruptionBooking) (*domain.Notification, error) {
+		return disruptionNotification(item.UserID, disruptionOfferTemplate, disruptionNotice{
+			DisruptionID:         disruption.ID,
+			DisruptionBookingID:  item.ID,
+			BookingID:            item.BookingID,
+			VoyageID:             disruption.VoyageID,
+			Type:                 disruption.Type,
+			Reason:               disruption.Reason,
+			Options:              item.OfferedOptions,
+			AlternativeVoyageIDs: item.AlternativeVoyageIDs,
+		})
+	})
+}
+
+// Resolve 由后台批量处理受影响订单，逐单返回结果；单个订单失败不影响其他订单。
+// 改签时按原舱型在目标航次上自动匹配有余量的舱房（或使用指定舱房），按目标航次出发日价格计算差价。
+func (s *VoyageDisruptionService) Resolve(ctx context.Context, disruptionID int64, req DisruptionResolveRequest, staffID int64) ([]DisruptionResolveResult, error) {
+	disruption, err := s.openDisruption(ctx, disruptionID)
+	if err != nil {
+		return nil, err
+	}
+	req.Resolution = strings.TrimSpace(req.Resolution)
+	if !validDisruptionResolution(req.Resolution) {
+		return nil, fmt.Errorf("%w: resolution must be rebook, refund or credit", ErrInvalidDisruption)
+	}
+	if req.CreditBonusPercent < 0 || req.CreditBonusPercent > disruptionMaxCreditBonus {
+		return nil, fmt.Errorf("%w: credit_bonus_percent must be between 0 and %d", ErrInvalidDisruption, disruptionMaxCreditBonus)
+	}
+	var target *domain.Voyage
+	var targetSKUs []domain.CabinSKU
+	if req.Resolution == domain.DisruptionResolutionRebook {
+		if target, err = s.rebookTarget(ctx, disruption, req.TargetVoyageID); err != nil {
+			return nil, err
+		}
+		if targetSKUs, err = s.cabins.ListSKUByVoyage(ctx, target.ID); err != nil {
+			return nil, err
+		}
+		if req.TargetCabinSKUID > 0 && !slices.ContainsFunc(targetSKUs, func(sku domain.CabinSKU) bool { return sku.ID == req.TargetCabinSKUID }) {
+			return nil, fmt.Errorf("%w: cabin %d does not belong to voyage %d", ErrInvalidDisruption, req.TargetCabinSKUID, target.ID)
+		}
+	}
+	items, err := s.targetBookings(ctx, disruptionID, req.BookingIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]DisruptionResolveResult, 0, len(items))
+	for _, item := range items {
+		result := DisruptionResolveResult{BookingID: item.BookingID}
+		if item.Status == domain.DisruptionBookingResolved {
+			result.Item = &item
+			result.Error = domain.ErrDisruptionBookingResolved.Error()
+			results = append(results, result)
+			continue
+		}
+		res := domain.DisruptionResolution{
+			Resolution:           req.Resolution,
+			WaivePriceDifference: req.WaivePriceDifference,
+			CreditBonusPercent:   req.CreditBonusPercent,
+			OperatorID:           staffID,
+			Remark:               strings.TrimSpace(req.Remark),
+		}
+		resolved, err := s.resolve(ctx, disruption, item, res, target, targetSKUs, req.TargetCabinSKUID)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Item = resolved
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Accept 由乘客在收到的替代方案中自助选择：改签需选择方案内的航次，差价减免与抵用金补偿沿用方案设置。
+func (s *VoyageDisruptionService) Accept(ctx context.Context, userID, itemID int64, resolution string, voyageID int64) (*domain.DisruptionBooking, error) {
+	item, err := s.repo.GetBooking(ctx, itemID)
+	if err != nil {
+		return nil, translateDisruptionError(err)
+	}
+	if item.UserID != userID {
+		return nil, ErrDisruptionNotFound
+	}
+	if item.Status != domain.DisruptionBookingOffered {
+		return nil, fmt.Errorf("%w: no pending offer for booking %d", ErrDisruptionConflict, item.BookingID)
+	}
+	resolution = strings.TrimSpace(resolution)
+	if !slices.Contains(strings.Split(item.OfferedOptions, ","), resolution) {
+		return nil, fmt.Errorf("%w: option %q was not offered", ErrInvalidDisruption, resolution)
+	}
+	disruption, err := s.openDisruption(ctx, item.DisruptionID)
+	if err != nil {
+		return nil, err
+	}
+	var target *domain.Voyage
+	var targetSKUs []domain.CabinSKU
+	if resolution == domain.DisruptionResolutionRebook {
+		if !slices.Contains(strings.Split(item.AlternativeVoyageIDs, ","), strconv.FormatInt(voyageID, 10)) {
+			return nil, fmt.Errorf("%w: voyage %d was not offered", ErrInvalidDisruption, voyageID)
+		}
+		if target, err = s.rebookTarget(ctx, disruption, voyageID); err != nil {
+			return nil, err
+		}
+		if targetSKUs, err = s.cabins.ListSKUByVoyage(ctx, target.ID); err != nil {
+			return nil, err
+		}
+	}
+	res := domain.DisruptionResolution{
+		Resolution:           resolution,
+		WaivePriceDifference: item.WaivePriceDifference,
+		CreditBonusPercent:   item.CreditBonusPercent,
+		Remark:               "accepted by passenger",
+	}
+	return s.resolve(ctx, disruption, *item, res, target, targetSKUs, 0)
+}
+
+// Close 在全部受影响订单处理完毕后结案。
+func (s *VoyageDisruptionService) Close(ctx context.Context, id int64) (*domain.VoyageDisruption, error) {
+	disruption, err := s.repo.Close(ctx, id, s.now())
+	if err != nil {
+		return nil, translateDisruptionError(err)
+	}
+	return disruption, nil
+}
+
+// resolve 补齐改签候选舱房、抵用金有效期与处理时间后执行单个订单的处理，并写入结果通知。
+func (s *VoyageDisruptionService) resolve(ctx context.Context, disruption *domain.VoyageDisruption, item domain.DisruptionBooking, res domain.DisruptionResolution,
+	target *domain.Voyage, targetSKUs []domain.CabinSKU, targetSKUID int64) (*domain.DisruptionBooking, error) {
+	now := s.now()
+	res.At = now
+	switch res.Resolution {
+	case domain.DisruptionResolutionRebook:
+		candidates, err := s.rebookCandidates(ctx, item, target, targetSKUs, targetSKUID)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("%w: %v for booking %d", ErrDisruptionConflict, domain.ErrNoRebookCabin, item.BookingID)
+		}
+		res.TargetVoyageID, res.Candidates = target.ID, candidates
+	case domain.DisruptionResolutionCredit:
+		if item.PaidCents <= 0 {
+			return nil, fmt.Errorf("%w: booking %d has no paid amount to convert into credit", ErrInvalidDisruption, item.BookingID)
+		}
+		res.CreditExpiresAt = now.Add(s.creditValidity)
+	}
+	resolved, err := s.repo.Resolve(ctx, item.ID, res, func(item *domain.DisruptionBooking) (*domain.Notification, error) {
+		return disruptionNotification(item.UserID, disruptionResolvedTemplate, disruptionNotice{
+			DisruptionID:        disruption.ID,
+			DisruptionBookingID: item.ID,
+			BookingID:           item.BookingID,
+			VoyageID:            disruption.VoyageID,
+			Resolution:          item.Resolution,
+			NewBookingID:        item.NewBookingID,
+			NewVoyageID:         item.NewVoyageID,
+			PriceDiffCents:      item.PriceDiffCents,
+			RefundCents:         item.RefundCents,
+			CreditID:            item.CreditID,
+		})
+	})
+	if err != nil {
+		return nil, translateDisruptionError(err)
+	}
+	return resolved, nil
+}
+
+// rebookCandidates 返回改签候选舱房：指定舱房时仅使用该舱房，否则为目标航次上与原订单同舱型的在售舱房。
+// 价格按目标航次出发日与乘客人数查询，未配置价格时沿用原订单金额。
+func (s *VoyageDisruptionService) rebookCandidates(ctx context.Context, item domain.DisruptionBooking, target *domain.Voyage, skus []domain.CabinSKU, targetSKUID int64) ([]domain.DisruptionRebookCandidate, error) {
+	occupancy := item.Guests
+	if occupancy <= 0 {
+		occupancy = disruptionDefaultOccupancy
+	}
+	candidates := make([]domain.DisruptionRebookCandidate, 0)
+	for _, sku := range skus {
+		if targetSKUID > 0 {
+			if sku.ID != targetSKUID {
+				continue
+			}
+		} else if sku.Status != 1 || item.CabinTypeID == 0 || sku.CabinTypeID != item.CabinTypeID {
+			continue
+		}
+		total, found, err := s.price.FindPrice(ctx, sku.ID, target.DepartDate, occupancy)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			total = item.TotalCents
+		}
+		candidates = append(candidates, domain.DisruptionRebookCandidate{CabinSKUID: sku.ID, TotalCents: total})
+	}
+	return candidates, nil
+}
+
+// rebookTarget 校验改签目标航次：必须存在、不是受影响航次本身且未被取消。
+func (s *VoyageDisruptionService) rebookTarget(ctx context.Context, disruption *domain.VoyageDisruption, voyageID int64) (*domain.Voyage, error) {
+	if voyageID <= 0 {
+		return nil, fmt.Errorf("%w: target voyage is required for rebook", ErrInvalidDisruption)
+	}
+	if voyageID == disruption.VoyageID {
+		return nil, fmt.Errorf("%w: cannot rebook onto the disrupted voyage", ErrInvalidDisruption)
+	}
+	voyage, err := s.voyages.GetByID(ctx, voyageID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: voyage %d not found", ErrInvalidDisruption, voyageID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if voyage.Disruption == domain.VoyageDisruptionCancelled {
+		return nil, fmt.Errorf("%w: voyage %d is cancelled", ErrInvalidDisruption, voyageID)
+	}
+	return voyage, nil
+}
+
+// openDisruption 查询处理中的事件，已结案时返回 ErrDisruptionConflict。
+func (s *VoyageDisruptionService) openDisruption(ctx context.Context, id int64) (*domain.VoyageDisruption, error) {
+	disruption, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if disruption.Status != domain.VoyageDisruptionStatusOpen {
+		return nil, fmt.Errorf("%w: %v", ErrDisruptionConflict, domain.ErrVoyageDisruptionClosed)
+	}
+	return disruption, nil
+}
+
+// targetBookings 返回指定订单在事件下的受影响记录；未指定时返回全部未处理记录。
+func (s *VoyageDisruptionService) targetBookings(ctx context.Context, disruptionID int64, bookingIDs []int64) ([]domain.DisruptionBooking, error) {
+	if len(bookingIDs) > disruptionMaxBatch {
+		return nil, fmt.Errorf("%w: at most %d bookings per request", ErrInvalidDisruption, disruptionMaxBatch)
+	}
+	if len(bookingIDs) == 0 {
+		items := make([]domain.DisruptionBooking, 0)
+		for _, status := range []string{domain.DisruptionBookingPending, domain.DisruptionBookingOffered} {
+			page, _, err := s.repo.ListBookings(ctx, domain.DisruptionBookingFilter{DisruptionID: disruptionID, Status: status}, 1, disruptionMaxBatch)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, page...)
+		}
+		slices.SortFunc(items, func(a, b domain.DisruptionBooking) int { return cmp.Compare(a.ID, b.ID) })
+		return items, nil
+	}
+	items := make([]domain.DisruptionBooking, 0, len(bookingIDs))
+	for _, bookingID := range bookingIDs {
+		item, err := s.repo.FindBooking(ctx, disruptionID, bookingID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: booking %d is not affected by disruption %d", ErrInvalidDisruption, bookingID, disruptionID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+func validDisruptionResolution(resolution string) bool {
+	switch resolution {
+	case domain.DisruptionResolutionRebook, domain.DisruptionResolutionRefund, domain.DisruptionResolutionCredit:
+		return true
+	}
+	return false
+}
+
+func normalizeDisruptionPage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+func disruptionNotification(userID int64, template string, payload disruptionNotice) (*domain.Notification, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Notification{
+		UserID:   userID,
+		Channel:  ChannelSMS,
+		Template: template,
+		Payload:  string(body),
+		Status:   NotificationStatusPending,
+	}, nil
+}
+
+func translateDisruptionError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrDisruptionNotFound
+	case errors.Is(err, domain.ErrVoyageDisruptionOpen),
+		errors.Is(err, domain.ErrVoyageDisruptionClosed),
+		errors.Is(err, domain.ErrDisruptionBookingResolved),
+		errors.Is(err, domain.ErrNoRebookCabin),
+		errors.Is(err, domain.ErrRebookPriceDifference),
+		errors.Is(err, domain.ErrDisruptionRefundShort),
+		errors.Is(err, domain.ErrDisruptionUnresolved):
+		return fmt.Errorf("%w: %v", ErrDisruptionConflict, err)
+	default:
+		return err
+	}
+}
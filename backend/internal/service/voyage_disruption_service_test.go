@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+type voyageDisruptionRepoStub struct {
+	disruption  *domain.VoyageDisruption
+	items       []domain.DisruptionBooking
+	resolutions map[int64]domain.DisruptionResolution
+	offer       domain.DisruptionOffer
+	offeredIDs  []int64
+	notices     []*domain.Notification
+	resolveErr  error
+}
+
+func (s *voyageDisruptionRepoStub) Open(_ context.Context, disruption *domain.VoyageDisruption, notify domain.DisruptionNotifier) error {
+	if s.disruption != nil && s.disruption.Status == domain.VoyageDisruptionStatusOpen {
+		return domain.ErrVoyageDisruptionOpen
+	}
+	disruption.ID, disruption.Status, disruption.AffectedCount = 3, domain.VoyageDisruptionStatusOpen, len(s.items)
+	s.disruption = disruption
+	return s.notify(notify, s.items)
+}
+
+func (s *voyageDisruptionRepoStub) GetByID(_ context.Context, id int64) (*domain.VoyageDisruption, error) {
+	if s.disruption == nil || s.disruption.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copyDisruption := *s.disruption
+	return &copyDisruption, nil
+}
+
+func (s *voyageDisruptionRepoStub) List(context.Context, int64, string, int, int) ([]domain.VoyageDisruption, int64, error) {
+	return []domain.VoyageDisruption{*s.disruption}, 1, nil
+}
+
+func (s *voyageDisruptionRepoStub) ListBookings(_ context.Context, filter domain.DisruptionBookingFilter, _, _ int) ([]domain.DisruptionBooking, int64, error) {
+	out := []domain.DisruptionBooking{}
+	for _, item := range s.items {
+		if (filter.Status == "" || item.Status == filter.Status) && (filter.UserID == 0 || item.UserID == filter.UserID) {
+			out = append(out, item)
+		}
+	}
+	return out, int64(len(out)), nil
+}
+
+func (s *voyageDisruptionRepoStub) GetBooking(_ context.Context, id int64) (*domain.DisruptionBooking, error) {
+	for _, item := range s.items {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *voyageDisruptionRepoStub) FindBooking(_ context.Context, _, bookingID int64) (*domain.DisruptionBooking, error) {
+	for _, item := range s.items {
+		if item.BookingID == bookingID {
+			return &item, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *voyageDisruptionRepoStub) Offer(_ context.Context, ids []int64, offer domain.DisruptionOffer, notify domain.DisruptionNotifier) (int, error) {
+	s.offer, s.offeredIDs = offer, ids
+	return len(ids), nil
+}
+
+func (s *voyageDisruptionRepoStub) Resolve(_ context.Context, id int64, res domain.DisruptionResolution, notify domain.DisruptionNotifier) (*domain.DisruptionBooking, error) {
+	if s.resolveErr != nil {
+		return nil, s.resolveErr
+	}
+	if s.resolutions == nil {
+		s.resolutions = map[int64]domain.DisruptionResolution{}
+	}
+	s.resolutions[id] = res
+	item, err := s.GetBooking(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	item.Status, item.Resolution, item.NewVoyageID = domain.DisruptionBookingResolved, res.Resolution, res.TargetVoyageID
+	return item, s.notify(notify, []domain.DisruptionBooking{*item})
+}
+
+func (s *voyageDisruptionRepoStub) Close(context.Context, int64, time.Time) (*domain.VoyageDisruption, error) {
+	return nil, domain.ErrDisruptionUnresolved
+}
+
+func (s *voyageDisruptionRepoStub) notify(notify domain.DisruptionNotifier, items []domain.DisruptionBooking) error {
+	if notify == nil {
+		return nil
+	}
+	for i := range items {
+		notice, err := notify(&items[i])
+		if err != nil {
+			return err
+		}
+		s.notices = append(s.notices, notice)
+	}
+	return nil
+}
+
+type disruptionVoyageStub map[int64]*domain.Voyage
+
+func (s disruptionVoyageStub) GetByID(_ context.Context, id int64) (*domain.Voyage, error) {
+	if voyage, ok := s[id]; ok {
+		return voyage, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type disruptionPriceStub struct {
+	prices    map[int64]int64
+	occupancy int
+	date      time.Time
+}
+
+func (s *disruptionPriceStub) FindPrice(_ context.Context, skuID int64, date time.Time, occupancy int) (int64, bool, error) {
+	s.occupancy, s.date = occupancy, date
+	price, ok := s.prices[skuID]
+	return price, ok, nil
+}
+
+func newVoyageDisruptionTestService() (*VoyageDisruptionService, *voyageDisruptionRepoStub, *disruptionPriceStub) {
+	repo := &voyageDisruptionRepoStub{
+		disruption: &domain.VoyageDisruption{ID: 3, VoyageID: 5, Type: domain.VoyageDisruptionCancelled, Status: domain.VoyageDisruptionStatusOpen},
+		items: []domain.DisruptionBooking{
+			{ID: 31, DisruptionID: 3, BookingID: 501, UserID: 11, CabinTypeID: 31, Guests: 3, TotalCents: 500000, PaidCents: 500000, Status: domain.DisruptionBookingPending},
+			{ID: 32, DisruptionID: 3, BookingID: 502, UserID: 12, CabinTypeID: 32, TotalCents: 300000, Status: domain.DisruptionBookingOffered,
+				OfferedOptions: "rebook,refund", AlternativeVoyageIDs: "6", WaivePriceDifference: true},
+			{ID: 33, DisruptionID: 3, BookingID: 503, UserID: 13, CabinTypeID: 31, Status: domain.DisruptionBookingResolved, Resolution: domain.DisruptionResolutionRefund},
+		},
+	}
+	voyages := disruptionVoyageStub{
+		5: {ID: 5, Code: "SPEC-20260801", Disruption: domain.VoyageDisruptionCancelled},
+		6: {ID: 6, Code: "SPEC-20260808", DepartDate: time.Date(2026, 8, 8, 16, 0, 0, 0, time.UTC)},
+		7: {ID: 7, Code: "SPEC-20260815", Disruption: domain.VoyageDisruptionCancelled},
+	}
+	cabins := seriesCabinStub{skus: []domain.CabinSKU{
+		{ID: 61, VoyageID: 6, CabinTypeID: 31, Status: 1},
+		{ID: 62, VoyageID: 6, CabinTypeID: 31, Status: 0},
+		{ID: 63, VoyageID: 6, CabinTypeID: 32, Status: 1},
+		{ID: 64, VoyageID: 6, CabinTypeID: 31, Status: 1},
+	}}
+	price := &disruptionPriceStub{prices: map[int64]int64{61: 520000, 63: 280000}}
+	svc := NewVoyageDisruptionService(repo, voyages, cabins, price)
+	svc.now = func() time.Time { return time.Date(2026, 7, 20, 9, 0, 0, 0, time.UTC) }
+	return svc, repo, price
+}
+
+func TestVoyageDisruptionService_OpenValidatesTypeAndNotifies(t *testing.T) {
+	svc, repo, _ := newVoyageDisruptionTestService()
+	repo.disruption = nil
+	if _, err := svc.Open(context.Background(), 5, "delayed", "", true, 9); !errors.Is(err, ErrInvalidDisruption) {
+		t.Fatalf("expected ErrInvalidDisruption, got %v", err)
+	}
+	disruption, err := svc.Open(context.Background(), 5, " cancelled ", " 台风 ", true, 9)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if disruption.Reason != "台风" || disruption.CreatedBy == nil || *disruption.CreatedBy != 9 {
+		t.Fatalf("unexpected disruption: %+v", disruption)
+	}
+	if len(repo.notices) != 3 || repo.notices[0].Template != disruptionNoticeTemplate || repo.notices[0].UserID != 11 {
+		t.Fatalf("unexpected notices: %+v", repo.notices)
+	}
+	var payload disruptionNotice
+	if err := json.Unmarshal([]byte(repo.notices[0].Payload), &payload); err != nil || payload.DisruptionID != 3 || payload.BookingID != 501 {
+		t.Fatalf("unexpected payload %s (%v)", repo.notices[0].Payload, err)
+	}
+	if _, err := svc.Open(context.Background(), 5, "modified", "", false, 9); !errors.Is(err, ErrDisruptionConflict) {
+		t.Fatalf("expected ErrDisruptionConflict for second open disruption, got %v", err)
+	}
+}
+
+func TestVoyageDisruptionService_OfferValidation(t *testing.T) {
+	svc, repo, _ := newVoyageDisruptionTestService()
+	ctx := context.Background()
+	cases := []DisruptionOfferRequest{
+		{},
+		{Options: []string{"voucher"}},
+		{Options: []string{"refund"}, CreditBonusPercent: 101},
+		{Options: []string{"rebook"}},
+		{Options: []string{"rebook"}, AlternativeVoyageIDs: []int64{5}},
+		{Options: []string{"rebook"}, AlternativeVoyageIDs: []int64{7}},
+		{Options: []string{"rebook"}, AlternativeVoyageIDs: []int64{404}},
+		{Options: []string{"refund"}, BookingIDs: []int64{999}},
+	}
+	for i, req := range cases {
+		if _, err := svc.Offer(ctx, 3, req); !errors.Is(err, ErrInvalidDisruption) {
+			t.Fatalf("case %d: expected ErrInvalidDisruption, got %v", i, err)
+		}
+	}
+
+	offered, err := svc.Offer(ctx, 3, DisruptionOfferRequest{Options: []string{"rebook", "credit", "rebook"}, AlternativeVoyageIDs: []int64{6, 6}, CreditBonusPercent: 10})
+	if err != nil || offered != 2 {
+		t.Fatalf("offer: offered=%d err=%v", offered, err)
+	}
+	if strings.Join(repo.offer.Options, ",") != "rebook,credit" || len(repo.offer.AlternativeVoyageIDs) != 1 {
+		t.Fatalf("options and voyages should be de-duplicated: %+v", repo.offer)
+	}
+	if len(repo.offeredIDs) != 2 || repo.offeredIDs[0] != 31 || repo.offeredIDs[1] != 32 {
+		t.Fatalf("only unresolved bookings should be offered, got %v", repo.offeredIDs)
+	}
+
+	repo.disruption.Status = domain.VoyageDisruptionStatusClosed
+	if _, err := svc.Offer(ctx, 3, DisruptionOfferRequest{Options: []string{"refund"}}); !errors.Is(err, ErrDisruptionConflict) {
+		t.Fatalf("expected ErrDisruptionConflict for closed disruption, got %v", err)
+	}
+	if _, err := svc.Offer(ctx, 404, DisruptionOfferRequest{Options: []string{"refund"}}); !errors.Is(err, ErrDisruptionNotFound) {
+		t.Fatalf("expected ErrDisruptionNotFound, got %v", err)
+	}
+}
+
+func TestVoyageDisruptionService_ResolveRebookMatchesCabinTypeAndPrices(t *testing.T) {
+	svc, repo, price := newVoyageDisruptionTestService()
+	results, err := svc.Resolve(context.Background(), 3, DisruptionResolveRequest{BookingIDs: []int64{501, 502, 503}, Resolution: "rebook", TargetVoyageID: 6}, 9)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 3 || results[0].Error != "" || results[1].Error != "" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[2].Error != domain.ErrDisruptionBookingResolved.Error() {
+		t.Fatalf("resolved booking should be reported, got %+v", results[2])
+	}
+	res := repo.resolutions[31]
+	if res.TargetVoyageID != 6 || res.OperatorID != 9 || len(res.Candidates) != 2 {
+		t.Fatalf("unexpected resolution: %+v", res)
+	}
+	// 同舱型在售舱房：61 有价格，64 未配置价格时沿用原订单金额；停售的 62 与其他舱型排除。
+	if res.Candidates[0] != (domain.DisruptionRebookCandidate{CabinSKUID: 61, TotalCents: 520000}) ||
+		res.Candidates[1] != (domain.DisruptionRebookCandidate{CabinSKUID: 64, TotalCents: 500000}) {
+		t.Fatalf("unexpected candidates: %+v", res.Candidates)
+	}
+	if second := repo.resolutions[32]; len(second.Candidates) != 1 || second.Candidates[0].CabinSKUID != 63 {
+		t.Fatalf("unexpected candidates for second booking: %+v", second.Candidates)
+	}
+	if price.occupancy != disruptionDefaultOccupancy || !price.date.Equal(time.Date(2026, 8, 8, 16, 0, 0, 0, time.UTC)) {
+		t.Fatalf("price lookup should use target depart date and default occupancy, got %v %d", price.date, price.occupancy)
+	}
+	if len(repo.notices) != 2 || repo.notices[0].Template != disruptionResolvedTemplate {
+		t.Fatalf("unexpected notices: %+v", repo.notices)
+	}
+
+	if _, err := svc.Resolve(context.Background(), 3, DisruptionResolveRequest{Resolution: "rebook", TargetVoyageID: 6, TargetCabinSKUID: 99}, 9); !errors.Is(err, ErrInvalidDisruption) {
+		t.Fatalf("expected ErrInvalidDisruption for foreign cabin, got %v", err)
+	}
+	if _, err := svc.Resolve(context.Background(), 3, DisruptionResolveRequest{Resolution: "cash"}, 9); !errors.Is(err, ErrInvalidDisruption) {
+		t.Fatalf("expected ErrInvalidDisruption for unknown resolution, got %v", err)
+	}
+}
+
+func TestVoyageDisruptionService_ResolveReportsPerBookingFailures(t *testing.T) {
+	svc, repo, _ := newVoyageDisruptionTestService()
+	results, err := svc.Resolve(context.Background(), 3, DisruptionResolveRequest{Resolution: "credit", CreditBonusPercent: 10}, 9)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(results) != 2 || results[0].Error != "" || !strings.Contains(results[1].Error, "no paid amount") {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	res := repo.resolutions[31]
+	if !res.CreditExpiresAt.Equal(svc.now().Add(defaultTravelCreditValidity)) || res.CreditBonusPercent != 10 {
+		t.Fatalf("unexpected credit resolution: %+v", res)
+	}
+
+	repo.resolveErr = domain.ErrNoRebookCabin
+	results, err = svc.Resolve(context.Background(), 3, DisruptionResolveRequest{BookingIDs: []int64{501}, Resolution: "refund"}, 9)
+	if err != nil || !strings.Contains(results[0].Error, ErrDisruptionConflict.Error()) {
+		t.Fatalf("repository conflicts should be reported per booking, got %+v (%v)", results, err)
+	}
+}
+
+func TestVoyageDisruptionService_AcceptOffer(t *testing.T) {
+	svc, repo, _ := newVoyageDisruptionTestService()
+	ctx := context.Background()
+	if _, err := svc.Accept(ctx, 99, 32, "refund", 0); !errors.Is(err, ErrDisruptionNotFound) {
+		t.Fatalf("expected ErrDisruptionNotFound for another user's booking, got %v", err)
+	}
+	if _, err := svc.Accept(ctx, 11, 31, "refund", 0); !errors.Is(err, ErrDisruptionConflict) {
+		t.Fatalf("expected ErrDisruptionConflict without offer, got %v", err)
+	}
+	if _, err := svc.Accept(ctx, 12, 32, "credit", 0); !errors.Is(err, ErrInvalidDisruption) {
+		t.Fatalf("expected ErrInvalidDisruption for option not offered, got %v", err)
+	}
+	if _, err := svc.Accept(ctx, 12, 32, "rebook", 7); !errors.Is(err, ErrInvalidDisruption) {
+		t.Fatalf("expected ErrInvalidDisruption for voyage not offered, got %v", err)
+	}
+	item, err := svc.Accept(ctx, 12, 32, "rebook", 6)
+	if err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if item.Resolution != domain.DisruptionResolutionRebook || item.NewVoyageID != 6 {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+	res := repo.resolutions[32]
+	if res.OperatorID != 0 || !res.WaivePriceDifference || len(res.Candidates) != 1 || res.Candidates[0].CabinSKUID != 63 {
+		t.Fatalf("offer terms should carry over: %+v", res)
+	}
+	if res.Candidates[0].TotalCents != 280000 {
+		t.Fatalf("unexpected rebook price %d", res.Candidates[0].TotalCents)
+	}
+}
+
+func TestVoyageDisruptionService_CloseTranslatesUnresolved(t *testing.T) {
+	svc, _, _ := newVoyageDisruptionTestService()
+	if _, err := svc.Close(context.Background(), 3); !errors.Is(err, ErrDisruptionConflict) {
+		t.Fatalf("expected ErrDisruptionConflict, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+)
+
+const (
+	voyageCodeMaxLen   = 50 // voyages.code 列长度
+	cabinSKUCodeMaxLen = 80 // cabin_skus.code 列长度
+)
+
+// cloneVoyageDraft 以 source 为蓝本生成出发日为 day、编码为 code 的航次草稿。
+// 出发/返航时刻与航次天数沿用 source，行程按 DayNo 原样复制；舱位编号按新航次编码改写，
+// 日历价格按出发日偏移天数平移。返回的草稿不含价格版本，由调用方按场景补充。
+func cloneVoyageDraft(source *domain.Voyage, code string, day time.Time, cabins []domain.VoyageDraftCabin) (domain.VoyageDraft, error) {
+	if len(code) > voyageCodeMaxLen {
+		return domain.VoyageDraft{}, fmt.Errorf("voyage code %s exceeds %d characters", code, voyageCodeMaxLen)
+	}
+	offset := calendarDaysBetween(source.DepartDate, day)
+	voyage := domain.Voyage{
+		CruiseID:                 source.CruiseID,
+		Code:                     code,
+		ImageURL:                 source.ImageURL,
+		BriefInfo:                source.BriefInfo,
+		DepartDate:               withClockOf(day, source.DepartDate),
+		ReturnDate:               withClockOf(day.AddDate(0, 0, calendarDaysBetween(source.DepartDate, source.ReturnDate)), source.ReturnDate),
+		Status:                   source.Status,
+		FeeNoteTemplateID:        source.FeeNoteTemplateID,
+		FeeNoteMode:              source.FeeNoteMode,
+		FeeNoteContentJSON:       source.FeeNoteContentJSON,
+		BookingNoticeTemplateID:  source.BookingNoticeTemplateID,
+		BookingNoticeMode:        source.BookingNoticeMode,
+		BookingNoticeContentJSON: source.BookingNoticeContentJSON,
+		Itineraries:              make([]domain.VoyageItinerary, len(source.Itineraries)),
+	}
+	for i, item := range source.Itineraries {
+		item.ID, item.VoyageID = 0, 0
+		item.CreatedAt, item.UpdatedAt = time.Time{}, time.Time{}
+		voyage.Itineraries[i] = item
+	}
+
+	draft := domain.VoyageDraft{Voyage: voyage, Cabins: make([]domain.VoyageDraftCabin, len(cabins))}
+	for i, cabin := range cabins {
+		cabin.SKU.ID, cabin.SKU.VoyageID = 0, 0
+		cabin.SKU.CreatedAt, cabin.SKU.UpdatedAt = time.Time{}, time.Time{}
+		cabin.SKU.Code = cloneCabinSKUCode(source.Code, code, cabin.SKU.Code)
+		if len(cabin.SKU.Code) > cabinSKUCodeMaxLen {
+			return domain.VoyageDraft{}, fmt.Errorf("cabin code %s exceeds %d characters", cabin.SKU.Code, cabinSKUCodeMaxLen)
+		}
+		if len(cabin.Prices) > 0 {
+			prices := make([]domain.CabinPrice, len(cabin.Prices))
+			for j, price := range cabin.Prices {
+				price.ID, price.CabinSKUID = 0, 0
+				price.CreatedAt, price.UpdatedAt = time.Time{}, time.Time{}
+				price.Date = price.Date.AddDate(0, 0, offset)
+				prices[j] = price
+			}
+			cabin.Prices = prices
+		}
+		draft.Cabins[i] = cabin
+	}
+	return draft, nil
+}
+
+// cloneCabinSKUCode 生成克隆舱位的编号：以源航次编码开头的编号替换为新航次编码，否则以新航次编码作前缀。
+func cloneCabinSKUCode(sourceCode, voyageCode, skuCode string) string {
+	if sourceCode != "" && strings.HasPrefix(skuCode, sourceCode) {
+		return voyageCode + strings.TrimPrefix(skuCode, sourceCode)
+	}
+	return voyageCode + "-" + skuCode
+}
+
+// withClockOf 返回 day 所在日期、clock 时刻与时区的时间。
+func withClockOf(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, clock.Location())
+}
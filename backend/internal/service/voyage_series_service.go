@@ -0,0 +1,494 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	maxVoyageSeriesOccurrences = 120 // 单个系列最多生成的航次数
+	maxVoyageSeriesInterval    = 52  // 重复间隔上限（天/周/月）
+	voyageSeriesCodeDateLayout = "20060102"
+)
+
+var (
+	// ErrInvalidVoyageSeries 表示系列模板、重复规则或初始价格不合法。
+	ErrInvalidVoyageSeries = errors.New("invalid voyage series")
+	// ErrVoyageSeriesNotFound 表示航次系列不存在。
+	ErrVoyageSeriesNotFound = errors.New("voyage series not found")
+	// ErrVoyageSeriesConflict 表示生成的航次编码或舱位编号已被占用。
+	ErrVoyageSeriesConflict = errors.New("voyage series code conflict")
+)
+
+// voyageSeriesCodePrefixPattern 校验航次编码前缀：大写字母、数字、连字符与下划线。
+var voyageSeriesCodePrefixPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]*$`)
+
+// VoyageSeriesRepo 定义航次系列服务所需的数据访问能力。
+type VoyageSeriesRepo interface {
+	domain.VoyageSeriesRepository
+}
+
+// VoyageSeriesTemplateSource 提供模板航次（含行程）。
+type VoyageSeriesTemplateSource interface {
+	GetByID(ctx context.Context, id int64) (*domain.Voyage, error)
+}
+
+// VoyageSeriesCabinSource 提供模板航次的舱位 SKU 与库存。
+type VoyageSeriesCabinSource interface {
+	ListSKUByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error)
+	GetInventoryBySKU(ctx context.Context, skuID int64) (domain.CabinInventory, error)
+}
+
+// VoyageSeriesPriceSource 提供模板航次的当前舱型价格，未指定初始价格时沿用。
+type VoyageSeriesPriceSource interface {
+	ListCurrentByVoyages(ctx context.Context, voyageIDs []int64) ([]domain.VoyageCabinTypeCurrent, error)
+}
+
+// VoyageSeriesCabinTypeBindings 提供邮轮可售舱型，用于校验初始价格中的舱型。
+type VoyageSeriesCabinTypeBindings interface {
+	ListCabinTypeIDsByCruise(ctx context.Context, cruiseID int64) ([]int64, error)
+}
+
+// VoyageSeriesOccurrence 是预览中的一个待生成航次。
+type VoyageSeriesOccurrence struct {
+	Code       string    `json:"code"`                // 航次编码
+	DepartDate time.Time `json:"depart_date"`         // 出发日期
+	ReturnDate time.Time `json:"return_date"`         // 返航日期
+	Conflicts  []string  `json:"conflicts,omitempty"` // 已被占用的航次编码或舱位编号
+}
+
+// VoyageSeriesPreview 汇总按模板与重复规则将生成的航次，供确认后再批量创建。
+type VoyageSeriesPreview struct {
+	TemplateVoyageID   int64                           `json:"template_voyage_id"`    // 模板航次 ID
+	CruiseID           int64                           `json:"cruise_id"`             // 执行邮轮 ID
+	DurationDays       int                             `json:"duration_days"`         // 每个航次的天数（含出发与返航日）
+	CabinSKUsPerVoyage int                             `json:"cabin_skus_per_voyage"` // 每个航次克隆的舱位数
+	CabinPrices        []domain.VoyageSeriesCabinPrice `json:"cabin_prices"`          // 初始舱型库存与价格
+	Schedule           *domain.VoyageSchedule          `json:"schedule,omitempty"`    // 首个航次的行程时间表校验结果
+	Occurrences        []VoyageSeriesOccurrence        `json:"occurrences"`           // 待生成航次
+	ConflictCount      int                             `json:"conflict_count"`        // 存在编码冲突的航次数
+}
+
+// voyageSeriesPlan 是预览与创建共用的展开结果。
+type voyageSeriesPlan struct {
+	preview *VoyageSeriesPreview
+	drafts  []domain.VoyageDraft
+}
+
+// VoyageSeriesService 按模板航次与重复规则预览并批量生成航次。
+type VoyageSeriesService struct {
+	repo     VoyageSeriesRepo
+	voyages  VoyageSeriesTemplateSource
+	cabins   VoyageSeriesCabinSource
+	prices   VoyageSeriesPriceSource
+	bindings VoyageSeriesCabinTypeBindings
+	now      func() time.Time
+}
+
+// NewVoyageSeriesService 创建航次系列服务实例。
+func NewVoyageSeriesService(repo VoyageSeriesRepo, voyages VoyageSeriesTemplateSource, cabins VoyageSeriesCabinSource, prices VoyageSeriesPriceSource, bindings VoyageSeriesCabinTypeBindings) *VoyageSeriesService {
+	return &VoyageSeriesService{repo: repo, voyages: voyages, cabins: cabins, prices: prices, bindings: bindings, now: time.Now}
+}
+
+// Preview 展开重复规则，返回将生成的航次编码、日期及编码冲突，不写入数据。
+func (s *VoyageSeriesService) Preview(ctx context.Context, series *domain.VoyageSeries) (*VoyageSeriesPreview, error) {
+	plan, err := s.plan(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+	return plan.preview, nil
+}
+
+// Create 在一个事务内创建系列及全部航次：克隆模板行程与舱位 SKU（含库存），并写入立即生效的初始价格版本。
+// 任一航次编码或舱位编号已被占用时整体拒绝。
+func (s *VoyageSeriesService) Create(ctx context.Context, series *domain.VoyageSeries, staffID int64) (*domain.VoyageSeries, error) {
+	plan, err := s.plan(ctx, series)
+	if err != nil {
+		return nil, err
+	}
+	if plan.preview.ConflictCount > 0 {
+		var taken []string
+		for _, occurrence := range plan.preview.Occurrences {
+			taken = append(taken, occurrence.Conflicts...)
+		}
+		return nil, fmt.Errorf("%w: %s already exist", ErrVoyageSeriesConflict, strings.Join(taken, ","))
+	}
+	effectiveAt := s.now()
+	var createdBy *int64
+	if staffID > 0 {
+		createdBy = &staffID
+	}
+	for i := range plan.drafts {
+		for j := range plan.drafts[i].PriceVersions {
+			plan.drafts[i].PriceVersions[j].EffectiveAt = effectiveAt
+			plan.drafts[i].PriceVersions[j].CreatedBy = createdBy
+		}
+	}
+	series.CreatedBy = createdBy
+	if err := s.repo.CreateWithVoyages(ctx, series, plan.drafts); err != nil {
+		if errors.Is(err, domain.ErrVoyageCodeConflict) {
+			return nil, fmt.Errorf("%w: %v", ErrVoyageSeriesConflict, err)
+		}
+		return nil, err
+	}
+	series.Voyages = make([]domain.Voyage, 0, len(plan.drafts))
+	for _, draft := range plan.drafts {
+		series.Voyages = append(series.Voyages, draft.Voyage)
+	}
+	return series, nil
+}
+
+// Get 查询航次系列及其航次。
+func (s *VoyageSeriesService) Get(ctx context.Context, id int64) (*domain.VoyageSeries, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVoyageSeriesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	voyages, err := s.repo.ListVoyages(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	series.Voyages = voyages
+	return series, nil
+}
+
+// List 分页查询航次系列。
+func (s *VoyageSeriesService) List(ctx context.Context, page, pageSize int) ([]domain.VoyageSeries, int64, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	return s.repo.List(ctx, page, pageSize)
+}
+
+// plan 校验系列参数并加载模板，展开出发日期后生成航次草稿与预览。
+func (s *VoyageSeriesService) plan(ctx context.Context, series *domain.VoyageSeries) (*voyageSeriesPlan, error) {
+	if err := normalizeVoyageSeries(series); err != nil {
+		return nil, err
+	}
+	template, err := s.voyages.GetByID(ctx, series.TemplateVoyageID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: template voyage %d not found", ErrInvalidVoyageSeries, series.TemplateVoyageID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if template.DepartDate.IsZero() || template.ReturnDate.IsZero() || len(template.Itineraries) == 0 {
+		return nil, fmt.Errorf("%w: template voyage must have dates and itineraries", ErrInvalidVoyageSeries)
+	}
+	series.CruiseID = template.CruiseID
+
+	prices, err := s.resolveCabinPrices(ctx, series, template)
+	if err != nil {
+		return nil, err
+	}
+	cabins, err := s.templateCabins(ctx, template, prices)
+	if err != nil {
+		return nil, err
+	}
+	departures, err := expandVoyageSeriesDates(series)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := calendarDaysBetween(template.DepartDate, template.ReturnDate)
+	preview := &VoyageSeriesPreview{
+		TemplateVoyageID:   template.ID,
+		CruiseID:           template.CruiseID,
+		DurationDays:       duration + 1,
+		CabinSKUsPerVoyage: len(cabins),
+		CabinPrices:        prices,
+		Occurrences:        make([]VoyageSeriesOccurrence, 0, len(departures)),
+	}
+	plan := &voyageSeriesPlan{preview: preview, drafts: make([]domain.VoyageDraft, 0, len(departures))}
+	for i, day := range departures {
+		draft, err := cloneVoyageDraft(template, series.CodePrefix+day.Format(voyageSeriesCodeDateLayout), day, cabins)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidVoyageSeries, err)
+		}
+		draft.PriceVersions = seriesPriceVersions(prices)
+		schedule, err := buildItinerarySchedule(&draft.Voyage)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			preview.Schedule = schedule
+		}
+		plan.drafts = append(plan.drafts, draft)
+		preview.Occurrences = append(preview.Occurrences, VoyageSeriesOccurrence{
+			Code:       draft.Voyage.Code,
+			DepartDate: draft.Voyage.DepartDate,
+			ReturnDate: draft.Voyage.ReturnDate,
+		})
+	}
+	if err := s.markConflicts(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// resolveCabinPrices 校验初始舱型价格；未指定时沿用模板航次的当前价。舱型须已绑定到模板邮轮。
+func (s *VoyageSeriesService) resolveCabinPrices(ctx context.Context, series *domain.VoyageSeries, template *domain.Voyage) ([]domain.VoyageSeriesCabinPrice, error) {
+	prices := series.CabinPrices
+	if len(prices) == 0 {
+		current, err := s.prices.ListCurrentByVoyages(ctx, []int64{template.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range current {
+			prices = append(prices, domain.VoyageSeriesCabinPrice{
+				CabinTypeID:          item.CabinTypeID,
+				InventoryTotal:       item.InventoryTotal,
+				SettlementPriceCents: item.SettlementPriceCents,
+				SalePriceCents:       item.SalePriceCents,
+			})
+		}
+	}
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("%w: cabin_prices is required when the template voyage has no current prices", ErrInvalidVoyageSeries)
+	}
+	allowed, err := s.bindings.ListCabinTypeIDsByCruise(ctx, template.CruiseID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool, len(prices))
+	for _, price := range prices {
+		switch {
+		case price.CabinTypeID <= 0:
+			return nil, fmt.Errorf("%w: cabin_type_id is required", ErrInvalidVoyageSeries)
+		case seen[price.CabinTypeID]:
+			return nil, fmt.Errorf("%w: duplicate cabin type %d", ErrInvalidVoyageSeries, price.CabinTypeID)
+		case !slices.Contains(allowed, price.CabinTypeID):
+			return nil, fmt.Errorf("%w: cabin type %d is not bound to cruise %d", ErrInvalidVoyageSeries, price.CabinTypeID, template.CruiseID)
+		case price.InventoryTotal < 0 || price.SettlementPriceCents < 0 || price.SalePriceCents <= 0:
+			return nil, fmt.Errorf("%w: invalid inventory or price for cabin type %d", ErrInvalidVoyageSeries, price.CabinTypeID)
+		}
+		seen[price.CabinTypeID] = true
+	}
+	series.CabinPrices = prices
+	return prices, nil
+}
+
+// templateCabins 取模板航次中属于初始价格舱型的 SKU 及其库存总量；库存为 0 的价格按克隆舱位库存汇总。
+func (s *VoyageSeriesService) templateCabins(ctx context.Context, template *domain.Voyage, prices []domain.VoyageSeriesCabinPrice) ([]domain.VoyageDraftCabin, error) {
+	skus, err := s.cabins.ListSKUByVoyage(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+	priced := make(map[int64]int, len(prices))
+	for i, price := range prices {
+		priced[price.CabinTypeID] = i
+	}
+	totals := make(map[int64]int, len(prices))
+	cabins := make([]domain.VoyageDraftCabin, 0, len(skus))
+	for _, sku := range skus {
+		if _, ok := priced[sku.CabinTypeID]; !ok {
+			continue
+		}
+		inventory, err := s.cabins.GetInventoryBySKU(ctx, sku.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		total := inventory.Total
+		if err != nil {
+			total = 1
+		}
+		cabins = append(cabins, domain.VoyageDraftCabin{SKU: sku, InventoryTotal: total, AlertThreshold: inventory.AlertThreshold})
+		totals[sku.CabinTypeID] += total
+	}
+	for cabinTypeID, idx := range priced {
+		if prices[idx].InventoryTotal == 0 {
+			prices[idx].InventoryTotal = totals[cabinTypeID]
+		}
+	}
+	return cabins, nil
+}
+
+// markConflicts 标记编码已被占用的航次。
+func (s *VoyageSeriesService) markConflicts(ctx context.Context, plan *voyageSeriesPlan) error {
+	var voyageCodes, skuCodes []string
+	for _, draft := range plan.drafts {
+		voyageCodes = append(voyageCodes, draft.Voyage.Code)
+		for _, cabin := range draft.Cabins {
+			skuCodes = append(skuCodes, cabin.SKU.Code)
+		}
+	}
+	takenVoyages, err := s.repo.FindExistingVoyageCodes(ctx, voyageCodes)
+	if err != nil {
+		return err
+	}
+	takenSKUs, err := s.repo.FindExistingSKUCodes(ctx, skuCodes)
+	if err != nil {
+		return err
+	}
+	if len(takenVoyages) == 0 && len(takenSKUs) == 0 {
+		return nil
+	}
+	for i, draft := range plan.drafts {
+		occurrence := &plan.preview.Occurrences[i]
+		if slices.Contains(takenVoyages, draft.Voyage.Code) {
+			occurrence.Conflicts = append(occurrence.Conflicts, draft.Voyage.Code)
+		}
+		for _, cabin := range draft.Cabins {
+			if slices.Contains(takenSKUs, cabin.SKU.Code) {
+				occurrence.Conflicts = append(occurrence.Conflicts, cabin.SKU.Code)
+			}
+		}
+		if len(occurrence.Conflicts) > 0 {
+			plan.preview.ConflictCount++
+		}
+	}
+	return nil
+}
+
+// seriesPriceVersions 将初始舱型价格转换为价格版本，生效时间在创建时统一写入。
+func seriesPriceVersions(prices []domain.VoyageSeriesCabinPrice) []domain.VoyageCabinTypePriceVersion {
+	versions := make([]domain.VoyageCabinTypePriceVersion, 0, len(prices))
+	for _, price := range prices {
+		versions = append(versions, domain.VoyageCabinTypePriceVersion{
+			CabinTypeID:          price.CabinTypeID,
+			InventoryTotal:       price.InventoryTotal,
+			SettlementPriceCents: price.SettlementPriceCents,
+			SalePriceCents:       price.SalePriceCents,
+		})
+	}
+	return versions
+}
+
+// expandVoyageSeriesDates 按重复规则展开 [StartDate, EndDate] 内的出发日期（按日历日计算）。
+func expandVoyageSeriesDates(series *domain.VoyageSeries) ([]time.Time, error) {
+	start := calendarDate(series.StartDate)
+	end := calendarDate(series.EndDate)
+	var dates []time.Time
+	add := func(day time.Time) error {
+		if len(dates) >= maxVoyageSeriesOccurrences {
+			return fmt.Errorf("%w: recurrence produces more than %d voyages", ErrInvalidVoyageSeries, maxVoyageSeriesOccurrences)
+		}
+		dates = append(dates, day)
+		return nil
+	}
+	switch series.Frequency {
+	case domain.VoyageSeriesFrequencyDaily:
+		for day := start; !day.After(end); day = day.AddDate(0, 0, series.RepeatInterval) {
+			if err := add(day); err != nil {
+				return nil, err
+			}
+		}
+	case domain.VoyageSeriesFrequencyWeekly:
+		weekdays := parseSeriesWeekdays(series.Weekdays)
+		weekStart := start.AddDate(0, 0, -int(start.Weekday()))
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			week := calendarDaysBetween(weekStart, day) / 7
+			if week%series.RepeatInterval == 0 && slices.Contains(weekdays, int(day.Weekday())) {
+				if err := add(day); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case domain.VoyageSeriesFrequencyMonthly:
+		for months := 0; ; months += series.RepeatInterval {
+			day := time.Date(start.Year(), start.Month()+time.Month(months), start.Day(), 0, 0, 0, 0, time.UTC)
+			if day.After(end) {
+				break
+			}
+			// 当月没有该日期（如 31 日）时跳过
+			if day.Day() != start.Day() {
+				continue
+			}
+			if err := add(day); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("%w: recurrence produces no voyages between start_date and end_date", ErrInvalidVoyageSeries)
+	}
+	return dates, nil
+}
+
+// normalizeVoyageSeries 规范化并校验系列参数；weekly 未指定星期时取开始日期的星期。
+func normalizeVoyageSeries(series *domain.VoyageSeries) error {
+	if series == nil {
+		return fmt.Errorf("%w: series is required", ErrInvalidVoyageSeries)
+	}
+	series.Name = strings.TrimSpace(series.Name)
+	series.CodePrefix = strings.ToUpper(strings.TrimSpace(series.CodePrefix))
+	series.Frequency = strings.ToLower(strings.TrimSpace(series.Frequency))
+	switch {
+	case series.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidVoyageSeries)
+	case series.TemplateVoyageID <= 0:
+		return fmt.Errorf("%w: template_voyage_id is required", ErrInvalidVoyageSeries)
+	case len(series.CodePrefix) > 30 || !voyageSeriesCodePrefixPattern.MatchString(series.CodePrefix):
+		return fmt.Errorf("%w: code_prefix must be 1-30 letters, digits, '-' or '_'", ErrInvalidVoyageSeries)
+	case series.StartDate.IsZero() || series.EndDate.IsZero():
+		return fmt.Errorf("%w: start_date and end_date are required", ErrInvalidVoyageSeries)
+	case calendarDate(series.EndDate).Before(calendarDate(series.StartDate)):
+		return fmt.Errorf("%w: end_date must not be before start_date", ErrInvalidVoyageSeries)
+	}
+	if series.RepeatInterval == 0 {
+		series.RepeatInterval = 1
+	}
+	if series.RepeatInterval < 0 || series.RepeatInterval > maxVoyageSeriesInterval {
+		return fmt.Errorf("%w: repeat_interval must be between 1 and %d", ErrInvalidVoyageSeries, maxVoyageSeriesInterval)
+	}
+	switch series.Frequency {
+	case domain.VoyageSeriesFrequencyDaily, domain.VoyageSeriesFrequencyMonthly:
+		series.Weekdays = ""
+	case domain.VoyageSeriesFrequencyWeekly:
+		if strings.TrimSpace(series.Weekdays) == "" {
+			series.Weekdays = strconv.Itoa(int(series.StartDate.Weekday()))
+		}
+		weekdays := parseSeriesWeekdays(series.Weekdays)
+		if weekdays == nil {
+			return fmt.Errorf("%w: weekdays must be comma separated numbers 0-6", ErrInvalidVoyageSeries)
+		}
+		parts := make([]string, len(weekdays))
+		for i, day := range weekdays {
+			parts[i] = strconv.Itoa(day)
+		}
+		series.Weekdays = strings.Join(parts, ",")
+	default:
+		return fmt.Errorf("%w: frequency must be daily, weekly or monthly", ErrInvalidVoyageSeries)
+	}
+	return nil
+}
+
+// parseSeriesWeekdays 解析逗号分隔的星期（0=周日），返回去重排序结果；存在非法值时返回 nil。
+func parseSeriesWeekdays(raw string) []int {
+	var days []int
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		day, err := strconv.Atoi(part)
+		if err != nil || day < 0 || day > 6 {
+			return nil
+		}
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+		}
+	}
+	slices.Sort(days)
+	return days
+}
+
+// calendarDate 返回 t 所在日历日的 UTC 零点，用于按日期而非时刻比较。
+func calendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
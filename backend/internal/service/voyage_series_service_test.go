@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
+)
+
+// voyageSeriesRepoStub 记录批量创建请求，并按预置集合报告已占用编码。
+type voyageSeriesRepoStub struct {
+	takenVoyages []string
+	takenSKUs    []string
+	created      *domain.VoyageSeries
+	drafts       []domain.VoyageDraft
+}
+
+func (s *voyageSeriesRepoStub) CreateWithVoyages(_ context.Context, series *domain.VoyageSeries, drafts []domain.VoyageDraft) error {
+	series.ID = 11
+	series.VoyageCount = len(drafts)
+	s.created, s.drafts = series, drafts
+	return nil
+}
+func (s *voyageSeriesRepoStub) CreateDraft(context.Context, *domain.VoyageDraft) error {
+	return errors.New("unexpected single draft")
+}
+func (s *voyageSeriesRepoStub) GetByID(_ context.Context, id int64) (*domain.VoyageSeries, error) {
+	if s.created == nil || s.created.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copySeries := *s.created
+	return &copySeries, nil
+}
+func (s *voyageSeriesRepoStub) List(context.Context, int, int) ([]domain.VoyageSeries, int64, error) {
+	return []domain.VoyageSeries{}, 0, nil
+}
+func (s *voyageSeriesRepoStub) ListVoyages(context.Context, int64) ([]domain.Voyage, error) {
+	voyages := make([]domain.Voyage, 0, len(s.drafts))
+	for _, draft := range s.drafts {
+		voyages = append(voyages, draft.Voyage)
+	}
+	return voyages, nil
+}
+func (s *voyageSeriesRepoStub) FindExistingVoyageCodes(_ context.Context, codes []string) ([]string, error) {
+	return intersectCodes(codes, s.takenVoyages), nil
+}
+func (s *voyageSeriesRepoStub) FindExistingSKUCodes(_ context.Context, codes []string) ([]string, error) {
+	return intersectCodes(codes, s.takenSKUs), nil
+}
+
+func intersectCodes(codes, taken []string) []string {
+	out := []string{}
+	for _, code := range codes {
+		if slices.Contains(taken, code) {
+			out = append(out, code)
+		}
+	}
+	return out
+}
+
+type seriesTemplateStub struct{ voyage *domain.Voyage }
+
+func (s seriesTemplateStub) GetByID(_ context.Context, id int64) (*domain.Voyage, error) {
+	if s.voyage == nil || s.voyage.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copyVoyage := *s.voyage
+	return &copyVoyage, nil
+}
+
+type seriesCabinStub struct {
+	skus        []domain.CabinSKU
+	inventories map[int64]domain.CabinInventory
+}
+
+func (s seriesCabinStub) ListSKUByVoyage(context.Context, int64) ([]domain.CabinSKU, error) {
+	return s.skus, nil
+}
+func (s seriesCabinStub) GetInventoryBySKU(_ context.Context, skuID int64) (domain.CabinInventory, error) {
+	if inventory, ok := s.inventories[skuID]; ok {
+		return inventory, nil
+	}
+	return domain.CabinInventory{}, gorm.ErrRecordNotFound
+}
+
+type seriesPriceStub struct {
+	current []domain.VoyageCabinTypeCurrent
+}
+
+func (s seriesPriceStub) ListCurrentByVoyages(context.Context, []int64) ([]domain.VoyageCabinTypeCurrent, error) {
+	return s.current, nil
+}
+
+type seriesBindingStub struct{ cabinTypeIDs []int64 }
+
+func (s seriesBindingStub) ListCabinTypeIDsByCruise(context.Context, int64) ([]int64, error) {
+	return s.cabinTypeIDs, nil
+}
+
+func newVoyageSeriesTestService(repo *voyageSeriesRepoStub) *VoyageSeriesService {
+	shanghai, _ := time.LoadLocation("Asia/Shanghai")
+	eta, etd := "08:00", "17:00"
+	template := &domain.Voyage{
+		ID: 5, CruiseID: 7, Code: "SPEC-20260405", BriefInfo: "上海-福冈-上海", Status: 1,
+		DepartDate: time.Date(2026, 4, 5, 16, 0, 0, 0, shanghai), ReturnDate: time.Date(2026, 4, 8, 8, 0, 0, 0, shanghai),
+		FeeNoteTemplateID: 3, FeeNoteMode: domain.VoyageContentModeTemplate,
+		Itineraries: []domain.VoyageItinerary{
+			{ID: 101, VoyageID: 5, DayNo: 1, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai", ETDTime: &etd},
+			{ID: 102, VoyageID: 5, DayNo: 2, StopIndex: 1, City: "海上巡游"},
+			{ID: 103, VoyageID: 5, DayNo: 3, StopIndex: 1, City: "福冈", Timezone: "Asia/Tokyo", ETATime: &eta, ETDTime: &etd},
+			{ID: 104, VoyageID: 5, DayNo: 4, StopIndex: 1, City: "上海", Timezone: "Asia/Shanghai", ETATime: &eta},
+		},
+	}
+	cabins := seriesCabinStub{
+		skus: []domain.CabinSKU{
+			{ID: 1, VoyageID: 5, CabinTypeID: 31, Code: "SPEC-20260405-8001", Deck: "8", Status: 1},
+			{ID: 2, VoyageID: 5, CabinTypeID: 31, Code: "B8002", Deck: "8", Status: 1},
+			{ID: 3, VoyageID: 5, CabinTypeID: 32, Code: "SPEC-20260405-9001", Deck: "9", Status: 1},
+		},
+		inventories: map[int64]domain.CabinInventory{1: {CabinSKUID: 1, Total: 2, Sold: 1, AlertThreshold: 1}},
+	}
+	prices := seriesPriceStub{current: []domain.VoyageCabinTypeCurrent{
+		{VoyageID: 5, CabinTypeID: 31, InventoryTotal: 0, SettlementPriceCents: 300000, SalePriceCents: 399900},
+	}}
+	return NewVoyageSeriesService(repo, seriesTemplateStub{voyage: template}, cabins, prices, seriesBindingStub{cabinTypeIDs: []int64{31, 32}})
+}
+
+func weeklySeries() *domain.VoyageSeries {
+	return &domain.VoyageSeries{
+		Name: "五月周日航次", TemplateVoyageID: 5, CodePrefix: " spec-", Frequency: "weekly", Weekdays: "0, 3",
+		StartDate: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2026, 5, 17, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestVoyageSeriesServicePreviewExpandsWeeklyRecurrence(t *testing.T) {
+	repo := &voyageSeriesRepoStub{takenSKUs: []string{"SPEC-20260510-B8002"}}
+	svc := newVoyageSeriesTestService(repo)
+
+	preview, err := svc.Preview(context.Background(), weeklySeries())
+	if err != nil {
+		t.Fatalf("Preview returned error: %v", err)
+	}
+	var codes []string
+	for _, occurrence := range preview.Occurrences {
+		codes = append(codes, occurrence.Code)
+	}
+	want := []string{"SPEC-20260503", "SPEC-20260506", "SPEC-20260510", "SPEC-20260513", "SPEC-20260517"}
+	if !slices.Equal(codes, want) {
+		t.Fatalf("expected codes %v, got %v", want, codes)
+	}
+	first := preview.Occurrences[0]
+	if first.DepartDate.Hour() != 16 || first.ReturnDate.Format("2006-01-02 15:04") != "2026-05-06 08:00" {
+		t.Fatalf("expected template clock and 4-day duration, got %s - %s", first.DepartDate, first.ReturnDate)
+	}
+	if preview.DurationDays != 4 || preview.CabinSKUsPerVoyage != 2 {
+		t.Fatalf("expected 4 days and 2 cloned cabins of the priced type, got %+v", preview)
+	}
+	if preview.CabinPrices[0].InventoryTotal != 3 {
+		t.Fatalf("expected inventory total summed from cloned cabins, got %+v", preview.CabinPrices)
+	}
+	if preview.ConflictCount != 1 || !slices.Equal(preview.Occurrences[2].Conflicts, []string{"SPEC-20260510-B8002"}) {
+		t.Fatalf("expected conflict on 05-10 occurrence, got %+v", preview.Occurrences)
+	}
+	if preview.Schedule == nil || preview.Schedule.SeaDays != 1 {
+		t.Fatalf("expected schedule with one sea day, got %+v", preview.Schedule)
+	}
+}
+
+func TestVoyageSeriesServiceCreateClonesTemplate(t *testing.T) {
+	repo := &voyageSeriesRepoStub{}
+	svc := newVoyageSeriesTestService(repo)
+	now := time.Date(2026, 4, 20, 10, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	series := weeklySeries()
+	series.Frequency, series.Weekdays, series.RepeatInterval = "monthly", "", 1
+	series.StartDate, series.EndDate = time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)
+	series.CabinPrices = []domain.VoyageSeriesCabinPrice{{CabinTypeID: 32, InventoryTotal: 5, SettlementPriceCents: 500000, SalePriceCents: 699900}}
+
+	created, err := svc.Create(context.Background(), series, 9)
+	if err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(repo.drafts) != 3 || repo.drafts[1].Voyage.Code != "SPEC-20260331" {
+		t.Fatalf("expected monthly occurrences skipping short months, got %d drafts", len(repo.drafts))
+	}
+	draft := repo.drafts[0]
+	if draft.Voyage.FeeNoteTemplateID != 3 || draft.Voyage.CruiseID != 7 || len(draft.Voyage.Itineraries) != 4 || draft.Voyage.Itineraries[0].ID != 0 {
+		t.Fatalf("expected voyage cloned from template, got %+v", draft.Voyage)
+	}
+	if draft.Voyage.Itineraries[1].Timezone != "Asia/Shanghai" {
+		t.Fatalf("expected resolved timezone on cloned itinerary, got %q", draft.Voyage.Itineraries[1].Timezone)
+	}
+	if len(draft.Cabins) != 1 || draft.Cabins[0].SKU.Code != "SPEC-20260131-9001" || draft.Cabins[0].SKU.ID != 0 || draft.Cabins[0].InventoryTotal != 1 {
+		t.Fatalf("expected one cloned cabin of type 32, got %+v", draft.Cabins)
+	}
+	version := draft.PriceVersions[0]
+	if version.SalePriceCents != 699900 || version.InventoryTotal != 5 || !version.EffectiveAt.Equal(now) || version.CreatedBy == nil || *version.CreatedBy != 9 {
+		t.Fatalf("expected immediately effective price version, got %+v", version)
+	}
+	if created.CruiseID != 7 || len(created.Voyages) != 3 || *created.CreatedBy != 9 {
+		t.Fatalf("unexpected created series: %+v", created)
+	}
+
+	loaded, err := svc.Get(context.Background(), created.ID)
+	if err != nil || len(loaded.Voyages) != 3 {
+		t.Fatalf("expected series detail with voyages, got %+v, %v", loaded, err)
+	}
+	if _, err := svc.Get(context.Background(), 404); !errors.Is(err, ErrVoyageSeriesNotFound) {
+		t.Fatalf("expected ErrVoyageSeriesNotFound, got %v", err)
+	}
+}
+
+func TestVoyageSeriesServiceRejectsConflictsAndInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	repo := &voyageSeriesRepoStub{takenVoyages: []string{"SPEC-20260506"}}
+	svc := newVoyageSeriesTestService(repo)
+	if _, err := svc.Create(ctx, weeklySeries(), 1); !errors.Is(err, ErrVoyageSeriesConflict) {
+		t.Fatalf("expected ErrVoyageSeriesConflict, got %v", err)
+	}
+	if repo.created != nil {
+		t.Fatal("expected nothing to be created on conflict")
+	}
+
+	cases := map[string]func(*domain.VoyageSeries){
+		"missing template": func(s *domain.VoyageSeries) { s.TemplateVoyageID = 99 },
+		"bad prefix":       func(s *domain.VoyageSeries) { s.CodePrefix = "航次" },
+		"bad frequency":    func(s *domain.VoyageSeries) { s.Frequency = "yearly" },
+		"bad weekday":      func(s *domain.VoyageSeries) { s.Weekdays = "7" },
+		"reversed dates":   func(s *domain.VoyageSeries) { s.EndDate = s.StartDate.AddDate(0, 0, -1) },
+		"no occurrences":   func(s *domain.VoyageSeries) { s.Weekdays = "1"; s.EndDate = s.StartDate },
+		"too many":         func(s *domain.VoyageSeries) { s.Frequency = "daily"; s.EndDate = s.StartDate.AddDate(1, 0, 0) },
+		"unbound cabin": func(s *domain.VoyageSeries) {
+			s.CabinPrices = []domain.VoyageSeriesCabinPrice{{CabinTypeID: 99, SalePriceCents: 1}}
+		},
+		"missing price": func(s *domain.VoyageSeries) { s.CabinPrices = []domain.VoyageSeriesCabinPrice{{CabinTypeID: 31}} },
+	}
+	for name, mutate := range cases {
+		series := weeklySeries()
+		mutate(series)
+		if _, err := svc.Preview(ctx, series); !errors.Is(err, ErrInvalidVoyageSeries) {
+			t.Fatalf("%s: expected ErrInvalidVoyageSeries, got %v", name, err)
+		}
+	}
+}
@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
+// voyageRoutePrefetchTimeout 是保存航次后后台预取航线地图的超时时间，需覆盖逐段请求外部航线服务的耗时。
+const voyageRoutePrefetchTimeout = 2 * time.Minute
+
 type VoyageRouteBuilder interface {
 	BuildVoyageRouteMap(ctx context.Context, itineraries []domain.VoyageItinerary) (*domain.VoyageRouteMap, error)
 }
@@ -14,14 +20,21 @@ type VoyageCityResolver interface {
 	ResolveLabel(ctx context.Context, label string) (*ResolvedPortCity, error)
 }
 
+// VoyageRouteCache 定义行程变更时清理旧航线缓存的能力，由 SeaRouteClient 实现。
+type VoyageRouteCache interface {
+	InvalidateVoyageRoute(ctx context.Context, previous, current []domain.VoyageItinerary) error
+}
+
 type VoyageService struct {
 	repo         domain.VoyageRepository
 	routeBuilder VoyageRouteBuilder
 	cityResolver VoyageCityResolver
+	routeCache   VoyageRouteCache
+	runAsync     func(task func()) // 后台任务执行方式，测试中可替换为同步执行
 }
 
 func NewVoyageService(repo domain.VoyageRepository, routeBuilder VoyageRouteBuilder) *VoyageService {
-	return &VoyageService{repo: repo, routeBuilder: routeBuilder}
+	return &VoyageService{repo: repo, routeBuilder: routeBuilder, runAsync: func(task func()) { go task() }}
 }
 
 func (s *VoyageService) SetCityResolver(resolver VoyageCityResolver) *VoyageService {
@@ -29,6 +42,12 @@ func (s *VoyageService) SetCityResolver(resolver VoyageCityResolver) *VoyageServ
 	return s
 }
 
+// SetRouteCache 启用航线缓存维护：行程变更时清理旧缓存，保存航次后在后台预取航线地图。
+func (s *VoyageService) SetRouteCache(cache VoyageRouteCache) *VoyageService {
+	s.routeCache = cache
+	return s
+}
+
 func (s *VoyageService) List(ctx context.Context) ([]domain.Voyage, error) {
 	return s.repo.List(ctx)
 }
@@ -37,18 +56,42 @@ func (s *VoyageService) ListPublic(ctx context.Context, cruiseID int64, keyword
 	return s.repo.ListPublic(ctx, cruiseID, keyword, page, pageSize)
 }
 
+// Create 补全行程坐标与时区并校验时间表后创建航次，校验结果（含警告）写入 v.Schedule。
 func (s *VoyageService) Create(ctx context.Context, v *domain.Voyage) error {
-	if err := s.enrichItineraryCoordinates(ctx, v); err != nil {
+	schedule, err := s.prepareItineraries(ctx, v)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, v); err != nil {
 		return err
 	}
-	return s.repo.Create(ctx, v)
+	v.Schedule = schedule
+	s.prefetchRouteMap(v.Itineraries)
+	return nil
 }
 
+// Update 补全行程坐标与时区并校验时间表后更新航次，校验结果（含警告）写入 v.Schedule。
 func (s *VoyageService) Update(ctx context.Context, v *domain.Voyage) error {
-	if err := s.enrichItineraryCoordinates(ctx, v); err != nil {
+	schedule, err := s.prepareItineraries(ctx, v)
+	if err != nil {
+		return err
+	}
+	previous := s.loadPreviousItineraries(ctx, v.ID)
+	if err := s.repo.Update(ctx, v); err != nil {
 		return err
 	}
-	return s.repo.Update(ctx, v)
+	v.Schedule = schedule
+	s.invalidateRouteMap(ctx, previous, v.Itineraries)
+	s.prefetchRouteMap(v.Itineraries)
+	return nil
+}
+
+// prepareItineraries 补全行程坐标与港口时区，再校验行程时间表；时间表不合法时返回 *ItineraryScheduleError。
+func (s *VoyageService) prepareItineraries(ctx context.Context, v *domain.Voyage) (*domain.VoyageSchedule, error) {
+	if err := s.enrichItineraryCoordinates(ctx, v); err != nil {
+		return nil, err
+	}
+	return buildItinerarySchedule(v)
 }
 
 func (s *VoyageService) GetByID(ctx context.Context, id int64) (*domain.Voyage, error) {
@@ -69,7 +112,49 @@ func (s *VoyageService) GetByID(ctx context.Context, id int64) (*domain.Voyage,
 }
 
 func (s *VoyageService) Delete(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+	previous := s.loadPreviousItineraries(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateRouteMap(ctx, previous, nil)
+	return nil
+}
+
+// loadPreviousItineraries 读取变更前的行程，用于清理旧航线缓存；未启用缓存或读取失败时返回 nil。
+func (s *VoyageService) loadPreviousItineraries(ctx context.Context, id int64) []domain.VoyageItinerary {
+	if s.routeCache == nil || id <= 0 {
+		return nil
+	}
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil || existing == nil {
+		return nil
+	}
+	return existing.Itineraries
+}
+
+// invalidateRouteMap 清理旧行程对应的航线缓存；失败只记录日志，不影响航次保存。
+func (s *VoyageService) invalidateRouteMap(ctx context.Context, previous, current []domain.VoyageItinerary) {
+	if s.routeCache == nil || len(previous) == 0 {
+		return
+	}
+	if err := s.routeCache.InvalidateVoyageRoute(ctx, previous, current); err != nil {
+		logger.FromContext(ctx).Warn("voyage: invalidate route map cache failed", zap.Error(err))
+	}
+}
+
+// prefetchRouteMap 在后台为已保存的行程规划航线并写入缓存，使首次查看航次详情即可命中缓存。
+func (s *VoyageService) prefetchRouteMap(itineraries []domain.VoyageItinerary) {
+	if s.routeCache == nil || s.routeBuilder == nil || len(itineraries) == 0 {
+		return
+	}
+	snapshot := append([]domain.VoyageItinerary(nil), itineraries...)
+	s.runAsync(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), voyageRoutePrefetchTimeout)
+		defer cancel()
+		if _, err := s.routeBuilder.BuildVoyageRouteMap(ctx, snapshot); err != nil {
+			logger.FromContext(ctx).Warn("voyage: prefetch route map failed", zap.Error(err))
+		}
+	})
 }
 
 func (s *VoyageService) enrichItineraryCoordinates(ctx context.Context, voyage *domain.Voyage) error {
@@ -84,10 +169,12 @@ func (s *VoyageService) enrichItineraryCoordinates(ctx context.Context, voyage *
 		if resolved == nil || resolved.IsSpecial {
 			voyage.Itineraries[index].Latitude = nil
 			voyage.Itineraries[index].Longitude = nil
+			voyage.Itineraries[index].Timezone = ""
 			continue
 		}
 		voyage.Itineraries[index].Latitude = resolved.Latitude
 		voyage.Itineraries[index].Longitude = resolved.Longitude
+		voyage.Itineraries[index].Timezone = resolved.Timezone
 	}
 	return nil
 }
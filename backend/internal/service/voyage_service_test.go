@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cruisebooking/backend/internal/domain"
+	"gorm.io/gorm"
 )
 
 type voyageRepoStub struct {
@@ -44,6 +46,37 @@ func (s *maritimeRouteBuilderStub) BuildVoyageRouteMap(context.Context, []domain
 	return s.routeMap, nil
 }
 
+// portLookupStub 是按中文名精确匹配的内存港口主数据。
+type portLookupStub struct {
+	ports []domain.Port
+}
+
+func (s *portLookupStub) FindByName(_ context.Context, name, country string) (*domain.Port, error) {
+	for _, port := range s.ports {
+		if port.NameZH == name && (country == "" || port.Country == country) {
+			copyPort := port
+			return &copyPort, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *portLookupStub) SearchByKeyword(_ context.Context, keyword string, _ int) ([]domain.Port, error) {
+	items := make([]domain.Port, 0, len(s.ports))
+	for _, port := range s.ports {
+		if strings.Contains(port.NameZH, keyword) || strings.Contains(strings.ToLower(port.Keywords), strings.ToLower(keyword)) {
+			items = append(items, port)
+		}
+	}
+	return items, nil
+}
+
+var testPorts = &portLookupStub{ports: []domain.Port{
+	{Code: "CNSHA", NameZH: "上海", NameEN: "Shanghai", Country: "中国", Timezone: "Asia/Shanghai", Latitude: 31.2304, Longitude: 121.4737, Keywords: "上海,shanghai"},
+	{Code: "JPFUK", NameZH: "福冈", NameEN: "Fukuoka", Country: "日本", Timezone: "Asia/Tokyo", Latitude: 33.5902, Longitude: 130.4017, Keywords: "福冈,博多,fukuoka,hakata"},
+	{Code: "JPNGS", NameZH: "长崎", NameEN: "Nagasaki", Country: "日本", Timezone: "Asia/Tokyo", Latitude: 32.7503, Longitude: 129.8777, Keywords: "长崎,nagasaki"},
+}}
+
 type voyageCityResolverStub struct{}
 
 func (s *voyageCityResolverStub) ResolveLabel(_ context.Context, label string) (*ResolvedPortCity, error) {
@@ -112,6 +145,74 @@ func TestSeaRouteClientBuildVoyageRouteMapUsesExplicitCoordinates(t *testing.T)
 	}
 }
 
+func TestSeaRouteClientFallsBackToOfflineRouter(t *testing.T) {
+	itineraries := []domain.VoyageItinerary{
+		{DayNo: 1, StopIndex: 1, City: "上海"},
+		{DayNo: 2, StopIndex: 1, City: "海上巡游"},
+		{DayNo: 3, StopIndex: 1, City: "福冈"},
+		{DayNo: 4, StopIndex: 1, City: "长崎港"},
+	}
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("upstream down"))
+	}))
+	defer failing.Close()
+
+	for name, endpoint := range map[string]string{"unset endpoint": "", "remote failure": failing.URL} {
+		client := NewSeaRouteClient(SeaRouteClientConfig{Endpoint: endpoint, Timeout: 2 * time.Second, ResolutionKM: 50}).SetPortLookup(testPorts)
+		routeMap, err := client.BuildVoyageRouteMap(context.Background(), itineraries)
+		if err != nil {
+			t.Fatalf("%s: BuildVoyageRouteMap returned error: %v", name, err)
+		}
+		if routeMap == nil || routeMap.Provider != seaRouteProviderOffline || routeMap.GeometryType != "MultiLineString" {
+			t.Fatalf("%s: expected offline route map, got %+v", name, routeMap)
+		}
+		if len(routeMap.Coordinates) != 2 {
+			t.Fatalf("%s: expected one line per leg, got %d", name, len(routeMap.Coordinates))
+		}
+		first := routeMap.Coordinates[0]
+		if first[0][0] != 121.4737 || first[0][1] != 31.2304 {
+			t.Fatalf("%s: route should start at Shanghai in [lon, lat] order, got %v", name, first[0])
+		}
+		if last := routeMap.Coordinates[1][len(routeMap.Coordinates[1])-1]; last[0] != 129.8777 || last[1] != 32.7503 {
+			t.Fatalf("%s: route should end at Nagasaki, got %v", name, last)
+		}
+		if routeMap.DistanceKM < 874 || routeMap.ResolutionKM != 50 {
+			t.Fatalf("%s: unexpected distance or resolution: %+v", name, routeMap)
+		}
+	}
+
+	disabled := NewSeaRouteClient(SeaRouteClientConfig{Endpoint: failing.URL, Timeout: 2 * time.Second, DisableOffline: true}).SetPortLookup(testPorts)
+	if _, err := disabled.BuildVoyageRouteMap(context.Background(), itineraries); err == nil {
+		t.Fatal("expected remote error when offline fallback is disabled")
+	}
+}
+
+func TestSeaRouteClientResolvesStopsFromPortMasterData(t *testing.T) {
+	itineraries := []domain.VoyageItinerary{
+		{DayNo: 1, StopIndex: 1, City: "上海（中国）"},
+		{DayNo: 2, StopIndex: 1, City: "福冈市"},
+	}
+	withoutPorts := NewSeaRouteClient(SeaRouteClientConfig{})
+	if routeMap, err := withoutPorts.BuildVoyageRouteMap(context.Background(), itineraries); err != nil || routeMap != nil {
+		t.Fatalf("expected no route without port master data, got %+v, %v", routeMap, err)
+	}
+
+	routeMap, err := NewSeaRouteClient(SeaRouteClientConfig{}).SetPortLookup(testPorts).BuildVoyageRouteMap(context.Background(), itineraries)
+	if err != nil || routeMap == nil {
+		t.Fatalf("expected route from port master coordinates, got %+v, %v", routeMap, err)
+	}
+	line := routeMap.Coordinates[0]
+	if last := line[len(line)-1]; last[0] != 130.4017 || last[1] != 33.5902 {
+		t.Fatalf("route should end at Fukuoka port coordinates, got %v", last)
+	}
+
+	unknown := append(itineraries, domain.VoyageItinerary{DayNo: 3, StopIndex: 1, City: "未知港"})
+	if routeMap, _ := NewSeaRouteClient(SeaRouteClientConfig{}).SetPortLookup(testPorts).BuildVoyageRouteMap(context.Background(), unknown); routeMap != nil {
+		t.Fatalf("expected no route when a stop is not in port master data, got %+v", routeMap)
+	}
+}
+
 func TestVoyageServiceGetByIDEnrichesRouteMap(t *testing.T) {
 	repo := &voyageRepoStub{item: &domain.Voyage{ID: 7, Code: "VOY-7", Itineraries: []domain.VoyageItinerary{{DayNo: 1, StopIndex: 1, City: "上海"}, {DayNo: 2, StopIndex: 1, City: "福冈"}}}}
 	svc := NewVoyageService(repo, &maritimeRouteBuilderStub{routeMap: &domain.VoyageRouteMap{Provider: "searoute", GeometryType: "MultiLineString", Coordinates: [][][]float64{{{121.4, 31.2}, {130.4, 33.5}}}}})
@@ -147,3 +248,191 @@ func TestVoyageServiceCreateResolvesSelectedCityLabelsToCoordinates(t *testing.T
 		t.Fatalf("expected sea cruise stop to keep empty coordinates, got %+v", repo.created.Itineraries[1])
 	}
 }
+
+// routeMapCacheStub 是按缓存键索引的内存航线缓存，按 now 判断过期。
+type routeMapCacheStub struct {
+	entries map[string]domain.RouteMapCache
+	deleted []string
+}
+
+func newRouteMapCacheStub() *routeMapCacheStub {
+	return &routeMapCacheStub{entries: map[string]domain.RouteMapCache{}}
+}
+
+func (s *routeMapCacheStub) Get(_ context.Context, key string, now time.Time) (*domain.RouteMapCache, error) {
+	entry, ok := s.entries[key]
+	if !ok || (entry.ExpiresAt != nil && !entry.ExpiresAt.After(now)) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entry, nil
+}
+
+func (s *routeMapCacheStub) Upsert(_ context.Context, entry *domain.RouteMapCache) error {
+	s.entries[entry.CacheKey] = *entry
+	return nil
+}
+
+func (s *routeMapCacheStub) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(s.entries, key)
+		s.deleted = append(s.deleted, key)
+	}
+	return nil
+}
+
+func TestSeaRouteClientCachesRouteMaps(t *testing.T) {
+	requests := 0
+	remoteDown := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if remoteDown {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "ok",
+			"dist":   400.0,
+			"geom":   map[string]any{"type": "LineString", "coordinates": [][]float64{{121.4737, 31.2304}, {130.4017, 33.5902}}},
+		})
+	}))
+	defer server.Close()
+
+	cache := newRouteMapCacheStub()
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	client := NewSeaRouteClient(SeaRouteClientConfig{Endpoint: server.URL, Timeout: 2 * time.Second, OfflineCacheTTL: time.Hour}).
+		SetPortLookup(testPorts).
+		SetRouteCache(cache)
+	client.now = func() time.Time { return now }
+	itineraries := []domain.VoyageItinerary{
+		{DayNo: 1, StopIndex: 1, City: "上海"},
+		{DayNo: 2, StopIndex: 1, City: "海上巡游"},
+		{DayNo: 3, StopIndex: 1, City: "福冈"},
+		{DayNo: 4, StopIndex: 1, City: "长崎"},
+	}
+
+	first, err := client.BuildVoyageRouteMap(context.Background(), itineraries)
+	if err != nil || first == nil || first.Provider != seaRouteProviderRemote {
+		t.Fatalf("expected remote route map, got %+v, %v", first, err)
+	}
+	if requests != 2 || len(cache.entries) != 1 {
+		t.Fatalf("expected one request per leg and one cache entry, got %d requests, %d entries", requests, len(cache.entries))
+	}
+	for _, entry := range cache.entries {
+		if entry.ExpiresAt != nil || entry.StopCount != 3 {
+			t.Fatalf("remote route should be cached without expiry, got %+v", entry)
+		}
+	}
+
+	second, err := client.BuildVoyageRouteMap(context.Background(), itineraries)
+	if err != nil || second == nil || second.DistanceKM != 800 || requests != 2 {
+		t.Fatalf("expected cached route without new requests, got %+v, %v, %d requests", second, err, requests)
+	}
+
+	// 外部服务故障时的离线兜底只缓存 OfflineCacheTTL，到期后重新请求外部服务
+	remoteDown = true
+	reversed := []domain.VoyageItinerary{itineraries[3], itineraries[2]}
+	offline, err := client.BuildVoyageRouteMap(context.Background(), reversed)
+	if err != nil || offline == nil || offline.Provider != seaRouteProviderOffline {
+		t.Fatalf("expected offline fallback, got %+v, %v", offline, err)
+	}
+	key := client.routeCacheKey(client.normalizeVoyageRouteStops(context.Background(), reversed))
+	if entry := cache.entries[key]; entry.ExpiresAt == nil || !entry.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("offline fallback should expire after the ttl, got %+v", entry)
+	}
+	remoteDown = false
+	before := requests
+	now = now.Add(2 * time.Hour)
+	if routeMap, _ := client.BuildVoyageRouteMap(context.Background(), reversed); routeMap == nil || routeMap.Provider != seaRouteProviderRemote || requests != before+1 {
+		t.Fatalf("expected expired offline cache to retry remote service, got %+v", routeMap)
+	}
+}
+
+func TestSeaRouteClientInvalidateVoyageRoute(t *testing.T) {
+	cache := newRouteMapCacheStub()
+	client := NewSeaRouteClient(SeaRouteClientConfig{}).SetPortLookup(testPorts).SetRouteCache(cache)
+	ctx := context.Background()
+	previous := []domain.VoyageItinerary{{DayNo: 1, City: "上海"}, {DayNo: 2, City: "福冈"}}
+	if routeMap, _ := client.BuildVoyageRouteMap(ctx, previous); routeMap == nil || len(cache.entries) != 1 {
+		t.Fatalf("expected route to be cached, got %+v", cache.entries)
+	}
+
+	sameStops := []domain.VoyageItinerary{{DayNo: 1, City: "上海", Summary: "登船"}, {DayNo: 2, City: "福冈", Summary: "自由活动"}}
+	if err := client.InvalidateVoyageRoute(ctx, previous, sameStops); err != nil || len(cache.deleted) != 0 {
+		t.Fatalf("unchanged stop coordinates should keep the cache, deleted %v, err %v", cache.deleted, err)
+	}
+
+	changed := append(previous, domain.VoyageItinerary{DayNo: 3, City: "长崎"})
+	if err := client.InvalidateVoyageRoute(ctx, previous, changed); err != nil || len(cache.entries) != 0 || len(cache.deleted) != 1 {
+		t.Fatalf("expected stale route cache to be deleted, entries %v, err %v", cache.entries, err)
+	}
+}
+
+// voyageRouteCacheStub 记录航次服务触发的航线缓存失效。
+type voyageRouteCacheStub struct {
+	previous [][]domain.VoyageItinerary
+	current  [][]domain.VoyageItinerary
+}
+
+func (s *voyageRouteCacheStub) InvalidateVoyageRoute(_ context.Context, previous, current []domain.VoyageItinerary) error {
+	s.previous = append(s.previous, previous)
+	s.current = append(s.current, current)
+	return nil
+}
+
+// countingRouteBuilderStub 记录后台预取时构建航线的行程。
+type countingRouteBuilderStub struct {
+	built [][]domain.VoyageItinerary
+}
+
+func (s *countingRouteBuilderStub) BuildVoyageRouteMap(_ context.Context, itineraries []domain.VoyageItinerary) (*domain.VoyageRouteMap, error) {
+	s.built = append(s.built, itineraries)
+	return nil, nil
+}
+
+func TestVoyageServiceMaintainsRouteCacheOnSave(t *testing.T) {
+	oldStops := []domain.VoyageItinerary{{DayNo: 1, City: "上海"}, {DayNo: 2, City: "福冈"}}
+	repo := &voyageRepoStub{item: &domain.Voyage{ID: 7, Itineraries: oldStops}}
+	builder := &countingRouteBuilderStub{}
+	cache := &voyageRouteCacheStub{}
+	svc := NewVoyageService(repo, builder).SetRouteCache(cache)
+	var pending []func()
+	svc.runAsync = func(task func()) { pending = append(pending, task) }
+	ctx := context.Background()
+
+	if err := svc.Create(ctx, &domain.Voyage{Itineraries: oldStops}); err != nil {
+		t.Fatalf("Create returned error: %v", err)
+	}
+	if len(pending) != 1 || len(cache.previous) != 0 {
+		t.Fatalf("create should only schedule a prefetch, got %d tasks, %d invalidations", len(pending), len(cache.previous))
+	}
+
+	newStops := []domain.VoyageItinerary{{DayNo: 1, City: "上海"}, {DayNo: 2, City: "长崎"}}
+	update := &domain.Voyage{ID: 7, Itineraries: newStops}
+	if err := svc.Update(ctx, update); err != nil {
+		t.Fatalf("Update returned error: %v", err)
+	}
+	if len(cache.previous) != 1 || cache.previous[0][1].City != "福冈" || cache.current[0][1].City != "长崎" {
+		t.Fatalf("expected invalidation with previous and current itineraries, got %+v -> %+v", cache.previous, cache.current)
+	}
+	update.Itineraries[1].City = "调用方后续修改"
+	for _, task := range pending {
+		task()
+	}
+	if len(builder.built) != 2 || builder.built[1][1].City != "长崎" {
+		t.Fatalf("expected prefetch for created and updated itineraries on a snapshot, got %+v", builder.built)
+	}
+
+	if err := svc.Delete(ctx, 7); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if len(cache.previous) != 2 || cache.current[1] != nil {
+		t.Fatalf("expected deleting voyage to invalidate its route cache, got %+v", cache.current)
+	}
+
+	uncached := NewVoyageService(repo, builder)
+	uncached.runAsync = func(task func()) { pending = append(pending, task) }
+	pending = nil
+	if err := uncached.Update(ctx, &domain.Voyage{ID: 7, Itineraries: newStops}); err != nil || len(pending) != 0 {
+		t.Fatalf("without a route cache no prefetch should be scheduled, got %d tasks, %v", len(pending), err)
+	}
+}
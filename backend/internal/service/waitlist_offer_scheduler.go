@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// waitlistOfferProcessor 结束到期的候补邀约并为排队用户发出新邀约，由 WaitlistService 实现。
+type waitlistOfferProcessor interface {
+	ProcessOffers(ctx context.Context) (int, error)
+}
+
+// WaitlistOfferScheduler 定期把独占期内未下单的候补占座退回库存，并把可售余量邀约给排队用户。
+// RunOnce 返回本轮发出的新邀约数。
+type WaitlistOfferScheduler struct {
+	*periodicJob
+}
+
+// NewWaitlistOfferScheduler 创建候补邀约调度器；interval 非正数时默认 1 分钟。
+func NewWaitlistOfferScheduler(processor waitlistOfferProcessor, interval time.Duration) *WaitlistOfferScheduler {
+	return &WaitlistOfferScheduler{periodicJob: newPeriodicJob("waitlist_offer_scheduler: process offers", interval, processor.ProcessOffers)}
+}
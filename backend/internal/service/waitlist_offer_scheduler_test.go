@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubOfferProcessor struct {
+	offered int
+	err     error
+}
+
+func (p *stubOfferProcessor) ProcessOffers(_ context.Context) (int, error) {
+	return p.offered, p.err
+}
+
+func TestWaitlistOfferScheduler_RunOnce(t *testing.T) {
+	processor := &stubOfferProcessor{offered: 2}
+	scheduler := NewWaitlistOfferScheduler(processor, 0)
+	assert.Equal(t, time.Minute, scheduler.interval)
+	assert.Equal(t, 2, scheduler.RunOnce(context.Background()))
+
+	processor.offered, processor.err = 1, errors.New("offer sku 3: db down")
+	assert.Equal(t, 1, scheduler.RunOnce(context.Background()), "部分失败时仍返回已发出的邀约数")
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	waitlistMaxGuests         = 8                // 候补登记允许的最大入住人数
+	waitlistOfferTemplate     = "waitlist_offer" // 候补邀约通知模板标识
+	defaultWaitlistOfferTTL   = 30 * time.Minute // 默认独占占座时长
+	waitlistMaxOffersPerRound = 100              // 单次释放最多连续发出的邀约数，防止异常数据导致死循环
+)
+
+var (
+	// ErrInvalidWaitlistRequest 表示候补登记参数不合法。
+	ErrInvalidWaitlistRequest = errors.New("invalid waitlist request")
+	// ErrWaitlistNotFound 表示候补记录不存在或不属于当前用户。
+	ErrWaitlistNotFound = errors.New("waitlist entry not found")
+	// ErrWaitlistEntryActive 表示用户在该航次舱型上已有有效候补。
+	ErrWaitlistEntryActive = errors.New("waitlist entry already active")
+	// ErrWaitlistEntryClosed 表示候补记录已下单、过期或取消。
+	ErrWaitlistEntryClosed = errors.New("waitlist entry is closed")
+)
+
+// WaitlistRepo 定义候补服务所需的数据访问能力，事务内方法保证邀约占座与通知原子写入。
+type WaitlistRepo interface {
+	domain.WaitlistRepository
+	InTx(ctx context.Context, fn func(tx *gorm.DB) error) error
+	LockInventoryTx(tx *gorm.DB, skuID int64) (*domain.CabinInventory, error)
+	NextWaitingTx(tx *gorm.DB, voyageID, cabinTypeID, skuID int64, maxGuests int, at time.Time) (*domain.WaitlistEntry, error)
+	CreateOfferTx(tx *gorm.DB, entry *domain.WaitlistEntry, skuID int64, at, expiresAt time.Time) error
+	CreateNotificationTx(tx *gorm.DB, n *domain.Notification) error
+	FulfillOfferTx(tx *gorm.DB, userID, skuID, bookingID int64, at time.Time) error
+}
+
+// waitlistSKUStore 提供舱房 SKU 查询，用于把释放的 SKU 映射到航次与舱型。
+type waitlistSKUStore interface {
+	GetSKUByID(ctx context.Context, id int64) (*domain.CabinSKU, error)
+	ListSKUByVoyage(ctx context.Context, voyageID int64) ([]domain.CabinSKU, error)
+}
+
+// InventoryReleaseListener 在库存退回后被通知，由 WaitlistService 实现。
+// 通知失败不影响已完成的库存释放。
+type InventoryReleaseListener interface {
+	OnInventoryReleased(ctx context.Context, skuID int64)
+}
+
+// waitlistOfferPayload 是候补邀约通知的负载。
+type waitlistOfferPayload struct {
+	WaitlistID  int64     `json:"waitlist_id"`
+	VoyageID    int64     `json:"voyage_id"`
+	CabinTypeID int64     `json:"cabin_type_id"`
+	CabinSKUID  int64     `json:"cabin_sku_id"`
+	Guests      int       `json:"guests"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// WaitlistService 负责售罄航次舱型的候补登记，并在库存释放时按登记顺序为候补用户独占占座、发送通知。
+// 用户在独占期内下单即使用该占座；逾期未下单则退回库存并邀约下一位。
+type WaitlistService struct {
+	repo     WaitlistRepo
+	skus     waitlistSKUStore
+	offerTTL time.Duration
+	now      func() time.Time
+}
+
+// NewWaitlistService 创建候补服务；offerTTL 非正数时默认独占 30 分钟。
+func NewWaitlistService(repo WaitlistRepo, skus waitlistSKUStore, offerTTL time.Duration) *WaitlistService {
+	if offerTTL <= 0 {
+		offerTTL = defaultWaitlistOfferTTL
+	}
+	return &WaitlistService{repo: repo, skus: skus, offerTTL: offerTTL, now: time.Now}
+}
+
+// Join 为用户登记航次舱型候补。
+func (s *WaitlistService) Join(ctx context.Context, userID, voyageID, cabinTypeID int64, guests int) (*domain.WaitlistEntry, error) {
+	if userID <= 0 || voyageID <= 0 || cabinTypeID <= 0 {
+		return nil, fmt.Errorf("%w: user, voyage and cabin type are required", ErrInvalidWaitlistRequest)
+	}
+	if guests < 1 || guests > waitlistMaxGuests {
+		return nil, fmt.Errorf("%w: guests must be between 1 and %d", ErrInvalidWaitlistRequest, waitlistMaxGuests)
+	}
+	entry := &domain.WaitlistEntry{
+		UserID:      userID,
+		VoyageID:    voyageID,
+		CabinTypeID: cabinTypeID,
+		Guests:      guests,
+		Status:      domain.WaitlistStatusWaiting,
+	}
+	if err := s.repo.CreateEntry(ctx, entry); err != nil {
+		return nil, translateWaitlistError(err)
+	}
+	return entry, nil
+}
+
+// ListMine 查询用户自己的候补记录。
+func (s *WaitlistService) ListMine(ctx context.Context, userID int64) ([]domain.WaitlistEntry, error) {
+	return s.repo.ListUserEntries(ctx, userID)
+}
+
+// List 供后台按航次、舱型、用户与状态分页查询候补记录。
+func (s *WaitlistService) List(ctx context.Context, filter domain.WaitlistFilter, page, pageSize int) ([]domain.WaitlistEntry, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	filter.Status = strings.TrimSpace(filter.Status)
+	return s.repo.ListEntries(ctx, filter, page, pageSize)
+}
+
+// Cancel 取消用户的候补；若正处于独占期，则退回占座并邀约下一位。
+func (s *WaitlistService) Cancel(ctx context.Context, userID, id int64) error {
+	entry, err := s.repo.CancelEntry(ctx, userID, id)
+	if err != nil {
+		return translateWaitlistError(err)
+	}
+	if entry.OfferHoldID > 0 {
+		s.OnInventoryReleased(ctx, entry.OfferedSKUID)
+	}
+	return nil
+}
+
+// OnInventoryReleased 实现 InventoryReleaseListener：把 SKU 当前可售余量依次邀约给排队用户。
+func (s *WaitlistService) OnInventoryReleased(ctx context.Context, skuID int64) {
+	if _, err := s.OfferAvailable(ctx, skuID); err != nil {
+		logger.FromContext(ctx).Error("waitlist: offer released inventory failed", zap.Int64("sku_id", skuID), zap.Error(err))
+	}
+}
+
+// OfferWaiting 巡检所有存在排队用户的航次舱型，把各 SKU 的可售余量邀约出去，返回发出的邀约数。
+// 既承接到期邀约退回的占座，也兜底未接入释放监听的库存退回路径（如分销配额到期释放）。
+func (s *WaitlistService) OfferWaiting(ctx context.Context) (int, error) {
+	targets, err := s.repo.ListWaitingTargets(ctx)
+	if err != nil {
+		return 0, err
+	}
+	offered := 0
+	var errs []error
+	skusByVoyage := make(map[int64][]domain.CabinSKU)
+	for _, target := range targets {
+		skus, ok := skusByVoyage[target.VoyageID]
+		if !ok {
+			if skus, err = s.skus.ListSKUByVoyage(ctx, target.VoyageID); err != nil {
+				errs = append(errs, fmt.Errorf("voyage %d: %w", target.VoyageID, err))
+				continue
+			}
+			skusByVoyage[target.VoyageID] = skus
+		}
+		for i := range skus {
+			if skus[i].CabinTypeID != target.CabinTypeID {
+				continue
+			}
+			n, err := s.offerSKU(ctx, &skus[i])
+			offered += n
+			if err != nil {
+				errs = append(errs, fmt.Errorf("offer sku %d: %w", skus[i].ID, err))
+			}
+		}
+	}
+	return offered, errors.Join(errs...)
+}
+
+// ProcessOffers 供调度器调用：先结束到期邀约，再把退回及其他途径释放的余量邀约给排队用户，返回本轮发出的新邀约数。
+func (s *WaitlistService) ProcessOffers(ctx context.Context) (int, error) {
+	_, expireErr := s.ExpireOffers(ctx)
+	offered, err := s.OfferWaiting(ctx)
+	return offered, errors.Join(expireErr, err)
+}
+
+// OfferAvailable 在 SKU 有可售余量时按登记顺序逐个发出独占邀约，返回本次发出的邀约数。
+func (s *WaitlistService) OfferAvailable(ctx context.Context, skuID int64) (int, error) {
+	sku, err := s.skus.GetSKUByID(ctx, skuID)
+	if err != nil {
+		return 0, err
+	}
+	return s.offerSKU(ctx, sku)
+}
+
+// offerSKU 对单个 SKU 逐个发出邀约，直到没有余量或没有可邀约的排队用户。
+func (s *WaitlistService) offerSKU(ctx context.Context, sku *domain.CabinSKU) (int, error) {
+	offered := 0
+	for offered < waitlistMaxOffersPerRound {
+		ok, err := s.offerNext(ctx, sku)
+		if err != nil {
+			return offered, err
+		}
+		if !ok {
+			break
+		}
+		offered++
+	}
+	return offered, nil
+}
+
+// offerNext 在一个事务内确认余量、取出最早的排队记录、独占占座并写入通知；没有余量或排队者时返回 false。
+func (s *WaitlistService) offerNext(ctx context.Context, sku *domain.CabinSKU) (bool, error) {
+	offered := false
+	err := s.repo.InTx(ctx, func(tx *gorm.DB) error {
+		inv, err := s.repo.LockInventoryTx(tx, sku.ID)
+		if err != nil {
+			return err
+		}
+		if inv.Total-inv.Locked-inv.Sold < 1 {
+			return nil
+		}
+		now := s.now()
+		entry, err := s.repo.NextWaitingTx(tx, sku.VoyageID, sku.CabinTypeID, sku.ID, sku.MaxGuests, now)
+		if err != nil || entry == nil {
+			return err
+		}
+		expiresAt := now.Add(s.offerTTL)
+		if err := s.repo.CreateOfferTx(tx, entry, sku.ID, now, expiresAt); err != nil {
+			return err
+		}
+		payload, err := json.Marshal(waitlistOfferPayload{
+			WaitlistID:  entry.ID,
+			VoyageID:    entry.VoyageID,
+			CabinTypeID: entry.CabinTypeID,
+			CabinSKUID:  sku.ID,
+			Guests:      entry.Guests,
+			ExpiresAt:   expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.repo.CreateNotificationTx(tx, &domain.Notification{
+			UserID:   entry.UserID,
+			Channel:  ChannelSMS,
+			Template: waitlistOfferTemplate,
+			Payload:  string(payload),
+			Status:   NotificationStatusPending,
+		}); err != nil {
+			return err
+		}
+		offered = true
+		return nil
+	})
+	return offered, err
+}
+
+// FulfillOfferTx 在下单事务内把用户对该 SKU 的有效邀约标记为已下单，由 BookingService 调用。
+func (s *WaitlistService) FulfillOfferTx(tx *gorm.DB, userID, skuID, bookingID int64) error {
+	return s.repo.FulfillOfferTx(tx, userID, skuID, bookingID, s.now())
+}
+
+// ExpireOffers 结束已过独占期的邀约并退回占座，返回本轮过期的邀约数；退回的余量由 OfferWaiting 邀约下一位。
+func (s *WaitlistService) ExpireOffers(ctx context.Context) (int, error) {
+	now := s.now()
+	ids, err := s.repo.ListExpiredOfferIDs(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	var errs []error
+	for _, id := range ids {
+		entry, err := s.repo.ExpireOffer(ctx, id, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("waitlist entry %d: %w", id, err))
+			continue
+		}
+		if entry != nil && entry.Status == domain.WaitlistStatusExpired {
+			expired++
+		}
+	}
+	return expired, errors.Join(errs...)
+}
+
+// translateWaitlistError 将仓储层错误转换为服务层错误。
+func translateWaitlistError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrWaitlistNotFound
+	case errors.Is(err, domain.ErrWaitlistEntryActive):
+		return ErrWaitlistEntryActive
+	case errors.Is(err, domain.ErrWaitlistEntryClosed):
+		return ErrWaitlistEntryClosed
+	default:
+		return err
+	}
+}
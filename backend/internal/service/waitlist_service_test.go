@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/domain"
+	"github.com/cruisebooking/backend/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type waitlistTestEnv struct {
+	db         *gorm.DB
+	waitlist   *WaitlistService
+	bookings   *BookingService
+	cabinAdmin *CabinAdminService
+	now        time.Time
+}
+
+// newWaitlistTestEnv 使用 SQLite 内存库组装候补、预订（真实占座）与库存调整服务，SKU 1 初始售罄。
+func newWaitlistTestEnv(t *testing.T) *waitlistTestEnv {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&domain.WaitlistEntry{},
+		&domain.Notification{},
+		&domain.CabinSKU{},
+		&domain.CabinInventory{},
+		&domain.InventoryLog{},
+		&domain.CabinHold{},
+		&domain.Booking{},
+	))
+	require.NoError(t, db.Create(&domain.CabinSKU{ID: 1, VoyageID: 10, CabinTypeID: 100, Code: "A1", MaxGuests: 2}).Error)
+	require.NoError(t, db.Create(&domain.CabinInventory{CabinSKUID: 1, Total: 0}).Error)
+
+	cabinRepo := repository.NewCabinRepository(db)
+	env := &waitlistTestEnv{db: db, now: time.Now().UTC()}
+	env.waitlist = NewWaitlistService(repository.NewWaitlistRepository(db), cabinRepo, 30*time.Minute)
+	env.waitlist.now = func() time.Time { return env.now }
+	env.bookings = NewBookingService(repository.NewBookingRepository(db), fakePriceService{}, NewCabinHoldService(repository.NewCabinHoldRepository(db), 15*time.Minute))
+	env.bookings.SetWaitlist(env.waitlist)
+	env.cabinAdmin = NewCabinAdminService(cabinRepo)
+	env.cabinAdmin.SetReleaseListener(env.waitlist)
+	return env
+}
+
+func (e *waitlistTestEnv) inventoryTotal(t *testing.T) int {
+	t.Helper()
+	var inv domain.CabinInventory
+	require.NoError(t, e.db.Where("cabin_sku_id = ?", 1).First(&inv).Error)
+	return inv.Total
+}
+
+func (e *waitlistTestEnv) entry(t *testing.T, id int64) domain.WaitlistEntry {
+	t.Helper()
+	var entry domain.WaitlistEntry
+	require.NoError(t, e.db.First(&entry, id).Error)
+	return entry
+}
+
+func TestWaitlistService_JoinValidation(t *testing.T) {
+	env := newWaitlistTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.waitlist.Join(ctx, 1, 0, 100, 2)
+	assert.True(t, errors.Is(err, ErrInvalidWaitlistRequest))
+	_, err = env.waitlist.Join(ctx, 1, 10, 100, 0)
+	assert.True(t, errors.Is(err, ErrInvalidWaitlistRequest))
+
+	entry, err := env.waitlist.Join(ctx, 1, 10, 100, 2)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WaitlistStatusWaiting, entry.Status)
+	_, err = env.waitlist.Join(ctx, 1, 10, 100, 1)
+	assert.True(t, errors.Is(err, ErrWaitlistEntryActive), "同一航次舱型不可重复候补")
+
+	require.NoError(t, env.waitlist.Cancel(ctx, 1, entry.ID))
+	assert.True(t, errors.Is(env.waitlist.Cancel(ctx, 1, entry.ID), ErrWaitlistEntryClosed))
+	assert.True(t, errors.Is(env.waitlist.Cancel(ctx, 2, entry.ID), ErrWaitlistNotFound), "不能取消他人的候补")
+	_, err = env.waitlist.Join(ctx, 1, 10, 100, 1)
+	assert.NoError(t, err, "取消后可重新登记")
+}
+
+func TestWaitlistService_ReleaseOffersExclusiveHoldToFirstFittingUser(t *testing.T) {
+	env := newWaitlistTestEnv(t)
+	ctx := context.Background()
+
+	_, err := env.bookings.Create(ctx, 1, 10, 1, 2)
+	require.True(t, errors.Is(err, ErrBookingSoldOut))
+
+	tooMany, err := env.waitlist.Join(ctx, 3, 10, 100, 4)
+	require.NoError(t, err)
+	first, err := env.waitlist.Join(ctx, 1, 10, 100, 2)
+	require.NoError(t, err)
+	second, err := env.waitlist.Join(ctx, 2, 10, 100, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, env.cabinAdmin.AdjustInventory(ctx, 1, 1, "restock"))
+	assert.Equal(t, 0, env.inventoryTotal(t), "邀约独占占座后库存再次为零")
+	assert.Equal(t, domain.WaitlistStatusWaiting, env.entry(t, tooMany.ID).Status, "人数超过舱房容量的候补被跳过")
+	offered := env.entry(t, first.ID)
+	assert.Equal(t, domain.WaitlistStatusOffered, offered.Status)
+	assert.Equal(t, int64(1), offered.OfferedSKUID)
+	require.NotNil(t, offered.OfferExpiresAt)
+	assert.WithinDuration(t, env.now.Add(30*time.Minute), *offered.OfferExpiresAt, time.Second)
+	assert.Equal(t, domain.WaitlistStatusWaiting, env.entry(t, second.ID).Status)
+
+	var notices []domain.Notification
+	require.NoError(t, env.db.Find(&notices).Error)
+	require.Len(t, notices, 1)
+	assert.Equal(t, int64(1), notices[0].UserID)
+	assert.Equal(t, "waitlist_offer", notices[0].Template)
+	assert.Equal(t, NotificationStatusPending, notices[0].Status)
+	assert.Contains(t, notices[0].Payload, `"cabin_sku_id":1`)
+
+	_, err = env.bookings.Create(ctx, 2, 10, 1, 2)
+	assert.True(t, errors.Is(err, ErrBookingSoldOut), "独占期内其他用户无法占用")
+
+	booking, err := env.bookings.Create(ctx, 1, 10, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, env.inventoryTotal(t), "下单使用邀约占座，不再扣减库存")
+	booked := env.entry(t, first.ID)
+	assert.Equal(t, domain.WaitlistStatusBooked, booked.Status)
+	assert.Equal(t, booking.ID, booked.BookingID)
+
+	env.now = env.now.Add(time.Hour)
+	offers, err := env.waitlist.ProcessOffers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, offers)
+	assert.Equal(t, domain.WaitlistStatusBooked, env.entry(t, first.ID).Status, "已下单的邀约不会被过期退回")
+	assert.Equal(t, 0, env.inventoryTotal(t))
+}
+
+func TestWaitlistService_ExpiredOfferPassesToNextUser(t *testing.T) {
+	env := newWaitlistTestEnv(t)
+	ctx := context.Background()
+
+	first, err := env.waitlist.Join(ctx, 1, 10, 100, 2)
+	require.NoError(t, err)
+	second, err := env.waitlist.Join(ctx, 2, 10, 100, 1)
+	require.NoError(t, err)
+	require.NoError(t, env.cabinAdmin.AdjustInventory(ctx, 1, 1, "restock"))
+	require.Equal(t, domain.WaitlistStatusOffered, env.entry(t, first.ID).Status)
+
+	env.now = env.now.Add(10 * time.Minute)
+	offers, err := env.waitlist.ProcessOffers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, offers, "独占期内不处理")
+
+	env.now = env.now.Add(30 * time.Minute)
+	offers, err = env.waitlist.ProcessOffers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, offers)
+	assert.Equal(t, domain.WaitlistStatusExpired, env.entry(t, first.ID).Status)
+	assert.Equal(t, domain.WaitlistStatusOffered, env.entry(t, second.ID).Status)
+	assert.Equal(t, 0, env.inventoryTotal(t), "退回的占座立即转给下一位")
+
+	var holds []domain.CabinHold
+	require.NoError(t, env.db.Find(&holds).Error)
+	require.Len(t, holds, 1, "过期邀约的占座已删除")
+	assert.Equal(t, int64(2), holds[0].UserID)
+}
+
+func TestWaitlistService_CancelOfferedEntryPassesToNextUser(t *testing.T) {
+	env := newWaitlistTestEnv(t)
+	ctx := context.Background()
+
+	first, err := env.waitlist.Join(ctx, 1, 10, 100, 2)
+	require.NoError(t, err)
+	require.NoError(t, env.cabinAdmin.AdjustInventory(ctx, 1, 1, "restock"))
+
+	require.NoError(t, env.waitlist.Cancel(ctx, 1, first.ID))
+	assert.Equal(t, 1, env.inventoryTotal(t), "无人排队时占座退回公共库存")
+
+	second, err := env.waitlist.Join(ctx, 2, 10, 100, 2)
+	require.NoError(t, err)
+	offers, err := env.waitlist.ProcessOffers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, offers, "巡检把现有余量邀约给新登记的用户")
+	assert.Equal(t, domain.WaitlistStatusOffered, env.entry(t, second.ID).Status)
+	assert.Equal(t, 0, env.inventoryTotal(t))
+
+	items, total, err := env.waitlist.List(ctx, domain.WaitlistFilter{VoyageID: 10, Status: domain.WaitlistStatusCancelled}, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, first.ID, items[0].ID)
+}
+
+type recordingReleaseListener struct {
+	skuIDs []int64
+}
+
+func (l *recordingReleaseListener) OnInventoryReleased(_ context.Context, skuID int64) {
+	l.skuIDs = append(l.skuIDs, skuID)
+}
+
+func TestOrderTimeoutService_NotifiesReleaseListener(t *testing.T) {
+	repo := &fakeOrderTimeoutRepo{orders: map[int64]domain.Booking{
+		1: {ID: 1, CabinSKUID: 101, Status: domain.OrderStatusPendingPayment},
+	}}
+	listener := &recordingReleaseListener{}
+	svc := NewOrderTimeoutService(repo, &fakeInventoryReleaser{})
+	svc.SetReleaseListener(listener)
+
+	closed, err := svc.CloseExpiredOrders(context.Background(), 15*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, closed)
+	assert.Equal(t, []int64{101}, listener.skuIDs)
+}
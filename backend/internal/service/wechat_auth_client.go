@@ -0,0 +1,178 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cruisebooking/backend/internal/pkg/tracing"
+)
+
+var (
+	// ErrWechatNotConfigured 表示未配置小程序 AppID/AppSecret。
+	ErrWechatNotConfigured = errors.New("wechat login not configured")
+	// ErrWechatCodeInvalid 表示 wx.login 登录凭证无效、已使用或与当前用户不匹配。
+	ErrWechatCodeInvalid = errors.New("invalid wechat login code")
+	// ErrWechatUnavailable 表示微信接口调用失败。
+	ErrWechatUnavailable = errors.New("wechat service unavailable")
+	// ErrWechatPhoneInvalid 表示手机号授权数据无法解密或不属于本小程序。
+	ErrWechatPhoneInvalid = errors.New("invalid wechat phone authorization")
+)
+
+// WechatSession 是 code2session 返回的登录会话。
+type WechatSession struct {
+	OpenID     string // 用户在小程序内的唯一标识
+	UnionID    string // 开放平台 UnionID，小程序未绑定开放平台时为空
+	SessionKey string // 会话密钥，仅保存在服务端
+}
+
+// WechatPhoneNumber 是手机号授权数据（getPhoneNumber 的 encryptedData）解密结果。
+type WechatPhoneNumber struct {
+	PhoneNumber     string `json:"phoneNumber"`     // 带区号的手机号（境外手机号带国家码）
+	PurePhoneNumber string `json:"purePhoneNumber"` // 不带区号的手机号
+	CountryCode     string `json:"countryCode"`     // 国家码
+	Watermark       struct {
+		AppID     string `json:"appid"`     // 数据所属小程序 AppID
+		Timestamp int64  `json:"timestamp"` // 获取时间戳
+	} `json:"watermark"`
+}
+
+// WechatAuthClient 定义小程序登录所需的微信接口能力，测试中可指向本地桩服务。
+type WechatAuthClient interface {
+	Code2Session(ctx context.Context, code string) (*WechatSession, error)
+	DecryptPhoneNumber(sessionKey, encryptedData, iv string) (*WechatPhoneNumber, error)
+}
+
+// WechatClientConfig 定义微信接口客户端的配置参数。
+type WechatClientConfig struct {
+	AppID     string        // 小程序 AppID
+	AppSecret string        // 小程序 AppSecret
+	Endpoint  string        // 接口地址，默认 https://api.weixin.qq.com
+	Timeout   time.Duration // HTTP 请求超时时间，默认 5 秒
+}
+
+// WechatHTTPClient 通过 HTTP 调用微信 jscode2session 接口，并在本地解密手机号授权数据。
+type WechatHTTPClient struct {
+	appID      string
+	appSecret  string
+	endpoint   string
+	httpClient *http.Client
+}
+
+var _ WechatAuthClient = (*WechatHTTPClient)(nil)
+
+// code2sessionResponse 定义 jscode2session 接口的 JSON 响应结构。
+type code2sessionResponse struct {
+	OpenID     string `json:"openid"`
+	SessionKey string `json:"session_key"`
+	UnionID    string `json:"unionid"`
+	ErrCode    int    `json:"errcode"`
+	ErrMsg     string `json:"errmsg"`
+}
+
+// NewWechatAuthClient 创建微信接口客户端。
+func NewWechatAuthClient(cfg WechatClientConfig) *WechatHTTPClient {
+	if strings.TrimSpace(cfg.Endpoint) == "" {
+		cfg.Endpoint = "https://api.weixin.qq.com"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &WechatHTTPClient{
+		appID:      strings.TrimSpace(cfg.AppID),
+		appSecret:  strings.TrimSpace(cfg.AppSecret),
+		endpoint:   strings.TrimRight(strings.TrimSpace(cfg.Endpoint), "/"),
+		httpClient: &http.Client{Timeout: cfg.Timeout, Transport: tracing.Transport(nil)},
+	}
+}
+
+// Code2Session 用 wx.login 返回的 code 换取 OpenID、UnionID 与会话密钥。
+func (c *WechatHTTPClient) Code2Session(ctx context.Context, code string) (*WechatSession, error) {
+	if c.appID == "" || c.appSecret == "" {
+		return nil, ErrWechatNotConfigured
+	}
+	query := url.Values{
+		"appid":      {c.appID},
+		"secret":     {c.appSecret},
+		"js_code":    {code},
+		"grant_type": {"authorization_code"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/sns/jscode2session?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWechatUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: http status %d", ErrWechatUnavailable, resp.StatusCode)
+	}
+	var payload code2sessionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("%w: decode response: %v", ErrWechatUnavailable, err)
+	}
+	switch payload.ErrCode {
+	case 0:
+	case 40029, 40163, 40226: // code 无效、code 已被使用、高风险用户被拦截
+		return nil, fmt.Errorf("%w: errcode %d: %s", ErrWechatCodeInvalid, payload.ErrCode, payload.ErrMsg)
+	default:
+		return nil, fmt.Errorf("%w: errcode %d: %s", ErrWechatUnavailable, payload.ErrCode, payload.ErrMsg)
+	}
+	if payload.OpenID == "" || payload.SessionKey == "" {
+		return nil, fmt.Errorf("%w: empty openid or session_key", ErrWechatUnavailable)
+	}
+	return &WechatSession{OpenID: payload.OpenID, UnionID: payload.UnionID, SessionKey: payload.SessionKey}, nil
+}
+
+// DecryptPhoneNumber 使用会话密钥解密手机号授权数据（AES-128-CBC，PKCS#7 填充），并校验水印中的 AppID。
+func (c *WechatHTTPClient) DecryptPhoneNumber(sessionKey, encryptedData, iv string) (*WechatPhoneNumber, error) {
+	plain, err := decryptWechatData(sessionKey, encryptedData, iv)
+	if err != nil {
+		return nil, err
+	}
+	var phone WechatPhoneNumber
+	if err := json.Unmarshal(plain, &phone); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWechatPhoneInvalid, err)
+	}
+	if phone.Watermark.AppID != c.appID {
+		return nil, fmt.Errorf("%w: watermark appid mismatch", ErrWechatPhoneInvalid)
+	}
+	if phone.PurePhoneNumber == "" {
+		return nil, fmt.Errorf("%w: empty phone number", ErrWechatPhoneInvalid)
+	}
+	return &phone, nil
+}
+
+func decryptWechatData(sessionKey, encryptedData, iv string) ([]byte, error) {
+	key, errKey := base64.StdEncoding.DecodeString(sessionKey)
+	data, errData := base64.StdEncoding.DecodeString(encryptedData)
+	vector, errIV := base64.StdEncoding.DecodeString(iv)
+	if err := errors.Join(errKey, errData, errIV); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWechatPhoneInvalid, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWechatPhoneInvalid, err)
+	}
+	if len(vector) != block.BlockSize() || len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("%w: malformed ciphertext", ErrWechatPhoneInvalid)
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, vector).CryptBlocks(plain, data)
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > block.BlockSize() || !bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, fmt.Errorf("%w: bad padding", ErrWechatPhoneInvalid)
+	}
+	return plain[:len(plain)-padding], nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const (
+	testWechatAppID      = "wx-test-app"
+	testWechatSessionKey = "MDEyMzQ1Njc4OWFiY2RlZg==" // base64("0123456789abcdef")
+	testWechatIV         = "ZmVkY2JhOTg3NjU0MzIxMA==" // base64("fedcba9876543210")
+)
+
+// newWechatStubServer 启动本地 jscode2session 桩服务：sessions 中的 code 返回对应会话，其余返回 40029。
+func newWechatStubServer(t *testing.T, sessions map[string]WechatSession) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/sns/jscode2session" || query.Get("appid") != testWechatAppID || query.Get("secret") != "secret" || query.Get("grant_type") != "authorization_code" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"errcode": 40013, "errmsg": "invalid appid"})
+			return
+		}
+		session, ok := sessions[query.Get("js_code")]
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]any{"errcode": 40029, "errmsg": "invalid code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"openid": session.OpenID, "unionid": session.UnionID, "session_key": session.SessionKey})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// encryptWechatPhone 按微信的方式（AES-128-CBC + PKCS#7）加密手机号授权数据。
+func encryptWechatPhone(t *testing.T, sessionKey, appID, countryCode, phone string) string {
+	t.Helper()
+	plain, _ := json.Marshal(map[string]any{
+		"phoneNumber":     phone,
+		"purePhoneNumber": phone,
+		"countryCode":     countryCode,
+		"watermark":       map[string]any{"appid": appID, "timestamp": 1760860800},
+	})
+	key, _ := base64.StdEncoding.DecodeString(sessionKey)
+	iv, _ := base64.StdEncoding.DecodeString(testWechatIV)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("new cipher: %v", err)
+	}
+	padding := block.BlockSize() - len(plain)%block.BlockSize()
+	plain = append(plain, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	out := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, plain)
+	return base64.StdEncoding.EncodeToString(out)
+}
+
+func TestWechatHTTPClientCode2Session(t *testing.T) {
+	server := newWechatStubServer(t, map[string]WechatSession{"code-1": {OpenID: "openid-1", UnionID: "union-1", SessionKey: testWechatSessionKey}})
+	client := NewWechatAuthClient(WechatClientConfig{AppID: testWechatAppID, AppSecret: "secret", Endpoint: server.URL + "/"})
+	ctx := context.Background()
+
+	session, err := client.Code2Session(ctx, "code-1")
+	if err != nil || session.OpenID != "openid-1" || session.UnionID != "union-1" || session.SessionKey != testWechatSessionKey {
+		t.Fatalf("unexpected session %+v (%v)", session, err)
+	}
+	if _, err := client.Code2Session(ctx, "used-code"); !errors.Is(err, ErrWechatCodeInvalid) {
+		t.Fatalf("expected ErrWechatCodeInvalid, got %v", err)
+	}
+	wrongSecret := NewWechatAuthClient(WechatClientConfig{AppID: testWechatAppID, AppSecret: "other", Endpoint: server.URL})
+	if _, err := wrongSecret.Code2Session(ctx, "code-1"); !errors.Is(err, ErrWechatUnavailable) {
+		t.Fatalf("expected ErrWechatUnavailable for config errors, got %v", err)
+	}
+	if _, err := NewWechatAuthClient(WechatClientConfig{Endpoint: server.URL}).Code2Session(ctx, "code-1"); !errors.Is(err, ErrWechatNotConfigured) {
+		t.Fatalf("expected ErrWechatNotConfigured, got %v", err)
+	}
+}
+
+func TestWechatHTTPClientDecryptPhoneNumber(t *testing.T) {
+	client := NewWechatAuthClient(WechatClientConfig{AppID: testWechatAppID, AppSecret: "secret"})
+
+	phone, err := client.DecryptPhoneNumber(testWechatSessionKey, encryptWechatPhone(t, testWechatSessionKey, testWechatAppID, "86", "13800000000"), testWechatIV)
+	if err != nil || phone.PurePhoneNumber != "13800000000" {
+		t.Fatalf("unexpected phone %+v (%v)", phone, err)
+	}
+	cases := []struct{ key, data string }{
+		{testWechatSessionKey, encryptWechatPhone(t, testWechatSessionKey, "wx-other-app", "86", "13800000000")},
+		{"YWJjZGVmZ2hpamtsbW5vcA==", encryptWechatPhone(t, testWechatSessionKey, testWechatAppID, "86", "13800000000")},
+		{testWechatSessionKey, "not-base64"},
+		{testWechatSessionKey, base64.StdEncoding.EncodeToString([]byte("short"))},
+	}
+	for i, tc := range cases {
+		if _, err := client.DecryptPhoneNumber(tc.key, tc.data, testWechatIV); !errors.Is(err, ErrWechatPhoneInvalid) {
+			t.Fatalf("case %d: expected ErrWechatPhoneInvalid, got %v", i, err)
+		}
+	}
+}
+
+func TestUserAuthWechatLoginAndPhoneBinding(t *testing.T) {
+	const refreshedKey = "YWJjZGVmZ2hpamtsbW5vcA==" // base64("abcdefghijklmnop")
+	server := newWechatStubServer(t, map[string]WechatSession{
+		"code-1":     {OpenID: "openid-1", UnionID: "union-1", SessionKey: testWechatSessionKey},
+		"code-1-new": {OpenID: "openid-1", SessionKey: refreshedKey},
+		"code-2":     {OpenID: "openid-2", SessionKey: testWechatSessionKey},
+	})
+	accounts := newFakeAccountStore()
+	svc := NewUserAuthServiceWithPolicy(&fakeCodeStore{ok: true}, UserAuthPolicy{
+		Accounts: accounts,
+		Wechat:   NewWechatAuthClient(WechatClientConfig{AppID: testWechatAppID, AppSecret: "secret", Endpoint: server.URL}),
+	})
+	ctx := context.Background()
+
+	user, err := svc.WechatLogin(ctx, " code-1 ")
+	if err != nil || user.WxOpenID != "openid-1" || user.WxUnionID != "union-1" {
+		t.Fatalf("unexpected login %+v (%v)", user, err)
+	}
+	again, err := svc.WechatLogin(ctx, "code-1")
+	if err != nil || again.ID != user.ID {
+		t.Fatalf("same openid should log into the same user: %+v (%v)", again, err)
+	}
+	if _, err := svc.WechatLogin(ctx, "forged-openid"); !errors.Is(err, ErrWechatCodeInvalid) {
+		t.Fatalf("expected ErrWechatCodeInvalid, got %v", err)
+	}
+
+	encrypted := encryptWechatPhone(t, testWechatSessionKey, testWechatAppID, "86", "13800000000")
+	bound, err := svc.BindWechatPhone(ctx, user.ID, "", encrypted, testWechatIV)
+	if err != nil || bound.Phone != "13800000000" {
+		t.Fatalf("unexpected phone binding %+v (%v)", bound, err)
+	}
+	refreshed := encryptWechatPhone(t, refreshedKey, testWechatAppID, "852", "51234567")
+	bound, err = svc.BindWechatPhone(ctx, user.ID, "code-1-new", refreshed, testWechatIV)
+	if err != nil || bound.Phone != "+85251234567" || accounts.users[user.ID].WxSessionKey != refreshedKey {
+		t.Fatalf("code should refresh the session key before decrypting: %+v (%v)", bound, err)
+	}
+	if _, err := svc.BindWechatPhone(ctx, user.ID, "code-2", encrypted, testWechatIV); !errors.Is(err, ErrWechatCodeInvalid) {
+		t.Fatalf("a session of another user must be rejected, got %v", err)
+	}
+
+	other, _ := svc.WechatLogin(ctx, "code-2")
+	taken := encryptWechatPhone(t, testWechatSessionKey, testWechatAppID, "852", "51234567")
+	if _, err := svc.BindWechatPhone(ctx, other.ID, "", taken, testWechatIV); !errors.Is(err, ErrThirdPartyAlreadyBound) {
+		t.Fatalf("expected ErrThirdPartyAlreadyBound, got %v", err)
+	}
+	accounts.users[other.ID].Status = 0
+	if _, err := svc.WechatLogin(ctx, "code-2"); !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("expected ErrUserDisabled, got %v", err)
+	}
+	if _, err := svc.BindWechatPhone(ctx, 404, "", encrypted, testWechatIV); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserAuthBindAccountResolvesWechatOpenIDFromCode(t *testing.T) {
+	server := newWechatStubServer(t, map[string]WechatSession{
+		"code-7": {OpenID: "openid-7", SessionKey: testWechatSessionKey},
+	})
+	accounts := newFakeAccountStore(7)
+	svc := NewUserAuthServiceWithPolicy(&fakeCodeStore{ok: true}, UserAuthPolicy{
+		Accounts: accounts,
+		Wechat:   NewWechatAuthClient(WechatClientConfig{AppID: testWechatAppID, AppSecret: "secret", Endpoint: server.URL}),
+	})
+	ctx := context.Background()
+	if err := svc.SendSMS("13800000007", "0007"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AuthorizeBinding(7, "13800000007", "0007"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.BindAccount(ctx, 7, "wechat", "openid-victim"); !errors.Is(err, ErrWechatCodeInvalid) {
+		t.Fatalf("a caller-supplied openid must not be bound, got %v", err)
+	}
+	if accounts.users[7].WxOpenID != "" {
+		t.Fatalf("rejected binding must not persist an openid, got %q", accounts.users[7].WxOpenID)
+	}
+	if err := svc.BindAccount(ctx, 7, "wechat", "code-7"); err != nil {
+		t.Fatalf("failed code must keep the confirmation window open: %v", err)
+	}
+	if accounts.users[7].WxOpenID != "openid-7" {
+		t.Fatalf("openid should come from code2session, got %q", accounts.users[7].WxOpenID)
+	}
+
+	unconfigured := NewUserAuthServiceWithPolicy(&fakeCodeStore{ok: true}, UserAuthPolicy{Accounts: newFakeAccountStore(8)})
+	_ = unconfigured.SendSMS("13800000008", "0008")
+	_ = unconfigured.AuthorizeBinding(8, "13800000008", "0008")
+	if err := unconfigured.BindAccount(ctx, 8, "wechat", "code-7"); !errors.Is(err, ErrWechatNotConfigured) {
+		t.Fatalf("expected ErrWechatNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_bookings_channel_created_at;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS channel;
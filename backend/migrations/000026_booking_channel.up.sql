@@ -0,0 +1,5 @@
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS channel VARCHAR(30) NOT NULL DEFAULT 'direct';
+
+-- 分析报表按渠道 + 下单时间聚合
+CREATE INDEX IF NOT EXISTS idx_bookings_channel_created_at ON bookings (channel, created_at);
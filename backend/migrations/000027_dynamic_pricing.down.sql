@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS dynamic_pricing_proposal_items;
+DROP TABLE IF EXISTS dynamic_pricing_proposals;
+DROP TABLE IF EXISTS dynamic_pricing_rules;
@@ -0,0 +1,62 @@
+-- 收益管理：动态调价规则
+CREATE TABLE IF NOT EXISTS dynamic_pricing_rules (
+    id                      BIGSERIAL        PRIMARY KEY,
+    name                    VARCHAR(100)     NOT NULL,
+    voyage_id               BIGINT           NOT NULL DEFAULT 0,  -- 0=全部航次
+    cabin_type_id           BIGINT           NOT NULL DEFAULT 0,  -- 0=全部舱型
+    min_load_factor         DOUBLE PRECISION,                     -- 上座率下限（含）
+    max_load_factor         DOUBLE PRECISION,                     -- 上座率上限（不含）
+    min_days_to_departure   INT,                                  -- 距出发天数下限（含）
+    max_days_to_departure   INT,                                  -- 距出发天数上限（含）
+    velocity_window_days    INT              NOT NULL DEFAULT 7,  -- 销售速度统计窗口（天）
+    min_velocity            DOUBLE PRECISION,                     -- 日均售出下限（含）
+    max_velocity            DOUBLE PRECISION,                     -- 日均售出上限（不含）
+    adjust_percent          DOUBLE PRECISION NOT NULL DEFAULT 0,  -- 调价比例（%）
+    floor_markup_percent    DOUBLE PRECISION NOT NULL DEFAULT 0,  -- 下限 = 结算价 × (1 + %)
+    ceiling_markup_percent  DOUBLE PRECISION,                     -- 上限 = 结算价 × (1 + %)
+    priority                INT              NOT NULL DEFAULT 0,
+    status                  SMALLINT         NOT NULL DEFAULT 1,  -- 1=启用, 0=停用
+    created_at              TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    deleted_at              TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_dynamic_pricing_rules_voyage_id ON dynamic_pricing_rules (voyage_id);
+CREATE INDEX IF NOT EXISTS idx_dynamic_pricing_rules_cabin_type_id ON dynamic_pricing_rules (cabin_type_id);
+CREATE INDEX IF NOT EXISTS idx_dynamic_pricing_rules_deleted_at ON dynamic_pricing_rules (deleted_at);
+
+-- 调价提案（审批后才生成价格版本）
+CREATE TABLE IF NOT EXISTS dynamic_pricing_proposals (
+    id            BIGSERIAL    PRIMARY KEY,
+    status        VARCHAR(20)  NOT NULL DEFAULT 'pending', -- pending/approved/rejected
+    effective_at  TIMESTAMPTZ,
+    created_by    BIGINT       NOT NULL DEFAULT 0,
+    reviewed_by   BIGINT       NOT NULL DEFAULT 0,
+    reviewed_at   TIMESTAMPTZ,
+    review_note   VARCHAR(500) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dynamic_pricing_proposals_status ON dynamic_pricing_proposals (status);
+
+CREATE TABLE IF NOT EXISTS dynamic_pricing_proposal_items (
+    id                         BIGSERIAL        PRIMARY KEY,
+    proposal_id                BIGINT           NOT NULL REFERENCES dynamic_pricing_proposals(id) ON DELETE CASCADE,
+    voyage_id                  BIGINT           NOT NULL,
+    cabin_type_id              BIGINT           NOT NULL,
+    rule_id                    BIGINT           NOT NULL DEFAULT 0,
+    base_version_id            BIGINT           NOT NULL DEFAULT 0,
+    inventory_total            INT              NOT NULL DEFAULT 0,
+    settlement_price_cents     BIGINT           NOT NULL DEFAULT 0,
+    current_sale_price_cents   BIGINT           NOT NULL DEFAULT 0,
+    proposed_sale_price_cents  BIGINT           NOT NULL DEFAULT 0,
+    load_factor                DOUBLE PRECISION NOT NULL DEFAULT 0,
+    days_to_departure          INT              NOT NULL DEFAULT 0,
+    velocity                   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reason                     VARCHAR(300)     NOT NULL DEFAULT '',
+    status                     VARCHAR(20)      NOT NULL DEFAULT 'pending', -- pending/applied/skipped
+    version_id                 BIGINT           NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_dynamic_pricing_proposal_items_proposal_id ON dynamic_pricing_proposal_items (proposal_id);
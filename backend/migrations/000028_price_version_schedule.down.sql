@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_vct_price_versions_pending;
+
+ALTER TABLE voyage_cabin_type_price_versions
+    DROP COLUMN IF EXISTS source_version_id,
+    DROP COLUMN IF EXISTS cancelled_by,
+    DROP COLUMN IF EXISTS cancelled_at;
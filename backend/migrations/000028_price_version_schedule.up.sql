@@ -0,0 +1,9 @@
+ALTER TABLE voyage_cabin_type_price_versions
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS cancelled_by BIGINT,
+    ADD COLUMN IF NOT EXISTS source_version_id BIGINT;
+
+-- 调度器与待生效列表按生效时间扫描未撤销的版本
+CREATE INDEX IF NOT EXISTS idx_vct_price_versions_pending
+    ON voyage_cabin_type_price_versions (effective_at)
+    WHERE cancelled_at IS NULL;
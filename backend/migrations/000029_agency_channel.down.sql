@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS agency_bookings;
+DROP TABLE IF EXISTS agency_statements;
+DROP TABLE IF EXISTS agency_net_rates;
+DROP TABLE IF EXISTS agency_allotments;
+DROP TABLE IF EXISTS agency_api_keys;
+DROP TABLE IF EXISTS agencies;
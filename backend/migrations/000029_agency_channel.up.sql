@@ -0,0 +1,102 @@
+-- B2B 分销渠道：分销商账户
+CREATE TABLE IF NOT EXISTS agencies (
+    id                  BIGSERIAL        PRIMARY KEY,
+    code                VARCHAR(50)      NOT NULL,
+    name                VARCHAR(100)     NOT NULL,
+    contact_name        VARCHAR(50)      NOT NULL DEFAULT '',
+    contact_phone       VARCHAR(20)      NOT NULL DEFAULT '',
+    email               VARCHAR(100)     NOT NULL DEFAULT '',
+    user_id             BIGINT           NOT NULL REFERENCES users(id),  -- 渠道下单用户
+    login_name          VARCHAR(50)      NOT NULL,
+    password_hash       VARCHAR(255)     NOT NULL DEFAULT '',
+    commission_percent  DOUBLE PRECISION NOT NULL DEFAULT 0,             -- 净价 = 售价 × (1 - %)
+    credit_limit_cents  BIGINT           NOT NULL DEFAULT 0,
+    status              SMALLINT         NOT NULL DEFAULT 1,             -- 1=启用, 0=停用
+    last_login_at       TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    deleted_at          TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_agencies_code ON agencies (code);
+CREATE UNIQUE INDEX IF NOT EXISTS uk_agencies_login_name ON agencies (login_name);
+CREATE INDEX IF NOT EXISTS idx_agencies_user_id ON agencies (user_id);
+CREATE INDEX IF NOT EXISTS idx_agencies_deleted_at ON agencies (deleted_at);
+
+-- 分销商 API Key（仅保存 SHA-256 摘要）
+CREATE TABLE IF NOT EXISTS agency_api_keys (
+    id            BIGSERIAL    PRIMARY KEY,
+    agency_id     BIGINT       NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    name          VARCHAR(100) NOT NULL DEFAULT '',
+    prefix        VARCHAR(20)  NOT NULL,
+    key_hash      VARCHAR(64)  NOT NULL,
+    last_used_at  TIMESTAMPTZ,
+    revoked_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_agency_api_keys_prefix ON agency_api_keys (prefix);
+CREATE INDEX IF NOT EXISTS idx_agency_api_keys_agency_id ON agency_api_keys (agency_id);
+
+-- 分销商航次配额：创建时扣减舱房库存，到达释放日期后未用部分退回
+CREATE TABLE IF NOT EXISTS agency_allotments (
+    id            BIGSERIAL    PRIMARY KEY,
+    agency_id     BIGINT       NOT NULL REFERENCES agencies(id),
+    voyage_id     BIGINT       NOT NULL,
+    cabin_sku_id  BIGINT       NOT NULL,
+    quantity      INT          NOT NULL,
+    used          INT          NOT NULL DEFAULT 0,
+    release_at    TIMESTAMPTZ  NOT NULL,
+    released_at   TIMESTAMPTZ,
+    created_by    BIGINT       NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_allotments_agency_voyage ON agency_allotments (agency_id, voyage_id);
+CREATE INDEX IF NOT EXISTS idx_agency_allotments_release_at ON agency_allotments (release_at) WHERE released_at IS NULL;
+
+-- 分销商固定净价，优先于默认佣金比例
+CREATE TABLE IF NOT EXISTS agency_net_rates (
+    id               BIGSERIAL    PRIMARY KEY,
+    agency_id        BIGINT       NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    voyage_id        BIGINT       NOT NULL,
+    cabin_type_id    BIGINT       NOT NULL,
+    net_price_cents  BIGINT       NOT NULL,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_agency_net_rates_agency_voyage_type ON agency_net_rates (agency_id, voyage_id, cabin_type_id);
+
+-- 分销商月结账单
+CREATE TABLE IF NOT EXISTS agency_statements (
+    id             BIGSERIAL    PRIMARY KEY,
+    agency_id      BIGINT       NOT NULL REFERENCES agencies(id),
+    period         VARCHAR(7)   NOT NULL,                    -- YYYY-MM（上海时间）
+    booking_count  INT          NOT NULL DEFAULT 0,
+    total_cents    BIGINT       NOT NULL DEFAULT 0,
+    status         VARCHAR(20)  NOT NULL DEFAULT 'issued',   -- issued/paid
+    issued_by      BIGINT       NOT NULL DEFAULT 0,
+    paid_at        TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_agency_statements_agency_period ON agency_statements (agency_id, period);
+
+-- 分销订单：渠道信息与结算净价
+CREATE TABLE IF NOT EXISTS agency_bookings (
+    id            BIGSERIAL    PRIMARY KEY,
+    agency_id     BIGINT       NOT NULL REFERENCES agencies(id),
+    booking_id    BIGINT       NOT NULL REFERENCES bookings(id),
+    allotment_id  BIGINT       NOT NULL REFERENCES agency_allotments(id),
+    net_cents     BIGINT       NOT NULL,
+    agency_ref    VARCHAR(64)  NOT NULL DEFAULT '',
+    statement_id  BIGINT       REFERENCES agency_statements(id),
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_agency_bookings_booking_id ON agency_bookings (booking_id);
+CREATE INDEX IF NOT EXISTS idx_agency_bookings_agency_created_at ON agency_bookings (agency_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_agency_bookings_statement_id ON agency_bookings (statement_id);
@@ -0,0 +1,21 @@
+-- 售罄航次舱型候补：库存释放时按登记顺序独占占座并通知
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id                BIGSERIAL    PRIMARY KEY,
+    user_id           BIGINT       NOT NULL REFERENCES users(id),
+    voyage_id         BIGINT       NOT NULL,
+    cabin_type_id     BIGINT       NOT NULL,
+    guests            INT          NOT NULL,
+    status            VARCHAR(20)  NOT NULL DEFAULT 'waiting',  -- waiting/offered/booked/expired/cancelled
+    offered_sku_id    BIGINT       NOT NULL DEFAULT 0,
+    offer_hold_id     BIGINT       NOT NULL DEFAULT 0,           -- 独占占座 cabin_holds.id
+    offered_at        TIMESTAMPTZ,
+    offer_expires_at  TIMESTAMPTZ,
+    booking_id        BIGINT       NOT NULL DEFAULT 0,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_user_id ON waitlist_entries (user_id);
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_voyage_type ON waitlist_entries (voyage_id, cabin_type_id);
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_status ON waitlist_entries (status);
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_offer_expires_at ON waitlist_entries (offer_expires_at);
@@ -0,0 +1,14 @@
+-- 将词典种子来源的港口还原到 custom_destinations，再删除港口主数据表
+INSERT INTO custom_destinations (name, country, latitude, longitude, keywords, description, status, sort_order)
+SELECT p.name_zh, p.country, p.latitude, p.longitude, p.keywords, p.source, p.status, p.sort_order
+FROM ports p
+WHERE p.source IN ('system_port_city_dictionary_seed_v1', 'system_port_city_dictionary_seed_v2')
+  AND NOT EXISTS (
+    SELECT 1
+    FROM custom_destinations existing
+    WHERE existing.name = p.name_zh
+      AND existing.country = p.country
+      AND existing.deleted_at IS NULL
+);
+
+DROP TABLE IF EXISTS ports;
@@ -0,0 +1,119 @@
+-- 港口主数据：取代港口城市词典种子与代码内置坐标表，承载 UN/LOCODE、中英文名、时区与码头信息
+CREATE TABLE IF NOT EXISTS ports (
+    id                BIGSERIAL        PRIMARY KEY,
+    code              VARCHAR(5)       NOT NULL,                  -- UN/LOCODE
+    name_zh           VARCHAR(100)     NOT NULL,
+    name_en           VARCHAR(100)     NOT NULL DEFAULT '',
+    country           VARCHAR(100)     NOT NULL DEFAULT '',
+    country_code      VARCHAR(2)       NOT NULL DEFAULT '',
+    timezone          VARCHAR(64)      NOT NULL DEFAULT 'UTC',    -- IANA 时区
+    latitude          DOUBLE PRECISION NOT NULL,
+    longitude         DOUBLE PRECISION NOT NULL,
+    keywords          TEXT             NOT NULL DEFAULT '',
+    terminal_name     VARCHAR(200)     NOT NULL DEFAULT '',
+    terminal_address  VARCHAR(500)     NOT NULL DEFAULT '',
+    terminal_notes    TEXT             NOT NULL DEFAULT '',
+    source            VARCHAR(64)      NOT NULL DEFAULT 'admin',  -- admin/csv_import/词典种子标记
+    status            SMALLINT         NOT NULL DEFAULT 1,
+    sort_order        INT              NOT NULL DEFAULT 0,
+    created_at        TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ports_code ON ports (code);
+CREATE INDEX IF NOT EXISTS idx_ports_name_zh ON ports (name_zh);
+CREATE INDEX IF NOT EXISTS idx_ports_country_code ON ports (country_code);
+
+-- 迁移 000024/000025 写入的词典种子，保留后台对坐标、关键词、状态与排序的修改
+INSERT INTO ports (code, name_zh, name_en, country, country_code, timezone, latitude, longitude, keywords, source, status, sort_order)
+SELECT seed.code, seed.name_zh, seed.name_en, seed.country, seed.country_code, seed.timezone,
+       COALESCE(existing.latitude, seed.latitude),
+       COALESCE(existing.longitude, seed.longitude),
+       COALESCE(existing.keywords, seed.keywords),
+       seed.source,
+       COALESCE(existing.status, 1),
+       COALESCE(existing.sort_order, seed.sort_order)
+FROM (
+    SELECT 'CNSHA' AS code, '上海' AS name_zh, 'Shanghai' AS name_en, '中国' AS country, 'CN' AS country_code, 'Asia/Shanghai' AS timezone, 31.2304 AS latitude, 121.4737 AS longitude, '上海,shanghai' AS keywords, 'system_port_city_dictionary_seed_v1' AS source, 1000 AS sort_order
+    UNION ALL SELECT 'CNTSN', '天津', 'Tianjin', '中国', 'CN', 'Asia/Shanghai', 39.0842, 117.2009, '天津,tianjin', 'system_port_city_dictionary_seed_v1', 995
+    UNION ALL SELECT 'CNDLC', '大连', 'Dalian', '中国', 'CN', 'Asia/Shanghai', 38.9140, 121.6147, '大连,dalian', 'system_port_city_dictionary_seed_v1', 990
+    UNION ALL SELECT 'CNTAO', '青岛', 'Qingdao', '中国', 'CN', 'Asia/Shanghai', 36.0671, 120.3826, '青岛,qingdao', 'system_port_city_dictionary_seed_v1', 985
+    UNION ALL SELECT 'CNXMN', '厦门', 'Xiamen', '中国', 'CN', 'Asia/Shanghai', 24.4798, 118.0894, '厦门,xiamen', 'system_port_city_dictionary_seed_v1', 980
+    UNION ALL SELECT 'HKHKG', '香港', 'Hong Kong', '中国', 'HK', 'Asia/Hong_Kong', 22.3193, 114.1694, '香港,hong kong,hk', 'system_port_city_dictionary_seed_v1', 975
+    UNION ALL SELECT 'TWKEL', '基隆', 'Keelung', '中国', 'TW', 'Asia/Taipei', 25.1276, 121.7392, '基隆,keelung', 'system_port_city_dictionary_seed_v1', 970
+    UNION ALL SELECT 'TWKHH', '高雄', 'Kaohsiung', '中国', 'TW', 'Asia/Taipei', 22.6273, 120.3014, '高雄,kaohsiung', 'system_port_city_dictionary_seed_v1', 965
+    UNION ALL SELECT 'JPFUK', '福冈', 'Fukuoka', '日本', 'JP', 'Asia/Tokyo', 33.5902, 130.4017, '福冈,博多,fukuoka,hakata', 'system_port_city_dictionary_seed_v1', 960
+    UNION ALL SELECT 'JPNGS', '长崎', 'Nagasaki', '日本', 'JP', 'Asia/Tokyo', 32.7503, 129.8777, '长崎,nagasaki', 'system_port_city_dictionary_seed_v1', 955
+    UNION ALL SELECT 'JPKOJ', '鹿儿岛', 'Kagoshima', '日本', 'JP', 'Asia/Tokyo', 31.5966, 130.5571, '鹿儿岛,kagoshima', 'system_port_city_dictionary_seed_v1', 950
+    UNION ALL SELECT 'JPYOK', '横滨', 'Yokohama', '日本', 'JP', 'Asia/Tokyo', 35.4437, 139.6380, '横滨,yokohama', 'system_port_city_dictionary_seed_v1', 945
+    UNION ALL SELECT 'JPUKB', '神户', 'Kobe', '日本', 'JP', 'Asia/Tokyo', 34.6901, 135.1955, '神户,kobe', 'system_port_city_dictionary_seed_v1', 940
+    UNION ALL SELECT 'JPOSA', '大阪', 'Osaka', '日本', 'JP', 'Asia/Tokyo', 34.6937, 135.5023, '大阪,osaka', 'system_port_city_dictionary_seed_v1', 935
+    UNION ALL SELECT 'KRCHA', '济州', 'Jeju', '韩国', 'KR', 'Asia/Seoul', 33.4996, 126.5312, '济州,济州岛,jeju', 'system_port_city_dictionary_seed_v1', 930
+    UNION ALL SELECT 'KRSGP', '西归浦', 'Seogwipo', '韩国', 'KR', 'Asia/Seoul', 33.2541, 126.5601, '西归浦,西归浦市,seogwipo', 'system_port_city_dictionary_seed_v1', 925
+    UNION ALL SELECT 'KRPUS', '釜山', 'Busan', '韩国', 'KR', 'Asia/Seoul', 35.1796, 129.0756, '釜山,busan', 'system_port_city_dictionary_seed_v1', 920
+    UNION ALL SELECT 'KRINC', '仁川', 'Incheon', '韩国', 'KR', 'Asia/Seoul', 37.4563, 126.7052, '仁川,incheon', 'system_port_city_dictionary_seed_v1', 915
+    UNION ALL SELECT 'SGSIN', '新加坡', 'Singapore', '新加坡', 'SG', 'Asia/Singapore', 1.2903, 103.8519, '新加坡,singapore', 'system_port_city_dictionary_seed_v1', 910
+    UNION ALL SELECT 'MYPEN', '槟城', 'Penang', '马来西亚', 'MY', 'Asia/Kuala_Lumpur', 5.4164, 100.3327, '槟城,penang', 'system_port_city_dictionary_seed_v1', 905
+    UNION ALL SELECT 'MYPKG', '巴生港', 'Port Klang', '马来西亚', 'MY', 'Asia/Kuala_Lumpur', 3.0019, 101.3910, '巴生港,port klang', 'system_port_city_dictionary_seed_v1', 900
+    UNION ALL SELECT 'THHKT', '普吉', 'Phuket', '泰国', 'TH', 'Asia/Bangkok', 7.8804, 98.3923, '普吉,phuket', 'system_port_city_dictionary_seed_v1', 895
+    UNION ALL SELECT 'THLCH', '林查班', 'Laem Chabang', '泰国', 'TH', 'Asia/Bangkok', 13.0827, 100.8830, '林查班,laem chabang', 'system_port_city_dictionary_seed_v1', 890
+    UNION ALL SELECT 'USMIA', '迈阿密', 'Miami', '美国', 'US', 'America/New_York', 25.7617, -80.1918, '迈阿密,邁阿密,miami', 'system_port_city_dictionary_seed_v1', 885
+    UNION ALL SELECT 'USPEF', '劳德代尔堡', 'Fort Lauderdale', '美国', 'US', 'America/New_York', 26.1224, -80.1373, '劳德代尔堡,罗德岱堡,fort lauderdale,port everglades', 'system_port_city_dictionary_seed_v1', 880
+    UNION ALL SELECT 'USPCV', '卡纳维拉尔港', 'Port Canaveral', '美国', 'US', 'America/New_York', 28.4089, -80.6043, '卡纳维拉尔港,奥兰多港,port canaveral', 'system_port_city_dictionary_seed_v1', 875
+    UNION ALL SELECT 'BSNAS', '拿骚', 'Nassau', '巴哈马', 'BS', 'America/Nassau', 25.0443, -77.3504, '拿骚,nassau', 'system_port_city_dictionary_seed_v1', 870
+    UNION ALL SELECT 'MXCZM', '科苏梅尔', 'Cozumel', '墨西哥', 'MX', 'America/Cancun', 20.4229839, -86.9223432, '科苏梅尔,cozumel,isla cozumel', 'system_port_city_dictionary_seed_v1', 865
+    UNION ALL SELECT 'KYGEC', '乔治城', 'George Town', '开曼群岛', 'KY', 'America/Cayman', 19.2866, -81.3674, '乔治城,george town,grand cayman', 'system_port_city_dictionary_seed_v1', 860
+    UNION ALL SELECT 'PRSJU', '圣胡安', 'San Juan', '波多黎各', 'PR', 'America/Puerto_Rico', 18.4655, -66.1057, '圣胡安,san juan', 'system_port_city_dictionary_seed_v1', 855
+    UNION ALL SELECT 'ESBCN', '巴塞罗那', 'Barcelona', '西班牙', 'ES', 'Europe/Madrid', 41.3851, 2.1734, '巴塞罗那,barcelona', 'system_port_city_dictionary_seed_v1', 850
+    UNION ALL SELECT 'ITCVV', '奇维塔韦基亚', 'Civitavecchia', '意大利', 'IT', 'Europe/Rome', 42.0924, 11.7950, '奇维塔韦基亚,罗马港,civitavecchia', 'system_port_city_dictionary_seed_v1', 845
+    UNION ALL SELECT 'ITNAP', '那不勒斯', 'Naples', '意大利', 'IT', 'Europe/Rome', 40.8518, 14.2681, '那不勒斯,naples,napoli', 'system_port_city_dictionary_seed_v1', 840
+    UNION ALL SELECT 'GRPIR', '比雷埃夫斯', 'Piraeus', '希腊', 'GR', 'Europe/Athens', 37.9420, 23.6465, '比雷埃夫斯,雅典港,piraeus', 'system_port_city_dictionary_seed_v1', 835
+    UNION ALL SELECT 'FRMRS', '马赛', 'Marseille', '法国', 'FR', 'Europe/Paris', 43.2965, 5.3698, '马赛,marseille', 'system_port_city_dictionary_seed_v1', 830
+    UNION ALL SELECT 'PTLIS', '里斯本', 'Lisbon', '葡萄牙', 'PT', 'Europe/Lisbon', 38.7223, -9.1393, '里斯本,lisbon,lisboa', 'system_port_city_dictionary_seed_v1', 825
+    UNION ALL SELECT 'GBSOU', '南安普敦', 'Southampton', '英国', 'GB', 'Europe/London', 50.9097, -1.4044, '南安普敦,southampton', 'system_port_city_dictionary_seed_v1', 820
+    UNION ALL SELECT 'ARBUE', '布宜诺斯艾利斯', 'Buenos Aires', '阿根廷', 'AR', 'America/Argentina/Buenos_Aires', -34.6037, -58.3816, '布宜诺斯艾利斯,布宜諾斯艾利斯,buenos aires', 'system_port_city_dictionary_seed_v1', 815
+    UNION ALL SELECT 'UYMVD', '蒙得维的亚', 'Montevideo', '乌拉圭', 'UY', 'America/Montevideo', -34.9011, -56.1645, '蒙得维的亚,montevideo', 'system_port_city_dictionary_seed_v1', 810
+    UNION ALL SELECT 'BRSSZ', '桑托斯', 'Santos', '巴西', 'BR', 'America/Sao_Paulo', -23.9608, -46.3336, '桑托斯,santos', 'system_port_city_dictionary_seed_v1', 805
+    UNION ALL SELECT 'BRRIO', '里约热内卢', 'Rio de Janeiro', '巴西', 'BR', 'America/Sao_Paulo', -22.9068, -43.1729, '里约热内卢,rio de janeiro', 'system_port_city_dictionary_seed_v1', 800
+    UNION ALL SELECT 'AUSYD', '悉尼', 'Sydney', '澳大利亚', 'AU', 'Australia/Sydney', -33.8688, 151.2093, '悉尼,sydney', 'system_port_city_dictionary_seed_v1', 795
+    UNION ALL SELECT 'NZAKL', '奥克兰', 'Auckland', '新西兰', 'NZ', 'Pacific/Auckland', -36.8509, 174.7645, '奥克兰,auckland', 'system_port_city_dictionary_seed_v1', 790
+    UNION ALL SELECT 'CAVAN', '温哥华', 'Vancouver', '加拿大', 'CA', 'America/Vancouver', 49.2827, -123.1207, '温哥华,vancouver', 'system_port_city_dictionary_seed_v2', 780
+    UNION ALL SELECT 'CAVIC', '维多利亚', 'Victoria', '加拿大', 'CA', 'America/Vancouver', 48.4284, -123.3656, '维多利亚,victoria bc', 'system_port_city_dictionary_seed_v2', 775
+    UNION ALL SELECT 'USJNU', '朱诺', 'Juneau', '美国', 'US', 'America/Juneau', 58.3019, -134.4197, '朱诺,juneau', 'system_port_city_dictionary_seed_v2', 770
+    UNION ALL SELECT 'USSGY', '斯卡格威', 'Skagway', '美国', 'US', 'America/Juneau', 59.4583, -135.3139, '斯卡格威,skagway', 'system_port_city_dictionary_seed_v2', 765
+    UNION ALL SELECT 'USKTN', '凯奇坎', 'Ketchikan', '美国', 'US', 'America/Sitka', 55.3422, -131.6461, '凯奇坎,ketchikan', 'system_port_city_dictionary_seed_v2', 760
+    UNION ALL SELECT 'USWTR', '惠蒂尔', 'Whittier', '美国', 'US', 'America/Anchorage', 60.7743, -148.6837, '惠蒂尔,whittier alaska', 'system_port_city_dictionary_seed_v2', 755
+    UNION ALL SELECT 'USSEA', '西雅图', 'Seattle', '美国', 'US', 'America/Los_Angeles', 47.6062, -122.3321, '西雅图,seattle', 'system_port_city_dictionary_seed_v2', 750
+    UNION ALL SELECT 'DKCPH', '哥本哈根', 'Copenhagen', '丹麦', 'DK', 'Europe/Copenhagen', 55.6761, 12.5683, '哥本哈根,copenhagen', 'system_port_city_dictionary_seed_v2', 745
+    UNION ALL SELECT 'SESTO', '斯德哥尔摩', 'Stockholm', '瑞典', 'SE', 'Europe/Stockholm', 59.3293, 18.0686, '斯德哥尔摩,stockholm', 'system_port_city_dictionary_seed_v2', 740
+    UNION ALL SELECT 'FIHEL', '赫尔辛基', 'Helsinki', '芬兰', 'FI', 'Europe/Helsinki', 60.1699, 24.9384, '赫尔辛基,helsinki', 'system_port_city_dictionary_seed_v2', 735
+    UNION ALL SELECT 'NOOSL', '奥斯陆', 'Oslo', '挪威', 'NO', 'Europe/Oslo', 59.9139, 10.7522, '奥斯陆,oslo', 'system_port_city_dictionary_seed_v2', 730
+    UNION ALL SELECT 'ISREY', '雷克雅未克', 'Reykjavik', '冰岛', 'IS', 'Atlantic/Reykjavik', 64.1466, -21.9426, '雷克雅未克,reykjavik', 'system_port_city_dictionary_seed_v2', 725
+    UNION ALL SELECT 'EETLL', '塔林', 'Tallinn', '爱沙尼亚', 'EE', 'Europe/Tallinn', 59.4370, 24.7536, '塔林,tallinn', 'system_port_city_dictionary_seed_v2', 720
+    UNION ALL SELECT 'IEDUB', '都柏林', 'Dublin', '爱尔兰', 'IE', 'Europe/Dublin', 53.3498, -6.2603, '都柏林,dublin', 'system_port_city_dictionary_seed_v2', 715
+    UNION ALL SELECT 'ITVCE', '威尼斯', 'Venice', '意大利', 'IT', 'Europe/Rome', 45.4408, 12.3155, '威尼斯,venice,venezia', 'system_port_city_dictionary_seed_v2', 710
+    UNION ALL SELECT 'ITGOA', '热那亚', 'Genoa', '意大利', 'IT', 'Europe/Rome', 44.4056, 8.9463, '热那亚,genoa,genova', 'system_port_city_dictionary_seed_v2', 705
+    UNION ALL SELECT 'ESPMI', '帕尔马', 'Palma de Mallorca', '西班牙', 'ES', 'Europe/Madrid', 39.5696, 2.6502, '帕尔马,palma de mallorca', 'system_port_city_dictionary_seed_v2', 700
+    UNION ALL SELECT 'MTMLA', '瓦莱塔', 'Valletta', '马耳他', 'MT', 'Europe/Malta', 35.8989, 14.5146, '瓦莱塔,valletta', 'system_port_city_dictionary_seed_v2', 695
+    UNION ALL SELECT 'GRJTR', '圣托里尼', 'Santorini', '希腊', 'GR', 'Europe/Athens', 36.3932, 25.4615, '圣托里尼,santorini,thira', 'system_port_city_dictionary_seed_v2', 690
+    UNION ALL SELECT 'GRJMK', '米科诺斯', 'Mykonos', '希腊', 'GR', 'Europe/Athens', 37.4467, 25.3289, '米科诺斯,mykonos', 'system_port_city_dictionary_seed_v2', 685
+    UNION ALL SELECT 'TRIST', '伊斯坦布尔', 'Istanbul', '土耳其', 'TR', 'Europe/Istanbul', 41.0082, 28.9784, '伊斯坦布尔,istanbul', 'system_port_city_dictionary_seed_v2', 680
+    UNION ALL SELECT 'QADOH', '多哈', 'Doha', '卡塔尔', 'QA', 'Asia/Qatar', 25.2854, 51.5310, '多哈,doha', 'system_port_city_dictionary_seed_v2', 675
+    UNION ALL SELECT 'AEDXB', '迪拜', 'Dubai', '阿联酋', 'AE', 'Asia/Dubai', 25.2048, 55.2708, '迪拜,dubai', 'system_port_city_dictionary_seed_v2', 670
+    UNION ALL SELECT 'AEAUH', '阿布扎比', 'Abu Dhabi', '阿联酋', 'AE', 'Asia/Dubai', 24.4539, 54.3773, '阿布扎比,abu dhabi', 'system_port_city_dictionary_seed_v2', 665
+    UNION ALL SELECT 'BBBGI', '布里奇顿', 'Bridgetown', '巴巴多斯', 'BB', 'America/Barbados', 13.0975, -59.6167, '布里奇顿,bridgetown', 'system_port_city_dictionary_seed_v2', 660
+    UNION ALL SELECT 'SXPHI', '菲利普斯堡', 'Philipsburg', '荷属圣马丁', 'SX', 'America/Lower_Princes', 18.0260, -63.0458, '菲利普斯堡,philipsburg,st maarten', 'system_port_city_dictionary_seed_v2', 655
+    UNION ALL SELECT 'JMFMH', '法尔茅斯', 'Falmouth', '牙买加', 'JM', 'America/Jamaica', 18.4928, -77.6563, '法尔茅斯,falmouth jamaica', 'system_port_city_dictionary_seed_v2', 650
+    UNION ALL SELECT 'HNRTB', '罗阿坦', 'Roatan', '洪都拉斯', 'HN', 'America/Tegucigalpa', 16.3170, -86.5371, '罗阿坦,roatan', 'system_port_city_dictionary_seed_v2', 645
+) AS seed
+LEFT JOIN custom_destinations existing
+    ON existing.name = seed.name_zh
+   AND existing.country = seed.country
+   AND existing.description = seed.source
+   AND existing.deleted_at IS NULL
+WHERE NOT EXISTS (
+    SELECT 1 FROM ports p WHERE p.code = seed.code
+);
+
+DELETE FROM custom_destinations
+WHERE description IN ('system_port_city_dictionary_seed_v1', 'system_port_city_dictionary_seed_v2');
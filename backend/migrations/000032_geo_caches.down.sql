@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS geocode_cache;
+DROP TABLE IF EXISTS route_map_cache;
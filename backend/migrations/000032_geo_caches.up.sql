@@ -0,0 +1,29 @@
+-- 航线地图缓存：按有序停靠港坐标与分辨率的哈希唯一，避免航次详情每次逐段请求航线服务
+CREATE TABLE IF NOT EXISTS route_map_cache (
+    id             BIGSERIAL    PRIMARY KEY,
+    cache_key      VARCHAR(64)  NOT NULL,
+    provider       VARCHAR(30)  NOT NULL,
+    resolution_km  INT          NOT NULL,
+    stop_count     INT          NOT NULL,
+    payload        TEXT         NOT NULL,                        -- 航线地图 JSON
+    expires_at     TIMESTAMPTZ,                                  -- 为空表示长期有效
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_route_map_cache_key ON route_map_cache (cache_key);
+CREATE INDEX IF NOT EXISTS idx_route_map_cache_expires_at ON route_map_cache (expires_at);
+
+-- 地理编码缓存：按服务商与规范化查询词缓存外部城市搜索结果，过期后重新请求
+CREATE TABLE IF NOT EXISTS geocode_cache (
+    id          BIGSERIAL     PRIMARY KEY,
+    provider    VARCHAR(30)   NOT NULL,
+    query       VARCHAR(200)  NOT NULL,
+    payload     TEXT          NOT NULL,                         -- 原始结果 JSON
+    expires_at  TIMESTAMPTZ   NOT NULL,
+    created_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_geocode_cache_query ON geocode_cache (provider, query);
+CREATE INDEX IF NOT EXISTS idx_geocode_cache_expires_at ON geocode_cache (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE voyage_itineraries
+    DROP COLUMN IF EXISTS timezone;
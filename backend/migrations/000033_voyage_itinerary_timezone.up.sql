@@ -0,0 +1,2 @@
+ALTER TABLE voyage_itineraries
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS voyage_series;
+DROP INDEX IF EXISTS idx_voyages_series_id;
+ALTER TABLE voyages
+    DROP COLUMN IF EXISTS series_id;
@@ -0,0 +1,26 @@
+ALTER TABLE voyages
+    ADD COLUMN IF NOT EXISTS series_id BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_voyages_series_id ON voyages (series_id);
+
+-- 航次系列：记录按模板航次与重复规则批量生成航次的参数，生成的航次通过 voyages.series_id 关联
+CREATE TABLE IF NOT EXISTS voyage_series (
+    id                  BIGSERIAL     PRIMARY KEY,
+    name                VARCHAR(100)  NOT NULL,
+    template_voyage_id  BIGINT        NOT NULL,
+    cruise_id           BIGINT        NOT NULL,
+    code_prefix         VARCHAR(30)   NOT NULL,
+    frequency           VARCHAR(20)   NOT NULL,                  -- daily / weekly / monthly
+    repeat_interval     INT           NOT NULL DEFAULT 1,
+    weekdays            VARCHAR(20)   NOT NULL DEFAULT '',       -- 0=周日，逗号分隔
+    start_date          TIMESTAMPTZ   NOT NULL,
+    end_date            TIMESTAMPTZ   NOT NULL,
+    cabin_prices_json   TEXT          NOT NULL DEFAULT '',        -- 初始舱型库存与价格
+    voyage_count        INT           NOT NULL DEFAULT 0,
+    created_by          BIGINT,
+    created_at          TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_voyage_series_template_voyage_id ON voyage_series (template_voyage_id);
+CREATE INDEX IF NOT EXISTS idx_voyage_series_cruise_id ON voyage_series (cruise_id);
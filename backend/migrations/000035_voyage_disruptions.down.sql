@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS travel_credits;
+DROP TABLE IF EXISTS disruption_bookings;
+DROP TABLE IF EXISTS voyage_disruptions;
+ALTER TABLE voyages
+    DROP COLUMN IF EXISTS disruption;
@@ -0,0 +1,71 @@
+ALTER TABLE voyages
+    ADD COLUMN IF NOT EXISTS disruption VARCHAR(20) NOT NULL DEFAULT '';
+
+-- 航次停航/变更事件：创建时快照受影响订单，逐单跟踪改签、全额退款或抵用金处理结果
+CREATE TABLE IF NOT EXISTS voyage_disruptions (
+    id              BIGSERIAL     PRIMARY KEY,
+    voyage_id       BIGINT        NOT NULL,
+    type            VARCHAR(20)   NOT NULL,                  -- cancelled / modified
+    reason          TEXT          NOT NULL DEFAULT '',
+    status          VARCHAR(20)   NOT NULL,                  -- open / closed
+    affected_count  INT           NOT NULL DEFAULT 0,
+    resolved_count  INT           NOT NULL DEFAULT 0,
+    created_by      BIGINT,
+    closed_at       TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_voyage_disruptions_voyage_id ON voyage_disruptions (voyage_id);
+CREATE INDEX IF NOT EXISTS idx_voyage_disruptions_status ON voyage_disruptions (status);
+
+CREATE TABLE IF NOT EXISTS disruption_bookings (
+    id                      BIGSERIAL     PRIMARY KEY,
+    disruption_id           BIGINT        NOT NULL,
+    booking_id              BIGINT        NOT NULL,
+    user_id                 BIGINT        NOT NULL,
+    cabin_sku_id            BIGINT        NOT NULL DEFAULT 0,
+    cabin_type_id           BIGINT        NOT NULL DEFAULT 0,
+    guests                  INT           NOT NULL DEFAULT 0,
+    booking_status          VARCHAR(30)   NOT NULL DEFAULT '',
+    total_cents             BIGINT        NOT NULL DEFAULT 0,
+    paid_cents              BIGINT        NOT NULL DEFAULT 0,
+    status                  VARCHAR(20)   NOT NULL,          -- pending / offered / resolved
+    offered_options         VARCHAR(50)   NOT NULL DEFAULT '',
+    alternative_voyage_ids  VARCHAR(500)  NOT NULL DEFAULT '',
+    waive_price_difference  BOOLEAN       NOT NULL DEFAULT FALSE,
+    credit_bonus_percent    INT           NOT NULL DEFAULT 0,
+    offered_at              TIMESTAMPTZ,
+    resolution              VARCHAR(20)   NOT NULL DEFAULT '', -- rebook / refund / credit
+    new_booking_id          BIGINT        NOT NULL DEFAULT 0,
+    new_voyage_id           BIGINT        NOT NULL DEFAULT 0,
+    price_diff_cents        BIGINT        NOT NULL DEFAULT 0,
+    refund_cents            BIGINT        NOT NULL DEFAULT 0,
+    credit_id               BIGINT        NOT NULL DEFAULT 0,
+    resolved_by             BIGINT        NOT NULL DEFAULT 0,
+    resolved_at             TIMESTAMPTZ,
+    remark                  TEXT          NOT NULL DEFAULT '',
+    created_at              TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_disruption_bookings_booking ON disruption_bookings (disruption_id, booking_id);
+CREATE INDEX IF NOT EXISTS idx_disruption_bookings_user_id ON disruption_bookings (user_id);
+CREATE INDEX IF NOT EXISTS idx_disruption_bookings_status ON disruption_bookings (status);
+
+-- 出行抵用金：停航/变更时以抵用金代替退款发放给用户
+CREATE TABLE IF NOT EXISTS travel_credits (
+    id                 BIGSERIAL     PRIMARY KEY,
+    user_id            BIGINT        NOT NULL,
+    amount_cents       BIGINT        NOT NULL,
+    balance_cents      BIGINT        NOT NULL,
+    source_booking_id  BIGINT        NOT NULL DEFAULT 0,
+    disruption_id      BIGINT        NOT NULL DEFAULT 0,
+    status             VARCHAR(20)   NOT NULL,
+    expires_at         TIMESTAMPTZ   NOT NULL,
+    created_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_travel_credits_user_id ON travel_credits (user_id);
+CREATE INDEX IF NOT EXISTS idx_travel_credits_disruption_id ON travel_credits (disruption_id);
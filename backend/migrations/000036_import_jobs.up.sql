@@ -0,0 +1,23 @@
+-- 异步批量导入任务：记录导入模式、逐行统计与失败行错误报告
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id            BIGSERIAL     PRIMARY KEY,
+    kind          VARCHAR(50)   NOT NULL,
+    status        VARCHAR(20)   NOT NULL,                  -- pending / running / succeeded / failed
+    mode          VARCHAR(20)   NOT NULL,                  -- all_or_nothing / best_effort
+    dry_run       BOOLEAN       NOT NULL DEFAULT FALSE,
+    file_name     VARCHAR(255)  NOT NULL DEFAULT '',
+    total         INT           NOT NULL DEFAULT 0,
+    succeeded     INT           NOT NULL DEFAULT 0,
+    failed        INT           NOT NULL DEFAULT 0,
+    committed     BOOLEAN       NOT NULL DEFAULT FALSE,
+    message       TEXT          NOT NULL DEFAULT '',
+    error_report  TEXT          NOT NULL DEFAULT '',
+    created_by    BIGINT,
+    started_at    TIMESTAMPTZ,
+    finished_at   TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_kind ON import_jobs (kind);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs (status);
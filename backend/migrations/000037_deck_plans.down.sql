@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS deck_plan_cabins;
+DROP TABLE IF EXISTS deck_plans;
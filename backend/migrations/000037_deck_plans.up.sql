@@ -0,0 +1,27 @@
+-- 甲板平面图：按邮轮与甲板层维护平面图图片，舱位坐标按船上舱房号对应各航次的舱房
+CREATE TABLE IF NOT EXISTS deck_plans (
+    id          BIGSERIAL     PRIMARY KEY,
+    cruise_id   BIGINT        NOT NULL,
+    deck        VARCHAR(20)   NOT NULL,
+    name        VARCHAR(100)  NOT NULL DEFAULT '',
+    image_url   VARCHAR(500)  NOT NULL DEFAULT '',
+    width       INT           NOT NULL DEFAULT 0,
+    height      INT           NOT NULL DEFAULT 0,
+    sort_order  INT           NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deck_plans_cruise_deck ON deck_plans (cruise_id, deck);
+
+CREATE TABLE IF NOT EXISTS deck_plan_cabins (
+    id            BIGSERIAL    PRIMARY KEY,
+    deck_plan_id  BIGINT       NOT NULL,
+    cabin_number  VARCHAR(50)  NOT NULL,
+    x             INT          NOT NULL DEFAULT 0,
+    y             INT          NOT NULL DEFAULT 0,
+    width         INT          NOT NULL DEFAULT 0,
+    height        INT          NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deck_plan_cabins_number ON deck_plan_cabins (deck_plan_id, cabin_number);
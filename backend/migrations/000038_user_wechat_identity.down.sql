@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_users_wx_union_id;
+DROP INDEX IF EXISTS idx_users_alipay_uid;
+DROP INDEX IF EXISTS idx_users_wx_open_id;
+DROP INDEX IF EXISTS idx_users_phone;
+
+-- 恢复列级唯一约束前把空字符串还原为 NULL，避免多条空值冲突
+UPDATE users SET phone = NULL WHERE phone = '';
+UPDATE users SET wx_open_id = NULL WHERE wx_open_id = '';
+UPDATE users SET alipay_uid = NULL WHERE alipay_uid = '';
+ALTER TABLE users
+  ADD CONSTRAINT users_phone_key UNIQUE (phone),
+  ADD CONSTRAINT users_wx_open_id_key UNIQUE (wx_open_id),
+  ADD CONSTRAINT users_alipay_uid_key UNIQUE (alipay_uid);
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS wx_session_key,
+  DROP COLUMN IF EXISTS wx_union_id;
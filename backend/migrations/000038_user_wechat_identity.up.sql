@@ -0,0 +1,14 @@
+-- 微信小程序登录：记录 UnionID 与服务端会话密钥（用于解密手机号授权数据）
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS wx_union_id VARCHAR(80) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS wx_session_key VARCHAR(64) NOT NULL DEFAULT '';
+
+-- 未绑定的登录标识以空字符串存储，原列级 UNIQUE 约束会让第二个未绑定的用户冲突，改为只约束非空值的唯一索引
+ALTER TABLE users
+  DROP CONSTRAINT IF EXISTS users_phone_key,
+  DROP CONSTRAINT IF EXISTS users_wx_open_id_key,
+  DROP CONSTRAINT IF EXISTS users_alipay_uid_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone ON users (phone) WHERE phone <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_wx_open_id ON users (wx_open_id) WHERE wx_open_id <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_alipay_uid ON users (alipay_uid) WHERE alipay_uid <> '';
+CREATE INDEX IF NOT EXISTS idx_users_wx_union_id ON users (wx_union_id);
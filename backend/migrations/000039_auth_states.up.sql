@@ -0,0 +1,9 @@
+-- 认证风控状态：验证码重发间隔、有效期、失败次数、锁定与绑定授权窗口，多副本共享，过期后由后台任务清理
+CREATE TABLE IF NOT EXISTS auth_states (
+    state_key   VARCHAR(128)  PRIMARY KEY,
+    count       BIGINT        NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ   NOT NULL,
+    updated_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_states_expires_at ON auth_states (expires_at);
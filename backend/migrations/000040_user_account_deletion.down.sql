@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS anonymized_at,
+  DROP COLUMN IF EXISTS deletion_scheduled_at,
+  DROP COLUMN IF EXISTS deletion_requested_at;
@@ -0,0 +1,7 @@
+-- 用户自助注销：申请后进入冷静期，到期后匿名化个人信息，订单与支付等财务记录保留
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
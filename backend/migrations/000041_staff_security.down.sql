@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS staff_password_reset_tokens;
+DROP TABLE IF EXISTS staff_password_histories;
+
+ALTER TABLE staffs
+  DROP COLUMN IF EXISTS totp_last_step,
+  DROP COLUMN IF EXISTS totp_enabled_at,
+  DROP COLUMN IF EXISTS totp_secret,
+  DROP COLUMN IF EXISTS locked_until,
+  DROP COLUMN IF EXISTS failed_login_count,
+  DROP COLUMN IF EXISTS must_change_password,
+  DROP COLUMN IF EXISTS password_changed_at;
@@ -0,0 +1,34 @@
+-- 员工账号安全：密码定期更换与历史校验、连续失败锁定、一次性重置令牌与可选 TOTP 二次验证
+ALTER TABLE staffs
+  ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS failed_login_count INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64),
+  ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- 存量账号从上线时刻开始计算密码有效期，避免升级后全员被强制改密
+UPDATE staffs SET password_changed_at = CURRENT_TIMESTAMP WHERE password_changed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS staff_password_histories (
+    id             BIGSERIAL     PRIMARY KEY,
+    staff_id       BIGINT        NOT NULL REFERENCES staffs(id) ON DELETE CASCADE,
+    password_hash  VARCHAR(255)  NOT NULL,
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_password_histories_staff_id ON staff_password_histories (staff_id);
+
+CREATE TABLE IF NOT EXISTS staff_password_reset_tokens (
+    id          BIGSERIAL     PRIMARY KEY,
+    staff_id    BIGINT        NOT NULL REFERENCES staffs(id) ON DELETE CASCADE,
+    token_hash  VARCHAR(64)   NOT NULL,
+    created_by  BIGINT        NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ   NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_staff_password_reset_tokens_token_hash ON staff_password_reset_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_staff_password_reset_tokens_staff_id ON staff_password_reset_tokens (staff_id);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS operation_log_chain_heads;
+
+DROP INDEX IF EXISTS idx_operation_logs_resource;
+
+ALTER TABLE operation_logs
+  DROP COLUMN IF EXISTS hash,
+  DROP COLUMN IF EXISTS prev_hash,
+  DROP COLUMN IF EXISTS changes,
+  DROP COLUMN IF EXISTS status_code,
+  DROP COLUMN IF EXISTS route,
+  DROP COLUMN IF EXISTS method;
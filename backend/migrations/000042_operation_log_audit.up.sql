@@ -0,0 +1,20 @@
+-- 操作审计：记录请求方法、路由、状态码与前后差异，并以哈希链提供防篡改校验
+ALTER TABLE operation_logs
+  ADD COLUMN IF NOT EXISTS method VARCHAR(10) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS route VARCHAR(200) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS status_code INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS changes TEXT,
+  ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS hash VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_operation_logs_resource ON operation_logs (resource, resource_id);
+
+-- 哈希链链头：单行表，追加日志时加行锁串行化，保证并发写入不会分叉
+CREATE TABLE IF NOT EXISTS operation_log_chain_heads (
+    id          INT          PRIMARY KEY,
+    last_log_id BIGINT       NOT NULL DEFAULT 0,
+    last_hash   VARCHAR(64)  NOT NULL DEFAULT '',
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO operation_log_chain_heads (id, last_log_id, last_hash) VALUES (1, 0, '') ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,9 @@
+-- 员工公司数据范围：合作方运营人员只能访问被授权公司的邮轮、航次、舱房与订单；无记录表示不限
+CREATE TABLE IF NOT EXISTS staff_companies (
+    staff_id    BIGINT       NOT NULL REFERENCES staffs(id) ON DELETE CASCADE,
+    company_id  BIGINT       NOT NULL REFERENCES cruise_companies(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (staff_id, company_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_staff_companies_company_id ON staff_companies (company_id);
@@ -0,0 +1,13 @@
+-- 幂等键：保存带 Idempotency-Key 的下单、退款等请求的指纹与响应，有效期内的重试直接重放原响应
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idem_key       VARCHAR(64)   PRIMARY KEY,
+    fingerprint    VARCHAR(64)   NOT NULL,
+    status_code    INT           NOT NULL DEFAULT 0,
+    content_type   VARCHAR(100)  NOT NULL DEFAULT '',
+    response_body  TEXT          NOT NULL DEFAULT '',
+    expires_at     TIMESTAMPTZ   NOT NULL,
+    created_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
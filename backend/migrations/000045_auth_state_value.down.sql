@@ -0,0 +1,2 @@
+ALTER TABLE auth_states
+  DROP COLUMN IF EXISTS state_value;
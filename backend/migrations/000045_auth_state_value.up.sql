@@ -0,0 +1,3 @@
+-- 认证风控状态增加取值列：短信验证码（哈希）与重发间隔、失败次数一起存放在共享存储中，任一副本都能校验
+ALTER TABLE auth_states
+  ADD COLUMN IF NOT EXISTS state_value VARCHAR(128) NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- 员工公司数据范围改为默认拒绝：只有标记 all_companies 的平台管理员可访问全部公司，
+-- 其余员工只能访问 staff_companies 中授权的公司，没有授权记录时看不到任何公司的数据。
+ALTER TABLE staffs
+  ADD COLUMN IF NOT EXISTS all_companies BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- 上线时保持存量员工的访问范围不变：此前没有授权记录即不受限，因此没有任何授权记录的存量员工
+-- （平台运营、财务、客服等内部账号）标记为可访问全部公司；已按公司授权的合作方运营人员保持原授权。
+-- 默认拒绝只对此后新建的账号生效，新建的内部账号需由平台管理员显式授予全部公司。
+UPDATE staffs SET all_companies = TRUE
+ WHERE NOT EXISTS (SELECT 1 FROM staff_companies WHERE staff_companies.staff_id = staffs.id);
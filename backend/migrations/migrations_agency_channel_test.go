@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var agencyChannelTables = []string{
+	"agencies",
+	"agency_api_keys",
+	"agency_allotments",
+	"agency_net_rates",
+	"agency_statements",
+	"agency_bookings",
+}
+
+func TestAgencyChannelMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000029_agency_channel.up.sql",
+		"000029_agency_channel.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestAgencyChannelMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	upBytes, err := os.ReadFile("000029_agency_channel.up.sql")
+	if err != nil {
+		t.Fatalf("read up migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(upBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute up statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	for _, table := range agencyChannelTables {
+		assertTableExists(t, db, table)
+	}
+	assertColumnExists(t, db, "agencies", "credit_limit_cents")
+	assertColumnExists(t, db, "agency_allotments", "release_at")
+	assertColumnExists(t, db, "agency_bookings", "statement_id")
+
+	downBytes, err := os.ReadFile("000029_agency_channel.down.sql")
+	if err != nil {
+		t.Fatalf("read down migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(downBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute down statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	for _, table := range agencyChannelTables {
+		assertTableMissing(t, db, table)
+	}
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestAuthStateValueMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000045_auth_state_value.up.sql",
+		"000045_auth_state_value.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestAuthStateValueMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000039_auth_states.up.sql")
+	execMigrationFileWithoutConstraints(t, db, "000045_auth_state_value.up.sql")
+	assertColumnExists(t, db, "auth_states", "state_value")
+
+	execMigrationFile(t, db, "000045_auth_state_value.down.sql")
+	if db.Migrator().HasColumn("auth_states", "state_value") {
+		t.Fatal("expected state_value to be dropped")
+	}
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestAuthStatesMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000039_auth_states.up.sql",
+		"000039_auth_states.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestAuthStatesMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000039_auth_states.up.sql")
+	assertTableExists(t, db, "auth_states")
+	assertColumnExists(t, db, "auth_states", "state_key")
+	assertColumnExists(t, db, "auth_states", "expires_at")
+
+	execMigrationFile(t, db, "000039_auth_states.down.sql")
+	assertTableMissing(t, db, "auth_states")
+}
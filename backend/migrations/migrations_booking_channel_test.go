@@ -0,0 +1,65 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestBookingChannelMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000026_booking_channel.up.sql",
+		"000026_booking_channel.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestBookingChannelMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE bookings (id INTEGER PRIMARY KEY AUTOINCREMENT, status TEXT NOT NULL, created_at DATETIME);`).Error; err != nil {
+		t.Fatalf("create bookings failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO bookings (status, created_at) VALUES ('paid', CURRENT_TIMESTAMP);`).Error; err != nil {
+		t.Fatalf("seed bookings failed: %v", err)
+	}
+
+	upBytes, err := os.ReadFile("000026_booking_channel.up.sql")
+	if err != nil {
+		t.Fatalf("read up migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(upBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute up statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	assertColumnExists(t, db, "bookings", "channel")
+
+	var channel string
+	if err := db.Raw(`SELECT channel FROM bookings LIMIT 1`).Scan(&channel).Error; err != nil {
+		t.Fatalf("query channel failed: %v", err)
+	}
+	if channel != "direct" {
+		t.Fatalf("expected existing bookings to default to direct channel, got %q", channel)
+	}
+
+	downBytes, err := os.ReadFile("000026_booking_channel.down.sql")
+	if err != nil {
+		t.Fatalf("read down migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(downBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute down statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestDeckPlanMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000037_deck_plans.up.sql",
+		"000037_deck_plans.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestDeckPlanMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000037_deck_plans.up.sql")
+	assertTableExists(t, db, "deck_plans")
+	assertTableExists(t, db, "deck_plan_cabins")
+	if err := db.Exec(`INSERT INTO deck_plans (cruise_id, deck) VALUES (1, '8')`).Error; err != nil {
+		t.Fatalf("insert deck plan failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO deck_plans (cruise_id, deck) VALUES (1, '8')`).Error; err == nil {
+		t.Fatal("expected duplicate deck on the same cruise to be rejected")
+	}
+	if err := db.Exec(`INSERT INTO deck_plan_cabins (deck_plan_id, cabin_number, x, y, width, height) VALUES (1, '8001', 10, 20, 30, 40)`).Error; err != nil {
+		t.Fatalf("insert deck plan cabin failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO deck_plan_cabins (deck_plan_id, cabin_number) VALUES (1, '8001')`).Error; err == nil {
+		t.Fatal("expected duplicate cabin number on the same plan to be rejected")
+	}
+
+	execMigrationFile(t, db, "000037_deck_plans.down.sql")
+	assertTableMissing(t, db, "deck_plans")
+	assertTableMissing(t, db, "deck_plan_cabins")
+}
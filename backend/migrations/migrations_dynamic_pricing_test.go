@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestDynamicPricingMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000027_dynamic_pricing.up.sql",
+		"000027_dynamic_pricing.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestDynamicPricingMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	upBytes, err := os.ReadFile("000027_dynamic_pricing.up.sql")
+	if err != nil {
+		t.Fatalf("read up migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(upBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute up statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	assertTableExists(t, db, "dynamic_pricing_rules")
+	assertTableExists(t, db, "dynamic_pricing_proposals")
+	assertTableExists(t, db, "dynamic_pricing_proposal_items")
+	assertColumnExists(t, db, "dynamic_pricing_rules", "ceiling_markup_percent")
+	assertColumnExists(t, db, "dynamic_pricing_proposal_items", "base_version_id")
+
+	downBytes, err := os.ReadFile("000027_dynamic_pricing.down.sql")
+	if err != nil {
+		t.Fatalf("read down migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(downBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute down statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	assertTableMissing(t, db, "dynamic_pricing_rules")
+	assertTableMissing(t, db, "dynamic_pricing_proposals")
+	assertTableMissing(t, db, "dynamic_pricing_proposal_items")
+}
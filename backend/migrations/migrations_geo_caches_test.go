@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGeoCachesMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000032_geo_caches.up.sql",
+		"000032_geo_caches.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestGeoCachesMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000032_geo_caches.up.sql")
+	assertTableExists(t, db, "route_map_cache")
+	assertTableExists(t, db, "geocode_cache")
+	assertColumnExists(t, db, "route_map_cache", "cache_key")
+	assertColumnExists(t, db, "geocode_cache", "expires_at")
+
+	execMigrationFile(t, db, "000032_geo_caches.down.sql")
+	assertTableMissing(t, db, "route_map_cache")
+	assertTableMissing(t, db, "geocode_cache")
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestIdempotencyKeysMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000044_idempotency_keys.up.sql",
+		"000044_idempotency_keys.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestIdempotencyKeysMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000044_idempotency_keys.up.sql")
+	assertTableExists(t, db, "idempotency_keys")
+	assertColumnExists(t, db, "idempotency_keys", "fingerprint")
+	assertColumnExists(t, db, "idempotency_keys", "response_body")
+	if err := db.Exec(`INSERT INTO idempotency_keys (idem_key, fingerprint, expires_at) VALUES ('k', 'f', CURRENT_TIMESTAMP)`).Error; err != nil {
+		t.Fatalf("insert record failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO idempotency_keys (idem_key, fingerprint, expires_at) VALUES ('k', 'g', CURRENT_TIMESTAMP)`).Error; err == nil {
+		t.Fatal("expected duplicate idempotency key to be rejected")
+	}
+
+	execMigrationFile(t, db, "000044_idempotency_keys.down.sql")
+	assertTableMissing(t, db, "idempotency_keys")
+}
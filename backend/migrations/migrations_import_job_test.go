@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestImportJobMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000036_import_jobs.up.sql",
+		"000036_import_jobs.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestImportJobMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000036_import_jobs.up.sql")
+	assertTableExists(t, db, "import_jobs")
+	assertColumnExists(t, db, "import_jobs", "error_report")
+	if err := db.Exec(`INSERT INTO import_jobs (kind, status, mode) VALUES ('custom_destinations', 'pending', 'best_effort')`).Error; err != nil {
+		t.Fatalf("insert import job failed: %v", err)
+	}
+	var dryRun bool
+	if err := db.Raw(`SELECT dry_run FROM import_jobs WHERE kind = 'custom_destinations'`).Scan(&dryRun).Error; err != nil || dryRun {
+		t.Fatalf("expected dry_run to default to false, got %v, %v", dryRun, err)
+	}
+
+	execMigrationFile(t, db, "000036_import_jobs.down.sql")
+	assertTableMissing(t, db, "import_jobs")
+}
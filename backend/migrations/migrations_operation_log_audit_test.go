@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestOperationLogAuditMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000042_operation_log_audit.up.sql",
+		"000042_operation_log_audit.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestOperationLogAuditMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE operation_logs (id INTEGER PRIMARY KEY, staff_id BIGINT, operation VARCHAR(50), resource VARCHAR(50), resource_id BIGINT, details TEXT)`).Error; err != nil {
+		t.Fatalf("create operation_logs failed: %v", err)
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000042_operation_log_audit.up.sql")
+	for _, column := range []string{"method", "route", "status_code", "changes", "prev_hash", "hash"} {
+		assertColumnExists(t, db, "operation_logs", column)
+	}
+	assertTableExists(t, db, "operation_log_chain_heads")
+	var heads int64
+	db.Raw(`SELECT COUNT(*) FROM operation_log_chain_heads WHERE id = 1`).Scan(&heads)
+	if heads != 1 {
+		t.Fatalf("expected the chain head row to be seeded, got %d", heads)
+	}
+
+	execMigrationFile(t, db, "000042_operation_log_audit.down.sql")
+	assertTableMissing(t, db, "operation_log_chain_heads")
+	if db.Migrator().HasColumn("operation_logs", "hash") {
+		t.Fatal("expected hash to be dropped")
+	}
+}
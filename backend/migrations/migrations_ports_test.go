@@ -0,0 +1,121 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestPortsMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000031_ports.up.sql",
+		"000031_ports.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestPortsMigrationMovesDictionarySeeds(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`
+		CREATE TABLE custom_destinations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			country TEXT NOT NULL DEFAULT '',
+			latitude REAL,
+			longitude REAL,
+			keywords TEXT NOT NULL DEFAULT '',
+			description TEXT NOT NULL DEFAULT '',
+			status SMALLINT NOT NULL DEFAULT 1,
+			sort_order INT NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME
+		);
+	`).Error; err != nil {
+		t.Fatalf("create custom_destinations failed: %v", err)
+	}
+	execMigrationFile(t, db, "000024_seed_port_city_dictionary.up.sql")
+	execMigrationFile(t, db, "000025_extend_port_city_dictionary.up.sql")
+	// 模拟后台修正过的种子坐标，以及一条非种子的自定义目的地
+	if err := db.Exec(`UPDATE custom_destinations SET latitude = 33.6065, longitude = 130.3960, sort_order = 1 WHERE name = '福冈'`).Error; err != nil {
+		t.Fatalf("update seed failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO custom_destinations (name, country, latitude, longitude, description) VALUES ('可可岛', '巴哈马', 25.82, -77.94, 'private island')`).Error; err != nil {
+		t.Fatalf("insert custom destination failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000031_ports.up.sql")
+	assertTableExists(t, db, "ports")
+	assertColumnExists(t, db, "ports", "timezone")
+	assertColumnExists(t, db, "ports", "terminal_name")
+
+	var portCount int64
+	if err := db.Raw(`SELECT COUNT(*) FROM ports`).Scan(&portCount).Error; err != nil {
+		t.Fatalf("count ports failed: %v", err)
+	}
+	if portCount != 71 {
+		t.Fatalf("expected 71 seeded ports, got %d", portCount)
+	}
+	type portRow struct {
+		Code      string
+		NameEN    string
+		Timezone  string
+		Latitude  float64
+		Longitude float64
+		SortOrder int
+	}
+	var shanghai portRow
+	if err := db.Raw(`SELECT code, name_en, timezone, latitude, longitude, sort_order FROM ports WHERE name_zh = '上海'`).Scan(&shanghai).Error; err != nil {
+		t.Fatalf("query shanghai failed: %v", err)
+	}
+	if shanghai.Code != "CNSHA" || shanghai.NameEN != "Shanghai" || shanghai.Timezone != "Asia/Shanghai" || shanghai.Latitude != 31.2304 {
+		t.Fatalf("unexpected shanghai port: %+v", shanghai)
+	}
+	var fukuoka portRow
+	if err := db.Raw(`SELECT code, latitude, longitude, sort_order FROM ports WHERE code = 'JPFUK'`).Scan(&fukuoka).Error; err != nil {
+		t.Fatalf("query fukuoka failed: %v", err)
+	}
+	if fukuoka.Latitude != 33.6065 || fukuoka.Longitude != 130.3960 || fukuoka.SortOrder != 1 {
+		t.Fatalf("expected edited seed values to be migrated, got %+v", fukuoka)
+	}
+
+	var seedLeft, customLeft int64
+	db.Raw(`SELECT COUNT(*) FROM custom_destinations WHERE description LIKE 'system_port_city_dictionary_seed_%'`).Scan(&seedLeft)
+	db.Raw(`SELECT COUNT(*) FROM custom_destinations WHERE name = '可可岛'`).Scan(&customLeft)
+	if seedLeft != 0 || customLeft != 1 {
+		t.Fatalf("expected only dictionary seeds moved out of custom_destinations, seeds=%d custom=%d", seedLeft, customLeft)
+	}
+
+	execMigrationFile(t, db, "000031_ports.down.sql")
+	assertTableMissing(t, db, "ports")
+	var restored int64
+	db.Raw(`SELECT COUNT(*) FROM custom_destinations WHERE description = 'system_port_city_dictionary_seed_v2'`).Scan(&restored)
+	if restored != 28 {
+		t.Fatalf("expected v2 dictionary seeds restored on down migration, got %d", restored)
+	}
+}
+
+// execMigrationFile 以 SQLite 兼容方式逐条执行迁移文件。
+func execMigrationFile(t *testing.T, db *gorm.DB, name string) {
+	t.Helper()
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("read migration %s failed: %v", name, err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(content)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute %s failed: %v\nstmt=%s", name, err, stmt)
+		}
+	}
+}
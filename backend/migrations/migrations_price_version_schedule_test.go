@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestPriceVersionScheduleMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000028_price_version_schedule.up.sql",
+		"000028_price_version_schedule.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestPriceVersionScheduleMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE voyage_cabin_type_price_versions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		voyage_id BIGINT NOT NULL,
+		cabin_type_id BIGINT NOT NULL,
+		effective_at DATETIME NOT NULL
+	);`).Error; err != nil {
+		t.Fatalf("create price versions failed: %v", err)
+	}
+
+	upBytes, err := os.ReadFile("000028_price_version_schedule.up.sql")
+	if err != nil {
+		t.Fatalf("read up migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(upBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute up statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	assertColumnExists(t, db, "voyage_cabin_type_price_versions", "cancelled_at")
+	assertColumnExists(t, db, "voyage_cabin_type_price_versions", "cancelled_by")
+	assertColumnExists(t, db, "voyage_cabin_type_price_versions", "source_version_id")
+
+	downBytes, err := os.ReadFile("000028_price_version_schedule.down.sql")
+	if err != nil {
+		t.Fatalf("read down migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(downBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute down statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestStaffAllCompaniesMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000046_staff_all_companies.up.sql",
+		"000046_staff_all_companies.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestStaffAllCompaniesMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE staffs (id INTEGER PRIMARY KEY, username VARCHAR(50), role VARCHAR(20))`,
+		`CREATE TABLE staff_companies (staff_id BIGINT NOT NULL, company_id BIGINT NOT NULL, PRIMARY KEY (staff_id, company_id))`,
+		`INSERT INTO staffs (id, username, role) VALUES (1, 'admin', 'super_admin'), (2, 'finance', 'finance'), (3, 'partner', 'operator'), (4, 'support', 'support')`,
+		`INSERT INTO staff_companies (staff_id, company_id) VALUES (3, 7)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("prepare staffs failed: %v", err)
+		}
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000046_staff_all_companies.up.sql")
+	assertColumnExists(t, db, "staffs", "all_companies")
+	var granted []int64
+	if err := db.Raw(`SELECT id FROM staffs WHERE all_companies ORDER BY id`).Scan(&granted).Error; err != nil {
+		t.Fatalf("query staffs failed: %v", err)
+	}
+	if len(granted) != 3 || granted[0] != 1 || granted[1] != 2 || granted[2] != 4 {
+		t.Fatalf("expected existing staff without company assignments to keep every company, got %v", granted)
+	}
+
+	execMigrationFile(t, db, "000046_staff_all_companies.down.sql")
+	if db.Migrator().HasColumn("staffs", "all_companies") {
+		t.Fatal("expected all_companies to be dropped")
+	}
+}
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestStaffCompanyScopeMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000043_staff_company_scope.up.sql",
+		"000043_staff_company_scope.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestStaffCompanyScopeMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE staffs (id INTEGER PRIMARY KEY)`,
+		`CREATE TABLE cruise_companies (id INTEGER PRIMARY KEY)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("create base table failed: %v", err)
+		}
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000043_staff_company_scope.up.sql")
+	assertTableExists(t, db, "staff_companies")
+	assertColumnExists(t, db, "staff_companies", "company_id")
+	if err := db.Exec(`INSERT INTO staff_companies (staff_id, company_id) VALUES (1, 2)`).Error; err != nil {
+		t.Fatalf("insert scope failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO staff_companies (staff_id, company_id) VALUES (1, 2)`).Error; err == nil {
+		t.Fatal("expected duplicate staff/company pair to be rejected")
+	}
+
+	execMigrationFile(t, db, "000043_staff_company_scope.down.sql")
+	assertTableMissing(t, db, "staff_companies")
+}
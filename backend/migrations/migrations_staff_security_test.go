@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestStaffSecurityMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000041_staff_security.up.sql",
+		"000041_staff_security.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestStaffSecurityMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE staffs (id INTEGER PRIMARY KEY, username VARCHAR(50))`).Error; err != nil {
+		t.Fatalf("create staffs failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO staffs (id, username) VALUES (1, 'admin')`).Error; err != nil {
+		t.Fatalf("seed staffs failed: %v", err)
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000041_staff_security.up.sql")
+	for _, column := range []string{"password_changed_at", "must_change_password", "failed_login_count", "locked_until", "totp_secret", "totp_enabled_at", "totp_last_step"} {
+		assertColumnExists(t, db, "staffs", column)
+	}
+	assertTableExists(t, db, "staff_password_histories")
+	assertTableExists(t, db, "staff_password_reset_tokens")
+	var backfilled int64
+	db.Raw(`SELECT COUNT(*) FROM staffs WHERE password_changed_at IS NOT NULL`).Scan(&backfilled)
+	if backfilled != 1 {
+		t.Fatalf("expected existing staff to get password_changed_at, got %d", backfilled)
+	}
+
+	execMigrationFile(t, db, "000041_staff_security.down.sql")
+	assertTableMissing(t, db, "staff_password_reset_tokens")
+	assertTableMissing(t, db, "staff_password_histories")
+	if db.Migrator().HasColumn("staffs", "totp_secret") {
+		t.Fatal("expected totp_secret to be dropped")
+	}
+}
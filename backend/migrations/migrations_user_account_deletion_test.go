@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestUserAccountDeletionMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000040_user_account_deletion.up.sql",
+		"000040_user_account_deletion.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestUserAccountDeletionMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, phone VARCHAR(20))`).Error; err != nil {
+		t.Fatalf("create users failed: %v", err)
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000040_user_account_deletion.up.sql")
+	assertColumnExists(t, db, "users", "deletion_requested_at")
+	assertColumnExists(t, db, "users", "deletion_scheduled_at")
+	assertColumnExists(t, db, "users", "anonymized_at")
+
+	execMigrationFile(t, db, "000040_user_account_deletion.down.sql")
+	if db.Migrator().HasColumn("users", "anonymized_at") {
+		t.Fatal("expected anonymized_at to be dropped")
+	}
+}
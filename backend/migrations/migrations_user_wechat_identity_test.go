@@ -0,0 +1,82 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestUserWechatIdentityMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000038_user_wechat_identity.up.sql",
+		"000038_user_wechat_identity.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+// execMigrationFileWithoutConstraints 执行迁移中 SQLite 支持的语句：去掉注释行并跳过 ADD/DROP CONSTRAINT。
+func execMigrationFileWithoutConstraints(t *testing.T, db *gorm.DB, name string) {
+	t.Helper()
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("read migration %s failed: %v", name, err)
+	}
+	lines := strings.Split(string(content), "\n")
+	lines = slices.DeleteFunc(lines, func(line string) bool { return strings.HasPrefix(strings.TrimSpace(line), "--") })
+	for _, stmt := range sqliteCompatibleStatements(strings.Join(lines, "\n")) {
+		if strings.Contains(stmt, " CONSTRAINT ") {
+			continue
+		}
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute %s failed: %v\nstmt=%s", name, err, stmt)
+		}
+	}
+}
+
+func TestUserWechatIdentityMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE users (id INTEGER PRIMARY KEY, phone VARCHAR(20), wx_open_id VARCHAR(80), alipay_uid VARCHAR(80))`).Error; err != nil {
+		t.Fatalf("create users failed: %v", err)
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000038_user_wechat_identity.up.sql")
+	assertColumnExists(t, db, "users", "wx_union_id")
+	assertColumnExists(t, db, "users", "wx_session_key")
+	for _, stmt := range []string{
+		`INSERT INTO users (phone, wx_open_id, alipay_uid) VALUES ('13800000000', '', '')`,
+		`INSERT INTO users (phone, wx_open_id, alipay_uid) VALUES ('', 'openid-1', '')`,
+		`INSERT INTO users (phone, wx_open_id, alipay_uid) VALUES ('', '', '')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("empty identities must not conflict: %v", err)
+		}
+	}
+	if err := db.Exec(`INSERT INTO users (phone, wx_open_id) VALUES ('', 'openid-1')`).Error; err == nil {
+		t.Fatal("expected duplicate wx_open_id to be rejected")
+	}
+	if err := db.Exec(`INSERT INTO users (phone, wx_open_id) VALUES ('13800000000', '')`).Error; err == nil {
+		t.Fatal("expected duplicate phone to be rejected")
+	}
+
+	execMigrationFileWithoutConstraints(t, db, "000038_user_wechat_identity.down.sql")
+	var blanks int64
+	if err := db.Raw(`SELECT COUNT(*) FROM users WHERE phone = '' OR wx_open_id = ''`).Scan(&blanks).Error; err != nil {
+		t.Fatalf("count blank identities failed: %v", err)
+	}
+	if blanks != 0 {
+		t.Fatalf("expected blank identities to be reset to NULL, got %d", blanks)
+	}
+}
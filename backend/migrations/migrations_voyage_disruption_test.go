@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestVoyageDisruptionMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000035_voyage_disruptions.up.sql",
+		"000035_voyage_disruptions.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestVoyageDisruptionMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE voyages (id INTEGER PRIMARY KEY AUTOINCREMENT, code TEXT NOT NULL);`).Error; err != nil {
+		t.Fatalf("create voyages failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO voyages (code) VALUES ('SPEC-20260801')`).Error; err != nil {
+		t.Fatalf("insert voyage failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000035_voyage_disruptions.up.sql")
+	assertTableExists(t, db, "voyage_disruptions")
+	assertTableExists(t, db, "disruption_bookings")
+	assertTableExists(t, db, "travel_credits")
+	assertColumnExists(t, db, "disruption_bookings", "alternative_voyage_ids")
+	assertColumnExists(t, db, "voyages", "disruption")
+	var disruption string
+	if err := db.Raw(`SELECT disruption FROM voyages WHERE code = 'SPEC-20260801'`).Scan(&disruption).Error; err != nil || disruption != "" {
+		t.Fatalf("expected existing voyages to default to no disruption, got %q, %v", disruption, err)
+	}
+
+	execMigrationFile(t, db, "000035_voyage_disruptions.down.sql")
+	assertTableMissing(t, db, "voyage_disruptions")
+	assertTableMissing(t, db, "disruption_bookings")
+	assertTableMissing(t, db, "travel_credits")
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestVoyageItineraryTimezoneMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000033_voyage_itinerary_timezone.up.sql",
+		"000033_voyage_itinerary_timezone.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestVoyageItineraryTimezoneMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE voyage_itineraries (id INTEGER PRIMARY KEY AUTOINCREMENT, city TEXT NOT NULL, eta_time TEXT);`).Error; err != nil {
+		t.Fatalf("create voyage_itineraries failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO voyage_itineraries (city, eta_time) VALUES ('福冈', '08:00')`).Error; err != nil {
+		t.Fatalf("insert itinerary failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000033_voyage_itinerary_timezone.up.sql")
+	assertColumnExists(t, db, "voyage_itineraries", "timezone")
+	var timezone string
+	if err := db.Raw(`SELECT timezone FROM voyage_itineraries WHERE city = '福冈'`).Scan(&timezone).Error; err != nil || timezone != "" {
+		t.Fatalf("expected existing rows to default to empty timezone, got %q, %v", timezone, err)
+	}
+
+	execMigrationFile(t, db, "000033_voyage_itinerary_timezone.down.sql")
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestVoyageSeriesMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000034_voyage_series.up.sql",
+		"000034_voyage_series.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestVoyageSeriesMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+	if err := db.Exec(`CREATE TABLE voyages (id INTEGER PRIMARY KEY AUTOINCREMENT, code TEXT NOT NULL);`).Error; err != nil {
+		t.Fatalf("create voyages failed: %v", err)
+	}
+	if err := db.Exec(`INSERT INTO voyages (code) VALUES ('SPEC-20260501')`).Error; err != nil {
+		t.Fatalf("insert voyage failed: %v", err)
+	}
+
+	execMigrationFile(t, db, "000034_voyage_series.up.sql")
+	assertTableExists(t, db, "voyage_series")
+	assertColumnExists(t, db, "voyage_series", "cabin_prices_json")
+	assertColumnExists(t, db, "voyages", "series_id")
+	var seriesID int64
+	if err := db.Raw(`SELECT series_id FROM voyages WHERE code = 'SPEC-20260501'`).Scan(&seriesID).Error; err != nil || seriesID != 0 {
+		t.Fatalf("expected existing voyages to default to series 0, got %d, %v", seriesID, err)
+	}
+
+	execMigrationFile(t, db, "000034_voyage_series.down.sql")
+	assertTableMissing(t, db, "voyage_series")
+}
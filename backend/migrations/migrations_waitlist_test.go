@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestWaitlistMigrationFilesExist(t *testing.T) {
+	files := []string{
+		"000030_waitlist.up.sql",
+		"000030_waitlist.down.sql",
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("expected migration file %s to exist: %v", f, err)
+		}
+	}
+}
+
+func TestWaitlistMigrationExecuteUpDown(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open sqlite failed: %v", err)
+	}
+
+	upBytes, err := os.ReadFile("000030_waitlist.up.sql")
+	if err != nil {
+		t.Fatalf("read up migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(upBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute up statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	assertTableExists(t, db, "waitlist_entries")
+	assertColumnExists(t, db, "waitlist_entries", "offer_hold_id")
+	assertColumnExists(t, db, "waitlist_entries", "offer_expires_at")
+
+	downBytes, err := os.ReadFile("000030_waitlist.down.sql")
+	if err != nil {
+		t.Fatalf("read down migration failed: %v", err)
+	}
+	for _, stmt := range sqliteCompatibleStatements(string(downBytes)) {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("execute down statement failed: %v\nstmt=%s", err, stmt)
+		}
+	}
+	assertTableMissing(t, db, "waitlist_entries")
+}